@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"syscall"
@@ -22,9 +24,10 @@ import (
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/lock"
-	"github.com/erikh/hydra/internal/notify"
 	"github.com/erikh/hydra/internal/repo"
 	"github.com/erikh/hydra/internal/runner"
+	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/erikh/hydra/internal/tracing"
 	"github.com/erikh/hydra/internal/tui"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
@@ -33,25 +36,89 @@ import (
 
 // NewApp creates the hydra CLI application.
 func NewApp() *cli.App {
-	return &cli.App{
+	shutdownTracing := func(context.Context) error { return nil }
+
+	app := &cli.App{
 		Name:                 "hydra",
 		Usage:                "Local pull request workflow where Claude is the only contributor",
 		EnableBashCompletion: true,
 		Description: "Hydra turns markdown design documents into branches, code, and commits. " +
 			"It assembles context from your design docs, hands it to Claude, runs tests and " +
 			"linting, and pushes a branch ready for your review.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "work-dir",
+				EnvVars: []string{workDirEnv},
+				Usage:   "Directory to keep work directories in, overriding work_dir in hydra.yml",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Answer yes to every confirmation prompt, for scripts",
+			},
+			&cli.BoolFlag{
+				Name:    "wait-in-use",
+				EnvVars: []string{waitInUseEnv},
+				Usage:   "Wait for hydra review dev to stop serving a work directory instead of refusing to touch it",
+			},
+			&cli.BoolFlag{
+				Name:    "rebase-onto",
+				EnvVars: []string{rebaseOntoEnv},
+				Usage:   "When origin's default branch was force-pushed, rebase only the branch's own commits without asking",
+			},
+		},
 		Before: func(c *cli.Context) error {
+			if dir := c.String("work-dir"); dir != "" {
+				abs, err := filepath.Abs(dir)
+				if err != nil {
+					return fmt.Errorf("resolving --work-dir: %w", err)
+				}
+				if err := os.Setenv(workDirEnv, abs); err != nil {
+					return err
+				}
+			}
+			if c.Bool("wait-in-use") {
+				if err := os.Setenv(waitInUseEnv, "1"); err != nil {
+					return err
+				}
+			} else if err := os.Unsetenv(waitInUseEnv); err != nil { // so HYDRA_WAIT_IN_USE=false reads as unset
+				return err
+			}
+			if c.Bool("rebase-onto") {
+				if err := os.Setenv(rebaseOntoEnv, "1"); err != nil {
+					return err
+				}
+			} else if err := os.Unsetenv(rebaseOntoEnv); err != nil {
+				return err
+			}
 			if c.Args().First() != "completion" {
 				promptCompletionInstall()
 			}
 			setTerminalTitle(c)
+
+			shutdown, err := tracing.Setup(c.Context)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: tracing disabled: %v\n", err)
+			}
+			shutdownTracing = shutdown
+			return nil
+		},
+		After: func(c *cli.Context) error {
+			if err := shutdownTracing(c.Context); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: flushing traces: %v\n", err)
+			}
 			return nil
 		},
 		Commands: []*cli.Command{
 			initCommand(),
 			runCommand(),
+			approveCommand(),
+			attachCommand(),
+			planCommand(),
+			splitCommand(),
 			groupCommand(),
 			editCommand(),
+			taskCommand(),
 			otherCommand(),
 			reviewCommand(),
 			testCommand(),
@@ -60,14 +127,28 @@ func NewApp() *cli.App {
 			reconcileCommand(),
 			verifyCommand(),
 			fixCommand(),
+			lintDesignCommand(),
+			designCommand(),
 			statusCommand(),
 			listCommand(),
 			milestoneCommand(),
 			syncCommand(),
+			importFailureCommand(),
+			importFromClaudeCodeCommand(),
 			notifyCommand(),
+			authCommand(),
+			hooksCommand(),
+			nextCommand(),
+			compareCommand(),
+			statsCommand(),
+			recordCommand(),
+			workdirsCommand(),
 			completionCommand(),
+			stateToolCommand(),
 		},
 	}
+	withFlagCompletion(app.Commands)
+	return app
 }
 
 func initCommand() *cli.Command {
@@ -77,7 +158,15 @@ func initCommand() *cli.Command {
 		ArgsUsage: "<source-repo-url> <design-dir>",
 		Description: "Clones the source repository and registers the design directory. " +
 			"If the design directory is empty, creates the full skeleton structure including " +
-			"tasks/, state/, milestone/, and configuration files.",
+			"tasks/, state/, milestone/, and configuration files. If the source repository " +
+			"has Claude Code guidance (CLAUDE.md or .claude/), it is imported into empty " +
+			"rules.md and lint.md files, as with 'hydra import-from-claude-code'.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "no-claude-code",
+				Usage: "Don't import the source repository's Claude Code guidance",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() != 2 {
 				return errors.New("usage: hydra init <source-repo-url> <design-dir>")
@@ -124,6 +213,16 @@ func initCommand() *cli.Command {
 				}
 			}
 
+			if !c.Bool("no-claude-code") {
+				dd, err := openDesignDir(cfg)
+				if err == nil {
+					_, err = importClaudeCode(dd, cfg.RepoDir, false)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not import Claude Code guidance: %v\n", err)
+				}
+			}
+
 			fmt.Println("Initialized hydra project.")
 			fmt.Printf("  Source repo: %s\n", cfg.RepoDir)
 			fmt.Printf("  Design dir:  %s\n", cfg.DesignDir)
@@ -140,23 +239,67 @@ func editCommand() *cli.Command {
 		BashComplete: completeTasks(design.StatePending),
 		Description: "Opens your editor to create or edit a task file. If the task already " +
 			"exists, opens it in-place. The editor is resolved from $VISUAL, then $EDITOR. " +
-			"The task name must not contain '/'.",
+			"The task name must not contain '/'.\n\n" +
+			"With --stdin or --message, creates the task from standard input or the " +
+			"given text instead, without an editor, for scripts and other tools. These " +
+			"only create tasks: they fail if the task already exists.\n\n" +
+			"With --rename, renames the task instead, like hydra task rename.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "stdin",
+				Usage: "Create the task from standard input",
+			},
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Create the task with this text as its content",
+			},
+			&cli.StringFlag{
+				Name:  "rename",
+				Usage: "Rename the task to this name instead of editing it",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() != 1 {
-				return errors.New("usage: hydra edit <task-name>")
+				return errors.New("usage: hydra edit [--stdin | -m <body> | --rename <new-name>] <task-name>")
+			}
+			if c.IsSet("rename") {
+				if c.Bool("stdin") || c.IsSet("message") {
+					return errors.New("--rename can't be combined with --stdin or --message")
+				}
+				return renameTask(c.Args().Get(0), c.String("rename"))
+			}
+			if c.Bool("stdin") && c.IsSet("message") {
+				return errors.New("--stdin and --message are mutually exclusive")
 			}
 
 			cfg, err := config.Discover()
 			if err != nil {
 				return fmt.Errorf("loading config (are you in an initialized hydra directory?): %w", err)
 			}
+			taskName := c.Args().Get(0)
+
+			if c.Bool("stdin") || c.IsSet("message") {
+				content := c.String("message") + "\n"
+				if c.Bool("stdin") {
+					data, err := io.ReadAll(os.Stdin)
+					if err != nil {
+						return fmt.Errorf("reading stdin: %w", err)
+					}
+					content = string(data)
+				}
+				dd, err := openDesignDir(cfg)
+				if err != nil {
+					return err
+				}
+				_, err = dd.CreateTask(taskName, content)
+				return err
+			}
 
 			editor, err := resolveEditor()
 			if err != nil {
 				return err
 			}
-
-			taskName := c.Args().Get(0)
 			return design.EditTask(cfg.DesignDir, taskName, editor, os.Stdin, os.Stdout, os.Stderr)
 		},
 	}
@@ -226,9 +369,10 @@ func otherCommand() *cli.Command {
 				},
 			},
 			{
-				Name:      "view",
-				Usage:     "Print the content of a file in other/",
-				ArgsUsage: "<name>",
+				Name:         "view",
+				Usage:        "Print the content of a file in other/",
+				ArgsUsage:    "<name>",
+				BashComplete: completeOtherFiles,
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return errors.New("usage: hydra other view <name>")
@@ -250,9 +394,10 @@ func otherCommand() *cli.Command {
 				},
 			},
 			{
-				Name:      "edit",
-				Usage:     "Edit an existing file in other/",
-				ArgsUsage: "<name>",
+				Name:         "edit",
+				Usage:        "Edit an existing file in other/",
+				ArgsUsage:    "<name>",
+				BashComplete: completeOtherFiles,
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return errors.New("usage: hydra other edit <name>")
@@ -269,9 +414,17 @@ func otherCommand() *cli.Command {
 				},
 			},
 			{
-				Name:      "rm",
-				Usage:     "Remove a file from other/",
-				ArgsUsage: "<name>",
+				Name:         "rm",
+				Usage:        "Remove a file from other/",
+				ArgsUsage:    "<name>",
+				BashComplete: completeOtherFiles,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Remove without asking",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return errors.New("usage: hydra other rm <name>")
@@ -284,7 +437,19 @@ func otherCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return dd.RemoveOtherFile(c.Args().Get(0))
+					name := c.Args().Get(0)
+					if !assumeYes(c) && isatty.IsTerminal(os.Stdin.Fd()) {
+						content, err := dd.OtherContent(name)
+						if err != nil {
+							return err
+						}
+						fmt.Printf("other/%s:\n%s", name, design.Preview(content))
+						if !runner.Confirm(fmt.Sprintf("Remove other/%s?", name)) {
+							fmt.Println("Aborted.")
+							return nil
+						}
+					}
+					return dd.RemoveOtherFile(name)
 				},
 			},
 		},
@@ -294,13 +459,24 @@ func otherCommand() *cli.Command {
 func runCommand() *cli.Command {
 	return &cli.Command{
 		Name:         "run",
-		Usage:        "Execute a design task",
-		ArgsUsage:    "<task-name>",
-		BashComplete: completeTasks(design.StatePending),
+		Usage:        "Execute one or more design tasks",
+		ArgsUsage:    "<task-name> [task-name...]",
+		BashComplete: completeTaskList(design.StatePending),
 		Description: "Executes the full task lifecycle: acquires a lock, creates a git branch, " +
 			"assembles the design document, invokes Claude via the Anthropic API with an " +
 			"interactive TUI, runs tests and linter, commits, pushes, records the commit SHA, " +
-			"and moves the task to review.",
+			"and moves the task to review. When several tasks are given, they run one after " +
+			"another; a failed or locked task does not stop the rest, and a summary is " +
+			"printed at the end. Without a task name on a terminal, opens a fuzzy picker " +
+			"over the pending tasks with a preview of each. --issue runs the task for an " +
+			"issue, importing it from the issue tracker first if needed. --detach runs a " +
+			"single task in the background without a TUI, auto-accepting tool calls and " +
+			"logging the session; 'hydra attach' follows it. --plan-handoff (or plan_handoff " +
+			"in hydra.yml) plans in a separate session first, saves the plan, sends a " +
+			"notification, and waits for 'hydra plan approve', 'edit', or 'reject' before " +
+			"executing it. Before each session starts, hydra prints the document's estimated " +
+			"tokens and the tokens, cost, and time that past runs of similar size took; " +
+			"--confirm-cost asks before going ahead.",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "no-auto-accept",
@@ -326,10 +502,55 @@ func runCommand() *cli.Command {
 				Name:  "model",
 				Usage: "Override the Claude model",
 			},
+			&cli.BoolFlag{
+				Name:  "use-plan",
+				Usage: "Execute the plan saved by 'hydra plan' instead of planning again",
+			},
+			&cli.BoolFlag{
+				Name:  "plan-handoff",
+				Usage: "Plan first, then wait for 'hydra plan approve' before executing the plan",
+			},
+			&cli.BoolFlag{
+				Name:  "confirm-cost",
+				Usage: "Ask before starting each session, after printing its token, cost, and time estimate",
+			},
+			&cli.IntFlag{
+				Name:  "issue",
+				Usage: "Run the task for this issue number, importing the issue first if 'hydra sync' hasn't",
+			},
+			&cli.BoolFlag{
+				Name:    "detach",
+				Aliases: []string{"d"},
+				Usage:   "Run in the background without a TUI, auto-accepting tool calls; follow it with 'hydra attach'",
+			},
+			&cli.BoolFlag{
+				Name:   "headless",
+				Hidden: true,
+				Usage:  "Run without a terminal, streaming the session to stdout (used by --detach)",
+			},
+			forceSetupFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			if c.NArg() != 1 {
-				return errors.New("usage: hydra run <task-name>")
+			args := c.Args().Slice()
+			issue := c.Int("issue")
+			if c.Bool("detach") && len(args) > 1 {
+				return errors.New("--detach runs a single task")
+			}
+			if c.Bool("confirm-cost") && c.Bool("detach") {
+				return errors.New("--confirm-cost needs a terminal and cannot be combined with --detach")
+			}
+			if c.Bool("plan-handoff") && (c.Bool("no-plan") || c.Bool("use-plan")) {
+				return errors.New("--plan-handoff cannot be combined with --no-plan or --use-plan")
+			}
+			if issue != 0 && len(args) > 0 {
+				return errors.New("--issue cannot be combined with task names")
+			}
+			if len(args) == 0 && issue == 0 {
+				name, err := pickTask("Run a pending task", errors.New("usage: hydra run <task-name> [task-name...]"), design.StatePending)
+				if err != nil || name == "" {
+					return err
+				}
+				args = []string{name}
 			}
 
 			cfg, err := config.Discover()
@@ -341,6 +562,9 @@ func runCommand() *cli.Command {
 			if err != nil {
 				return err
 			}
+			r.WorkRoot = os.Getenv(workDirEnv)
+			r.WaitInUse = os.Getenv(waitInUseEnv) != ""
+			r.RebaseOnto = os.Getenv(rebaseOntoEnv) != ""
 
 			r.AutoAccept = true
 			r.PlanMode = true
@@ -356,10 +580,104 @@ func runCommand() *cli.Command {
 			}
 			r.ForceTUI = c.Bool("tui")
 			if m := c.String("model"); m != "" {
-				r.Model = m
+				r.SetModel(m)
+			}
+			r.UsePlan = c.Bool("use-plan")
+			r.PlanHandoff = c.Bool("plan-handoff")
+			if c.Bool("confirm-cost") {
+				r.ConfirmCost = func() bool { return runner.Confirm("Start the session?") }
+			}
+			r.ForceSetup = c.Bool("force-setup")
+			if c.Bool("headless") {
+				r.Headless = true
+				r.AutoAccept = true
+				r.PlanMode = false
 			}
 
-			return r.Run(c.Args().Get(0))
+			if issue != 0 {
+				name, err := r.ImportIssue(issue)
+				if err != nil {
+					return err
+				}
+				args = []string{name}
+			}
+			if c.Bool("detach") {
+				t, err := r.Design.FindTask(args[0])
+				if err != nil {
+					return err
+				}
+				name := t.Name
+				if t.Group != "" {
+					name = t.Group + "/" + t.Name
+				}
+				return detachRun(c, name)
+			}
+			if len(args) > 1 {
+				return r.RunTasks(args)
+			}
+			return r.Run(args[0])
+		},
+	}
+}
+
+func planCommand() *cli.Command {
+	return &cli.Command{
+		Name:         "plan",
+		Usage:        "Run only the planning phase of a task",
+		ArgsUsage:    "<task-name>",
+		BashComplete: completeTasks(design.StatePending),
+		Description: "Opens a Claude session in plan mode that designs an implementation plan " +
+			"for a pending task without changing any code. Once the plan is approved it is " +
+			"saved to .hydra/plans/<task>.md, where it can be reviewed and edited. " +
+			"Execute it later with 'hydra run --use-plan <task>'. The task stays pending. " +
+			"'approve' and 'reject' answer a run waiting on its plan under --plan-handoff " +
+			"or plan_handoff, and 'edit' opens a task's saved plan in $EDITOR.",
+		Subcommands: planReviewCommands(),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "no-auto-accept",
+				Aliases: []string{"Y"},
+				Usage:   "Disable auto-accept (prompt for each tool call)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-notify",
+				Aliases: []string{"N"},
+				Usage:   "Disable desktop notifications when confirmation is needed",
+			},
+			&cli.BoolFlag{
+				Name:    "tui",
+				Aliases: []string{"T"},
+				Usage:   "Force the built-in TUI instead of Claude Code CLI",
+			},
+			&cli.StringFlag{
+				Name:  "model",
+				Usage: "Override the Claude model",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra plan <task-name>")
+			}
+
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+
+			r.AutoAccept = true
+			r.Notify = true
+			if c.Bool("no-auto-accept") {
+				r.AutoAccept = false
+			}
+			if c.Bool("no-notify") {
+				r.Notify = false
+			}
+			r.ForceTUI = c.Bool("tui")
+			if m := c.String("model"); m != "" {
+				r.SetModel(m)
+			}
+
+			return r.Plan(c.Args().Get(0))
 		},
 	}
 }
@@ -404,7 +722,9 @@ func groupCommand() *cli.Command {
 				ArgsUsage:    "<group-name>",
 				BashComplete: completeGroups,
 				Description: "Runs all pending tasks in the named group in alphabetical order. " +
-					"Each task gets its own cloned work directory. Stops on the first error.",
+					"Each task gets its own cloned work directory. Stops on the first error. " +
+					"Before starting, prints an estimate of the total run time (and cost, when known) " +
+					"from past runs in record.json and asks for confirmation.",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    "no-auto-accept",
@@ -430,6 +750,11 @@ func groupCommand() *cli.Command {
 						Name:  "model",
 						Usage: "Override the Claude model",
 					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Skip the task list and estimate confirmation",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
@@ -439,6 +764,21 @@ func groupCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
+
+					est, err := r.EstimateGroup(c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Tasks, in order:\n")
+					for _, name := range est.Names {
+						fmt.Printf("  %s\n", name)
+					}
+					fmt.Printf("Estimate: %s\n", est)
+					if !assumeYes(c) && isatty.IsTerminal(os.Stdin.Fd()) && !runner.Confirm("Proceed?") {
+						fmt.Println("Aborted.")
+						return nil
+					}
+
 					r.AutoAccept = true
 					r.PlanMode = true
 					r.Notify = true
@@ -453,7 +793,7 @@ func groupCommand() *cli.Command {
 					}
 					r.ForceTUI = c.Bool("tui")
 					if m := c.String("model"); m != "" {
-						r.Model = m
+						r.SetModel(m)
 					}
 					return r.RunGroup(c.Args().Get(0))
 				},
@@ -514,7 +854,7 @@ func groupCommand() *cli.Command {
 					}
 					r.ForceTUI = c.Bool("tui")
 					if m := c.String("model"); m != "" {
-						r.Model = m
+						r.SetModel(m)
 					}
 					return r.MergeGroup(c.Args().Get(0))
 				},
@@ -528,13 +868,84 @@ type statusRunning struct {
 	PID    int    `json:"pid" yaml:"pid"`
 }
 
+// statusBranch describes a task branch as seen from its work directory.
+type statusBranch struct {
+	Branch       string `json:"branch" yaml:"branch"`
+	Ahead        int    `json:"ahead" yaml:"ahead"`   // commits not on origin's default branch
+	Behind       int    `json:"behind" yaml:"behind"` // origin default-branch commits missing from the branch
+	LastSHA      string `json:"last_sha" yaml:"last_sha"`
+	LastCommit   string `json:"last_commit" yaml:"last_commit"` // age of the last commit, e.g. "3h ago"
+	RemoteExists bool   `json:"remote_exists" yaml:"remote_exists"`
+}
+
+// statusMilestone summarizes an undelivered milestone.
+type statusMilestone struct {
+	Due      string `json:"due" yaml:"due"`
+	Kept     int    `json:"kept" yaml:"kept"`         // promises whose task is completed
+	Promises int    `json:"promises" yaml:"promises"` // promises in the milestone file
+	Overdue  bool   `json:"overdue" yaml:"overdue"`
+}
+
 type statusOutput struct {
-	Running   map[string]statusRunning `json:"running,omitempty" yaml:"running,omitempty"`
-	Pending   []string                 `json:"pending,omitempty" yaml:"pending,omitempty"`
-	Review    []string                 `json:"review,omitempty" yaml:"review,omitempty"`
-	Merge     []string                 `json:"merge,omitempty" yaml:"merge,omitempty"`
-	Completed []string                 `json:"completed,omitempty" yaml:"completed,omitempty"`
-	Abandoned []string                 `json:"abandoned,omitempty" yaml:"abandoned,omitempty"`
+	Running    map[string]statusRunning `json:"running,omitempty" yaml:"running,omitempty"`
+	Pending    []string                 `json:"pending,omitempty" yaml:"pending,omitempty"`
+	Review     []string                 `json:"review,omitempty" yaml:"review,omitempty"`
+	Merge      []string                 `json:"merge,omitempty" yaml:"merge,omitempty"`
+	Completed  []string                 `json:"completed,omitempty" yaml:"completed,omitempty"`
+	Abandoned  []string                 `json:"abandoned,omitempty" yaml:"abandoned,omitempty"`
+	Branches   map[string]statusBranch  `json:"branches,omitempty" yaml:"branches,omitempty"`
+	Reviewers  map[string]string        `json:"reviewers,omitempty" yaml:"reviewers,omitempty"`
+	Stale      map[string]string        `json:"stale,omitempty" yaml:"stale,omitempty"`
+	Milestones []statusMilestone        `json:"milestones,omitempty" yaml:"milestones,omitempty"`
+}
+
+// branchStatus computes divergence details for the task branch checked out
+// in workDir, relative to origin's default branch as of the last fetch:
+// defaultBranch, default_branch from hydra.yml, if set, or else the one
+// origin reports. It returns false if workDir is not a git work directory.
+func branchStatus(workDir, branch, defaultBranch string, now time.Time) (statusBranch, bool) {
+	if !repo.IsGitRepo(workDir) {
+		return statusBranch{}, false
+	}
+	taskRepo := &repo.Repo{Dir: workDir}
+
+	sb := statusBranch{Branch: branch, RemoteExists: taskRepo.RemoteBranchExists(branch)}
+
+	sha, when, err := taskRepo.CommitInfo("HEAD")
+	if err != nil {
+		return statusBranch{}, false
+	}
+	sb.LastSHA = sha[:12]
+	sb.LastCommit = formatAge(now.Sub(when))
+
+	defaults := []string{"origin/main", "origin/master"}
+	if head, ok := taskRepo.OriginHead(); ok {
+		defaults = append([]string{"origin/" + head}, defaults...)
+	}
+	if defaultBranch != "" {
+		defaults = []string{"origin/" + defaultBranch}
+	}
+	for _, def := range defaults {
+		if ahead, behind, err := taskRepo.AheadBehind("HEAD", def); err == nil {
+			sb.Ahead, sb.Behind = ahead, behind
+			break
+		}
+	}
+	return sb, true
+}
+
+// formatAge renders a duration as a coarse "N<unit> ago" string.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
 }
 
 // MarshalYAML quotes string values that start with a digit so the chroma YAML
@@ -570,7 +981,24 @@ func statusCommand() *cli.Command {
 		Description: "Outputs a structured document with tasks grouped by state " +
 			"(running, pending, review, merge, completed, abandoned). " +
 			"Running tasks are keyed by name with 'action' and 'pid' fields. " +
+			"Tasks with a work directory also get a 'branches' entry with " +
+			"ahead/behind counts against origin's default branch, the last " +
+			"commit SHA and age, and whether the branch exists on the remote " +
+			"(as of the last fetch). Reviewers assigned with 'hydra review assign' " +
+			"are listed under 'reviewers'. Pending and review tasks past their " +
+			"'expires:' front matter date, or unchanged for longer than stale_after " +
+			"in hydra.yml, are listed under 'stale' with the reason. Undelivered " +
+			"milestones are listed under 'milestones', oldest first, with their due " +
+			"date, how many of their promises are kept, and whether they are overdue. " +
 			"Default format is YAML; pass -j/--json for JSON.\n\n" +
+			"With --task <name>, shows one task in detail instead: its state, work " +
+			"directory, branch with ahead/behind counts, the process holding its lock, " +
+			"reviewer, originating issue or milestone, last run duration, and its " +
+			"record.json history.\n\n" +
+			"With --fail-on, status exits with status 1 when any task is in one of the " +
+			"given states (running, pending, review, or merge), after printing as usual. " +
+			"Use it to gate scripts, e.g. 'hydra status --fail-on running >/dev/null && " +
+			"hydra group run backend'.\n\n" +
 			"When stdout is a TTY, output is syntax-highlighted. Colors are " +
 			"sourced from pywal (~/.cache/wal/colors.json) when available, " +
 			"otherwise a built-in theme is used. Pass --no-color to disable.\n\n" +
@@ -593,8 +1021,25 @@ func statusCommand() *cli.Command {
 				Name:  "no-color",
 				Usage: "Disable syntax highlighting",
 			},
+			&cli.StringFlag{
+				Name:    "task",
+				Aliases: []string{"t"},
+				Usage:   "Show a detailed view of one task",
+			},
+			&cli.StringSliceFlag{
+				Name:  "fail-on",
+				Usage: "Exit with status 1 if any task is in these states: running, pending, review, merge",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			failOn, err := parseFailOn(c.StringSlice("fail-on"))
+			if err != nil {
+				return err
+			}
+			if len(failOn) > 0 && c.String("task") != "" {
+				return errors.New("--fail-on cannot be combined with --task")
+			}
+
 			cfg, err := config.Discover()
 			if err != nil {
 				return fmt.Errorf("loading config: %w", err)
@@ -605,11 +1050,19 @@ func statusCommand() *cli.Command {
 				return err
 			}
 
+			if name := c.String("task"); name != "" {
+				detail, err := taskDetail(dd, projectHydraDir(), workRoot(dd), name, time.Now())
+				if err != nil {
+					return err
+				}
+				return printStatus(c, detail)
+			}
+
 			var out statusOutput
 
 			// Collect running tasks.
 			runningSet := make(map[string]bool)
-			running, err := lock.ReadAll(config.HydraPath("."))
+			running, err := lock.ReadAll(projectHydraDir())
 			if err == nil && len(running) > 0 {
 				out.Running = make(map[string]statusRunning, len(running))
 				for _, rt := range running {
@@ -647,51 +1100,160 @@ func statusCommand() *cli.Command {
 						continue
 					}
 					*ss.dest = append(*ss.dest, label)
+					if ss.state != design.StateReview && ss.state != design.StateMerge {
+						continue
+					}
+					if reviewer := runner.Reviewer(&t); reviewer != "" {
+						if out.Reviewers == nil {
+							out.Reviewers = make(map[string]string)
+						}
+						out.Reviewers[label] = reviewer
+					}
 				}
 				sort.Strings(*ss.dest)
 			}
 
-			var buf bytes.Buffer
-			lang := "yaml"
-			if c.Bool("json") {
-				lang = "json"
-				enc := json.NewEncoder(&buf)
-				enc.SetIndent("", "  ")
-				if err := enc.Encode(out); err != nil {
+			// Collect branch divergence for tasks with work directories.
+			now := time.Now()
+			root := workRoot(dd)
+			defaultBranch := configuredDefaultBranch(dd)
+			for _, state := range []design.TaskState{design.StatePending, design.StateReview, design.StateMerge} {
+				tasks, err := dd.TasksByState(state)
+				if err != nil {
 					return err
 				}
-			} else {
-				if err := yaml.NewEncoder(&buf).Encode(out); err != nil {
-					return err
+				for _, t := range tasks {
+					wd := filepath.Join(root, t.Name)
+					label := t.Name
+					if t.Group != "" {
+						wd = filepath.Join(root, t.Group, t.Name)
+						label = t.Group + "/" + t.Name
+					}
+					sb, ok := branchStatus(wd, t.BranchName(), defaultBranch, now)
+					if !ok {
+						continue
+					}
+					if out.Branches == nil {
+						out.Branches = make(map[string]statusBranch)
+					}
+					out.Branches[label] = sb
 				}
 			}
 
-			if !c.Bool("no-color") && isatty.IsTerminal(os.Stdout.Fd()) {
-				lexer := lexers.Get(lang)
-				if lexer == nil {
-					lexer = lexers.Fallback
-				}
-				lexer = chroma.Coalesce(lexer)
-				formatter := formatters.Get("terminal256")
-				style := tui.LoadTheme().ChromaStyle()
-				iterator, err := lexer.Tokenise(nil, buf.String())
-				if err != nil {
-					return err
+			stale, err := staleTasks(dd, now)
+			if err != nil {
+				return err
+			}
+			for label, st := range stale {
+				if out.Stale == nil {
+					out.Stale = make(map[string]string)
 				}
-				return formatter.Format(os.Stdout, style, iterator)
+				out.Stale[label] = st.Reason()
 			}
-			_, err = buf.WriteTo(os.Stdout)
-			return err
+
+			if out.Milestones, err = milestoneStatus(dd, now); err != nil {
+				return err
+			}
+
+			if err := printStatus(c, out); err != nil {
+				return err
+			}
+			if found := statusFailures(out, failOn); found != "" {
+				return cli.Exit("status: "+found, 1)
+			}
+			return nil
 		},
 	}
 }
 
+// failOnStates are the states hydra status --fail-on accepts.
+var failOnStates = []string{"running", "pending", "review", "merge"}
+
+// parseFailOn validates the states given to --fail-on, which may be
+// repeated or comma-separated.
+func parseFailOn(values []string) ([]string, error) {
+	var states []string
+	for _, v := range values {
+		for state := range strings.SplitSeq(v, ",") {
+			state = strings.TrimSpace(state)
+			if !slices.Contains(failOnStates, state) {
+				return nil, fmt.Errorf("invalid --fail-on state %q; use %s", state, strings.Join(failOnStates, ", "))
+			}
+			if !slices.Contains(states, state) {
+				states = append(states, state)
+			}
+		}
+	}
+	return states, nil
+}
+
+// statusFailures describes the tasks in out that are in the given states,
+// such as "2 running, 1 merge". It is empty if there are none.
+func statusFailures(out statusOutput, states []string) string {
+	var found []string
+	for _, state := range states {
+		var n int
+		switch state {
+		case "running":
+			n = len(out.Running)
+		case "pending":
+			n = len(out.Pending)
+		case "review":
+			n = len(out.Review)
+		case "merge":
+			n = len(out.Merge)
+		}
+		if n > 0 {
+			found = append(found, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	return strings.Join(found, ", ")
+}
+
+// printStatus writes v as YAML, or JSON with --json, syntax-highlighted when
+// stdout is a terminal and --no-color is not set.
+func printStatus(c *cli.Context, v any) error {
+	var buf bytes.Buffer
+	lang := "yaml"
+	if c.Bool("json") {
+		lang = "json"
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	} else {
+		if err := yaml.NewEncoder(&buf).Encode(v); err != nil {
+			return err
+		}
+	}
+
+	if !c.Bool("no-color") && isatty.IsTerminal(os.Stdout.Fd()) {
+		lexer := lexers.Get(lang)
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+		lexer = chroma.Coalesce(lexer)
+		formatter := formatters.Get("terminal256")
+		style := tui.LoadTheme().ChromaStyle()
+		iterator, err := lexer.Tokenise(nil, buf.String())
+		if err != nil {
+			return err
+		}
+		return formatter.Format(os.Stdout, style, iterator)
+	}
+	_, err := buf.WriteTo(os.Stdout)
+	return err
+}
+
 func listCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "list",
 		Usage: "List available pending tasks",
 		Description: "Shows all pending tasks from the design directory's tasks/ folder, " +
-			"including grouped tasks displayed as group/name.",
+			"including grouped tasks displayed as group/name. Tasks past their 'expires:' " +
+			"front matter date, or unchanged for longer than stale_after in hydra.yml, " +
+			"are flagged with the reason, e.g. '[expired 2026-05-01]' or '[idle 45d]'.",
 		Action: func(_ *cli.Context) error {
 			cfg, err := config.Discover()
 			if err != nil {
@@ -713,6 +1275,11 @@ func listCommand() *cli.Command {
 				return nil
 			}
 
+			stale, err := staleTasks(dd, time.Now())
+			if err != nil {
+				return err
+			}
+
 			var labels []string
 			for _, t := range tasks {
 				label := t.Name
@@ -723,6 +1290,10 @@ func listCommand() *cli.Command {
 			}
 			sort.Strings(labels)
 			for _, label := range labels {
+				if st, ok := stale[label]; ok {
+					fmt.Printf("%s  [%s]\n", label, st.Reason())
+					continue
+				}
 				fmt.Println(label)
 			}
 
@@ -734,23 +1305,33 @@ func listCommand() *cli.Command {
 func syncCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "sync",
-		Usage: "Import open issues from GitHub or Gitea as design tasks",
+		Usage: "Import open issues from GitHub, Gitea, Forgejo, or Bitbucket as design tasks",
 		Description: "Fetches open issues from the source repository's issue tracker and " +
 			"creates task files under tasks/issues/. Existing issues (matched by number) " +
-			"are skipped. Supports both GitHub and Gitea; the API type is auto-detected " +
-			"from the remote URL or can be set via api_type in hydra.yml.",
+			"are skipped. Supports GitHub, Gitea, Forgejo (including codeberg.org), and " +
+			"Bitbucket Cloud; the " +
+			"API type is auto-detected from the remote URL or can be set via api_type " +
+			"in hydra.yml. Comments on the pull requests of tasks in review are copied into " +
+			"their comments files, and tasks whose pull requests were merged or closed outside " +
+			"hydra are flagged. Afterwards, " +
+			"merged hydra/* branches on origin with no matching task are offered for deletion.",
 		Flags: []cli.Flag{
 			&cli.StringSliceFlag{
 				Name:  "label",
 				Usage: "Filter issues by label (can be specified multiple times)",
 			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Delete orphaned hydra branches without prompting",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			r, err := newRunner()
 			if err != nil {
 				return err
 			}
-			return r.Sync(c.StringSlice("label"))
+			return r.Sync(c.StringSlice("label"), assumeYes(c))
 		},
 	}
 }
@@ -760,10 +1341,31 @@ type stateOps struct {
 	list func(r *runner.Runner) error
 	view func(r *runner.Runner, name string) error
 	edit func(r *runner.Runner, name, editor string) error
-	rm   func(r *runner.Runner, name string) error
+	rm   func(r *runner.Runner, name string, opts runner.AbandonOpts) error
 	run  func(r *runner.Runner, name string) error
 }
 
+// abandonFlags returns the flags for commands that move a task to abandoned.
+func abandonFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "Abandon without asking, deleting the work directory and remote branch and closing the issue",
+		},
+		&cli.BoolFlag{
+			Name:    "keep",
+			Aliases: []string{"k"},
+			Usage:   "Only move the task to abandoned; skip all cleanup",
+		},
+	}
+}
+
+// abandonOpts builds runner.AbandonOpts from the flags in abandonFlags.
+func abandonOpts(c *cli.Context) runner.AbandonOpts {
+	return runner.AbandonOpts{Yes: assumeYes(c), Keep: c.Bool("keep")}
+}
+
 // stateCommand builds a CLI command with list/view/edit/rm/run subcommands
 // for a given task state (review, merge, etc.).
 func stateCommand(name, usage, description, runUsage string, states []design.TaskState, ops stateOps) *cli.Command {
@@ -825,6 +1427,7 @@ func stateCommand(name, usage, description, runUsage string, states []design.Tas
 				Usage:        "Move a task from " + name + " to abandoned",
 				ArgsUsage:    "<task-name>",
 				BashComplete: complete,
+				Flags:        abandonFlags(),
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return fmt.Errorf("usage: hydra %s rm <task-name>", name)
@@ -833,7 +1436,7 @@ func stateCommand(name, usage, description, runUsage string, states []design.Tas
 					if err != nil {
 						return err
 					}
-					return ops.rm(r, c.Args().Get(0))
+					return ops.rm(r, c.Args().Get(0), abandonOpts(c))
 				},
 			},
 			{
@@ -866,11 +1469,20 @@ func stateCommand(name, usage, description, runUsage string, states []design.Tas
 						Name:  "model",
 						Usage: "Override the Claude model",
 					},
+					forceSetupFlag(),
 				},
 				Action: func(c *cli.Context) error {
-					if c.NArg() != 1 {
+					if c.NArg() > 1 {
 						return fmt.Errorf("usage: hydra %s run <task-name>", name)
 					}
+					taskName := c.Args().Get(0)
+					if taskName == "" {
+						var err error
+						taskName, err = pickTask(runUsage, fmt.Errorf("usage: hydra %s run <task-name>", name), states...)
+						if err != nil || taskName == "" {
+							return err
+						}
+					}
 					r, err := newRunner()
 					if err != nil {
 						return err
@@ -889,22 +1501,118 @@ func stateCommand(name, usage, description, runUsage string, states []design.Tas
 					}
 					r.ForceTUI = c.Bool("tui")
 					if m := c.String("model"); m != "" {
-						r.Model = m
+						r.SetModel(m)
 					}
-					return ops.run(r, c.Args().Get(0))
+					r.ForceSetup = c.Bool("force-setup")
+					return ops.run(r, taskName)
 				},
 			},
 		},
 	}
 }
 
-// newRunner creates a runner from discovered config.
-func newRunner() (*runner.Runner, error) {
-	cfg, err := config.Discover()
+// newRunner creates a runner from discovered config.
+func newRunner() (*runner.Runner, error) {
+	cfg, err := config.Discover()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	r, err := runner.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.WorkRoot = os.Getenv(workDirEnv)
+	r.WaitInUse = os.Getenv(waitInUseEnv) != ""
+	r.RebaseOnto = os.Getenv(rebaseOntoEnv) != ""
+	return r, nil
+}
+
+// workDirEnv carries --work-dir to every command, and to the hydra
+// processes they start, such as detached runs.
+const workDirEnv = "HYDRA_WORK_DIR"
+
+// waitInUseEnv carries --wait-in-use the same way.
+const waitInUseEnv = "HYDRA_WAIT_IN_USE"
+
+// rebaseOntoEnv carries --rebase-onto the same way.
+const rebaseOntoEnv = "HYDRA_REBASE_ONTO"
+
+// workRoot returns where work directories live for commands without a
+// runner: --work-dir, else work_dir from hydra.yml, else .hydra/work.
+func workRoot(dd *design.Dir) string {
+	if dir := os.Getenv(workDirEnv); dir != "" {
+		return dir
+	}
+	cmds, _ := taskrun.Load(filepath.Join(dd.Path, "hydra.yml")) // nil, and the default, without a usable hydra.yml
+	return runner.WorkRoot(projectBase(), cmds)
+}
+
+// configuredDefaultBranch returns default_branch from hydra.yml, or "" if it
+// isn't set.
+func configuredDefaultBranch(dd *design.Dir) string {
+	cmds, err := taskrun.Load(filepath.Join(dd.Path, "hydra.yml"))
+	if err != nil || cmds == nil {
+		return ""
+	}
+	return cmds.DefaultBranch
+}
+
+// openDesignDir opens the design directory for commands that change it
+// without a runner, enabling design_autocommit from hydra.yml.
+func openDesignDir(cfg *config.Config) (*design.Dir, error) {
+	dd, err := design.NewDir(cfg.DesignDir)
+	if err != nil {
+		return nil, err
+	}
+	cmds, err := taskrun.Load(filepath.Join(dd.Path, "hydra.yml"))
+	if err == nil && cmds.DesignAutoCommit {
+		dd.EnableAutoCommit(cmds.DesignAutoPush)
+	}
+	return dd, nil
+}
+
+// staleTasks returns the expired and stale pending and review tasks, keyed
+// by task label, using stale_after from hydra.yml.
+func staleTasks(dd *design.Dir, now time.Time) (map[string]design.StaleTask, error) {
+	var staleAfter time.Duration
+	if cmds, err := taskrun.Load(filepath.Join(dd.Path, "hydra.yml")); err == nil && cmds.StaleAfter != nil {
+		staleAfter = cmds.StaleAfter.Duration
+	}
+	stale, err := dd.StaleTasks(staleAfter, now)
+	if err != nil {
+		return nil, err
+	}
+	byLabel := make(map[string]design.StaleTask, len(stale))
+	for _, st := range stale {
+		byLabel[st.Task.Label()] = st
+	}
+	return byLabel, nil
+}
+
+// milestoneStatus summarizes the undelivered milestones, oldest first. A
+// milestone is overdue once its date has passed.
+func milestoneStatus(dd *design.Dir, now time.Time) ([]statusMilestone, error) {
+	milestones, err := dd.Milestones()
 	if err != nil {
-		return nil, fmt.Errorf("loading config: %w", err)
+		return nil, err
+	}
+	slices.SortFunc(milestones, func(a, b design.Milestone) int { return strings.Compare(a.Date, b.Date) })
+
+	today := now.Format("2006-01-02")
+	var out []statusMilestone
+	for _, m := range milestones {
+		result, err := dd.VerifyMilestone(&m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, statusMilestone{
+			Due:      m.Date,
+			Kept:     len(result.Promises) - len(result.Missing) - len(result.Incomplete),
+			Promises: len(result.Promises),
+			Overdue:  m.Date < today,
+		})
 	}
-	return runner.New(cfg)
+	return out, nil
 }
 
 func reviewCommand() *cli.Command {
@@ -966,6 +1674,7 @@ func reviewCommand() *cli.Command {
 				Usage:        "Move a task from review to abandoned",
 				ArgsUsage:    "<task-name>",
 				BashComplete: complete,
+				Flags:        abandonFlags(),
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return errors.New("usage: hydra review rm <task-name>")
@@ -974,7 +1683,7 @@ func reviewCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
-					return r.ReviewRemove(c.Args().Get(0))
+					return r.ReviewRemove(c.Args().Get(0), abandonOpts(c))
 				},
 			},
 			{
@@ -1012,11 +1721,36 @@ func reviewCommand() *cli.Command {
 						Aliases: []string{"R"},
 						Usage:   "Skip rebasing onto origin/main before reviewing",
 					},
+					&cli.BoolFlag{
+						Name:  "precheck",
+						Usage: "Trial-rebase onto origin/main first and report conflicted files before starting Claude",
+					},
+					&cli.StringSliceFlag{
+						Name:  "focus",
+						Usage: "Review only the changes under this path, relative to the repository root (repeatable)",
+					},
+					&cli.StringFlag{
+						Name:  "persona",
+						Usage: "Review as the reviewer persona in reviewers/<name>.md in the design directory",
+					},
+					&cli.BoolFlag{
+						Name:  "by-commit",
+						Usage: "Include each commit's message and diff so Claude checks the messages against their changes",
+					},
+					forceSetupFlag(),
 				},
 				Action: func(c *cli.Context) error {
-					if c.NArg() != 1 {
+					if c.NArg() > 1 {
 						return errors.New("usage: hydra review run <task-name>")
 					}
+					taskName := c.Args().Get(0)
+					if taskName == "" {
+						var err error
+						taskName, err = pickTask("Review a task", errors.New("usage: hydra review run <task-name>"), design.StateReview)
+						if err != nil || taskName == "" {
+							return err
+						}
+					}
 					r, err := newRunner()
 					if err != nil {
 						return err
@@ -1035,12 +1769,17 @@ func reviewCommand() *cli.Command {
 					}
 					r.ForceTUI = c.Bool("tui")
 					if m := c.String("model"); m != "" {
-						r.Model = m
+						r.SetModel(m)
 					}
 					if c.Bool("no-rebase") {
 						r.Rebase = false
 					}
-					return r.Review(c.Args().Get(0))
+					r.Precheck = c.Bool("precheck")
+					r.Focus = c.StringSlice("focus")
+					r.Persona = c.String("persona")
+					r.ByCommit = c.Bool("by-commit")
+					r.ForceSetup = c.Bool("force-setup")
+					return r.Review(taskName)
 				},
 			},
 			{
@@ -1048,6 +1787,12 @@ func reviewCommand() *cli.Command {
 				Usage:        "Show git diff for all changes on the task's branch",
 				ArgsUsage:    "<task-name>",
 				BashComplete: complete,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "by-commit",
+						Usage: "Show each commit with its message and its own diff, through $PAGER",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					if c.NArg() != 1 {
 						return errors.New("usage: hydra review diff <task-name>")
@@ -1056,9 +1801,139 @@ func reviewCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
+					r.ByCommit = c.Bool("by-commit")
 					return r.ReviewDiff(c.Args().Get(0))
 				},
 			},
+			{
+				Name:         "pr",
+				Usage:        "Open a pull request for a task's branch",
+				ArgsUsage:    "<task-name>",
+				BashComplete: complete,
+				Description: "Opens a pull request from the task's branch on origin into the branch it merges into, " +
+					"titled with the task's name and described by the task. " +
+					"Supported on Bitbucket Cloud.",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra review pr <task-name>")
+					}
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					return r.ReviewPR(c.Args().Get(0))
+				},
+			},
+			{
+				Name:         "assign",
+				Usage:        "Assign a human reviewer to a task",
+				ArgsUsage:    "<task-name> <reviewer>",
+				BashComplete: complete,
+				Description: "Records the reviewer in the task's front matter. " +
+					"Assignments are shown by 'hydra status'.",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return errors.New("usage: hydra review assign <task-name> <reviewer>")
+					}
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					return r.ReviewAssign(c.Args().Get(0), c.Args().Get(1))
+				},
+			},
+			{
+				Name:         "requeue",
+				Usage:        "Move a task from review back to pending to run it again",
+				ArgsUsage:    "<task-name>",
+				BashComplete: complete,
+				Description: "Moves the task back under tasks/, in its group. By default the attempt " +
+					"is thrown away: the work directory is removed and the task's branch is " +
+					"deleted locally and from origin, so the next 'hydra run' starts fresh.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "note",
+						Aliases: []string{"n"},
+						Usage:   "Open the editor for a note on what went wrong, appended to the task",
+					},
+					&cli.BoolFlag{
+						Name:    "keep-branch",
+						Aliases: []string{"k"},
+						Usage:   "Keep the work directory and branch so the next run builds on them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra review requeue <task-name>")
+					}
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					opts := runner.RequeueOpts{KeepBranch: c.Bool("keep-branch")}
+					if c.Bool("note") {
+						if opts.Editor, err = resolveEditor(); err != nil {
+							return err
+						}
+					}
+					return r.ReviewRequeue(c.Args().Get(0), opts)
+				},
+			},
+			{
+				Name:         "comment",
+				Usage:        "Leave feedback for a task's next review session",
+				ArgsUsage:    "<task-name>",
+				BashComplete: complete,
+				Description: "Opens the editor for a note, which is appended to " +
+					"state/review/<task-name>.comments.md. The next 'hydra review run' " +
+					"includes the notes as reviewer feedback and then clears them.",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra review comment <task-name>")
+					}
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					editor, err := resolveEditor()
+					if err != nil {
+						return err
+					}
+					return r.ReviewComment(c.Args().Get(0), editor)
+				},
+			},
+			{
+				Name:         "handoff",
+				Usage:        "Bundle a task for review by a teammate",
+				ArgsUsage:    "<task-name>",
+				BashComplete: complete,
+				Description: "Writes a single markdown file with the task document, the diff " +
+					"against the default branch, and any saved session transcripts, " +
+					"for sending to a teammate. The file is written to <task-name>-handoff.md " +
+					"in the current directory unless -o is given.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Path of the handoff file",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra review handoff [-o file] <task-name>")
+					}
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					path, err := r.ReviewHandoff(c.Args().Get(0), c.String("output"))
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Wrote handoff to %s\n", path)
+					return nil
+				},
+			},
 			{
 				Name:         "dev",
 				Usage:        "Run the dev command from hydra.yml in the task's work directory",
@@ -1123,6 +1998,10 @@ func testCommand() *cli.Command {
 				Aliases: []string{"R"},
 				Usage:   "Skip rebasing onto origin/main before testing",
 			},
+			&cli.StringFlag{
+				Name:  "only",
+				Usage: "Focus the session on tests matching a package, path, or name pattern",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			if c.NArg() != 1 {
@@ -1148,11 +2027,12 @@ func testCommand() *cli.Command {
 			}
 			r.ForceTUI = c.Bool("tui")
 			if m := c.String("model"); m != "" {
-				r.Model = m
+				r.SetModel(m)
 			}
 			if c.Bool("no-rebase") {
 				r.Rebase = false
 			}
+			r.TestOnly = c.String("only")
 
 			return r.Test(c.Args().Get(0))
 		},
@@ -1166,10 +2046,18 @@ func cleanCommand() *cli.Command {
 		ArgsUsage:    "<task-name>",
 		BashComplete: completeAllTasks,
 		Description: "Runs the clean command defined in hydra.yml in the task's work directory, " +
-			"regardless of which state the task is in.",
+			"regardless of which state the task is in. With --all, runs it in every " +
+			"work directory instead.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Run the clean command in every work directory",
+			},
+		},
 		Action: func(c *cli.Context) error {
-			if c.NArg() != 1 {
-				return errors.New("usage: hydra clean <task-name>")
+			all := c.Bool("all")
+			if (all && c.NArg() != 0) || (!all && c.NArg() != 1) {
+				return errors.New("usage: hydra clean <task-name> | hydra clean --all")
 			}
 
 			r, err := newRunner()
@@ -1177,18 +2065,22 @@ func cleanCommand() *cli.Command {
 				return err
 			}
 
+			if all {
+				return r.CleanAll()
+			}
 			return r.Clean(c.Args().Get(0))
 		},
 	}
 }
 
 func mergeCommand() *cli.Command {
-	return stateCommand(
+	states := []design.TaskState{design.StateReview, design.StateMerge}
+	cmd := stateCommand(
 		"merge",
 		"Manage and run merge workflows on reviewed tasks",
 		"CRUD operations and merge workflow for tasks in review or merge state.",
 		"Run the merge workflow (rebase, test, merge, push)",
-		[]design.TaskState{design.StateReview, design.StateMerge},
+		states,
 		stateOps{
 			list: (*runner.Runner).MergeList,
 			view: (*runner.Runner).MergeView,
@@ -1197,6 +2089,57 @@ func mergeCommand() *cli.Command {
 			run:  (*runner.Runner).Merge,
 		},
 	)
+	cmd.Subcommands = append(cmd.Subcommands, &cli.Command{
+		Name:         "check",
+		Usage:        "Dry-run the merge workflow and report conflicts and failing checks",
+		ArgsUsage:    "<task-name>",
+		BashComplete: completeTasks(states...),
+		Description: "Fetches origin, rebases a copy of the task's branch onto the default " +
+			"branch in a throwaway worktree, and runs the test and lint commands there if " +
+			"the rebase is clean. Reports conflicting files, failing checks, and how many " +
+			"commits the branch is ahead and behind. The task's work directory, the record, " +
+			"and the task's state are never changed. Exits nonzero if the task would not " +
+			"merge cleanly.",
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra merge check <task-name>")
+			}
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			return r.MergeCheck(c.Args().Get(0))
+		},
+	})
+	cmd.Subcommands = append(cmd.Subcommands, &cli.Command{
+		Name:         "rollback",
+		Usage:        "Revert a completed task's merge and move the task back to review",
+		ArgsUsage:    "<task-name>",
+		BashComplete: completeTasks(design.StateCompleted),
+		Description: "Reverts the commits a merge put on the default branch and pushes the " +
+			"reverts. With --reset, moves the default branch back to before the merge and " +
+			"force-pushes it instead, which is only allowed while nothing has landed on top " +
+			"of the merge. The task's branch is pushed again with its changes, the task moves " +
+			"from completed back to review, the rollback is recorded in record.json, and the " +
+			"task's issue, if any, is reopened.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "reset",
+				Usage: "Reset and force-push the default branch instead of reverting",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra merge rollback [--reset] <task-name>")
+			}
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			return r.MergeRollback(c.Args().Get(0), c.Bool("reset"))
+		},
+	})
+	return cmd
 }
 
 // autonomousFlags returns the common flags for autonomous commands (reconcile, verify).
@@ -1226,6 +2169,16 @@ func autonomousFlags() []cli.Flag {
 			Name:  "model",
 			Usage: "Override the Claude model",
 		},
+		forceSetupFlag(),
+	}
+}
+
+// forceSetupFlag returns the flag that reruns the setup command from
+// hydra.yml in a work directory that has already been set up.
+func forceSetupFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "force-setup",
+		Usage: "Run the setup command from hydra.yml again, even if the work directory was already set up",
 	}
 }
 
@@ -1250,8 +2203,9 @@ func configureAutonomousRunner(c *cli.Context) (*runner.Runner, error) {
 	}
 	r.ForceTUI = c.Bool("tui")
 	if m := c.String("model"); m != "" {
-		r.Model = m
+		r.SetModel(m)
 	}
+	r.ForceSetup = c.Bool("force-setup")
 
 	return r, nil
 }
@@ -1262,13 +2216,29 @@ func reconcileCommand() *cli.Command {
 		Usage: "Merge completed tasks into functional.md and clean up",
 		Description: "Reads all completed task documents, uses Claude to synthesize " +
 			"their requirements into functional.md, then removes the completed task files.",
-		Flags: autonomousFlags(),
+		Flags: append(autonomousFlags(),
+			&cli.StringFlag{
+				Name:  "group",
+				Usage: "Only reconcile completed tasks in this group",
+			},
+			&cli.IntFlag{
+				Name:    "batch",
+				Aliases: []string{"b"},
+				Usage:   "Reconcile N tasks per Claude session, saving functional.md between batches",
+			},
+		),
 		Action: func(c *cli.Context) error {
+			if c.Int("batch") < 0 {
+				return errors.New("--batch must not be negative")
+			}
 			r, err := configureAutonomousRunner(c)
 			if err != nil {
 				return err
 			}
-			return r.Reconcile()
+			return r.Reconcile(runner.ReconcileOpts{
+				Group:     c.String("group"),
+				BatchSize: c.Int("batch"),
+			})
 		},
 	}
 }
@@ -1278,13 +2248,28 @@ func verifyCommand() *cli.Command {
 		Name:  "verify",
 		Usage: "Verify all functional.md requirements against the codebase",
 		Description: "Uses Claude to check that every requirement in functional.md " +
-			"is implemented and tests pass on the current main branch.",
-		Flags: autonomousFlags(),
+			"is implemented and tests pass on the current main branch. With --report, " +
+			"the outcome and the commit verified are saved to state/verify.json for " +
+			"'hydra milestone deliver --verified'. With --since, only the requirements " +
+			"whose text changed in functional.md since a commit or date, or that mention " +
+			"files changed on main since then, are verified.",
+		Flags: append(autonomousFlags(),
+			&cli.BoolFlag{
+				Name:  "report",
+				Usage: "Save the outcome to state/verify.json",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Only verify requirements touched since a commit or date (YYYY-MM-DD)",
+			},
+		),
 		Action: func(c *cli.Context) error {
 			r, err := configureAutonomousRunner(c)
 			if err != nil {
 				return err
 			}
+			r.VerifyReport = c.Bool("report")
+			r.VerifySince = c.String("since")
 			return r.Verify()
 		},
 	}
@@ -1295,22 +2280,42 @@ func fixCommand() *cli.Command {
 		Name:  "fix",
 		Usage: "Scan for and fix project issues",
 		Description: "Checks for duplicate task names, stale locks, work directories on " +
-			"wrong branches, remote URL mismatches, missing state directories, and orphaned " +
-			"work directories. Reports all issues found, then prompts for confirmation " +
-			"before applying fixes. Use -y to skip confirmation.",
+			"wrong branches, remote URL mismatches, missing state directories, orphaned " +
+			"work directories, hydra/* branches on origin with no task, and tasks past their " +
+			"'expires:' date, which it offers to abandon. Reports all issues found, then prompts for confirmation " +
+			"before applying fixes. Use -y to skip confirmation. Use --check to only " +
+			"report issues, one per line as kind<TAB>subject<TAB>description, and exit " +
+			"nonzero if any are found.",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "yes",
 				Aliases: []string{"y"},
 				Usage:   "Skip confirmation prompt and apply fixes immediately",
 			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "Report issues without fixing anything; exit 1 if any are found",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			if c.Bool("check") && assumeYes(c) {
+				return errors.New("--check and --yes cannot be used together")
+			}
 			r, err := newRunner()
 			if err != nil {
 				return err
 			}
-			return r.Fix(c.Bool("yes"))
+			if c.Bool("check") {
+				n, err := r.FixCheck()
+				if err != nil {
+					return err
+				}
+				if n > 0 {
+					return cli.Exit(fmt.Sprintf("%d issue(s) found", n), 1)
+				}
+				return nil
+			}
+			return r.Fix(assumeYes(c))
 		},
 	}
 }
@@ -1318,12 +2323,16 @@ func fixCommand() *cli.Command {
 func notifyCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "notify",
-		Usage:     "Send a desktop notification",
+		Usage:     "Send a notification",
 		ArgsUsage: "<message>",
-		Description: "Sends a desktop notification with the given message. " +
-			"If a notify command is configured in hydra.yml, it is executed with " +
-			"the title and message as arguments. Otherwise, uses the platform's " +
-			"native notification API (D-Bus on Linux, osascript on macOS).\n\n" +
+		Description: "Sends a notification with the given message to the default channels " +
+			"from hydra.yml (every configured one, if none are set), or to the channels " +
+			"given with --channel. The desktop channel runs the notify command from " +
+			"hydra.yml with the title and message as arguments, or uses the platform's " +
+			"native notification API (D-Bus on Linux, osascript on macOS). The slack and " +
+			"webhook channels post to the URLs under notifications in hydra.yml.\n\n" +
+			"With --test, sends a test notification to every configured channel and " +
+			"reports which succeeded.\n\n" +
 			"Used by Claude during task runs to alert the user when input is needed.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -1332,30 +2341,64 @@ func notifyCommand() *cli.Command {
 				Usage:   "Notification title (defaults to 'hydra')",
 				Value:   "hydra",
 			},
+			&cli.StringSliceFlag{
+				Name:    "channel",
+				Aliases: []string{"c"},
+				Usage:   "Channel to send to: desktop, slack, or webhook (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "test",
+				Usage: "Send a test notification to every configured channel and report the results",
+			},
 		},
 		Action: func(c *cli.Context) error {
-			if c.NArg() < 1 {
-				return errors.New("usage: hydra notify <message>")
-			}
-			message := strings.Join(c.Args().Slice(), " ")
 			title := c.String("title")
+			channels := c.StringSlice("channel")
+
+			// Outside a project, or without hydra.yml, only the desktop
+			// channel is available.
+			r := &runner.Runner{}
+			if cfg, err := config.Discover(); err == nil {
+				if pr, err := runner.New(cfg); err == nil {
+					r = pr
+				}
+			}
 
-			// Check for custom notify command in hydra.yml.
-			cfg, err := config.Discover()
-			if err == nil {
-				r, rErr := runner.New(cfg)
-				if rErr == nil && r.TaskRunner != nil {
-					if handled, nErr := r.TaskRunner.RunNotify(title, message); handled {
-						return nErr
-					}
+			if c.Bool("test") {
+				if len(channels) == 0 {
+					channels = r.NotifyChannels()
 				}
+				return reportNotify(r.SendNotification(c.Context, channels, title, "Test notification from hydra"), true)
 			}
 
-			return notify.Send(title, message)
+			if c.NArg() < 1 {
+				return errors.New("usage: hydra notify [--channel <name>] <message>")
+			}
+			message := strings.Join(c.Args().Slice(), " ")
+			return reportNotify(r.SendNotification(c.Context, channels, title, message), false)
 		},
 	}
 }
 
+// reportNotify prints how sending to each channel went, successes too if
+// verbose, and returns an error if any channel failed.
+func reportNotify(results []runner.NotifyResult, verbose bool) error {
+	failed := 0
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: FAILED: %v\n", res.Channel, res.Err)
+		case verbose:
+			fmt.Printf("%s: ok\n", res.Channel)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notification channel(s) failed", failed, len(results))
+	}
+	return nil
+}
+
 // setTerminalTitle sets the xterm window title to a compact summary
 // including the operation, task name, and PID.
 func setTerminalTitle(c *cli.Context) {
@@ -1363,7 +2406,7 @@ func setTerminalTitle(c *cli.Context) {
 		return
 	}
 	args := c.Args().Slice()
-	if len(args) == 0 || args[0] == "completion" || args[0] == "status" || args[0] == "help" {
+	if len(args) == 0 || args[0] == "completion" || args[0] == "status" || args[0] == "help" || args[0] == "state-tool" {
 		return
 	}
 	// Skip title when any help flag is present.
@@ -1376,6 +2419,17 @@ func setTerminalTitle(c *cli.Context) {
 	fmt.Fprintf(os.Stderr, "\033]0;%s\007", title)
 }
 
+// assumeYes reports whether --yes was given to the command or any command
+// above it, such as the global hydra --yes.
+func assumeYes(c *cli.Context) bool {
+	for _, ctx := range c.Lineage() {
+		if ctx.Bool("yes") {
+			return true
+		}
+	}
+	return false
+}
+
 // parseRunningTask splits a raw lock name like "review:foo" into
 // a display state ("reviewing") and the task name ("foo").
 func parseRunningTask(name string) (state, task string) {
@@ -1383,6 +2437,7 @@ func parseRunningTask(name string) (state, task string) {
 		"review": "reviewing",
 		"merge":  "merging",
 		"test":   "testing",
+		"split":  "splitting",
 	}
 
 	if prefix, rest, ok := strings.Cut(name, ":"); ok {
@@ -1397,7 +2452,7 @@ func milestoneCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "milestone",
 		Usage: "Manage milestones and their promises",
-		Description: "Create, edit, list, verify, repair, and deliver milestones. " +
+		Description: "Create, edit, list, verify, repair, run, and deliver milestones. " +
 			"Each milestone is a date-based markdown file where ## headings are promises. " +
 			"Hydra creates tasks for each promise and tracks their completion.",
 		Subcommands: []*cli.Command{
@@ -1406,6 +2461,7 @@ func milestoneCommand() *cli.Command {
 			milestoneListCommand(),
 			milestoneVerifyCommand(),
 			milestoneRepairCommand(),
+			milestoneRunCommand(),
 			milestoneDeliverCommand(),
 		},
 	}
@@ -1478,7 +2534,7 @@ func milestoneCreateCommand() *cli.Command {
 				return errors.New("empty milestone, aborting")
 			}
 
-			dd, err := design.NewDir(cfg.DesignDir)
+			dd, err := openDesignDir(cfg)
 			if err != nil {
 				return err
 			}
@@ -1522,7 +2578,7 @@ func milestoneEditCommand() *cli.Command {
 				return fmt.Errorf("loading config: %w", err)
 			}
 
-			dd, err := design.NewDir(cfg.DesignDir)
+			dd, err := openDesignDir(cfg)
 			if err != nil {
 				return err
 			}
@@ -1538,7 +2594,11 @@ func milestoneEditCommand() *cli.Command {
 				return err
 			}
 
-			return design.RunEditorOnFile(editor, m.FilePath, os.Stdin, os.Stdout, os.Stderr)
+			if err := design.RunEditorOnFile(editor, m.FilePath, os.Stdin, os.Stdout, os.Stderr); err != nil {
+				return err
+			}
+			dd.Changed([]string{m.FilePath}, "edit milestone %s", m.Date)
+			return nil
 		},
 	}
 }
@@ -1643,7 +2703,7 @@ func milestoneVerifyCommand() *cli.Command {
 				return fmt.Errorf("loading config: %w", err)
 			}
 
-			dd, err := design.NewDir(cfg.DesignDir)
+			dd, err := openDesignDir(cfg)
 			if err != nil {
 				return err
 			}
@@ -1719,7 +2779,7 @@ func milestoneRepairCommand() *cli.Command {
 				return fmt.Errorf("loading config: %w", err)
 			}
 
-			dd, err := design.NewDir(cfg.DesignDir)
+			dd, err := openDesignDir(cfg)
 			if err != nil {
 				return err
 			}
@@ -1758,40 +2818,108 @@ func milestoneRepairCommand() *cli.Command {
 	}
 }
 
+func milestoneRunCommand() *cli.Command {
+	return &cli.Command{
+		Name:         "run",
+		Usage:        "Run, review, and merge all of a milestone's tasks",
+		ArgsUsage:    "<date>",
+		BashComplete: completeMilestones,
+		Description: "Runs each pending task in the milestone's task group and merges it, and merges " +
+			"tasks already in review or merge state. Tasks are taken in the order of their promises " +
+			"in the milestone file. After every merge the milestone is verified and newly kept " +
+			"promises are reported. Stops on the first error.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "no-auto-accept",
+				Aliases: []string{"Y"},
+				Usage:   "Disable auto-accept (prompt for each tool call)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-plan",
+				Aliases: []string{"P"},
+				Usage:   "Disable plan mode (skip plan approval, run fully autonomously)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-notify",
+				Aliases: []string{"N"},
+				Usage:   "Disable desktop notifications when confirmation is needed",
+			},
+			&cli.BoolFlag{
+				Name:    "tui",
+				Aliases: []string{"T"},
+				Usage:   "Force the built-in TUI instead of Claude Code CLI",
+			},
+			&cli.StringFlag{
+				Name:  "model",
+				Usage: "Override the Claude model",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra milestone run <date>")
+			}
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			r.AutoAccept = true
+			r.PlanMode = true
+			r.Notify = true
+			if c.Bool("no-auto-accept") {
+				r.AutoAccept = false
+			}
+			if c.Bool("no-plan") {
+				r.PlanMode = false
+			}
+			if c.Bool("no-notify") {
+				r.Notify = false
+			}
+			r.ForceTUI = c.Bool("tui")
+			if m := c.String("model"); m != "" {
+				r.SetModel(m)
+			}
+			return r.MilestoneRun(c.Args().Get(0))
+		},
+	}
+}
+
 func milestoneDeliverCommand() *cli.Command {
 	return &cli.Command{
 		Name:         "deliver",
 		Usage:        "Mark a milestone as delivered",
 		ArgsUsage:    "<date>",
 		BashComplete: completeMilestones,
-		Description:  "Moves a milestone to the delivered directory.",
+		Description: "Moves a milestone to the delivered directory.\n\n" +
+			"With --verified, the functional requirements are verified first, reusing " +
+			"the report saved by 'hydra verify --report' if it is for the current head " +
+			"of the default branch. Delivery is refused if a failed requirement names " +
+			"one of the milestone's promises; pass --force to deliver anyway.",
+		Flags: append(autonomousFlags(),
+			&cli.BoolFlag{
+				Name:  "verified",
+				Usage: "Refuse delivery unless functional verification passes for the milestone's promises",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "With --verified, deliver even if promises fail verification",
+			},
+		),
 		Action: func(c *cli.Context) error {
 			if c.NArg() != 1 {
 				return errors.New("usage: hydra milestone deliver <date>")
 			}
-
-			cfg, err := config.Discover()
-			if err != nil {
-				return fmt.Errorf("loading config: %w", err)
-			}
-
-			dd, err := design.NewDir(cfg.DesignDir)
-			if err != nil {
-				return err
+			if c.Bool("force") && !c.Bool("verified") {
+				return errors.New("--force only applies with --verified")
 			}
 
-			date := c.Args().Get(0)
-			m, err := dd.FindMilestone(date)
+			r, err := configureAutonomousRunner(c)
 			if err != nil {
 				return err
 			}
-
-			if err := dd.DeliverMilestone(m); err != nil {
-				return err
-			}
-
-			fmt.Printf("Delivered milestone %s\n", date)
-			return nil
+			return r.MilestoneDeliver(c.Args().Get(0), runner.MilestoneDeliverOpts{
+				Verified: c.Bool("verified"),
+				Force:    c.Bool("force"),
+			})
 		},
 	}
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+func approveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "approve",
+		Usage:     "Approve the push of a task run waiting under approve_before_push",
+		ArgsUsage: "<task-name>",
+		Description: "With approve_before_push set in hydra.yml, hydra run waits after " +
+			"Claude commits, showing the diff stat and commit messages, until the push " +
+			"is approved. This approves it from another terminal; the run then pushes " +
+			"the branch and moves the task to review.",
+		BashComplete: completeTaskList(design.StatePending),
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra approve <task-name>")
+			}
+
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			return r.Approve(c.Args().First())
+		},
+	}
+}
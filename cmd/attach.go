@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/platform"
+	"github.com/erikh/hydra/internal/runner"
+	"github.com/urfave/cli/v2"
+)
+
+// attachPollInterval is how often hydra attach checks the log for new
+// output.
+const attachPollInterval = 500 * time.Millisecond
+
+// projectBase returns the base directory of the project holding the
+// working directory, found the way config.Discover finds it, so hydra works
+// from any of the project's subdirectories. Outside of a project it
+// returns ".".
+func projectBase() string {
+	base, err := config.FindBase()
+	if err != nil {
+		return "."
+	}
+	return base
+}
+
+// projectHydraDir returns the project's .hydra directory.
+func projectHydraDir() string {
+	return config.HydraPath(projectBase())
+}
+
+// detachedDir returns the directory holding the logs and PID files of runs
+// started with hydra run --detach.
+func detachedDir() string {
+	return filepath.Join(projectHydraDir(), "detached")
+}
+
+// detachedPath returns the path of a detached run's file with the given
+// extension. Slashes in grouped task names are replaced with "--".
+func detachedPath(task, ext string) string {
+	return filepath.Join(detachedDir(), strings.ReplaceAll(task, "/", "--")+ext)
+}
+
+// detachedPID returns the PID of the detached run of task, and whether that
+// process is still running.
+func detachedPID(task string) (int, bool) {
+	data, err := os.ReadFile(detachedPath(task, ".pid")) //nolint:gosec // path built from hydra's detached dir
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, platform.ProcessAlive(pid)
+}
+
+// detachRun starts hydra run for task in a new background session with no
+// terminal, forwarding the flags that apply to it, and prints its PID and
+// where it logs. The run is headless: tool calls are auto-accepted and plan
+// mode is off, since no one is there to approve them.
+func detachRun(c *cli.Context, task string) error {
+	if pid, running := detachedPID(task); running {
+		return fmt.Errorf("task %q is already running in the background (PID %d); follow it with 'hydra attach %s'", task, pid, task)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the hydra executable: %w", err)
+	}
+	args := []string{"run", "--headless"}
+	if c.Bool("no-notify") {
+		args = append(args, "--no-notify")
+	}
+	if m := c.String("model"); m != "" {
+		args = append(args, "--model", m)
+	}
+	if c.Bool("use-plan") {
+		args = append(args, "--use-plan")
+	}
+	if c.Bool("plan-handoff") {
+		args = append(args, "--plan-handoff")
+	}
+	if c.Bool("force-setup") {
+		args = append(args, "--force-setup")
+	}
+	args = append(args, task)
+
+	if err := os.MkdirAll(detachedDir(), 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", detachedDir(), err)
+	}
+	logPath := detachedPath(task, ".log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // path built from hydra's detached dir
+	if err != nil {
+		return fmt.Errorf("creating log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	cmd := exec.CommandContext(context.Background(), exe, args...) //nolint:gosec // re-runs this executable
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// Detaching keeps the run going after the terminal closes.
+	platform.Detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting background run: %w", err)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	if err := os.WriteFile(detachedPath(task, ".pid"), []byte(strconv.Itoa(pid)+"\n"), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record PID: %v\n", err)
+	}
+
+	fmt.Printf("Running %s in the background (PID %d).\n", task, pid)
+	fmt.Printf("  Log:  %s\n", logPath)
+	fmt.Printf("  Lock: %s\n", lock.New(projectHydraDir(), task).Path())
+	fmt.Printf("Follow it with: hydra attach %s\n", task)
+	return nil
+}
+
+func attachCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "attach",
+		Usage:     "Watch a task's running session from another terminal",
+		ArgsUsage: "<task-name>",
+		Description: "For a run started with hydra run --detach, prints the run's log so far, " +
+			"then follows it until the run exits. For a session running in the TUI, or " +
+			"any other headless session, mirrors the session's output read-only through " +
+			"its socket under .hydra/attach until the session ends. Interrupting attach " +
+			"leaves the session going. Once a detached run has ended, attach prints its log.",
+		BashComplete: completeAttach,
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra attach <task-name>")
+			}
+			task := c.Args().First()
+
+			if _, running := detachedPID(task); running {
+				return followDetached(task, true)
+			}
+			err := runner.AttachMirror(projectHydraDir(), task, os.Stdout)
+			switch {
+			case err == nil:
+				fmt.Fprintf(os.Stderr, "\nSession of %s has ended.\n", task)
+				return nil
+			case !errors.Is(err, runner.ErrNoMirror):
+				return err
+			}
+			if _, err := os.Stat(detachedPath(task, ".log")); err == nil {
+				return followDetached(task, false)
+			}
+			return fmt.Errorf("no running session of %q to attach to", task)
+		},
+	}
+}
+
+// followDetached prints the log of the background run of task, following
+// it while the run is going if follow is set.
+func followDetached(task string, follow bool) error {
+	f, err := os.Open(detachedPath(task, ".log")) //nolint:gosec // path built from hydra's detached dir
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	running := func() bool {
+		_, ok := detachedPID(task)
+		return follow && ok
+	}
+	if err := followLog(os.Stdout, f, running, attachPollInterval); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "\nBackground run of %s has exited.\n", task)
+	return nil
+}
+
+// followLog copies r to w, then keeps copying what is appended to it every
+// interval until running reports false. Output written just before the
+// process exited is copied before returning.
+func followLog(w io.Writer, r io.Reader, running func() bool, interval time.Duration) error {
+	for {
+		alive := running()
+		if _, err := io.Copy(w, r); err != nil {
+			return err
+		}
+		if !alive {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+}
+
+// completeAttach prints the tasks with a background run log or a running
+// session mirror, for shell tab completion.
+func completeAttach(cCtx *cli.Context) {
+	if cCtx.NArg() > 0 {
+		return
+	}
+	seen := make(map[string]bool)
+	logs, _ := filepath.Glob(filepath.Join(detachedDir(), "*.log"))
+	mirrors, _ := filepath.Glob(filepath.Join(projectHydraDir(), "attach", "*"))
+	for _, p := range append(logs, mirrors...) {
+		name := strings.ReplaceAll(strings.TrimSuffix(filepath.Base(p), ".log"), "--", "/")
+		if !seen[name] {
+			seen[name] = true
+			fmt.Println(name)
+		}
+	}
+}
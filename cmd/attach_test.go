@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFollowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	if err := os.WriteFile(path, []byte("started\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path) //nolint:gosec // test path
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// The run appends a line and exits between two polls.
+	polls := 0
+	running := func() bool {
+		polls++
+		if polls == 2 {
+			if err := os.WriteFile(path, []byte("started\nfinished\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			return false
+		}
+		return true
+	}
+
+	var out strings.Builder
+	if err := followLog(&out, f, running, 0); err != nil {
+		t.Fatalf("followLog: %v", err)
+	}
+	if out.String() != "started\nfinished\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestDetachedDirFromSubdirectory(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(base, ".hydra"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, ".hydra", "config.json"), []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(base, "internal", "pkg")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	if got, want := detachedDir(), filepath.Join(base, ".hydra", "detached"); got != want {
+		t.Errorf("detachedDir = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erikh/hydra/internal/credstore"
+	"github.com/mattn/go-isatty"
+	"github.com/urfave/cli/v2"
+)
+
+func authCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "Manage stored API credentials",
+		Description: "Stores GitHub, Gitea, Forgejo, Bitbucket, Anthropic, and HTTPS git tokens and the SSH and " +
+			"signing key passphrases in the OS keychain (macOS Keychain or the Secret Service via " +
+			"secret-tool), falling back to an encrypted file in the user config directory. Environment " +
+			"variables (GITHUB_TOKEN, GITEA_TOKEN, FORGEJO_TOKEN, BITBUCKET_TOKEN, ANTHROPIC_API_KEY, HYDRA_GIT_TOKEN, " +
+			"HYDRA_SSH_PASSPHRASE, HYDRA_SIGNING_PASSPHRASE) still take precedence.",
+		Subcommands: []*cli.Command{
+			{
+				Name:         "login",
+				Usage:        "Store a token for a provider",
+				ArgsUsage:    "<provider>",
+				Description:  "Reads the token from stdin and saves it. Providers: " + strings.Join(credstore.Providers(), ", ") + ".",
+				BashComplete: completeProviders,
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra auth login <provider>")
+					}
+					provider := c.Args().First()
+					if err := credstore.ValidateProvider(provider); err != nil {
+						return err
+					}
+
+					store, err := credstore.Default()
+					if err != nil {
+						return err
+					}
+
+					if isatty.IsTerminal(os.Stdin.Fd()) {
+						fmt.Fprintf(os.Stderr, "Paste %s token: ", provider)
+					}
+					scanner := bufio.NewScanner(os.Stdin)
+					if !scanner.Scan() {
+						return errors.New("no token provided")
+					}
+					token := strings.TrimSpace(scanner.Text())
+					if token == "" {
+						return errors.New("no token provided")
+					}
+
+					if err := store.Set(provider, token); err != nil {
+						return fmt.Errorf("saving %s token: %w", provider, err)
+					}
+					fmt.Printf("Saved %s token to %s.\n", provider, store.Name())
+					return nil
+				},
+			},
+			{
+				Name:         "logout",
+				Usage:        "Remove the stored token for a provider",
+				ArgsUsage:    "<provider>",
+				BashComplete: completeProviders,
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("usage: hydra auth logout <provider>")
+					}
+					provider := c.Args().First()
+					if err := credstore.ValidateProvider(provider); err != nil {
+						return err
+					}
+
+					store, err := credstore.Default()
+					if err != nil {
+						return err
+					}
+					if err := store.Delete(provider); err != nil {
+						if errors.Is(err, credstore.ErrNotFound) {
+							return fmt.Errorf("no stored %s token", provider)
+						}
+						return fmt.Errorf("removing %s token: %w", provider, err)
+					}
+					fmt.Printf("Removed %s token from %s.\n", provider, store.Name())
+					return nil
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "Show where each provider's token comes from",
+				Action: func(_ *cli.Context) error {
+					store, err := credstore.Default()
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Store: %s\n", store.Name())
+					for _, provider := range credstore.Providers() {
+						source := "not configured"
+						if env := credstore.EnvVar(provider); os.Getenv(env) != "" {
+							source = "environment (" + env + ")"
+						} else if _, err := store.Get(provider); err == nil {
+							source = "stored"
+						}
+						fmt.Printf("  %-10s %s\n", provider, source)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// completeProviders prints the supported credential providers.
+func completeProviders(cCtx *cli.Context) {
+	if cCtx.NArg() > 0 {
+		return
+	}
+	for _, p := range credstore.Providers() {
+		fmt.Println(p)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+func importFromClaudeCodeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "import-from-claude-code",
+		Usage: "Adopt the source repository's Claude Code guidance into rules.md and lint.md",
+		Description: "Reads CLAUDE.md, .claude/CLAUDE.md, .claude/rules/*.md, and the CLAUDE.md " +
+			"files of subdirectories in the source repository, and writes their guidance " +
+			"into the design directory: sections about linting, style, formatting, naming, " +
+			"or conventions go to lint.md, and the rest to rules.md. Guidance from a " +
+			"subdirectory is kept under a heading naming it. Lines that only import a file " +
+			"with @path are replaced with that file. rules.md and lint.md are only written " +
+			"while empty, unless --force is given. hydra init does this automatically.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replace rules.md and lint.md even if they have content",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 0 {
+				return errors.New("usage: hydra import-from-claude-code [--force]")
+			}
+			cfg, err := config.Discover()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			dd, err := openDesignDir(cfg)
+			if err != nil {
+				return err
+			}
+			found, err := importClaudeCode(dd, cfg.RepoDir, c.Bool("force"))
+			if err != nil {
+				return err
+			}
+			if !found {
+				fmt.Println("No Claude Code guidance found in the source repository.")
+			}
+			return nil
+		},
+	}
+}
+
+// importClaudeCode adopts the Claude Code guidance of the repository at
+// repoDir into the design directory's rules.md and lint.md, reporting what
+// it wrote and what it left alone. It returns false if the repository has
+// no guidance to import.
+func importClaudeCode(dd *design.Dir, repoDir string, force bool) (bool, error) {
+	guidance, err := design.ReadClaudeCode(repoDir)
+	if err != nil || guidance == nil {
+		return false, err
+	}
+	written, err := dd.ImportClaudeCode(guidance, force)
+	if err != nil {
+		return true, err
+	}
+	fmt.Printf("Found Claude Code guidance in %s.\n", strings.Join(guidance.Sources, ", "))
+	for _, f := range []struct{ name, content string }{{"rules.md", guidance.Rules}, {"lint.md", guidance.Lint}} {
+		switch {
+		case f.content == "":
+		case slices.Contains(written, f.name):
+			fmt.Printf("  Wrote %s\n", f.name)
+		default:
+			fmt.Printf("  Kept %s, which already has content (use hydra import-from-claude-code --force to replace it)\n", f.name)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+func compareCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "compare",
+		Usage:     "Show how two task branches diverge",
+		ArgsUsage: "<task-a> <task-b>",
+		Description: "Lists the files each task's branch changed relative to the default " +
+			"branch and flags files changed by both, which may conflict when the tasks " +
+			"are merged. Use --patch to also print each branch's changes since the two " +
+			"branches diverged.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "patch",
+				Aliases: []string{"p"},
+				Usage:   "Print each branch's diff since the merge base",
+			},
+		},
+		BashComplete: func(c *cli.Context) {
+			if c.NArg() >= 2 {
+				return
+			}
+			completeTaskList(design.StatePending, design.StateReview, design.StateMerge,
+				design.StateCompleted, design.StateAbandoned)(c)
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 2 {
+				return errors.New("usage: hydra compare [--patch] <task-a> <task-b>")
+			}
+
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			return r.Compare(c.Args().Get(0), c.Args().Get(1), c.Bool("patch"))
+		},
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/erikh/hydra/internal/claude"
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
 )
@@ -362,27 +366,46 @@ func completeTasks(states ...design.TaskState) func(*cli.Context) {
 		if cCtx.NArg() > 0 {
 			return
 		}
+		printTaskNames(states, nil)
+	}
+}
 
-		cfg, err := config.Discover()
-		if err != nil {
-			return
+// completeTaskList is like completeTasks for commands that take any number of
+// tasks. Tasks already on the command line are not offered again.
+func completeTaskList(states ...design.TaskState) func(*cli.Context) {
+	return func(cCtx *cli.Context) {
+		given := make(map[string]bool, cCtx.NArg())
+		for _, arg := range cCtx.Args().Slice() {
+			given[arg] = true
 		}
+		printTaskNames(states, given)
+	}
+}
 
-		dd, err := design.NewDir(cfg.DesignDir)
+// printTaskNames prints the names of tasks in the given states, skipping
+// those in skip.
+func printTaskNames(states []design.TaskState, skip map[string]bool) {
+	cfg, err := config.Discover()
+	if err != nil {
+		return
+	}
+
+	dd, err := design.NewDir(cfg.DesignDir)
+	if err != nil {
+		return
+	}
+
+	for _, state := range states {
+		tasks, err := dd.TasksByState(state)
 		if err != nil {
-			return
+			continue
 		}
-
-		for _, state := range states {
-			tasks, err := dd.TasksByState(state)
-			if err != nil {
-				continue
+		for _, t := range tasks {
+			label := t.Name
+			if t.Group != "" {
+				label = t.Group + "/" + t.Name
 			}
-			for _, t := range tasks {
-				label := t.Name
-				if t.Group != "" {
-					label = t.Group + "/" + t.Name
-				}
+			if !skip[label] {
 				fmt.Println(label)
 			}
 		}
@@ -454,24 +477,27 @@ func completeMilestones(cCtx *cli.Context) {
 	if cCtx.NArg() > 0 {
 		return
 	}
+	for _, date := range milestoneDates() {
+		fmt.Println(date)
+	}
+}
 
-	cfg, err := config.Discover()
-	if err != nil {
+// completeOtherFiles prints the names of files in other/ for shell tab
+// completion.
+func completeOtherFiles(cCtx *cli.Context) {
+	if cCtx.NArg() > 0 {
 		return
 	}
-
-	dd, err := design.NewDir(cfg.DesignDir)
+	dd, err := completionDesignDir()
 	if err != nil {
 		return
 	}
-
-	milestones, err := dd.Milestones()
+	files, err := dd.OtherFiles()
 	if err != nil {
 		return
 	}
-
-	for _, m := range milestones {
-		fmt.Println(m.Date)
+	for _, f := range files {
+		fmt.Println(f)
 	}
 }
 
@@ -504,3 +530,145 @@ func completeAllTasks(cCtx *cli.Context) {
 		fmt.Println(label)
 	}
 }
+
+// flagValues lists the values offered for a flag, by flag name, when shell
+// completion is asked for right after the flag (--model <TAB>) or inside
+// its value (--model=<TAB>).
+var flagValues = map[string]func() []string{
+	"model":   modelNames,
+	"state":   func() []string { return stateNames(taskStates) },
+	"fail-on": func() []string { return failOnStates },
+	"since":   milestoneDates,
+	"until":   milestoneDates,
+	"persona": personaNames,
+}
+
+// withFlagCompletion wraps the completion of each command in cmds, and
+// their subcommands, so that flag values are completed after a flag and
+// flag names after a dash. Otherwise the command's own completion runs.
+func withFlagCompletion(cmds []*cli.Command) {
+	for _, c := range cmds {
+		withFlagCompletion(c.Subcommands)
+
+		complete := c.BashComplete
+		if complete == nil {
+			complete = cli.DefaultCompleteWithFlags(c)
+		}
+		c.BashComplete = func(cCtx *cli.Context) {
+			// Like urfave/cli's own completion, this reads the word before
+			// --generate-bash-completion from os.Args.
+			var lastArg string
+			if len(os.Args) > 2 {
+				lastArg = os.Args[len(os.Args)-2]
+			}
+			switch {
+			case printFlagValues(cCtx.App.Writer, c, lastArg):
+			case strings.HasPrefix(lastArg, "-"):
+				cli.DefaultCompleteWithFlags(c)(cCtx)
+			default:
+				complete(cCtx)
+			}
+		}
+	}
+}
+
+// printFlagValues prints the values of the flag of cmd that lastArg names
+// or is assigning to, and reports whether it did.
+func printFlagValues(w io.Writer, cmd *cli.Command, lastArg string) bool {
+	if !strings.HasPrefix(lastArg, "-") {
+		return false
+	}
+	name, _, assigned := strings.Cut(strings.TrimLeft(lastArg, "-"), "=")
+	values, ok := flagValues[name]
+	if !ok || !commandHasFlag(cmd, name) {
+		return false
+	}
+	prefix := ""
+	if assigned {
+		prefix = lastArg[:strings.Index(lastArg, "=")+1]
+	}
+	for _, v := range values() {
+		_, _ = fmt.Fprintln(w, prefix+v)
+	}
+	return true
+}
+
+// commandHasFlag reports whether cmd has a flag called name.
+func commandHasFlag(cmd *cli.Command, name string) bool {
+	for _, f := range cmd.Flags {
+		if slices.Contains(f.Names(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// completionDesignDir opens the design directory of the project in the
+// current directory.
+func completionDesignDir() (*design.Dir, error) {
+	cfg, err := config.Discover()
+	if err != nil {
+		return nil, err
+	}
+	return design.NewDir(cfg.DesignDir)
+}
+
+// modelNames returns the known Claude models followed by any other models
+// the project's hydra.yml files name as a model or model fallback.
+func modelNames() []string {
+	names := slices.Clone(claude.KnownModels)
+	dd, err := completionDesignDir()
+	if err != nil {
+		return names
+	}
+	paths, _ := filepath.Glob(filepath.Join(dd.Path, "tasks", "*", "hydra.yml"))
+	paths = append([]string{filepath.Join(dd.Path, "hydra.yml")}, paths...)
+	for _, p := range paths {
+		cmds, err := taskrun.Load(p)
+		if err != nil {
+			continue
+		}
+		for _, m := range append([]string{cmds.Model}, cmds.ModelFallbacks...) {
+			if m != "" && !slices.Contains(names, m) {
+				names = append(names, m)
+			}
+		}
+	}
+	return names
+}
+
+// stateNames returns states as strings.
+func stateNames(states []design.TaskState) []string {
+	names := make([]string, len(states))
+	for i, s := range states {
+		names[i] = string(s)
+	}
+	return names
+}
+
+// milestoneDates returns the dates of unacknowledged milestones.
+func milestoneDates() []string {
+	dd, err := completionDesignDir()
+	if err != nil {
+		return nil
+	}
+	milestones, err := dd.Milestones()
+	if err != nil {
+		return nil
+	}
+	dates := make([]string, len(milestones))
+	for i, m := range milestones {
+		dates[i] = m.Date
+	}
+	return dates
+}
+
+// personaNames returns the names of the reviewer personas.
+func personaNames() []string {
+	dd, err := completionDesignDir()
+	if err != nil {
+		return nil
+	}
+	names, _ := dd.Personas()
+	return names
+}
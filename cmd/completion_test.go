@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestPrintFlagValues(t *testing.T) {
+	cmd := &cli.Command{
+		Name:  "prune",
+		Flags: []cli.Flag{&cli.StringSliceFlag{Name: "state"}, &cli.BoolFlag{Name: "dry-run"}},
+	}
+	tests := []struct {
+		lastArg string
+		want    string
+		ok      bool
+	}{
+		{"--state", "pending\nreview\nmerge\ncompleted\nabandoned\n", true},
+		{"--state=com", "--state=pending\n--state=review\n--state=merge\n--state=completed\n--state=abandoned\n", true},
+		{"--dry-run", "", false},
+		{"--model", "", false}, // not a flag of this command
+		{"state", "", false},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		ok := printFlagValues(&buf, cmd, tt.lastArg)
+		if ok != tt.ok || buf.String() != tt.want {
+			t.Errorf("printFlagValues(%q) = %v, %q; want %v, %q", tt.lastArg, ok, buf.String(), tt.ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/urfave/cli/v2"
+)
+
+func designCommand() *cli.Command {
+	remoteFlag := &cli.StringFlag{
+		Name:  "remote",
+		Usage: "Remote to sync with (default: design_remote from hydra.yml, else the branch's upstream)",
+	}
+	return &cli.Command{
+		Name:  "design",
+		Usage: "Share the design directory between machines through git",
+		Description: "The design directory is kept in a git repository. 'hydra design push' " +
+			"commits whatever hydra or you changed there and pushes it; 'hydra design pull' " +
+			"brings in what other machines pushed. Conflicting additions to " +
+			"state/record.json are merged by keeping both sides' entries.",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "push",
+				Usage: "Commit design directory changes and push them",
+				Flags: []cli.Flag{remoteFlag},
+				Action: func(c *cli.Context) error {
+					dd, remote, err := openDesignSync(c)
+					if err != nil {
+						return err
+					}
+					committed, err := dd.Push(remote)
+					if err != nil {
+						return err
+					}
+					if committed {
+						fmt.Println("Committed and pushed design changes.")
+					} else {
+						fmt.Println("Pushed design directory.")
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "pull",
+				Usage: "Commit design directory changes and rebase them onto the remote",
+				Flags: []cli.Flag{remoteFlag},
+				Action: func(c *cli.Context) error {
+					dd, remote, err := openDesignSync(c)
+					if err != nil {
+						return err
+					}
+					if err := dd.Pull(remote); err != nil {
+						return err
+					}
+					fmt.Println("Design directory is up to date.")
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// openDesignSync opens the design directory and resolves the remote that
+// hydra design push and pull use: --remote, then design_remote from
+// hydra.yml.
+func openDesignSync(c *cli.Context) (*design.Dir, string, error) {
+	cfg, err := config.Discover()
+	if err != nil {
+		return nil, "", fmt.Errorf("loading config: %w", err)
+	}
+	dd, err := design.NewDir(cfg.DesignDir)
+	if err != nil {
+		return nil, "", err
+	}
+	remote := c.String("remote")
+	if remote == "" {
+		if cmds, err := taskrun.Load(filepath.Join(dd.Path, "hydra.yml")); err == nil {
+			remote = cmds.DesignRemote
+		}
+	}
+	return dd, remote, nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/hooks"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/urfave/cli/v2"
+)
+
+func hooksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "hooks",
+		Usage: "Install git hooks in developer clones",
+		Description: "Installs git hooks into a developer's clone of the source repository " +
+			"that keep it from silently diverging from hydra-managed branches.",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "install",
+				Usage:     "Install hooks into a clone (defaults to the current directory)",
+				ArgsUsage: "[clone-dir]",
+				Description: "With --remote-guard, installs a pre-push hook that warns when pushing " +
+					"to a hydra/* branch whose task is running, in review, or being merged. " +
+					"Pass --block to reject such pushes instead. Set " + hooks.AllowEnv +
+					"=1 to bypass the hook for a single push.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "remote-guard",
+						Usage: "Install the pre-push guard for hydra/* branches",
+					},
+					&cli.BoolFlag{
+						Name:  "block",
+						Usage: "Reject pushes to active hydra branches instead of warning",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Replace an existing pre-push hook not installed by hydra",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if !c.Bool("remote-guard") {
+						return errors.New("usage: hydra hooks install --remote-guard [--block] [clone-dir]")
+					}
+
+					base, err := config.FindBase()
+					if err != nil {
+						return fmt.Errorf("loading config: %w", err)
+					}
+
+					cloneDir := "."
+					if c.NArg() > 0 {
+						cloneDir = c.Args().First()
+					}
+					cloneDir, err = filepath.Abs(cloneDir)
+					if err != nil {
+						return fmt.Errorf("resolving clone dir: %w", err)
+					}
+					if strings.HasPrefix(cloneDir+string(filepath.Separator), config.HydraPath(base)+string(filepath.Separator)) {
+						return errors.New("refusing to install the remote guard into hydra's own checkout")
+					}
+
+					hooksDir, err := repo.Open(cloneDir).HooksDir()
+					if err != nil {
+						return fmt.Errorf("locating hooks dir: %w", err)
+					}
+
+					hydraBin, err := os.Executable()
+					if err != nil {
+						return fmt.Errorf("locating hydra binary: %w", err)
+					}
+
+					script := hooks.PrePushScript(hydraBin, base, c.Bool("block"))
+					path, err := hooks.Install(hooksDir, "pre-push", script, c.Bool("force"))
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Installed remote guard at %s\n", path)
+					return nil
+				},
+			},
+			{
+				Name:      "pre-push",
+				Usage:     "Check a push against active hydra branches (called by the pre-push hook)",
+				ArgsUsage: "<remote> <url>",
+				Hidden:    true,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "project",
+						Usage:    "Path to the hydra project",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:  "block",
+						Usage: "Reject pushes to active hydra branches instead of warning",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if os.Getenv(hooks.AllowEnv) != "" {
+						return nil
+					}
+
+					base := c.String("project")
+					cfg, err := config.Load(base)
+					if err != nil {
+						// The project is gone or unreadable; don't get in the way.
+						fmt.Fprintf(os.Stderr, "Warning: hydra remote guard: %v\n", err)
+						return nil
+					}
+					dd, err := design.NewDir(cfg.DesignDir)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: hydra remote guard: %v\n", err)
+						return nil
+					}
+					running, err := lock.ReadAll(config.HydraPath(base))
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: hydra remote guard: %v\n", err)
+					}
+
+					active, err := hooks.ActiveBranches(dd, running)
+					if err != nil {
+						return err
+					}
+					violations, err := hooks.CheckPush(os.Stdin, active)
+					if err != nil {
+						return err
+					}
+					if len(violations) == 0 {
+						return nil
+					}
+
+					for _, v := range violations {
+						fmt.Fprintf(os.Stderr, "hydra: %s is managed by hydra (task is %s)\n", v.Branch, v.State)
+					}
+					if c.Bool("block") {
+						return cli.Exit(fmt.Sprintf("push rejected; set %s=1 to override", hooks.AllowEnv), 1)
+					}
+					fmt.Fprintln(os.Stderr, "hydra: pushing anyway; hydra may overwrite or conflict with these changes")
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/urfave/cli/v2"
+)
+
+func importFailureCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "import-failure",
+		Usage:     "Create a task from a failing CI run",
+		ArgsUsage: "<log-file|url|->",
+		Description: "Reads a CI log from a file, a URL, or stdin (-), extracts the failing " +
+			"test and build output, and creates a pending fix-ci-<id> task holding it, " +
+			"ready for 'hydra run'. GitHub Actions job URLs are fetched through the API " +
+			"using the token from 'hydra auth'. The id is the CI run number or the log's " +
+			"file name unless --id is given.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "id",
+				Usage: "Task id; the task is named fix-ci-<id>",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra import-failure [--id id] <log-file|url|->")
+			}
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			return r.ImportFailure(c.Context, c.Args().Get(0), c.String("id"))
+		},
+	}
+}
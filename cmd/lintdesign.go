@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+func lintDesignCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lint-design",
+		Usage: "Validate the design directory",
+		Description: "Checks the design directory for group.md files with no tasks, tasks " +
+			"with empty bodies or invalid front matter, task names that map to the same " +
+			"branch, a malformed state/record.json, and milestones with no promises. Each " +
+			"problem is printed with a suggested fix where there is one. Nothing is " +
+			"changed. Exits nonzero if any problems are found.",
+		Action: func(_ *cli.Context) error {
+			cfg, err := config.Discover()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			dd, err := design.NewDir(cfg.DesignDir)
+			if err != nil {
+				return err
+			}
+
+			problems, err := dd.Validate()
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				fmt.Println("No problems found.")
+				return nil
+			}
+			for _, p := range problems {
+				fmt.Printf("%s: %s: %s\n", p.Path, p.Kind, p.Message)
+				if p.Fix != "" {
+					fmt.Printf("  fix: %s\n", p.Fix)
+				}
+			}
+			return cli.Exit(fmt.Sprintf("%d problem(s) found", len(problems)), 1)
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func nextCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "next",
+		Usage: "Recommend what to do next, ranked by urgency",
+		Description: "Combines task states, running tasks, review results from " +
+			"state/record.json, and milestone due dates into a ranked list of " +
+			"suggested actions. Overdue and soon-due milestones come first, then " +
+			"tasks stuck in merge, tasks awaiting review (oldest and failing " +
+			"review checklists first), and finally pending tasks.",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Value:   10,
+				Usage:   "Show at most this many recommendations (0 for all)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+
+			recs, err := r.Next(time.Now())
+			if err != nil {
+				return err
+			}
+			if len(recs) == 0 {
+				fmt.Println("Nothing to do.")
+				return nil
+			}
+
+			if limit := c.Int("limit"); limit > 0 && len(recs) > limit {
+				recs = recs[:limit]
+			}
+			for i, rec := range recs {
+				fmt.Printf("%d. %s\n   %s\n", i+1, rec.Reason, rec.Command)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/tui"
+	"github.com/mattn/go-isatty"
+)
+
+// pickTask lets the user choose a task in one of the given states with the
+// interactive fuzzy picker, for commands run without a task name. It
+// returns the task as group/name, or "" if the user cancels. When stdin or
+// stdout is not a terminal there is no one to ask, so it returns usageErr.
+func pickTask(title string, usageErr error, states ...design.TaskState) (string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return "", usageErr
+	}
+
+	cfg, err := config.Discover()
+	if err != nil {
+		return "", fmt.Errorf("loading config: %w", err)
+	}
+	dd, err := design.NewDir(cfg.DesignDir)
+	if err != nil {
+		return "", err
+	}
+
+	items, err := taskPickerItems(dd, states)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		names := make([]string, len(states))
+		for i, s := range states {
+			names[i] = string(s)
+		}
+		return "", fmt.Errorf("no tasks in %s state", strings.Join(names, " or "))
+	}
+
+	name, err := tui.Pick(title, items)
+	if errors.Is(err, tui.ErrPickerCancelled) {
+		return "", nil
+	}
+	return name, err
+}
+
+// taskPickerItems returns a picker item for each task in the given states,
+// previewing the task's content.
+func taskPickerItems(dd *design.Dir, states []design.TaskState) ([]tui.PickerItem, error) {
+	var items []tui.PickerItem
+	for _, state := range states {
+		tasks, err := dd.TasksByState(state)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			label := t.Name
+			if t.Group != "" {
+				label = t.Group + "/" + t.Name
+			}
+			content, err := t.Content()
+			if err != nil {
+				content = fmt.Sprintf("(could not read task: %v)", err)
+			}
+			items = append(items, tui.PickerItem{Label: label, Preview: content})
+		}
+	}
+	return items, nil
+}
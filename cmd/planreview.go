@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+// planReviewCommands answer a run waiting on its plan under plan_handoff.
+func planReviewCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:         "approve",
+			Usage:        "Approve the plan of a run waiting under plan_handoff",
+			ArgsUsage:    "<task-name>",
+			BashComplete: completeTaskList(design.StatePending),
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("usage: hydra plan approve <task-name>")
+				}
+				r, err := newRunner()
+				if err != nil {
+					return err
+				}
+				return r.ApprovePlan(c.Args().First())
+			},
+		},
+		{
+			Name:         "edit",
+			Usage:        "Edit the saved plan of a task in $EDITOR",
+			ArgsUsage:    "<task-name>",
+			BashComplete: completeTaskList(design.StatePending),
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("usage: hydra plan edit <task-name>")
+				}
+				r, err := newRunner()
+				if err != nil {
+					return err
+				}
+				path, err := r.PlanPath(c.Args().First())
+				if err != nil {
+					return err
+				}
+				editor, err := resolveEditor()
+				if err != nil {
+					return err
+				}
+				return design.RunEditorOnFile(editor, path, os.Stdin, os.Stdout, os.Stderr)
+			},
+		},
+		{
+			Name:         "reject",
+			Usage:        "Reject the plan of a run waiting under plan_handoff, leaving the task pending",
+			ArgsUsage:    "<task-name>",
+			BashComplete: completeTaskList(design.StatePending),
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("usage: hydra plan reject <task-name>")
+				}
+				r, err := newRunner()
+				if err != nil {
+					return err
+				}
+				return r.RejectPlan(c.Args().First())
+			},
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+func recordCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "record",
+		Usage: "List and archive the commit record",
+		Description: "The record, state/record.json in the design directory, maps the " +
+			"commits hydra made to the tasks that produced them. It grows with every run, " +
+			"review, and merge; archive old entries to keep it small.",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List record entries, oldest first",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "archived",
+						Aliases: []string{"a"},
+						Usage:   "Include entries moved to state/archive/",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Output as JSON",
+					},
+					&cli.BoolFlag{
+						Name:  "no-color",
+						Usage: "Disable syntax highlighting of JSON output",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Discover()
+					if err != nil {
+						return fmt.Errorf("loading config: %w", err)
+					}
+					dd, err := design.NewDir(cfg.DesignDir)
+					if err != nil {
+						return err
+					}
+
+					rec := dd.Record()
+					entries, err := rec.Entries()
+					if err != nil {
+						return err
+					}
+					if c.Bool("archived") {
+						archived, err := rec.ArchivedEntries()
+						if err != nil {
+							return err
+						}
+						entries = append(archived, entries...)
+					}
+
+					if c.Bool("json") {
+						if entries == nil {
+							entries = []design.RecordEntry{}
+						}
+						return printStatus(c, entries)
+					}
+					printRecord(entries)
+					return nil
+				},
+			},
+			{
+				Name:  "archive",
+				Usage: "Move old entries out of record.json",
+				Description: "Moves the entries recorded before --before into dated files, " +
+					"state/archive/record-YYYY-MM.json, one per month. Entries from before " +
+					"hydra stamped record times stay in record.json. Use 'hydra record list " +
+					"--archived' to see archived entries.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "before",
+						Usage:    "Archive entries recorded before this date (YYYY-MM-DD)",
+						Required: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					before, err := time.Parse(time.DateOnly, c.String("before"))
+					if err != nil {
+						return errors.New("--before must be a date in YYYY-MM-DD form")
+					}
+
+					cfg, err := config.Discover()
+					if err != nil {
+						return fmt.Errorf("loading config: %w", err)
+					}
+					dd, err := openDesignDir(cfg)
+					if err != nil {
+						return err
+					}
+
+					n, err := dd.Record().Archive(before)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Archived %d record entries from before %s.\n", n, before.Format(time.DateOnly))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// printRecord prints record entries as a table.
+func printRecord(entries []design.RecordEntry) {
+	if len(entries) == 0 {
+		fmt.Println("No record entries.")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tSHA\tTASK\tDURATION")
+	for _, e := range entries {
+		when := "-"
+		if !e.Time.IsZero() {
+			when = e.Time.Local().Format("2006-01-02 15:04")
+		}
+		sha := e.SHA
+		if len(sha) > 12 {
+			sha = sha[:12]
+		}
+		duration := "-"
+		if e.DurationSeconds > 0 {
+			duration = formatDuration(time.Duration(e.DurationSeconds * float64(time.Second)))
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", when, sha, e.TaskName, duration)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/runner"
+	"github.com/urfave/cli/v2"
+)
+
+func splitCommand() *cli.Command {
+	return &cli.Command{
+		Name:         "split",
+		Usage:        "Split a large pending task into a group of smaller tasks",
+		ArgsUsage:    "<task-name>",
+		BashComplete: completeTasks(design.StatePending),
+		Description: "Asks Claude to read the code and decompose an ungrouped pending task " +
+			"into several smaller tasks. The proposed tasks are printed for confirmation; " +
+			"if accepted, the task is replaced by a group of the same name, with the " +
+			"original task as the group's group.md and one task file per part.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Write the proposed tasks without asking",
+			},
+			&cli.BoolFlag{
+				Name:    "no-auto-accept",
+				Aliases: []string{"Y"},
+				Usage:   "Disable auto-accept (prompt for each tool call)",
+			},
+			&cli.BoolFlag{
+				Name:    "no-notify",
+				Aliases: []string{"N"},
+				Usage:   "Disable desktop notifications when confirmation is needed",
+			},
+			&cli.BoolFlag{
+				Name:    "tui",
+				Aliases: []string{"T"},
+				Usage:   "Force the built-in TUI instead of Claude Code CLI",
+			},
+			&cli.StringFlag{
+				Name:  "model",
+				Usage: "Override the Claude model",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra split [--yes] <task-name>")
+			}
+
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+
+			r.AutoAccept = !c.Bool("no-auto-accept")
+			r.Notify = !c.Bool("no-notify")
+			r.ForceTUI = c.Bool("tui")
+			if m := c.String("model"); m != "" {
+				r.SetModel(m)
+			}
+
+			confirm := runner.Confirm
+			if assumeYes(c) {
+				confirm = func(string) bool { return true }
+			}
+			return r.Split(c.Args().Get(0), confirm)
+		},
+	}
+}
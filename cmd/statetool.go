@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/claude"
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/runner"
+	"github.com/urfave/cli/v2"
+)
+
+func stateToolCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "state-tool",
+		Usage:     "Serve the hydra_state tool over MCP on stdin and stdout (started by Claude Code sessions)",
+		ArgsUsage: "<task-name>",
+		Hidden:    true,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "project",
+				Usage:    "Path to the hydra project",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("usage: hydra state-tool --project <dir> <task-name>")
+			}
+			base := c.String("project")
+			cfg, err := config.Load(base)
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			r, err := runner.New(cfg)
+			if err != nil {
+				return err
+			}
+			r.BaseDir = base
+			r.WorkRoot = os.Getenv(workDirEnv)
+
+			taskName := c.Args().First()
+			return claude.ServeStateMCP(c.Context, os.Stdin, os.Stdout, func(topic string, files []string) (string, error) {
+				return r.TaskState(taskName, topic, files)
+			})
+		},
+	}
+}
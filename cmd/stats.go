@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func statsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "Summarize activity over a date range",
+		Description: "Reports tasks completed, merges, average review rounds per " +
+			"completed task, tokens spent, lines changed, and failures by category " +
+			"between --since and --until (inclusive, YYYY-MM-DD, local time). " +
+			"Defaults to the last seven days. Counts come from state/record.json " +
+			"and state/failures.json; entries recorded before hydra tracked times " +
+			"are not included.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "First day to include (YYYY-MM-DD); defaults to six days before --until",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "Last day to include (YYYY-MM-DD); defaults to today",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output as JSON",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable syntax highlighting of JSON output",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			since, until, err := statsRange(c.String("since"), c.String("until"), time.Now())
+			if err != nil {
+				return err
+			}
+
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			stats, err := r.Stats(since, until)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("json") {
+				return printStatus(c, stats)
+			}
+			fmt.Print(stats)
+			return nil
+		},
+	}
+}
+
+// statsRange turns the --since and --until days into a half-open time range
+// from the start of since to the start of the day after until.
+func statsRange(sinceFlag, untilFlag string, now time.Time) (since, until time.Time, err error) {
+	parse := func(flag, value string) (time.Time, error) {
+		t, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --%s %q: want YYYY-MM-DD", flag, value)
+		}
+		return t, nil
+	}
+
+	until = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if untilFlag != "" {
+		if until, err = parse("until", untilFlag); err != nil {
+			return since, until, err
+		}
+	}
+	since = until.AddDate(0, 0, -6)
+	if sinceFlag != "" {
+		if since, err = parse("since", sinceFlag); err != nil {
+			return since, until, err
+		}
+	}
+	if since.After(until) {
+		return since, until, errors.New("--since is after --until")
+	}
+	return since, until.AddDate(0, 0, 1), nil
+}
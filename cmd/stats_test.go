@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsRange(t *testing.T) {
+	now := time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	since, until, err := statsRange("", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since.Equal(day(9)) || !until.Equal(day(16)) {
+		t.Errorf("default range = %s to %s, want the seven days through today", since, until)
+	}
+
+	since, until, err = statsRange("2026-03-01", "2026-03-02", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since.Equal(day(1)) || !until.Equal(day(3)) {
+		t.Errorf("range = %s to %s, want 03-01 up to 03-03", since, until)
+	}
+
+	if _, _, err := statsRange("March 1", "", now); err == nil {
+		t.Error("expected an error for a malformed date")
+	}
+	if _, _, err := statsRange("2026-03-10", "2026-03-01", now); err == nil {
+		t.Error("expected an error when --since is after --until")
+	}
+}
@@ -2,10 +2,18 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
 	"go.yaml.in/yaml/v4"
 )
 
@@ -152,3 +160,232 @@ func TestStatusOutputEmptyOmitted(t *testing.T) {
 		t.Errorf("empty output = %s, want {}", buf)
 	}
 }
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBranchStatus(t *testing.T) {
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", append([]string{"-C", dir}, args...)...) //nolint:gosec // test with controlled args
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	bare := filepath.Join(t.TempDir(), "remote.git")
+	git(".", "init", "--bare", "-b", "main", bare)
+
+	wd := t.TempDir()
+	git(wd, "init", "-b", "main")
+	git(wd, "config", "user.email", "test@test.com")
+	git(wd, "config", "user.name", "Test")
+	git(wd, "config", "commit.gpgsign", "false")
+	git(wd, "commit", "--allow-empty", "-m", "initial")
+	git(wd, "remote", "add", "origin", bare)
+	git(wd, "push", "origin", "main")
+	git(wd, "checkout", "-b", "hydra/task")
+	if err := os.WriteFile(filepath.Join(wd, "f.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git(wd, "add", "-A")
+	git(wd, "commit", "-m", "task change")
+	git(wd, "fetch", "origin")
+
+	sb, ok := branchStatus(wd, "hydra/task", "", time.Now())
+	if !ok {
+		t.Fatal("branchStatus returned false for a git work dir")
+	}
+	if sb.Ahead != 1 || sb.Behind != 0 {
+		t.Errorf("ahead/behind = %d/%d, want 1/0", sb.Ahead, sb.Behind)
+	}
+	if len(sb.LastSHA) != 12 || sb.LastCommit != "just now" {
+		t.Errorf("last commit = %q %q", sb.LastSHA, sb.LastCommit)
+	}
+	if sb.RemoteExists {
+		t.Error("branch has not been pushed; RemoteExists should be false")
+	}
+
+	// With default_branch set, divergence is measured against it.
+	git(wd, "checkout", "-b", "trunk", "main")
+	git(wd, "commit", "--allow-empty", "-m", "trunk change")
+	git(wd, "push", "origin", "trunk")
+	git(wd, "checkout", "hydra/task")
+	if sb, _ := branchStatus(wd, "hydra/task", "trunk", time.Now()); sb.Ahead != 1 || sb.Behind != 1 {
+		t.Errorf("ahead/behind of trunk = %d/%d, want 1/1", sb.Ahead, sb.Behind)
+	}
+
+	if _, ok := branchStatus(t.TempDir(), "hydra/none", "", time.Now()); ok {
+		t.Error("branchStatus should return false for a non-git dir")
+	}
+}
+
+func TestTaskDetail(t *testing.T) {
+	designDir := t.TempDir()
+	hydraDir := t.TempDir()
+	issueDir := filepath.Join(designDir, "state", "review", "issues")
+	if err := os.MkdirAll(issueDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(designDir, "tasks"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	content := "Issue #42: Fix bug\nURL: https://github.com/o/r/issues/42\n\nBody\n"
+	if err := os.WriteFile(filepath.Join(issueDir, "42-fix-bug.md"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := dd.Record()
+	entries := []design.RecordEntry{
+		{SHA: "aaaaaaaaaaaaaaaa", TaskName: "issues/42-fix-bug", DurationSeconds: 750},
+		{SHA: "bbbbbbbbbbbbbbbb", TaskName: "other-task", DurationSeconds: 30},
+		{SHA: "cccccccccccccccc", TaskName: "review:issues/42-fix-bug", Checklist: []design.ChecklistResult{
+			{Item: "Docs", Passed: true}, {Item: "Tests", Passed: false},
+		}},
+	}
+	for _, e := range entries {
+		if err := record.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lk := lock.New(hydraDir, "review:issues/42-fix-bug")
+	if err := lk.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lk.Release() }()
+
+	detail, err := taskDetail(dd, hydraDir, filepath.Join(hydraDir, "work"), "issues/42-fix-bug", time.Now())
+	if err != nil {
+		t.Fatalf("taskDetail: %v", err)
+	}
+
+	if detail.Name != "issues/42-fix-bug" || detail.State != "review" {
+		t.Errorf("name/state = %q/%q", detail.Name, detail.State)
+	}
+	if detail.Issue == nil || detail.Issue.Number != 42 || detail.Issue.URL != "https://github.com/o/r/issues/42" {
+		t.Errorf("issue = %+v", detail.Issue)
+	}
+	if detail.Lock == nil || detail.Lock.Action != "reviewing" || detail.Lock.PID != os.Getpid() {
+		t.Errorf("lock = %+v", detail.Lock)
+	}
+	if detail.WorkDir != "" || detail.Branch != nil {
+		t.Errorf("work dir = %q, branch = %+v; want none", detail.WorkDir, detail.Branch)
+	}
+	if detail.LastRun != "12m30s" {
+		t.Errorf("last run = %q, want 12m30s", detail.LastRun)
+	}
+	if len(detail.Records) != 2 {
+		t.Fatalf("records = %+v, want 2", detail.Records)
+	}
+	if r := detail.Records[1]; r.Action != "review" || r.SHA != "cccccccccccc" || r.Checklist != "1/2 passed" {
+		t.Errorf("review record = %+v", r)
+	}
+}
+
+func TestTaskDetailMilestone(t *testing.T) {
+	designDir := t.TempDir()
+	taskDir := filepath.Join(designDir, "tasks", design.MilestoneTaskGroup("2026-03-01"))
+	if err := os.MkdirAll(taskDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(taskDir, "promise.md"), []byte("Do it"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := taskDetail(dd, t.TempDir(), t.TempDir(), "promise", time.Now())
+	if err != nil {
+		t.Fatalf("taskDetail: %v", err)
+	}
+	if detail.Milestone != "2026-03-01" || detail.State != "pending" || detail.Issue != nil {
+		t.Errorf("detail = %+v", detail)
+	}
+
+	if _, err := taskDetail(dd, t.TempDir(), t.TempDir(), "missing", time.Now()); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
+
+func TestMilestoneStatus(t *testing.T) {
+	designDir := t.TempDir()
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dd.CreateMilestone("2026-05-01", "## Ship search\n\n## Ship sync\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dd.CreateMilestone("2026-03-01", "## Fix login\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dd.RepairMilestone(&design.Milestone{Date: "2026-05-01", FilePath: filepath.Join(designDir, "milestone", "2026-05-01.md")}); err != nil {
+		t.Fatal(err)
+	}
+	completedDir := filepath.Join(designDir, "state", "completed")
+	if err := os.MkdirAll(completedDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(designDir, "tasks", design.MilestoneTaskGroup("2026-05-01"), "ship-search.md"),
+		filepath.Join(completedDir, "ship-search.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.Local)
+	got, err := milestoneStatus(dd, now)
+	if err != nil {
+		t.Fatalf("milestoneStatus: %v", err)
+	}
+	want := []statusMilestone{
+		{Due: "2026-03-01", Kept: 0, Promises: 1, Overdue: true},
+		{Due: "2026-05-01", Kept: 1, Promises: 2, Overdue: false},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("milestoneStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStatusFailOn(t *testing.T) {
+	states, err := parseFailOn([]string{"running,merge", "running"})
+	if err != nil {
+		t.Fatalf("parseFailOn: %v", err)
+	}
+	if strings.Join(states, ",") != "running,merge" {
+		t.Errorf("states = %v, want running,merge", states)
+	}
+	if _, err := parseFailOn([]string{"completed"}); err == nil {
+		t.Error("parseFailOn should reject states other than running, pending, review, and merge")
+	}
+
+	out := statusOutput{
+		Running: map[string]statusRunning{"a": {Action: "running"}, "b": {Action: "merging"}},
+		Pending: []string{"c"},
+	}
+	if got := statusFailures(out, states); got != "2 running" {
+		t.Errorf("statusFailures = %q, want %q", got, "2 running")
+	}
+	if got := statusFailures(out, []string{"merge", "review"}); got != "" {
+		t.Errorf("statusFailures with no matching tasks = %q, want empty", got)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/urfave/cli/v2"
+)
+
+// allStates are the task states, for completing commands that take a task
+// in any of them.
+var allStates = []design.TaskState{
+	design.StatePending, design.StateReview, design.StateMerge, design.StateCompleted, design.StateAbandoned,
+}
+
+func taskCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "task",
+		Usage: "Manage tasks in any state",
+		Subcommands: []*cli.Command{
+			{
+				Name:         "rename",
+				Usage:        "Rename a task and everything hydra keeps under its name",
+				ArgsUsage:    "<task-name> <new-name>",
+				BashComplete: completeTasks(allStates...),
+				Description: "Renames a task in its current state and group. The work directory " +
+					"moves to the new name, the task branch is renamed locally and on origin, " +
+					"and the task's saved plan, session transcripts, and entries in " +
+					"state/record.json follow it. The new name is given without the group. " +
+					"The task must not be running, in review, merging, or served by " +
+					"hydra review dev. Issue tasks keep their issue number.",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return errors.New("usage: hydra task rename <task-name> <new-name>")
+					}
+					return renameTask(c.Args().Get(0), c.Args().Get(1))
+				},
+			},
+		},
+	}
+}
+
+// renameTask renames a task for hydra task rename and hydra edit --rename.
+func renameTask(taskName, newName string) error {
+	r, err := newRunner()
+	if err != nil {
+		return err
+	}
+	return r.RenameTask(taskName, newName)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/runner"
+	"go.yaml.in/yaml/v4"
+)
+
+// statusTaskDetail is the detailed view of one task printed by
+// "hydra status --task".
+type statusTaskDetail struct {
+	Name      string         `json:"name" yaml:"name"`
+	State     string         `json:"state" yaml:"state"`
+	WorkDir   string         `json:"work_dir,omitempty" yaml:"work_dir,omitempty"`
+	Branch    *statusBranch  `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Lock      *statusRunning `json:"lock,omitempty" yaml:"lock,omitempty"` // the hydra process working on the task
+	Reviewer  string         `json:"reviewer,omitempty" yaml:"reviewer,omitempty"`
+	Issue     *statusIssue   `json:"issue,omitempty" yaml:"issue,omitempty"`
+	Milestone string         `json:"milestone,omitempty" yaml:"milestone,omitempty"`
+	LastRun   string         `json:"last_run,omitempty" yaml:"last_run,omitempty"` // duration of the last Claude run session
+	Records   []statusRecord `json:"records,omitempty" yaml:"records,omitempty"`
+}
+
+// statusIssue is the issue a task was imported from.
+type statusIssue struct {
+	Number int    `json:"number" yaml:"number"`
+	URL    string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// statusRecord is one record.json entry for a task.
+type statusRecord struct {
+	SHA       string `json:"sha" yaml:"sha"`
+	Action    string `json:"action" yaml:"action"` // run, review, test, or merge
+	Duration  string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Checklist string `json:"checklist,omitempty" yaml:"checklist,omitempty"` // e.g. "3/4 passed"
+}
+
+// taskDetail assembles the detailed status of one task from the design
+// directory, the lock files under hydraDir, and the task's git work
+// directory under workRoot.
+func taskDetail(dd *design.Dir, hydraDir, workRoot, name string, now time.Time) (statusTaskDetail, error) {
+	task, err := dd.FindTaskAny(name)
+	if err != nil {
+		return statusTaskDetail{}, err
+	}
+	label := task.Name
+	wd := filepath.Join(workRoot, task.Name)
+	if task.Group != "" {
+		label = task.Group + "/" + task.Name
+		wd = filepath.Join(workRoot, task.Group, task.Name)
+	}
+
+	detail := statusTaskDetail{
+		Name:     label,
+		State:    string(task.State),
+		Reviewer: runner.Reviewer(task),
+	}
+
+	if repo.IsGitRepo(wd) {
+		detail.WorkDir = wd
+		if sb, ok := branchStatus(wd, task.BranchName(), configuredDefaultBranch(dd), now); ok {
+			detail.Branch = &sb
+		}
+	}
+
+	if running, err := lock.ReadAll(hydraDir); err == nil {
+		for _, rt := range running {
+			if action, runName := parseRunningTask(rt.TaskName); runName == label {
+				detail.Lock = &statusRunning{Action: action, PID: rt.PID}
+				break
+			}
+		}
+	}
+
+	if n := issues.IssueNumber(task); n > 0 {
+		detail.Issue = &statusIssue{Number: n, URL: issueURL(task)}
+	}
+	if date, ok := strings.CutPrefix(task.Group, design.MilestoneTaskGroup("")); ok {
+		detail.Milestone = date
+	}
+
+	entries, err := dd.Record().Entries()
+	if err != nil {
+		return statusTaskDetail{}, err
+	}
+	for _, e := range entries {
+		action, entryName, ok := strings.Cut(e.TaskName, ":")
+		if !ok {
+			action, entryName = "run", e.TaskName
+		}
+		if entryName != label {
+			continue
+		}
+		rec := statusRecord{SHA: e.SHA, Action: action}
+		if len(rec.SHA) > 12 {
+			rec.SHA = rec.SHA[:12]
+		}
+		if e.DurationSeconds > 0 {
+			rec.Duration = formatDuration(time.Duration(e.DurationSeconds * float64(time.Second)))
+			if action == "run" {
+				detail.LastRun = rec.Duration
+			}
+		}
+		if len(e.Checklist) > 0 {
+			passed := 0
+			for _, c := range e.Checklist {
+				if c.Passed {
+					passed++
+				}
+			}
+			rec.Checklist = strconv.Itoa(passed) + "/" + strconv.Itoa(len(e.Checklist)) + " passed"
+		}
+		detail.Records = append(detail.Records, rec)
+	}
+
+	return detail, nil
+}
+
+// issueURL returns the issue URL recorded in an imported issue task, or "".
+func issueURL(task *design.Task) string {
+	content, err := task.Content()
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if u, ok := strings.CutPrefix(scanner.Text(), "URL: "); ok {
+			return strings.TrimSpace(u)
+		}
+	}
+	return ""
+}
+
+// formatDuration renders a duration rounded to the second, e.g. "12m30s".
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// MarshalYAML quotes string values that start with a digit, like
+// statusOutput.MarshalYAML.
+func (s statusTaskDetail) MarshalYAML() (any, error) {
+	type raw statusTaskDetail
+	var n yaml.Node
+	if err := n.Encode(raw(s)); err != nil {
+		return nil, err
+	}
+	quoteDigitScalars(&n)
+	return &n, nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/runner"
+	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/urfave/cli/v2"
+)
+
+func workdirsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "workdirs",
+		Usage: "Report work directory disk usage",
+		Description: "Lists the work directory of every task that has one, largest " +
+			"first, with the task's state, the checked-out branch, the size on disk, " +
+			"and how long ago anything in it last changed. Other checkouts under the " +
+			"work root are listed too, those whose task is gone as orphaned.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output as JSON",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable syntax highlighting of JSON output",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			r, err := newRunner()
+			if err != nil {
+				return err
+			}
+			dirs, err := r.WorkDirs()
+			if err != nil {
+				return err
+			}
+			if c.Bool("json") {
+				return printStatus(c, dirs)
+			}
+			fmt.Print(runner.FormatWorkDirs(dirs, time.Now()))
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "prune",
+				Usage: "Remove old work directories to reclaim disk space",
+				Description: "Runs the teardown command in, and then removes, the work " +
+					"directories of tasks in the given states that have not changed for " +
+					"--older-than. Tasks another hydra command is working on are skipped. " +
+					"A later run, review, or merge of the task re-creates its work directory.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "older-than",
+						Value: "30d",
+						Usage: "Minimum time since last use, as days (30d) or a Go duration (12h)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "state",
+						Value: cli.NewStringSlice(string(design.StateCompleted), string(design.StateAbandoned)),
+						Usage: "Task state to prune (repeatable)",
+					},
+					&cli.BoolFlag{
+						Name:    "dry-run",
+						Aliases: []string{"n"},
+						Usage:   "List the directories that would be removed without removing them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					olderThan, err := taskrun.ParseDuration(c.String("older-than"))
+					if err != nil {
+						return fmt.Errorf("invalid --older-than: %w", err)
+					}
+					states, err := parseStates(c.StringSlice("state"))
+					if err != nil {
+						return err
+					}
+
+					r, err := newRunner()
+					if err != nil {
+						return err
+					}
+					opts := runner.PruneOpts{OlderThan: olderThan, States: states, DryRun: c.Bool("dry-run")}
+					pruned, err := r.PruneWorkDirs(opts, time.Now())
+					if err != nil {
+						return err
+					}
+
+					verb := "Removed"
+					if opts.DryRun {
+						verb = "Would remove"
+					}
+					var total int64
+					for _, d := range pruned {
+						total += d.Size
+						fmt.Printf("%s %s (%s)\n", verb, d.Path, d.Task)
+					}
+					fmt.Printf("%s %d work directories, %s\n", verb, len(pruned), runner.FormatSize(total))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// taskStates are the states --state accepts.
+var taskStates = []design.TaskState{
+	design.StatePending, design.StateReview, design.StateMerge,
+	design.StateCompleted, design.StateAbandoned,
+}
+
+// parseStates converts --state values to task states.
+func parseStates(values []string) ([]design.TaskState, error) {
+	states := make([]design.TaskState, 0, len(values))
+	for _, v := range values {
+		s := design.TaskState(v)
+		if !slices.Contains(taskStates, s) {
+			return nil, fmt.Errorf("invalid --state %q", v)
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestParseStates(t *testing.T) {
+	states, err := parseStates([]string{"completed", "review"})
+	if err != nil || len(states) != 2 || states[0] != design.StateCompleted || states[1] != design.StateReview {
+		t.Errorf("parseStates = %v, %v", states, err)
+	}
+	if _, err := parseStates([]string{"done"}); err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+}
@@ -0,0 +1,112 @@
+package claude
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Actions an approval rule can take on a tool call.
+const (
+	ApprovalAllow  = "allow"  // run the tool call without asking
+	ApprovalPrompt = "prompt" // ask, even with auto-accept on
+	ApprovalDeny   = "deny"   // reject the tool call without asking
+)
+
+// DeniedByRule is the reason given to Claude for a tool call an approval
+// rule rejected.
+const DeniedByRule = "Tool execution was denied by hydra's approval rules. Do not retry it; find another way or explain why it is needed."
+
+// approvalTools are the tool kinds rules can name: the ones that need
+// approval. Reads, listings, and searches never do.
+var approvalTools = map[string]ToolKind{
+	"write": ToolKindWrite,
+	"edit":  ToolKindEdit,
+	"bash":  ToolKindBash,
+}
+
+// ApprovalRule decides the approval of the tool calls it matches, from the
+// approval list in hydra.yml. A rule matches a tool call when every
+// condition it sets holds.
+type ApprovalRule struct {
+	Tools   []string `yaml:"tools"`   // tool kinds: write, edit, bash; empty for all three
+	Command string   `yaml:"command"` // regular expression a bash command must contain a match for
+	Path    string   `yaml:"path"`    // glob the path of a write or edit must match, relative to the work directory
+	Action  string   `yaml:"action"`  // allow, prompt, or deny
+}
+
+// ValidateApprovalRules checks the rules' tools, patterns, and actions.
+func ValidateApprovalRules(rules []ApprovalRule) error {
+	for i, r := range rules {
+		switch r.Action {
+		case ApprovalAllow, ApprovalPrompt, ApprovalDeny:
+		default:
+			return fmt.Errorf("approval rule %d: action %q must be allow, prompt, or deny", i+1, r.Action)
+		}
+		for _, tool := range r.Tools {
+			if _, ok := approvalTools[tool]; !ok {
+				return fmt.Errorf("approval rule %d: unknown tool %q (want write, edit, or bash; reads, listings, and searches never need approval)", i+1, tool)
+			}
+		}
+		if _, err := regexp.Compile(r.Command); err != nil {
+			return fmt.Errorf("approval rule %d: command: %w", i+1, err)
+		}
+		if _, err := filepath.Match(strings.TrimSuffix(r.Path, "/"), ""); err != nil {
+			return fmt.Errorf("approval rule %d: path %q: %w", i+1, r.Path, err)
+		}
+	}
+	return nil
+}
+
+// DecideApproval returns the action of the first rule that matches the
+// tool request, or "" if none does and the usual auto-accept setting
+// applies. Rules are checked by ValidateApprovalRules beforehand.
+func DecideApproval(rules []ApprovalRule, req EventToolRequest) string {
+	for _, r := range rules {
+		if r.matches(req) {
+			return r.Action
+		}
+	}
+	return ""
+}
+
+func (r ApprovalRule) matches(req EventToolRequest) bool {
+	if !slices.Contains(slices.Collect(maps.Values(approvalTools)), req.Meta.Kind) {
+		return false
+	}
+	if len(r.Tools) > 0 && !slices.ContainsFunc(r.Tools, func(t string) bool { return approvalTools[t] == req.Meta.Kind }) {
+		return false
+	}
+	if r.Command != "" {
+		re, err := regexp.Compile(r.Command)
+		if err != nil || req.Meta.Kind != ToolKindBash || !re.MatchString(req.Meta.Command) {
+			return false
+		}
+	}
+	if r.Path != "" && !matchRulePath(r.Path, req.Meta.RelPath) {
+		return false
+	}
+	return true
+}
+
+// matchRulePath matches a path relative to the work directory against a
+// rule's glob. A pattern ending in "/" matches everything under that
+// directory, and one without a "/" matches the file's base name, as in
+// .gitignore. A path outside the work directory matches nothing.
+func matchRulePath(pattern, rel string) bool {
+	if rel == "" {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		return dir == "" || strings.HasPrefix(rel, dir+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		rel = filepath.Base(rel)
+	}
+	ok, _ := filepath.Match(pattern, rel)
+	return ok
+}
@@ -0,0 +1,56 @@
+package claude
+
+import "testing"
+
+func TestValidateApprovalRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ApprovalRule
+		wantErr bool
+	}{
+		{"valid", ApprovalRule{Tools: []string{"bash"}, Command: `git\s+push`, Action: ApprovalPrompt}, false},
+		{"bad action", ApprovalRule{Action: "maybe"}, true},
+		{"missing action", ApprovalRule{Tools: []string{"write"}}, true},
+		{"read tool", ApprovalRule{Tools: []string{"read"}, Action: ApprovalAllow}, true},
+		{"bad command", ApprovalRule{Command: "(", Action: ApprovalDeny}, true},
+		{"bad path", ApprovalRule{Path: "[", Action: ApprovalAllow}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateApprovalRules([]ApprovalRule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateApprovalRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecideApproval(t *testing.T) {
+	rules := []ApprovalRule{
+		{Tools: []string{"bash"}, Command: `git\s+push`, Action: ApprovalPrompt},
+		{Tools: []string{"bash"}, Command: `(^|[;&|]\s*)rm\s`, Action: ApprovalDeny},
+		{Tools: []string{"write", "edit"}, Path: "src/", Action: ApprovalAllow},
+		{Path: "*.lock", Action: ApprovalDeny},
+	}
+	tests := []struct {
+		name string
+		meta ToolMeta
+		want string
+	}{
+		{"push", ToolMeta{Kind: ToolKindBash, Command: "git push origin main"}, ApprovalPrompt},
+		{"rm", ToolMeta{Kind: ToolKindBash, Command: "make && rm -rf out"}, ApprovalDeny},
+		{"other bash", ToolMeta{Kind: ToolKindBash, Command: "go test ./..."}, ""},
+		{"write under src", ToolMeta{Kind: ToolKindWrite, RelPath: "src/a/b.go"}, ApprovalAllow},
+		{"edit elsewhere", ToolMeta{Kind: ToolKindEdit, RelPath: "docs/a.md"}, ""},
+		{"lock file by base name", ToolMeta{Kind: ToolKindEdit, RelPath: "web/yarn.lock"}, ApprovalDeny},
+		{"outside work dir", ToolMeta{Kind: ToolKindWrite}, ""},
+		{"read never matches", ToolMeta{Kind: ToolKindRead, RelPath: "src/a.go"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecideApproval(rules, EventToolRequest{Meta: tt.meta}); got != tt.want {
+				t.Errorf("DecideApproval() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
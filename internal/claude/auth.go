@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/erikh/hydra/internal/credstore"
 )
 
 // Credentials holds the API authentication details.
@@ -17,17 +19,18 @@ type Credentials struct {
 }
 
 // LoadCredentials resolves API credentials.
-// It checks ~/.claude/.credentials.json first, then falls back to ANTHROPIC_API_KEY.
+// It checks ~/.claude/.credentials.json first, then falls back to ANTHROPIC_API_KEY
+// and finally the key saved with "hydra auth login anthropic".
 func LoadCredentials() (*Credentials, error) {
 	if creds, err := loadFromCredentialsFile(); err == nil {
 		return creds, nil
 	}
 
-	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+	if key := credstore.Lookup(credstore.ProviderAnthropic); key != "" {
 		return &Credentials{APIKey: key}, nil
 	}
 
-	return nil, errors.New("no credentials found: set ANTHROPIC_API_KEY or log in with the Claude CLI (~/.claude/.credentials.json)")
+	return nil, errors.New("no credentials found: set ANTHROPIC_API_KEY, run \"hydra auth login anthropic\", or log in with the Claude CLI (~/.claude/.credentials.json)")
 }
 
 func loadFromCredentialsFile() (*Credentials, error) {
@@ -1,9 +1,21 @@
 package claude
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/platform"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // CLIConfig configures a Claude Code CLI invocation.
@@ -14,8 +26,24 @@ type CLIConfig struct {
 	WorkDir    string
 	AutoAccept bool
 	PlanMode   bool
+	Settings   string // extra settings JSON passed with --settings, e.g. hooks
+
+	// FallbackModel is passed with --fallback-model, for the CLI to switch
+	// to when the model is overloaded.
+	FallbackModel string
+
+	MCPConfig    string   // MCP server configuration JSON passed with --mcp-config
+	AllowedTools []string // tools Claude may use without asking, e.g. MCPStateTool
+
+	// Limiter, if set, is waited on before the CLI starts. The CLI's own
+	// requests can't be seen, so the session counts as one request.
+	Limiter *Limiter
 }
 
+// cliStopGrace is how long the claude CLI gets to exit after being asked to
+// stop before it is killed.
+const cliStopGrace = 10 * time.Second
+
 // FindCLI looks for the `claude` binary on PATH.
 // Returns the path or empty string if not found.
 func FindCLI() string {
@@ -34,6 +62,9 @@ func BuildArgs(cfg CLIConfig) []string {
 	if cfg.Model != "" {
 		args = append(args, "--model", cfg.Model)
 	}
+	if cfg.FallbackModel != "" {
+		args = append(args, "--fallback-model", cfg.FallbackModel)
+	}
 
 	switch {
 	case cfg.AutoAccept && cfg.PlanMode:
@@ -44,6 +75,19 @@ func BuildArgs(cfg CLIConfig) []string {
 		args = append(args, "--permission-mode", "plan")
 	}
 
+	if cfg.Settings != "" {
+		args = append(args, "--settings", cfg.Settings)
+	}
+
+	// These flags take a list of values, so they are joined with "=" to keep
+	// them from taking the prompt as well.
+	if cfg.MCPConfig != "" {
+		args = append(args, "--mcp-config="+cfg.MCPConfig)
+	}
+	if len(cfg.AllowedTools) > 0 {
+		args = append(args, "--allowedTools="+strings.Join(cfg.AllowedTools, ","))
+	}
+
 	// Positional argument: starts an interactive session with this prompt.
 	args = append(args, cfg.Prompt)
 
@@ -52,7 +96,19 @@ func BuildArgs(cfg CLIConfig) []string {
 
 // RunCLI invokes the claude CLI as a subprocess with the given config.
 // The process inherits stdin/stdout/stderr for interactive use.
-func RunCLI(ctx context.Context, cfg CLIConfig) error {
+func RunCLI(ctx context.Context, cfg CLIConfig) (err error) {
+	ctx, span := tracing.StartChild(ctx, "claude cli", attribute.String("hydra.model", cfg.Model))
+	defer func() { tracing.End(span, err) }()
+
+	if cfg.Limiter != nil {
+		err := cfg.Limiter.Wait(ctx, func(wait time.Duration) {
+			fmt.Fprintf(os.Stderr, "Rate limit reached; starting Claude Code in %s\n", wait.Round(time.Second))
+		})
+		if err != nil {
+			return fmt.Errorf("waiting for rate limit: %w", err)
+		}
+	}
+
 	args := BuildArgs(cfg)
 
 	cmd := exec.CommandContext(ctx, cfg.CLIPath, args...) //nolint:gosec // CLIPath comes from exec.LookPath, not user input
@@ -61,6 +117,136 @@ func RunCLI(ctx context.Context, cfg CLIConfig) error {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Env = append(os.Environ(), "CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1")
+	// When the context ends (the session's time limit), ask claude to exit
+	// cleanly so it restores the terminal, and kill it if it doesn't.
+	cmd.Cancel = func() error { return platform.Terminate(cmd.Process) }
+	cmd.WaitDelay = cliStopGrace
 
 	return cmd.Run()
 }
+
+// CLISession is what the transcript of a Claude Code session tells about
+// it.
+type CLISession struct {
+	FinalMessage string // text of the last assistant message that had any
+	Tokens       int64  // input and output tokens of the session's API calls
+	LastTool     string // the latest tool call, as Session.LastTool describes it
+}
+
+// ReadCLISession reads a Claude Code session's transcript, given the JSON a
+// hook of the session received on stdin, which names the transcript file.
+func ReadCLISession(hookInput []byte) (*CLISession, error) {
+	t, err := NewCLITranscript(hookInput)
+	if err != nil {
+		return nil, err
+	}
+	session, err := t.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CLITranscript follows the transcript of a Claude Code session as the
+// session writes it, reading each line once.
+type CLITranscript struct {
+	path    string
+	offset  int64
+	counted map[string]bool // message IDs whose usage is counted
+	session CLISession
+}
+
+// NewCLITranscript returns a CLITranscript for the session whose transcript
+// is named by the JSON a hook of the session received on stdin.
+func NewCLITranscript(hookInput []byte) (*CLITranscript, error) {
+	var in struct {
+		TranscriptPath string `json:"transcript_path"`
+	}
+	if err := json.Unmarshal(hookInput, &in); err != nil {
+		return nil, fmt.Errorf("parsing hook input: %w", err)
+	}
+	return &CLITranscript{path: in.TranscriptPath, counted: make(map[string]bool)}, nil
+}
+
+// Path returns where Claude Code keeps the session's transcript.
+func (t *CLITranscript) Path() string {
+	return t.path
+}
+
+// Read reads the lines added to the transcript since the last call and
+// returns the session as of the latest complete line.
+func (t *CLITranscript) Read() (CLISession, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return t.session, fmt.Errorf("opening session transcript: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return t.session, fmt.Errorf("reading session transcript: %w", err)
+	}
+
+	// A line holds a whole message, tool output included. A line without
+	// its newline is still being written, and is left for the next call.
+	reader := bufio.NewReaderSize(f, 1<<20)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return t.session, fmt.Errorf("reading session transcript: %w", err)
+		}
+		t.offset += int64(len(line))
+		t.readLine(line)
+	}
+	return t.session, nil
+}
+
+// readLine adds a transcript line to the session. Claude Code writes a line
+// per content block, each repeating its message's usage, so usage is
+// counted once per message ID.
+func (t *CLITranscript) readLine(line []byte) {
+	var entry struct {
+		Type    string `json:"type"`
+		Message struct {
+			ID      string          `json:"id"`
+			Content json.RawMessage `json:"content"`
+			Usage   struct {
+				InputTokens  int64 `json:"input_tokens"`
+				OutputTokens int64 `json:"output_tokens"`
+			} `json:"usage"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(line, &entry) != nil || entry.Type != "assistant" {
+		return
+	}
+	if id := entry.Message.ID; id == "" || !t.counted[id] {
+		t.counted[id] = true
+		t.session.Tokens += entry.Message.Usage.InputTokens + entry.Message.Usage.OutputTokens
+	}
+	var blocks []struct {
+		Type  string `json:"type"`
+		Text  string `json:"text"`
+		Name  string `json:"name"`
+		Input struct {
+			Command  string `json:"command"`
+			FilePath string `json:"file_path"`
+			Path     string `json:"path"`
+		} `json:"input"`
+	}
+	if json.Unmarshal(entry.Message.Content, &blocks) != nil {
+		return
+	}
+	var text []string
+	for _, b := range blocks {
+		switch {
+		case b.Type == eventTypeText && b.Text != "":
+			text = append(text, b.Text)
+		case b.Type == eventTypeToolUse:
+			t.session.LastTool = toolDescription(b.Name, b.Input.Command, cmp.Or(b.Input.FilePath, b.Input.Path))
+		}
+	}
+	if len(text) > 0 {
+		t.session.FinalMessage = strings.Join(text, "\n\n")
+	}
+}
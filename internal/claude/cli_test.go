@@ -1,7 +1,13 @@
 package claude
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestFindCLI(t *testing.T) {
@@ -26,6 +32,23 @@ func TestBuildArgs(t *testing.T) {
 			},
 			want: []string{"hello world"},
 		},
+		{
+			name: "settings",
+			cfg: CLIConfig{
+				Prompt:   "hello world",
+				Settings: `{"hooks":{}}`,
+			},
+			want: []string{"--settings", `{"hooks":{}}`, "hello world"},
+		},
+		{
+			name: "mcp config and allowed tools",
+			cfg: CLIConfig{
+				Prompt:       "hello world",
+				MCPConfig:    `{"mcpServers":{}}`,
+				AllowedTools: []string{MCPStateTool},
+			},
+			want: []string{`--mcp-config={"mcpServers":{}}`, "--allowedTools=mcp__hydra__hydra_state", "hello world"},
+		},
 		{
 			name: "default: auto-accept and plan",
 			cfg: CLIConfig{
@@ -70,6 +93,15 @@ func TestBuildArgs(t *testing.T) {
 			},
 			want: []string{"--model", "claude-opus-4-6", "--permission-mode", "plan", "do something"},
 		},
+		{
+			name: "with model and fallback",
+			cfg: CLIConfig{
+				Prompt:        "do something",
+				Model:         "claude-opus-4-6",
+				FallbackModel: "claude-sonnet-4-5",
+			},
+			want: []string{"--model", "claude-opus-4-6", "--fallback-model", "claude-sonnet-4-5", "do something"},
+		},
 		{
 			name: "with model, auto-accept only",
 			cfg: CLIConfig{
@@ -95,3 +127,90 @@ func TestBuildArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestReadCLISession(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	lines := `{"type":"user","message":{"content":"Do the task."}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Checking."}],"usage":{"input_tokens":100,"output_tokens":20}}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./...\necho"}}],"usage":{"input_tokens":100,"output_tokens":20}}}
+{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"text","text":"All done."}],"usage":{"input_tokens":150,"output_tokens":5}}}
+{"type":"assistant","message":{"id":"m3","content":[{"type":"tool_use","name":"Read","input":{"file_path":"main.go"}}],"usage":{"input_tokens":10,"output_tokens":1}}}
+not json
+`
+	if err := os.WriteFile(transcript, []byte(lines), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input, _ := json.Marshal(map[string]string{"hook_event_name": "Stop", "transcript_path": transcript})
+
+	got, err := ReadCLISession(input)
+	if err != nil {
+		t.Fatalf("ReadCLISession: %v", err)
+	}
+	if got.FinalMessage != "All done." {
+		t.Errorf("FinalMessage = %q, want the last assistant text", got.FinalMessage)
+	}
+	if got.Tokens != 286 {
+		t.Errorf("Tokens = %d, want 286, counting each message once", got.Tokens)
+	}
+	if got.LastTool != "Read: main.go" {
+		t.Errorf("LastTool = %q, want the latest tool call", got.LastTool)
+	}
+
+	if _, err := ReadCLISession([]byte(`{"transcript_path":"/nonexistent"}`)); err == nil {
+		t.Error("expected an error for a missing transcript")
+	}
+}
+
+func TestCLITranscriptFollows(t *testing.T) {
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	first := `{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","name":"Bash","input":{"command":"make"}}],"usage":{"input_tokens":100,"output_tokens":10}}}` + "\n"
+	partial := `{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","name":"Edit","input":{"file_path":"a.go"}}],`
+	if err := os.WriteFile(transcript, []byte(first+partial), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input, _ := json.Marshal(map[string]string{"transcript_path": transcript})
+	tr, err := NewCLITranscript(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tr.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Tokens != 110 || got.LastTool != "Bash: make" {
+		t.Errorf("first Read = %+v, want the complete line only", got)
+	}
+
+	f, err := os.OpenFile(transcript, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString(`"usage":{"input_tokens":200,"output_tokens":20}}}` + "\n")
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err = tr.Read(); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Tokens != 330 || got.LastTool != "Edit: a.go" {
+		t.Errorf("second Read = %+v, want the finished line added", got)
+	}
+}
+
+func TestRunCLIWaitsForRateLimit(t *testing.T) {
+	limiter := NewLimiter(filepath.Join(t.TempDir(), "ratelimit.json"), RateLimit{RequestsPerMinute: 1})
+	if err := limiter.Wait(t.Context(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bucket is empty for the next minute, so the CLI never starts.
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	err := RunCLI(ctx, CLIConfig{CLIPath: "false", Limiter: limiter})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RunCLI = %v, want the rate limit wait to end with the context", err)
+	}
+}
@@ -1,6 +1,8 @@
 package claude
 
 import (
+	"os/exec"
+
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 )
@@ -8,14 +10,33 @@ import (
 // DefaultModel is the model used when none is specified.
 const DefaultModel = "claude-opus-4-6"
 
+// KnownModels are the models offered when completing --model in the shell,
+// newest first. Any other model name can still be given.
+var KnownModels = []string{
+	string(anthropic.ModelClaudeOpus4_6),
+	string(anthropic.ModelClaudeSonnet4_6),
+	string(anthropic.ModelClaudeOpus4_5),
+	string(anthropic.ModelClaudeSonnet4_5),
+	string(anthropic.ModelClaudeHaiku4_5),
+}
+
 // DefaultMaxTokens is the default maximum token count.
 const DefaultMaxTokens = 16384
 
 // ClientConfig configures the API client.
 type ClientConfig struct {
 	Model     string
+	Fallbacks []string // models to switch to, in order, when Model stays overloaded
 	MaxTokens int64
 	RepoDir   string
+	RateLimit RateLimit // shared request and token limits; zero means none
+
+	// Shell builds the command the bash tool runs, e.g. inside a container;
+	// nil runs it with bash on the host.
+	Shell func(command string) *exec.Cmd
+
+	// State answers the hydra_state tool; nil leaves the tool out.
+	State StateFunc
 }
 
 // Client wraps the Anthropic SDK client with hydra-specific configuration.
@@ -24,6 +45,9 @@ type Client struct {
 	Config ClientConfig
 	Tools  []anthropic.ToolUnionParam
 	System string
+
+	// Limiter throttles requests when a rate limit is configured; nil otherwise.
+	Limiter *Limiter
 }
 
 // NewClient creates a Client from credentials and configuration.
@@ -44,15 +68,27 @@ func NewClient(creds *Credentials, cfg ClientConfig) (*Client, error) {
 
 	sdk := anthropic.NewClient(opts...)
 
-	return &Client{
-		SDK:    sdk,
-		Config: cfg,
-		Tools:  ToolDefinitions(),
+	limiter, err := SharedLimiter(cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		SDK:     sdk,
+		Config:  cfg,
+		Tools:   ToolDefinitions(),
+		Limiter: limiter,
 		System: "You are a software engineering assistant executing a specific task. " +
 			"Read the task document carefully and implement exactly what it asks — nothing more, nothing less. " +
 			"Do not make unrelated changes, refactor surrounding code, or add features not described in the task. " +
 			"You have access to tools for reading, writing, and editing files, running bash commands, " +
 			"listing files, and searching file contents. Work within the repository directory. " +
 			"Be precise and make minimal changes.",
-	}, nil
+	}
+	if cfg.State != nil {
+		client.Tools = append(client.Tools, stateToolDefinition())
+		client.System += " Use the hydra_state tool to look up the task's state, related tasks, history, " +
+			"and the functional requirements that cover the files you touch."
+	}
+	return client, nil
 }
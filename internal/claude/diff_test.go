@@ -0,0 +1,61 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffIdentical(t *testing.T) {
+	diff := UnifiedDiff("f.go", "a\nb\n", "a\nb\n")
+	if diff != "--- a/f.go\n+++ b/f.go\n" {
+		t.Errorf("identical content should produce only the header:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiffMinimal(t *testing.T) {
+	old := "one\ntwo\nthree\nfour\n"
+	updated := "one\nTWO\nthree\nfour\nfive\n"
+	want := "--- a/f.txt\n+++ b/f.txt\n" +
+		"@@ -1,4 +1,5 @@\n" +
+		" one\n-two\n+TWO\n three\n four\n+five\n"
+	if got := UnifiedDiff("f.txt", old, updated); got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var oldLines, newLines []string
+	for i := range 30 {
+		line := strings.Repeat("x", i+1)
+		oldLines = append(oldLines, line)
+		switch i {
+		case 2:
+			newLines = append(newLines, "changed-early")
+		case 25:
+			newLines = append(newLines, "changed-late")
+		default:
+			newLines = append(newLines, line)
+		}
+	}
+
+	diff := UnifiedDiff("f.txt", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+	if n := strings.Count(diff, "@@ -"); n != 2 {
+		t.Fatalf("got %d hunks, want 2:\n%s", n, diff)
+	}
+	if !strings.Contains(diff, "@@ -1,6 +1,6 @@\n") {
+		t.Errorf("first hunk header wrong:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -23,7 +23,7 @@\n") {
+		t.Errorf("second hunk header wrong:\n%s", diff)
+	}
+	if strings.Contains(diff, " "+strings.Repeat("x", 15)+"\n") {
+		t.Errorf("lines far from any change should be omitted:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiffNewFile(t *testing.T) {
+	want := "--- a/new.go\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+package main\n+\n"
+	if got := UnifiedDiff("new.go", "", "package main\n\n"); got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+}
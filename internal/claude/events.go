@@ -1,7 +1,10 @@
 // Package claude provides direct Anthropic API integration for hydra.
 package claude
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Event is the interface for all session events sent to the TUI.
 type Event interface {
@@ -39,6 +42,7 @@ const (
 type ToolMeta struct {
 	Kind    ToolKind
 	Path    string // file path for read/write/edit/list/search
+	RelPath string // Path relative to the repository root; empty if it is outside it
 	Diff    string // unified diff for write/edit (before execution)
 	Command string // shell command for bash
 	Content string // file content for write
@@ -63,6 +67,42 @@ type EventToolResult struct {
 
 func (EventToolResult) eventMarker() {}
 
+// EventRateLimited signals that the session is waiting for the rate limiter
+// or for the API to accept requests again.
+type EventRateLimited struct {
+	Wait time.Duration
+}
+
+func (EventRateLimited) eventMarker() {}
+
+// EventModelFallback signals that the model stayed overloaded or
+// unavailable, and the session switched to the next fallback model.
+type EventModelFallback struct {
+	From string
+	To   string
+}
+
+func (EventModelFallback) eventMarker() {}
+
+// EventInjected signals that a message from hydra, such as a reminder
+// that time is nearly up, was added to the conversation.
+type EventInjected struct {
+	Text string
+}
+
+func (EventInjected) eventMarker() {}
+
+// EventUsage reports the session's token usage as a response streams in.
+// Input and output are totals for the session so far; Context is the size
+// of the conversation as of the latest request and its response.
+type EventUsage struct {
+	InputTokens   int64
+	OutputTokens  int64
+	ContextTokens int64
+}
+
+func (EventUsage) eventMarker() {}
+
 // EventDone signals the conversation has ended.
 type EventDone struct {
 	StopReason string
@@ -81,4 +121,5 @@ func (EventError) eventMarker() {}
 type ToolAnswer struct {
 	ID       string
 	Approved bool
+	Reason   string // why a rejected call was rejected, for Claude; empty for the user's choice
 }
@@ -0,0 +1,133 @@
+package claude
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MCPServerName is the name the hydra_state MCP server is configured under
+// for the Claude Code CLI, which calls its tool mcp__hydra__hydra_state.
+const MCPServerName = "hydra"
+
+// MCPStateTool is the name the Claude Code CLI gives the hydra_state tool
+// of the MCP server, for allowing it without a permission prompt.
+const MCPStateTool = "mcp__" + MCPServerName + "__" + toolHydraState
+
+// mcpProtocolVersion is the MCP version offered to clients that don't ask
+// for one.
+const mcpProtocolVersion = "2024-11-05"
+
+// MCPConfig returns Claude Code CLI MCP configuration JSON that starts
+// command, a process serving the hydra_state tool with ServeStateMCP.
+func MCPConfig(command []string) string {
+	server := map[string]any{"type": "stdio", "command": command[0], "args": command[1:]}
+	data, _ := json.Marshal(map[string]any{"mcpServers": map[string]any{MCPServerName: server}}) // plain strings and maps always marshal
+	return string(data)
+}
+
+// mcpRequest is a JSON-RPC request or notification from an MCP client.
+type mcpRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"` // absent for notifications
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// mcpError is a JSON-RPC error.
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeStateMCP serves the hydra_state tool over MCP's stdio transport,
+// reading newline-delimited JSON-RPC messages from in and writing replies to
+// out, until in ends or ctx is done. It is how Claude Code CLI sessions,
+// which run their own tools, reach hydra_state.
+func ServeStateMCP(ctx context.Context, in io.Reader, out io.Writer, state StateFunc) error {
+	enc := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var req mcpRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if err := enc.Encode(map[string]any{"jsonrpc": "2.0", "id": nil, "error": mcpError{Code: -32700, Message: "parse error"}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(req.ID) == 0 {
+			continue // notifications need no reply
+		}
+
+		reply := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		result, rpcErr := handleMCP(req, state)
+		if rpcErr != nil {
+			reply["error"] = rpcErr
+		} else {
+			reply["result"] = result
+		}
+		if err := enc.Encode(reply); err != nil {
+			return fmt.Errorf("writing MCP reply: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// handleMCP answers one MCP request.
+func handleMCP(req mcpRequest, state StateFunc) (any, *mcpError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params) // a missing version gets the default
+		version := params.ProtocolVersion
+		if version == "" {
+			version = mcpProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": MCPServerName, "version": "1"},
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		return map[string]any{"tools": []any{map[string]any{
+			"name":        toolHydraState,
+			"description": stateToolDescription,
+			"inputSchema": map[string]any{"type": "object", "properties": stateToolProperties},
+		}}}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &mcpError{Code: -32602, Message: "invalid params: " + err.Error()}
+		}
+		if params.Name != toolHydraState {
+			return nil, &mcpError{Code: -32602, Message: "unknown tool: " + params.Name}
+		}
+		text, err := callState(state, params.Arguments)
+		isError := err != nil
+		if err != nil {
+			text = err.Error()
+		}
+		return map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": text}},
+			"isError": isError,
+		}, nil
+
+	default:
+		return nil, &mcpError{Code: -32601, Message: "method not found: " + req.Method}
+	}
+}
@@ -0,0 +1,98 @@
+package claude
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mcpTestReply holds the parts of MCP replies TestServeStateMCP checks.
+type mcpTestReply struct {
+	ID     int `json:"id"`
+	Result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Tools           []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	} `json:"result"`
+	Error *mcpError `json:"error"`
+}
+
+func TestServeStateMCP(t *testing.T) {
+	var gotTopic string
+	var gotFiles []string
+	state := func(topic string, files []string) (string, error) {
+		gotTopic, gotFiles = topic, files
+		if topic == "group" {
+			return "", errors.New("task has no group")
+		}
+		return "state: review", nil
+	}
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"hydra_state","arguments":{"topic":"functional","files":"a.go b/c.go"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"hydra_state","arguments":{"topic":"group"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := ServeStateMCP(context.Background(), strings.NewReader(in), &out, state); err != nil {
+		t.Fatalf("ServeStateMCP: %v", err)
+	}
+
+	var replies []mcpTestReply
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r mcpTestReply
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("decoding reply: %v", err)
+		}
+		replies = append(replies, r)
+	}
+
+	if len(replies) != 5 {
+		t.Fatalf("got %d replies, want 5 (the notification gets none): %s", len(replies), out.String())
+	}
+	if replies[0].Result.ProtocolVersion != "2025-06-18" {
+		t.Errorf("initialize protocolVersion = %q", replies[0].Result.ProtocolVersion)
+	}
+	if len(replies[1].Result.Tools) != 1 || replies[1].Result.Tools[0].Name != toolHydraState {
+		t.Errorf("tools/list = %+v", replies[1].Result.Tools)
+	}
+	if c := replies[2].Result.Content; len(c) != 1 || c[0].Text != "state: review" || replies[2].Result.IsError {
+		t.Errorf("tools/call result = %+v", replies[2].Result)
+	}
+	if gotTopic != "group" || len(gotFiles) != 0 {
+		t.Errorf("last call got topic %q files %v", gotTopic, gotFiles)
+	}
+	if !replies[3].Result.IsError || replies[3].Result.Content[0].Text != "task has no group" {
+		t.Errorf("failed tools/call result = %+v", replies[3].Result)
+	}
+	if replies[4].Error == nil || replies[4].Error.Code != -32601 {
+		t.Errorf("unknown method reply error = %+v", replies[4].Error)
+	}
+}
+
+func TestCallStateFiles(t *testing.T) {
+	var got []string
+	_, err := callState(func(_ string, files []string) (string, error) {
+		got = files
+		return "", nil
+	}, json.RawMessage(`{"files":"a.go  b/c.go"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "a.go" || got[1] != "b/c.go" {
+		t.Errorf("files = %v", got)
+	}
+}
@@ -0,0 +1,230 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erikh/hydra/internal/platform"
+	"go.yaml.in/yaml/v4"
+)
+
+// RateLimit configures the API rate limiter. A zero field disables that limit.
+type RateLimit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute"`
+}
+
+// Enabled reports whether any limit is set.
+func (rl RateLimit) Enabled() bool {
+	return rl.RequestsPerMinute > 0 || rl.TokensPerMinute > 0
+}
+
+// LoadRateLimit reads the rate_limit section of ~/.hydra.yml. A missing or
+// unreadable file means no limit.
+func LoadRateLimit() RateLimit {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return RateLimit{}
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".hydra.yml")) //nolint:gosec // well-known user config path
+	if err != nil {
+		return RateLimit{}
+	}
+
+	var cfg struct {
+		RateLimit RateLimit `yaml:"rate_limit"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return RateLimit{}
+	}
+	return cfg.RateLimit
+}
+
+// SharedLimiter returns a Limiter on the state file every hydra process of
+// the current user shares, or nil if limit is not enabled.
+func SharedLimiter(limit RateLimit) (*Limiter, error) {
+	if !limit.Enabled() {
+		return nil, nil
+	}
+	path, err := DefaultLimiterPath()
+	if err != nil {
+		return nil, fmt.Errorf("setting up rate limiter: %w", err)
+	}
+	return NewLimiter(path, limit), nil
+}
+
+// DefaultLimiterPath returns the state file shared by every hydra process of
+// the current user: <user cache dir>/hydra/ratelimit.json.
+func DefaultLimiterPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache dir: %w", err)
+	}
+	return filepath.Join(dir, "hydra", "ratelimit.json"), nil
+}
+
+// Limiter is a token-bucket rate limiter shared between hydra processes on
+// one machine. Both buckets hold one minute of budget and refill
+// continuously. The bucket levels live in a JSON state file guarded by an
+// advisory file lock, so parallel group runs and separate hydra instances
+// draw from the same budget.
+//
+// Token costs are only known once a response finishes, so a request is let
+// through while the token bucket is positive and the actual usage is
+// charged afterwards with Record, possibly driving the bucket negative.
+type Limiter struct {
+	path  string
+	limit RateLimit
+	now   func() time.Time
+}
+
+// bucketState is the on-disk state of a Limiter.
+type bucketState struct {
+	Requests     float64   `json:"requests"`
+	Tokens       float64   `json:"tokens"`
+	Updated      time.Time `json:"updated"`
+	BlockedUntil time.Time `json:"blocked_until,omitzero"`
+}
+
+// NewLimiter creates a Limiter keeping its state in path.
+func NewLimiter(path string, limit RateLimit) *Limiter {
+	return &Limiter{path: path, limit: limit, now: time.Now}
+}
+
+// Wait blocks until a request may be sent, then takes one request from the
+// bucket. onWait, if set, is called with each wait before it starts.
+func (l *Limiter) Wait(ctx context.Context, onWait func(time.Duration)) error {
+	for {
+		var wait time.Duration
+		err := l.update(func(st *bucketState, now time.Time) {
+			wait = l.reserve(st, now)
+		})
+		if err != nil {
+			return err
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		if onWait != nil {
+			onWait(wait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Record charges tokens used by a finished request to the token bucket.
+func (l *Limiter) Record(tokens int64) error {
+	if l.limit.TokensPerMinute <= 0 || tokens <= 0 {
+		return nil
+	}
+	return l.update(func(st *bucketState, _ time.Time) {
+		st.Tokens -= float64(tokens)
+	})
+}
+
+// Block holds back every process sharing the limiter for d, after the API
+// has rejected a request with a rate limit error.
+func (l *Limiter) Block(d time.Duration) error {
+	return l.update(func(st *bucketState, now time.Time) {
+		if until := now.Add(d); until.After(st.BlockedUntil) {
+			st.BlockedUntil = until
+		}
+	})
+}
+
+// reserve takes a request from the buckets if possible and returns zero, or
+// returns how long to wait before trying again.
+func (l *Limiter) reserve(st *bucketState, now time.Time) time.Duration {
+	var wait time.Duration
+	if now.Before(st.BlockedUntil) {
+		wait = st.BlockedUntil.Sub(now)
+	}
+	if rpm := l.limit.RequestsPerMinute; rpm > 0 && st.Requests < 1 {
+		wait = max(wait, refillTime(1-st.Requests, rpm))
+	}
+	if tpm := l.limit.TokensPerMinute; tpm > 0 && st.Tokens <= 0 {
+		// Wait until the bucket holds at least one token again.
+		wait = max(wait, refillTime(1-st.Tokens, tpm))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.limit.RequestsPerMinute > 0 {
+		st.Requests--
+	}
+	return 0
+}
+
+// refillTime returns how long a bucket refilling at perMinute takes to gain
+// amount.
+func refillTime(amount float64, perMinute int) time.Duration {
+	secs := amount * 60 / float64(perMinute)
+	return time.Duration(math.Ceil(secs * float64(time.Second)))
+}
+
+// refill tops up the buckets for the time elapsed since the last update.
+func (l *Limiter) refill(st *bucketState, now time.Time) {
+	rpm, tpm := float64(l.limit.RequestsPerMinute), float64(l.limit.TokensPerMinute)
+	if st.Updated.IsZero() {
+		st.Requests, st.Tokens = rpm, tpm
+	} else if elapsed := now.Sub(st.Updated).Minutes(); elapsed > 0 {
+		st.Requests = min(rpm, st.Requests+elapsed*rpm)
+		st.Tokens = min(tpm, st.Tokens+elapsed*tpm)
+	}
+	st.Updated = now
+}
+
+// update applies fn to the refilled state while holding the state file lock.
+func (l *Limiter) update(fn func(st *bucketState, now time.Time)) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("creating rate limit directory: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o600) //nolint:gosec // path from hydra's cache dir
+	if err != nil {
+		return fmt.Errorf("opening rate limit state: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := platform.LockFile(f); err != nil {
+		return fmt.Errorf("locking rate limit state: %w", err)
+	}
+	defer func() { _ = platform.UnlockFile(f) }()
+
+	var st bucketState
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(&st); err != nil {
+		// Empty or corrupt state starts over with full buckets.
+		st = bucketState{}
+	}
+
+	now := l.now()
+	l.refill(&st, now)
+	fn(&st, now)
+
+	data, err := json.Marshal(&st)
+	if err != nil {
+		return fmt.Errorf("marshaling rate limit state: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("writing rate limit state: %w", err)
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("writing rate limit state: %w", err)
+	}
+	return nil
+}
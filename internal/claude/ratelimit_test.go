@@ -0,0 +1,117 @@
+package claude
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testLimiter returns a Limiter with a fake clock that starts at a fixed time.
+func testLimiter(t *testing.T, limit RateLimit) (*Limiter, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(filepath.Join(t.TempDir(), "ratelimit.json"), limit)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+// reserve runs one reservation against the limiter's stored state.
+func reserve(t *testing.T, l *Limiter) time.Duration {
+	t.Helper()
+	var wait time.Duration
+	if err := l.update(func(st *bucketState, now time.Time) {
+		wait = l.reserve(st, now)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return wait
+}
+
+func TestLimiterRequestsPerMinute(t *testing.T) {
+	l, now := testLimiter(t, RateLimit{RequestsPerMinute: 2})
+
+	for i := range 2 {
+		if wait := reserve(t, l); wait != 0 {
+			t.Fatalf("request %d waited %s, want none", i, wait)
+		}
+	}
+	if wait := reserve(t, l); wait != 30*time.Second {
+		t.Fatalf("third request wait = %s, want 30s", wait)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if wait := reserve(t, l); wait != 0 {
+		t.Errorf("request after refill waited %s", wait)
+	}
+}
+
+func TestLimiterTokensPerMinute(t *testing.T) {
+	l, now := testLimiter(t, RateLimit{TokensPerMinute: 6000})
+
+	if wait := reserve(t, l); wait != 0 {
+		t.Fatalf("first request waited %s", wait)
+	}
+	if err := l.Record(8999); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bucket is at -2999 tokens and refills at 100 tokens a second.
+	if wait := reserve(t, l); wait != 30*time.Second {
+		t.Fatalf("wait after overspending = %s, want 30s", wait)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if wait := reserve(t, l); wait != 0 {
+		t.Errorf("request after refill waited %s", wait)
+	}
+}
+
+func TestLimiterSharedState(t *testing.T) {
+	l, now := testLimiter(t, RateLimit{RequestsPerMinute: 1})
+	other := NewLimiter(l.path, l.limit)
+	other.now = func() time.Time { return *now }
+
+	if wait := reserve(t, l); wait != 0 {
+		t.Fatalf("first request waited %s", wait)
+	}
+	if wait := reserve(t, other); wait == 0 {
+		t.Error("second limiter on the same state file should wait")
+	}
+}
+
+func TestLimiterBlock(t *testing.T) {
+	l, now := testLimiter(t, RateLimit{RequestsPerMinute: 100})
+
+	if err := l.Block(20 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if wait := reserve(t, l); wait != 20*time.Second {
+		t.Errorf("wait while blocked = %s, want 20s", wait)
+	}
+
+	*now = now.Add(20 * time.Second)
+	if wait := reserve(t, l); wait != 0 {
+		t.Errorf("request after block waited %s", wait)
+	}
+}
+
+func TestLimiterWaitHonorsContext(t *testing.T) {
+	l, _ := testLimiter(t, RateLimit{RequestsPerMinute: 1})
+	if err := l.Wait(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	err := l.Wait(ctx, func(d time.Duration) {
+		waits = append(waits, d)
+		cancel()
+	})
+	if err == nil {
+		t.Fatal("expected context error")
+	}
+	if len(waits) != 1 || waits[0] != time.Minute {
+		t.Errorf("waits = %v, want [1m0s]", waits)
+	}
+}
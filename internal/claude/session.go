@@ -3,8 +3,18 @@ package claude
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Stream event type constants.
@@ -23,6 +33,25 @@ const (
 	eventTypeMessageStop       = "message_stop"
 )
 
+// maxRateLimitRetries is how many times a request rejected by the API's rate
+// limit is retried before the session fails.
+const maxRateLimitRetries = 5
+
+// defaultRateLimitBackoff is the wait after a rate limit error that carries
+// no retry-after header.
+const defaultRateLimitBackoff = 30 * time.Second
+
+// maxOverloadRetries is how many times a request rejected because the model
+// is overloaded or unavailable is retried before the session switches to the
+// next fallback model.
+const maxOverloadRetries = 2
+
+// overloadBackoff is the wait before retrying an overloaded model.
+const overloadBackoff = 10 * time.Second
+
+// statusOverloaded is the status the API returns when a model is overloaded.
+const statusOverloaded = 529
+
 // Session manages an agentic conversation with the Anthropic API.
 type Session struct {
 	client     *Client
@@ -30,6 +59,19 @@ type Session struct {
 	ToolAnswer chan ToolAnswer
 	cancel     context.CancelFunc
 	messages   []anthropic.MessageParam
+
+	mu        sync.Mutex
+	injected  []string // messages from hydra waiting for the next request
+	model     string   // the model requests go to
+	fallbacks []string // models left to switch to, in order
+	lastTool  string   // the tool call most recently started, for LastTool
+	finalText string   // text of the latest assistant message, for FinalText
+
+	tokens atomic.Int64 // input and output tokens used so far
+
+	// Input and output tokens of completed requests, for EventUsage. Only
+	// the loop goroutine touches them.
+	inputTokens, outputTokens int64
 }
 
 // NewSession creates a new Session tied to the given client.
@@ -38,6 +80,8 @@ func NewSession(client *Client) *Session {
 		client:     client,
 		Events:     make(chan Event, 64),
 		ToolAnswer: make(chan ToolAnswer, 1),
+		model:      client.Config.Model,
+		fallbacks:  slices.Clone(client.Config.Fallbacks),
 	}
 }
 
@@ -59,6 +103,105 @@ func (s *Session) Cancel() {
 	}
 }
 
+// Tokens returns the input and output tokens the session has used so far.
+func (s *Session) Tokens() int64 {
+	return s.tokens.Load()
+}
+
+// LastTool describes the tool call the session most recently started, such
+// as "bash: go test ./..." or "read_file: main.go", or returns "" before the
+// first one.
+func (s *Session) LastTool() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastTool
+}
+
+// FinalText returns the text of the latest assistant message that had any:
+// once the session ends, Claude's final message.
+func (s *Session) FinalText() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finalText
+}
+
+// setLastTool records the tool call about to run for LastTool.
+func (s *Session) setLastTool(name string, meta ToolMeta) {
+	desc := toolDescription(name, meta.Command, meta.Path)
+	s.mu.Lock()
+	s.lastTool = desc
+	s.mu.Unlock()
+}
+
+// toolDescription describes a tool call by its name and the first line of
+// its command or the path it works on, as Session.LastTool reports it.
+func toolDescription(name, command, path string) string {
+	switch {
+	case command != "":
+		return name + ": " + strings.SplitN(command, "\n", 2)[0]
+	case path != "":
+		return name + ": " + path
+	}
+	return name
+}
+
+// Model returns the model the session is using: the client's model, or the
+// fallback it switched to after that model stayed overloaded.
+func (s *Session) Model() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.model
+}
+
+// nextModel switches the session to the next fallback model, skipping any
+// that name the current one, and tells the TUI. It returns false if there
+// is none left.
+func (s *Session) nextModel() bool {
+	s.mu.Lock()
+	from := s.model
+	var to string
+	for len(s.fallbacks) > 0 && to == "" {
+		if s.fallbacks[0] != from {
+			to = s.fallbacks[0]
+		}
+		s.fallbacks = s.fallbacks[1:]
+	}
+	if to != "" {
+		s.model = to
+	}
+	s.mu.Unlock()
+
+	if to == "" {
+		return false
+	}
+	s.Events <- EventModelFallback{From: from, To: to}
+	return true
+}
+
+// Inject queues a message from hydra to be added to the conversation with
+// the next request, after any pending tool results. It has no effect once
+// the conversation has ended.
+func (s *Session) Inject(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected = append(s.injected, text)
+}
+
+// appendInjected adds the queued messages to the last user turn, which is
+// the one about to be sent, and reports them to the TUI.
+func (s *Session) appendInjected() {
+	s.mu.Lock()
+	texts := s.injected
+	s.injected = nil
+	s.mu.Unlock()
+
+	last := &s.messages[len(s.messages)-1]
+	for _, text := range texts {
+		last.Content = append(last.Content, anthropic.NewTextBlock(text))
+		s.Events <- EventInjected{Text: text}
+	}
+}
+
 func (s *Session) loop(ctx context.Context) {
 	defer close(s.Events)
 
@@ -96,11 +239,24 @@ type streamState struct {
 	currentBlockType string
 	currentToolUse   *toolUseInfo
 	currentText      string
+	streamed         bool // content has been sent to the TUI
+	inputTokens      int64
+	outputTokens     int64
 }
 
-func (s *Session) sendAndStream(ctx context.Context) (string, error) {
+func (s *Session) sendAndStream(ctx context.Context) (stopReason string, err error) {
+	ctx, span := tracing.StartChild(ctx, "claude message",
+		attribute.String("hydra.model", s.Model()),
+		attribute.Int("hydra.messages", len(s.messages)),
+	)
+	defer func() {
+		span.SetAttributes(attribute.String("hydra.stop_reason", stopReason))
+		tracing.End(span, err)
+	}()
+
+	s.appendInjected()
+
 	params := anthropic.MessageNewParams{
-		Model:     anthropic.Model(s.client.Config.Model),
 		MaxTokens: s.client.Config.MaxTokens,
 		Messages:  s.messages,
 		Tools:     s.client.Tools,
@@ -109,6 +265,86 @@ func (s *Session) sendAndStream(ctx context.Context) (string, error) {
 		},
 	}
 
+	var st *streamState
+	var rateLimited, overloaded int
+	for {
+		if err := s.waitForRateLimit(ctx); err != nil {
+			return "", err
+		}
+
+		var streamErr error
+		params.Model = anthropic.Model(s.Model())
+		st, streamErr = s.stream(ctx, params)
+		if streamErr == nil {
+			break
+		}
+
+		// Rate limit and overload rejections arrive before any output, so
+		// the request can be sent again without duplicating text in the TUI.
+		if st.streamed {
+			return "", streamErr
+		}
+		if modelOverloaded(streamErr) {
+			overloaded++
+			if overloaded > maxOverloadRetries {
+				if !s.nextModel() {
+					return "", streamErr
+				}
+				overloaded = 0
+				continue
+			}
+			s.Events <- EventRateLimited{Wait: overloadBackoff}
+			if err := sleepContext(ctx, overloadBackoff); err != nil {
+				return "", err
+			}
+			continue
+		}
+		wait, limited := rateLimitBackoff(streamErr)
+		if !limited || rateLimited >= maxRateLimitRetries {
+			return "", streamErr
+		}
+		rateLimited++
+		if err := s.backOff(ctx, wait); err != nil {
+			return "", err
+		}
+	}
+
+	// Append assistant message.
+	if len(st.assistantBlocks) > 0 {
+		s.messages = append(s.messages, anthropic.MessageParam{
+			Role:    anthropic.MessageParamRoleAssistant,
+			Content: st.assistantBlocks,
+		})
+	}
+	if text := assistantText(st.assistantBlocks); text != "" {
+		s.mu.Lock()
+		s.finalText = text
+		s.mu.Unlock()
+	}
+
+	// Process tool uses.
+	if len(st.toolUses) > 0 {
+		if err := s.processToolUses(ctx, st); err != nil {
+			return "", err
+		}
+	}
+
+	return st.stopReason, nil
+}
+
+// assistantText joins the text blocks of an assistant message.
+func assistantText(blocks []anthropic.ContentBlockParamUnion) string {
+	var text []string
+	for _, b := range blocks {
+		if b.OfText != nil && b.OfText.Text != "" {
+			text = append(text, b.OfText.Text)
+		}
+	}
+	return strings.Join(text, "\n\n")
+}
+
+// stream sends one request and accumulates the streamed response.
+func (s *Session) stream(ctx context.Context, params anthropic.MessageNewParams) (*streamState, error) {
 	stream := s.client.SDK.Messages.NewStreaming(ctx, params)
 	defer func() { _ = stream.Close() }()
 
@@ -119,9 +355,11 @@ func (s *Session) sendAndStream(ctx context.Context) (string, error) {
 
 		switch event.Type {
 		case eventTypeMessageStart:
-			// Nothing needed.
+			st.inputTokens = event.AsMessageStart().Message.Usage.InputTokens
+			s.reportUsage(st)
 		case eventTypeMessageDelta:
 			s.handleMessageDelta(event, st)
+			s.reportUsage(st)
 		case eventTypeContentBlockStart:
 			s.handleContentBlockStart(event, st)
 		case eventTypeContentBlockDelta:
@@ -133,36 +371,98 @@ func (s *Session) sendAndStream(ctx context.Context) (string, error) {
 		}
 	}
 
-	if err := stream.Err(); err != nil {
-		return "", err
+	s.tokens.Add(st.inputTokens + st.outputTokens)
+	s.inputTokens += st.inputTokens
+	s.outputTokens += st.outputTokens
+	if s.client.Limiter != nil {
+		// Failing to charge tokens only makes the limiter more permissive,
+		// which is no reason to abort the session.
+		_ = s.client.Limiter.Record(st.inputTokens + st.outputTokens)
 	}
 
-	// Append assistant message.
-	if len(st.assistantBlocks) > 0 {
-		s.messages = append(s.messages, anthropic.MessageParam{
-			Role:    anthropic.MessageParamRoleAssistant,
-			Content: st.assistantBlocks,
-		})
+	return st, stream.Err()
+}
+
+// reportUsage tells the TUI the session's token usage, including the
+// response being streamed.
+func (s *Session) reportUsage(st *streamState) {
+	s.Events <- EventUsage{
+		InputTokens:   s.inputTokens + st.inputTokens,
+		OutputTokens:  s.outputTokens + st.outputTokens,
+		ContextTokens: st.inputTokens + st.outputTokens,
 	}
+}
 
-	// Process tool uses.
-	if len(st.toolUses) > 0 {
-		if err := s.processToolUses(ctx, st); err != nil {
-			return "", err
-		}
+// waitForRateLimit blocks until the shared limiter lets a request through,
+// telling the TUI while it waits.
+func (s *Session) waitForRateLimit(ctx context.Context) error {
+	if s.client.Limiter == nil {
+		return nil
 	}
+	return s.client.Limiter.Wait(ctx, func(wait time.Duration) {
+		s.Events <- EventRateLimited{Wait: wait}
+	})
+}
 
-	return st.stopReason, nil
+// backOff holds the session back after the API rejected a request with a
+// rate limit error. With a shared limiter, every hydra process is held back
+// and the wait happens in waitForRateLimit.
+func (s *Session) backOff(ctx context.Context, wait time.Duration) error {
+	if s.client.Limiter != nil {
+		return s.client.Limiter.Block(wait)
+	}
+
+	s.Events <- EventRateLimited{Wait: wait}
+	return sleepContext(ctx, wait)
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// modelOverloaded reports whether err is the API saying the model is
+// overloaded or temporarily unavailable.
+func modelOverloaded(err error) bool {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == statusOverloaded || apiErr.StatusCode == http.StatusServiceUnavailable
+}
+
+// rateLimitBackoff reports whether err is a rate limit error from the API and
+// how long to wait before retrying.
+func rateLimitBackoff(err error) (time.Duration, bool) {
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if apiErr.Response != nil {
+		if secs, err := strconv.Atoi(apiErr.Response.Header.Get("retry-after")); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	return defaultRateLimitBackoff, true
 }
 
 func (s *Session) handleMessageDelta(event anthropic.MessageStreamEventUnion, st *streamState) {
 	delta := event.AsMessageDelta()
 	st.stopReason = string(delta.Delta.StopReason)
+	st.outputTokens = delta.Usage.OutputTokens
 }
 
 func (s *Session) handleContentBlockStart(event anthropic.MessageStreamEventUnion, st *streamState) {
 	startEvt := event.AsContentBlockStart()
 	cb := startEvt.ContentBlock
+	st.streamed = true
 	switch cb.Type {
 	case eventTypeText:
 		st.currentBlockType = eventTypeText
@@ -242,11 +542,15 @@ func (s *Session) processToolUses(ctx context.Context, st *streamState) error {
 			select {
 			case answer := <-s.ToolAnswer:
 				if !answer.Approved {
+					reason, shown := "Tool execution was rejected by the user.", "Rejected by user"
+					if answer.Reason != "" {
+						reason, shown = answer.Reason, answer.Reason
+					}
 					toolResultBlocks = append(toolResultBlocks,
-						anthropic.NewToolResultBlock(tu.ID, "Tool execution was rejected by the user.", true))
+						anthropic.NewToolResultBlock(tu.ID, reason, true))
 					s.Events <- EventToolResult{
 						ID:      tu.ID,
-						Content: "Rejected by user",
+						Content: shown,
 						IsError: true,
 					}
 					continue
@@ -257,7 +561,8 @@ func (s *Session) processToolUses(ctx context.Context, st *streamState) error {
 		}
 
 		// Execute the tool.
-		result, err := ExecuteTool(s.client.Config.RepoDir, tu.Name, inputRaw)
+		s.setLastTool(tu.Name, meta)
+		result, err := s.execute(tu.Name, inputRaw)
 		isError := err != nil
 		content := result
 		if err != nil {
@@ -283,6 +588,15 @@ func (s *Session) processToolUses(ctx context.Context, st *streamState) error {
 	return nil
 }
 
+// execute runs a tool call: hydra_state through the client's State, and the
+// rest with executeTool.
+func (s *Session) execute(name string, input json.RawMessage) (string, error) {
+	if name == toolHydraState && s.client.Config.State != nil {
+		return callState(s.client.Config.State, input)
+	}
+	return executeTool(s.client.Config.RepoDir, s.client.Config.Shell, name, input)
+}
+
 type toolUseInfo struct {
 	ID        string
 	Name      string
@@ -0,0 +1,76 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+func TestSessionNextModel(t *testing.T) {
+	s := NewSession(&Client{Config: ClientConfig{Model: "opus", Fallbacks: []string{"opus", "sonnet", "haiku"}}})
+
+	if !s.nextModel() || s.Model() != "sonnet" {
+		t.Fatalf("first fallback: model = %q, want sonnet (the primary is skipped)", s.Model())
+	}
+	if evt, ok := (<-s.Events).(EventModelFallback); !ok || evt.From != "opus" || evt.To != "sonnet" {
+		t.Errorf("event = %+v, want opus -> sonnet", evt)
+	}
+	if !s.nextModel() || s.Model() != "haiku" {
+		t.Fatalf("second fallback: model = %q, want haiku", s.Model())
+	}
+	<-s.Events
+	if s.nextModel() {
+		t.Error("nextModel should report false once the fallbacks run out")
+	}
+	if s.Model() != "haiku" {
+		t.Errorf("model = %q, want haiku to stay", s.Model())
+	}
+}
+
+func TestSessionLastTool(t *testing.T) {
+	s := NewSession(&Client{})
+	if got := s.LastTool(); got != "" {
+		t.Errorf("LastTool before any call = %q, want empty", got)
+	}
+	s.setLastTool("bash", ToolMeta{Command: "go test ./...\necho done"})
+	if got := s.LastTool(); got != "bash: go test ./..." {
+		t.Errorf("LastTool = %q, want the command's first line", got)
+	}
+	s.setLastTool("read_file", ToolMeta{Path: "main.go"})
+	if got := s.LastTool(); got != "read_file: main.go" {
+		t.Errorf("LastTool = %q, want read_file: main.go", got)
+	}
+}
+
+func TestAssistantText(t *testing.T) {
+	blocks := []anthropic.ContentBlockParamUnion{
+		anthropic.NewTextBlock("Looked around."),
+		anthropic.NewToolUseBlock("t1", map[string]string{"command": "ls"}, "bash"),
+		anthropic.NewTextBlock("Done."),
+	}
+	if got := assistantText(blocks); got != "Looked around.\n\nDone." {
+		t.Errorf("assistantText = %q", got)
+	}
+	if got := assistantText(nil); got != "" {
+		t.Errorf("assistantText(nil) = %q, want empty", got)
+	}
+}
+
+func TestModelOverloaded(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&anthropic.Error{StatusCode: statusOverloaded}, true},
+		{fmt.Errorf("wrapped: %w", &anthropic.Error{StatusCode: http.StatusServiceUnavailable}), true},
+		{&anthropic.Error{StatusCode: http.StatusTooManyRequests}, false},
+		{errors.New("connection reset"), false},
+	} {
+		if got := modelOverloaded(tc.err); got != tc.want {
+			t.Errorf("modelOverloaded(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
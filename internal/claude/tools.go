@@ -1,6 +1,10 @@
 package claude
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/packages/param"
 )
@@ -13,8 +17,58 @@ const (
 	toolBash        = "bash"
 	toolListFiles   = "list_files"
 	toolSearchFiles = "search_files"
+	toolHydraState  = "hydra_state"
 )
 
+// StateFunc answers the hydra_state tool: what hydra knows about the
+// session's task, limited to topic ("task", "group", "history", or
+// "functional"), or everything for "". files are the paths to find
+// functional.md sections for; none means the files the task changed.
+type StateFunc func(topic string, files []string) (string, error)
+
+// State tool schema, shared by the API tool and the MCP server.
+const stateToolDescription = "Read-only: report what hydra knows about the task you are working on. " +
+	"Topics: task (name, state, branch, work directory, issue), group (the other tasks in its group and their states), " +
+	"history (its entries in record.json: past runs, reviews, tests, and merges), " +
+	"functional (the functional.md sections that mention the files the task changed, or the given files). " +
+	"Leave topic empty for all of them."
+
+var stateToolProperties = map[string]any{
+	"topic": map[string]any{
+		"type":        "string",
+		"enum":        []string{"", "task", "group", "history", "functional"},
+		"description": "What to report; empty for everything.",
+	},
+	"files": map[string]any{
+		"type":        "string",
+		"description": "Optional space-separated paths to find functional.md sections for, relative to the repository root.",
+	},
+}
+
+// stateToolDefinition returns the hydra_state tool schema for the
+// Anthropic API.
+func stateToolDefinition() anthropic.ToolUnionParam {
+	return anthropic.ToolUnionParam{OfTool: &anthropic.ToolParam{
+		Name:        toolHydraState,
+		Description: param.NewOpt(stateToolDescription),
+		InputSchema: anthropic.ToolInputSchemaParam{Properties: stateToolProperties},
+	}}
+}
+
+// callState answers a hydra_state call with input from Claude.
+func callState(state StateFunc, input json.RawMessage) (string, error) {
+	var params struct {
+		Topic string `json:"topic"`
+		Files string `json:"files"`
+	}
+	if len(input) > 0 {
+		if err := json.Unmarshal(input, &params); err != nil {
+			return "", fmt.Errorf("invalid tool input: %w", err)
+		}
+	}
+	return state(params.Topic, strings.Fields(params.Files))
+}
+
 // ToolDefinitions returns the six tool schemas for the Anthropic API.
 func ToolDefinitions() []anthropic.ToolUnionParam {
 	return []anthropic.ToolUnionParam{
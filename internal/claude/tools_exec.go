@@ -47,9 +47,9 @@ func PrepareMeta(repoDir, name string, input json.RawMessage) ToolMeta {
 		meta.Content = params["content"]
 		if absPath, err := ValidatePath(repoDir, params["path"]); err == nil {
 			if old, err := os.ReadFile(absPath); err == nil { //nolint:gosec // path validated
-				meta.Diff = computeUnifiedDiff(params["path"], string(old), params["content"])
+				meta.Diff = UnifiedDiff(params["path"], string(old), params["content"])
 			} else {
-				meta.Diff = computeUnifiedDiff(params["path"], "", params["content"])
+				meta.Diff = UnifiedDiff(params["path"], "", params["content"])
 			}
 		}
 	case toolEditFile:
@@ -57,7 +57,7 @@ func PrepareMeta(repoDir, name string, input json.RawMessage) ToolMeta {
 		if absPath, err := ValidatePath(repoDir, params["path"]); err == nil {
 			if old, err := os.ReadFile(absPath); err == nil { //nolint:gosec // path validated
 				newContent := strings.Replace(string(old), params["old_text"], params["new_text"], 1)
-				meta.Diff = computeUnifiedDiff(params["path"], string(old), newContent)
+				meta.Diff = UnifiedDiff(params["path"], string(old), newContent)
 			}
 		}
 	case toolBash:
@@ -67,12 +67,24 @@ func PrepareMeta(repoDir, name string, input json.RawMessage) ToolMeta {
 	case toolSearchFiles:
 		meta.Path = params["path"]
 	}
+	if meta.Path != "" {
+		if abs, err := ValidatePath(repoDir, meta.Path); err == nil {
+			meta.RelPath, _ = filepath.Rel(repoDir, abs)
+		}
+	}
 
 	return meta
 }
 
-// ExecuteTool runs a tool and returns its output.
+// ExecuteTool runs a tool and returns its output. Bash commands run on the
+// host.
 func ExecuteTool(repoDir, name string, input json.RawMessage) (string, error) {
+	return executeTool(repoDir, nil, name, input)
+}
+
+// executeTool implements ExecuteTool, building bash commands with shell
+// when it is set.
+func executeTool(repoDir string, shell func(string) *exec.Cmd, name string, input json.RawMessage) (string, error) {
 	var params map[string]string
 	if err := json.Unmarshal(input, &params); err != nil {
 		return "", fmt.Errorf("invalid tool input: %w", err)
@@ -86,7 +98,7 @@ func ExecuteTool(repoDir, name string, input json.RawMessage) (string, error) {
 	case toolEditFile:
 		return execEditFile(repoDir, params)
 	case toolBash:
-		return execBash(repoDir, params)
+		return execBash(repoDir, shell, params)
 	case toolListFiles:
 		return execListFiles(repoDir, params)
 	case toolSearchFiles:
@@ -143,9 +155,14 @@ func execEditFile(repoDir string, params map[string]string) (string, error) {
 	return "Edited " + params["path"], nil
 }
 
-func execBash(repoDir string, params map[string]string) (string, error) {
-	cmd := exec.CommandContext(context.Background(), "bash", "-c", params["command"]) //nolint:gosec // user-approved command
-	cmd.Dir = repoDir
+func execBash(repoDir string, shell func(string) *exec.Cmd, params map[string]string) (string, error) {
+	var cmd *exec.Cmd
+	if shell != nil {
+		cmd = shell(params["command"])
+	} else {
+		cmd = exec.CommandContext(context.Background(), "bash", "-c", params["command"]) //nolint:gosec // user-approved command
+		cmd.Dir = repoDir
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -242,6 +259,7 @@ func execSearchFiles(repoDir string, params map[string]string) (string, error) {
 		if relErr != nil {
 			relPath = path // fall back to absolute path
 		}
+		relPath = filepath.ToSlash(relPath)
 		lines := strings.Split(string(data), "\n")
 		for i, line := range lines {
 			if re.MatchString(line) {
@@ -264,32 +282,3 @@ func execSearchFiles(repoDir string, params map[string]string) (string, error) {
 	}
 	return strings.Join(results, "\n"), nil
 }
-
-// computeUnifiedDiff creates a simple unified diff between old and new content.
-func computeUnifiedDiff(path, oldContent, newContent string) string {
-	oldLines := strings.Split(oldContent, "\n")
-	newLines := strings.Split(newContent, "\n")
-
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "--- a/%s\n", path)
-	fmt.Fprintf(&buf, "+++ b/%s\n", path)
-
-	// Simple line-by-line diff.
-	i, j := 0, 0
-	for i < len(oldLines) || j < len(newLines) {
-		switch {
-		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
-			fmt.Fprintf(&buf, " %s\n", oldLines[i])
-			i++
-			j++
-		case i < len(oldLines):
-			fmt.Fprintf(&buf, "-%s\n", oldLines[i])
-			i++
-		default:
-			fmt.Fprintf(&buf, "+%s\n", newLines[j])
-			j++
-		}
-	}
-
-	return buf.String()
-}
@@ -3,6 +3,7 @@ package claude
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -131,6 +132,24 @@ func TestExecBash(t *testing.T) {
 	}
 }
 
+func TestExecBashShell(t *testing.T) {
+	repoDir := t.TempDir()
+
+	var got string
+	shell := func(command string) *exec.Cmd {
+		got = command
+		return exec.CommandContext(t.Context(), "echo", "from the shell")
+	}
+	input, _ := json.Marshal(map[string]string{"command": "echo hello"})
+	result, err := executeTool(repoDir, shell, "bash", input)
+	if err != nil {
+		t.Fatalf("executeTool: %v", err)
+	}
+	if got != "echo hello" || strings.TrimSpace(result) != "from the shell" {
+		t.Errorf("shell got %q and returned %q", got, result)
+	}
+}
+
 func TestExecListFiles(t *testing.T) {
 	repoDir := t.TempDir()
 	if err := os.WriteFile(filepath.Join(repoDir, "a.go"), []byte(""), 0o600); err != nil {
@@ -105,20 +105,31 @@ var ErrNoConfig = errors.New("no hydra configuration found")
 // Discover searches upward from the current working directory for a .hydra/config.json file.
 // It returns the loaded Config if found, or ErrNoConfig if no config exists in any parent directory.
 func Discover() (*Config, error) {
+	base, err := FindBase()
+	if err != nil {
+		return nil, err
+	}
+	return Load(base)
+}
+
+// FindBase searches upward from the current working directory for the
+// directory containing .hydra/config.json and returns its absolute path.
+// It returns ErrNoConfig if no config exists in any parent directory.
+func FindBase() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("getting working directory: %w", err)
+		return "", fmt.Errorf("getting working directory: %w", err)
 	}
 
 	for {
 		configPath := Path(dir)
 		if _, err := os.Stat(configPath); err == nil {
-			return Load(dir)
+			return dir, nil
 		}
 
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			return nil, ErrNoConfig
+			return "", ErrNoConfig
 		}
 		dir = parent
 	}
@@ -157,6 +157,33 @@ func TestDiscoverFromSubdir(t *testing.T) {
 	}
 }
 
+func TestFindBaseFromSubdir(t *testing.T) {
+	base := t.TempDir()
+	designDir := t.TempDir()
+
+	if _, err := Init(base, testRepoURL, designDir); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	subdir := filepath.Join(base, "sub")
+	if err := os.MkdirAll(subdir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(subdir)
+
+	got, err := FindBase()
+	if err != nil {
+		t.Fatalf("FindBase: %v", err)
+	}
+	want, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = filepath.EvalSymlinks(got); got != want {
+		t.Errorf("FindBase = %q, want %q", got, want)
+	}
+}
+
 func TestDiscoverFromRoot(t *testing.T) {
 	base := t.TempDir()
 	designDir := t.TempDir()
@@ -0,0 +1,123 @@
+// Package credstore stores API tokens for hydra's external integrations.
+//
+// Secrets are kept in the OS keychain when one is available (macOS Keychain
+// via security(1), the Secret Service via secret-tool(1) on Linux, the
+// Windows Credential Manager), and in an AES-GCM encrypted file under the
+// user's config directory otherwise.
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Service is the keychain service name under which hydra secrets are stored.
+const Service = "hydra"
+
+// Known providers and the environment variables that override them.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitea     = "gitea"
+	ProviderForgejo   = "forgejo"
+	ProviderBitbucket = "bitbucket"
+	ProviderAnthropic = "anthropic"
+	ProviderGit       = "git"     // token for HTTPS git remotes
+	ProviderSSH       = "ssh"     // passphrase of the SSH identity file
+	ProviderSigning   = "signing" // passphrase of the commit signing key
+)
+
+// ErrNotFound is returned when no secret is stored for a provider.
+var ErrNotFound = errors.New("credential not found")
+
+// Store persists secrets keyed by provider name.
+type Store interface {
+	// Name returns a short description of the backend (e.g. "keychain").
+	Name() string
+	Get(provider string) (string, error)
+	Set(provider, secret string) error
+	Delete(provider string) error
+}
+
+// envVars maps each provider to the environment variable that takes
+// precedence over the stored secret.
+var envVars = map[string]string{
+	ProviderGitHub:    "GITHUB_TOKEN",
+	ProviderGitea:     "GITEA_TOKEN",
+	ProviderForgejo:   "FORGEJO_TOKEN",
+	ProviderBitbucket: "BITBUCKET_TOKEN",
+	ProviderAnthropic: "ANTHROPIC_API_KEY",
+	ProviderGit:       "HYDRA_GIT_TOKEN",
+	ProviderSSH:       "HYDRA_SSH_PASSPHRASE",
+	ProviderSigning:   "HYDRA_SIGNING_PASSPHRASE",
+}
+
+// Providers returns the list of supported provider names, sorted.
+func Providers() []string {
+	names := make([]string, 0, len(envVars))
+	for name := range envVars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// EnvVar returns the environment variable that overrides the provider's
+// stored secret.
+func EnvVar(provider string) string {
+	return envVars[provider]
+}
+
+// ValidateProvider returns an error if provider is not a supported name.
+func ValidateProvider(provider string) error {
+	if _, ok := envVars[provider]; !ok {
+		return fmt.Errorf("unknown provider %q (supported: %s)", provider, strings.Join(Providers(), ", "))
+	}
+	return nil
+}
+
+// Default returns the preferred store for this machine: the OS keychain when
+// available, otherwise the encrypted file store. Setting HYDRA_CREDENTIAL_STORE
+// to "file" or "keychain" forces a backend.
+func Default() (Store, error) {
+	switch os.Getenv("HYDRA_CREDENTIAL_STORE") {
+	case "file":
+		return DefaultFileStore()
+	case "keychain":
+		if !keychainAvailable() {
+			return nil, errors.New("OS keychain is not available")
+		}
+		return keychainStore{}, nil
+	}
+
+	if keychainAvailable() {
+		return keychainStore{}, nil
+	}
+	return DefaultFileStore()
+}
+
+// Lookup resolves the secret for provider. The provider's environment
+// variable wins when set; otherwise the default store is consulted. An empty
+// string is returned when nothing is configured.
+func Lookup(provider string) string {
+	if env := envVars[provider]; env != "" {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+
+	store, err := Default()
+	if err != nil {
+		return ""
+	}
+	secret, err := store.Get(provider)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "Warning: reading %s credential from %s: %v\n", provider, store.Name(), err)
+		}
+		return ""
+	}
+	return secret
+}
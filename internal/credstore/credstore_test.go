@@ -0,0 +1,129 @@
+package credstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+
+	if _, err := s.Get(ProviderGitHub); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on empty store: err = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Set(ProviderGitHub, "gh-secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set(ProviderGitea, "gitea-secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := NewFileStore(dir).Get(ProviderGitHub)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != "gh-secret" {
+		t.Errorf("Get = %q, want gh-secret", got)
+	}
+
+	if err := s.Delete(ProviderGitHub); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(ProviderGitHub); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if got, _ := s.Get(ProviderGitea); got != "gitea-secret" {
+		t.Errorf("gitea secret = %q, want gitea-secret", got)
+	}
+	if err := s.Delete(ProviderGitHub); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStoreEncryptsAtRest(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+	if err := s.Set(ProviderAnthropic, "sk-plaintext-marker"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, dataFile)) //nolint:gosec // test reads from temp dir
+	if err != nil {
+		t.Fatalf("reading data file: %v", err)
+	}
+	if strings.Contains(string(data), "sk-plaintext-marker") {
+		t.Error("credentials file contains plaintext secret")
+	}
+
+	for _, name := range []string{dataFile, keyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s permissions = %o, want 600", name, perm)
+		}
+	}
+}
+
+func TestFileStoreWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileStore(dir)
+	if err := s.Set(ProviderGitHub, "secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, keyFile), make([]byte, 32), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ProviderGitHub); err == nil {
+		t.Error("expected decryption error with wrong key")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cfgHome := t.TempDir()
+	t.Setenv("HOME", cfgHome)
+	t.Setenv("XDG_CONFIG_HOME", cfgHome)
+	t.Setenv("HYDRA_CREDENTIAL_STORE", "file")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	if got := Lookup(ProviderGitHub); got != "" {
+		t.Errorf("Lookup with nothing configured = %q, want empty", got)
+	}
+
+	s, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	if err := s.Set(ProviderGitHub, "stored-token"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := Lookup(ProviderGitHub); got != "stored-token" {
+		t.Errorf("Lookup = %q, want stored-token", got)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	if got := Lookup(ProviderGitHub); got != "env-token" {
+		t.Errorf("Lookup with env set = %q, want env-token", got)
+	}
+}
+
+func TestValidateProvider(t *testing.T) {
+	for _, p := range Providers() {
+		if err := ValidateProvider(p); err != nil {
+			t.Errorf("ValidateProvider(%q): %v", p, err)
+		}
+		if EnvVar(p) == "" {
+			t.Errorf("EnvVar(%q) is empty", p)
+		}
+	}
+	if err := ValidateProvider("gitlab"); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
@@ -0,0 +1,164 @@
+package credstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	keyFile  = "credentials.key"
+	dataFile = "credentials.enc"
+)
+
+// FileStore keeps secrets in an AES-256-GCM encrypted JSON file. The key is
+// generated on first use and stored next to the data with 0600 permissions.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore rooted at dir.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// DefaultFileStore returns a FileStore in the user's config directory
+// (e.g. ~/.config/hydra).
+func DefaultFileStore() (*FileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("locating config dir: %w", err)
+	}
+	return NewFileStore(filepath.Join(dir, "hydra")), nil
+}
+
+// Name implements Store.
+func (f *FileStore) Name() string {
+	return "encrypted file " + filepath.Join(f.dir, dataFile)
+}
+
+// Get implements Store.
+func (f *FileStore) Get(provider string) (string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[provider]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set implements Store.
+func (f *FileStore) Set(provider, secret string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[provider] = secret
+	return f.save(secrets)
+}
+
+// Delete implements Store.
+func (f *FileStore) Delete(provider string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[provider]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, provider)
+	return f.save(secrets)
+}
+
+// load decrypts and parses the data file. A missing file yields an empty map.
+func (f *FileStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+
+	data, err := os.ReadFile(filepath.Join(f.dir, dataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading credentials: %w", err)
+	}
+
+	gcm, err := f.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("credentials file is truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting credentials: %w", err)
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("parsing credentials: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts and writes secrets to the data file.
+func (f *FileStore) save(secrets map[string]string) error {
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return fmt.Errorf("creating credential dir: %w", err)
+	}
+
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("marshaling credentials: %w", err)
+	}
+
+	gcm, err := f.cipher(true)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+
+	out := gcm.Seal(nonce, nonce, plain, nil)
+	if err := os.WriteFile(filepath.Join(f.dir, dataFile), out, 0o600); err != nil {
+		return fmt.Errorf("writing credentials: %w", err)
+	}
+	return nil
+}
+
+// cipher returns an AES-GCM AEAD keyed from the key file. When create is
+// true, a new random key is generated if none exists yet.
+func (f *FileStore) cipher(create bool) (cipher.AEAD, error) {
+	keyPath := filepath.Join(f.dir, keyFile)
+	key, err := os.ReadFile(keyPath) //nolint:gosec // path is under the user's config dir
+	if errors.Is(err, os.ErrNotExist) && create {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, fmt.Errorf("generating key: %w", err)
+		}
+		if err := os.WriteFile(keyPath, key, 0o600); err != nil {
+			return nil, fmt.Errorf("writing key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("initializing cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,68 @@
+package credstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainStore stores secrets in the macOS Keychain via security(1).
+type keychainStore struct{}
+
+func keychainAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+func (keychainStore) Name() string { return "macOS Keychain" }
+
+func (keychainStore) Get(provider string) (string, error) {
+	out, err := exec.CommandContext(context.Background(), "security", "find-generic-password",
+		"-s", Service, "-a", provider, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("security find-generic-password: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Set runs add-generic-password through security's interactive mode, so the
+// secret is read from stdin rather than appearing in the process list.
+// Interactive mode exits successfully even when a command fails, so the
+// stored secret is read back to confirm it.
+func (k keychainStore) Set(provider, secret string) error {
+	cmd := exec.CommandContext(context.Background(), "security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(Service), securityQuote(provider), securityQuote(secret)))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("security add-generic-password: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	if stored, err := k.Get(provider); err != nil || stored != secret {
+		return fmt.Errorf("security add-generic-password: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityQuote quotes s as one argument for security's interactive mode.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (keychainStore) Delete(provider string) error {
+	cmd := exec.CommandContext(context.Background(), "security", "delete-generic-password",
+		"-s", Service, "-a", provider)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("security delete-generic-password: %w", err)
+	}
+	return nil
+}
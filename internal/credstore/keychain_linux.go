@@ -0,0 +1,59 @@
+package credstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainStore stores secrets in the Secret Service (GNOME Keyring, KWallet)
+// via secret-tool(1).
+type keychainStore struct{}
+
+func keychainAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (keychainStore) Name() string { return "Secret Service" }
+
+func (keychainStore) Get(provider string) (string, error) {
+	out, err := exec.CommandContext(context.Background(), "secret-tool", "lookup",
+		"service", Service, "account", provider).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	secret := strings.TrimRight(string(out), "\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (keychainStore) Set(provider, secret string) error {
+	cmd := exec.CommandContext(context.Background(), "secret-tool", "store", //nolint:gosec // args are provider names
+		"--label=hydra "+provider, "service", Service, "account", provider)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+func (keychainStore) Delete(provider string) error {
+	if _, err := (keychainStore{}).Get(provider); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(context.Background(), "secret-tool", "clear",
+		"service", Service, "account", provider)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("secret-tool clear: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build !darwin && !linux && !windows
+
+package credstore
+
+import "errors"
+
+// keychainStore is a placeholder on systems without a supported OS
+// keychain; the encrypted file store is used instead.
+type keychainStore struct{}
+
+func keychainAvailable() bool { return false }
+
+func (keychainStore) Name() string { return "OS keychain" }
+
+func (keychainStore) Get(string) (string, error) { return "", ErrNotFound }
+
+func (keychainStore) Set(string, string) error {
+	return errors.New("OS keychain is not available")
+}
+
+func (keychainStore) Delete(string) error { return ErrNotFound }
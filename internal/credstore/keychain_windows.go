@@ -0,0 +1,110 @@
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// keychainStore stores secrets as generic credentials in the Windows
+// Credential Manager, named hydra:<provider>.
+type keychainStore struct{}
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func keychainAvailable() bool {
+	return procCredRead.Find() == nil
+}
+
+func (keychainStore) Name() string { return "Windows Credential Manager" }
+
+// credTarget returns the Credential Manager target name for provider.
+func credTarget(provider string) (*uint16, error) {
+	return windows.UTF16PtrFromString(Service + ":" + provider)
+}
+
+func (keychainStore) Get(provider string) (string, error) {
+	target, err := credTarget(provider)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("CredRead: %w", err)
+	}
+	defer func() { _, _, _ = procCredFree.Call(uintptr(unsafe.Pointer(cred))) }()
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (keychainStore) Set(provider, secret string) error {
+	target, err := credTarget(provider)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(provider)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)), //nolint:gosec // secrets are far below 4 GiB
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("CredWrite: %w", err)
+	}
+	return nil
+}
+
+func (keychainStore) Delete(provider string) error {
+	target, err := credTarget(provider)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("CredDelete: %w", err)
+	}
+	return nil
+}
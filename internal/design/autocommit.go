@@ -0,0 +1,49 @@
+package design
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// Changed reports a change to the design directory, such as a task state
+// transition, a record entry, or a milestone update, that touched paths. It
+// calls OnChange, if set, with a message describing the change.
+func (d *Dir) Changed(paths []string, format string, args ...any) {
+	if d.OnChange != nil {
+		d.OnChange(fmt.Sprintf(format, args...), paths)
+	}
+}
+
+// Record returns the directory's SHA record. Entries added through it are
+// reported with Changed.
+func (d *Dir) Record() *Record {
+	rec := NewRecord(d.Path)
+	rec.dir = d
+	return rec
+}
+
+// EnableAutoCommit makes every change reported by Changed a git commit in
+// the design directory, so the repository holding it keeps a history of
+// planning state. Only the paths the change touched are committed, so
+// edits of the user's own in progress stay out of it. If push is true,
+// each commit is also pushed to the branch's upstream. Failures are
+// warnings: the change itself has already been made on disk.
+func (d *Dir) EnableAutoCommit(push bool) {
+	// The design directory is often a subdirectory of its repository, which
+	// repo.Open would warn about; CommitPaths only needs the git CLI.
+	designRepo := &repo.Repo{Dir: d.Path}
+	d.OnChange = func(message string, paths []string) {
+		committed, err := designRepo.CommitPaths("hydra: "+message, paths...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not commit design change: %v\n", err)
+			return
+		}
+		if committed && push {
+			if err := designRepo.PushUpstream(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not push design change: %v\n", err)
+			}
+		}
+	}
+}
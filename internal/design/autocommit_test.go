@@ -0,0 +1,90 @@
+package design
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangedCallsOnChange(t *testing.T) {
+	dd, err := NewDir(setupDesignDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	dd.OnChange = func(message string, _ []string) { messages = append(messages, message) }
+
+	task, err := dd.FindTask("backend/add-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must(t, dd.MoveTask(task, StateReview))
+	must(t, dd.Record().Add("0123456789abcdef0123", "backend/add-api"))
+	if _, err := dd.CreateMilestone("2026-06-01", "## Ship it\n"); err != nil {
+		t.Fatal(err)
+	}
+	// Records opened without the Dir don't report changes.
+	must(t, NewRecord(dd.Path).Add("fedcba", "other"))
+
+	want := []string{
+		"move backend/add-api to review",
+		"record backend/add-api at 0123456789ab",
+		"create milestone 2026-06-01",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("messages = %q, want %q", messages, want)
+	}
+}
+
+func TestEnableAutoCommit(t *testing.T) {
+	dir := setupDesignDir(t)
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-m", "initial"},
+	} {
+		cmd := exec.CommandContext(context.Background(), "git", append([]string{"-C", dir}, args...)...) //nolint:gosec // test with controlled args
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	dd, err := NewDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dd.EnableAutoCommit(false)
+
+	// An edit of the user's own in progress stays out of hydra's commits.
+	if err := os.WriteFile(filepath.Join(dir, "rules.md"), []byte("draft rules\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := dd.FindTask("add-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must(t, dd.MoveTask(task, StateAbandoned))
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "log", "-1", "--format=%s").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "hydra: move add-auth to abandoned" {
+		t.Errorf("last commit = %q", got)
+	}
+
+	out, err = exec.CommandContext(context.Background(), "git", "-C", dir, "status", "--porcelain").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "M rules.md" {
+		t.Errorf("status after auto-commit = %q, want only the user's rules.md edit", got)
+	}
+}
@@ -0,0 +1,68 @@
+package design
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ChecklistResult records the outcome of a single review checklist item.
+type ChecklistResult struct {
+	Item   string `json:"item"`
+	Passed bool   `json:"passed"`
+	Note   string `json:"note,omitempty"`
+}
+
+// checklistItemRe matches markdown list items ("- x", "* x", "1. x", "- [ ] x").
+var checklistItemRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+?)\s*$`)
+
+// checklistResultRe matches result lines written by Claude ("1. PASS", "2. FAIL: reason").
+var checklistResultRe = regexp.MustCompile(`(?i)^\s*(\d+)[.):]?\s+(PASS|FAIL)\b[\s:—-]*(.*?)\s*$`)
+
+// ReviewChecklist returns the items listed in review.md, or nil if the file
+// doesn't exist. Each markdown list item becomes one check; other lines
+// (headings, prose) are ignored.
+func (d *Dir) ReviewChecklist() ([]string, error) {
+	content, err := d.readFile("review.md")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []string
+	for line := range strings.SplitSeq(content, "\n") {
+		if m := checklistItemRe.FindStringSubmatch(line); m != nil {
+			items = append(items, m[1])
+		}
+	}
+	return items, nil
+}
+
+// ParseChecklistResults matches "<n>. PASS" / "<n>. FAIL: <note>" lines in
+// output against the numbered items. Items without a result line are
+// omitted; if an item is reported more than once, the last report wins.
+func ParseChecklistResults(output string, items []string) []ChecklistResult {
+	found := make(map[int]ChecklistResult)
+	for line := range strings.SplitSeq(output, "\n") {
+		m := checklistResultRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > len(items) {
+			continue
+		}
+		found[n] = ChecklistResult{
+			Item:   items[n-1],
+			Passed: strings.EqualFold(m[2], "PASS"),
+			Note:   m[3],
+		}
+	}
+
+	var results []ChecklistResult
+	for i := range items {
+		if res, ok := found[i+1]; ok {
+			results = append(results, res)
+		}
+	}
+	return results
+}
@@ -0,0 +1,212 @@
+package design
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// claudeCodeSkipDirs are directories never searched for nested CLAUDE.md
+// files.
+var claudeCodeSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"third_party":  true,
+}
+
+// lintHeading matches the headings of CLAUDE.md sections that belong in
+// lint.md rather than rules.md.
+var lintHeading = regexp.MustCompile(`(?i)\b(lint\w*|style|format\w*|naming|conventions?)\b`)
+
+// claudeImportLine matches a CLAUDE.md line that is only an @path import.
+var claudeImportLine = regexp.MustCompile(`^@(\S+)$`)
+
+// ClaudeCodeGuidance is the guidance found in a repository's Claude Code
+// files, sorted into rules.md and lint.md content.
+type ClaudeCodeGuidance struct {
+	Rules   string
+	Lint    string
+	Sources []string // files read, relative to the repository
+}
+
+// ReadClaudeCode collects the guidance a repository keeps for Claude Code:
+// CLAUDE.md and .claude/CLAUDE.md at the root, the .claude/rules/*.md files,
+// and CLAUDE.md files in subdirectories, which are kept under a heading
+// naming the directory they apply to. Lines that are only an @path import
+// are replaced with the imported file when it is in the repository.
+// Sections whose headings are about linting, style, formatting, naming, or
+// conventions go to Lint; everything else goes to Rules. It returns nil if
+// the repository has none of these files.
+func ReadClaudeCode(repoDir string) (*ClaudeCodeGuidance, error) {
+	var sources []string
+	for _, name := range []string{"CLAUDE.md", filepath.Join(".claude", "CLAUDE.md")} {
+		if _, err := os.Stat(filepath.Join(repoDir, name)); err == nil {
+			sources = append(sources, name)
+		}
+	}
+	ruleFiles, _ := filepath.Glob(filepath.Join(repoDir, ".claude", "rules", "*.md"))
+	slices.Sort(ruleFiles)
+	for _, p := range ruleFiles {
+		sources = append(sources, filepath.Join(".claude", "rules", filepath.Base(p)))
+	}
+	nested, err := nestedClaudeFiles(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	sources = append(sources, nested...)
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	var rules, lint strings.Builder
+	for _, src := range sources {
+		data, err := os.ReadFile(filepath.Join(repoDir, src)) //nolint:gosec // files inside the source repository
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", src, err)
+		}
+		r, l := splitLintSections(expandClaudeImports(repoDir, filepath.Dir(src), string(data)))
+		if dir := filepath.Dir(src); filepath.Base(src) == "CLAUDE.md" && dir != "." && dir != ".claude" {
+			r, l = scopedSection(dir, r), scopedSection(dir, l)
+		}
+		appendSection(&rules, r)
+		appendSection(&lint, l)
+	}
+	return &ClaudeCodeGuidance{Rules: rules.String(), Lint: lint.String(), Sources: sources}, nil
+}
+
+// nestedClaudeFiles returns the CLAUDE.md files below the repository root,
+// relative to it, skipping hidden and vendored directories.
+func nestedClaudeFiles(repoDir string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(repoDir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // skip unreadable directories
+		}
+		if e.IsDir() {
+			if path != repoDir && (strings.HasPrefix(e.Name(), ".") || claudeCodeSkipDirs[e.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if e.Name() == "CLAUDE.md" && filepath.Dir(path) != repoDir {
+			rel, err := filepath.Rel(repoDir, path)
+			if err != nil {
+				return err
+			}
+			found = append(found, rel)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// expandClaudeImports replaces lines holding only an @path import with the
+// content of the file, resolved against dir in the repository. Imports of
+// files outside the repository, or missing ones, are left as they are.
+func expandClaudeImports(repoDir, dir, content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		m := claudeImportLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		rel := filepath.Clean(filepath.Join(dir, filepath.FromSlash(m[1])))
+		if filepath.IsAbs(m[1]) || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(repoDir, rel)) //nolint:gosec // checked to be inside the repository
+		if err != nil {
+			continue
+		}
+		lines[i] = strings.TrimRight(string(data), "\n")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitLintSections splits markdown into the sections that belong in
+// lint.md and the rest. A section runs from a heading to the next heading
+// of the same or a higher level, so subsections follow their parent.
+func splitLintSections(content string) (rules, lint string) {
+	var r, l strings.Builder
+	inLint, lintLevel := false, 0
+	inFence := false
+	for line := range strings.SplitSeq(content, "\n") {
+		inFence = toggleFence(line, inFence)
+		if level, _, ok := MarkdownHeading(line); ok && !inFence {
+			switch {
+			case lintHeading.MatchString(line):
+				if !inLint || level <= lintLevel {
+					inLint, lintLevel = true, level
+				}
+			case inLint && level <= lintLevel:
+				inLint = false
+			}
+		}
+		if inLint {
+			l.WriteString(line + "\n")
+		} else {
+			r.WriteString(line + "\n")
+		}
+	}
+	return strings.TrimSpace(r.String()), strings.TrimSpace(l.String())
+}
+
+// scopedSection puts guidance from a subdirectory's CLAUDE.md under a
+// heading naming the directory it applies to.
+func scopedSection(dir, s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("## In %s/\n\n%s", filepath.ToSlash(dir), s)
+}
+
+// appendSection appends s to b, separated from what is there by a blank
+// line.
+func appendSection(b *strings.Builder, s string) {
+	if s == "" {
+		return
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n\n")
+	}
+	b.WriteString(s)
+}
+
+// ImportClaudeCode writes the guidance into rules.md and lint.md. A file
+// that already has content is left alone unless force is set, in which
+// case it is replaced. It returns the names of the files written.
+func (d *Dir) ImportClaudeCode(g *ClaudeCodeGuidance, force bool) ([]string, error) {
+	var written []string
+	for _, f := range []struct{ name, content string }{
+		{"rules.md", g.Rules},
+		{"lint.md", g.Lint},
+	} {
+		if f.content == "" {
+			continue
+		}
+		existing, err := d.readFile(f.name)
+		if err != nil {
+			return written, err
+		}
+		if strings.TrimSpace(existing) != "" && !force {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(d.Path, f.name), []byte(f.content+"\n"), 0o600); err != nil {
+			return written, fmt.Errorf("writing %s: %w", f.name, err)
+		}
+		written = append(written, f.name)
+	}
+	if len(written) > 0 {
+		paths := make([]string, len(written))
+		for i, name := range written {
+			paths[i] = filepath.Join(d.Path, name)
+		}
+		d.Changed(paths, "import %s from Claude Code guidance", strings.Join(written, " and "))
+	}
+	return written, nil
+}
@@ -0,0 +1,88 @@
+package design
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadClaudeCode(t *testing.T) {
+	repoDir := t.TempDir()
+	writeTestFile(t, filepath.Join(repoDir, "CLAUDE.md"), "# Project\n\nRun make before committing.\n\n"+
+		"## Code style\n\nUse tabs.\n\n### Naming\n\nShort names.\n\n## Testing\n\nTable tests.\n\n@docs/arch.md\n")
+	writeTestFile(t, filepath.Join(repoDir, "docs", "arch.md"), "The server lives in cmd/.\n")
+	writeTestFile(t, filepath.Join(repoDir, ".claude", "rules", "security.md"), "Never log tokens.\n")
+	writeTestFile(t, filepath.Join(repoDir, "web", "CLAUDE.md"), "## Formatting\n\nRun prettier.\n")
+	writeTestFile(t, filepath.Join(repoDir, "node_modules", "x", "CLAUDE.md"), "ignored\n")
+
+	g, err := ReadClaudeCode(repoDir)
+	if err != nil {
+		t.Fatalf("ReadClaudeCode: %v", err)
+	}
+	wantSources := []string{"CLAUDE.md", filepath.Join(".claude", "rules", "security.md"), filepath.Join("web", "CLAUDE.md")}
+	if strings.Join(g.Sources, ",") != strings.Join(wantSources, ",") {
+		t.Errorf("Sources = %v, want %v", g.Sources, wantSources)
+	}
+	for _, want := range []string{"Run make before committing.", "## Testing", "The server lives in cmd/.", "Never log tokens."} {
+		if !strings.Contains(g.Rules, want) {
+			t.Errorf("Rules missing %q:\n%s", want, g.Rules)
+		}
+	}
+	for _, want := range []string{"## Code style", "### Naming", "Short names.", "## In web/\n\n## Formatting"} {
+		if !strings.Contains(g.Lint, want) {
+			t.Errorf("Lint missing %q:\n%s", want, g.Lint)
+		}
+	}
+	if strings.Contains(g.Rules, "Use tabs.") || strings.Contains(g.Rules+g.Lint, "ignored") {
+		t.Errorf("guidance put in the wrong place:\nrules:\n%s\nlint:\n%s", g.Rules, g.Lint)
+	}
+}
+
+func TestReadClaudeCodeNone(t *testing.T) {
+	g, err := ReadClaudeCode(t.TempDir())
+	if err != nil || g != nil {
+		t.Errorf("ReadClaudeCode = %v, %v; want nil, nil", g, err)
+	}
+}
+
+func TestImportClaudeCode(t *testing.T) {
+	dir := t.TempDir()
+	if err := Scaffold(dir); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "lint.md"), "Existing lint rules.\n")
+	d := &Dir{Path: dir}
+	g := &ClaudeCodeGuidance{Rules: "Imported rules.", Lint: "Imported lint."}
+
+	written, err := d.ImportClaudeCode(g, false)
+	if err != nil {
+		t.Fatalf("ImportClaudeCode: %v", err)
+	}
+	if len(written) != 1 || written[0] != "rules.md" {
+		t.Errorf("written = %v, want [rules.md]", written)
+	}
+	if rules, _ := d.Rules(); rules != "Imported rules.\n" {
+		t.Errorf("rules.md = %q", rules)
+	}
+	if lint, _ := d.Lint(); lint != "Existing lint rules.\n" {
+		t.Errorf("lint.md = %q, want it kept", lint)
+	}
+
+	if written, err := d.ImportClaudeCode(g, true); err != nil || len(written) != 2 {
+		t.Errorf("forced ImportClaudeCode = %v, %v", written, err)
+	}
+	if lint, _ := d.Lint(); lint != "Imported lint.\n" {
+		t.Errorf("lint.md = %q after --force", lint)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,74 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// commentsSuffix ends the file beside a task that holds reviewer comments
+// for its next review session.
+const commentsSuffix = ".comments.md"
+
+// CommentsPath returns the path of the task's reviewer comments file,
+// {task}.comments.md beside the task file.
+func (t *Task) CommentsPath() string {
+	return strings.TrimSuffix(t.FilePath, ".md") + commentsSuffix
+}
+
+// Comments returns the reviewer comments waiting for the task's next review
+// session, or "" if there are none.
+func (t *Task) Comments() (string, error) {
+	data, err := os.ReadFile(t.CommentsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading comments for %s: %w", t.Label(), err)
+	}
+	return string(data), nil
+}
+
+// AddComment appends a reviewer comment to the task's comments file.
+func (d *Dir) AddComment(task *Task, comment string) error {
+	existing, err := task.Comments()
+	if err != nil {
+		return err
+	}
+	comment = strings.TrimSpace(comment) + "\n"
+	if existing != "" {
+		comment = strings.TrimRight(existing, "\n") + "\n\n" + comment
+	}
+	if err := os.WriteFile(task.CommentsPath(), []byte(comment), 0o600); err != nil {
+		return fmt.Errorf("writing comments for %s: %w", task.Label(), err)
+	}
+	d.Changed([]string{task.CommentsPath()}, "add review comment to %s", task.Label())
+	return nil
+}
+
+// ClearComments removes the comments a review session was given, used,
+// from the task's comments file, keeping any added during the session.
+func (d *Dir) ClearComments(task *Task, used string) error {
+	if used == "" {
+		return nil
+	}
+	current, err := task.Comments()
+	if err != nil || current == "" {
+		return err
+	}
+	rest, ok := strings.CutPrefix(current, used)
+	if !ok {
+		// Edited by hand since; leave it to the reviewer.
+		return nil
+	}
+	if rest = strings.TrimLeft(rest, "\n"); rest != "" {
+		err = os.WriteFile(task.CommentsPath(), []byte(rest), 0o600)
+	} else {
+		err = os.Remove(task.CommentsPath())
+	}
+	if err != nil {
+		return fmt.Errorf("clearing comments for %s: %w", task.Label(), err)
+	}
+	d.Changed([]string{task.CommentsPath()}, "clear review comments of %s", task.Label())
+	return nil
+}
@@ -0,0 +1,91 @@
+package design
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CommitTemplateVars are the values substituted into commit_template.md.
+type CommitTemplateVars struct {
+	Task  string // task name, without its group
+	Group string // group name, or "" for ungrouped tasks
+	Issue string // issue number for imported issue tasks, or ""
+}
+
+// commitSlotRe matches a free-form slot in a commit template, like <description>.
+var commitSlotRe = regexp.MustCompile(`<[^<>\n]+>`)
+
+// CommitTemplate returns the content of commit_template.md, or empty string
+// if it doesn't exist. The file is named like the design directory's other
+// documents, though it holds a plain-text commit message.
+func (d *Dir) CommitTemplate() (string, error) {
+	content, err := d.readFile("commit_template.md")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(content), nil
+}
+
+// RenderCommitTemplate replaces the {{task}}, {{group}}, and {{issue}}
+// placeholders in a commit template. Lines after the subject that use a
+// placeholder with no value (e.g. an issue reference on a task that isn't an
+// issue) are dropped.
+func RenderCommitTemplate(tmpl string, vars CommitTemplateVars) string {
+	values := map[string]string{
+		"{{task}}":  vars.Task,
+		"{{group}}": vars.Group,
+		"{{issue}}": vars.Issue,
+	}
+
+	var lines []string
+	for i, line := range strings.Split(tmpl, "\n") {
+		keep := true
+		for placeholder, value := range values {
+			if !strings.Contains(line, placeholder) {
+				continue
+			}
+			if value == "" && i > 0 {
+				keep = false
+				break
+			}
+			line = strings.ReplaceAll(line, placeholder, value)
+		}
+		if keep {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// CommitSubjectPattern returns a pattern matching commit subjects that follow
+// a rendered template's first line. Each <slot> matches any non-empty text;
+// everything else must match literally.
+func CommitSubjectPattern(rendered string) *regexp.Regexp {
+	subject, _, _ := strings.Cut(rendered, "\n")
+	subject = strings.TrimSpace(subject)
+
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range commitSlotRe.FindAllStringIndex(subject, -1) {
+		b.WriteString(regexp.QuoteMeta(subject[last:loc[0]]))
+		b.WriteString(".+")
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(subject[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// MismatchedCommitSubjects returns the subjects that don't follow the
+// rendered template.
+func MismatchedCommitSubjects(rendered string, subjects []string) []string {
+	re := CommitSubjectPattern(rendered)
+	var bad []string
+	for _, s := range subjects {
+		if !re.MatchString(strings.TrimSpace(s)) {
+			bad = append(bad, s)
+		}
+	}
+	return bad
+}
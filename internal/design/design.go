@@ -10,6 +10,11 @@ import (
 // Dir represents a design directory containing rules, lint, functional specs, and tasks.
 type Dir struct {
 	Path string
+
+	// OnChange, if set, is called after each change hydra makes to the
+	// directory with a message describing it and the paths it wrote,
+	// moved, or removed. See EnableAutoCommit.
+	OnChange func(message string, paths []string)
 }
 
 // NewDir opens and validates a design directory at the given path.
@@ -67,6 +72,7 @@ const DefaultHydraYml = `# Commands that Claude runs before committing.
 # work directory, or shared network ports. Each invocation should be fully
 # isolated to its own working tree.
 commands:
+  # setup: "npm ci"
   # before: "make deps"
   # clean: "make clean"
   # dev: "npm run dev"
@@ -128,13 +134,19 @@ func Scaffold(path string) error {
 	return nil
 }
 
-// GroupContent returns the content of the group heading file (tasks/{group}/group.md).
+// GroupContent returns the content of the group heading file (tasks/{group}/group.md),
+// without its front matter.
 // Returns empty string if the group is empty or the file doesn't exist.
 func (d *Dir) GroupContent(group string) (string, error) {
 	if group == "" {
 		return "", nil
 	}
-	return d.readFile(filepath.Join("tasks", group, "group.md"))
+	content, err := d.readFile(filepath.Join("tasks", group, "group.md"))
+	if err != nil {
+		return "", err
+	}
+	_, body, _ := splitFrontmatter(content)
+	return body, nil
 }
 
 // MissionPreamble is prepended to every assembled document to keep Claude focused on the task.
@@ -5,8 +5,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const testGroupBackend = "backend"
@@ -429,6 +432,56 @@ func TestFindTaskNotFound(t *testing.T) {
 	}
 }
 
+func TestFindTaskSuggestion(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+
+	_, err := dd.FindTask("add-ath")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "add-auth"?`) {
+		t.Errorf("FindTask(add-ath) error = %v, want a suggestion of add-auth", err)
+	}
+	_, err = dd.FindTaskByState("old-tsk", StateReview)
+	if err == nil || !strings.Contains(err.Error(), `did you mean "old-task"?`) {
+		t.Errorf("FindTaskByState(old-tsk) error = %v, want a suggestion of old-task", err)
+	}
+	_, err = dd.FindTask("something-else")
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("FindTask(something-else) error = %v, want no suggestion", err)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"add-auth", "fix-bug", "backend/add-api"}
+	tests := []struct {
+		name, want string
+	}{
+		{"fix-bgu", ` (did you mean "fix-bug"?)`},
+		{"ADD-AUTH", ` (did you mean "add-auth"?)`},
+		{"add-apj", ` (did you mean "backend/add-api"?)`},
+		{"backend/add-ap", ` (did you mean "backend/add-api"?)`},
+		{"deploy", ""},
+	}
+	for _, tt := range tests {
+		if got := DidYouMean(tt.name, candidates); got != tt.want {
+			t.Errorf("DidYouMean(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := DidYouMean("x", nil); got != "" {
+		t.Errorf("DidYouMean with no candidates = %q", got)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	if got := Preview("one\ntwo\n"); got != "  one\n  two\n" {
+		t.Errorf("Preview = %q", got)
+	}
+	long := strings.Repeat("line\n", 12)
+	got := Preview(long)
+	if !strings.HasSuffix(got, "  ... (2 more lines)\n") || strings.Count(got, "  line\n") != 10 {
+		t.Errorf("Preview of 12 lines = %q", got)
+	}
+}
+
 func TestTaskContent(t *testing.T) {
 	dir := setupDesignDir(t)
 	dd, _ := NewDir(dir)
@@ -495,6 +548,126 @@ func TestMoveTask(t *testing.T) {
 	}
 }
 
+func TestReviewComments(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+
+	task, err := dd.FindTaskByState("old-task", StateReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := task.Comments(); err != nil || got != "" {
+		t.Fatalf("Comments() = %q, %v; want empty", got, err)
+	}
+
+	must(t, dd.AddComment(task, "First note.\n\n"))
+	must(t, dd.AddComment(task, "Second note."))
+	used, err := task.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != "First note.\n\nSecond note.\n" {
+		t.Errorf("Comments() = %q", used)
+	}
+
+	// The comments file is not a task.
+	tasks, err := dd.TasksByState(StateReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("got %d review tasks, want 1", len(tasks))
+	}
+
+	// A comment added while the session ran survives clearing.
+	must(t, dd.AddComment(task, "Late note."))
+	must(t, dd.ClearComments(task, used))
+	if got, _ := task.Comments(); got != "Late note.\n" {
+		t.Errorf("after clear, Comments() = %q, want the late note", got)
+	}
+
+	// Comments follow the task between states.
+	must(t, dd.MoveTask(task, StateMerge))
+	if got, _ := task.Comments(); got != "Late note.\n" {
+		t.Errorf("after move, Comments() = %q", got)
+	}
+
+	must(t, dd.ClearComments(task, "Late note.\n"))
+	if _, err := os.Stat(task.CommentsPath()); !os.IsNotExist(err) {
+		t.Error("comments file should be removed once all comments are used")
+	}
+}
+
+func TestCreateTask(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+
+	task, err := dd.CreateTask("new-task", "Do the thing.\n")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.State != StatePending || task.FilePath != filepath.Join(dir, "tasks", "new-task.md") {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	if content, _ := task.Content(); content != "Do the thing.\n" {
+		t.Errorf("Content() = %q", content)
+	}
+
+	if _, err := dd.CreateTask("old-task", "x"); err == nil {
+		t.Error("CreateTask should fail for a task in review state")
+	}
+	if _, err := dd.CreateTask("a/b", "x"); err == nil {
+		t.Error("CreateTask should reject names containing '/'")
+	}
+	if _, err := dd.CreateTask("blank", " \n\t\n"); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("CreateTask with blank content: err = %v, want an empty error", err)
+	}
+}
+
+func TestSplitTask(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+	var changes []string
+	dd.OnChange = func(msg string, _ []string) { changes = append(changes, msg) }
+
+	task, _ := dd.FindTask("add-auth")
+	if err := dd.SplitTask(task, []SplitPart{{Name: "only", Content: "x"}}); err == nil {
+		t.Error("SplitTask should need at least two parts")
+	}
+	if err := dd.SplitTask(task, []SplitPart{{Name: "a", Content: "x"}, {Name: "a", Content: "y"}}); err == nil {
+		t.Error("SplitTask should reject duplicate names")
+	}
+	if err := dd.SplitTask(task, []SplitPart{{Name: "a", Content: "x"}, {Name: "b", Content: "\n"}}); err == nil {
+		t.Error("SplitTask should reject empty parts")
+	}
+
+	parts := []SplitPart{{Name: "login", Content: "Add login."}, {Name: "sessions", Content: "Add sessions."}}
+	if err := dd.SplitTask(task, parts); err != nil {
+		t.Fatalf("SplitTask: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", "add-auth.md")); !os.IsNotExist(err) {
+		t.Error("original task file should be removed")
+	}
+	if group, _ := dd.GroupContent("add-auth"); group != "Add authentication." {
+		t.Errorf("group.md = %q", group)
+	}
+	login, err := dd.FindTask("add-auth/login")
+	if err != nil {
+		t.Fatalf("FindTask: %v", err)
+	}
+	if content, _ := login.Content(); content != "Add login." {
+		t.Errorf("login content = %q", content)
+	}
+	if len(changes) != 1 || changes[0] != "split add-auth into 2 tasks" {
+		t.Errorf("changes = %v", changes)
+	}
+
+	grouped, _ := dd.FindTask("backend/add-api")
+	if err := dd.SplitTask(grouped, parts); err == nil {
+		t.Error("SplitTask should reject grouped tasks")
+	}
+}
+
 func TestMoveTaskAllStates(t *testing.T) {
 	for _, state := range []TaskState{StateReview, StateMerge, StateCompleted, StateAbandoned} {
 		t.Run(string(state), func(t *testing.T) {
@@ -557,6 +730,60 @@ func TestMoveTaskGroupPreserved(t *testing.T) {
 	}
 }
 
+func TestRenameTask(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+
+	task, err := dd.FindTask("backend/add-api")
+	if err != nil {
+		t.Fatalf("FindTask: %v", err)
+	}
+	must(t, dd.MoveTask(task, StateReview))
+	must(t, os.WriteFile(task.CommentsPath(), []byte("Fix the typo.\n"), 0o600))
+
+	must(t, os.WriteFile(filepath.Join(dir, "tasks", "backend", "add-db.md"), []byte("Add a database."), 0o600))
+	if err := dd.RenameTask(task, "add-db"); err == nil {
+		t.Error("RenameTask onto an existing task succeeded")
+	}
+	if err := dd.RenameTask(task, "other/add-api"); err == nil {
+		t.Error("RenameTask accepted a name with a slash")
+	}
+
+	must(t, dd.RenameTask(task, "add-endpoint"))
+	if task.Name != "add-endpoint" || filepath.Base(task.FilePath) != "add-endpoint.md" {
+		t.Errorf("task = %+v, want add-endpoint", task)
+	}
+	found, err := dd.FindTaskByState("backend/add-endpoint", StateReview)
+	if err != nil {
+		t.Fatalf("FindTaskByState after rename: %v", err)
+	}
+	if comments, err := found.Comments(); err != nil || comments == "" {
+		t.Errorf("comments after rename = %q, %v; want them to move with the task", comments, err)
+	}
+	if _, err := dd.FindTaskAny("backend/add-api"); err == nil {
+		t.Error("old name still found after rename")
+	}
+}
+
+func TestMoveTaskBackToPending(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, _ := NewDir(dir)
+
+	task, err := dd.FindTask("backend/add-api")
+	if err != nil {
+		t.Fatalf("FindTask: %v", err)
+	}
+	must(t, dd.MoveTask(task, StateReview))
+	must(t, dd.MoveTask(task, StatePending))
+
+	if want := filepath.Join(dir, "tasks", "backend", "add-api.md"); task.FilePath != want || task.State != StatePending {
+		t.Errorf("task = %s (%s), want %s (pending)", task.FilePath, task.State, want)
+	}
+	if _, err := dd.FindTask("backend/add-api"); err != nil {
+		t.Errorf("requeued task not pending: %v", err)
+	}
+}
+
 func TestMoveTaskInvalidState(t *testing.T) {
 	dir := setupDesignDir(t)
 	dd, _ := NewDir(dir)
@@ -917,6 +1144,33 @@ func TestGroupContentMissing(t *testing.T) {
 	}
 }
 
+func TestGroupBaseBranch(t *testing.T) {
+	dir := setupDesignDir(t)
+	must(t, os.WriteFile(filepath.Join(dir, "tasks", "backend", "group.md"), []byte("---\nbase: release/2.0\n---\nBackend group context."), 0o600))
+	dd, _ := NewDir(dir)
+
+	base, err := dd.GroupBaseBranch("backend")
+	if err != nil {
+		t.Fatalf("GroupBaseBranch: %v", err)
+	}
+	if base != "release/2.0" {
+		t.Errorf("GroupBaseBranch = %q, want release/2.0", base)
+	}
+	content, err := dd.GroupContent("backend")
+	if err != nil {
+		t.Fatalf("GroupContent: %v", err)
+	}
+	if content != "Backend group context." {
+		t.Errorf("GroupContent = %q, want the front matter left out", content)
+	}
+
+	for _, group := range []string{"", "frontend"} {
+		if base, err := dd.GroupBaseBranch(group); err != nil || base != "" {
+			t.Errorf("GroupBaseBranch(%q) = %q, %v; want no base", group, base, err)
+		}
+	}
+}
+
 func TestGroupContentEmptyGroup(t *testing.T) {
 	dir := setupDesignDir(t)
 	dd, _ := NewDir(dir)
@@ -1010,3 +1264,547 @@ func TestRecordPersistence(t *testing.T) {
 		t.Errorf("SHA = %q, want sha1", entries[0].SHA)
 	}
 }
+
+func TestRecordAddEntryChecklist(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := NewRecord(dir)
+	entry := RecordEntry{
+		SHA:      "sha1",
+		TaskName: "review:task1",
+		Checklist: []ChecklistResult{
+			{Item: "Docs updated", Passed: true},
+			{Item: "No debug logging", Passed: false, Note: "found fmt.Println"},
+		},
+	}
+	if err := rec.AddEntry(entry); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	entries, err := NewRecord(dir).Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].Checklist) != 2 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if got := entries[0].Checklist[1]; got.Passed || got.Note != "found fmt.Println" {
+		t.Errorf("checklist[1] = %+v", got)
+	}
+}
+
+func TestRecordAddEntryStampsTime(t *testing.T) {
+	dir := t.TempDir()
+
+	rec := NewRecord(dir)
+	must(t, rec.AddEntry(RecordEntry{SHA: "sha1", TaskName: "task1", Tokens: 42}))
+
+	entries, err := rec.Entries()
+	must(t, err)
+	if len(entries) != 1 || entries[0].Time.IsZero() || entries[0].Tokens != 42 {
+		t.Errorf("entries = %+v, want one stamped entry with tokens", entries)
+	}
+}
+
+func TestRecordArchive(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecord(dir)
+	must(t, os.MkdirAll(filepath.Join(dir, "state"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "record.json"), []byte(`[
+		{"sha":"z","task_name":"legacy"},
+		{"sha":"a","task_name":"jan","time":"2026-01-10T00:00:00Z"},
+		{"sha":"b","task_name":"feb","time":"2026-02-03T00:00:00Z"},
+		{"sha":"c","task_name":"mar","time":"2026-03-20T00:00:00Z"}
+	]`), 0o600))
+	day := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		must(t, err)
+		return d
+	}
+
+	n, err := rec.Archive(day("2026-02-15"))
+	must(t, err)
+	if n != 2 {
+		t.Errorf("Archive = %d, want 2", n)
+	}
+
+	entries, err := rec.Entries()
+	must(t, err)
+	if len(entries) != 2 || entries[0].TaskName != "legacy" || entries[1].TaskName != "mar" {
+		t.Errorf("remaining entries = %+v, want legacy and mar", entries)
+	}
+	for _, month := range []string{"2026-01", "2026-02"} {
+		if _, err := os.Stat(filepath.Join(dir, "state", "archive", "record-"+month+".json")); err != nil {
+			t.Errorf("archive for %s: %v", month, err)
+		}
+	}
+
+	// Archiving again merges into the existing month files.
+	must(t, rec.AddEntry(RecordEntry{SHA: "d", TaskName: "feb2", Time: day("2026-02-20")}))
+	if n, err := rec.Archive(day("2026-03-01")); err != nil || n != 1 {
+		t.Errorf("second Archive = %d, %v; want 1", n, err)
+	}
+	archived, err := rec.ArchivedEntries()
+	must(t, err)
+	var names []string
+	for _, e := range archived {
+		names = append(names, e.TaskName)
+	}
+	if strings.Join(names, ",") != "jan,feb,feb2" {
+		t.Errorf("archived = %v, want jan,feb,feb2", names)
+	}
+}
+
+func TestRecordRenameTask(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRecord(dir)
+	must(t, os.MkdirAll(filepath.Join(dir, "state", "archive"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "record.json"), []byte(`[
+		{"sha":"a","task_name":"backend/add-api"},
+		{"sha":"b","task_name":"merge:backend/add-api"},
+		{"sha":"c","task_name":"backend/add-api-v2"}
+	]`), 0o600))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "archive", "record-2026-01.json"), []byte(`[
+		{"sha":"z","task_name":"review:backend/add-api"}
+	]`), 0o600))
+
+	n, err := rec.RenameTask("backend/add-api", "backend/add-endpoint")
+	must(t, err)
+	if n != 3 {
+		t.Errorf("RenameTask = %d, want 3", n)
+	}
+
+	entries, err := rec.Entries()
+	must(t, err)
+	archived, err := rec.ArchivedEntries()
+	must(t, err)
+	var names []string
+	for _, e := range append(entries, archived...) {
+		names = append(names, e.TaskName)
+	}
+	want := "backend/add-endpoint,merge:backend/add-endpoint,backend/add-api-v2,review:backend/add-endpoint"
+	if strings.Join(names, ",") != want {
+		t.Errorf("names = %v, want %s", names, want)
+	}
+}
+
+func TestRecordConcurrentAdds(t *testing.T) {
+	dir := t.TempDir()
+
+	const writers = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Go(func() {
+			// Separate Records, as separate processes would have.
+			errs <- NewRecord(dir).Add(fmt.Sprintf("sha%d", i), fmt.Sprintf("task%d", i))
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		must(t, err)
+	}
+
+	entries, err := NewRecord(dir).Entries()
+	must(t, err)
+	if len(entries) != writers {
+		t.Fatalf("got %d entries, want %d", len(entries), writers)
+	}
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		seen[e.SHA] = true
+	}
+	if len(seen) != writers {
+		t.Errorf("entries were dropped or duplicated: %+v", entries)
+	}
+
+	leftovers, err := filepath.Glob(filepath.Join(dir, "state", ".record.json.*"))
+	must(t, err)
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestFailureLog(t *testing.T) {
+	dir := t.TempDir()
+
+	log := NewFailureLog(dir)
+	entries, err := log.Entries()
+	must(t, err)
+	if len(entries) != 0 {
+		t.Errorf("expected no failures, got %d", len(entries))
+	}
+
+	must(t, log.Add(Failure{TaskName: "task1", Action: "run", Category: "timeout", Message: "session time limit reached"}))
+	must(t, log.Add(Failure{TaskName: "task2", Action: "merge", Category: "push", Message: "pushing: rejected"}))
+
+	entries, err = NewFailureLog(dir).Entries()
+	must(t, err)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(entries))
+	}
+	if entries[0].TaskName != "task1" || entries[1].Category != "push" {
+		t.Errorf("entries = %+v", entries)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("failure should be stamped with the current time")
+	}
+}
+
+func TestReviewChecklist(t *testing.T) {
+	dir := t.TempDir()
+	dd, err := NewDir(dir)
+	must(t, err)
+
+	items, err := dd.ReviewChecklist()
+	if err != nil {
+		t.Fatalf("ReviewChecklist without file: %v", err)
+	}
+	if items != nil {
+		t.Errorf("items = %v, want nil", items)
+	}
+
+	content := "# Review checklist\n\nThings to check:\n\n" +
+		"- Public API is documented\n" +
+		"* [ ] No TODOs left behind\n" +
+		"3. Migrations are reversible\n"
+	must(t, os.WriteFile(filepath.Join(dir, "review.md"), []byte(content), 0o600))
+
+	items, err = dd.ReviewChecklist()
+	if err != nil {
+		t.Fatalf("ReviewChecklist: %v", err)
+	}
+	want := []string{"Public API is documented", "No TODOs left behind", "Migrations are reversible"}
+	if strings.Join(items, "|") != strings.Join(want, "|") {
+		t.Errorf("items = %q, want %q", items, want)
+	}
+}
+
+func TestPersona(t *testing.T) {
+	dir := t.TempDir()
+	dd, err := NewDir(dir)
+	must(t, err)
+
+	if _, err := dd.Persona("security"); err == nil || !strings.Contains(err.Error(), "no personas") {
+		t.Errorf("Persona without reviewers/ = %v, want a not found error", err)
+	}
+
+	must(t, os.MkdirAll(filepath.Join(dir, "reviewers"), 0o750))
+	content := "# Security reviewer\n\nLook for injection.\n\n" +
+		"## Checklist\n\n- Input is escaped\n- Permissions are checked\n\n" +
+		"## Notes\n\n- Ignore test fixtures\n"
+	must(t, os.WriteFile(filepath.Join(dir, "reviewers", "security.md"), []byte(content), 0o600))
+	must(t, os.WriteFile(filepath.Join(dir, "reviewers", "performance.md"), []byte("Watch allocations."), 0o600))
+
+	names, err := dd.Personas()
+	must(t, err)
+	if strings.Join(names, ",") != "performance,security" {
+		t.Errorf("Personas = %v, want [performance security]", names)
+	}
+
+	p, err := dd.Persona("security")
+	must(t, err)
+	if strings.Join(p.Checklist, "|") != "Input is escaped|Permissions are checked" {
+		t.Errorf("Checklist = %q", p.Checklist)
+	}
+	for _, want := range []string{"Look for injection.", "## Notes", "- Ignore test fixtures"} {
+		if !strings.Contains(p.Focus, want) {
+			t.Errorf("Focus missing %q:\n%s", want, p.Focus)
+		}
+	}
+	if strings.Contains(p.Focus, "Input is escaped") {
+		t.Error("Focus should not repeat the checklist")
+	}
+
+	if _, err := dd.Persona("style"); err == nil || !strings.Contains(err.Error(), "performance, security") {
+		t.Errorf("Persona(style) = %v, want an error listing the personas", err)
+	}
+	if _, err := dd.Persona("../rules"); err == nil {
+		t.Error("expected error for a persona name with a path")
+	}
+}
+
+func TestParseChecklistResults(t *testing.T) {
+	items := []string{"Docs", "Tests", "Changelog"}
+	output := "Some summary text.\n\n" +
+		"1. PASS\n" +
+		"2. FAIL: missing test for error path\n" +
+		"7. PASS\n"
+
+	results := ParseChecklistResults(output, items)
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want 2", results)
+	}
+	if results[0].Item != "Docs" || !results[0].Passed {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Item != "Tests" || results[1].Passed || results[1].Note != "missing test for error path" {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+}
+
+func TestTaskFrontmatter(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, err := NewDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := dd.FindTaskByState("old-task", StateReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := task.Frontmatter()
+	if err != nil {
+		t.Fatalf("Frontmatter: %v", err)
+	}
+	if len(meta) != 0 {
+		t.Errorf("task without front matter has metadata %v", meta)
+	}
+
+	must(t, task.SetFrontmatter(map[string]string{"reviewer": "alice"}))
+	must(t, task.SetFrontmatter(map[string]string{"assigned_at": "2026-03-01T00:00:00Z"}))
+
+	meta, err = task.Frontmatter()
+	if err != nil {
+		t.Fatalf("Frontmatter: %v", err)
+	}
+	if meta["reviewer"] != "alice" || meta["assigned_at"] != "2026-03-01T00:00:00Z" {
+		t.Errorf("metadata = %v", meta)
+	}
+
+	content, err := task.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "Done." {
+		t.Errorf("Content = %q, want the task body without front matter", content)
+	}
+}
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		content, front, body string
+		ok                   bool
+	}{
+		{"# Task\n", "", "# Task\n", false},
+		{"---\nreviewer: bob\n---\n# Task\n", "reviewer: bob\n", "# Task\n", true},
+		{"---\n---\nbody", "", "body", true},
+		{"---\nno closing delimiter\n", "", "---\nno closing delimiter\n", false},
+	}
+	for _, tt := range tests {
+		front, body, ok := splitFrontmatter(tt.content)
+		if front != tt.front || body != tt.body || ok != tt.ok {
+			t.Errorf("splitFrontmatter(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.content, front, body, ok, tt.front, tt.body, tt.ok)
+		}
+	}
+}
+
+func TestCommitTemplate(t *testing.T) {
+	dir := t.TempDir()
+	dd, err := NewDir(dir)
+	must(t, err)
+
+	tmpl, err := dd.CommitTemplate()
+	if err != nil || tmpl != "" {
+		t.Fatalf("CommitTemplate without file = %q, %v", tmpl, err)
+	}
+
+	must(t, os.WriteFile(filepath.Join(dir, "commit_template.md"),
+		[]byte("<type>({{group}}): <description>\n\nTask: {{task}}\nRefs #{{issue}}\n"), 0o600))
+	tmpl, err = dd.CommitTemplate()
+	if err != nil {
+		t.Fatalf("CommitTemplate: %v", err)
+	}
+
+	got := RenderCommitTemplate(tmpl, CommitTemplateVars{Task: "42-fix-bug", Group: "issues", Issue: "42"})
+	if want := "<type>(issues): <description>\n\nTask: 42-fix-bug\nRefs #42"; got != want {
+		t.Errorf("rendered = %q, want %q", got, want)
+	}
+
+	got = RenderCommitTemplate(tmpl, CommitTemplateVars{Task: "add-api", Group: "backend"})
+	if want := "<type>(backend): <description>\n\nTask: add-api"; got != want {
+		t.Errorf("rendered without issue = %q, want %q", got, want)
+	}
+}
+
+func TestMismatchedCommitSubjects(t *testing.T) {
+	rendered := "<type>(backend): <description>\n\nTask: add-api"
+	subjects := []string{
+		"feat(backend): add the API",
+		"fix(backend): handle empty bodies",
+		"add the API",
+		"feat(frontend): wrong scope",
+	}
+	bad := MismatchedCommitSubjects(rendered, subjects)
+	if strings.Join(bad, "|") != "add the API|feat(frontend): wrong scope" {
+		t.Errorf("mismatched = %q", bad)
+	}
+
+	if re := CommitSubjectPattern("[add-api] <summary>"); !re.MatchString("[add-api] x") || re.MatchString("[other] x") {
+		t.Errorf("pattern %q should match brackets literally", re)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	must(t, Scaffold(dir))
+	dd, err := NewDir(dir)
+	must(t, err)
+
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		must(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		must(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	problems, err := dd.Validate()
+	must(t, err)
+	if len(problems) != 0 {
+		t.Fatalf("fresh design dir has problems: %+v", problems)
+	}
+
+	write("tasks/good.md", "Do the thing.")
+	write("tasks/empty.md", "---\nreviewer: bob\n---\n\n  \n")
+	write("tasks/unclosed.md", "---\nreviewer: bob\nDo the thing.")
+	write("tasks/nested.md", "---\nreviewer:\n  name: bob\n---\nDo the thing.")
+	write("tasks/someday.md", "---\nexpires: next spring\n---\nDo the thing.")
+	write("tasks/Dupe Task.md", "One.")
+	write("state/review/dupe-task.md", "Two.")
+	write("tasks/done/group.md", "Shared context.")
+	write("state/completed/done/finished.md", "Finished.")
+	write("tasks/orphan/group.md", "Nobody home.")
+	write("state/record.json", "{not json")
+	write("milestone/2026-01-01.md", "# Goals\n\nNothing promised.\n")
+	write("milestone/2026-02-01.md", "## Ship it\n")
+
+	problems, err = dd.Validate()
+	must(t, err)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Kind+" "+p.Path)
+	}
+	want := []string{
+		"duplicate-slug state/review/dupe-task.md",
+		"empty-task tasks/empty.md",
+		"invalid-frontmatter tasks/nested.md",
+		"invalid-frontmatter tasks/someday.md",
+		"invalid-frontmatter tasks/unclosed.md",
+		"malformed-record state/record.json",
+		"milestone-no-promises milestone/2026-01-01.md",
+		"orphan-group tasks/orphan/group.md",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, p := range problems {
+		if p.Kind == "duplicate-slug" && !strings.Contains(p.Fix, "rename") {
+			t.Errorf("differently named duplicates should suggest renaming, got %q", p.Fix)
+		}
+	}
+}
+
+func TestStaleTasks(t *testing.T) {
+	dir := setupDesignDir(t)
+	dd, err := NewDir(dir)
+	must(t, err)
+
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.Local)
+	write := func(rel, content string, modified time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		must(t, os.WriteFile(path, []byte(content), 0o600))
+		must(t, os.Chtimes(path, modified, modified))
+	}
+	recent := now.Add(-time.Hour)
+	old := now.AddDate(0, 0, -45)
+
+	// Expired yesterday; expiring today is not yet expired.
+	write("tasks/add-auth.md", "---\nexpires: 2026-06-14\n---\nAdd authentication.", recent)
+	write("tasks/fix-bug.md", "---\nexpires: 2026-06-15\n---\nFix the login bug.", recent)
+	// Untouched for 45 days, but a recent run keeps the review task fresh.
+	write("tasks/backend/add-api.md", "Add REST API.", old)
+	write("state/review/old-task.md", "Done.", old)
+	must(t, dd.Record().AddEntry(RecordEntry{SHA: "abc", TaskName: "old-task", Time: now.AddDate(0, 0, -2)}))
+	// So does a recent review, recorded under the review: prefix.
+	write("state/review/reviewed.md", "Done.", old)
+	must(t, dd.Record().AddEntry(RecordEntry{SHA: "def", TaskName: "review:reviewed", Time: now.AddDate(0, 0, -1)}))
+	// Completed tasks are never stale.
+	write("state/completed/shipped.md", "Shipped.", old)
+
+	reasons := func(staleAfter time.Duration) []string {
+		t.Helper()
+		stale, err := dd.StaleTasks(staleAfter, now)
+		must(t, err)
+		var got []string
+		for _, s := range stale {
+			got = append(got, s.Task.Label()+": "+s.Reason())
+		}
+		return got
+	}
+
+	if got := reasons(0); strings.Join(got, "; ") != "add-auth: expired 2026-06-14" {
+		t.Errorf("without stale_after: %q", got)
+	}
+	want := "add-auth: expired 2026-06-14; backend/add-api: idle 45d"
+	if got := reasons(30 * 24 * time.Hour); strings.Join(got, "; ") != want {
+		t.Errorf("with stale_after 30d: %q, want %q", got, want)
+	}
+}
+
+func TestDevEnv(t *testing.T) {
+	dir := t.TempDir()
+	dd, _ := NewDir(dir)
+
+	env, err := dd.DevEnv(nil)
+	if err != nil || env != nil {
+		t.Fatalf("DevEnv without %s = %v, %v; want nothing", DevEnvFile, env, err)
+	}
+
+	content := "# local settings\n\n" +
+		"export PORT=3000\n" +
+		"DATABASE_URL=postgres://localhost/app_${HYDRA_TASK}\n" +
+		"API_URL=\"http://localhost:$PORT/api\"\n" +
+		"LITERAL='${HYDRA_TASK}'\n"
+	must(t, os.WriteFile(filepath.Join(dir, DevEnvFile), []byte(content), 0o600))
+	env, err = dd.DevEnv(map[string]string{"HYDRA_TASK": "login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"PORT=3000",
+		"DATABASE_URL=postgres://localhost/app_login",
+		"API_URL=http://localhost:3000/api",
+		"LITERAL=${HYDRA_TASK}",
+	}
+	if !slices.Equal(env, want) {
+		t.Errorf("DevEnv = %q, want %q", env, want)
+	}
+
+	must(t, os.WriteFile(filepath.Join(dir, DevEnvFile), []byte("PORT 3000\n"), 0o600))
+	if _, err := dd.DevEnv(nil); err == nil {
+		t.Error("DevEnv accepted a line without =")
+	}
+}
+
+func TestMarkdownSections(t *testing.T) {
+	content := "Intro.\n# One\n\nText.\n```sh\n# not a heading\n```\n## Two\n#hashtag\n"
+	sections := MarkdownSections(content)
+	want := []string{
+		"Intro.\n",
+		"# One\n\nText.\n```sh\n# not a heading\n```\n",
+		"## Two\n#hashtag\n",
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d: %q", len(sections), len(want), sections)
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d = %q, want %q", i, sections[i], want[i])
+		}
+	}
+}
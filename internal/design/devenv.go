@@ -0,0 +1,70 @@
+package design
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DevEnvFile is the file in the design directory whose variables hydra
+// review dev adds to the dev command's environment.
+const DevEnvFile = ".env.hydra"
+
+// DevEnv reads .env.hydra from the design directory and returns its
+// variables as KEY=VALUE assignments, in file order. Lines are KEY=VALUE,
+// optionally preceded by "export"; blank lines and lines starting with # are
+// ignored, and a value in matching single or double quotes is unquoted.
+// References such as ${HYDRA_TASK} in unquoted and double-quoted values are
+// expanded from vars, then from the variables defined above them. A missing
+// file yields no variables.
+func (d *Dir) DevEnv(vars map[string]string) ([]string, error) {
+	path := filepath.Join(d.Path, DevEnvFile)
+	f, err := os.Open(path) //nolint:gosec // path is constructed from trusted design dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", DevEnvFile, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	defined := make(map[string]string)
+	lookup := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return defined[name]
+	}
+
+	var env []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%s:%d: want KEY=VALUE", DevEnvFile, n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = os.Expand(value[1:len(value)-1], lookup)
+		default:
+			value = os.Expand(value, lookup)
+		}
+		defined[key] = value
+		env = append(env, key+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", DevEnvFile, err)
+	}
+	return env, nil
+}
@@ -0,0 +1,96 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// expiresKey is the front matter key holding the date after which a task is
+// no longer worth doing.
+const expiresKey = "expires"
+
+// Expires returns the date in the task's expires: front matter, in local
+// time. ok is false if the task has no expiry date or it is not a
+// YYYY-MM-DD date.
+func (t *Task) Expires() (date time.Time, ok bool) {
+	meta, err := t.Frontmatter()
+	if err != nil || meta[expiresKey] == "" {
+		return time.Time{}, false
+	}
+	date, err = time.ParseInLocation(time.DateOnly, meta[expiresKey], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// StaleTask is a pending or review task that has expired or has gone
+// untouched for too long.
+type StaleTask struct {
+	Task    Task
+	Expires time.Time     // the task's expires: date; zero if it has none
+	Expired bool          // the expires: date has passed
+	Idle    time.Duration // time since the task file or its record last changed
+}
+
+// Reason describes why the task is stale, such as "expired 2026-05-01" or
+// "idle 45d".
+func (s StaleTask) Reason() string {
+	if s.Expired {
+		return "expired " + s.Expires.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("idle %dd", int(s.Idle.Hours()/24))
+}
+
+// StaleTasks returns the pending and review tasks that expired before now,
+// and, if staleAfter is positive, those that have not changed for at least
+// staleAfter. A task's last change is the later of its file's modification
+// time and its newest state/record.json entry. Tasks are sorted by state
+// and name.
+func (d *Dir) StaleTasks(staleAfter time.Duration, now time.Time) ([]StaleTask, error) {
+	entries, err := d.Record().Entries()
+	if err != nil {
+		return nil, err
+	}
+	// Reviews and tests are recorded as "review:group/name" and so on; any
+	// of them counts as a change to the task.
+	lastRecorded := make(map[string]time.Time)
+	for _, e := range entries {
+		_, label := e.Task()
+		if e.Time.After(lastRecorded[label]) {
+			lastRecorded[label] = e.Time
+		}
+	}
+
+	var stale []StaleTask
+	for _, state := range []TaskState{StatePending, StateReview} {
+		tasks, err := d.TasksByState(state)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].Label() < tasks[j].Label() })
+
+		for _, t := range tasks {
+			s := StaleTask{Task: t}
+			if expires, ok := t.Expires(); ok {
+				s.Expires = expires
+				s.Expired = !now.Before(expires.AddDate(0, 0, 1))
+			}
+
+			last := lastRecorded[t.Label()]
+			if info, err := os.Stat(t.FilePath); err == nil && info.ModTime().After(last) {
+				last = info.ModTime()
+			}
+			if !last.IsZero() {
+				s.Idle = now.Sub(last)
+			}
+
+			if s.Expired || (staleAfter > 0 && s.Idle >= staleAfter) {
+				stale = append(stale, s)
+			}
+		}
+	}
+	return stale, nil
+}
@@ -0,0 +1,89 @@
+package design
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FailureLog records failed hydra workflows in state/failures.json, so
+// their causes can be summarized later by hydra stats.
+type FailureLog struct {
+	path string // {designDir}/state/failures.json
+	dir  *Dir   // reports changes; nil for logs from NewFailureLog
+}
+
+// Failure is one failed run, review, test, or merge of a task.
+type Failure struct {
+	TaskName string    `json:"task_name"`
+	Action   string    `json:"action"`   // run, review, test, or merge
+	Category string    `json:"category"` // coarse cause, e.g. timeout or rebase
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+// NewFailureLog opens or creates a failure log at
+// {designDir}/state/failures.json.
+func NewFailureLog(designDir string) *FailureLog {
+	return &FailureLog{path: filepath.Join(designDir, "state", "failures.json")}
+}
+
+// Failures returns the design directory's failure log. Failures added
+// through it are reported to Changed.
+func (d *Dir) Failures() *FailureLog {
+	log := NewFailureLog(d.Path)
+	log.dir = d
+	return log
+}
+
+// Add appends a failure to the log. A failure without a time is stamped
+// with the current time. Concurrent appends are serialized.
+func (l *FailureLog) Add(f Failure) error {
+	if f.Time.IsZero() {
+		f.Time = time.Now().UTC().Truncate(time.Second)
+	}
+
+	err := withFileLock(l.path, func() error {
+		entries, err := l.Entries()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, f)
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling failure log: %w", err)
+		}
+		if err := writeFileAtomic(l.path, data); err != nil {
+			return fmt.Errorf("writing failure log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if l.dir != nil {
+		l.dir.Changed([]string{l.path}, "record %s failure of %s (%s)", f.Action, f.TaskName, f.Category)
+	}
+	return nil
+}
+
+// Entries returns all logged failures, oldest first.
+func (l *FailureLog) Entries() ([]Failure, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading failure log: %w", err)
+	}
+
+	var entries []Failure
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing failure log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,111 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// frontmatterDelim opens and closes the metadata block at the top of a task
+// file.
+const frontmatterDelim = "---"
+
+// splitFrontmatter separates a leading "---" delimited YAML block from the
+// rest of content. ok is false if content has no such block.
+func splitFrontmatter(content string) (front, body string, ok bool) {
+	rest, found := strings.CutPrefix(content, frontmatterDelim+"\n")
+	if !found {
+		return "", content, false
+	}
+	// An empty block: the closing delimiter directly follows the opening one.
+	if after, found := strings.CutPrefix(rest, frontmatterDelim+"\n"); found {
+		return "", after, true
+	}
+	front, body, found = strings.Cut(rest, "\n"+frontmatterDelim+"\n")
+	if !found {
+		return "", content, false
+	}
+	return front + "\n", body, true
+}
+
+// Frontmatter returns the metadata hydra keeps in the YAML block at the top
+// of the task file, such as the assigned reviewer. Tasks without a block
+// have no metadata.
+func (t *Task) Frontmatter() (map[string]string, error) {
+	data, err := os.ReadFile(t.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading task %s: %w", t.Name, err)
+	}
+	front, _, ok := splitFrontmatter(string(data))
+	meta := make(map[string]string)
+	if !ok {
+		return meta, nil
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return nil, fmt.Errorf("parsing front matter of task %s: %w", t.Name, err)
+	}
+	return meta, nil
+}
+
+// baseKey is the group.md front matter key naming the branch a group's
+// tasks start from and merge into.
+const baseKey = "base"
+
+// GroupFrontmatter returns the metadata in the YAML block at the top of the
+// group's group.md. Groups without the file or a block have no metadata.
+func (d *Dir) GroupFrontmatter(group string) (map[string]string, error) {
+	meta := make(map[string]string)
+	if group == "" {
+		return meta, nil
+	}
+	content, err := d.readFile(filepath.Join("tasks", group, "group.md"))
+	if err != nil {
+		return nil, err
+	}
+	front, _, ok := splitFrontmatter(content)
+	if !ok {
+		return meta, nil
+	}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return nil, fmt.Errorf("parsing front matter of group %s: %w", group, err)
+	}
+	return meta, nil
+}
+
+// GroupBaseBranch returns the branch named by base: in the group's
+// group.md front matter, such as "release/2.0", or "" if the group
+// follows the repository's default branch.
+func (d *Dir) GroupBaseBranch(group string) (string, error) {
+	meta, err := d.GroupFrontmatter(group)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(meta[baseKey]), nil
+}
+
+// SetFrontmatter sets metadata keys in the task file's front matter,
+// creating the block if the task has none. The task content is unchanged.
+func (t *Task) SetFrontmatter(values map[string]string) error {
+	data, err := os.ReadFile(t.FilePath)
+	if err != nil {
+		return fmt.Errorf("reading task %s: %w", t.Name, err)
+	}
+	meta, err := t.Frontmatter()
+	if err != nil {
+		return err
+	}
+	for k, v := range values {
+		meta[k] = v
+	}
+	front, err := yaml.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("encoding front matter: %w", err)
+	}
+
+	_, body, _ := splitFrontmatter(string(data))
+	content := frontmatterDelim + "\n" + string(front) + frontmatterDelim + "\n" + body
+	return os.WriteFile(t.FilePath, []byte(content), 0o600)
+}
@@ -0,0 +1,43 @@
+package design
+
+import "strings"
+
+// MarkdownHeading returns the level and text of an ATX heading line.
+func MarkdownHeading(line string) (level int, text string, ok bool) {
+	trimmed := strings.TrimLeft(line, "#")
+	level = len(line) - len(trimmed)
+	if level == 0 || level > 6 || !strings.HasPrefix(trimmed, " ") {
+		return 0, "", false
+	}
+	return level, strings.TrimSpace(trimmed), true
+}
+
+// MarkdownSections splits content at its markdown headings, leaving lines
+// in code fences alone. Text before the first heading is a section of its
+// own.
+func MarkdownSections(content string) []string {
+	var sections []string
+	var cur strings.Builder
+	inFence := false
+	for line := range strings.SplitAfterSeq(content, "\n") {
+		inFence = toggleFence(line, inFence)
+		if _, _, ok := MarkdownHeading(strings.TrimRight(line, "\r\n")); ok && !inFence && cur.Len() > 0 {
+			sections = append(sections, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		sections = append(sections, cur.String())
+	}
+	return sections
+}
+
+// toggleFence returns whether the line after line is in a code fence,
+// given whether line is.
+func toggleFence(line string, inFence bool) bool {
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		return !inFence
+	}
+	return inFence
+}
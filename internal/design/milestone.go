@@ -275,12 +275,14 @@ func (d *Dir) DeliverMilestone(m *Milestone) error {
 		return fmt.Errorf("creating delivered dir: %w", err)
 	}
 
-	destPath := filepath.Join(ackDir, filepath.Base(m.FilePath))
-	if err := os.Rename(m.FilePath, destPath); err != nil {
+	srcPath := m.FilePath
+	destPath := filepath.Join(ackDir, filepath.Base(srcPath))
+	if err := os.Rename(srcPath, destPath); err != nil {
 		return fmt.Errorf("moving milestone to delivered: %w", err)
 	}
 
 	m.FilePath = destPath
+	d.Changed([]string{srcPath, destPath}, "deliver milestone %s", m.Date)
 	return nil
 }
 
@@ -299,10 +301,47 @@ func (d *Dir) CreateMilestone(date, content string) (*Milestone, error) {
 	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
 		return nil, fmt.Errorf("writing milestone: %w", err)
 	}
+	d.Changed([]string{filePath}, "create milestone %s", date)
 
 	return &Milestone{Date: date, FilePath: filePath}, nil
 }
 
+// AddPromise adds a promise to the milestone for date, creating the
+// milestone if there is none. A promise whose heading has the same slug is
+// left as it is. added reports whether the milestone changed.
+func (d *Dir) AddPromise(date, heading, body string) (added bool, err error) {
+	promise := "## " + heading + "\n"
+	if body != "" {
+		promise += "\n" + strings.TrimSpace(body) + "\n"
+	}
+
+	m, err := d.FindMilestone(date)
+	if err != nil {
+		if _, err := d.CreateMilestone(date, promise); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	content, err := m.Content()
+	if err != nil {
+		return false, err
+	}
+	slug := Slugify(heading)
+	for _, p := range ParsePromises(content) {
+		if p.Slug == slug {
+			return false, nil
+		}
+	}
+
+	content = strings.TrimRight(content, "\n") + "\n\n" + promise
+	if err := os.WriteFile(m.FilePath, []byte(content), 0o600); err != nil {
+		return false, fmt.Errorf("writing milestone: %w", err)
+	}
+	d.Changed([]string{m.FilePath}, "add promise %q to milestone %s", heading, date)
+	return true, nil
+}
+
 // VerifyMilestone checks whether all promises in a milestone have completed tasks.
 // It looks for tasks in the milestone's task group (pending state) and also checks
 // state directories (review, merge, completed, abandoned) by task name, since
@@ -434,5 +473,8 @@ func (d *Dir) RepairMilestone(m *Milestone) (*RepairResult, error) {
 		result.Created = append(result.Created, p.Slug)
 	}
 
+	if len(result.Created) > 0 {
+		d.Changed([]string{groupDir}, "create %d task(s) for milestone %s", len(result.Created), m.Date)
+	}
 	return result, nil
 }
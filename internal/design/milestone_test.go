@@ -3,7 +3,9 @@ package design
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestSlugify(t *testing.T) {
@@ -249,9 +251,13 @@ func TestDeliverMilestone(t *testing.T) {
 	}
 
 	// Should be in delivered/.
-	if _, err := os.Stat(filepath.Join(dir, "milestone", "delivered", "2025-06-01.md")); err != nil {
+	delivered := filepath.Join(dir, "milestone", "delivered", "2025-06-01.md")
+	if _, err := os.Stat(delivered); err != nil {
 		t.Errorf("delivered file missing: %v", err)
 	}
+	if m.FilePath != delivered {
+		t.Errorf("FilePath = %q, want %q", m.FilePath, delivered)
+	}
 }
 
 func TestCreateMilestoneSuccess(t *testing.T) {
@@ -276,6 +282,34 @@ func TestCreateMilestoneSuccess(t *testing.T) {
 	}
 }
 
+func TestAddPromise(t *testing.T) {
+	dir := t.TempDir()
+	dd, _ := NewDir(dir)
+
+	added, err := dd.AddPromise("2025-06-01", "Ship auth", "Issue #3")
+	if err != nil || !added {
+		t.Fatalf("AddPromise (new milestone) = %v, %v", added, err)
+	}
+	added, err = dd.AddPromise("2025-06-01", "Add tests", "")
+	if err != nil || !added {
+		t.Fatalf("AddPromise (existing milestone) = %v, %v", added, err)
+	}
+	added, err = dd.AddPromise("2025-06-01", "Ship Auth", "again")
+	if err != nil || added {
+		t.Fatalf("AddPromise (duplicate) = %v, %v", added, err)
+	}
+
+	m, err := dd.FindMilestone("2025-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := m.Content()
+	promises := ParsePromises(content)
+	if len(promises) != 2 || promises[0].Slug != "ship-auth" || promises[1].Slug != "add-tests" {
+		t.Errorf("promises = %+v", promises)
+	}
+}
+
 func TestCreateMilestoneDuplicate(t *testing.T) {
 	dir := t.TempDir()
 	must(t, os.MkdirAll(filepath.Join(dir, "milestone"), 0o750))
@@ -449,3 +483,85 @@ func TestMilestoneTaskGroup(t *testing.T) {
 		t.Errorf("MilestoneTaskGroup = %q", got)
 	}
 }
+
+func TestParseVerifyFailures(t *testing.T) {
+	text := "Failed requirements:\n\n" +
+		"- Export to CSV: the export command\n  is missing\n" +
+		"- Logging: no tests\n" +
+		"12. Ship user authentication: login fails\n\n" +
+		"Tests pass otherwise.\n"
+	got, listed := ParseVerifyFailures(text)
+	if !listed {
+		t.Error("listed = false for failures written as a list")
+	}
+	want := []string{
+		"Failed requirements:",
+		"Export to CSV: the export command is missing",
+		"Logging: no tests",
+		"Ship user authentication: login fails",
+		"Tests pass otherwise.",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseVerifyFailures =\n%q\nwant\n%q", got, want)
+	}
+
+	got, listed = ParseVerifyFailures("Export is missing.\n\nLogging has no tests.\n")
+	if listed || len(got) != 2 {
+		t.Errorf("ParseVerifyFailures of paragraphs = %q, listed %v; want 2 entries, not listed", got, listed)
+	}
+}
+
+func TestVerifyReportPromiseFailures(t *testing.T) {
+	report := &VerifyReport{Failures: []string{
+		"export to csv: the export command is missing",
+		"Ship user-authentication: login fails",
+		"Shipping: labels are wrong",
+	}}
+	promises := ParsePromises("## Export to CSV\n\n## Ship user authentication\n\n## Ship\n")[:2]
+	got := report.PromiseFailures(promises)
+	want := report.Failures[:2]
+	if !slices.Equal(got, want) {
+		t.Errorf("PromiseFailures = %q, want %q", got, want)
+	}
+
+	// A failed report that doesn't say which requirements failed breaks
+	// every promise.
+	missing := &VerifyReport{}
+	if got := missing.PromiseFailures(promises); len(got) != 1 || got[0] != unlistedFailure {
+		t.Errorf("PromiseFailures without failures = %q", got)
+	}
+	unlisted := &VerifyReport{Failures: []string{"Shipping labels are wrong and more."}, Unlisted: true}
+	if got := unlisted.PromiseFailures(promises); !slices.Equal(got, unlisted.Failures) {
+		t.Errorf("PromiseFailures of unlisted failures = %q", got)
+	}
+	passed := &VerifyReport{Passed: true}
+	if got := passed.PromiseFailures(promises); len(got) != 0 {
+		t.Errorf("PromiseFailures of a passing report = %q", got)
+	}
+}
+
+func TestVerifyReportRoundTrip(t *testing.T) {
+	dd, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report, err := dd.VerifyReport(); err != nil || report != nil {
+		t.Fatalf("VerifyReport with none saved = %v, %v; want nil", report, err)
+	}
+
+	var messages []string
+	dd.OnChange = func(message string, _ []string) { messages = append(messages, message) }
+	want := VerifyReport{Time: time.Unix(100, 0).UTC(), SHA: "0123456789abcdef", Failures: []string{"Logging: no tests"}}
+	must(t, dd.SaveVerifyReport(want))
+
+	got, err := dd.VerifyReport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Time.Equal(want.Time) || got.SHA != want.SHA || got.Passed || !slices.Equal(got.Failures, want.Failures) {
+		t.Errorf("VerifyReport = %+v, want %+v", got, want)
+	}
+	if len(messages) != 1 || messages[0] != "record verify failed at 0123456789ab" {
+		t.Errorf("messages = %q", messages)
+	}
+}
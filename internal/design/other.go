@@ -44,6 +44,13 @@ func (d *Dir) OtherFiles() ([]string, error) {
 	return names, nil
 }
 
+// otherNotFound returns the error for a file missing from other/,
+// suggesting the closest name there.
+func otherNotFound(designDir, name string) error {
+	files, _ := (&Dir{Path: designDir}).OtherFiles()
+	return fmt.Errorf("other file %q not found%s", name, DidYouMean(name, files))
+}
+
 // OtherContent reads and returns the content of a file in other/.
 func (d *Dir) OtherContent(name string) (string, error) {
 	if err := validateOtherFileName(name); err != nil {
@@ -53,7 +60,7 @@ func (d *Dir) OtherContent(name string) (string, error) {
 	data, err := os.ReadFile(filepath.Join(d.Path, "other", name)) //nolint:gosec // name validated above
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("other file %q not found", name)
+			return "", otherNotFound(d.Path, name)
 		}
 		return "", fmt.Errorf("reading other file %q: %w", name, err)
 	}
@@ -68,7 +75,7 @@ func (d *Dir) RemoveOtherFile(name string) error {
 
 	path := filepath.Join(d.Path, "other", name)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return fmt.Errorf("other file %q not found", name)
+		return otherNotFound(d.Path, name)
 	}
 
 	if err := os.Remove(path); err != nil {
@@ -134,7 +141,7 @@ func EditOtherFile(designDir, fileName, editor string, stdin io.Reader, stdout,
 
 	filePath := filepath.Join(designDir, "other", fileName)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("other file %q not found", fileName)
+		return otherNotFound(designDir, fileName)
 	}
 
 	return runEditor(editor, filePath, stdin, stdout, stderr)
@@ -0,0 +1,90 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Persona is a named reviewer from reviewers/<name>.md, used for a
+// specialized review pass with hydra review run --persona.
+type Persona struct {
+	Name string
+	// Focus is the file's content apart from its checklist: who the reviewer
+	// is and what to look for.
+	Focus string
+	// Checklist holds the list items under the file's "Checklist" heading,
+	// checked in addition to review.md.
+	Checklist []string
+}
+
+// reviewersDir returns the directory reviewer personas are kept in.
+func (d *Dir) reviewersDir() string {
+	return filepath.Join(d.Path, "reviewers")
+}
+
+// Personas returns the names of the reviewer personas in reviewers/, sorted.
+func (d *Dir) Personas() ([]string, error) {
+	entries, err := os.ReadDir(d.reviewersDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading reviewers: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".md"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Persona loads the reviewer persona in reviewers/<name>.md. A heading
+// whose text is "Checklist" starts the persona's checklist: each markdown
+// list item under it, up to the next heading of the same or a higher level,
+// is one check. Everything else is the persona's focus.
+func (d *Dir) Persona(name string) (*Persona, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid reviewer persona name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(d.reviewersDir(), name+".md")) //nolint:gosec // path is constructed from trusted design dir
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading reviewer persona %s: %w", name, err)
+		}
+		names, _ := d.Personas()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("reviewer persona %q not found: no personas in reviewers/", name)
+		}
+		return nil, fmt.Errorf("reviewer persona %q not found (have: %s)", name, strings.Join(names, ", "))
+	}
+
+	p := &Persona{Name: name}
+	var focus []string
+	checklistLevel := 0 // heading level of the checklist section, 0 outside it
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if level, text, ok := MarkdownHeading(line); ok {
+			switch {
+			case strings.EqualFold(text, "checklist"):
+				checklistLevel = level
+				continue
+			case checklistLevel > 0 && level <= checklistLevel:
+				checklistLevel = 0
+			}
+		}
+		if checklistLevel > 0 {
+			if m := checklistItemRe.FindStringSubmatch(line); m != nil {
+				p.Checklist = append(p.Checklist, m[1])
+			}
+			continue
+		}
+		focus = append(focus, line)
+	}
+	p.Focus = strings.TrimSpace(strings.Join(focus, "\n"))
+	return p, nil
+}
@@ -0,0 +1,25 @@
+package design
+
+import (
+	"fmt"
+	"strings"
+)
+
+// previewLines is how many lines of a file Preview shows.
+const previewLines = 10
+
+// Preview returns the first lines of content, indented, for showing what a
+// destructive command is about to act on. Longer content ends with a count
+// of the lines left out.
+func Preview(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		if i == previewLines {
+			fmt.Fprintf(&b, "  ... (%d more lines)\n", len(lines)-previewLines)
+			break
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
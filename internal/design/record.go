@@ -5,17 +5,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Record maps commit SHAs to the task documents that produced them.
 type Record struct {
 	path string // {designDir}/state/record.json
+	dir  *Dir   // reports changes; nil for records from NewRecord
 }
 
 // RecordEntry represents a single SHA -> task name mapping.
 type RecordEntry struct {
-	SHA      string `json:"sha"`
-	TaskName string `json:"task_name"`
+	SHA             string            `json:"sha"`
+	TaskName        string            `json:"task_name"`
+	Checklist       []ChecklistResult `json:"checklist,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds,omitempty"` // wall time of the Claude session
+	Tokens          int64             `json:"tokens,omitempty"`           // API tokens the session used, when known
+	DocTokens       int64             `json:"doc_tokens,omitempty"`       // estimated tokens in the task document a run started with
+	BaseSHA         string            `json:"base_sha,omitempty"`         // for merges, the default branch's tip the task's commits landed on
+	Model           string            `json:"model,omitempty"`            // model that finished the session
+	LinesAdded      int               `json:"lines_added,omitempty"`      // lines the session's commits added
+	LinesDeleted    int               `json:"lines_deleted,omitempty"`    // lines the session's commits deleted
+	Time            time.Time         `json:"time,omitzero"`              // when the entry was recorded
+}
+
+// Task splits the entry's task name into the workflow that recorded it,
+// such as "review", and the task's name or group/name. Runs have no action.
+func (e RecordEntry) Task() (action, label string) {
+	if action, label, ok := strings.Cut(e.TaskName, ":"); ok {
+		return action, label
+	}
+	return "", e.TaskName
 }
 
 // NewRecord opens or creates a record at {designDir}/state/record.json.
@@ -27,27 +49,44 @@ func NewRecord(designDir string) *Record {
 
 // Add appends a SHA -> task name mapping to the record.
 func (r *Record) Add(sha, taskName string) error {
-	entries, err := r.Entries()
-	if err != nil {
-		return err
-	}
+	return r.AddEntry(RecordEntry{SHA: sha, TaskName: taskName})
+}
 
-	entries = append(entries, RecordEntry{SHA: sha, TaskName: taskName})
+// AddEntry appends a full entry, including any checklist results, to the
+// record. An entry without a time is stamped with the current time.
+// Concurrent appends, from this or other processes, are serialized.
+func (r *Record) AddEntry(entry RecordEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC().Truncate(time.Second)
+	}
 
-	data, err := json.MarshalIndent(entries, "", "  ")
+	err := withFileLock(r.path, func() error {
+		entries, err := r.Entries()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		if err := writeRecordFile(r.path, entries); err != nil {
+			return fmt.Errorf("writing record: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("marshaling record: %w", err)
+		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(r.path), 0o750); err != nil {
-		return fmt.Errorf("creating record directory: %w", err)
+	if r.dir != nil {
+		r.dir.Changed([]string{r.path}, "record %s at %s", entry.TaskName, shortSHA(entry.SHA))
 	}
+	return nil
+}
 
-	if err := os.WriteFile(r.path, data, 0o600); err != nil {
-		return fmt.Errorf("writing record: %w", err)
+// shortSHA abbreviates a commit SHA for messages.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
 	}
-
-	return nil
+	return sha
 }
 
 // Entries returns all recorded SHA -> task name entries.
@@ -67,3 +106,174 @@ func (r *Record) Entries() ([]RecordEntry, error) {
 
 	return entries, nil
 }
+
+// RenameTask rewrites the entries of a task renamed from oldName to newName,
+// both given as name or group/name, in record.json and its archives. Entries
+// of the task's reviews, merges, and other workflows ("review:name") are
+// rewritten along with its runs. It returns how many entries changed.
+func (r *Record) RenameTask(oldName, newName string) (int, error) {
+	var renamed int
+	var written []string
+	err := withFileLock(r.path, func() error {
+		archives, err := filepath.Glob(filepath.Join(r.archiveDir(), "record-*.json"))
+		if err != nil {
+			return err
+		}
+		for _, path := range append([]string{r.path}, archives...) {
+			entries, err := readRecordFile(path)
+			if err != nil {
+				return err
+			}
+			changed := 0
+			for i, e := range entries {
+				prefix, name := e.Task()
+				if name != oldName {
+					continue
+				}
+				if prefix != "" {
+					entries[i].TaskName = prefix + ":" + newName
+				} else {
+					entries[i].TaskName = newName
+				}
+				changed++
+			}
+			if changed == 0 {
+				continue
+			}
+			if err := writeRecordFile(path, entries); err != nil {
+				return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+			}
+			renamed += changed
+			written = append(written, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if renamed > 0 && r.dir != nil {
+		r.dir.Changed(written, "rename %s to %s in record", oldName, newName)
+	}
+	return renamed, nil
+}
+
+// archiveDir returns where archived record entries are kept,
+// {designDir}/state/archive.
+func (r *Record) archiveDir() string {
+	return filepath.Join(filepath.Dir(r.path), "archive")
+}
+
+// Archive moves the entries recorded before the given time out of
+// record.json into state/archive/record-YYYY-MM.json, one file per month of
+// the entries' times, merging with any entries already archived for that
+// month. Entries without a time are kept in record.json, since their age
+// is unknown. It returns how many entries were archived.
+func (r *Record) Archive(before time.Time) (int, error) {
+	var archived int
+	err := withFileLock(r.path, func() error {
+		entries, err := r.Entries()
+		if err != nil {
+			return err
+		}
+
+		var kept []RecordEntry
+		byMonth := make(map[string][]RecordEntry)
+		for _, e := range entries {
+			if e.Time.IsZero() || !e.Time.Before(before) {
+				kept = append(kept, e)
+				continue
+			}
+			month := e.Time.UTC().Format("2006-01")
+			byMonth[month] = append(byMonth[month], e)
+			archived++
+		}
+		if archived == 0 {
+			return nil
+		}
+
+		if err := os.MkdirAll(r.archiveDir(), 0o750); err != nil {
+			return fmt.Errorf("creating archive directory: %w", err)
+		}
+		months := make([]string, 0, len(byMonth))
+		for month := range byMonth {
+			months = append(months, month)
+		}
+		sort.Strings(months)
+		for _, month := range months {
+			path := filepath.Join(r.archiveDir(), "record-"+month+".json")
+			existing, err := readRecordFile(path)
+			if err != nil {
+				return err
+			}
+			if err := writeRecordFile(path, append(existing, byMonth[month]...)); err != nil {
+				return fmt.Errorf("writing archive %s: %w", filepath.Base(path), err)
+			}
+		}
+
+		// The archives are written first, so an interruption leaves entries
+		// duplicated rather than lost.
+		if err := writeRecordFile(r.path, kept); err != nil {
+			return fmt.Errorf("writing record: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if archived > 0 && r.dir != nil {
+		r.dir.Changed([]string{r.path, r.archiveDir()}, "archive %d record entries before %s", archived, before.Format(time.DateOnly))
+	}
+	return archived, nil
+}
+
+// ArchivedEntries returns the entries moved out of record.json by Archive,
+// oldest month first.
+func (r *Record) ArchivedEntries() ([]RecordEntry, error) {
+	files, err := filepath.Glob(filepath.Join(r.archiveDir(), "record-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var all []RecordEntry
+	for _, f := range files {
+		entries, err := readRecordFile(f)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+	return all, nil
+}
+
+// readRecordFile reads a JSON list of record entries. A missing file has no
+// entries.
+func readRecordFile(path string) ([]RecordEntry, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is inside our own design dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+
+	var entries []RecordEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	return entries, nil
+}
+
+// writeRecordFile atomically writes entries as a JSON list.
+func writeRecordFile(path string, entries []RecordEntry) error {
+	if entries == nil {
+		entries = []RecordEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling record: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
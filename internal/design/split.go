@@ -0,0 +1,72 @@
+package design
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SplitPart is one of the smaller tasks a task is split into.
+type SplitPart struct {
+	Name    string
+	Content string
+}
+
+// SplitTask replaces an ungrouped pending task with a group of the same
+// name: the original task's content becomes tasks/{name}/group.md, shared
+// context for every part, and each part is written to
+// tasks/{name}/{part}.md. The original task file is removed.
+func (d *Dir) SplitTask(task *Task, parts []SplitPart) error {
+	if task.State != StatePending {
+		return fmt.Errorf("task %s is %s; only pending tasks can be split", task.Label(), task.State)
+	}
+	if task.Group != "" {
+		return fmt.Errorf("task %s is already in group %s", task.Label(), task.Group)
+	}
+	if len(parts) < 2 {
+		return errors.New("a split needs at least two tasks")
+	}
+
+	seen := make(map[string]bool, len(parts))
+	for _, p := range parts {
+		if p.Name == "" || strings.Contains(p.Name, "/") || p.Name == "group" {
+			return fmt.Errorf("invalid task name %q", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("task %q appears twice in the split", p.Name)
+		}
+		seen[p.Name] = true
+		if strings.TrimSpace(p.Content) == "" {
+			return fmt.Errorf("task %q is empty", p.Name)
+		}
+	}
+
+	groupDir := filepath.Join(d.Path, "tasks", task.Name)
+	if _, err := os.Stat(groupDir); err == nil {
+		return fmt.Errorf("group directory %s already exists", d.rel(groupDir))
+	}
+
+	content, err := task.Content()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(groupDir, 0o750); err != nil {
+		return fmt.Errorf("creating group directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(groupDir, "group.md"), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("writing group.md: %w", err)
+	}
+	for _, p := range parts {
+		if err := os.WriteFile(filepath.Join(groupDir, p.Name+".md"), []byte(p.Content), 0o600); err != nil {
+			return fmt.Errorf("writing task %s/%s: %w", task.Name, p.Name, err)
+		}
+	}
+	if err := os.Remove(task.FilePath); err != nil {
+		return fmt.Errorf("removing task %s: %w", task.Name, err)
+	}
+
+	d.Changed([]string{groupDir, task.FilePath}, "split %s into %d tasks", task.Name, len(parts))
+	return nil
+}
@@ -0,0 +1,51 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/erikh/hydra/internal/platform"
+)
+
+// withFileLock runs fn while holding an exclusive advisory lock on the
+// directory holding path, so read-modify-write cycles on the files in it
+// from concurrent hydra processes, such as parallel runs or a group merge,
+// never interleave. The directory is created if missing.
+func withFileLock(path string, fn func() error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", dir, err)
+	}
+	unlock, err := platform.LockDir(dir)
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", dir, err)
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so readers never see a partly written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package design
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DidYouMean returns a hint naming the candidate closest to name, such as
+// ` (did you mean "backend/add-api"?)`, or "" if none is close enough to
+// be a likely typo. A grouped candidate also matches by its bare name.
+func DidYouMean(name string, candidates []string) string {
+	name = strings.ToLower(name)
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		lower := strings.ToLower(c)
+		dist := editDistance(name, lower)
+		if _, bare, ok := strings.Cut(lower, "/"); ok {
+			dist = min(dist, editDistance(name, bare))
+		}
+		if best == "" || dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	if best == "" || bestDist > max(1, len(name)/3) {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// taskNotFound returns the error for a task name that matches none of
+// tasks, which are the tasks in where, suggesting the closest one.
+func taskNotFound(name, where string, tasks []Task) error {
+	labels := make([]string, len(tasks))
+	for i := range tasks {
+		labels[i] = tasks[i].Label()
+	}
+	return fmt.Errorf("task %q not found in %s%s", name, where, DidYouMean(name, labels))
+}
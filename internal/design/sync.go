@@ -0,0 +1,130 @@
+package design
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// syncMessage is the commit message for design changes committed by Push
+// and Pull.
+const syncMessage = "hydra: sync design directory"
+
+// Push commits any uncommitted changes in the design directory and pushes
+// the branch holding it to remote, or to the branch's upstream if remote is
+// empty. It reports whether there were changes to commit.
+func (d *Dir) Push(remote string) (bool, error) {
+	designRepo := &repo.Repo{Dir: d.Path}
+	committed, err := designRepo.CommitDir(syncMessage)
+	if err != nil {
+		return false, fmt.Errorf("committing design changes: %w", err)
+	}
+	if err := designRepo.PushCurrent(remote); err != nil {
+		return committed, fmt.Errorf("pushing design directory: %w", err)
+	}
+	return committed, nil
+}
+
+// Pull commits any uncommitted changes in the design directory, then
+// fetches the branch holding it from remote, or from the branch's upstream
+// if remote is empty, and rebases local commits onto it. Conflicting
+// additions to state/record.json, which every machine appends to, are
+// resolved by keeping the entries of both sides. Any other conflict aborts
+// the rebase, leaving the design directory as it was, and is returned as
+// an error naming the files.
+func (d *Dir) Pull(remote string) error {
+	designRepo := &repo.Repo{Dir: d.Path}
+	if _, err := designRepo.CommitDir(syncMessage); err != nil {
+		return fmt.Errorf("committing design changes: %w", err)
+	}
+	prefix, err := designRepo.Prefix()
+	if err != nil {
+		return fmt.Errorf("locating design directory: %w", err)
+	}
+	recordPath := prefix + filepath.ToSlash(filepath.Join("state", "record.json"))
+
+	pullErr := designRepo.PullRebase(remote)
+	for pullErr != nil {
+		conflicted, err := designRepo.HasConflicts()
+		if err != nil || !conflicted {
+			return fmt.Errorf("pulling design directory: %w", pullErr)
+		}
+		files, err := designRepo.ConflictFiles()
+		if err != nil {
+			_ = designRepo.RebaseAbort()
+			return fmt.Errorf("listing conflicts: %w", err)
+		}
+		var others []string
+		for _, f := range files {
+			if f != recordPath {
+				others = append(others, f)
+			}
+		}
+		if len(others) > 0 {
+			_ = designRepo.RebaseAbort()
+			return fmt.Errorf("design changes conflict with the remote in %s; resolve them with git and pull again",
+				strings.Join(others, ", "))
+		}
+
+		if err := mergeRecordConflict(designRepo, recordPath, filepath.Join(d.Path, "state", "record.json")); err != nil {
+			_ = designRepo.RebaseAbort()
+			return err
+		}
+		pullErr = designRepo.RebaseContinue()
+	}
+	return nil
+}
+
+// mergeRecordConflict resolves a conflict in record.json by writing the
+// entries of both sides to path and staging it.
+func mergeRecordConflict(designRepo *repo.Repo, recordPath, path string) error {
+	var sides [][]RecordEntry
+	for _, stage := range []int{2, 3} {
+		data, ok := designRepo.ConflictVersion(recordPath, stage)
+		if !ok {
+			continue
+		}
+		var entries []RecordEntry
+		if err := json.Unmarshal([]byte(data), &entries); err != nil {
+			return fmt.Errorf("parsing conflicting record.json: %w", err)
+		}
+		sides = append(sides, entries)
+	}
+	if len(sides) == 0 {
+		return errors.New("record.json conflicts but neither side has it")
+	}
+
+	if err := writeRecordFile(path, mergeRecordEntries(sides...)); err != nil {
+		return fmt.Errorf("writing merged record: %w", err)
+	}
+	if err := designRepo.Stage(recordPath); err != nil {
+		return fmt.Errorf("staging merged record: %w", err)
+	}
+	return nil
+}
+
+// mergeRecordEntries combines lists of record entries, dropping entries that
+// appear in more than one, and orders the result by time. Entries without a
+// time keep their place ahead of the rest.
+func mergeRecordEntries(lists ...[]RecordEntry) []RecordEntry {
+	type key struct{ sha, task, time string }
+	seen := make(map[key]bool)
+	var merged []RecordEntry
+	for _, list := range lists {
+		for _, e := range list {
+			k := key{e.SHA, e.TaskName, e.Time.String()}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			merged = append(merged, e)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	return merged
+}
@@ -0,0 +1,172 @@
+package design
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", append([]string{"-C", dir}, args...)...) //nolint:gosec // test with controlled args
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cloneDesign clones remote and configures a committer, returning the
+// design directory, which lives in the repository's design/ subdirectory.
+func cloneDesign(t *testing.T, remote string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "clone")
+	git(t, ".", "clone", "-q", remote, dir)
+	git(t, dir, "config", "user.email", "test@test.com")
+	git(t, dir, "config", "user.name", "Test")
+	git(t, dir, "config", "commit.gpgsign", "false")
+	return filepath.Join(dir, "design")
+}
+
+func TestPushPullMergesRecord(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	git(t, ".", "init", "-q", "--bare", "-b", "main", remote)
+
+	seed := filepath.Join(t.TempDir(), "seed")
+	must(t, os.MkdirAll(seed, 0o750))
+	git(t, seed, "init", "-q", "-b", "main")
+	git(t, seed, "config", "user.email", "test@test.com")
+	git(t, seed, "config", "user.name", "Test")
+	git(t, seed, "config", "commit.gpgsign", "false")
+	must(t, os.MkdirAll(filepath.Join(seed, "design", "tasks"), 0o750))
+	must(t, os.WriteFile(filepath.Join(seed, "design", "tasks", "add-auth.md"), []byte("Add authentication."), 0o600))
+	must(t, os.WriteFile(filepath.Join(seed, "design", "tasks", "fix-bug.md"), []byte("Fix the bug."), 0o600))
+	must(t, os.WriteFile(filepath.Join(seed, "README.md"), []byte("Code.\n"), 0o600))
+	must(t, NewRecord(filepath.Join(seed, "design")).AddEntry(RecordEntry{SHA: "aaa", TaskName: "seed", Time: time.Unix(100, 0).UTC()}))
+	git(t, seed, "add", "-A")
+	git(t, seed, "commit", "-q", "-m", "initial")
+	git(t, seed, "push", "-q", remote, "main")
+
+	desktop, err := NewDir(cloneDesign(t, remote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewDir(cloneDesign(t, remote))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both machines record a run; the desktop also moves a task.
+	must(t, desktop.Record().AddEntry(RecordEntry{SHA: "bbb", TaskName: "add-auth", Time: time.Unix(200, 0).UTC()}))
+	task, err := desktop.FindTask("add-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must(t, desktop.MoveTask(task, StateReview))
+	must(t, server.Record().AddEntry(RecordEntry{SHA: "ccc", TaskName: "fix-bug", Time: time.Unix(300, 0).UTC()}))
+	// Uncommitted work outside the design directory survives a pull.
+	serverRoot := filepath.Dir(server.Path)
+	must(t, os.WriteFile(filepath.Join(serverRoot, "README.md"), []byte("Edited.\n"), 0o600))
+
+	committed, err := desktop.Push("")
+	if err != nil {
+		t.Fatalf("desktop push: %v", err)
+	}
+	if !committed {
+		t.Error("desktop push should have committed its changes")
+	}
+	if err := server.Pull("origin"); err != nil {
+		t.Fatalf("server pull: %v", err)
+	}
+
+	entries, err := server.Record().Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shas []string
+	for _, e := range entries {
+		shas = append(shas, e.SHA)
+	}
+	if got := strings.Join(shas, ","); got != "aaa,bbb,ccc" {
+		t.Errorf("merged record = %s, want aaa,bbb,ccc", got)
+	}
+	if _, err := os.Stat(filepath.Join(server.Path, "state", "review", "add-auth.md")); err != nil {
+		t.Errorf("task moved on the desktop should be in review after pull: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(serverRoot, "README.md")); string(data) != "Edited.\n" {
+		t.Errorf("README.md = %q, want the uncommitted edit kept", data)
+	}
+	if status := git(t, serverRoot, "status", "--porcelain", "--", "design"); status != "" {
+		t.Errorf("design dir should be clean after pull, got %q", status)
+	}
+
+	if _, err := server.Push("origin"); err != nil {
+		t.Fatalf("server push: %v", err)
+	}
+	if err := desktop.Pull(""); err != nil {
+		t.Fatalf("desktop pull: %v", err)
+	}
+	if entries, _ := desktop.Record().Entries(); len(entries) != 3 {
+		t.Errorf("desktop has %d record entries after pull, want 3", len(entries))
+	}
+}
+
+func TestPullAbortsOnTaskConflict(t *testing.T) {
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	git(t, ".", "init", "-q", "--bare", "-b", "main", remote)
+
+	seed := filepath.Join(t.TempDir(), "seed")
+	must(t, os.MkdirAll(filepath.Join(seed, "design", "tasks"), 0o750))
+	git(t, seed, "init", "-q", "-b", "main")
+	git(t, seed, "config", "user.email", "test@test.com")
+	git(t, seed, "config", "user.name", "Test")
+	git(t, seed, "config", "commit.gpgsign", "false")
+	must(t, os.WriteFile(filepath.Join(seed, "design", "tasks", "add-auth.md"), []byte("Add authentication."), 0o600))
+	git(t, seed, "add", "-A")
+	git(t, seed, "commit", "-q", "-m", "initial")
+	git(t, seed, "push", "-q", remote, "main")
+
+	a, b := cloneDesign(t, remote), cloneDesign(t, remote)
+	taskA, taskB := filepath.Join(a, "tasks", "add-auth.md"), filepath.Join(b, "tasks", "add-auth.md")
+	must(t, os.WriteFile(taskA, []byte("Add OAuth."), 0o600))
+	must(t, os.WriteFile(taskB, []byte("Add passwords."), 0o600))
+
+	ddA, err := NewDir(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddB, err := NewDir(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ddA.Push(""); err != nil {
+		t.Fatal(err)
+	}
+	err = ddB.Pull("")
+	if err == nil || !strings.Contains(err.Error(), "design/tasks/add-auth.md") {
+		t.Fatalf("pull error = %v, want a conflict naming the task", err)
+	}
+	if data, _ := os.ReadFile(taskB); string(data) != "Add passwords." {
+		t.Errorf("task = %q, want the local edit kept after the aborted pull", data)
+	}
+}
+
+func TestMergeRecordEntries(t *testing.T) {
+	shared := RecordEntry{SHA: "aaa", TaskName: "a", Time: time.Unix(100, 0).UTC()}
+	legacy := RecordEntry{SHA: "000", TaskName: "old"}
+	got := mergeRecordEntries(
+		[]RecordEntry{legacy, shared, {SHA: "ccc", TaskName: "c", Time: time.Unix(300, 0).UTC()}},
+		[]RecordEntry{legacy, shared, {SHA: "bbb", TaskName: "b", Time: time.Unix(200, 0).UTC()}},
+	)
+	var shas []string
+	for _, e := range got {
+		shas = append(shas, e.SHA)
+	}
+	if strings.Join(shas, ",") != "000,aaa,bbb,ccc" {
+		t.Errorf("merged = %v, want 000,aaa,bbb,ccc", shas)
+	}
+}
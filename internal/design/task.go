@@ -1,6 +1,7 @@
 package design
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -31,13 +32,15 @@ type Task struct {
 	State    TaskState
 }
 
-// Content reads and returns the task's markdown content.
+// Content reads and returns the task's markdown content, without any front
+// matter.
 func (t *Task) Content() (string, error) {
 	data, err := os.ReadFile(t.FilePath)
 	if err != nil {
 		return "", fmt.Errorf("reading task %s: %w", t.Name, err)
 	}
-	return string(data), nil
+	_, body, _ := splitFrontmatter(string(data))
+	return body, nil
 }
 
 // BranchName returns the normalized git branch name for this task.
@@ -79,7 +82,7 @@ func (d *Dir) discoverTasks(dir string, group string, state TaskState) ([]Task,
 			continue
 		}
 
-		if entry.Name() == "group.md" {
+		if entry.Name() == "group.md" || strings.HasSuffix(entry.Name(), commentsSuffix) {
 			continue
 		}
 
@@ -141,7 +144,7 @@ func (d *Dir) FindTask(name string) (*Task, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("task %q not found in pending tasks", name)
+	return nil, taskNotFound(name, "pending tasks", tasks)
 }
 
 // FindTaskByState looks up a task by name in the given state.
@@ -160,7 +163,7 @@ func (d *Dir) FindTaskByState(name string, state TaskState) (*Task, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("task %q not found in %s state", name, state)
+	return nil, taskNotFound(name, string(state)+" state", tasks)
 }
 
 // FindTaskAny looks up a task by name across all states.
@@ -179,13 +182,16 @@ func (d *Dir) FindTaskAny(name string) (*Task, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("task %q not found in any state", name)
+	return nil, taskNotFound(name, "any state", tasks)
 }
 
-// MoveTask moves a task file to the given state directory.
+// MoveTask moves a task file to the given state directory. Moving a task to
+// pending puts it back under tasks/, in its group.
 func (d *Dir) MoveTask(task *Task, newState TaskState) error {
 	var destDir string
 	switch newState {
+	case StatePending:
+		destDir = filepath.Join(d.Path, "tasks")
 	case StateReview, StateMerge, StateCompleted, StateAbandoned:
 		destDir = filepath.Join(d.Path, "state", string(newState))
 	default:
@@ -206,12 +212,125 @@ func (d *Dir) MoveTask(task *Task, newState TaskState) error {
 		return fmt.Errorf("moving task file: %w", err)
 	}
 
+	// Reviewer comments travel with the task.
+	paths := []string{task.FilePath, destPath}
+	comments := task.CommentsPath()
+	if _, err := os.Stat(comments); err == nil {
+		destComments := filepath.Join(destDir, filepath.Base(comments))
+		if err := os.Rename(comments, destComments); err != nil {
+			return fmt.Errorf("moving task comments: %w", err)
+		}
+		paths = append(paths, comments, destComments)
+	}
+
 	task.FilePath = destPath
 	task.State = newState
+	d.Changed(paths, "move %s to %s", task.Label(), newState)
 	return nil
 }
 
-// DeleteTask removes a task file from disk.
+// CreateTask writes a new pending task, tasks/{name}.md, with the given
+// content. It fails if the content is blank or a task of that name exists
+// in any state.
+func (d *Dir) CreateTask(name, content string) (*Task, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid task name %q", name)
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("empty task file, aborting")
+	}
+	if existing, err := d.FindTaskAny(name); err == nil {
+		return nil, fmt.Errorf("task %q already exists in %s state", name, existing.State)
+	}
+
+	tasksDir := filepath.Join(d.Path, "tasks")
+	if err := os.MkdirAll(tasksDir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating tasks directory: %w", err)
+	}
+	task := &Task{Name: name, FilePath: filepath.Join(tasksDir, name+".md"), State: StatePending}
+	if err := os.WriteFile(task.FilePath, []byte(content), 0o600); err != nil {
+		return nil, fmt.Errorf("writing task %s: %w", name, err)
+	}
+	d.Changed([]string{task.FilePath}, "add task %s", name)
+	return task, nil
+}
+
+// RenameTask renames a task file, and any reviewer comments, in its state
+// directory and group. It fails if the name is invalid or a task of that
+// name exists in any state.
+func (d *Dir) RenameTask(task *Task, newName string) error {
+	if newName == "" || strings.Contains(newName, "/") {
+		return fmt.Errorf("invalid task name %q", newName)
+	}
+	renamed := Task{Name: newName, Group: task.Group}
+	if existing, err := d.FindTaskAny(renamed.Label()); err == nil {
+		return fmt.Errorf("task %q already exists in %s state", renamed.Label(), existing.State)
+	}
+
+	oldLabel := task.Label()
+	comments := task.CommentsPath()
+	newPath := filepath.Join(filepath.Dir(task.FilePath), newName+".md")
+	if err := os.Rename(task.FilePath, newPath); err != nil {
+		return fmt.Errorf("renaming task file: %w", err)
+	}
+	paths := []string{task.FilePath, newPath}
+	task.Name = newName
+	task.FilePath = newPath
+	if _, err := os.Stat(comments); err == nil {
+		if err := os.Rename(comments, task.CommentsPath()); err != nil {
+			return fmt.Errorf("renaming task comments: %w", err)
+		}
+		paths = append(paths, comments, task.CommentsPath())
+	}
+	d.Changed(paths, "rename %s to %s", oldLabel, task.Label())
+	return nil
+}
+
+// DeleteTask removes a task file, and any reviewer comments, from disk.
 func (d *Dir) DeleteTask(task *Task) error {
-	return os.Remove(task.FilePath)
+	if err := removeTask(task); err != nil {
+		return err
+	}
+	d.Changed([]string{task.FilePath, task.CommentsPath()}, "delete %s copy of %s", task.State, task.Label())
+	return nil
+}
+
+// DeleteTasks removes tasks and their reviewer comments from disk and
+// reports them as a single change, together with written, other paths the
+// change wrote, so a batch of work lands in one commit. Tasks deleted
+// before a failure are still reported.
+func (d *Dir) DeleteTasks(tasks []Task, written []string, format string, args ...any) error {
+	paths := written
+	var err error
+	for i := range tasks {
+		if err = removeTask(&tasks[i]); err != nil {
+			err = fmt.Errorf("deleting task %s: %w", tasks[i].Label(), err)
+			break
+		}
+		paths = append(paths, tasks[i].FilePath, tasks[i].CommentsPath())
+	}
+	if len(paths) > 0 {
+		d.Changed(paths, format, args...)
+	}
+	return err
+}
+
+// removeTask removes a task file and any reviewer comments.
+func removeTask(task *Task) error {
+	if err := os.Remove(task.FilePath); err != nil {
+		return err
+	}
+	if err := os.Remove(task.CommentsPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Label returns the task's name as given on the command line: group/name
+// for grouped tasks.
+func (t *Task) Label() string {
+	if t.Group != "" {
+		return t.Group + "/" + t.Name
+	}
+	return t.Name
 }
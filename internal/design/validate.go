@@ -0,0 +1,200 @@
+package design
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Problem is an issue found in the design directory by Validate.
+type Problem struct {
+	Kind    string // stable problem type, e.g. empty-task
+	Path    string // file the problem is in, relative to the design directory
+	Message string
+	Fix     string // suggested fix; empty if there is no obvious one
+}
+
+// Validate checks the design directory for problems hydra would otherwise
+// trip over later: group.md files with no tasks, tasks with empty bodies or
+// invalid front matter, task names that collide on the same branch, a
+// malformed state/record.json, and milestones without promises. Problems
+// are sorted by kind, then path.
+func (d *Dir) Validate() ([]Problem, error) {
+	tasks, err := d.AllTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, t := range tasks {
+		problems = append(problems, d.validateTask(t)...)
+	}
+	problems = append(problems, d.duplicateBranches(tasks)...)
+
+	orphans, err := d.orphanGroups(tasks)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, orphans...)
+
+	if _, err := d.Record().Entries(); err != nil {
+		problems = append(problems, Problem{
+			Kind:    "malformed-record",
+			Path:    filepath.Join("state", "record.json"),
+			Message: err.Error(),
+			Fix:     "restore the file from version control, or correct the JSON by hand",
+		})
+	}
+
+	milestones, err := d.Milestones()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range milestones {
+		content, err := m.Content()
+		if err != nil {
+			return nil, err
+		}
+		if len(ParsePromises(content)) == 0 {
+			problems = append(problems, Problem{
+				Kind:    "milestone-no-promises",
+				Path:    d.rel(m.FilePath),
+				Message: fmt.Sprintf("milestone %s has no promises", m.Date),
+				Fix:     "add a \"## \" heading for each promise the milestone makes",
+			})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		return problems[i].Path < problems[j].Path
+	})
+	return problems, nil
+}
+
+// validateTask checks a task file's front matter and body.
+func (d *Dir) validateTask(t Task) []Problem {
+	path := d.rel(t.FilePath)
+	data, err := os.ReadFile(t.FilePath)
+	if err != nil {
+		return []Problem{{Kind: "unreadable-task", Path: path, Message: err.Error()}}
+	}
+
+	var problems []Problem
+	content := string(data)
+	if strings.HasPrefix(content, frontmatterDelim+"\n") {
+		if _, _, ok := splitFrontmatter(content); !ok {
+			problems = append(problems, Problem{
+				Kind:    "invalid-frontmatter",
+				Path:    path,
+				Message: "front matter is never closed, so it is read as part of the task",
+				Fix:     "add a \"---\" line after the front matter",
+			})
+		} else if meta, err := t.Frontmatter(); err != nil {
+			problems = append(problems, Problem{
+				Kind:    "invalid-frontmatter",
+				Path:    path,
+				Message: err.Error(),
+				Fix:     "front matter holds plain \"key: value\" pairs; fix or remove the block",
+			})
+		} else if v := meta[expiresKey]; v != "" {
+			if _, err := time.Parse(time.DateOnly, v); err != nil {
+				problems = append(problems, Problem{
+					Kind:    "invalid-frontmatter",
+					Path:    path,
+					Message: fmt.Sprintf("expires: %q is not a date, so the task never expires", v),
+					Fix:     "write the date as YYYY-MM-DD",
+				})
+			}
+		}
+	}
+
+	if body, err := t.Content(); err == nil && strings.TrimSpace(body) == "" {
+		problems = append(problems, Problem{
+			Kind:    "empty-task",
+			Path:    path,
+			Message: fmt.Sprintf("task %s has no content", t.Label()),
+			Fix:     "describe the task, or delete the file",
+		})
+	}
+	return problems
+}
+
+// duplicateBranches reports tasks that share a branch name, whether they are
+// copies of one task in different states or differently named tasks that
+// normalize to the same branch.
+func (d *Dir) duplicateBranches(tasks []Task) []Problem {
+	byBranch := make(map[string][]Task)
+	for _, t := range tasks {
+		byBranch[t.BranchName()] = append(byBranch[t.BranchName()], t)
+	}
+
+	var problems []Problem
+	for branch, dupes := range byBranch {
+		if len(dupes) < 2 {
+			continue
+		}
+		where := make([]string, len(dupes))
+		sameName := true
+		for i, t := range dupes {
+			where[i] = d.rel(t.FilePath)
+			sameName = sameName && t.Label() == dupes[0].Label()
+		}
+		sort.Strings(where)
+
+		fix := "rename all but one of the tasks"
+		if sameName {
+			fix = "run hydra fix to choose which copy to keep"
+		}
+		problems = append(problems, Problem{
+			Kind:    "duplicate-slug",
+			Path:    where[0],
+			Message: fmt.Sprintf("%d tasks share branch %s: %s", len(dupes), branch, strings.Join(where, ", ")),
+			Fix:     fix,
+		})
+	}
+	return problems
+}
+
+// orphanGroups reports tasks/{group}/group.md files for groups with no
+// tasks in any state.
+func (d *Dir) orphanGroups(tasks []Task) ([]Problem, error) {
+	groups := make(map[string]bool)
+	for _, t := range tasks {
+		groups[t.Group] = true
+	}
+
+	matches, err := filepath.Glob(filepath.Join(d.Path, "tasks", "*", "group.md"))
+	if err != nil {
+		return nil, fmt.Errorf("finding group files: %w", err)
+	}
+	var problems []Problem
+	for _, path := range matches {
+		group := filepath.Base(filepath.Dir(path))
+		if groups[group] {
+			continue
+		}
+		problems = append(problems, Problem{
+			Kind:    "orphan-group",
+			Path:    d.rel(path),
+			Message: fmt.Sprintf("group %s has a group.md but no tasks", group),
+			Fix:     "add tasks to the group, or delete " + d.rel(path),
+		})
+	}
+	return problems, nil
+}
+
+// rel returns path relative to the design directory, or path itself if it
+// is outside it.
+func (d *Dir) rel(path string) string {
+	rel, err := filepath.Rel(d.Path, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,143 @@
+package design
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VerifyReport is the outcome of the last functional verification saved with
+// hydra verify --report, kept in state/verify.json.
+type VerifyReport struct {
+	Time     time.Time `json:"time"`
+	SHA      string    `json:"sha"`             // commit of the default branch that was verified
+	Since    string    `json:"since,omitempty"` // hydra verify --since of a verification limited to requirements touched since then
+	Passed   bool      `json:"passed"`
+	Failures []string  `json:"failures,omitempty"` // failed requirements, one per entry
+	Unlisted bool      `json:"unlisted,omitempty"` // the failures were not written as a list, so an entry may cover several requirements
+}
+
+// verifyReportPath returns where the verify report is kept.
+func (d *Dir) verifyReportPath() string {
+	return filepath.Join(d.Path, "state", "verify.json")
+}
+
+// VerifyReport returns the last saved verify report, or nil if there is
+// none.
+func (d *Dir) VerifyReport() (*VerifyReport, error) {
+	data, err := os.ReadFile(d.verifyReportPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading verify report: %w", err)
+	}
+	var report VerifyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing verify report: %w", err)
+	}
+	return &report, nil
+}
+
+// SaveVerifyReport replaces the saved verify report.
+func (d *Dir) SaveVerifyReport(report VerifyReport) error {
+	path := d.verifyReportPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling verify report: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing verify report: %w", err)
+	}
+	outcome := "failed"
+	if report.Passed {
+		outcome = "passed"
+	}
+	d.Changed([]string{path}, "record verify %s at %s", outcome, shortSHA(report.SHA))
+	return nil
+}
+
+// ParseVerifyFailures splits the failures Claude wrote to verify-failed.txt
+// into one entry per failed requirement. Each list item starts an entry and
+// the lines under it are folded in; without list items, each paragraph is
+// an entry and listed is false.
+func ParseVerifyFailures(text string) (failures []string, listed bool) {
+	var cur []string
+	flush := func() {
+		if len(cur) > 0 {
+			failures = append(failures, strings.Join(cur, " "))
+			cur = nil
+		}
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if item, ok := listItem(trimmed); ok && line == strings.TrimLeft(line, " \t") {
+			flush()
+			trimmed = item
+			listed = true
+		}
+		cur = append(cur, trimmed)
+	}
+	flush()
+	return failures, listed
+}
+
+// listItem strips a top-level "- ", "* ", or "1. " marker from line.
+func listItem(line string) (string, bool) {
+	for _, marker := range []string{"- ", "* "} {
+		if item, ok := strings.CutPrefix(line, marker); ok {
+			return item, true
+		}
+	}
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	if digits > 0 {
+		if item, ok := strings.CutPrefix(line[digits:], ". "); ok {
+			return item, true
+		}
+	}
+	return "", false
+}
+
+// unlistedFailure stands in for the failures of a failed report that lists
+// none.
+const unlistedFailure = "verification failed without listing the failed requirements"
+
+// PromiseFailures returns the failures in the report that concern one of
+// the promises: those that name a promise's heading, ignoring case and
+// punctuation. A failed report that can't tell which requirements failed,
+// because it lists no failures or they weren't written as a list, can't
+// show that any promise was kept, so all its failures are returned.
+func (r *VerifyReport) PromiseFailures(promises []Promise) []string {
+	if !r.Passed && len(promises) > 0 {
+		if len(r.Failures) == 0 {
+			return []string{unlistedFailure}
+		}
+		if r.Unlisted {
+			return r.Failures
+		}
+	}
+	words := func(s string) string {
+		return "-" + strings.Trim(nonAlphaNumRe.ReplaceAllString(strings.ToLower(s), "-"), "-") + "-"
+	}
+	var matched []string
+	for _, f := range r.Failures {
+		failure := words(f)
+		for _, p := range promises {
+			if heading := words(p.Heading); heading != "--" && strings.Contains(failure, heading) {
+				matched = append(matched, f)
+				break
+			}
+		}
+	}
+	return matched
+}
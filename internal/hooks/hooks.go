@@ -0,0 +1,128 @@
+// Package hooks installs git hooks that keep developer clones from silently
+// diverging from hydra-managed branches.
+package hooks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+// guardMarker identifies hooks written by hydra so they can be safely replaced.
+const guardMarker = "# hydra remote-guard"
+
+// AllowEnv is the environment variable that bypasses the remote guard.
+const AllowEnv = "HYDRA_ALLOW_PUSH"
+
+// Violation describes a push to a hydra branch whose task is still active.
+type Violation struct {
+	Branch string
+	State  string
+}
+
+// PrePushScript returns the contents of a pre-push hook that delegates to
+// "hydra hooks pre-push" for the hydra project rooted at projectDir.
+func PrePushScript(hydraBin, projectDir string, block bool) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString(guardMarker + " (installed by \"hydra hooks install --remote-guard\")\n")
+	fmt.Fprintf(&b, "[ -x %s ] || exit 0\n", shellQuote(hydraBin))
+	fmt.Fprintf(&b, "exec %s hooks pre-push --project %s", shellQuote(hydraBin), shellQuote(projectDir))
+	if block {
+		b.WriteString(" --block")
+	}
+	b.WriteString(" \"$@\"\n")
+	return b.String()
+}
+
+// Install writes script as the named hook in hooksDir. An existing hook that
+// was not written by hydra is only replaced when force is true.
+func Install(hooksDir, name, script string, force bool) (string, error) {
+	if err := os.MkdirAll(hooksDir, 0o750); err != nil {
+		return "", fmt.Errorf("creating hooks dir: %w", err)
+	}
+
+	path := filepath.Join(hooksDir, name)
+	existing, err := os.ReadFile(path) //nolint:gosec // path is the repo's hooks dir
+	if err == nil && !force && !strings.Contains(string(existing), guardMarker) {
+		return "", fmt.Errorf("%s already exists and was not installed by hydra; use --force to replace it", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("reading existing hook: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil { //nolint:gosec // hooks must be executable
+		return "", fmt.Errorf("writing hook: %w", err)
+	}
+	return path, nil
+}
+
+// ActiveBranches returns the hydra branches whose tasks are in an active
+// state, mapped to a description of that state. Tasks in review or merge are
+// active, as are pending tasks currently being run.
+func ActiveBranches(dd *design.Dir, running []lock.RunningTask) (map[string]string, error) {
+	active := make(map[string]string)
+
+	for _, state := range []design.TaskState{design.StateReview, design.StateMerge} {
+		tasks, err := dd.TasksByState(state)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			active[t.BranchName()] = string(state)
+		}
+	}
+
+	pending, err := dd.PendingTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, rt := range running {
+		name := rt.TaskName
+		if _, rest, ok := strings.Cut(name, ":"); ok {
+			name = rest
+		}
+		for _, t := range pending {
+			if t.Name == name || (t.Group != "" && t.Group+"/"+t.Name == name) {
+				active[t.BranchName()] = "running"
+			}
+		}
+	}
+
+	return active, nil
+}
+
+// CheckPush reads pre-push hook input ("<local ref> <local sha> <remote ref>
+// <remote sha>" per line) and reports any pushes to active hydra branches.
+func CheckPush(input io.Reader, active map[string]string) ([]Violation, error) {
+	var violations []Violation
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		branch, ok := strings.CutPrefix(fields[2], "refs/heads/")
+		if !ok || !strings.HasPrefix(branch, "hydra/") {
+			continue
+		}
+		if state, ok := active[branch]; ok {
+			violations = append(violations, Violation{Branch: branch, State: state})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading push refs: %w", err)
+	}
+	return violations, nil
+}
+
+// shellQuote wraps s in single quotes for safe use in a POSIX shell script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setupDesignDir(t *testing.T) *design.Dir {
+	t.Helper()
+	dir := t.TempDir()
+
+	must(t, os.MkdirAll(filepath.Join(dir, "tasks", "backend"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "tasks", "idle.md"), []byte("Idle."), 0o600))
+	must(t, os.WriteFile(filepath.Join(dir, "tasks", "backend", "add-api.md"), []byte("API."), 0o600))
+	must(t, os.MkdirAll(filepath.Join(dir, "state", "review"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "review", "in-review.md"), []byte("R."), 0o600))
+	must(t, os.MkdirAll(filepath.Join(dir, "state", "merge"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "merge", "merging.md"), []byte("M."), 0o600))
+	must(t, os.MkdirAll(filepath.Join(dir, "state", "completed"), 0o750))
+	must(t, os.WriteFile(filepath.Join(dir, "state", "completed", "done.md"), []byte("D."), 0o600))
+
+	dd, err := design.NewDir(dir)
+	must(t, err)
+	return dd
+}
+
+func TestActiveBranches(t *testing.T) {
+	dd := setupDesignDir(t)
+
+	active, err := ActiveBranches(dd, []lock.RunningTask{{TaskName: "backend/add-api", PID: 1}})
+	if err != nil {
+		t.Fatalf("ActiveBranches: %v", err)
+	}
+
+	want := map[string]string{
+		"hydra/in-review":       "review",
+		"hydra/merging":         "merge",
+		"hydra/backend/add-api": "running",
+	}
+	if len(active) != len(want) {
+		t.Errorf("active = %v, want %v", active, want)
+	}
+	for branch, state := range want {
+		if active[branch] != state {
+			t.Errorf("active[%q] = %q, want %q", branch, active[branch], state)
+		}
+	}
+}
+
+func TestCheckPush(t *testing.T) {
+	active := map[string]string{"hydra/in-review": "review"}
+	input := strings.Join([]string{
+		"refs/heads/hydra/in-review abc123 refs/heads/hydra/in-review def456",
+		"refs/heads/hydra/done abc123 refs/heads/hydra/done def456",
+		"refs/heads/main abc123 refs/heads/main def456",
+		"",
+	}, "\n")
+
+	violations, err := CheckPush(strings.NewReader(input), active)
+	if err != nil {
+		t.Fatalf("CheckPush: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("violations = %v, want 1", violations)
+	}
+	if violations[0].Branch != "hydra/in-review" || violations[0].State != "review" {
+		t.Errorf("violation = %+v", violations[0])
+	}
+}
+
+func TestInstall(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+	script := PrePushScript("/usr/bin/hydra", "/home/me/project's", true)
+
+	if !strings.Contains(script, "--block") {
+		t.Error("script should pass --block")
+	}
+	if !strings.Contains(script, `'/home/me/project'\''s'`) {
+		t.Errorf("project dir not quoted correctly:\n%s", script)
+	}
+
+	path, err := Install(hooksDir, "pre-push", script, false)
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Error("hook is not executable")
+	}
+
+	// Reinstalling over our own hook is allowed.
+	if _, err := Install(hooksDir, "pre-push", script, false); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+
+	// A foreign hook is preserved unless forced.
+	must(t, os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0o700)) //nolint:gosec // test hook
+	if _, err := Install(hooksDir, "pre-push", script, false); err == nil {
+		t.Error("expected error overwriting foreign hook")
+	}
+	if _, err := Install(hooksDir, "pre-push", script, true); err != nil {
+		t.Fatalf("forced install: %v", err)
+	}
+}
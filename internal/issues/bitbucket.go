@@ -0,0 +1,422 @@
+package issues
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/credstore"
+)
+
+// bitbucketAPI is the base URL of the Bitbucket Cloud REST API.
+const bitbucketAPI = "https://api.bitbucket.org/2.0"
+
+// bitbucketPageSize is the number of items requested per page, the most
+// Bitbucket allows for issues and pull requests.
+const bitbucketPageSize = 50
+
+// bitbucketMaxPages bounds pagination so a misbehaving server cannot loop forever.
+const bitbucketMaxPages = 20
+
+// BitbucketSource fetches issues and pull requests from Bitbucket Cloud.
+// Bitbucket issues have no labels; an issue's kind (bug, enhancement,
+// proposal, or task) and component stand in for them.
+type BitbucketSource struct {
+	BaseURL   string // API base URL; bitbucket.org's by default
+	Workspace string
+	Repo      string
+	// Token is from BITBUCKET_TOKEN or the credential store. A
+	// "user:app-password" pair is sent with basic auth and anything else
+	// as a bearer access token.
+	Token string
+}
+
+// NewBitbucketSource creates a BitbucketSource for a repository on
+// bitbucket.org. An empty token is looked up in the credential store.
+func NewBitbucketSource(workspace, repo, token string) *BitbucketSource {
+	if token == "" {
+		token = credstore.Lookup(credstore.ProviderBitbucket)
+	}
+	return &BitbucketSource{
+		BaseURL:   bitbucketAPI,
+		Workspace: workspace,
+		Repo:      repo,
+		Token:     token,
+	}
+}
+
+// bitbucketPage is a page of a paginated Bitbucket response.
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// bitbucketError is the body of a Bitbucket API error response.
+type bitbucketError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// bitbucketLinks holds the web link of an issue or pull request.
+type bitbucketLinks struct {
+	HTML struct {
+		Href string `json:"href"`
+	} `json:"html"`
+}
+
+type bitbucketIssue struct {
+	ID      int    `json:"id"`
+	Title   string `json:"title"`
+	Kind    string `json:"kind"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Component *struct {
+		Name string `json:"name"`
+	} `json:"component"`
+	Milestone *struct {
+		Name string `json:"name"`
+	} `json:"milestone"`
+	Links bitbucketLinks `json:"links"`
+}
+
+// FetchOpenIssues retrieves the new and open issues from Bitbucket,
+// following pagination. With labels, only issues whose kind or component
+// matches every label are returned.
+func (b *BitbucketSource) FetchOpenIssues(ctx context.Context, labels []string) ([]Issue, error) {
+	query := url.QueryEscape(`state="new" OR state="open"`)
+	next := fmt.Sprintf("%s/issues?q=%s&sort=id&pagelen=%d", b.repoURL(), query, bitbucketPageSize)
+
+	var result []Issue
+	for page := 0; next != "" && page < bitbucketMaxPages; page++ {
+		var bbIssues bitbucketPage[bitbucketIssue]
+		if err := b.get(ctx, next, &bbIssues); err != nil {
+			return nil, err
+		}
+		for _, bi := range bbIssues.Values {
+			issue := bi.issue()
+			if hasLabels(issue.Labels, labels) {
+				result = append(result, issue)
+			}
+		}
+		next = bbIssues.Next
+	}
+	return result, nil
+}
+
+// FetchIssue retrieves a single issue from Bitbucket.
+func (b *BitbucketSource) FetchIssue(ctx context.Context, number int) (*Issue, error) {
+	var bi bitbucketIssue
+	if err := b.get(ctx, fmt.Sprintf("%s/issues/%d", b.repoURL(), number), &bi); err != nil {
+		return nil, err
+	}
+	issue := bi.issue()
+	return &issue, nil
+}
+
+// issue converts an API issue.
+func (bi bitbucketIssue) issue() Issue {
+	var labels []string
+	if bi.Kind != "" {
+		labels = append(labels, bi.Kind)
+	}
+	if bi.Component != nil && bi.Component.Name != "" {
+		labels = append(labels, bi.Component.Name)
+	}
+	issue := Issue{
+		Number: bi.ID,
+		Title:  bi.Title,
+		Body:   bi.Content.Raw,
+		Labels: labels,
+		URL:    bi.Links.HTML.Href,
+	}
+	if bi.Milestone != nil && bi.Milestone.Name != "" {
+		issue.Milestone = &Milestone{Title: bi.Milestone.Name}
+	}
+	return issue
+}
+
+// hasLabels reports whether have includes every one of want, ignoring case.
+func hasLabels(have, want []string) bool {
+	for _, w := range want {
+		if !slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, w) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// CloseIssue resolves a Bitbucket issue with an optional comment.
+func (b *BitbucketSource) CloseIssue(number int, comment string) error {
+	return b.setIssueState(number, "resolved", comment)
+}
+
+// ReopenIssue reopens a resolved Bitbucket issue with an optional comment.
+func (b *BitbucketSource) ReopenIssue(number int, comment string) error {
+	return b.setIssueState(number, "open", comment)
+}
+
+// setIssueState posts comment, if given, on a Bitbucket issue and sets its
+// state.
+func (b *BitbucketSource) setIssueState(number int, state, comment string) error {
+	ctx := context.Background()
+	action := "closing"
+	if state == "open" {
+		action = "reopening"
+	}
+	issueURL := fmt.Sprintf("%s/issues/%d", b.repoURL(), number)
+
+	if comment != "" {
+		body, err := json.Marshal(map[string]any{"content": map[string]string{"raw": comment}})
+		if err != nil {
+			return err
+		}
+		resp, err := b.do(ctx, http.MethodPost, issueURL+"/comments", string(body))
+		if err != nil {
+			return fmt.Errorf("posting comment: %w", err)
+		}
+		if err := decodeBitbucket(resp, nil, http.StatusCreated); err != nil {
+			return fmt.Errorf("posting comment on issue #%d: %w", number, err)
+		}
+	}
+
+	resp, err := b.do(ctx, http.MethodPut, issueURL, fmt.Sprintf(`{"state":%q}`, state))
+	if err != nil {
+		return fmt.Errorf("%s issue: %w", action, err)
+	}
+	if err := decodeBitbucket(resp, nil, http.StatusOK); err != nil {
+		return fmt.Errorf("%s issue #%d: %w", action, number, err)
+	}
+	return nil
+}
+
+// bitbucketUser is the author of a Bitbucket comment.
+type bitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	Type        string `json:"type"` // "app_user" for apps
+}
+
+// bitbucketBranch is one end of a pull request.
+type bitbucketBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type bitbucketPull struct {
+	ID     int             `json:"id"`
+	State  string          `json:"state"` // OPEN, MERGED, DECLINED, or SUPERSEDED
+	Source bitbucketBranch `json:"source"`
+	Links  bitbucketLinks  `json:"links"`
+}
+
+// pull converts an API pull request.
+func (bp bitbucketPull) pull() PullRequest {
+	state := PullClosed
+	switch bp.State {
+	case "OPEN":
+		state = PullOpen
+	case "MERGED":
+		state = PullMerged
+	}
+	return PullRequest{Number: bp.ID, Branch: bp.Source.Branch.Name, State: state, URL: bp.Links.HTML.Href}
+}
+
+// bitbucketComment is a pull request comment; inline comments are on a
+// line of a file.
+type bitbucketComment struct {
+	ID      int64         `json:"id"`
+	User    bitbucketUser `json:"user"`
+	Deleted bool          `json:"deleted"`
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline *struct {
+		Path string `json:"path"`
+		From *int   `json:"from"`
+		To   *int   `json:"to"`
+	} `json:"inline"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+// FetchPullRequests retrieves the most recently updated pull requests, open
+// and closed, from Bitbucket.
+func (b *BitbucketSource) FetchPullRequests(ctx context.Context) ([]PullRequest, error) {
+	next := fmt.Sprintf("%s/pullrequests?state=OPEN&state=MERGED&state=DECLINED&state=SUPERSEDED&sort=-updated_on&pagelen=%d",
+		b.repoURL(), bitbucketPageSize)
+
+	var result []PullRequest
+	for page := 0; next != "" && page < bitbucketMaxPages; page++ {
+		var bbPulls bitbucketPage[bitbucketPull]
+		if err := b.get(ctx, next, &bbPulls); err != nil {
+			return nil, err
+		}
+		for _, bp := range bbPulls.Values {
+			result = append(result, bp.pull())
+		}
+		next = bbPulls.Next
+	}
+	return result, nil
+}
+
+// FetchPullComments retrieves a Bitbucket pull request's comments, both
+// general and inline, leaving out deleted ones and those from apps.
+func (b *BitbucketSource) FetchPullComments(ctx context.Context, number int) ([]PullComment, error) {
+	next := fmt.Sprintf("%s/pullrequests/%d/comments?pagelen=100", b.repoURL(), number)
+
+	var result []PullComment
+	for page := 0; next != "" && page < bitbucketMaxPages; page++ {
+		var comments bitbucketPage[bitbucketComment]
+		if err := b.get(ctx, next, &comments); err != nil {
+			return nil, err
+		}
+		for _, c := range comments.Values {
+			if c.Deleted || c.User.Type == "app_user" {
+				continue
+			}
+			author := c.User.Nickname
+			if author == "" {
+				author = c.User.DisplayName
+			}
+			pc := PullComment{
+				ID:      "comment:" + strconv.FormatInt(c.ID, 10),
+				Author:  author,
+				Body:    c.Content.Raw,
+				Created: c.CreatedOn,
+			}
+			if c.Inline != nil {
+				pc.Path = c.Inline.Path
+				switch {
+				case c.Inline.To != nil:
+					pc.Line = *c.Inline.To
+				case c.Inline.From != nil:
+					pc.Line = *c.Inline.From
+				}
+			}
+			result = append(result, pc)
+		}
+		next = comments.Next
+	}
+	return result, nil
+}
+
+// CreatePullRequest opens a Bitbucket pull request from head into base. A
+// head of <workspace>:<branch> names a branch of the fork in that workspace,
+// which Bitbucket expects to keep the repository's name.
+func (b *BitbucketSource) CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error) {
+	source := map[string]any{}
+	branch := head
+	if workspace, name, ok := strings.Cut(head, ":"); ok {
+		branch = name
+		source["repository"] = map[string]string{"full_name": workspace + "/" + b.Repo}
+	}
+	source["branch"] = map[string]string{"name": branch}
+	req := map[string]any{
+		"title":       title,
+		"description": body,
+		"source":      source,
+		"destination": map[string]any{"branch": map[string]string{"name": base}},
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.do(ctx, http.MethodPost, b.repoURL()+"/pullrequests", string(data))
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket API request failed: %w", err)
+	}
+	var bp bitbucketPull
+	if err := decodeBitbucket(resp, &bp, http.StatusCreated); err != nil {
+		return nil, fmt.Errorf("creating pull request for %s: %w", branch, err)
+	}
+	pr := bp.pull()
+	return &pr, nil
+}
+
+// ParseBitbucketURL extracts the workspace and repo from a bitbucket.org
+// remote URL, such as https://user@bitbucket.org/workspace/repo.git or
+// git@bitbucket.org:workspace/repo.git.
+func ParseBitbucketURL(remoteURL string) (workspace, repo string, ok bool) {
+	baseURL, workspace, repo, ok := ParseGiteaURL(remoteURL)
+	if !ok {
+		return "", "", false
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || !strings.EqualFold(u.Hostname(), "bitbucket.org") {
+		return "", "", false
+	}
+	return workspace, repo, true
+}
+
+// repoURL returns the API URL of the repository.
+func (b *BitbucketSource) repoURL() string {
+	return fmt.Sprintf("%s/repositories/%s/%s", strings.TrimRight(b.BaseURL, "/"),
+		url.PathEscape(b.Workspace), url.PathEscape(b.Repo))
+}
+
+// get sends a GET request to the Bitbucket API and decodes the response
+// into v.
+func (b *BitbucketSource) get(ctx context.Context, apiURL string, v any) error {
+	resp, err := b.do(ctx, http.MethodGet, apiURL, "")
+	if err != nil {
+		return fmt.Errorf("bitbucket API request failed: %w", err)
+	}
+	return decodeBitbucket(resp, v, http.StatusOK)
+}
+
+// do sends an API request with a JSON body, if one is given.
+func (b *BitbucketSource) do(ctx context.Context, method, apiURL, body string) (*http.Response, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.Token != "" {
+		if strings.Contains(b.Token, ":") {
+			req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(b.Token)))
+		} else {
+			req.Header.Set("Authorization", "Bearer "+b.Token)
+		}
+	}
+	return http.DefaultClient.Do(req) //nolint:gosec // URL is built from the Bitbucket API and the source repo's workspace/repo
+}
+
+// decodeBitbucket checks the response status against the accepted ones and
+// decodes the body into v, if v is not nil. Errors carry the message
+// Bitbucket sends with failed requests.
+func decodeBitbucket(resp *http.Response, v any, accepted ...int) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	if !slices.Contains(accepted, resp.StatusCode) {
+		var apiErr bitbucketError
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("bitbucket API returned status %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("bitbucket API returned status %d", resp.StatusCode)
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding Bitbucket response: %w", err)
+	}
+	return nil
+}
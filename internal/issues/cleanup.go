@@ -54,19 +54,15 @@ func Cleanup(dd *design.Dir, sourceRepo *repo.Repo, closer Closer) (*CleanupResu
 			}
 
 			// Close associated issue if this is an issue task.
-			if closer == nil || !IsIssueTask(&task) {
+			if closer == nil {
 				continue
 			}
-			num := ParseIssueTaskNumber(task.Name)
+			num := IssueNumber(&task)
 			if num == 0 {
 				continue
 			}
 
-			taskRef := task.Name
-			if task.Group != "" {
-				taskRef = task.Group + "/" + task.Name
-			}
-			sha := mergeSHAs[taskRef]
+			sha := mergeSHAs[task.Label()]
 
 			comment := fmt.Sprintf(
 				"Thanks for reporting this! It has been addressed by hydra in commit %s.",
@@ -86,3 +82,37 @@ func Cleanup(dd *design.Dir, sourceRepo *repo.Repo, closer Closer) (*CleanupResu
 
 	return result, nil
 }
+
+// OrphanedBranches returns hydra/* branches on the push remote (origin, or
+// the fork when push_remote is set) that have already been merged into
+// defaultBranch but no longer correspond to any task, for example because
+// the task was deleted or renamed after merging. With an empty
+// defaultBranch, every hydra/* branch without a task is returned, merged or
+// not. The source repo should be freshly fetched.
+func OrphanedBranches(dd *design.Dir, sourceRepo *repo.Repo, defaultBranch string) ([]string, error) {
+	remote, err := sourceRepo.RemoteBranches("hydra/")
+	if err != nil {
+		return nil, fmt.Errorf("listing remote branches: %w", err)
+	}
+
+	tasks, err := dd.AllTasks()
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+	known := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		known[t.BranchName()] = true
+	}
+
+	pushRemote := sourceRepo.PushRemote()
+	var orphans []string
+	for _, branch := range remote {
+		if known[branch] {
+			continue
+		}
+		if defaultBranch == "" || sourceRepo.IsAncestor(pushRemote+"/"+branch, "origin/"+defaultBranch) {
+			orphans = append(orphans, branch)
+		}
+	}
+	return orphans, nil
+}
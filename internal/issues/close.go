@@ -12,6 +12,13 @@ type Closer interface {
 	CloseIssue(number int, comment string) error
 }
 
+// Reopener is the interface for reopening closed issues on a remote
+// tracker. The GitHub, Gitea, Forgejo, and Bitbucket sources implement it
+// alongside Closer.
+type Reopener interface {
+	ReopenIssue(number int, comment string) error
+}
+
 // ParseIssueTaskNumber extracts the issue number from a task name like "42-fix-bug".
 // Returns 0 if the name doesn't start with a number.
 func ParseIssueTaskNumber(taskName string) int {
@@ -26,7 +33,41 @@ func ParseIssueTaskNumber(taskName string) int {
 	return n
 }
 
-// IsIssueTask returns true if the task belongs to the "issues" group.
+// IssueNumber returns the number of the issue a task was imported from, or
+// 0 if it wasn't imported from one. The number comes from the task's
+// "issue" front matter, which sync writes for issues it places outside the
+// issues group, or else from the name of a task in the issues group.
+func IssueNumber(task *design.Task) int {
+	if meta, err := task.Frontmatter(); err == nil && meta[issueKey] != "" {
+		if n, err := strconv.Atoi(meta[issueKey]); err == nil && n > 0 {
+			return n
+		}
+	}
+	if task.Group == issuesGroup {
+		return ParseIssueTaskNumber(task.Name)
+	}
+	return 0
+}
+
+// IsIssueTask reports whether the task was imported from an issue.
 func IsIssueTask(task *design.Task) bool {
-	return task.Group == "issues"
+	return IssueNumber(task) > 0
+}
+
+// PinIssueNumber writes the number of the issue a task in the issues group
+// was imported from into its front matter, where IssueNumber finds it
+// after the task is renamed. Other tasks are left alone.
+func PinIssueNumber(task *design.Task) error {
+	if task.Group != issuesGroup {
+		return nil
+	}
+	meta, err := task.Frontmatter()
+	if err != nil {
+		return err
+	}
+	n := ParseIssueTaskNumber(task.Name)
+	if meta[issueKey] != "" || n == 0 {
+		return nil
+	}
+	return task.SetFrontmatter(map[string]string{issueKey: strconv.Itoa(n)})
 }
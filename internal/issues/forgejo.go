@@ -0,0 +1,353 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/credstore"
+)
+
+// forgejoPageSize is the number of issues requested per page. Forgejo caps
+// page sizes at the instance's MAX_RESPONSE_ITEMS, which is 50 by default
+// and on codeberg.org.
+const forgejoPageSize = 50
+
+// forgejoMaxPages bounds pagination so a misbehaving server cannot loop
+// forever. Reaching it is reported, since results are left out.
+const forgejoMaxPages = 200
+
+// ForgejoSource fetches issues from a Forgejo instance, such as codeberg.org.
+type ForgejoSource struct {
+	BaseURL string // e.g. "https://codeberg.org"
+	Owner   string
+	Repo    string
+	Token   string // from FORGEJO_TOKEN or the credential store
+}
+
+// NewForgejoSource creates a ForgejoSource. An empty token is looked up in
+// the credential store.
+func NewForgejoSource(baseURL, owner, repo, token string) *ForgejoSource {
+	if token == "" {
+		token = credstore.Lookup(credstore.ProviderForgejo)
+	}
+	return &ForgejoSource{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Owner:   owner,
+		Repo:    repo,
+		Token:   token,
+	}
+}
+
+type forgejoIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone   *forgeMilestone `json:"milestone"`
+	PullRequest *struct{}       `json:"pull_request"` // non-nil means it's a PR
+}
+
+// forgejoError is the body of a Forgejo API error response.
+type forgejoError struct {
+	Message string `json:"message"`
+}
+
+// FetchOpenIssues retrieves all open issues from a Forgejo instance,
+// following pagination.
+func (f *ForgejoSource) FetchOpenIssues(ctx context.Context, labels []string) ([]Issue, error) {
+	var result []Issue
+	for page := 1; page <= forgejoMaxPages; page++ {
+		apiURL := fmt.Sprintf("%s/issues?state=open&type=issues&limit=%d&page=%d",
+			f.repoURL(), forgejoPageSize, page)
+		if len(labels) > 0 {
+			apiURL += "&labels=" + url.QueryEscape(strings.Join(labels, ","))
+		}
+
+		resp, err := f.do(ctx, http.MethodGet, apiURL, "")
+		if err != nil {
+			return nil, fmt.Errorf("forgejo API request failed: %w", err)
+		}
+		var fjIssues []forgejoIssue
+		err = decodeForgejo(resp, &fjIssues, http.StatusOK)
+		if err != nil {
+			return nil, err
+		}
+
+		// The server may return fewer than asked for per page, so only an
+		// empty page ends the list.
+		if len(fjIssues) == 0 {
+			return result, nil
+		}
+		for _, fi := range fjIssues {
+			if fi.PullRequest != nil {
+				continue
+			}
+			result = append(result, fi.issue())
+		}
+	}
+
+	warnForgejoMaxPages("issues")
+	return result, nil
+}
+
+// FetchIssue retrieves a single issue from a Forgejo instance. Pull
+// requests, which share issue numbers, are rejected.
+func (f *ForgejoSource) FetchIssue(ctx context.Context, number int) (*Issue, error) {
+	resp, err := f.do(ctx, http.MethodGet, fmt.Sprintf("%s/issues/%d", f.repoURL(), number), "")
+	if err != nil {
+		return nil, fmt.Errorf("forgejo API request failed: %w", err)
+	}
+	var fi forgejoIssue
+	if err := decodeForgejo(resp, &fi, http.StatusOK); err != nil {
+		return nil, err
+	}
+	if fi.PullRequest != nil {
+		return nil, fmt.Errorf("#%d is a pull request, not an issue", number)
+	}
+	issue := fi.issue()
+	return &issue, nil
+}
+
+// issue converts an API issue.
+func (fi forgejoIssue) issue() Issue {
+	var labelNames []string
+	for _, l := range fi.Labels {
+		labelNames = append(labelNames, l.Name)
+	}
+	return Issue{
+		Number:    fi.Number,
+		Title:     fi.Title,
+		Body:      fi.Body,
+		Labels:    labelNames,
+		URL:       fi.HTMLURL,
+		Milestone: fi.Milestone.milestone(),
+	}
+}
+
+// CloseIssue closes a Forgejo issue with an optional comment.
+func (f *ForgejoSource) CloseIssue(number int, comment string) error {
+	return f.setIssueState(number, "closed", comment)
+}
+
+// ReopenIssue reopens a closed Forgejo issue with an optional comment.
+func (f *ForgejoSource) ReopenIssue(number int, comment string) error {
+	return f.setIssueState(number, "open", comment)
+}
+
+// setIssueState posts comment, if given, on a Forgejo issue and sets its
+// state to open or closed.
+func (f *ForgejoSource) setIssueState(number int, state, comment string) error {
+	ctx := context.Background()
+	action := "closing"
+	if state == "open" {
+		action = "reopening"
+	}
+	issueURL := fmt.Sprintf("%s/issues/%d", f.repoURL(), number)
+
+	if comment != "" {
+		body, err := json.Marshal(map[string]string{"body": comment})
+		if err != nil {
+			return err
+		}
+		resp, err := f.do(ctx, http.MethodPost, issueURL+"/comments", string(body))
+		if err != nil {
+			return fmt.Errorf("posting comment: %w", err)
+		}
+		if err := decodeForgejo(resp, nil, http.StatusCreated); err != nil {
+			return fmt.Errorf("posting comment on issue #%d: %w", number, err)
+		}
+	}
+
+	resp, err := f.do(ctx, http.MethodPatch, issueURL, fmt.Sprintf(`{"state":%q}`, state))
+	if err != nil {
+		return fmt.Errorf("%s issue: %w", action, err)
+	}
+	if err := decodeForgejo(resp, nil, http.StatusCreated, http.StatusOK); err != nil {
+		return fmt.Errorf("%s issue #%d: %w", action, number, err)
+	}
+	return nil
+}
+
+// repoURL returns the API URL of the repository.
+func (f *ForgejoSource) repoURL() string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s", f.BaseURL, url.PathEscape(f.Owner), url.PathEscape(f.Repo))
+}
+
+// do sends an API request with a JSON body, if one is given.
+func (f *ForgejoSource) do(ctx context.Context, method, apiURL, body string) (*http.Response, error) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if f.Token != "" {
+		req.Header.Set("Authorization", "token "+f.Token)
+	}
+	return http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured Forgejo base URL
+}
+
+// decodeForgejo checks the response status against the accepted ones and
+// decodes the body into v, if v is not nil. Errors carry the message
+// Forgejo sends with failed requests.
+func decodeForgejo(resp *http.Response, v any, accepted ...int) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	ok := false
+	for _, status := range accepted {
+		if resp.StatusCode == status {
+			ok = true
+		}
+	}
+	if !ok {
+		var apiErr forgejoError
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Message != "" {
+			return fmt.Errorf("forgejo API returned status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("forgejo API returned status %d", resp.StatusCode)
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding Forgejo response: %w", err)
+	}
+	return nil
+}
+
+// forgejoUser is the author of a Forgejo or Gitea comment or review.
+type forgejoUser struct {
+	Login string `json:"login"`
+}
+
+type forgejoPull struct {
+	Number  int    `json:"number"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+// forgejoComment is a conversation comment, a review, or a line comment;
+// each endpoint fills in the fields it has.
+type forgejoComment struct {
+	ID          int64       `json:"id"`
+	User        forgejoUser `json:"user"`
+	Body        string      `json:"body"`
+	Path        string      `json:"path"`
+	Position    int         `json:"position"`
+	CreatedAt   time.Time   `json:"created_at"`
+	SubmittedAt time.Time   `json:"submitted_at"`
+}
+
+// FetchPullRequests retrieves the pull requests, open and closed, from a
+// Forgejo instance, following pagination.
+func (f *ForgejoSource) FetchPullRequests(ctx context.Context) ([]PullRequest, error) {
+	var result []PullRequest
+	for page := 1; page <= forgejoMaxPages; page++ {
+		apiURL := fmt.Sprintf("%s/pulls?state=all&sort=recentupdate&limit=%d&page=%d", f.repoURL(), forgejoPageSize, page)
+		resp, err := f.do(ctx, http.MethodGet, apiURL, "")
+		if err != nil {
+			return nil, fmt.Errorf("forgejo API request failed: %w", err)
+		}
+		var fjPulls []forgejoPull
+		if err := decodeForgejo(resp, &fjPulls, http.StatusOK); err != nil {
+			return nil, err
+		}
+		if len(fjPulls) == 0 {
+			return result, nil
+		}
+		for _, fp := range fjPulls {
+			state := PullOpen
+			switch {
+			case fp.Merged:
+				state = PullMerged
+			case fp.State == "closed":
+				state = PullClosed
+			}
+			result = append(result, PullRequest{Number: fp.Number, Branch: fp.Head.Ref, State: state, URL: fp.HTMLURL})
+		}
+	}
+	warnForgejoMaxPages("pull requests")
+	return result, nil
+}
+
+// warnForgejoMaxPages reports that a listing stopped at forgejoMaxPages
+// with more left on the server.
+func warnForgejoMaxPages(what string) {
+	fmt.Fprintf(os.Stderr, "Warning: stopped reading Forgejo %s after %d pages; the rest are left out\n", what, forgejoMaxPages)
+}
+
+// FetchPullComments retrieves a Forgejo pull request's conversation
+// comments, review summaries, and the line comments of each review.
+func (f *ForgejoSource) FetchPullComments(ctx context.Context, number int) ([]PullComment, error) {
+	get := func(apiURL string) ([]forgejoComment, error) {
+		resp, err := f.do(ctx, http.MethodGet, apiURL, "")
+		if err != nil {
+			return nil, fmt.Errorf("forgejo API request failed: %w", err)
+		}
+		var comments []forgejoComment
+		if err := decodeForgejo(resp, &comments, http.StatusOK); err != nil {
+			return nil, err
+		}
+		return comments, nil
+	}
+	convert := func(kind string, c forgejoComment) PullComment {
+		created := c.CreatedAt
+		if created.IsZero() {
+			created = c.SubmittedAt
+		}
+		return PullComment{
+			ID:      kind + ":" + strconv.FormatInt(c.ID, 10),
+			Author:  c.User.Login,
+			Body:    c.Body,
+			Path:    c.Path,
+			Line:    c.Position,
+			Created: created,
+		}
+	}
+
+	var result []PullComment
+	comments, err := get(fmt.Sprintf("%s/issues/%d/comments", f.repoURL(), number))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		result = append(result, convert("comment", c))
+	}
+	reviews, err := get(fmt.Sprintf("%s/pulls/%d/reviews", f.repoURL(), number))
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		result = append(result, convert("review", r))
+		lines, err := get(fmt.Sprintf("%s/pulls/%d/reviews/%d/comments", f.repoURL(), number, r.ID))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range lines {
+			result = append(result, convert("line", c))
+		}
+	}
+	return result, nil
+}
@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
+
+	"github.com/erikh/hydra/internal/credstore"
 )
 
 // GiteaSource fetches issues from a Gitea instance.
@@ -15,13 +16,13 @@ type GiteaSource struct {
 	BaseURL string // e.g. "https://gitea.example.com"
 	Owner   string
 	Repo    string
-	Token   string // from GITEA_TOKEN or hydra.yml
+	Token   string // from GITEA_TOKEN or the credential store
 }
 
 // NewGiteaSource creates a GiteaSource.
 func NewGiteaSource(baseURL, owner, repo, token string) *GiteaSource {
 	if token == "" {
-		token = os.Getenv("GITEA_TOKEN")
+		token = credstore.Lookup(credstore.ProviderGitea)
 	}
 	return &GiteaSource{
 		BaseURL: strings.TrimRight(baseURL, "/"),
@@ -39,6 +40,8 @@ type giteaIssue struct {
 	Labels  []struct {
 		Name string `json:"name"`
 	} `json:"labels"`
+	Milestone   *forgeMilestone `json:"milestone"`
+	PullRequest *struct{}       `json:"pull_request"` // non-nil means it's a PR
 }
 
 // FetchOpenIssues retrieves open issues from a Gitea instance.
@@ -74,25 +77,79 @@ func (g *GiteaSource) FetchOpenIssues(ctx context.Context, labels []string) ([]I
 
 	var result []Issue
 	for _, gi := range gtIssues {
-		var labelNames []string
-		for _, l := range gi.Labels {
-			labelNames = append(labelNames, l.Name)
-		}
-		result = append(result, Issue{
-			Number: gi.Number,
-			Title:  gi.Title,
-			Body:   gi.Body,
-			Labels: labelNames,
-			URL:    gi.HTMLURL,
-		})
+		result = append(result, gi.issue())
 	}
 
 	return result, nil
 }
 
+// FetchIssue retrieves a single issue from a Gitea instance. Pull
+// requests, which share issue numbers, are rejected.
+func (g *GiteaSource) FetchIssue(ctx context.Context, number int) (*Issue, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/issues/%d", g.BaseURL, g.Owner, g.Repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "token "+g.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured Gitea base URL
+	if err != nil {
+		return nil, fmt.Errorf("gitea API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gitea API returned status %d", resp.StatusCode)
+	}
+
+	var gi giteaIssue
+	if err := json.NewDecoder(resp.Body).Decode(&gi); err != nil {
+		return nil, fmt.Errorf("decoding Gitea response: %w", err)
+	}
+	if gi.PullRequest != nil {
+		return nil, fmt.Errorf("#%d is a pull request, not an issue", number)
+	}
+	issue := gi.issue()
+	return &issue, nil
+}
+
+// issue converts an API issue.
+func (gi giteaIssue) issue() Issue {
+	var labelNames []string
+	for _, l := range gi.Labels {
+		labelNames = append(labelNames, l.Name)
+	}
+	return Issue{
+		Number:    gi.Number,
+		Title:     gi.Title,
+		Body:      gi.Body,
+		Labels:    labelNames,
+		URL:       gi.HTMLURL,
+		Milestone: gi.Milestone.milestone(),
+	}
+}
+
 // CloseIssue closes a Gitea issue with an optional comment.
 func (g *GiteaSource) CloseIssue(number int, comment string) error {
+	return g.setIssueState(number, "closed", comment)
+}
+
+// ReopenIssue reopens a closed Gitea issue with an optional comment.
+func (g *GiteaSource) ReopenIssue(number int, comment string) error {
+	return g.setIssueState(number, "open", comment)
+}
+
+// setIssueState posts comment, if given, on a Gitea issue and sets its
+// state to open or closed.
+func (g *GiteaSource) setIssueState(number int, state, comment string) error {
 	ctx := context.Background()
+	action := "closing"
+	if state == "open" {
+		action = "reopening"
+	}
 
 	// Post comment if provided.
 	if comment != "" {
@@ -113,9 +170,9 @@ func (g *GiteaSource) CloseIssue(number int, comment string) error {
 		_ = resp.Body.Close()
 	}
 
-	// Close the issue.
-	closeURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/issues/%d", g.BaseURL, g.Owner, g.Repo, number)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, closeURL, strings.NewReader(`{"state":"closed"}`))
+	// Set the issue's state.
+	issueURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/issues/%d", g.BaseURL, g.Owner, g.Repo, number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, issueURL, strings.NewReader(fmt.Sprintf(`{"state":%q}`, state)))
 	if err != nil {
 		return err
 	}
@@ -126,12 +183,12 @@ func (g *GiteaSource) CloseIssue(number int, comment string) error {
 
 	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured Gitea base URL
 	if err != nil {
-		return fmt.Errorf("closing issue: %w", err)
+		return fmt.Errorf("%s issue: %w", action, err)
 	}
 	_ = resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("gitea API returned status %d when closing issue #%d", resp.StatusCode, number)
+		return fmt.Errorf("gitea API returned status %d when %s issue #%d", resp.StatusCode, action, number)
 	}
 
 	return nil
@@ -164,3 +221,21 @@ func ParseGiteaURL(remoteURL string) (baseURL, owner, repo string, ok bool) {
 	}
 	return fmt.Sprintf("%s://%s", u.Scheme, u.Host), owner, repo, true
 }
+
+// forgejo returns a Forgejo client for the same repository. Forgejo is a
+// fork of Gitea and keeps its pull request API.
+func (g *GiteaSource) forgejo() *ForgejoSource {
+	return &ForgejoSource{BaseURL: g.BaseURL, Owner: g.Owner, Repo: g.Repo, Token: g.Token}
+}
+
+// FetchPullRequests retrieves the pull requests, open and closed, from a
+// Gitea instance.
+func (g *GiteaSource) FetchPullRequests(ctx context.Context) ([]PullRequest, error) {
+	return g.forgejo().FetchPullRequests(ctx)
+}
+
+// FetchPullComments retrieves a Gitea pull request's conversation comments,
+// review summaries, and line comments.
+func (g *GiteaSource) FetchPullComments(ctx context.Context, number int) ([]PullComment, error) {
+	return g.forgejo().FetchPullComments(ctx, number)
+}
@@ -5,15 +5,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/credstore"
 )
 
+// githubAPI is the GitHub REST API's base URL.
+const githubAPI = "https://api.github.com"
+
+// githubPageSize is the largest page GitHub's list endpoints serve.
+const githubPageSize = 100
+
+// githubMaxPages bounds pagination so a misbehaving server cannot loop forever.
+const githubMaxPages = 20
+
 // GitHubSource fetches issues from the GitHub REST API.
 type GitHubSource struct {
-	Owner string
-	Repo  string
-	Token string // optional; from GITHUB_TOKEN
+	BaseURL string // API base URL; api.github.com's when empty
+	Owner   string
+	Repo    string
+	Token   string // optional; from GITHUB_TOKEN or the credential store
 }
 
 // NewGitHubSource creates a GitHubSource from an owner/repo pair.
@@ -21,7 +34,7 @@ func NewGitHubSource(owner, repo string) *GitHubSource {
 	return &GitHubSource{
 		Owner: owner,
 		Repo:  repo,
-		Token: os.Getenv("GITHUB_TOKEN"),
+		Token: credstore.Lookup(credstore.ProviderGitHub),
 	}
 }
 
@@ -33,12 +46,13 @@ type githubIssue struct {
 	Labels  []struct {
 		Name string `json:"name"`
 	} `json:"labels"`
-	PullRequest *struct{} `json:"pull_request"` // non-nil means it's a PR
+	Milestone   *forgeMilestone `json:"milestone"`
+	PullRequest *struct{}       `json:"pull_request"` // non-nil means it's a PR
 }
 
 // FetchOpenIssues retrieves open issues from GitHub.
 func (g *GitHubSource) FetchOpenIssues(ctx context.Context, labels []string) ([]Issue, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues?state=open&per_page=100", g.Owner, g.Repo)
+	url := g.repoURL() + "/issues?state=open&per_page=100"
 	if len(labels) > 0 {
 		url += "&labels=" + strings.Join(labels, ",")
 	}
@@ -73,22 +87,62 @@ func (g *GitHubSource) FetchOpenIssues(ctx context.Context, labels []string) ([]
 		if gi.PullRequest != nil {
 			continue
 		}
-		var labelNames []string
-		for _, l := range gi.Labels {
-			labelNames = append(labelNames, l.Name)
-		}
-		result = append(result, Issue{
-			Number: gi.Number,
-			Title:  gi.Title,
-			Body:   gi.Body,
-			Labels: labelNames,
-			URL:    gi.HTMLURL,
-		})
+		result = append(result, gi.issue())
 	}
 
 	return result, nil
 }
 
+// FetchIssue retrieves a single issue from GitHub. Pull requests, which
+// share issue numbers, are rejected.
+func (g *GitHubSource) FetchIssue(ctx context.Context, number int) (*Issue, error) {
+	url := fmt.Sprintf("%s/issues/%d", g.repoURL(), number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured GitHub owner/repo
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var gi githubIssue
+	if err := json.NewDecoder(resp.Body).Decode(&gi); err != nil {
+		return nil, fmt.Errorf("decoding GitHub response: %w", err)
+	}
+	if gi.PullRequest != nil {
+		return nil, fmt.Errorf("#%d is a pull request, not an issue", number)
+	}
+	issue := gi.issue()
+	return &issue, nil
+}
+
+// issue converts an API issue.
+func (gi githubIssue) issue() Issue {
+	var labelNames []string
+	for _, l := range gi.Labels {
+		labelNames = append(labelNames, l.Name)
+	}
+	return Issue{
+		Number:    gi.Number,
+		Title:     gi.Title,
+		Body:      gi.Body,
+		Labels:    labelNames,
+		URL:       gi.HTMLURL,
+		Milestone: gi.Milestone.milestone(),
+	}
+}
+
 // ParseGitHubURL extracts owner and repo from a GitHub URL.
 // Supports https://github.com/owner/repo and git@github.com:owner/repo formats.
 func ParseGitHubURL(remoteURL string) (owner, repo string, ok bool) {
@@ -115,11 +169,26 @@ func ParseGitHubURL(remoteURL string) (owner, repo string, ok bool) {
 
 // CloseIssue closes a GitHub issue with an optional comment.
 func (g *GitHubSource) CloseIssue(number int, comment string) error {
+	return g.setIssueState(number, "closed", comment)
+}
+
+// ReopenIssue reopens a closed GitHub issue with an optional comment.
+func (g *GitHubSource) ReopenIssue(number int, comment string) error {
+	return g.setIssueState(number, "open", comment)
+}
+
+// setIssueState posts comment, if given, on a GitHub issue and sets its
+// state to open or closed.
+func (g *GitHubSource) setIssueState(number int, state, comment string) error {
 	ctx := context.Background()
+	action := "closing"
+	if state == "open" {
+		action = "reopening"
+	}
 
 	// Post comment if provided.
 	if comment != "" {
-		commentURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/comments", g.Owner, g.Repo, number)
+		commentURL := fmt.Sprintf("%s/issues/%d/comments", g.repoURL(), number)
 		body := fmt.Sprintf(`{"body":%q}`, comment)
 		req, err := http.NewRequestWithContext(ctx, http.MethodPost, commentURL, strings.NewReader(body))
 		if err != nil {
@@ -137,9 +206,9 @@ func (g *GitHubSource) CloseIssue(number int, comment string) error {
 		_ = resp.Body.Close()
 	}
 
-	// Close the issue.
-	closeURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", g.Owner, g.Repo, number)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, closeURL, strings.NewReader(`{"state":"closed"}`))
+	// Set the issue's state.
+	issueURL := fmt.Sprintf("%s/issues/%d", g.repoURL(), number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, issueURL, strings.NewReader(fmt.Sprintf(`{"state":%q}`, state)))
 	if err != nil {
 		return err
 	}
@@ -151,12 +220,12 @@ func (g *GitHubSource) CloseIssue(number int, comment string) error {
 
 	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured GitHub owner/repo
 	if err != nil {
-		return fmt.Errorf("closing issue: %w", err)
+		return fmt.Errorf("%s issue: %w", action, err)
 	}
 	_ = resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("GitHub API returned status %d when closing issue #%d", resp.StatusCode, number)
+		return fmt.Errorf("GitHub API returned status %d when %s issue #%d", resp.StatusCode, action, number)
 	}
 
 	return nil
@@ -171,3 +240,144 @@ func parseOwnerRepo(path string) (string, string, bool) {
 	}
 	return parts[0], parts[1], true
 }
+
+// githubUser is the author of a GitHub pull request, comment, or review.
+type githubUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"` // "Bot" for apps
+}
+
+type githubPull struct {
+	Number   int        `json:"number"`
+	State    string     `json:"state"`
+	MergedAt *time.Time `json:"merged_at"`
+	HTMLURL  string     `json:"html_url"`
+	Head     struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+}
+
+// githubComment is a conversation comment, a review, or a line comment;
+// each endpoint fills in the fields it has.
+type githubComment struct {
+	ID          int64      `json:"id"`
+	User        githubUser `json:"user"`
+	Body        string     `json:"body"`
+	Path        string     `json:"path"`
+	Line        int        `json:"line"`
+	CreatedAt   time.Time  `json:"created_at"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+}
+
+// FetchPullRequests retrieves the pull requests, open and closed, from
+// GitHub, most recently updated first, following pagination.
+func (g *GitHubSource) FetchPullRequests(ctx context.Context) ([]PullRequest, error) {
+	ghPulls, err := githubList[githubPull](ctx, g, g.repoURL()+"/pulls?state=all&sort=updated&direction=desc")
+	if err != nil {
+		return nil, err
+	}
+	var result []PullRequest
+	for _, gp := range ghPulls {
+		state := PullOpen
+		switch {
+		case gp.MergedAt != nil:
+			state = PullMerged
+		case gp.State == "closed":
+			state = PullClosed
+		}
+		result = append(result, PullRequest{Number: gp.Number, Branch: gp.Head.Ref, State: state, URL: gp.HTMLURL})
+	}
+	return result, nil
+}
+
+// FetchPullComments retrieves a GitHub pull request's conversation
+// comments, review summaries, and line comments, leaving out bots and
+// following pagination.
+func (g *GitHubSource) FetchPullComments(ctx context.Context, number int) ([]PullComment, error) {
+	base := g.repoURL()
+	var result []PullComment
+	for _, kind := range []struct{ id, path string }{
+		{"comment", fmt.Sprintf("%s/issues/%d/comments", base, number)},
+		{"review", fmt.Sprintf("%s/pulls/%d/reviews", base, number)},
+		{"line", fmt.Sprintf("%s/pulls/%d/comments", base, number)},
+	} {
+		comments, err := githubList[githubComment](ctx, g, kind.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if c.User.Type == "Bot" {
+				continue
+			}
+			created := c.CreatedAt
+			if created.IsZero() {
+				created = c.SubmittedAt
+			}
+			result = append(result, PullComment{
+				ID:      kind.id + ":" + strconv.FormatInt(c.ID, 10),
+				Author:  c.User.Login,
+				Body:    c.Body,
+				Path:    c.Path,
+				Line:    c.Line,
+				Created: created,
+			})
+		}
+	}
+	return result, nil
+}
+
+// githubList fetches every page of a GitHub list endpoint, up to
+// githubMaxPages.
+func githubList[T any](ctx context.Context, g *GitHubSource, apiURL string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(apiURL, "?") {
+		sep = "&"
+	}
+	var result []T
+	for page := 1; page <= githubMaxPages; page++ {
+		var items []T
+		if err := g.get(ctx, fmt.Sprintf("%s%sper_page=%d&page=%d", apiURL, sep, githubPageSize, page), &items); err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		if len(items) < githubPageSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// repoURL returns the API URL of the repository.
+func (g *GitHubSource) repoURL() string {
+	base := g.BaseURL
+	if base == "" {
+		base = githubAPI
+	}
+	return fmt.Sprintf("%s/repos/%s/%s", strings.TrimRight(base, "/"), g.Owner, g.Repo)
+}
+
+// get sends a GET request to the GitHub API and decodes the response into v.
+func (g *GitHubSource) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL is built from user-configured GitHub owner/repo
+	if err != nil {
+		return fmt.Errorf("GitHub API request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding GitHub response: %w", err)
+	}
+	return nil
+}
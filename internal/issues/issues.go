@@ -1,4 +1,4 @@
-// Package issues imports open issues from GitHub or Gitea as design tasks.
+// Package issues imports open issues from GitHub, Gitea, Forgejo, or Bitbucket as design tasks.
 package issues
 
 import (
@@ -7,76 +7,288 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
 )
 
 // Issue represents a single issue from a remote source.
 type Issue struct {
-	Number int
-	Title  string
-	Body   string
-	Labels []string
-	URL    string
+	Number    int
+	Title     string
+	Body      string
+	Labels    []string
+	URL       string
+	Milestone *Milestone // forge milestone the issue is attached to, if any
+}
+
+// Milestone is a forge milestone.
+type Milestone struct {
+	Title string
+	Due   time.Time // zero if the milestone has no due date
+}
+
+// forgeMilestone is the milestone of an issue in GitHub, Gitea, and Forgejo
+// API responses, which share its shape.
+type forgeMilestone struct {
+	Title string     `json:"title"`
+	DueOn *time.Time `json:"due_on"`
+}
+
+// milestone converts an API milestone, which may be absent.
+func (m *forgeMilestone) milestone() *Milestone {
+	if m == nil {
+		return nil
+	}
+	ms := &Milestone{Title: m.Title}
+	if m.DueOn != nil {
+		ms.Due = *m.DueOn
+	}
+	return ms
 }
 
 // Source is the interface for fetching issues from a remote.
 type Source interface {
 	FetchOpenIssues(ctx context.Context, labels []string) ([]Issue, error)
+	// FetchIssue retrieves a single issue, open or closed, by number.
+	FetchIssue(ctx context.Context, number int) (*Issue, error)
+}
+
+const (
+	// issuesGroup is the task group issues are imported into by default.
+	issuesGroup = "issues"
+	// issueKey is the front matter key holding an imported issue's number.
+	issueKey = "issue"
+	// priorityKey is the front matter key holding a task's priority.
+	priorityKey = "priority"
+)
+
+// Rule maps issues with a label to a task group or priority, or skips them.
+type Rule struct {
+	Label    string // issue label the rule applies to, matched ignoring case
+	Group    string // task group to import into instead of issues
+	Priority string // written to the task's front matter as priority
+	Skip     bool   // don't import the issue
+}
+
+// SyncOptions controls which issues Sync imports and where they go.
+type SyncOptions struct {
+	Labels []string // only fetch issues with these labels
+
+	// Rules are checked in order; the first with a label the issue has
+	// applies.
+	Rules []Rule
+
+	// Milestones imports issues attached to a forge milestone with a due
+	// date as promises of the hydra milestone for that date, with their
+	// tasks in the milestone's task group.
+	Milestones bool
 }
 
-// Sync imports open issues into the design directory under tasks/issues/.
-func Sync(ctx context.Context, designDir string, source Source, labels []string) (created, skipped int, err error) {
-	issues, err := source.FetchOpenIssues(ctx, labels)
+// SyncResult counts what Sync did.
+type SyncResult struct {
+	Created    int
+	Skipped    int      // already imported
+	Ignored    int      // skipped by a rule
+	Milestones []string // dates of hydra milestones created or given promises
+}
+
+// Sync imports open issues into the design directory, under tasks/issues/
+// unless a rule or milestone places them elsewhere. Issues already imported,
+// in any state, are skipped.
+func Sync(ctx context.Context, designDir string, source Source, opts SyncOptions) (*SyncResult, error) {
+	issues, err := source.FetchOpenIssues(ctx, opts.Labels)
 	if err != nil {
-		return 0, 0, fmt.Errorf("fetching issues: %w", err)
+		return nil, fmt.Errorf("fetching issues: %w", err)
 	}
 
-	issuesDir := filepath.Join(designDir, "tasks", "issues")
+	issuesDir := filepath.Join(designDir, "tasks", issuesGroup)
 	if err := os.MkdirAll(issuesDir, 0o750); err != nil {
-		return 0, 0, fmt.Errorf("creating issues directory: %w", err)
+		return nil, fmt.Errorf("creating issues directory: %w", err)
 	}
 
 	// Create group.md if missing.
 	groupPath := filepath.Join(issuesDir, "group.md")
 	if _, err := os.Stat(groupPath); os.IsNotExist(err) {
 		if err := os.WriteFile(groupPath, []byte("Imported from repository issues.\n"), 0o600); err != nil {
-			return 0, 0, fmt.Errorf("creating group.md: %w", err)
+			return nil, fmt.Errorf("creating group.md: %w", err)
 		}
 	}
 
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := importedIssues(dd)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
 	for _, issue := range issues {
-		// Check if a file already exists for this issue number.
-		if issueFileExists(issuesDir, issue.Number) {
-			skipped++
+		if imported[issue.Number] {
+			result.Skipped++
+			continue
+		}
+		rule := matchRule(opts.Rules, issue.Labels)
+		if rule != nil && rule.Skip {
+			result.Ignored++
 			continue
 		}
 
-		filename := fmt.Sprintf("%d-%s.md", issue.Number, slugify(issue.Title))
-		filePath := filepath.Join(issuesDir, filename)
+		_, created, err := importIssue(dd, issue, rule, opts.Milestones, result)
+		if err != nil {
+			return result, fmt.Errorf("importing issue %d: %w", issue.Number, err)
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
 
-		content := formatIssueContent(issue)
-		if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
-			return created, skipped, fmt.Errorf("writing issue %d: %w", issue.Number, err)
+// Import imports a single issue by number, open or closed, as Sync would,
+// and returns its task. If a task in any state was already imported from
+// the issue, that task is returned instead. Rules place the task as in
+// Sync, except that a rule skipping the issue is ignored, since the issue
+// was asked for by number.
+func Import(ctx context.Context, designDir string, source Source, number int, opts SyncOptions) (*design.Task, error) {
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := dd.AllTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if IssueNumber(&t) == number {
+			return &t, nil
 		}
-		created++
 	}
 
-	return created, skipped, nil
+	issue, err := source.FetchIssue(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("fetching issue %d: %w", number, err)
+	}
+	if err := writeGroupFile(dd, issuesGroup, "Imported from repository issues.\n"); err != nil {
+		return nil, err
+	}
+	rule := matchRule(opts.Rules, issue.Labels)
+	if rule != nil && rule.Skip {
+		rule = nil
+	}
+	task, _, err := importIssue(dd, *issue, rule, opts.Milestones, &SyncResult{})
+	if err != nil {
+		return nil, fmt.Errorf("importing issue %d: %w", number, err)
+	}
+	return task, nil
 }
 
-// issueFileExists checks if any file in the directory starts with the issue number prefix.
-func issueFileExists(dir string, number int) bool {
-	prefix := fmt.Sprintf("%d-", number)
-	entries, err := os.ReadDir(dir)
+// importedIssues returns the numbers of the issues tasks in any state were
+// imported from.
+func importedIssues(dd *design.Dir) (map[int]bool, error) {
+	tasks, err := dd.AllTasks()
 	if err != nil {
-		return false
+		return nil, err
+	}
+	imported := make(map[int]bool)
+	for _, t := range tasks {
+		if n := IssueNumber(&t); n > 0 {
+			imported[n] = true
+		}
+	}
+	return imported, nil
+}
+
+// matchRule returns the first rule with one of labels, or nil.
+func matchRule(rules []Rule, labels []string) *Rule {
+	for i, r := range rules {
+		for _, l := range labels {
+			if strings.EqualFold(r.Label, l) {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// importIssue writes the task file for an issue. An issue attached to a
+// dated forge milestone, when milestones are imported, becomes a promise
+// of the hydra milestone for that date, and its task is the promise's task.
+// Otherwise the task goes in the rule's group, or issues. created is false
+// if a task of the same name is already there.
+func importIssue(dd *design.Dir, issue Issue, rule *Rule, milestones bool, result *SyncResult) (task *design.Task, created bool, err error) {
+	group := issuesGroup
+	if rule != nil && rule.Group != "" {
+		group = rule.Group
+	}
+	name := fmt.Sprintf("%d-%s", issue.Number, slugify(issue.Title))
+
+	ms := issue.Milestone
+	if milestones && ms != nil && !ms.Due.IsZero() && design.Slugify(issue.Title) != "" {
+		date := ms.Due.UTC().Format("2006-01-02")
+		group = design.MilestoneTaskGroup(date)
+		name = design.Slugify(issue.Title)
+
+		added, err := dd.AddPromise(date, issue.Title, fmt.Sprintf("Issue #%d: %s", issue.Number, issue.URL))
+		if err != nil {
+			return nil, false, err
+		}
+		if added && !slices.Contains(result.Milestones, date) {
+			result.Milestones = append(result.Milestones, date)
+		}
+		if err := writeGroupFile(dd, group, fmt.Sprintf("Milestone %s tasks.\n", date)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	groupDir := filepath.Join(dd.Path, "tasks", group)
+	if err := os.MkdirAll(groupDir, 0o750); err != nil {
+		return nil, false, fmt.Errorf("creating group directory: %w", err)
+	}
+	task = &design.Task{Name: name, Group: group, FilePath: filepath.Join(groupDir, name+".md"), State: design.StatePending}
+	if _, err := os.Stat(task.FilePath); err == nil {
+		return task, false, nil
+	}
+	if err := os.WriteFile(task.FilePath, []byte(formatIssueContent(issue)), 0o600); err != nil {
+		return nil, false, fmt.Errorf("writing task: %w", err)
+	}
+
+	meta := make(map[string]string)
+	if group != issuesGroup {
+		meta[issueKey] = strconv.Itoa(issue.Number)
+	}
+	if rule != nil && rule.Priority != "" {
+		meta[priorityKey] = rule.Priority
+	}
+	if len(meta) > 0 {
+		if err := task.SetFrontmatter(meta); err != nil {
+			return nil, false, err
+		}
+	}
+	return task, true, nil
+}
+
+// writeGroupFile creates a group's group.md with content if it is missing.
+func writeGroupFile(dd *design.Dir, group, content string) error {
+	dir := filepath.Join(dd.Path, "tasks", group)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating group directory: %w", err)
 	}
-	for _, e := range entries {
-		if strings.HasPrefix(e.Name(), prefix) {
-			return true
+	path := filepath.Join(dir, "group.md")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("creating group.md: %w", err)
 		}
 	}
-	return false
+	return nil
 }
 
 // formatIssueContent formats an issue into the task file content.
@@ -2,14 +2,21 @@ package issues
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
 )
 
 // mockSource implements Source for testing.
@@ -22,6 +29,59 @@ func (m *mockSource) FetchOpenIssues(_ context.Context, _ []string) ([]Issue, er
 	return m.issues, m.err
 }
 
+func (m *mockSource) FetchIssue(_ context.Context, number int) (*Issue, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, issue := range m.issues {
+		if issue.Number == number {
+			return &issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue %d not found", number)
+}
+
+func TestImport(t *testing.T) {
+	designDir := t.TempDir()
+	src := &mockSource{
+		issues: []Issue{
+			{Number: 7, Title: "Fix the bug", Body: "There is a bug.", Labels: []string{"wontfix"}, URL: "https://example.com/7"},
+		},
+	}
+	opts := SyncOptions{Rules: []Rule{{Label: "wontfix", Skip: true}}}
+
+	task, err := Import(context.Background(), designDir, src, 7, opts)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if task.Group != "issues" || task.Name != "7-fix-the-bug" {
+		t.Errorf("task = %s/%s, want issues/7-fix-the-bug", task.Group, task.Name)
+	}
+	if _, err := os.Stat(filepath.Join(designDir, "tasks", "issues", "group.md")); err != nil {
+		t.Error("group.md not created")
+	}
+
+	// Importing again returns the existing task, wherever it has moved.
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dd.MoveTask(task, design.StateReview); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Import(context.Background(), designDir, &mockSource{err: fmt.Errorf("should not fetch")}, 7, opts)
+	if err != nil {
+		t.Fatalf("Import existing: %v", err)
+	}
+	if again.State != design.StateReview || again.Name != "7-fix-the-bug" {
+		t.Errorf("existing task = %s in %s, want 7-fix-the-bug in review", again.Name, again.State)
+	}
+
+	if _, err := Import(context.Background(), designDir, src, 8, opts); err == nil {
+		t.Error("expected error importing a missing issue")
+	}
+}
+
 func TestSyncCreatesFiles(t *testing.T) {
 	designDir := t.TempDir()
 
@@ -32,15 +92,15 @@ func TestSyncCreatesFiles(t *testing.T) {
 		},
 	}
 
-	created, skipped, err := Sync(context.Background(), designDir, src, nil)
+	res, err := Sync(context.Background(), designDir, src, SyncOptions{})
 	if err != nil {
 		t.Fatalf("Sync: %v", err)
 	}
-	if created != 2 {
-		t.Errorf("created = %d, want 2", created)
+	if res.Created != 2 {
+		t.Errorf("created = %d, want 2", res.Created)
 	}
-	if skipped != 0 {
-		t.Errorf("skipped = %d, want 0", skipped)
+	if res.Skipped != 0 {
+		t.Errorf("skipped = %d, want 0", res.Skipped)
 	}
 
 	// Verify group.md exists.
@@ -85,15 +145,15 @@ func TestSyncSkipsDuplicates(t *testing.T) {
 		},
 	}
 
-	created, skipped, err := Sync(context.Background(), designDir, src, nil)
+	res, err := Sync(context.Background(), designDir, src, SyncOptions{})
 	if err != nil {
 		t.Fatalf("Sync: %v", err)
 	}
-	if created != 1 {
-		t.Errorf("created = %d, want 1", created)
+	if res.Created != 1 {
+		t.Errorf("created = %d, want 1", res.Created)
 	}
-	if skipped != 1 {
-		t.Errorf("skipped = %d, want 1", skipped)
+	if res.Skipped != 1 {
+		t.Errorf("skipped = %d, want 1", res.Skipped)
 	}
 
 	// Original file should be untouched.
@@ -107,7 +167,7 @@ func TestSyncGroupMdCreated(t *testing.T) {
 	designDir := t.TempDir()
 
 	src := &mockSource{issues: []Issue{}}
-	_, _, err := Sync(context.Background(), designDir, src, nil)
+	_, err := Sync(context.Background(), designDir, src, SyncOptions{})
 	if err != nil {
 		t.Fatalf("Sync: %v", err)
 	}
@@ -131,7 +191,7 @@ func TestFileContentFormat(t *testing.T) {
 		},
 	}
 
-	_, _, err := Sync(context.Background(), designDir, src, nil)
+	_, err := Sync(context.Background(), designDir, src, SyncOptions{})
 	if err != nil {
 		t.Fatalf("Sync: %v", err)
 	}
@@ -256,6 +316,131 @@ func TestIsIssueTask(t *testing.T) {
 	}
 }
 
+func TestIssueNumber(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fix-login.md")
+	if err := os.WriteFile(path, []byte("---\nissue: 7\n---\nFix login.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n := IssueNumber(&design.Task{Name: "fix-login", Group: "bugs", FilePath: path}); n != 7 {
+		t.Errorf("IssueNumber (front matter) = %d, want 7", n)
+	}
+	if n := IssueNumber(&design.Task{Name: "42-fix-bug", Group: "issues"}); n != 42 {
+		t.Errorf("IssueNumber (issues group) = %d, want 42", n)
+	}
+}
+
+func TestPinIssueNumber(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "42-fix-bug.md")
+	if err := os.WriteFile(path, []byte("Fix the bug.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	task := &design.Task{Name: "42-fix-bug", Group: "issues", FilePath: path}
+	if err := PinIssueNumber(task); err != nil {
+		t.Fatal(err)
+	}
+	task.Name = "fix-bug"
+	if n := IssueNumber(task); n != 42 {
+		t.Errorf("IssueNumber after rename = %d, want 42", n)
+	}
+}
+
+func TestSyncRules(t *testing.T) {
+	designDir := t.TempDir()
+
+	src := &mockSource{
+		issues: []Issue{
+			{Number: 1, Title: "Crash on start", Labels: []string{"Bug"}, URL: "https://example.com/1"},
+			{Number: 2, Title: "Won't do", Labels: []string{"wontfix", "bug"}, URL: "https://example.com/2"},
+			{Number: 3, Title: "Plain", URL: "https://example.com/3"},
+		},
+	}
+	opts := SyncOptions{Rules: []Rule{
+		{Label: "wontfix", Skip: true},
+		{Label: "bug", Group: "bugs", Priority: "high"},
+	}}
+
+	res, err := Sync(context.Background(), designDir, src, opts)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 2 || res.Ignored != 1 {
+		t.Errorf("result = %+v, want 2 created, 1 ignored", res)
+	}
+
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := dd.FindTask("bugs/1-crash-on-start")
+	if err != nil {
+		t.Fatalf("mapped task: %v", err)
+	}
+	if n := IssueNumber(task); n != 1 {
+		t.Errorf("IssueNumber = %d, want 1", n)
+	}
+	data, _ := os.ReadFile(task.FilePath)
+	if !strings.Contains(string(data), "priority: high") {
+		t.Errorf("task missing priority:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(designDir, "tasks", "issues", "3-plain.md")); err != nil {
+		t.Error("unmatched issue not imported into issues")
+	}
+
+	// A second sync skips the mapped issue rather than re-importing it.
+	res, err = Sync(context.Background(), designDir, src, opts)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 0 || res.Skipped != 2 {
+		t.Errorf("re-sync result = %+v, want 0 created, 2 skipped", res)
+	}
+}
+
+func TestSyncMilestones(t *testing.T) {
+	designDir := t.TempDir()
+	due := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	src := &mockSource{
+		issues: []Issue{
+			{Number: 5, Title: "Ship auth", Body: "Login flow.", URL: "https://example.com/5", Milestone: &Milestone{Title: "v1", Due: due}},
+			{Number: 6, Title: "Undated", URL: "https://example.com/6", Milestone: &Milestone{Title: "someday"}},
+		},
+	}
+
+	res, err := Sync(context.Background(), designDir, src, SyncOptions{Milestones: true})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Created != 2 || len(res.Milestones) != 1 || res.Milestones[0] != "2025-06-01" {
+		t.Errorf("result = %+v", res)
+	}
+
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := dd.FindMilestone("2025-06-01")
+	if err != nil {
+		t.Fatalf("milestone not created: %v", err)
+	}
+	content, _ := m.Content()
+	if !strings.Contains(content, "## Ship auth") {
+		t.Errorf("milestone missing promise:\n%s", content)
+	}
+	task, err := dd.FindTask("milestone-2025-06-01/ship-auth")
+	if err != nil {
+		t.Fatalf("milestone task: %v", err)
+	}
+	if n := IssueNumber(task); n != 5 {
+		t.Errorf("IssueNumber = %d, want 5", n)
+	}
+	if _, err := os.Stat(filepath.Join(designDir, "tasks", "issues", "6-undated.md")); err != nil {
+		t.Error("undated milestone issue not imported into issues")
+	}
+}
+
 type mockCloser struct {
 	called  bool
 	number  int
@@ -343,6 +528,36 @@ func TestGiteaCloseIssue(t *testing.T) {
 	}
 }
 
+func TestGiteaReopenIssue(t *testing.T) {
+	var gotState string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/comments") {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if r.Method == http.MethodPatch && strings.Contains(r.URL.Path, "/issues/42") {
+			var body struct {
+				State string `json:"state"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			gotState = body.State
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	var src Reopener = NewGiteaSource(ts.URL, "owner", "repo", "test-token")
+	if err := src.ReopenIssue(42, "reopened by hydra"); err != nil {
+		t.Fatalf("ReopenIssue: %v", err)
+	}
+	if gotState != "open" {
+		t.Errorf("state = %q, want open", gotState)
+	}
+}
+
 func TestResolveSourceGitHub(t *testing.T) {
 	src, err := ResolveSource("https://github.com/owner/repo.git", "", "")
 	if err != nil {
@@ -373,9 +588,737 @@ func TestResolveSourceExplicitType(t *testing.T) {
 	}
 }
 
+func TestResolveSourceForgejo(t *testing.T) {
+	for _, repoURL := range []string{
+		"https://codeberg.org/owner/repo.git",
+		"git@codeberg.org:owner/repo.git",
+		"https://forgejo.example.com/owner/repo.git",
+	} {
+		src, err := ResolveSource(repoURL, "", "")
+		if err != nil {
+			t.Fatalf("ResolveSource(%q): %v", repoURL, err)
+		}
+		fj, ok := src.(*ForgejoSource)
+		if !ok {
+			t.Fatalf("ResolveSource(%q) = %T, want ForgejoSource", repoURL, src)
+		}
+		if fj.Owner != "owner" || fj.Repo != "repo" {
+			t.Errorf("ResolveSource(%q) owner/repo = %s/%s", repoURL, fj.Owner, fj.Repo)
+		}
+	}
+
+	src, err := ResolveSource("https://git.example.com/owner/repo.git", "forgejo", "https://api.example.com")
+	if err != nil {
+		t.Fatalf("ResolveSource: %v", err)
+	}
+	if fj, ok := src.(*ForgejoSource); !ok || fj.BaseURL != "https://api.example.com" {
+		t.Errorf("explicit forgejo api_type = %#v, want ForgejoSource at the override URL", src)
+	}
+}
+
+func TestForgejoFetchOpenIssuesPaginates(t *testing.T) {
+	var pages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/repo/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "token test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.URL.Query().Get("labels"); got != "bug,hydra" {
+			t.Errorf("labels = %q", got)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		var issues []map[string]any
+		switch page {
+		case "1":
+			for i := 1; i <= forgejoPageSize; i++ {
+				issues = append(issues, map[string]any{"number": i, "title": fmt.Sprintf("Issue %d", i)})
+			}
+		case "2":
+			issues = append(issues,
+				map[string]any{"number": 51, "title": "Last", "labels": []map[string]string{{"name": "bug"}}},
+				map[string]any{"number": 52, "title": "A pull", "pull_request": map[string]any{}},
+			)
+		}
+		_ = json.NewEncoder(w).Encode(issues)
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	got, err := src.FetchOpenIssues(context.Background(), []string{"bug", "hydra"})
+	if err != nil {
+		t.Fatalf("FetchOpenIssues: %v", err)
+	}
+	if len(got) != forgejoPageSize+1 {
+		t.Fatalf("got %d issues, want %d (pull requests skipped)", len(got), forgejoPageSize+1)
+	}
+	if last := got[len(got)-1]; last.Number != 51 || len(last.Labels) != 1 || last.Labels[0] != "bug" {
+		t.Errorf("last issue = %+v", last)
+	}
+	if strings.Join(pages, ",") != "1,2,3" {
+		t.Errorf("pages requested = %v, want [1 2 3]", pages)
+	}
+}
+
+func TestForgejoFetchOpenIssuesStopsAtMaxPages(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A server that ignores the page and never runs out.
+		requests++
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"number": 1, "title": "Again"}})
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	got, err := src.FetchOpenIssues(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("FetchOpenIssues: %v", err)
+	}
+	if requests != forgejoMaxPages || len(got) != forgejoMaxPages {
+		t.Errorf("made %d requests for %d issues, want %d of each", requests, len(got), forgejoMaxPages)
+	}
+}
+
+func TestGiteaFetchIssue(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/issues/42":
+			_ = json.NewEncoder(w).Encode(map[string]any{"number": 42, "title": "Fix it", "labels": []map[string]string{{"name": "bug"}}})
+		case "/api/v1/repos/owner/repo/issues/43":
+			_ = json.NewEncoder(w).Encode(map[string]any{"number": 43, "title": "A pull", "pull_request": map[string]any{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	src := NewGiteaSource(ts.URL, "owner", "repo", "test-token")
+	issue, err := src.FetchIssue(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("FetchIssue: %v", err)
+	}
+	if issue.Number != 42 || issue.Title != "Fix it" || len(issue.Labels) != 1 {
+		t.Errorf("issue = %+v", issue)
+	}
+	if _, err := src.FetchIssue(context.Background(), 43); err == nil {
+		t.Error("expected error fetching a pull request")
+	}
+	if _, err := src.FetchIssue(context.Background(), 44); err == nil {
+		t.Error("expected error fetching a missing issue")
+	}
+}
+
+func TestForgejoFetchIssue(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/issues/42":
+			_ = json.NewEncoder(w).Encode(map[string]any{"number": 42, "title": "Fix it", "labels": []map[string]string{{"name": "bug"}}})
+		case "/api/v1/repos/owner/repo/issues/43":
+			_ = json.NewEncoder(w).Encode(map[string]any{"number": 43, "title": "A pull", "pull_request": map[string]any{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	issue, err := src.FetchIssue(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("FetchIssue: %v", err)
+	}
+	if issue.Number != 42 || issue.Title != "Fix it" || len(issue.Labels) != 1 {
+		t.Errorf("issue = %+v", issue)
+	}
+	if _, err := src.FetchIssue(context.Background(), 43); err == nil {
+		t.Error("expected error fetching a pull request")
+	}
+	if _, err := src.FetchIssue(context.Background(), 44); err == nil {
+		t.Error("expected error fetching a missing issue")
+	}
+}
+
+func TestForgejoCloseIssue(t *testing.T) {
+	var gotComment, gotPatch bool
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/repos/owner/repo/issues/42/comments":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			gotComment = body["body"] == "closed by hydra"
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/repos/owner/repo/issues/42":
+			gotPatch = true
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	if err := src.CloseIssue(42, "closed by hydra"); err != nil {
+		t.Fatalf("CloseIssue: %v", err)
+	}
+	if !gotComment {
+		t.Error("expected comment POST with the comment body")
+	}
+	if !gotPatch {
+		t.Error("expected PATCH to close issue")
+	}
+}
+
+func TestForgejoErrorMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"token does not have required scope","url":"https://forgejo.org/api/swagger"}`))
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	err := src.CloseIssue(7, "")
+	if err == nil || !strings.Contains(err.Error(), "status 403: token does not have required scope") {
+		t.Errorf("CloseIssue error = %v, want Forgejo's message", err)
+	}
+}
+
+func TestIsForgejoHost(t *testing.T) {
+	tests := map[string]bool{
+		"https://codeberg.org":         true,
+		"https://CodeBerg.org":         true,
+		"https://forgejo.example.com":  true,
+		"https://git.forgejo.internal": true,
+		"https://gitea.example.com":    false,
+		"https://notcodeberg.org":      false,
+	}
+	for baseURL, want := range tests {
+		if got := IsForgejoHost(baseURL); got != want {
+			t.Errorf("IsForgejoHost(%q) = %v, want %v", baseURL, got, want)
+		}
+	}
+}
+
 func TestResolveSourceInvalid(t *testing.T) {
 	_, err := ResolveSource("not-a-url", "", "")
 	if err == nil {
 		t.Error("expected error for invalid URL")
 	}
 }
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...) //nolint:gosec // test with controlled args
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestOrphanedBranches(t *testing.T) {
+	bare := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, ".", "init", "--bare", "-b", "main", bare)
+
+	local := t.TempDir()
+	gitRun(t, local, "init", "-b", "main")
+	gitRun(t, local, "config", "user.email", "test@test.com")
+	gitRun(t, local, "config", "user.name", "Test")
+	gitRun(t, local, "config", "commit.gpgsign", "false")
+	gitRun(t, local, "remote", "add", "origin", bare)
+	if err := os.WriteFile(filepath.Join(local, "README.md"), []byte("# Test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, local, "add", "-A")
+	gitRun(t, local, "commit", "-m", "initial")
+	gitRun(t, local, "push", "origin", "main")
+
+	// Merged into main, no task: orphaned.
+	gitRun(t, local, "push", "origin", "main:refs/heads/hydra/renamed-away")
+	// Merged into main, task still exists: left for Cleanup.
+	gitRun(t, local, "push", "origin", "main:refs/heads/hydra/shipped")
+	// Not merged, no task: may hold unmerged work, leave it alone.
+	gitRun(t, local, "commit", "--allow-empty", "-m", "unmerged")
+	gitRun(t, local, "push", "origin", "HEAD:refs/heads/hydra/in-flight")
+	gitRun(t, local, "fetch", "origin")
+
+	designDir := t.TempDir()
+	completed := filepath.Join(designDir, "state", "completed")
+	if err := os.MkdirAll(completed, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(completed, "shipped.md"), []byte("Done."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := OrphanedBranches(dd, repo.Open(local), "main")
+	if err != nil {
+		t.Fatalf("OrphanedBranches: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0] != "hydra/renamed-away" {
+		t.Errorf("orphans = %v, want [hydra/renamed-away]", orphans)
+	}
+
+	// Without a default branch, unmerged branches count too.
+	orphans, err = OrphanedBranches(dd, repo.Open(local), "")
+	if err != nil {
+		t.Fatalf("OrphanedBranches: %v", err)
+	}
+	if !slices.Equal(orphans, []string{"hydra/in-flight", "hydra/renamed-away"}) {
+		t.Errorf("orphans = %v, want [hydra/in-flight hydra/renamed-away]", orphans)
+	}
+}
+
+// mockPullSource implements PullSource for testing.
+type mockPullSource struct {
+	pulls    []PullRequest
+	comments map[int][]PullComment
+	errs     map[int]error
+	fetched  []int
+}
+
+func (m *mockPullSource) FetchPullRequests(_ context.Context) ([]PullRequest, error) {
+	return m.pulls, nil
+}
+
+func (m *mockPullSource) FetchPullComments(_ context.Context, number int) ([]PullComment, error) {
+	m.fetched = append(m.fetched, number)
+	return m.comments[number], m.errs[number]
+}
+
+func TestSyncPulls(t *testing.T) {
+	designDir := t.TempDir()
+	for path, content := range map[string]string{
+		"tasks/pending.md":                "Pending.",
+		"state/review/backend/add-api.md": "API.",
+		"state/review/early-merge.md":     "Merged elsewhere.",
+		"state/completed/shipped.md":      "Done.",
+	} {
+		full := filepath.Join(designDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	src := &mockPullSource{
+		pulls: []PullRequest{
+			{Number: 7, Branch: "hydra/backend/add-api", State: PullOpen},
+			{Number: 3, Branch: "hydra/backend/add-api", State: PullClosed},
+			{Number: 8, Branch: "hydra/early-merge", State: PullMerged, URL: "https://example.com/pull/8"},
+			{Number: 9, Branch: "hydra/shipped", State: PullOpen},
+			{Number: 10, Branch: "feature/unrelated", State: PullOpen},
+		},
+		comments: map[int][]PullComment{7: {
+			{ID: "line:2", Author: "bob", Body: "Check for nil here.", Path: "api.go", Line: 12, Created: day.Add(time.Hour)},
+			{ID: "review:1", Author: "alice", Body: "Needs tests.", Created: day},
+			{ID: "review:3", Author: "carol", Body: "  ", Created: day},
+		}},
+	}
+
+	result, err := SyncPulls(context.Background(), dd, src)
+	if err != nil {
+		t.Fatalf("SyncPulls: %v", err)
+	}
+	if result.Comments != 2 {
+		t.Errorf("comments = %d, want 2", result.Comments)
+	}
+	if len(src.fetched) != 1 || src.fetched[0] != 7 {
+		t.Errorf("fetched comments of %v, want only the open pull request of the task in review", src.fetched)
+	}
+	if len(result.Drifted) != 2 ||
+		result.Drifted[0].Task != "early-merge" || result.Drifted[0].Pull.State != PullMerged ||
+		result.Drifted[1].Task != "shipped" || result.Drifted[1].State != design.StateCompleted {
+		t.Errorf("drifted = %+v", result.Drifted)
+	}
+
+	task, err := dd.FindTaskByState("backend/add-api", design.StateReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments, err := task.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "From @alice on pull request #7:\n\nNeeds tests.\n\nFrom @bob on pull request #7 on `api.go:12`:\n\nCheck for nil here.\n"
+	if comments != want {
+		t.Errorf("comments file = %q, want %q", comments, want)
+	}
+
+	// A second sync adds nothing new.
+	result, err = SyncPulls(context.Background(), dd, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Comments != 0 {
+		t.Errorf("second sync added %d comments, want 0", result.Comments)
+	}
+}
+
+func TestSyncPullsKeepsProgressOnError(t *testing.T) {
+	designDir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		full := filepath.Join(designDir, "state", "review", name+".md")
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("Task."), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dd, err := design.NewDir(designDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &mockPullSource{
+		pulls: []PullRequest{
+			{Number: 1, Branch: "hydra/a", State: PullOpen},
+			{Number: 2, Branch: "hydra/b", State: PullOpen},
+		},
+		comments: map[int][]PullComment{1: {{ID: "comment:1", Author: "alice", Body: "Fix this."}}},
+		errs:     map[int]error{2: errors.New("rate limited")},
+	}
+	if _, err := SyncPulls(context.Background(), dd, src); err == nil {
+		t.Fatal("expected the failed fetch to be reported")
+	}
+
+	// The comment copied before the failure is not copied again.
+	src.errs = nil
+	result, err := SyncPulls(context.Background(), dd, src)
+	if err != nil {
+		t.Fatalf("SyncPulls: %v", err)
+	}
+	if result.Comments != 0 {
+		t.Errorf("retry added %d comments, want 0", result.Comments)
+	}
+}
+
+func TestGitHubFetchPullsPaginates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("per_page = %q", r.URL.Query().Get("per_page"))
+		}
+		page := r.URL.Query().Get("page")
+		var body []map[string]any
+		switch r.URL.Path {
+		case "/repos/o/r/pulls":
+			n := 100
+			if page == "2" {
+				n = 1
+			}
+			for i := range n {
+				body = append(body, map[string]any{"number": i + 1, "state": "open", "head": map[string]string{"ref": "hydra/x"}})
+			}
+		case "/repos/o/r/issues/4/comments":
+			if page == "1" {
+				for i := range 100 {
+					body = append(body, map[string]any{"id": i + 1, "user": map[string]string{"login": "alice"}, "body": "Hi."})
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer ts.Close()
+
+	src := &GitHubSource{BaseURL: ts.URL, Owner: "o", Repo: "r"}
+	pulls, err := src.FetchPullRequests(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPullRequests: %v", err)
+	}
+	if len(pulls) != 101 {
+		t.Errorf("got %d pull requests, want both pages' 101", len(pulls))
+	}
+	comments, err := src.FetchPullComments(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("FetchPullComments: %v", err)
+	}
+	if len(comments) != 100 {
+		t.Errorf("got %d comments, want 100", len(comments))
+	}
+}
+
+func TestForgejoFetchPulls(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/pulls":
+			if r.URL.Query().Get("page") != "1" {
+				body = []map[string]any{}
+				break
+			}
+			body = []map[string]any{
+				{"number": 4, "state": "open", "head": map[string]string{"ref": "hydra/a"}},
+				{"number": 5, "state": "closed", "merged": true, "head": map[string]string{"ref": "hydra/b"}},
+				{"number": 6, "state": "closed", "head": map[string]string{"ref": "hydra/c"}},
+			}
+		case "/api/v1/repos/owner/repo/issues/4/comments":
+			body = []map[string]any{{"id": 1, "user": map[string]string{"login": "alice"}, "body": "Looks close."}}
+		case "/api/v1/repos/owner/repo/pulls/4/reviews":
+			body = []map[string]any{{"id": 2, "user": map[string]string{"login": "bob"}, "body": "Some changes."}}
+		case "/api/v1/repos/owner/repo/pulls/4/reviews/2/comments":
+			body = []map[string]any{{"id": 3, "user": map[string]string{"login": "bob"}, "body": "Rename this.", "path": "a.go", "position": 9}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer ts.Close()
+
+	src := NewForgejoSource(ts.URL, "owner", "repo", "test-token")
+	pulls, err := src.FetchPullRequests(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPullRequests: %v", err)
+	}
+	if len(pulls) != 3 || pulls[0].State != PullOpen || pulls[1].State != PullMerged || pulls[2].State != PullClosed || pulls[0].Branch != "hydra/a" {
+		t.Errorf("pulls = %+v", pulls)
+	}
+
+	comments, err := src.FetchPullComments(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("FetchPullComments: %v", err)
+	}
+	var ids []string
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	if strings.Join(ids, ",") != "comment:1,review:2,line:3" {
+		t.Errorf("comment IDs = %v", ids)
+	}
+	if c := comments[2]; c.Author != "bob" || c.Path != "a.go" || c.Line != 9 {
+		t.Errorf("line comment = %+v", c)
+	}
+}
+
+func TestResolveSourceBitbucket(t *testing.T) {
+	for _, repoURL := range []string{
+		"https://bitbucket.org/team/repo.git",
+		"https://alice@bitbucket.org/team/repo.git",
+		"git@bitbucket.org:team/repo.git",
+	} {
+		src, err := ResolveSource(repoURL, "", "")
+		if err != nil {
+			t.Fatalf("ResolveSource(%q): %v", repoURL, err)
+		}
+		bb, ok := src.(*BitbucketSource)
+		if !ok {
+			t.Fatalf("ResolveSource(%q) = %T, want BitbucketSource", repoURL, src)
+		}
+		if bb.Workspace != "team" || bb.Repo != "repo" || bb.BaseURL != bitbucketAPI {
+			t.Errorf("ResolveSource(%q) = %+v", repoURL, bb)
+		}
+	}
+
+	src, err := ResolveSource("git@bitbucket.org:team/repo.git", "bitbucket", "")
+	if err != nil {
+		t.Fatalf("ResolveSource: %v", err)
+	}
+	if _, ok := src.(*BitbucketSource); !ok {
+		t.Errorf("explicit bitbucket api_type = %T, want BitbucketSource", src)
+	}
+
+	// Only Bitbucket Cloud is supported, so another host is an error
+	// rather than a source that talks to api.bitbucket.org.
+	if _, err := ResolveSource("https://git.example.com/team/repo.git", "bitbucket", ""); err == nil ||
+		!strings.Contains(err.Error(), "bitbucket.org") {
+		t.Errorf("explicit bitbucket api_type on another host = %v, want an error", err)
+	}
+
+	if _, _, ok := ParseBitbucketURL("https://gitea.example.com/team/repo.git"); ok {
+		t.Error("ParseBitbucketURL accepted a non-Bitbucket host")
+	}
+}
+
+func TestBitbucketFetchOpenIssues(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/team/repo/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		body := map[string]any{}
+		if r.URL.Query().Get("page") == "" {
+			if got := r.URL.Query().Get("q"); got != `state="new" OR state="open"` {
+				t.Errorf("q = %q", got)
+			}
+			body["values"] = []map[string]any{
+				{"id": 1, "title": "Crash", "kind": "bug", "content": map[string]string{"raw": "It crashes."},
+					"component": map[string]string{"name": "api"}, "milestone": map[string]string{"name": "v2"},
+					"links": map[string]any{"html": map[string]string{"href": "https://bitbucket.org/team/repo/issues/1"}}},
+				{"id": 2, "title": "Dark mode", "kind": "enhancement"},
+			}
+			body["next"] = ts.URL + r.URL.Path + "?page=2"
+		} else {
+			body["values"] = []map[string]any{{"id": 3, "title": "Slow", "kind": "bug"}}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer ts.Close()
+
+	src := &BitbucketSource{BaseURL: ts.URL, Workspace: "team", Repo: "repo", Token: "test-token"}
+	all, err := src.FetchOpenIssues(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("FetchOpenIssues: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d issues, want 3 across both pages", len(all))
+	}
+	first := all[0]
+	if first.Number != 1 || first.Body != "It crashes." || strings.Join(first.Labels, ",") != "bug,api" ||
+		first.URL != "https://bitbucket.org/team/repo/issues/1" || first.Milestone == nil || first.Milestone.Title != "v2" {
+		t.Errorf("first issue = %+v", first)
+	}
+
+	bugs, err := src.FetchOpenIssues(context.Background(), []string{"Bug"})
+	if err != nil {
+		t.Fatalf("FetchOpenIssues: %v", err)
+	}
+	if len(bugs) != 2 || bugs[0].Number != 1 || bugs[1].Number != 3 {
+		t.Errorf("bug issues = %+v", bugs)
+	}
+}
+
+func TestBitbucketCloseIssue(t *testing.T) {
+	var gotComment, gotState, gotAuth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		var body struct {
+			State   string `json:"state"`
+			Content struct {
+				Raw string `json:"raw"`
+			} `json:"content"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repositories/team/repo/issues/7/comments":
+			gotComment = body.Content.Raw
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/repositories/team/repo/issues/7":
+			gotState = body.State
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"message":"No such issue"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	src := &BitbucketSource{BaseURL: ts.URL, Workspace: "team", Repo: "repo", Token: "alice:app-pass"}
+	if err := src.CloseIssue(7, "Fixed in abc123"); err != nil {
+		t.Fatalf("CloseIssue: %v", err)
+	}
+	if gotComment != "Fixed in abc123" || gotState != "resolved" {
+		t.Errorf("comment = %q, state = %q", gotComment, gotState)
+	}
+	if !strings.HasPrefix(gotAuth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth for an app password", gotAuth)
+	}
+
+	err := src.ReopenIssue(8, "")
+	if err == nil || !strings.Contains(err.Error(), "No such issue") {
+		t.Errorf("ReopenIssue error = %v, want Bitbucket's message", err)
+	}
+}
+
+func TestBitbucketPulls(t *testing.T) {
+	var forkSources []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repositories/team/repo/pullrequests":
+			body = map[string]any{"values": []map[string]any{
+				{"id": 4, "state": "OPEN", "source": map[string]any{"branch": map[string]string{"name": "hydra/a"}}},
+				{"id": 5, "state": "MERGED", "source": map[string]any{"branch": map[string]string{"name": "hydra/b"}}},
+				{"id": 6, "state": "DECLINED", "source": map[string]any{"branch": map[string]string{"name": "hydra/c"}}},
+			}}
+		case r.URL.Path == "/repositories/team/repo/pullrequests/4/comments":
+			body = map[string]any{"values": []map[string]any{
+				{"id": 1, "user": map[string]string{"nickname": "alice"}, "content": map[string]string{"raw": "Looks close."}},
+				{"id": 2, "user": map[string]string{"nickname": "bob"}, "content": map[string]string{"raw": "Rename this."},
+					"inline": map[string]any{"path": "a.go", "to": 9}},
+				{"id": 3, "user": map[string]string{"nickname": "ci", "type": "app_user"}, "content": map[string]string{"raw": "Build passed."}},
+				{"id": 4, "user": map[string]string{"nickname": "bob"}, "deleted": true},
+			}}
+		case r.Method == http.MethodPost && r.URL.Path == "/repositories/team/repo/pullrequests":
+			var req struct {
+				Title  string `json:"title"`
+				Source struct {
+					Branch     struct{ Name string } `json:"branch"`
+					Repository *struct {
+						FullName string `json:"full_name"`
+					} `json:"repository"`
+				} `json:"source"`
+				Destination struct {
+					Branch struct{ Name string } `json:"branch"`
+				} `json:"destination"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Title != "Add API" || req.Source.Branch.Name != "hydra/a" || req.Destination.Branch.Name != "main" {
+				t.Errorf("create request = %+v", req)
+			}
+			if fork := req.Source.Repository; fork != nil {
+				forkSources = append(forkSources, fork.FullName)
+			}
+			w.WriteHeader(http.StatusCreated)
+			body = map[string]any{"id": 9, "state": "OPEN", "source": map[string]any{"branch": map[string]string{"name": "hydra/a"}},
+				"links": map[string]any{"html": map[string]string{"href": "https://bitbucket.org/team/repo/pull-requests/9"}}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer ts.Close()
+
+	src := &BitbucketSource{BaseURL: ts.URL, Workspace: "team", Repo: "repo"}
+	pulls, err := src.FetchPullRequests(context.Background())
+	if err != nil {
+		t.Fatalf("FetchPullRequests: %v", err)
+	}
+	if len(pulls) != 3 || pulls[0].State != PullOpen || pulls[1].State != PullMerged || pulls[2].State != PullClosed || pulls[0].Branch != "hydra/a" {
+		t.Errorf("pulls = %+v", pulls)
+	}
+
+	comments, err := src.FetchPullComments(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("FetchPullComments: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "comment:1" || comments[0].Author != "alice" {
+		t.Fatalf("comments = %+v, want alice's and bob's", comments)
+	}
+	if c := comments[1]; c.Path != "a.go" || c.Line != 9 {
+		t.Errorf("inline comment = %+v", c)
+	}
+
+	var creator PullCreator = src
+	pr, err := creator.CreatePullRequest(context.Background(), "hydra/a", "main", "Add API", "Implements the API.")
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if pr.Number != 9 || pr.State != PullOpen || pr.URL != "https://bitbucket.org/team/repo/pull-requests/9" {
+		t.Errorf("created pull request = %+v", pr)
+	}
+
+	if _, err := creator.CreatePullRequest(context.Background(), "me:hydra/a", "main", "Add API", "Implements the API."); err != nil {
+		t.Fatalf("CreatePullRequest from a fork: %v", err)
+	}
+	if len(forkSources) != 1 || forkSources[0] != "me/repo" {
+		t.Errorf("fork source repositories = %v, want [me/repo]", forkSources)
+	}
+}
@@ -0,0 +1,213 @@
+package issues
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+// Pull request states.
+const (
+	PullOpen   = "open"
+	PullClosed = "closed" // closed without merging
+	PullMerged = "merged"
+)
+
+// PullRequest is a pull request on the forge.
+type PullRequest struct {
+	Number int
+	Branch string // head branch, e.g. hydra/add-api
+	State  string // PullOpen, PullClosed, or PullMerged
+	URL    string
+}
+
+// PullComment is a comment a person left on a pull request: a
+// conversation comment, a review's summary, or a comment on a line.
+type PullComment struct {
+	ID      string // unique among the pull request's comments, e.g. "review:12"
+	Author  string
+	Body    string
+	Path    string // file a line comment is on; empty for other comments
+	Line    int
+	Created time.Time
+}
+
+// PullSource is implemented by sources whose forge also hosts the pull
+// requests opened from hydra's branches.
+type PullSource interface {
+	// FetchPullRequests returns the repository's pull requests, open and
+	// closed, newest first.
+	FetchPullRequests(ctx context.Context) ([]PullRequest, error)
+	// FetchPullComments returns the comments people left on a pull
+	// request. Comments from bots are left out where the forge marks them.
+	FetchPullComments(ctx context.Context, number int) ([]PullComment, error)
+}
+
+// PullCreator is implemented by sources that can open pull requests.
+type PullCreator interface {
+	// CreatePullRequest opens a pull request from head into base. head is
+	// a branch of the repository, or <owner>:<branch> for a branch of
+	// owner's fork of it.
+	CreatePullRequest(ctx context.Context, head, base, title, body string) (*PullRequest, error)
+}
+
+// pullsFile records which pull request comments hydra sync has copied into
+// comments files, by pull request number, so each is copied once.
+const pullsFile = "pulls.json"
+
+// DriftedPull is a task whose pull request's state disagrees with the
+// task's own: merged or closed while the task waits in review or merge, or
+// still open after the task was completed or abandoned.
+type DriftedPull struct {
+	Task  string
+	State design.TaskState
+	Pull  PullRequest
+}
+
+// PullSyncResult holds what SyncPulls found.
+type PullSyncResult struct {
+	Comments int // comments added to tasks' comments files
+	Drifted  []DriftedPull
+}
+
+// SyncPulls maps pull requests whose head branch is a task's hydra/*
+// branch back to the task. Comments people left on the open pull request of
+// a task in review are added to the task's comments file, which its next
+// review session is given; each comment is added once. Tasks whose pull
+// requests were merged or closed outside hydra, or are still open after the
+// task left review, are returned as drifted.
+func SyncPulls(ctx context.Context, dd *design.Dir, source PullSource) (_ *PullSyncResult, err error) {
+	pulls, err := source.FetchPullRequests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching pull requests: %w", err)
+	}
+	// A branch may have several pull requests; the open one, or else the
+	// newest, speaks for it.
+	byBranch := make(map[string]PullRequest)
+	for _, pr := range pulls {
+		if !strings.HasPrefix(pr.Branch, "hydra/") {
+			continue
+		}
+		if cur, ok := byBranch[pr.Branch]; !ok || (cur.State != PullOpen && pr.State == PullOpen) {
+			byBranch[pr.Branch] = pr
+		}
+	}
+	if len(byBranch) == 0 {
+		return &PullSyncResult{}, nil
+	}
+
+	tasks, err := dd.AllTasks()
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+	seen, err := loadPullsSeen(dd.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PullSyncResult{}
+	changed := false
+	// Comments already added are recorded even if a later pull request
+	// fails, so the next sync doesn't add them again.
+	defer func() {
+		if !changed {
+			return
+		}
+		if saveErr := savePullsSeen(dd, seen); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}()
+	for i := range tasks {
+		task := &tasks[i]
+		pr, ok := byBranch[task.BranchName()]
+		if !ok {
+			continue
+		}
+		label := task.Label()
+
+		switch {
+		case pr.State != PullOpen && (task.State == design.StateReview || task.State == design.StateMerge),
+			pr.State == PullOpen && (task.State == design.StateCompleted || task.State == design.StateAbandoned):
+			result.Drifted = append(result.Drifted, DriftedPull{Task: label, State: task.State, Pull: pr})
+			continue
+		case pr.State != PullOpen || task.State != design.StateReview:
+			continue
+		}
+
+		comments, err := source.FetchPullComments(ctx, pr.Number)
+		if err != nil {
+			return nil, fmt.Errorf("fetching comments of pull request #%d: %w", pr.Number, err)
+		}
+		slices.SortStableFunc(comments, func(a, b PullComment) int { return a.Created.Compare(b.Created) })
+		key := strconv.Itoa(pr.Number)
+		for _, c := range comments {
+			if strings.TrimSpace(c.Body) == "" || slices.Contains(seen[key], c.ID) {
+				continue
+			}
+			if err := dd.AddComment(task, formatPullComment(pr, c)); err != nil {
+				return nil, err
+			}
+			seen[key] = append(seen[key], c.ID)
+			result.Comments++
+			changed = true
+		}
+	}
+
+	slices.SortFunc(result.Drifted, func(a, b DriftedPull) int { return cmp.Compare(a.Task, b.Task) })
+	return result, nil
+}
+
+// formatPullComment renders a pull request comment for a comments file.
+func formatPullComment(pr PullRequest, c PullComment) string {
+	where := ""
+	switch {
+	case c.Path != "" && c.Line > 0:
+		where = fmt.Sprintf(" on `%s:%d`", c.Path, c.Line)
+	case c.Path != "":
+		where = fmt.Sprintf(" on `%s`", c.Path)
+	}
+	return fmt.Sprintf("From @%s on pull request #%d%s:\n\n%s", c.Author, pr.Number, where, strings.TrimSpace(c.Body))
+}
+
+// loadPullsSeen reads the comment IDs already copied, by pull request
+// number.
+func loadPullsSeen(designDir string) (map[string][]string, error) {
+	seen := make(map[string][]string)
+	data, err := os.ReadFile(filepath.Join(designDir, "state", pullsFile)) //nolint:gosec // path is inside the design directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return seen, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", pullsFile, err)
+	}
+	if err := json.Unmarshal(data, &seen); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", pullsFile, err)
+	}
+	return seen, nil
+}
+
+// savePullsSeen writes the comment IDs copied so far.
+func savePullsSeen(dd *design.Dir, seen map[string][]string) error {
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return err
+	}
+	stateDir := filepath.Join(dd.Path, "state")
+	if err := os.MkdirAll(stateDir, 0o750); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(stateDir, pullsFile), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", pullsFile, err)
+	}
+	dd.Changed([]string{filepath.Join(stateDir, pullsFile)}, "record synced pull request comments")
+	return nil
+}
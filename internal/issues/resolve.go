@@ -2,38 +2,46 @@ package issues
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
 // ResolveSource determines the issue source from a repo URL and optional overrides.
+// giteaURL overrides the API base URL of a Gitea or Forgejo instance.
 func ResolveSource(repoURL, apiType, giteaURL string) (Source, error) {
 	giteaToken := ""
 
 	// Explicit api_type override.
-	if apiType == "github" {
+	switch apiType {
+	case "github":
 		owner, repo, ok := ParseGitHubURL(repoURL)
 		if !ok {
 			return nil, fmt.Errorf("cannot parse GitHub owner/repo from %q", repoURL)
 		}
 		return NewGitHubSource(owner, repo), nil
-	}
-	if apiType == "gitea" {
-		baseURL := giteaURL
-		if baseURL == "" {
-			var owner, repo string
-			var ok bool
-			baseURL, owner, repo, ok = ParseGiteaURL(repoURL)
-			if !ok {
-				return nil, fmt.Errorf("cannot parse Gitea URL from %q", repoURL)
-			}
-			return NewGiteaSource(baseURL, owner, repo, giteaToken), nil
+	case "gitea":
+		baseURL, owner, repo, err := parseForgeURL(repoURL, giteaURL, "Gitea")
+		if err != nil {
+			return nil, err
+		}
+		return NewGiteaSource(baseURL, owner, repo, giteaToken), nil
+	case "forgejo":
+		baseURL, owner, repo, err := parseForgeURL(repoURL, giteaURL, "Forgejo")
+		if err != nil {
+			return nil, err
 		}
-		// Parse owner/repo from URL even when base URL is overridden.
-		_, owner, repo, ok := ParseGiteaURL(repoURL)
+		return NewForgejoSource(baseURL, owner, repo, ""), nil
+	case "bitbucket":
+		// Only Bitbucket Cloud is supported, whose API is at
+		// api.bitbucket.org whatever the remote's host.
+		workspace, repo, ok := ParseBitbucketURL(repoURL)
 		if !ok {
-			return nil, fmt.Errorf("cannot parse owner/repo from %q", repoURL)
+			if _, _, _, parsed := ParseGiteaURL(repoURL); parsed {
+				return nil, fmt.Errorf("api_type bitbucket supports only Bitbucket Cloud, but %q is not on bitbucket.org", repoURL)
+			}
+			return nil, fmt.Errorf("cannot parse Bitbucket workspace/repo from %q", repoURL)
 		}
-		return NewGiteaSource(baseURL, owner, repo, giteaToken), nil
+		return NewBitbucketSource(workspace, repo, ""), nil
 	}
 
 	// Auto-detect: if URL contains github.com, use GitHub.
@@ -45,14 +53,58 @@ func ResolveSource(repoURL, apiType, giteaURL string) (Source, error) {
 		return NewGitHubSource(owner, repo), nil
 	}
 
-	// Default to Gitea for non-GitHub hosts.
+	if workspace, repo, ok := ParseBitbucketURL(repoURL); ok {
+		return NewBitbucketSource(workspace, repo, ""), nil
+	}
+
+	// Default to Gitea for other hosts, unless the host is known to run Forgejo.
 	baseURL, owner, repo, ok := ParseGiteaURL(repoURL)
 	if !ok {
 		return nil, fmt.Errorf("cannot determine issue source from %q; set api_type in hydra.yml", repoURL)
 	}
+	if IsForgejoHost(baseURL) {
+		return NewForgejoSource(baseURL, owner, repo, ""), nil
+	}
 	return NewGiteaSource(baseURL, owner, repo, giteaToken), nil
 }
 
+// parseForgeURL returns the API base URL, owner, and repo of a Gitea or
+// Forgejo repository. A non-empty override replaces the base URL parsed from
+// repoURL.
+func parseForgeURL(repoURL, override, kind string) (baseURL, owner, repo string, err error) {
+	baseURL, owner, repo, ok := ParseGiteaURL(repoURL)
+	if !ok {
+		if override != "" {
+			return "", "", "", fmt.Errorf("cannot parse owner/repo from %q", repoURL)
+		}
+		return "", "", "", fmt.Errorf("cannot parse %s URL from %q", kind, repoURL)
+	}
+	if override != "" {
+		baseURL = override
+	}
+	return baseURL, owner, repo, nil
+}
+
+// forgejoHosts are public hosts known to run Forgejo.
+var forgejoHosts = []string{"codeberg.org"}
+
+// IsForgejoHost reports whether the instance at baseURL is known to run
+// Forgejo: a public Forgejo host such as codeberg.org, or a host whose name
+// contains "forgejo".
+func IsForgejoHost(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range forgejoHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return strings.Contains(host, "forgejo")
+}
+
 // ResolveCloser resolves a Closer from the source, if the source implements it.
 func ResolveCloser(source Source) Closer {
 	if closer, ok := source.(Closer); ok {
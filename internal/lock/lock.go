@@ -3,11 +3,13 @@ package lock
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
+
+	"github.com/erikh/hydra/internal/platform"
 )
 
 type lockData struct {
@@ -42,13 +44,21 @@ func New(hydraDir, taskName string) *Lock {
 	}
 }
 
+// ErrHeld is returned by Acquire when another live process holds the lock.
+var ErrHeld = errors.New("already running")
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string {
+	return l.path
+}
+
 // Acquire attempts to acquire the lock. It returns an error if another live process holds it.
 // Stale locks from dead processes are automatically cleaned up.
 func (l *Lock) Acquire() error {
 	existing, err := l.read()
 	if err == nil && existing != nil {
-		if processAlive(existing.PID) {
-			return fmt.Errorf("task %q is already running (PID %d)", existing.TaskName, existing.PID)
+		if platform.ProcessAlive(existing.PID) {
+			return fmt.Errorf("task %q is %w (PID %d)", existing.TaskName, ErrHeld, existing.PID)
 		}
 		// Stale lock, remove it.
 		if err := os.Remove(l.path); err != nil {
@@ -85,7 +95,7 @@ func (l *Lock) IsHeld() bool {
 	if err != nil || existing == nil {
 		return false
 	}
-	return processAlive(existing.PID)
+	return platform.ProcessAlive(existing.PID)
 }
 
 func (l *Lock) read() (*lockData, error) {
@@ -123,7 +133,7 @@ func ReadAll(hydraDir string) ([]RunningTask, error) {
 			continue
 		}
 
-		if processAlive(ld.PID) {
+		if platform.ProcessAlive(ld.PID) {
 			running = append(running, RunningTask{TaskName: ld.TaskName, PID: ld.PID})
 		}
 	}
@@ -131,11 +141,74 @@ func ReadAll(hydraDir string) ([]RunningTask, error) {
 	return running, nil
 }
 
-func processAlive(pid int) bool {
-	process, err := os.FindProcess(pid)
+// Shared is a shared lock: any number of processes may hold it at once,
+// each with a lock file of its own. It marks something as in use, such as
+// a work directory served by hydra review dev, so that workflows which
+// would disturb it can refuse or wait while it has holders.
+type Shared struct {
+	path string
+}
+
+// sharedPattern returns the glob matching every holder's lock file for
+// name. File names can't tell "a" from "a-b", so holders are matched on the
+// name recorded inside the file.
+func sharedPattern(hydraDir, name string) string {
+	safe := strings.ReplaceAll(name, "/", "--")
+	return filepath.Join(hydraDir, "inuse-"+safe+"-*.lock")
+}
+
+// AcquireShared takes the shared lock name for this process. A process
+// holds it at most once: acquiring it again reuses the same lock file.
+func AcquireShared(hydraDir, name string) (*Shared, error) {
+	safe := strings.ReplaceAll(name, "/", "--")
+	path := filepath.Join(hydraDir, fmt.Sprintf("inuse-%s-%d.lock", safe, os.Getpid()))
+
+	data, err := json.Marshal(&lockData{PID: os.Getpid(), TaskName: name})
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("marshaling lock data: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("writing lock file: %w", err)
+	}
+	return &Shared{path: path}, nil
+}
+
+// Release removes this process's lock file.
+func (s *Shared) Release() error {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing lock file: %w", err)
+	}
+	return nil
+}
+
+// SharedHolders returns the live processes holding the shared lock name.
+// Lock files left behind by dead processes are removed.
+func SharedHolders(hydraDir, name string) ([]RunningTask, error) {
+	matches, err := filepath.Glob(sharedPattern(hydraDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("globbing lock files: %w", err)
+	}
+
+	var holders []RunningTask
+	for _, path := range matches {
+		data, err := os.ReadFile(path) //nolint:gosec // lock files in hydra dir
+		if err != nil {
+			continue
+		}
+
+		var ld lockData
+		if err := json.Unmarshal(data, &ld); err != nil || ld.TaskName != name {
+			continue
+		}
+
+		if !platform.ProcessAlive(ld.PID) {
+			if err := os.Remove(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not remove stale lock %s: %v\n", path, err)
+			}
+			continue
+		}
+		holders = append(holders, RunningTask{TaskName: ld.TaskName, PID: ld.PID})
 	}
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+
+	return holders, nil
 }
@@ -2,6 +2,7 @@ package lock
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -56,8 +57,8 @@ func TestAcquireBlockedBySameTask(t *testing.T) {
 	// Second lock with the same task name should fail.
 	lk2 := New(dir, "task-1")
 	err := lk2.Acquire()
-	if err == nil {
-		t.Fatal("expected error when same task lock is held by live process")
+	if !errors.Is(err, ErrHeld) {
+		t.Fatalf("Acquire = %v, want ErrHeld when same task lock is held by live process", err)
 	}
 
 	must(t, lk1.Release())
@@ -198,3 +199,62 @@ func TestLockFileNameGroupedTask(t *testing.T) {
 		t.Errorf("lockFileName = %q, want hydra-backend--add-api.lock", name)
 	}
 }
+
+func TestSharedLock(t *testing.T) {
+	dir := t.TempDir()
+
+	s1, err := AcquireShared(dir, "backend/add-api")
+	must(t, err)
+	// A second holder in the same process shares the lock file.
+	s2, err := AcquireShared(dir, "backend/add-api")
+	must(t, err)
+
+	holders, err := SharedHolders(dir, "backend/add-api")
+	must(t, err)
+	if len(holders) != 1 || holders[0].PID != os.Getpid() {
+		t.Errorf("holders = %+v, want this process", holders)
+	}
+
+	// Names that share a prefix don't see each other's holders.
+	holders, err = SharedHolders(dir, "backend/add")
+	must(t, err)
+	if len(holders) != 0 {
+		t.Errorf("holders of backend/add = %+v, want none", holders)
+	}
+
+	// Shared locks don't show up as running tasks.
+	tasks, err := ReadAll(dir)
+	must(t, err)
+	if len(tasks) != 0 {
+		t.Errorf("ReadAll = %+v, want none", tasks)
+	}
+
+	must(t, s1.Release())
+	must(t, s2.Release())
+	holders, err = SharedHolders(dir, "backend/add-api")
+	must(t, err)
+	if len(holders) != 0 {
+		t.Errorf("holders after Release = %+v, want none", holders)
+	}
+}
+
+func TestSharedHoldersStale(t *testing.T) {
+	dir := t.TempDir()
+
+	stalePID := 4194304
+	data, err := json.Marshal(&lockData{PID: stalePID, TaskName: "stale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "inuse-stale-4194304.lock")
+	must(t, os.WriteFile(path, data, 0o600))
+
+	holders, err := SharedHolders(dir, "stale")
+	must(t, err)
+	if len(holders) != 0 {
+		t.Errorf("holders = %+v, want none", holders)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("stale shared lock file was not removed")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !linux && !windows
+
+// Package notify provides desktop notification support.
+package notify
+
+import "errors"
+
+// Send reports that desktop notifications aren't supported on this
+// platform.
+func Send(_, _ string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,28 @@
+// Package notify provides desktop notification support.
+package notify
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// toastScript shows a toast with the title and message from the
+// environment, so neither needs quoting for PowerShell. Toasts are raised
+// under PowerShell's own app ID, which Windows always accepts.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:HYDRA_NOTIFY_TITLE)) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode($env:HYDRA_NOTIFY_MESSAGE)) | Out-Null
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($template))
+`
+
+// Send sends a desktop notification as a Windows toast, via PowerShell.
+func Send(title, message string) error {
+	cmd := exec.CommandContext(context.Background(), "powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(), "HYDRA_NOTIFY_TITLE="+title, "HYDRA_NOTIFY_MESSAGE="+message)
+	return cmd.Run()
+}
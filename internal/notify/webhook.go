@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notification channels hydra notify can route to.
+const (
+	ChannelDesktop = "desktop" // the notify command from hydra.yml, or the platform's notifications
+	ChannelSlack   = "slack"   // a Slack incoming webhook
+	ChannelWebhook = "webhook" // a JSON POST to any URL
+)
+
+// Channels lists every notification channel, in the order they are tried.
+var Channels = []string{ChannelDesktop, ChannelSlack, ChannelWebhook}
+
+// postTimeout bounds how long a webhook notification may take.
+const postTimeout = 10 * time.Second
+
+// SendSlack posts a notification to a Slack incoming webhook URL.
+func SendSlack(ctx context.Context, webhookURL, title, message string) error {
+	return postJSON(ctx, webhookURL, map[string]string{"text": "*" + title + "*\n" + message})
+}
+
+// SendWebhook posts a notification to a URL as a JSON object with title and
+// message fields.
+func SendWebhook(ctx context.Context, webhookURL, title, message string) error {
+	return postJSON(ctx, webhookURL, map[string]string{"title": title, "message": message})
+}
+
+// postJSON posts v as JSON to url and checks for a 2xx response.
+func postJSON(ctx context.Context, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL from trusted config
+	if err != nil {
+		return fmt.Errorf("posting notification: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting notification: %s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
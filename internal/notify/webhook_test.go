@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendWebhooks(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	if err := SendSlack(context.Background(), srv.URL, "hydra", "done"); err != nil {
+		t.Fatalf("SendSlack: %v", err)
+	}
+	if got["text"] != "*hydra*\ndone" {
+		t.Errorf("slack payload = %v", got)
+	}
+
+	if err := SendWebhook(context.Background(), srv.URL, "hydra", "done"); err != nil {
+		t.Fatalf("SendWebhook: %v", err)
+	}
+	if got["title"] != "hydra" || got["message"] != "done" {
+		t.Errorf("webhook payload = %v", got)
+	}
+
+	if err := SendWebhook(context.Background(), srv.URL+"/fail", "hydra", "done"); err == nil {
+		t.Error("SendWebhook succeeded on a 403")
+	}
+}
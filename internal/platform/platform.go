@@ -0,0 +1,4 @@
+// Package platform wraps the process and file operations that differ between
+// Unix and Windows: process liveness checks, advisory file locks, detaching
+// background processes, asking a process to stop, and waiting for input.
+package platform
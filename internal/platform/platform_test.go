@@ -0,0 +1,50 @@
+package platform
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProcessAlive(t *testing.T) {
+	if !ProcessAlive(os.Getpid()) {
+		t.Error("ProcessAlive(own PID) = false")
+	}
+	for _, pid := range []int{0, -1, 1 << 30} {
+		if ProcessAlive(pid) {
+			t.Errorf("ProcessAlive(%d) = true", pid)
+		}
+	}
+}
+
+func TestLockDir(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockDir(dir)
+	if err != nil {
+		t.Fatalf("LockDir: %v", err)
+	}
+
+	var second atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unlock2, err := LockDir(dir)
+		if err != nil {
+			t.Errorf("second LockDir: %v", err)
+			return
+		}
+		second.Store(true)
+		unlock2()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if second.Load() {
+		t.Fatal("second LockDir did not wait for the first to unlock")
+	}
+	unlock()
+	<-done
+	if !second.Load() {
+		t.Error("second LockDir never got the lock")
+	}
+}
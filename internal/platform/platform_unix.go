@@ -0,0 +1,70 @@
+//go:build unix
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ProcessAlive reports whether a process with the given PID is running.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	return syscall.Kill(pid, 0) == nil
+}
+
+// LockFile takes an exclusive advisory lock on f, blocking until it is free.
+func LockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX) //nolint:gosec // fd fits in an int
+}
+
+// UnlockFile releases a lock taken with LockFile.
+func UnlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:gosec // fd fits in an int
+}
+
+// LockDir takes an exclusive advisory lock on dir, blocking until it is
+// free, and returns a function that releases it.
+func LockDir(dir string) (unlock func(), err error) {
+	f, err := os.Open(dir) //nolint:gosec // callers lock their own directories
+	if err != nil {
+		return nil, err
+	}
+	if err := LockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = UnlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// Detach makes cmd start in a session of its own, so it keeps running after
+// the terminal that started it closes.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// Terminate asks p to stop by sending it SIGTERM.
+func Terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
+
+// WaitReadable waits up to timeout for f to have input to read, so a read
+// can be given up on instead of blocking.
+func WaitReadable(f *os.File, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLIN}} //nolint:gosec // fd fits in an int32
+	n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	if errors.Is(err, unix.EINTR) {
+		return false, nil
+	}
+	return n > 0, err
+}
@@ -0,0 +1,94 @@
+//go:build windows
+
+package platform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a process
+// that has not exited.
+const stillActive = 259
+
+// ProcessAlive reports whether a process with the given PID is running.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid)) //nolint:gosec // PIDs fit in a uint32
+	if err != nil {
+		// The process exists but belongs to someone we can't query.
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer func() { _ = windows.CloseHandle(h) }()
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// LockFile takes an exclusive lock on f, blocking until it is free.
+func LockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
+
+// UnlockFile releases a lock taken with LockFile.
+func UnlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &windows.Overlapped{})
+}
+
+// LockDir takes an exclusive lock on dir, blocking until it is free, and
+// returns a function that releases it. Windows can't lock a directory, so
+// a lock file named after the directory's path is locked in its place,
+// under the temporary directory so nothing is added to dir itself.
+func LockDir(dir string) (unlock func(), err error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(abs)))
+	path := filepath.Join(os.TempDir(), "hydra-"+hex.EncodeToString(sum[:8])+".lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // path built from a hash
+	if err != nil {
+		return nil, err
+	}
+	if err := LockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = UnlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// Detach makes cmd start detached from the console that started it, in a
+// process group of its own, so it keeps running after the console closes.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+}
+
+// Terminate stops p. Windows has no SIGTERM to send a process, so it is
+// killed.
+func Terminate(p *os.Process) error {
+	return p.Kill()
+}
+
+// WaitReadable would wait for f to have input to read. Console handles
+// can't be waited on for whole lines, so it is unsupported on Windows.
+func WaitReadable(*os.File, time.Duration) (bool, error) {
+	return false, errors.ErrUnsupported
+}
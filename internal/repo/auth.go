@@ -0,0 +1,240 @@
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/erikh/hydra/internal/credstore"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/mattn/go-isatty"
+	"go.yaml.in/yaml/v4"
+)
+
+// AuthConfig configures authentication for git remotes. It is read from
+// ~/.hydra.yml.
+type AuthConfig struct {
+	SSH SSHConfig `yaml:"ssh"`
+
+	// GitToken authenticates to HTTPS remotes. HYDRA_GIT_TOKEN and the
+	// credential store (hydra auth login git) take precedence.
+	GitToken string `yaml:"git_token"`
+	// GitUsername is sent with GitToken; defaults to x-access-token.
+	GitUsername string `yaml:"git_username"`
+	// GitTokenHosts are the hosts GitToken is sent to; defaults to
+	// github.com.
+	GitTokenHosts []string `yaml:"git_token_hosts"`
+}
+
+// SSHConfig configures SSH authentication for git remotes. Without an
+// identity file, ssh-agent is used.
+type SSHConfig struct {
+	IdentityFile string `yaml:"identity_file"`
+}
+
+// defaultGitUsername is the username sent with an HTTPS token. GitHub
+// ignores it and GitLab accepts any non-empty name with a personal access
+// token.
+const defaultGitUsername = "x-access-token"
+
+// defaultGitTokenHost is the host the git token is sent to when
+// git_token_hosts isn't set.
+const defaultGitTokenHost = "github.com"
+
+// LoadAuthConfig reads git remote authentication settings from ~/.hydra.yml.
+// A missing or unreadable file means ssh-agent is used for SSH remotes and
+// HTTPS remotes rely on git's own credential helpers. A leading ~/ in the
+// SSH identity file is expanded to the home directory.
+func LoadAuthConfig() AuthConfig {
+	var cfg AuthConfig
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return cfg
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".hydra.yml")) //nolint:gosec // well-known user config path
+	if err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			cfg = AuthConfig{}
+		}
+	}
+	if rest, ok := strings.CutPrefix(cfg.SSH.IdentityFile, "~/"); ok {
+		cfg.SSH.IdentityFile = filepath.Join(home, rest)
+	}
+	return cfg
+}
+
+// authConfig is the remote authentication configuration, loaded once per
+// process with the git token resolved.
+var authConfig = sync.OnceValue(func() AuthConfig {
+	cfg := LoadAuthConfig()
+	if token := credstore.Lookup(credstore.ProviderGit); token != "" {
+		cfg.GitToken = token
+	}
+	if cfg.GitUsername == "" {
+		cfg.GitUsername = defaultGitUsername
+	}
+	if len(cfg.GitTokenHosts) == 0 {
+		cfg.GitTokenHosts = []string{defaultGitTokenHost}
+	}
+	return cfg
+})
+
+// tokenURL parses raw and reports whether the git token in cfg may be sent
+// to it: only over HTTPS, and only to the hosts in git_token_hosts.
+func (cfg AuthConfig) tokenURL(raw string) (*url.URL, bool) {
+	if cfg.GitToken == "" {
+		return nil, false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, false
+	}
+	for _, host := range cfg.GitTokenHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// sshKeyCache holds the decrypted identity file, so the passphrase is asked
+// for at most once per process.
+var sshKeyCache struct {
+	sync.Mutex
+	path string
+	auth *gitssh.PublicKeys
+}
+
+// sshAuth returns the auth method for SSH remotes: the configured identity
+// file, or ssh-agent if none is configured.
+func sshAuth() (transport.AuthMethod, error) {
+	if path := authConfig().SSH.IdentityFile; path != "" {
+		return identityFileAuth(path)
+	}
+	return gitssh.NewSSHAgentAuth("git")
+}
+
+// identityFileAuth loads a private key for go-git. An encrypted key is
+// unlocked with the passphrase from HYDRA_SSH_PASSPHRASE or the credential
+// store (hydra auth login ssh), or, on a terminal, by prompting for it.
+func identityFileAuth(path string) (transport.AuthMethod, error) {
+	sshKeyCache.Lock()
+	defer sshKeyCache.Unlock()
+	if sshKeyCache.path == path && sshKeyCache.auth != nil {
+		return sshKeyCache.auth, nil
+	}
+
+	pem, err := os.ReadFile(path) //nolint:gosec // identity file path from user config
+	if err != nil {
+		return nil, fmt.Errorf("reading SSH identity file: %w", err)
+	}
+
+	auth, err := gitssh.NewPublicKeys("git", pem, "")
+	if err != nil {
+		passphrase := credstore.Lookup(credstore.ProviderSSH)
+		if passphrase == "" {
+			passphrase, err = promptPassphrase(path, credstore.ProviderSSH)
+			if err != nil {
+				return nil, err
+			}
+		}
+		auth, err = gitssh.NewPublicKeys("git", pem, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("loading SSH identity file %s: %w", path, err)
+		}
+	}
+
+	sshKeyCache.path = path
+	sshKeyCache.auth = auth
+	return auth, nil
+}
+
+// promptPassphrase asks for the passphrase of an encrypted key file on the
+// terminal. provider is the credential store provider that could supply it
+// instead.
+func promptPassphrase(path, provider string) (string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("key file %s is encrypted; set %s or run hydra auth login %s",
+			path, credstore.EnvVar(provider), provider)
+	}
+	fmt.Fprintf(os.Stderr, "Passphrase for %s: ", path)
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// sshCommandEnv returns the GIT_SSH_COMMAND setting that makes git commands
+// run by the CLI fallback use the configured identity file, or "" if none is
+// configured or GIT_SSH_COMMAND is already set.
+func sshCommandEnv() string {
+	path := authConfig().SSH.IdentityFile
+	if path == "" || os.Getenv("GIT_SSH_COMMAND") != "" {
+		return ""
+	}
+	return "GIT_SSH_COMMAND=ssh -i " + shellQuote(path) + " -o IdentitiesOnly=yes"
+}
+
+// shellQuote quotes s for use as one word in a shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// httpsAuth returns basic auth carrying the configured git token for the
+// remote at url, or nil if no token is configured or it isn't sent there.
+func httpsAuth(url string) transport.AuthMethod {
+	return httpsAuthFor(authConfig(), url)
+}
+
+func httpsAuthFor(cfg AuthConfig, url string) transport.AuthMethod {
+	if _, ok := cfg.tokenURL(url); !ok {
+		return nil
+	}
+	return &githttp.BasicAuth{Username: cfg.GitUsername, Password: cfg.GitToken}
+}
+
+// tokenEnv returns environment settings that make git commands send the
+// token in cfg to the HTTPS remotes in urls on the hosts in
+// git_token_hosts. The token is passed as an Authorization header scoped to
+// each remote's host through GIT_CONFIG_* variables, so it never appears in
+// command lines or in the repository's config. Returns nil if no token is
+// configured or GIT_CONFIG_COUNT is already in use.
+func tokenEnv(cfg AuthConfig, urls []string) []string {
+	if cfg.GitToken == "" || os.Getenv("GIT_CONFIG_COUNT") != "" {
+		return nil
+	}
+
+	header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.GitUsername+":"+cfg.GitToken))
+	var env []string
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		u, ok := cfg.tokenURL(raw)
+		if !ok {
+			continue
+		}
+		prefix := u.Scheme + "://" + u.Host + "/"
+		if seen[prefix] {
+			continue
+		}
+		seen[prefix] = true
+		n := len(seen) - 1
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s.extraHeader", n, prefix),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, header),
+		)
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	return append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(seen)))
+}
@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Bounds on the conflict context collected for a conflicted file, so a large
+// conflict can't swamp the document it is reported in.
+const (
+	maxConflictHunks   = 5  // hunks per file
+	maxHunkLines       = 40 // lines per side of a hunk
+	maxConflictCommits = 10 // upstream commits per file
+)
+
+// Conflict describes a file left conflicted by a stopped rebase.
+type Conflict struct {
+	File string
+
+	// Hunks are the file's conflicted regions, up to maxConflictHunks.
+	Hunks []ConflictHunk
+
+	// MoreHunks counts hunks left out of Hunks.
+	MoreHunks int
+
+	// Commits are the upstream commits since the merge base that touched
+	// the conflicted lines (or, failing that, the file), as "sha subject".
+	Commits []string
+}
+
+// ConflictHunk is one conflicted region, each side cut to maxHunkLines.
+type ConflictHunk struct {
+	// Upstream is the side being rebased onto.
+	Upstream string
+
+	// Task is the side of the task commit being replayed.
+	Task string
+
+	// Truncated reports whether either side was cut.
+	Truncated bool
+}
+
+// Conflicts describes each conflicted file while a rebase of head onto
+// upstream is stopped on conflicts. Context that can't be collected for a
+// file is left out rather than failing the whole list.
+func (r *Repo) Conflicts(upstream, head string) ([]Conflict, error) {
+	files, err := r.ConflictFiles()
+	if err != nil {
+		return nil, err
+	}
+	base, err := r.MergeBase(upstream, head)
+	if err != nil {
+		base = ""
+	}
+
+	conflicts := make([]Conflict, 0, len(files))
+	for _, file := range files {
+		c := Conflict{File: file}
+		if data, err := os.ReadFile(filepath.Join(r.Dir, file)); err == nil {
+			hunks := parseConflictHunks(string(data))
+			if len(hunks) > maxConflictHunks {
+				c.MoreHunks = len(hunks) - maxConflictHunks
+				hunks = hunks[:maxConflictHunks]
+			}
+			c.Hunks = hunks
+		}
+		if base != "" {
+			c.Commits = r.conflictCommits(upstream, base, file, c.Hunks)
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, nil
+}
+
+// conflictCommits returns the commits in base..upstream that touched the
+// upstream side of the hunks in file, or the file itself when the hunks
+// can't be located in upstream's version of it.
+func (r *Repo) conflictCommits(upstream, base, file string, hunks []ConflictHunk) []string {
+	rangeArg := base + ".." + upstream
+	limit := "-n" + strconv.Itoa(maxConflictCommits)
+
+	var commits []string
+	seen := make(map[string]bool)
+	add := func(out string) {
+		for line := range strings.SplitSeq(out, "\n") {
+			if line != "" && !seen[line] && len(commits) < maxConflictCommits {
+				seen[line] = true
+				commits = append(commits, line)
+			}
+		}
+	}
+
+	located := false
+	if content, err := r.run("show", upstream+":"+file); err == nil {
+		for _, h := range hunks {
+			if h.Truncated {
+				continue
+			}
+			start, end, ok := findLines(content, h.Upstream)
+			if !ok {
+				continue
+			}
+			located = true
+			lines := "-L" + strconv.Itoa(start) + "," + strconv.Itoa(end) + ":" + file
+			if out, err := r.run("log", "--format=%h %s", "-s", limit, lines, rangeArg); err == nil {
+				add(out)
+			}
+		}
+	}
+	if !located {
+		if out, err := r.run("log", "--format=%h %s", limit, rangeArg, "--", file); err == nil {
+			add(out)
+		}
+	}
+	return commits
+}
+
+// parseConflictHunks extracts the conflicted regions between conflict
+// markers in content. The diff3 base section, if present, is skipped.
+func parseConflictHunks(content string) []ConflictHunk {
+	const (
+		outside = iota
+		upstreamSide
+		baseSide
+		taskSide
+	)
+
+	var hunks []ConflictHunk
+	var upstream, task []string
+	state := outside
+	for line := range strings.SplitSeq(content, "\n") {
+		switch {
+		case state == outside && isMarker(line, "<<<<<<<"):
+			state = upstreamSide
+			upstream, task = nil, nil
+		case state == upstreamSide && isMarker(line, "|||||||"):
+			state = baseSide
+		case (state == upstreamSide || state == baseSide) && line == "=======":
+			state = taskSide
+		case state == taskSide && isMarker(line, ">>>>>>>"):
+			state = outside
+			u, uCut := capLines(upstream)
+			t, tCut := capLines(task)
+			hunks = append(hunks, ConflictHunk{Upstream: u, Task: t, Truncated: uCut || tCut})
+		case state == upstreamSide:
+			upstream = append(upstream, line)
+		case state == taskSide:
+			task = append(task, line)
+		}
+	}
+	return hunks
+}
+
+// isMarker reports whether line is the given conflict marker, alone or
+// followed by a label.
+func isMarker(line, marker string) bool {
+	rest, ok := strings.CutPrefix(line, marker)
+	return ok && (rest == "" || rest[0] == ' ')
+}
+
+// capLines joins lines, keeping at most maxHunkLines of them.
+func capLines(lines []string) (string, bool) {
+	if len(lines) > maxHunkLines {
+		return strings.Join(lines[:maxHunkLines], "\n"), true
+	}
+	return strings.Join(lines, "\n"), false
+}
+
+// findLines returns the 1-based line range at which block appears in
+// content. An empty block is never found.
+func findLines(content, block string) (start, end int, ok bool) {
+	if strings.TrimSpace(block) == "" {
+		return 0, 0, false
+	}
+	lines := strings.Split(content, "\n")
+	want := strings.Split(block, "\n")
+	for i := 0; i+len(want) <= len(lines); i++ {
+		match := true
+		for j, w := range want {
+			if lines[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i + 1, i + len(want), true
+		}
+	}
+	return 0, 0, false
+}
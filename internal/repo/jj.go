@@ -0,0 +1,281 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JJ is a Jujutsu repository colocated with git. Bookmarks, commits, and
+// rebases go through jj so its operation log stays consistent; fetches,
+// pushes, and read-only queries use the git repository underneath, which jj
+// keeps in sync.
+//
+// jj leaves git's HEAD detached at the working-copy commit's parent, so the
+// bookmark hydra is working on is remembered in .jj/hydra-branch.
+type JJ struct {
+	*Repo
+}
+
+// OpenJJ returns a JJ handle for an existing colocated repository.
+func OpenJJ(dir string) *JJ {
+	return &JJ{Repo: Open(dir)}
+}
+
+// CloneJJ clones url into dest as a Jujutsu repository colocated with git.
+func CloneJJ(url, dest string) (*JJ, error) {
+	j := &JJ{Repo: &Repo{Dir: filepath.Dir(dest)}}
+	if _, err := j.jj("git", "clone", "--colocate", url, dest); err != nil {
+		return nil, err
+	}
+	return OpenJJ(dest), nil
+}
+
+// Traced returns a copy of the repository whose jj and git commands are
+// traced as children of the span in ctx.
+func (j *JJ) Traced(ctx context.Context) VCS {
+	return &JJ{Repo: j.WithContext(ctx)}
+}
+
+func (j *JJ) jj(args ...string) (out string, err error) {
+	ctx, span := tracing.StartChild(j.Context(), "jj "+args[0], attribute.StringSlice("jj.args", args))
+	defer func() { tracing.End(span, err) }()
+
+	cmd := exec.CommandContext(ctx, "jj", append([]string{"--no-pager", "--color", "never"}, args...)...) //nolint:gosec // args are controlled internally
+	cmd.Dir = j.Dir
+	cmd.Env = append(os.Environ(), "JJ_EDITOR=true")
+	if env := sshCommandEnv(); env != "" {
+		cmd.Env = append(cmd.Env, env)
+	}
+	cmd.Env = append(cmd.Env, j.tokenEnv()...)
+	raw, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("jj %s: %w\n%s", args[0], err, raw)
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// branchFile is where the bookmark hydra is working on is remembered.
+func (j *JJ) branchFile() string {
+	return filepath.Join(j.Dir, ".jj", "hydra-branch")
+}
+
+func (j *JJ) setBranch(name string) error {
+	return os.WriteFile(j.branchFile(), []byte(name+"\n"), 0o600)
+}
+
+// resolve returns the commit ID of a git ref such as "origin/main", which jj
+// revsets spell differently.
+func (j *JJ) resolve(ref string) (string, error) {
+	return j.run("rev-parse", "--verify", ref+"^{commit}")
+}
+
+// CurrentBranch returns the bookmark hydra last checked out or created.
+func (j *JJ) CurrentBranch() (string, error) {
+	data, err := os.ReadFile(j.branchFile())
+	if err != nil {
+		return "", fmt.Errorf("no bookmark checked out: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// CreateBranch creates a bookmark at the working-copy commit's parent and
+// makes it current.
+func (j *JJ) CreateBranch(name string) error {
+	if _, err := j.jj("bookmark", "create", name, "-r", "@-"); err != nil {
+		return err
+	}
+	return j.setBranch(name)
+}
+
+// Checkout starts a new working-copy commit on top of the bookmark, or of
+// the remote branch of that name if there is no local bookmark yet, and
+// makes it current.
+func (j *JJ) Checkout(name string) error {
+	if !j.localBookmark(name) {
+		if _, err := j.jj("bookmark", "track", name+"@origin"); err != nil {
+			return err
+		}
+	}
+	if _, err := j.jj("new", revsetString(name)); err != nil {
+		return err
+	}
+	return j.setBranch(name)
+}
+
+// localBookmark reports whether a local bookmark named name exists.
+func (j *JJ) localBookmark(name string) bool {
+	out, err := j.jj("bookmark", "list", "-T", `name ++ "\n"`, name)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(strings.Split(out, "\n"), name)
+}
+
+// AddAll does nothing: jj snapshots the working copy, untracked files
+// included, before every command.
+func (j *JJ) AddAll() error {
+	return nil
+}
+
+// Commit describes the working-copy commit with message, starts a new one
+// on top of it, and moves the current bookmark to it. Signing follows jj's
+// own signing settings, so sign is not consulted.
+func (j *JJ) Commit(message string, _ bool) error {
+	if _, err := j.jj("commit", "-m", message); err != nil {
+		return err
+	}
+	if branch, err := j.CurrentBranch(); err == nil {
+		return j.syncBranch(branch)
+	}
+	return nil
+}
+
+// syncBranch moves the current bookmark to the working-copy commit's
+// parent, picking up commits made with git, which leave bookmarks behind.
+func (j *JJ) syncBranch(branch string) error {
+	if current, err := j.CurrentBranch(); err != nil || current != branch {
+		return nil
+	}
+	_, err := j.jj("bookmark", "set", branch, "-r", "@-", "--allow-backwards")
+	return err
+}
+
+// Push moves the bookmark up to any commits made with git and pushes it.
+func (j *JJ) Push(branch string) error {
+	if err := j.syncBranch(branch); err != nil {
+		return err
+	}
+	return j.Repo.Push(branch)
+}
+
+// ForcePushWithLease moves the bookmark up to any commits made with git and
+// force-pushes it.
+func (j *JJ) ForcePushWithLease(branch string) error {
+	if err := j.syncBranch(branch); err != nil {
+		return err
+	}
+	return j.Repo.ForcePushWithLease(branch)
+}
+
+// Clean removes untracked files with git clean, leaving ignored files, and
+// so the .jj directory, alone.
+func (j *JJ) Clean() error {
+	_, err := j.run("clean", "-fd")
+	return err
+}
+
+// ResetHard moves the current bookmark to ref and starts a new, empty
+// working-copy commit on it. Uncommitted changes stay in the abandoned
+// working-copy commit, where jj op log can find them.
+func (j *JJ) ResetHard(ref string) error {
+	target, err := j.resolve(ref)
+	if err != nil {
+		return fmt.Errorf("resolve %q: %w", ref, err)
+	}
+	if branch, err := j.CurrentBranch(); err == nil {
+		if _, err := j.jj("bookmark", "set", branch, "-r", target, "--allow-backwards"); err != nil {
+			return err
+		}
+	}
+	_, err = j.jj("new", target)
+	return err
+}
+
+// Rebase moves the current bookmark onto the given ref: it fast-forwards if
+// the bookmark is already behind ref, and otherwise rebases the bookmark's
+// commits with jj rebase. jj records conflicts in commits instead of
+// stopping, so a rebase that leaves any is undone and reported as an error,
+// as a failed git rebase would be.
+func (j *JJ) Rebase(onto string) error {
+	branch, err := j.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	target, err := j.resolve(onto)
+	if err != nil {
+		return fmt.Errorf("resolve %q: %w", onto, err)
+	}
+
+	if j.IsAncestor(branch, target) {
+		if _, err := j.jj("bookmark", "set", branch, "-r", target); err != nil {
+			return err
+		}
+		_, err := j.jj("new", target)
+		return err
+	}
+
+	if _, err := j.jj("rebase", "-b", revsetString(branch), "-d", target); err != nil {
+		return err
+	}
+	return j.finishRebase(branch, target, onto)
+}
+
+// RebaseOnto moves the current bookmark's commits after upstream onto the
+// given ref, leaving the commits up to upstream behind, like git rebase
+// --onto. A rebase that leaves conflicts is undone and reported as an
+// error.
+func (j *JJ) RebaseOnto(onto, upstream string) error {
+	branch, err := j.CurrentBranch()
+	if err != nil {
+		return err
+	}
+	target, err := j.resolve(onto)
+	if err != nil {
+		return fmt.Errorf("resolve %q: %w", onto, err)
+	}
+	base, err := j.resolve(upstream)
+	if err != nil {
+		return fmt.Errorf("resolve %q: %w", upstream, err)
+	}
+
+	if _, err := j.jj("rebase", "-s", fmt.Sprintf("roots(%s..%s)", base, revsetString(branch)), "-d", target); err != nil {
+		return err
+	}
+	return j.finishRebase(branch, target, onto)
+}
+
+// Revert is not supported in Jujutsu work directories; git revert would
+// move HEAD behind jj's back.
+func (j *JJ) Revert(_, _ string) error {
+	return errors.New("reverting commits is not supported with vcs: jj")
+}
+
+// CherryPick is not supported in Jujutsu work directories, for the same
+// reason as Revert.
+func (j *JJ) CherryPick(_, _ string) error {
+	return errors.New("cherry-picking commits is not supported with vcs: jj")
+}
+
+// finishRebase undoes a rebase of branch onto target that left conflicts,
+// reporting them as an error, and otherwise starts a new working-copy
+// commit on the rebased bookmark.
+func (j *JJ) finishRebase(branch, target, onto string) error {
+	conflicted, err := j.jj("log", "--no-graph", "-r", fmt.Sprintf("(%s..%s) & conflicts()", target, revsetString(branch)), "-T", `commit_id ++ "\n"`)
+	if err != nil {
+		return err
+	}
+	if conflicted != "" {
+		if _, err := j.jj("undo"); err != nil {
+			return fmt.Errorf("undoing conflicted rebase: %w", err)
+		}
+		return fmt.Errorf("rebase of %s onto %s has conflicts", branch, onto)
+	}
+	_, err = j.jj("new", revsetString(branch))
+	return err
+}
+
+// revsetString quotes name for use as a symbol in a jj revset, since
+// bookmark names like hydra/add-auth contain revset operators.
+func revsetString(name string) string {
+	return strconv.Quote(name)
+}
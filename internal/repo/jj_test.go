@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenVCS(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	if _, ok := OpenVCS(dir).(*Repo); !ok {
+		t.Errorf("OpenVCS of a git repo = %T, want *Repo", OpenVCS(dir))
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, ".jj"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := OpenVCS(dir).(*JJ); !ok {
+		t.Errorf("OpenVCS of a jj repo = %T, want *JJ", OpenVCS(dir))
+	}
+}
+
+// cloneJJ clones a remote with a main branch into a colocated jj repo,
+// skipping the test if jj is not installed.
+func cloneJJ(t *testing.T) (*JJ, string) {
+	t.Helper()
+	if _, err := exec.LookPath("jj"); err != nil {
+		t.Skip("jj not installed")
+	}
+	t.Setenv("JJ_USER", "Test")
+	t.Setenv("JJ_EMAIL", "test@test.com")
+
+	bare := initBareRemote(t)
+	local := initLocalRepo(t, bare)
+	gitRun(t, "-C", local, "branch", "-M", "main")
+	gitRun(t, "-C", local, "push", "origin", "main")
+	gitRun(t, "-C", bare, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	j, err := CloneJJ(bare, filepath.Join(t.TempDir(), "work"))
+	if err != nil {
+		t.Fatalf("CloneJJ: %v", err)
+	}
+	return j, local
+}
+
+func TestJJBranchCommitPush(t *testing.T) {
+	j, _ := cloneJJ(t)
+
+	if err := j.CreateBranch("hydra/add-auth"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if branch, err := j.CurrentBranch(); err != nil || branch != "hydra/add-auth" {
+		t.Fatalf("CurrentBranch = %q, %v", branch, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(j.Dir, "auth.go"), []byte("package auth\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := j.HasChanges(); err != nil || !changed {
+		t.Fatalf("HasChanges = %v, %v; want true", changed, err)
+	}
+	if err := j.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Commit("Add auth", false); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := j.Push("hydra/add-auth"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	head, err := j.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.CommandContext(context.Background(), "git", "-C", j.Dir, "rev-parse", "origin/hydra/add-auth").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != head {
+		t.Errorf("pushed %s, want HEAD %s", got, head)
+	}
+}
+
+func TestJJRebase(t *testing.T) {
+	j, local := cloneJJ(t)
+
+	if err := j.CreateBranch("hydra/feature"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(j.Dir, "feature.txt"), []byte("feature\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Commit("Add feature", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// main moves on without conflicting.
+	if err := os.WriteFile(filepath.Join(local, "other.txt"), []byte("other\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, "-C", local, "add", "-A")
+	gitRun(t, "-C", local, "commit", "-m", "Add other")
+	gitRun(t, "-C", local, "push", "origin", "main")
+
+	if err := j.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Rebase("origin/main"); err != nil {
+		t.Fatalf("Rebase: %v", err)
+	}
+	if !j.IsAncestor("origin/main", "hydra/feature") {
+		t.Error("hydra/feature should be rebased onto origin/main")
+	}
+	if _, err := os.Stat(filepath.Join(j.Dir, "other.txt")); err != nil {
+		t.Errorf("working copy should include main's change: %v", err)
+	}
+
+	// Fast-forwarding main to the branch, as a merge does.
+	if err := j.Checkout("main"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if err := j.Rebase("hydra/feature"); err != nil {
+		t.Fatalf("Rebase fast-forward: %v", err)
+	}
+	if !j.IsAncestor("hydra/feature", "main") || !j.IsAncestor("main", "hydra/feature") {
+		t.Error("main should be fast-forwarded to hydra/feature")
+	}
+}
@@ -8,16 +8,20 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/erikh/hydra/internal/tracing"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Repo represents a local git repository.
@@ -26,6 +30,9 @@ type Repo struct {
 	repo     *git.Repository
 	auth     transport.AuthMethod
 	authDone bool
+	env      []string
+	envDone  bool
+	ctx      context.Context //nolint:containedctx // carries the trace parent for git commands
 }
 
 // Clone clones a git repository from url into dest.
@@ -37,29 +44,65 @@ func Clone(url, dest string) (*Repo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("git clone: %w", err)
 	}
-	return &Repo{Dir: dest, repo: r}, nil
+	cloned := &Repo{Dir: dest, repo: r}
+	// The clone checks out origin's default branch; record it as origin's
+	// HEAD, which go-git doesn't.
+	if head, err := r.Head(); err == nil && head.Name().IsBranch() {
+		_ = cloned.setOriginHead(head.Name().Short())
+	}
+	return cloned, nil
 }
 
 // Open returns a Repo handle for an existing directory.
 // If the directory is not a valid git repo, the internal repo handle is left nil
 // and will be lazily opened by ensure().
 func Open(dir string) *Repo {
-	r, err := git.PlainOpen(dir)
+	r, err := plainOpen(dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not open git repo at %s: %v\n", dir, err)
 	}
 	return &Repo{Dir: dir, repo: r}
 }
 
-func (r *Repo) run(args ...string) (string, error) {
-	cmd := exec.CommandContext(context.Background(), "git", args...) //nolint:gosec // args are controlled internally
+// plainOpen opens the go-git repository at dir. Linked worktrees keep
+// their refs in the main repository's git dir, which go-git only reads when
+// asked to.
+func plainOpen(dir string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
+// WithContext returns a copy of the repo whose git commands are traced as
+// children of the span in ctx.
+func (r *Repo) WithContext(ctx context.Context) *Repo {
+	c := *r
+	c.ctx = ctx
+	return &c
+}
+
+// Context returns the context set by WithContext, or context.Background().
+func (r *Repo) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+func (r *Repo) run(args ...string) (out string, err error) {
+	ctx, span := tracing.StartChild(r.Context(), "git "+args[0], attribute.StringSlice("git.args", args))
+	defer func() { tracing.End(span, err) }()
+
+	cmd := exec.CommandContext(ctx, "git", args...) //nolint:gosec // args are controlled internally
 	cmd.Dir = r.Dir
 	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
-	out, err := cmd.CombinedOutput()
+	if env := sshCommandEnv(); env != "" {
+		cmd.Env = append(cmd.Env, env)
+	}
+	cmd.Env = append(cmd.Env, r.tokenEnv()...)
+	raw, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git %s: %w\n%s", args[0], err, out)
+		return "", fmt.Errorf("git %s: %w\n%s", args[0], err, raw)
 	}
-	return strings.TrimSpace(string(out)), nil
+	return strings.TrimSpace(string(raw)), nil
 }
 
 // ensure lazily opens the go-git repository if not already set.
@@ -67,7 +110,7 @@ func (r *Repo) ensure() error {
 	if r.repo != nil {
 		return nil
 	}
-	repo, err := git.PlainOpen(r.Dir)
+	repo, err := plainOpen(r.Dir)
 	if err != nil {
 		return fmt.Errorf("open repo: %w", err)
 	}
@@ -75,6 +118,31 @@ func (r *Repo) ensure() error {
 	return nil
 }
 
+// tokenEnv returns the environment that authenticates git commands to the
+// repository's HTTPS remotes with the configured git token. It is worked
+// out once per Repo, and again after ConfigurePushRemote changes the
+// remotes.
+func (r *Repo) tokenEnv() []string {
+	if r.envDone {
+		return r.env
+	}
+	cfg := authConfig()
+	if cfg.GitToken == "" || r.ensure() != nil {
+		return nil
+	}
+	r.envDone = true
+	remotes, err := r.repo.Remotes()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, remote := range remotes {
+		urls = append(urls, remote.Config().URLs...)
+	}
+	r.env = tokenEnv(cfg, urls)
+	return r.env
+}
+
 // isSSHURL returns true if the URL uses an SSH transport.
 func isSSHURL(url string) bool {
 	return strings.HasPrefix(url, "git@") || strings.HasPrefix(url, "ssh://")
@@ -91,28 +159,31 @@ func (r *Repo) resolveAuth() {
 		return
 	}
 	if isSSHURL(url) {
-		auth, err := gitssh.NewSSHAgentAuth("git")
+		auth, err := sshAuth()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not set up SSH agent auth: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: could not set up SSH auth: %v\n", err)
 			return
 		}
 		r.auth = auth
+	} else {
+		r.auth = httpsAuth(url)
 	}
 }
 
-// detectAuthFromURL returns SSH agent auth if the URL is an SSH remote.
+// detectAuthFromURL returns SSH auth if the URL is an SSH remote, or token
+// auth if it is an HTTPS remote and a git token is configured.
 func detectAuthFromURL(url string) transport.AuthMethod {
 	if isSSHURL(url) {
-		auth, err := gitssh.NewSSHAgentAuth("git")
+		auth, err := sshAuth()
 		if err != nil {
 			return nil
 		}
 		return auth
 	}
-	return nil
+	return httpsAuth(url)
 }
 
-// isHTTPS returns true if the origin remote uses HTTPS.
+// isHTTPS returns true if the origin remote uses HTTP(S).
 func (r *Repo) isHTTPS() bool {
 	url, err := r.RemoteURL()
 	if err != nil {
@@ -168,11 +239,19 @@ func (r *Repo) CreateBranch(name string) error {
 	})
 }
 
-// Checkout switches to an existing branch.
+// Checkout switches to an existing branch. Like git checkout, a branch that
+// only exists on origin is first created locally, tracking it.
 func (r *Repo) Checkout(name string) error {
 	if err := r.ensure(); err != nil {
 		return err
 	}
+	if _, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), false); err != nil {
+		if _, err := r.repo.Reference(plumbing.NewRemoteReferenceName("origin", name), false); err == nil {
+			if _, err := r.run("branch", "--track", name, "origin/"+name); err != nil {
+				return err
+			}
+		}
+	}
 	w, err := r.repo.Worktree()
 	if err != nil {
 		return fmt.Errorf("worktree: %w", err)
@@ -195,10 +274,16 @@ func (r *Repo) AddAll() error {
 	return err
 }
 
-// Commit creates a commit with the given message. If sign is true, the commit
-// is GPG-signed via the git CLI (go-git has no signing support).
+// Commit creates a commit from the staged changes. If a signing key is
+// configured in ~/.hydra.yml, go-git signs the commit with it directly.
+// Otherwise, when sign is true, the git CLI signs it using git's own signing
+// setup.
 func (r *Repo) Commit(message string, sign bool) error {
-	if sign {
+	signer, err := commitSigner()
+	if err != nil {
+		return fmt.Errorf("loading signing key: %w", err)
+	}
+	if sign && signer == nil {
 		args := []string{"commit", "-m", message, "-S"}
 		_, err := r.run(args...)
 		return err
@@ -217,22 +302,32 @@ func (r *Repo) Commit(message string, sign bool) error {
 			Email: email,
 			When:  time.Now(),
 		},
+		Signer: signer,
 	})
 	return err
 }
 
-// Push pushes the given branch to origin.
-func (r *Repo) Push(branch string) error {
+// Push pushes the given branch to the push remote (origin unless a fork
+// push remote is configured).
+func (r *Repo) Push(branch string) (err error) {
+	ctx, span := tracing.StartChild(r.Context(), "push", attribute.String("git.branch", branch))
+	defer func() { tracing.End(span, err) }()
+	r = r.WithContext(ctx)
+
 	if err := r.ensure(); err != nil {
 		return err
 	}
+	if remote := r.PushRemote(); remote != "origin" {
+		_, err := r.run("push", remote, branch)
+		return err
+	}
 	r.resolveAuth()
 	if r.isHTTPS() {
 		_, err := r.run("push", "origin", branch)
 		return err
 	}
 	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
-	err := r.repo.Push(&git.PushOptions{
+	err = r.repo.Push(&git.PushOptions{
 		RemoteName: "origin",
 		RefSpecs:   []config.RefSpec{refSpec},
 		Auth:       r.auth,
@@ -294,6 +389,117 @@ func (r *Repo) CurrentBranch() (string, error) {
 	return head.Name().Short(), nil
 }
 
+// CommitPaths stages the changes to paths, including deletions, and commits
+// only those with the given message, leaving anything else in the index and
+// work tree as it was. Paths that neither exist nor are tracked are
+// skipped. It reports whether there was anything to commit.
+func (r *Repo) CommitPaths(message string, paths ...string) (bool, error) {
+	var known []string
+	for _, p := range paths {
+		if _, err := os.Lstat(p); err == nil {
+			known = append(known, p)
+			continue
+		}
+		if out, err := r.run("ls-files", "--", p); err == nil && out != "" {
+			known = append(known, p)
+		}
+	}
+	if len(known) == 0 {
+		return false, nil
+	}
+
+	if _, err := r.run(append([]string{"add", "-A", "--"}, known...)...); err != nil {
+		return false, err
+	}
+	if out, err := r.run(append([]string{"diff", "--cached", "--name-only", "--"}, known...)...); err != nil || out == "" {
+		return false, err
+	}
+	if _, err := r.run(append([]string{"commit", "-m", message, "--"}, known...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CommitDir stages every change under Dir, including deletions, and commits
+// it with the given message. Dir may be a subdirectory of a larger
+// repository; only changes beneath it are committed. It reports whether
+// there was anything to commit.
+func (r *Repo) CommitDir(message string) (bool, error) {
+	if _, err := r.run("add", "-A", "--", "."); err != nil {
+		return false, err
+	}
+	if out, err := r.run("diff", "--cached", "--name-only", "--", "."); err != nil || out == "" {
+		return false, err
+	}
+	if _, err := r.run("commit", "-m", message, "--", "."); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PushUpstream pushes the current branch to its configured upstream.
+func (r *Repo) PushUpstream() error {
+	_, err := r.run("push")
+	return err
+}
+
+// PushCurrent pushes the current branch to the branch of the same name on
+// remote. An empty remote pushes to the branch's configured upstream.
+func (r *Repo) PushCurrent(remote string) error {
+	if remote == "" {
+		return r.PushUpstream()
+	}
+	branch, err := r.run("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("current branch: %w", err)
+	}
+	_, err = r.run("push", remote, branch)
+	return err
+}
+
+// PullRebase fetches the current branch from remote and rebases local
+// commits onto it, stashing uncommitted changes elsewhere in the repository
+// around the rebase. An empty remote pulls from the branch's configured
+// upstream. On conflict the rebase is left in progress; see HasConflicts.
+func (r *Repo) PullRebase(remote string) error {
+	args := []string{"pull", "--rebase", "--autostash"}
+	if remote != "" {
+		branch, err := r.run("symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return fmt.Errorf("current branch: %w", err)
+		}
+		args = append(args, remote, branch)
+	}
+	_, err := r.run(args...)
+	return err
+}
+
+// Prefix returns the path of Dir relative to the repository root, with a
+// trailing slash, or "" at the root. Paths from ConflictFiles are relative
+// to the root.
+func (r *Repo) Prefix() (string, error) {
+	return r.run("rev-parse", "--show-prefix")
+}
+
+// ConflictVersion returns one side of a conflicted file: stage 2 is the
+// branch being rebased onto or merged into, stage 3 the commit being
+// applied. path is relative to the repository root. It reports false if
+// the file does not exist on that side.
+func (r *Repo) ConflictVersion(path string, stage int) (string, bool) {
+	out, err := r.run("show", fmt.Sprintf(":%d:%s", stage, path))
+	if err != nil {
+		return "", false
+	}
+	return out, true
+}
+
+// Stage adds path, relative to the repository root, to the index, marking
+// a conflict in it as resolved.
+func (r *Repo) Stage(path string) error {
+	_, err := r.run("add", "--", ":(top)"+path)
+	return err
+}
+
 // LastCommitSHA returns the full SHA of the HEAD commit.
 func (r *Repo) LastCommitSHA() (string, error) {
 	if err := r.ensure(); err != nil {
@@ -306,11 +512,34 @@ func (r *Repo) LastCommitSHA() (string, error) {
 	return head.Hash().String(), nil
 }
 
-// Fetch runs git fetch origin.
-func (r *Repo) Fetch() error {
+// Fetch runs git fetch origin, and also fetches the push remote when it
+// differs from origin. Origin branches the fetch finds force-pushed have
+// their old tips recorded for RewrittenTip, and origin's default branch is
+// recorded for OriginHead if the repository doesn't know it yet.
+func (r *Repo) Fetch() (err error) {
+	ctx, span := tracing.StartChild(r.Context(), "fetch")
+	defer func() { tracing.End(span, err) }()
+	r = r.WithContext(ctx)
+
 	if err := r.ensure(); err != nil {
 		return err
 	}
+	before := r.trackingTips()
+	if err := r.fetchOrigin(); err != nil {
+		return err
+	}
+	r.recordRewrites(before)
+	r.ensureOriginHead()
+	if remote := r.PushRemote(); remote != "origin" {
+		if _, err := r.run("fetch", remote); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchOrigin fetches origin via go-git, or the git CLI for HTTPS remotes.
+func (r *Repo) fetchOrigin() error {
 	r.resolveAuth()
 	if r.isHTTPS() {
 		_, err := r.run("fetch", "origin")
@@ -375,11 +604,70 @@ func (r *Repo) DeleteBranch(name string) error {
 	return r.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(name))
 }
 
-// DeleteRemoteBranch deletes a branch from the origin remote.
+// RenameBranch renames a local branch, along with its configuration. A
+// worktree that has the branch checked out stays on it under the new name.
+func (r *Repo) RenameBranch(oldName, newName string) error {
+	_, err := r.run("branch", "-m", oldName, newName)
+	return err
+}
+
+// WorktreeAdd creates a worktree at dir on a new branch started at the
+// repository's HEAD.
+func (r *Repo) WorktreeAdd(dir, branch string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	_, err = r.run("worktree", "add", "-b", branch, abs)
+	return err
+}
+
+// WorktreeAddExisting creates a worktree at dir with an existing branch
+// checked out. A branch that only exists on origin is checked out as a new
+// local branch tracking it.
+func (r *Repo) WorktreeAddExisting(dir, branch string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	_, err = r.run("worktree", "add", abs, branch)
+	return err
+}
+
+// WorktreeRemove removes a worktree of the repository, discarding any
+// changes in it.
+func (r *Repo) WorktreeRemove(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	_, err = r.run("worktree", "remove", "--force", abs)
+	return err
+}
+
+// WorktreeMove moves a worktree of the repository to newDir.
+func (r *Repo) WorktreeMove(dir, newDir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	newAbs, err := filepath.Abs(newDir)
+	if err != nil {
+		return err
+	}
+	_, err = r.run("worktree", "move", abs, newAbs)
+	return err
+}
+
+// DeleteRemoteBranch deletes a branch from the push remote.
 func (r *Repo) DeleteRemoteBranch(name string) error {
 	if err := r.ensure(); err != nil {
 		return err
 	}
+	if remote := r.PushRemote(); remote != "origin" {
+		_, err := r.run("push", remote, "--delete", name)
+		return err
+	}
 	r.resolveAuth()
 	if r.isHTTPS() {
 		_, err := r.run("push", "origin", "--delete", name)
@@ -445,6 +733,27 @@ func (r *Repo) RebaseAbort() error {
 	return err
 }
 
+// Revert commits a revert of each commit in base..head on the current
+// branch, newest first. On a conflict it aborts, leaving the branch as it
+// was.
+func (r *Repo) Revert(base, head string) error {
+	if _, err := r.run("revert", "--no-edit", base+".."+head); err != nil {
+		_, _ = r.run("revert", "--abort")
+		return err
+	}
+	return nil
+}
+
+// CherryPick applies the commits in base..head to the current branch,
+// oldest first. On a conflict it aborts, leaving the branch as it was.
+func (r *Repo) CherryPick(base, head string) error {
+	if _, err := r.run("cherry-pick", base+".."+head); err != nil {
+		_, _ = r.run("cherry-pick", "--abort")
+		return err
+	}
+	return nil
+}
+
 // HasConflicts returns true if there are unmerged paths.
 func (r *Repo) HasConflicts() (bool, error) {
 	out, err := r.run("status", "--porcelain")
@@ -566,6 +875,22 @@ func (r *Repo) Log(n int) (string, error) {
 	return strings.Join(lines, "\n"), nil
 }
 
+// CommitSubjects returns the subject lines of the commits reachable from
+// head but not from base, newest first.
+func (r *Repo) CommitSubjects(base, head string) ([]string, error) {
+	out, err := r.run("log", "--format=%s", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
 // IsAncestor returns true if ancestor is an ancestor of ref.
 func (r *Repo) IsAncestor(ancestor, ref string) bool {
 	ancestorCommit, err := r.resolveCommit(ancestor)
@@ -621,24 +946,366 @@ func (r *Repo) MergeBase(a, b string) (string, error) {
 
 // DiffRange returns the diff between the merge-base of base..head and head.
 func (r *Repo) DiffRange(base, head string) (string, error) {
-	baseCommit, err := r.resolveCommit(base)
+	patch, err := r.rangePatch(base, head)
 	if err != nil {
 		return "", err
 	}
-	headCommit, err := r.resolveCommit(head)
+	return patch.String(), nil
+}
+
+// DiffRangePaths is DiffRange limited to files under paths, given relative
+// to the repository root. A renamed file is included if either name is.
+func (r *Repo) DiffRangePaths(base, head string, paths []string) (string, error) {
+	patch, err := r.rangePatch(base, head)
+	if err != nil {
+		return "", err
+	}
+
+	var files []diff.FilePatch
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		for _, f := range []diff.File{from, to} {
+			if f != nil && underAny(f.Path(), paths) {
+				files = append(files, fp)
+				break
+			}
+		}
+	}
+
+	var buf strings.Builder
+	if err := diff.NewUnifiedEncoder(&buf, diff.DefaultContextLines).Encode(pathPatch{patch, files}); err != nil {
+		return "", fmt.Errorf("encoding diff: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// pathPatch is a patch reduced to some of its files.
+type pathPatch struct {
+	diff.Patch
+	files []diff.FilePatch
+}
+
+// FilePatches returns the files kept in the patch.
+func (p pathPatch) FilePatches() []diff.FilePatch { return p.files }
+
+// underAny reports whether path is one of dirs or inside one of them.
+func underAny(path string, dirs []string) bool {
+	for _, d := range dirs {
+		d = strings.Trim(filepath.ToSlash(filepath.Clean(d)), "/")
+		if d == "." || d == "" || path == d || strings.HasPrefix(path, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// LineStats returns the number of lines added and deleted between the
+// merge-base of base..head and head.
+func (r *Repo) LineStats(base, head string) (added, deleted int, err error) {
+	patch, err := r.rangePatch(base, head)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, fs := range patch.Stats() {
+		added += fs.Addition
+		deleted += fs.Deletion
+	}
+	return added, deleted, nil
+}
+
+// DiffStat returns a per-file summary of the lines changed between the
+// merge-base of base..head and head, like git diff --stat.
+func (r *Repo) DiffStat(base, head string) (string, error) {
+	patch, err := r.rangePatch(base, head)
 	if err != nil {
 		return "", err
 	}
+	return patch.Stats().String(), nil
+}
+
+// CommitMessages returns the full messages of the commits reachable from
+// head but not from base, newest first, each headed by its short hash.
+func (r *Repo) CommitMessages(base, head string) (string, error) {
+	out, err := r.run("log", "--format=commit %h%n%n%B", base+".."+head)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitDiff is a commit with its full message and the changes it makes.
+type CommitDiff struct {
+	SHA     string // short hash
+	Message string
+	Diff    string // diff against the commit's first parent
+}
+
+// CommitDiffs returns the commits reachable from head but not from base,
+// oldest first, each with its message and its own diff.
+func (r *Repo) CommitDiffs(base, head string) ([]CommitDiff, error) {
+	out, err := r.run("log", "--reverse", "--format=%h", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	var commits []CommitDiff
+	for _, sha := range strings.Fields(out) {
+		msg, err := r.run("log", "-1", "--format=%B", sha)
+		if err != nil {
+			return nil, err
+		}
+		diff, err := r.run("show", "--format=", "--no-color", "-m", "--first-parent", sha)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, CommitDiff{SHA: sha, Message: msg, Diff: diff})
+	}
+	return commits, nil
+}
+
+// ChangedFiles returns the paths changed between the merge-base of
+// base..head and head, sorted. Renamed files are listed under both names.
+func (r *Repo) ChangedFiles(base, head string) ([]string, error) {
+	patch, err := r.rangePatch(base, head)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var files []string
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		for _, f := range []diff.File{from, to} {
+			if f != nil && !seen[f.Path()] {
+				seen[f.Path()] = true
+				files = append(files, f.Path())
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// rangePatch returns the patch from the merge-base of base..head to head.
+func (r *Repo) rangePatch(base, head string) (*object.Patch, error) {
+	baseCommit, err := r.resolveCommit(base)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := r.resolveCommit(head)
+	if err != nil {
+		return nil, err
+	}
 	bases, err := baseCommit.MergeBase(headCommit)
 	if err != nil {
-		return "", fmt.Errorf("merge-base: %w", err)
+		return nil, fmt.Errorf("merge-base: %w", err)
 	}
 	if len(bases) == 0 {
-		return "", errors.New("no merge base found")
+		return nil, errors.New("no merge base found")
 	}
 	patch, err := bases[0].Patch(headCommit)
 	if err != nil {
-		return "", fmt.Errorf("patch: %w", err)
+		return nil, fmt.Errorf("patch: %w", err)
 	}
-	return patch.String(), nil
+	return patch, nil
+}
+
+// HooksDir returns the absolute path of the repository's hooks directory,
+// honoring core.hooksPath.
+func (r *Repo) HooksDir() (string, error) {
+	out, err := r.run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(out) {
+		return out, nil
+	}
+	return filepath.Join(r.Dir, out), nil
+}
+
+// GitDir returns the absolute path of the git directory of the work tree:
+// for a linked worktree, its own directory under the main repository's
+// .git/worktrees, which goes away with the worktree.
+func (r *Repo) GitDir() (string, error) {
+	return r.run("rev-parse", "--absolute-git-dir")
+}
+
+// RemoteBranches returns the names of the push remote's branches that start
+// with prefix (e.g. "hydra/"), as seen by the last fetch.
+func (r *Repo) RemoteBranches(prefix string) ([]string, error) {
+	out, err := r.run("for-each-ref", "--format=%(refname:strip=3)", "refs/remotes/"+r.PushRemote()+"/"+prefix)
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line != "" && strings.HasPrefix(line, prefix) {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
+
+// TrialRebase rebases a detached copy of ref onto onto in a temporary
+// worktree and returns the files that would conflict. The repository's own
+// working tree and branches are left untouched. An empty result means the
+// rebase would apply cleanly.
+func (r *Repo) TrialRebase(ref, onto string) ([]string, error) {
+	_, files, cleanup, err := r.TrialRebaseWorktree(ref, onto)
+	if cleanup != nil {
+		defer cleanup()
+	}
+	return files, err
+}
+
+// TrialRebaseWorktree is TrialRebase, keeping the temporary worktree for the
+// caller to work in, e.g. to run tests on the rebased code. It returns the
+// worktree, the files that would conflict, and a function that removes the
+// worktree, which the caller must call if it isn't nil. If there are
+// conflicts, the rebase is aborted, leaving ref checked out in the worktree.
+func (r *Repo) TrialRebaseWorktree(ref, onto string) (*Repo, []string, func(), error) {
+	tmp, err := os.MkdirTemp("", "hydra-precheck-")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+
+	wt := filepath.Join(tmp, "wt")
+	if _, err := r.run("worktree", "add", "--detach", wt, ref); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, nil, nil, err
+	}
+	cleanup := func() {
+		if _, err := r.run("worktree", "remove", "--force", wt); err != nil {
+			_, _ = r.run("worktree", "prune")
+		}
+		_ = os.RemoveAll(tmp)
+	}
+
+	trial := &Repo{Dir: wt}
+	if err := trial.Rebase(onto); err == nil {
+		return trial, nil, cleanup, nil
+	}
+
+	files, err := trial.ConflictFiles()
+	_ = trial.RebaseAbort()
+	if err != nil {
+		return trial, nil, cleanup, err
+	}
+	if len(files) == 0 {
+		return trial, nil, cleanup, fmt.Errorf("trial rebase of %s onto %s failed without conflicts", ref, onto)
+	}
+	return trial, files, cleanup, nil
+}
+
+// PushRemoteName is the git remote hydra adds for the push_remote setting in
+// hydra.yml, so hydra/* branches can be pushed to a fork while the default
+// branch is still fetched from origin.
+const PushRemoteName = "hydra-push"
+
+// PushRemote returns the remote that branches are pushed to: the configured
+// remote.pushDefault, or "origin" if none is set.
+func (r *Repo) PushRemote() string {
+	out, err := r.run("config", "--get", "remote.pushDefault")
+	if err != nil || out == "" {
+		return "origin"
+	}
+	return out
+}
+
+// ConfigurePushRemote points branch pushes at url by adding (or updating) the
+// PushRemoteName remote and making it remote.pushDefault. Plain `git push`
+// from any worktree then goes to the fork. An empty url undoes a previous
+// configuration so pushes go back to origin.
+func (r *Repo) ConfigurePushRemote(url string) error {
+	current, _ := r.run("remote", "get-url", PushRemoteName)
+	defer func() { r.envDone = false }()
+
+	if url == "" {
+		if current == "" {
+			return nil
+		}
+		if r.PushRemote() == PushRemoteName {
+			if _, err := r.run("config", "--unset", "remote.pushDefault"); err != nil {
+				return err
+			}
+		}
+		_, err := r.run("remote", "remove", PushRemoteName)
+		return err
+	}
+
+	switch current {
+	case url:
+	case "":
+		if _, err := r.run("remote", "add", PushRemoteName, url); err != nil {
+			return err
+		}
+	default:
+		if _, err := r.run("remote", "set-url", PushRemoteName, url); err != nil {
+			return err
+		}
+	}
+
+	if r.PushRemote() == PushRemoteName {
+		return nil
+	}
+	_, err := r.run("config", "remote.pushDefault", PushRemoteName)
+	return err
+}
+
+// AheadBehind returns how many commits ref has that upstream does not
+// (ahead) and how many upstream has that ref does not (behind).
+func (r *Repo) AheadBehind(ref, upstream string) (ahead, behind int, err error) {
+	out, err := r.run("rev-list", "--left-right", "--count", ref+"..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(out, "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("parsing rev-list output %q: %w", out, err)
+	}
+	return ahead, behind, nil
+}
+
+// CommitInfo returns the full SHA and committer time of ref.
+func (r *Repo) CommitInfo(ref string) (string, time.Time, error) {
+	out, err := r.run("log", "-1", "--format=%H %ct", ref)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var sha string
+	var unix int64
+	if _, err := fmt.Sscanf(out, "%s %d", &sha, &unix); err != nil {
+		return "", time.Time{}, fmt.Errorf("parsing log output %q: %w", out, err)
+	}
+	return sha, time.Unix(unix, 0), nil
+}
+
+// CommitBefore returns the SHA of the newest commit on ref made before t, or
+// "" if ref has no commit that old.
+func (r *Repo) CommitBefore(ref string, t time.Time) (string, error) {
+	return r.run("rev-list", "-1", "--before=@"+strconv.FormatInt(t.Unix(), 10), ref)
+}
+
+// FileBefore returns the content path had as of the newest commit before t,
+// and false if it had not been committed by then. path is relative to the
+// repository directory, which may be a subdirectory of the work tree.
+// Surrounding whitespace is trimmed from the content.
+func (r *Repo) FileBefore(path string, t time.Time) (string, bool, error) {
+	rev, err := r.run("rev-list", "-1", "--before=@"+strconv.FormatInt(t.Unix(), 10), "HEAD", "--", path)
+	if err != nil || rev == "" {
+		return "", false, err
+	}
+	object := rev + ":./" + filepath.ToSlash(path)
+	if _, err := r.run("cat-file", "-e", object); err != nil {
+		return "", false, nil // the newest commit touching path before t deleted it
+	}
+	content, err := r.run("show", object)
+	if err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
+
+// RemoteBranchExists reports whether branch exists on the push remote as of
+// the last fetch.
+func (r *Repo) RemoteBranchExists(branch string) bool {
+	_, err := r.run("rev-parse", "--verify", "--quiet", "refs/remotes/"+r.PushRemote()+"/"+branch)
+	return err == nil
 }
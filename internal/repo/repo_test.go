@@ -1,12 +1,17 @@
 package repo
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 )
 
 // initBareRemote creates a bare git repo to act as a remote.
@@ -248,6 +253,98 @@ func TestFetch(t *testing.T) {
 	}
 }
 
+func TestRewrittenTipAndRebaseOnto(t *testing.T) {
+	bare := initBareRemote(t)
+	upstream := initLocalRepo(t, bare)
+	writeTestFile(t, filepath.Join(upstream, "old.txt"), "old")
+	gitRun(t, "-C", upstream, "add", "-A")
+	gitRun(t, "-C", upstream, "commit", "-m", "old upstream")
+	gitRun(t, "-C", upstream, "push")
+	up := Open(upstream)
+	oldSHA, _ := up.LastCommitSHA()
+	branch, _ := up.CurrentBranch()
+
+	dir := filepath.Join(t.TempDir(), "task")
+	gitRun(t, "clone", bare, dir)
+	gitRun(t, "-C", dir, "config", "user.email", "test@test.com")
+	gitRun(t, "-C", dir, "config", "user.name", "Test")
+	r := Open(dir)
+	if err := r.CreateBranch("hydra/feature"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "feature.txt"), "feature")
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("add feature", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if _, ok := r.RewrittenTip("origin/" + branch); ok {
+		t.Fatal("RewrittenTip reported a rewrite before any force-push")
+	}
+
+	// Rewrite upstream history: drop the old commit and force-push.
+	gitRun(t, "-C", upstream, "reset", "--hard", "HEAD~1")
+	writeTestFile(t, filepath.Join(upstream, "new.txt"), "new")
+	gitRun(t, "-C", upstream, "add", "-A")
+	gitRun(t, "-C", upstream, "commit", "-m", "new upstream")
+	gitRun(t, "-C", upstream, "push", "--force")
+
+	if err := r.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	old, ok := r.RewrittenTip("origin/" + branch)
+	if !ok || old != oldSHA {
+		t.Fatalf("RewrittenTip = %q, %v; want %q, true", old, ok, oldSHA)
+	}
+
+	fork, err := r.MergeBase("HEAD", old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RebaseOnto("origin/"+branch, fork); err != nil {
+		t.Fatalf("RebaseOnto: %v", err)
+	}
+	for name, want := range map[string]bool{"feature.txt": true, "new.txt": true, "old.txt": false} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", name, err == nil, want)
+		}
+	}
+}
+
+func TestOriginHead(t *testing.T) {
+	bare := initBareRemote(t)
+	src := initLocalRepo(t, "")
+	gitRun(t, "-C", src, "branch", "-M", "trunk")
+	gitRun(t, "-C", src, "remote", "add", "origin", bare)
+	gitRun(t, "-C", src, "push", "origin", "trunk")
+	gitRun(t, "-C", bare, "symbolic-ref", "HEAD", "refs/heads/trunk")
+
+	r, err := Clone(bare, filepath.Join(t.TempDir(), "clone"))
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if got, ok := r.OriginHead(); !ok || got != "trunk" {
+		t.Errorf("OriginHead() after Clone = %q, %v; want trunk", got, ok)
+	}
+
+	// A clone that lost origin/HEAD gets it back on the next fetch.
+	gitRun(t, "-C", r.Dir, "symbolic-ref", "--delete", "refs/remotes/origin/HEAD")
+	if _, ok := r.OriginHead(); ok {
+		t.Fatal("OriginHead() reported a deleted ref")
+	}
+	if err := r.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got, ok := r.OriginHead(); !ok || got != "trunk" {
+		t.Errorf("OriginHead() after Fetch = %q, %v; want trunk", got, ok)
+	}
+}
+
 func TestResetHard(t *testing.T) {
 	dir := initLocalRepo(t, "")
 	r := Open(dir)
@@ -307,6 +404,64 @@ func TestDeleteBranch(t *testing.T) {
 	}
 }
 
+func TestWorktreeAddAndRemove(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	wd := filepath.Join(t.TempDir(), "new")
+	if err := r.WorktreeAdd(wd, "hydra/new"); err != nil {
+		t.Fatalf("WorktreeAdd: %v", err)
+	}
+	if !IsGitRepo(wd) || !r.BranchExists("hydra/new") {
+		t.Fatal("worktree was not created on a new branch")
+	}
+	if err := r.WorktreeRemove(wd); err != nil {
+		t.Fatalf("WorktreeRemove: %v", err)
+	}
+	if IsGitRepo(wd) {
+		t.Fatal("worktree was not removed")
+	}
+
+	existing := filepath.Join(filepath.Dir(wd), "existing")
+	if err := r.WorktreeAddExisting(existing, "hydra/new"); err != nil {
+		t.Fatalf("WorktreeAddExisting: %v", err)
+	}
+	if branch, err := Open(existing).run("rev-parse", "--abbrev-ref", "HEAD"); err != nil || branch != "hydra/new" {
+		t.Errorf("worktree branch = %q, %v; want hydra/new", branch, err)
+	}
+}
+
+func TestRenameBranchAndWorktreeMove(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	if _, err := r.run("branch", "hydra/old"); err != nil {
+		t.Fatal(err)
+	}
+	wd := filepath.Join(t.TempDir(), "old")
+	if _, err := r.run("worktree", "add", wd, "hydra/old"); err != nil {
+		t.Fatal(err)
+	}
+
+	newWD := filepath.Join(filepath.Dir(wd), "new")
+	if err := r.WorktreeMove(wd, newWD); err != nil {
+		t.Fatalf("WorktreeMove: %v", err)
+	}
+	if IsGitRepo(wd) || !IsGitRepo(newWD) {
+		t.Fatal("worktree was not moved")
+	}
+
+	if err := r.RenameBranch("hydra/old", "hydra/new"); err != nil {
+		t.Fatalf("RenameBranch: %v", err)
+	}
+	if r.BranchExists("hydra/old") || !r.BranchExists("hydra/new") {
+		t.Error("branch was not renamed")
+	}
+	if branch, err := Open(newWD).run("rev-parse", "--abbrev-ref", "HEAD"); err != nil || branch != "hydra/new" {
+		t.Errorf("worktree branch = %q, %v; want hydra/new", branch, err)
+	}
+}
+
 func TestIsGitRepo(t *testing.T) {
 	dir := initLocalRepo(t, "")
 	if !IsGitRepo(dir) {
@@ -433,6 +588,88 @@ func TestRebaseConflictAndAbort(t *testing.T) {
 	}
 }
 
+func TestTrialRebase(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	defaultBranch, _ := r.CurrentBranch()
+
+	commit := func(name, content, msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Commit(msg, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.CreateBranch("hydra/clean"); err != nil {
+		t.Fatal(err)
+	}
+	commit("clean.txt", "clean", "clean change")
+	if err := r.Checkout(defaultBranch); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateBranch("hydra/conflict"); err != nil {
+		t.Fatal(err)
+	}
+	commit("file.txt", "branch content", "branch change")
+	if err := r.Checkout(defaultBranch); err != nil {
+		t.Fatal(err)
+	}
+	commit("file.txt", "main content", "main change")
+
+	files, err := r.TrialRebase("hydra/clean", defaultBranch)
+	if err != nil {
+		t.Fatalf("TrialRebase clean: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("clean branch conflicts = %v, want none", files)
+	}
+
+	files, err = r.TrialRebase("hydra/conflict", defaultBranch)
+	if err != nil {
+		t.Fatalf("TrialRebase conflict: %v", err)
+	}
+	if len(files) != 1 || files[0] != "file.txt" {
+		t.Errorf("conflicts = %v, want [file.txt]", files)
+	}
+
+	// The real checkout must be untouched and no worktrees left behind.
+	if branch, _ := r.CurrentBranch(); branch != defaultBranch {
+		t.Errorf("current branch = %q, want %q", branch, defaultBranch)
+	}
+	out, err := r.run("worktree", "list", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out, "worktree "); n != 1 {
+		t.Errorf("worktree count = %d, want 1:\n%s", n, out)
+	}
+
+	// The worktree variant leaves the rebased code in place until cleanup.
+	wt, files, cleanup, err := r.TrialRebaseWorktree("hydra/clean", defaultBranch)
+	if err != nil {
+		t.Fatalf("TrialRebaseWorktree: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("clean branch conflicts = %v, want none", files)
+	}
+	for _, name := range []string{"clean.txt", "file.txt"} {
+		if _, err := os.Stat(filepath.Join(wt.Dir, name)); err != nil {
+			t.Errorf("rebased worktree lacks %s: %v", name, err)
+		}
+	}
+	cleanup()
+	if _, err := os.Stat(wt.Dir); !os.IsNotExist(err) {
+		t.Errorf("worktree not removed: %v", err)
+	}
+}
+
 func TestMergeFFOnly(t *testing.T) {
 	dir := initLocalRepo(t, "")
 	r := Open(dir)
@@ -563,3 +800,865 @@ func TestPushMain(t *testing.T) {
 		t.Fatalf("PushMain: %v", err)
 	}
 }
+
+func TestRevertAndCherryPick(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+	base, _ := r.LastCommitSHA()
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeTestFile(t, filepath.Join(dir, name), name)
+		if err := r.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Commit("add "+name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head, _ := r.LastCommitSHA()
+
+	if err := r.Revert(base, head); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s still exists after Revert", name)
+		}
+	}
+
+	if err := r.CherryPick(base, head); err != nil {
+		t.Fatalf("CherryPick: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s missing after CherryPick: %v", name, err)
+		}
+	}
+}
+
+func TestHooksDir(t *testing.T) {
+	local := initLocalRepo(t, "")
+	r := Open(local)
+
+	dir, err := r.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir: %v", err)
+	}
+	if want := filepath.Join(local, ".git", "hooks"); dir != want {
+		t.Errorf("HooksDir = %q, want %q", dir, want)
+	}
+
+	gitRun(t, "-C", local, "config", "core.hooksPath", "custom-hooks")
+	dir, err = r.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir: %v", err)
+	}
+	if want := filepath.Join(local, "custom-hooks"); dir != want {
+		t.Errorf("HooksDir with core.hooksPath = %q, want %q", dir, want)
+	}
+}
+
+func TestUpdateSubmodules(t *testing.T) {
+	// Submodules are cloned from local paths, which git refuses by default.
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	sub := initLocalRepo(t, "")
+	bare := initBareRemote(t)
+	local := initLocalRepo(t, bare)
+	gitRun(t, "-C", local, "submodule", "add", sub, "lib")
+	gitRun(t, "-C", local, "commit", "-m", "add submodule")
+	gitRun(t, "-C", local, "push", "origin", "HEAD")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitRun(t, "clone", bare, clone)
+	r := Open(clone)
+	if !r.HasSubmodules() {
+		t.Fatal("HasSubmodules = false, want true")
+	}
+	if err := r.UpdateSubmodules(); err != nil {
+		t.Fatalf("UpdateSubmodules: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(clone, "lib", "README.md")); err != nil {
+		t.Errorf("submodule not checked out: %v", err)
+	}
+
+	// Without .gitmodules, there is nothing to do.
+	plain := Open(initLocalRepo(t, ""))
+	if plain.HasSubmodules() {
+		t.Error("HasSubmodules = true without .gitmodules")
+	}
+	if err := plain.UpdateSubmodules(); err != nil {
+		t.Errorf("UpdateSubmodules without submodules: %v", err)
+	}
+}
+
+func TestPullLFSWithoutLFS(t *testing.T) {
+	local := initLocalRepo(t, "")
+	r := Open(local)
+	if r.UsesLFS() {
+		t.Error("UsesLFS = true without .gitattributes")
+	}
+	if err := r.PullLFS(); err != nil {
+		t.Errorf("PullLFS without LFS: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(local, ".gitattributes"), "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+	if !r.UsesLFS() {
+		t.Error("UsesLFS = false with an LFS filter in .gitattributes")
+	}
+}
+
+func TestRemoteBranches(t *testing.T) {
+	bare := initBareRemote(t)
+	local := initLocalRepo(t, bare)
+	r := Open(local)
+
+	for _, b := range []string{"hydra/one", "hydra/backend/two", "feature/x"} {
+		gitRun(t, "-C", local, "push", "origin", "HEAD:refs/heads/"+b)
+	}
+	gitRun(t, "-C", local, "fetch", "origin")
+
+	branches, err := r.RemoteBranches("hydra/")
+	if err != nil {
+		t.Fatalf("RemoteBranches: %v", err)
+	}
+	want := []string{"hydra/backend/two", "hydra/one"}
+	if strings.Join(branches, ",") != strings.Join(want, ",") {
+		t.Errorf("RemoteBranches = %v, want %v", branches, want)
+	}
+}
+
+func TestConfigurePushRemote(t *testing.T) {
+	upstream := initBareRemote(t)
+	fork := initBareRemote(t)
+	local := initLocalRepo(t, upstream)
+	r := Open(local)
+
+	if got := r.PushRemote(); got != "origin" {
+		t.Fatalf("PushRemote = %q, want origin", got)
+	}
+
+	if err := r.ConfigurePushRemote(fork); err != nil {
+		t.Fatalf("ConfigurePushRemote: %v", err)
+	}
+	// Configuring twice is a no-op.
+	if err := r.ConfigurePushRemote(fork); err != nil {
+		t.Fatalf("ConfigurePushRemote (again): %v", err)
+	}
+	if got := r.PushRemote(); got != PushRemoteName {
+		t.Fatalf("PushRemote = %q, want %q", got, PushRemoteName)
+	}
+
+	if err := r.CreateBranch("hydra/forked"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Push("hydra/forked"); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if out, err := exec.CommandContext(context.Background(), "git", "-C", fork, "branch", "--list", "hydra/forked").Output(); err != nil || !strings.Contains(string(out), "hydra/forked") { //nolint:gosec // test with controlled args
+		t.Errorf("branch not pushed to fork: %q %v", out, err)
+	}
+	if out, _ := exec.CommandContext(context.Background(), "git", "-C", upstream, "branch", "--list", "hydra/forked").Output(); len(out) != 0 { //nolint:gosec // test with controlled args
+		t.Errorf("branch should not be pushed to upstream: %q", out)
+	}
+
+	if err := r.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	branches, err := r.RemoteBranches("hydra/")
+	if err != nil {
+		t.Fatalf("RemoteBranches: %v", err)
+	}
+	if len(branches) != 1 || branches[0] != "hydra/forked" {
+		t.Errorf("RemoteBranches = %v, want [hydra/forked]", branches)
+	}
+
+	if err := r.DeleteRemoteBranch("hydra/forked"); err != nil {
+		t.Fatalf("DeleteRemoteBranch: %v", err)
+	}
+
+	// Clearing the setting sends pushes back to origin.
+	if err := r.ConfigurePushRemote(""); err != nil {
+		t.Fatalf("ConfigurePushRemote(\"\"): %v", err)
+	}
+	if got := r.PushRemote(); got != "origin" {
+		t.Errorf("PushRemote after reset = %q, want origin", got)
+	}
+}
+
+func TestBranchDivergence(t *testing.T) {
+	bare := initBareRemote(t)
+	local := initLocalRepo(t, bare)
+	r := Open(local)
+
+	defaultBranch, _ := r.CurrentBranch()
+
+	if err := r.CreateBranch("hydra/diverge"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(local, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Commit("add "+name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r.RemoteBranchExists("hydra/diverge") {
+		t.Error("branch should not exist on remote before push")
+	}
+	gitRun(t, "-C", local, "push", "origin", "hydra/diverge")
+	gitRun(t, "-C", local, "fetch", "origin")
+	if !r.RemoteBranchExists("hydra/diverge") {
+		t.Error("branch should exist on remote after push")
+	}
+
+	// Advance the default branch on origin by one commit.
+	if err := r.Checkout(defaultBranch); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(local, "main.txt"), []byte("main"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("main change", false); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, "-C", local, "push", "origin", defaultBranch)
+	gitRun(t, "-C", local, "fetch", "origin")
+
+	ahead, behind, err := r.AheadBehind("hydra/diverge", "origin/"+defaultBranch)
+	if err != nil {
+		t.Fatalf("AheadBehind: %v", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Errorf("ahead/behind = %d/%d, want 2/1", ahead, behind)
+	}
+
+	sha, when, err := r.CommitInfo("hydra/diverge")
+	if err != nil {
+		t.Fatalf("CommitInfo: %v", err)
+	}
+	if len(sha) != 40 {
+		t.Errorf("sha = %q, want 40 hex chars", sha)
+	}
+	if time.Since(when) > time.Hour {
+		t.Errorf("commit time = %s, want recent", when)
+	}
+}
+
+func TestCommitBeforeAndFileBefore(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	sub := filepath.Join(dir, "design")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	commitAt := func(date, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(sub, "functional.md"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, "-C", dir, "add", "-A")
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		gitRun(t, "-C", dir, "commit", "-m", content, "--date", date)
+	}
+	commitAt("2026-01-10T12:00:00Z", "v1")
+	commitAt("2026-02-10T12:00:00Z", "v2")
+
+	r := &Repo{Dir: sub}
+	feb := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	content, ok, err := r.FileBefore("functional.md", feb)
+	if err != nil || !ok || content != "v1" {
+		t.Errorf("FileBefore(Feb 1) = %q, %v, %v; want v1", content, ok, err)
+	}
+	content, ok, err = r.FileBefore("functional.md", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || !ok || content != "v2" {
+		t.Errorf("FileBefore(Mar 1) = %q, %v, %v; want v2", content, ok, err)
+	}
+	if _, ok, err := r.FileBefore("functional.md", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil || ok {
+		t.Errorf("FileBefore(2020) = %v, %v; want not committed", ok, err)
+	}
+
+	sha, err := Open(dir).CommitBefore("HEAD", feb)
+	if err != nil {
+		t.Fatalf("CommitBefore: %v", err)
+	}
+	want, _, err := Open(dir).CommitInfo("HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != want {
+		t.Errorf("CommitBefore(Feb 1) = %s, want %s", sha, want)
+	}
+}
+
+func TestCommitDirOnlyCommitsSubdirectory(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	sub := filepath.Join(dir, "design")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "task.md"), []byte("task"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "outside.txt"), []byte("outside"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Repo{Dir: sub}
+	committed, err := r.CommitDir("design change")
+	if err != nil {
+		t.Fatalf("CommitDir: %v", err)
+	}
+	if !committed {
+		t.Fatal("CommitDir should report a commit")
+	}
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "show", "--name-only", "--format=%s", "HEAD").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); !strings.Contains(got, "design change") || !strings.Contains(got, "design/task.md") || strings.Contains(got, "outside.txt") {
+		t.Errorf("HEAD commit = %q, want only design/task.md", got)
+	}
+
+	// Nothing left to commit under the subdirectory.
+	committed, err = r.CommitDir("empty")
+	if err != nil {
+		t.Fatalf("CommitDir: %v", err)
+	}
+	if committed {
+		t.Error("CommitDir should not commit when there are no changes")
+	}
+}
+
+func TestCommitPathsLeavesOtherChanges(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	sub := filepath.Join(dir, "design")
+	if err := os.MkdirAll(sub, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"task.md", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(sub, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &Repo{Dir: sub}
+	task := filepath.Join(sub, "task.md")
+	committed, err := r.CommitPaths("add task", task, filepath.Join(sub, "missing.md"))
+	if err != nil {
+		t.Fatalf("CommitPaths: %v", err)
+	}
+	if !committed {
+		t.Fatal("CommitPaths should report a commit")
+	}
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "show", "--name-only", "--format=%s", "HEAD").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(out); !strings.Contains(got, "design/task.md") || strings.Contains(got, "notes.md") {
+		t.Errorf("HEAD commit = %q, want only design/task.md", got)
+	}
+
+	// A removed path is committed as a deletion.
+	if err := os.Remove(task); err != nil {
+		t.Fatal(err)
+	}
+	committed, err = r.CommitPaths("remove task", task)
+	if err != nil {
+		t.Fatalf("CommitPaths: %v", err)
+	}
+	if !committed {
+		t.Error("CommitPaths should commit the deletion")
+	}
+
+	status, err := exec.CommandContext(context.Background(), "git", "-C", dir, "status", "--porcelain").Output() //nolint:gosec // test with controlled args
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(status); !strings.Contains(got, "?? design/") {
+		t.Errorf("status = %q, want notes.md left untracked", got)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	defaultBranch, _ := r.CurrentBranch()
+
+	if err := r.CreateBranch("hydra/files"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"pkg/a.go": "package pkg", "README.md": "# Changed"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("change files", false); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := r.ChangedFiles(defaultBranch, "hydra/files")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if strings.Join(files, ",") != "README.md,pkg/a.go" {
+		t.Errorf("changed files = %v, want [README.md pkg/a.go]", files)
+	}
+
+	files, err = r.ChangedFiles("hydra/files", defaultBranch)
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("default branch has no changes since the merge base, got %v", files)
+	}
+}
+
+func TestDiffRangePaths(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	defaultBranch, _ := r.CurrentBranch()
+
+	if err := r.CreateBranch("hydra/focus"); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{"pkg/api", "pkgx"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{"pkg/api/a.go": "package api\n", "pkgx/b.go": "package pkgx\n", "README.md": "# Changed\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("change files", false); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := r.DiffRangePaths(defaultBranch, "hydra/focus", []string{"./pkg/"})
+	if err != nil {
+		t.Fatalf("DiffRangePaths: %v", err)
+	}
+	if !strings.Contains(out, "pkg/api/a.go") || strings.Contains(out, "pkgx/b.go") || strings.Contains(out, "README.md") {
+		t.Errorf("diff for pkg/ = %q, want only pkg/api/a.go", out)
+	}
+
+	out, err = r.DiffRangePaths(defaultBranch, "hydra/focus", []string{"docs"})
+	if err != nil {
+		t.Fatalf("DiffRangePaths: %v", err)
+	}
+	if out != "" {
+		t.Errorf("diff for an unchanged path = %q, want empty", out)
+	}
+}
+
+func TestLineStats(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	before, err := r.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"a.txt": "one\ntwo\nthree\n", "README.md": "# Changed\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("change lines", false); err != nil {
+		t.Fatal(err)
+	}
+
+	added, deleted, err := r.LineStats(before, "HEAD")
+	if err != nil {
+		t.Fatalf("LineStats: %v", err)
+	}
+	if added != 4 || deleted != 1 {
+		t.Errorf("LineStats = +%d -%d, want +4 -1", added, deleted)
+	}
+}
+
+func TestDiffStatAndCommitMessages(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	before, err := r.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "one\ntwo\n")
+	if err := r.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Commit("Add a.txt\n\nWith two lines.", false); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := r.DiffStat(before, "HEAD")
+	if err != nil {
+		t.Fatalf("DiffStat: %v", err)
+	}
+	if !strings.Contains(stat, "a.txt") || !strings.Contains(stat, "++") {
+		t.Errorf("DiffStat = %q, want a.txt with two additions", stat)
+	}
+
+	msgs, err := r.CommitMessages(before, "HEAD")
+	if err != nil {
+		t.Fatalf("CommitMessages: %v", err)
+	}
+	if !strings.Contains(msgs, "Add a.txt") || !strings.Contains(msgs, "With two lines.") {
+		t.Errorf("CommitMessages = %q, want the full message", msgs)
+	}
+}
+
+func TestCommitDiffs(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	before, err := r.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ file, msg string }{
+		{"a.txt", "Add a.txt\n\nThe first file."},
+		{"b.txt", "Add b.txt"},
+	} {
+		writeTestFile(t, filepath.Join(dir, c.file), c.file+"\n")
+		if err := r.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Commit(c.msg, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commits, err := r.CommitDiffs(before, "HEAD")
+	if err != nil {
+		t.Fatalf("CommitDiffs: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2", len(commits))
+	}
+	first, second := commits[0], commits[1]
+	if first.Message != "Add a.txt\n\nThe first file." || second.Message != "Add b.txt" {
+		t.Errorf("messages = %q, %q; want oldest first", first.Message, second.Message)
+	}
+	if !strings.Contains(first.Diff, "+a.txt") || strings.Contains(first.Diff, "b.txt") {
+		t.Errorf("first diff = %q, want only a.txt", first.Diff)
+	}
+	if !strings.Contains(second.Diff, "+b.txt") || strings.Contains(second.Diff, "a.txt") {
+		t.Errorf("second diff = %q, want only b.txt", second.Diff)
+	}
+	if first.SHA == "" || first.SHA == second.SHA {
+		t.Errorf("SHAs = %q, %q", first.SHA, second.SHA)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	dir := initLocalRepo(t, "")
+	r := Open(dir)
+
+	defaultBranch, _ := r.CurrentBranch()
+
+	commit := func(name, content, msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.AddAll(); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Commit(msg, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("file.txt", "one\ntwo\nthree\nfour\nfive\n", "add file")
+	if err := r.CreateBranch("hydra/conflict"); err != nil {
+		t.Fatal(err)
+	}
+	commit("file.txt", "one\nTWO\nthree\nfour\nfive\n", "branch change")
+	if err := r.Checkout(defaultBranch); err != nil {
+		t.Fatal(err)
+	}
+	commit("other.txt", "unrelated", "unrelated change")
+	commit("file.txt", "one\n2\nthree\nfour\nfive\n", "main change")
+	commit("file.txt", "one\n2\nthree\nfour\n5\n", "main change elsewhere in the file")
+
+	if err := r.Checkout("hydra/conflict"); err != nil {
+		t.Fatal(err)
+	}
+	head, err := r.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Rebase(defaultBranch); err == nil {
+		t.Fatal("expected the rebase to conflict")
+	}
+	defer func() { _ = r.RebaseAbort() }()
+
+	conflicts, err := r.Conflicts(defaultBranch, head)
+	if err != nil {
+		t.Fatalf("Conflicts: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].File != "file.txt" {
+		t.Fatalf("conflicts = %+v, want file.txt", conflicts)
+	}
+	c := conflicts[0]
+	if len(c.Hunks) != 1 || c.Hunks[0].Upstream != "2" || c.Hunks[0].Task != "TWO" {
+		t.Errorf("hunks = %+v", c.Hunks)
+	}
+	if len(c.Commits) != 1 || !strings.HasSuffix(c.Commits[0], " main change") {
+		t.Errorf("commits = %v, want only the commit that changed the conflicted line", c.Commits)
+	}
+}
+
+func TestParseConflictHunks(t *testing.T) {
+	content := "keep\n" +
+		"<<<<<<< HEAD\nupstream 1\nupstream 2\n=======\ntask\n>>>>>>> abc123 (task change)\n" +
+		"middle\n" +
+		"<<<<<<< HEAD\nnew\n||||||| base\nold\n=======\n>>>>>>> abc123\n" +
+		"======= not a marker\n"
+
+	hunks := parseConflictHunks(content)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2: %+v", len(hunks), hunks)
+	}
+	if hunks[0].Upstream != "upstream 1\nupstream 2" || hunks[0].Task != "task" {
+		t.Errorf("hunk 0 = %+v", hunks[0])
+	}
+	if hunks[1].Upstream != "new" || hunks[1].Task != "" {
+		t.Errorf("hunk 1 = %+v, want the diff3 base skipped", hunks[1])
+	}
+
+	long := "<<<<<<< HEAD\n" + strings.Repeat("x\n", maxHunkLines+10) + "=======\ny\n>>>>>>> abc\n"
+	hunks = parseConflictHunks(long)
+	if len(hunks) != 1 || !hunks[0].Truncated || strings.Count(hunks[0].Upstream, "x") != maxHunkLines {
+		t.Errorf("long hunk was not truncated to %d lines: %+v", maxHunkLines, hunks)
+	}
+}
+
+func TestLoadAuthConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if cfg := LoadAuthConfig(); cfg.SSH.IdentityFile != "" || cfg.GitToken != "" {
+		t.Errorf("LoadAuthConfig without config = %+v, want empty", cfg)
+	}
+
+	yml := "ssh:\n  identity_file: ~/.ssh/deploy\ngit_token: tok\ngit_username: me\n"
+	if err := os.WriteFile(filepath.Join(home, ".hydra.yml"), []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := LoadAuthConfig()
+	if want := filepath.Join(home, ".ssh", "deploy"); cfg.SSH.IdentityFile != want {
+		t.Errorf("IdentityFile = %q, want %q", cfg.SSH.IdentityFile, want)
+	}
+	if cfg.GitToken != "tok" || cfg.GitUsername != "me" {
+		t.Errorf("GitToken/GitUsername = %q/%q, want tok/me", cfg.GitToken, cfg.GitUsername)
+	}
+}
+
+func TestTokenEnv(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "")
+	cfg := AuthConfig{GitToken: "tok", GitUsername: "me", GitTokenHosts: []string{"github.com", "gitlab.example.com"}}
+
+	env := tokenEnv(cfg, []string{
+		"https://github.com/owner/repo.git",
+		"https://github.com/owner/fork.git",
+		"git@gitlab.com:owner/repo.git",
+		"https://gitlab.example.com:8443/owner/repo.git",
+		"https://elsewhere.example.com/owner/repo.git",
+		"http://github.com/owner/repo.git",
+	})
+	header := "Authorization: Basic bWU6dG9r" // base64("me:tok")
+	want := []string{
+		"GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader",
+		"GIT_CONFIG_VALUE_0=" + header,
+		"GIT_CONFIG_KEY_1=http.https://gitlab.example.com:8443/.extraHeader",
+		"GIT_CONFIG_VALUE_1=" + header,
+		"GIT_CONFIG_COUNT=2",
+	}
+	if strings.Join(env, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tokenEnv =\n%s\nwant\n%s", strings.Join(env, "\n"), strings.Join(want, "\n"))
+	}
+
+	if env := tokenEnv(AuthConfig{}, []string{"https://github.com/owner/repo.git"}); env != nil {
+		t.Errorf("tokenEnv without token = %v, want nil", env)
+	}
+	if env := tokenEnv(cfg, []string{"git@github.com:owner/repo.git"}); env != nil {
+		t.Errorf("tokenEnv for SSH remote = %v, want nil", env)
+	}
+	if auth := httpsAuthFor(cfg, "http://github.com/owner/repo.git"); auth != nil {
+		t.Errorf("auth for http:// remote = %v, want nil", auth)
+	}
+	if auth := httpsAuthFor(cfg, "https://elsewhere.example.com/owner/repo.git"); auth != nil {
+		t.Errorf("auth for unlisted host = %v, want nil", auth)
+	}
+	if auth := httpsAuthFor(cfg, "https://github.com/owner/repo.git"); auth == nil {
+		t.Error("auth for github.com = nil, want basic auth")
+	}
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	if env := tokenEnv(cfg, []string{"https://github.com/owner/repo.git"}); env != nil {
+		t.Errorf("tokenEnv with GIT_CONFIG_COUNT set = %v, want nil", env)
+	}
+}
+
+func TestIdentityFileAuth(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain")
+	sshKeygen(t, plain, "")
+	if _, err := identityFileAuth(plain); err != nil {
+		t.Errorf("unencrypted key: %v", err)
+	}
+
+	encrypted := filepath.Join(dir, "encrypted")
+	sshKeygen(t, encrypted, "secret")
+	t.Setenv("HYDRA_SSH_PASSPHRASE", "secret")
+	if _, err := identityFileAuth(encrypted); err != nil {
+		t.Errorf("encrypted key with passphrase: %v", err)
+	}
+
+	if _, err := identityFileAuth(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing identity file")
+	}
+}
+
+// sshKeygen writes a new ed25519 key to path, encrypted with passphrase.
+func sshKeygen(t *testing.T, path, passphrase string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "ssh-keygen", "-q", "-t", "ed25519", "-N", passphrase, "-f", path) //nolint:gosec // test with controlled args
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, out)
+	}
+}
+
+// writeTestFile writes content to path.
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/home/me/it's key"); got != `'/home/me/it'\''s key'` {
+		t.Errorf("shellQuote = %s", got)
+	}
+}
+
+func TestNewSignerOpenPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("Test", "", "test@test.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key bytes.Buffer
+	w, err := armor.Encode(&key, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.SerializePrivate(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.asc")
+	if err := os.WriteFile(keyFile, key.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewSigner(SigningConfig{KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	sig, err := signer.Sign(strings.NewReader("tree abc\n\nmessage\n"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	keyring := openpgp.EntityList{entity}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader("tree abc\n\nmessage\n"), bytes.NewReader(sig), nil); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+}
+
+func TestNewSignerSSH(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing")
+	sshKeygen(t, keyFile, "secret")
+	t.Setenv("HYDRA_SIGNING_PASSPHRASE", "secret")
+
+	signer, err := NewSigner(SigningConfig{Format: SigningFormatSSH, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	message := "tree abc\n\nmessage\n"
+	sig, err := signer.Sign(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// Verify the way git does for gpg.format=ssh.
+	pub, err := os.ReadFile(keyFile + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(dir, "allowed_signers")
+	writeTestFile(t, allowed, "test@test.com "+string(pub))
+	sigFile := filepath.Join(dir, "message.sig")
+	writeTestFile(t, sigFile, string(sig))
+	cmd := exec.CommandContext(context.Background(), "ssh-keygen", "-Y", "verify", "-f", allowed, "-I", "test@test.com", "-n", "git", "-s", sigFile) //nolint:gosec // test with controlled args
+	cmd.Stdin = strings.NewReader(message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("ssh-keygen -Y verify: %v\n%s", err, out)
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	if signer, err := NewSigner(SigningConfig{}); signer != nil || err != nil {
+		t.Errorf("NewSigner without key = %v, %v; want nil, nil", signer, err)
+	}
+	if _, err := NewSigner(SigningConfig{KeyFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected error for missing key file")
+	}
+	keyFile := filepath.Join(t.TempDir(), "key")
+	writeTestFile(t, keyFile, "not a key")
+	if _, err := NewSigner(SigningConfig{Format: "x509", KeyFile: keyFile}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
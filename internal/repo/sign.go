@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/erikh/hydra/internal/credstore"
+	"github.com/go-git/go-git/v5"
+	"go.yaml.in/yaml/v4"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signing key formats.
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+)
+
+// SigningConfig configures native commit signing, which signs the commits
+// hydra makes itself without gpg, gpg-agent, or pinentry.
+type SigningConfig struct {
+	Format  string `yaml:"format"`   // "openpgp" (default) or "ssh"
+	KeyFile string `yaml:"key_file"` // armored OpenPGP private key, or SSH private key
+}
+
+// LoadSigningConfig reads the signing section of ~/.hydra.yml. A missing or
+// unreadable file disables native signing. A leading ~/ in key_file is
+// expanded to the home directory.
+func LoadSigningConfig() SigningConfig {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return SigningConfig{}
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".hydra.yml")) //nolint:gosec // well-known user config path
+	if err != nil {
+		return SigningConfig{}
+	}
+
+	var cfg struct {
+		Signing SigningConfig `yaml:"signing"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return SigningConfig{}
+	}
+	if rest, ok := strings.CutPrefix(cfg.Signing.KeyFile, "~/"); ok {
+		cfg.Signing.KeyFile = filepath.Join(home, rest)
+	}
+	return cfg.Signing
+}
+
+// commitSigner is the signer built from ~/.hydra.yml, loaded once per
+// process so an encrypted key's passphrase is asked for at most once. It is
+// nil when native signing is not configured.
+var commitSigner = sync.OnceValues(func() (git.Signer, error) {
+	return NewSigner(LoadSigningConfig())
+})
+
+// NewSigner loads the key in cfg and returns a signer for go-git commits, or
+// nil if cfg has no key file. An encrypted key is unlocked with the
+// passphrase from HYDRA_SIGNING_PASSPHRASE or the credential store (hydra
+// auth login signing), or, on a terminal, by prompting for it.
+func NewSigner(cfg SigningConfig) (git.Signer, error) {
+	if cfg.KeyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(cfg.KeyFile) //nolint:gosec // key path from user config
+	if err != nil {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+
+	switch cfg.Format {
+	case "", SigningFormatOpenPGP:
+		return newOpenPGPSigner(cfg.KeyFile, data)
+	case SigningFormatSSH:
+		return newSSHSigner(cfg.KeyFile, data)
+	default:
+		return nil, fmt.Errorf("unknown signing format %q (supported: %s, %s)", cfg.Format, SigningFormatOpenPGP, SigningFormatSSH)
+	}
+}
+
+// signingPassphrase returns the passphrase for an encrypted signing key.
+func signingPassphrase(path string) (string, error) {
+	if passphrase := credstore.Lookup(credstore.ProviderSigning); passphrase != "" {
+		return passphrase, nil
+	}
+	return promptPassphrase(path, credstore.ProviderSigning)
+}
+
+// openPGPSigner signs with an OpenPGP private key.
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+func newOpenPGPSigner(path string, data []byte) (git.Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading OpenPGP key %s: %w", path, err)
+	}
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, fmt.Errorf("OpenPGP key %s has no private key", path)
+	}
+	if entity.PrivateKey.Encrypted {
+		passphrase, err := signingPassphrase(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("decrypting OpenPGP key %s: %w", path, err)
+		}
+	}
+	return &openPGPSigner{entity: entity}, nil
+}
+
+// Sign returns an armored detached signature of message.
+func (s *openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, message, nil); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// sshSigNamespace is the namespace git uses for SSH commit signatures.
+const sshSigNamespace = "git"
+
+// sshSigner signs with an SSH private key, producing the armored SSHSIG
+// format that git verifies with gpg.format=ssh.
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+func newSSHSigner(path string, data []byte) (git.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		passphrase, perr := signingPassphrase(path)
+		if perr != nil {
+			return nil, perr
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading SSH signing key %s: %w", path, err)
+	}
+	return &sshSigner{signer: signer}, nil
+}
+
+// Sign returns an armored SSHSIG signature of message, as described in
+// OpenSSH's PROTOCOL.sshsig.
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, err
+	}
+
+	var signed []byte
+	signed = append(signed, "SSHSIG"...)
+	signed = appendSSHString(signed, []byte(sshSigNamespace))
+	signed = appendSSHString(signed, nil) // reserved
+	signed = appendSSHString(signed, []byte("sha512"))
+	signed = appendSSHString(signed, h.Sum(nil))
+
+	var sig *ssh.Signature
+	var err error
+	if as, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// SSHSIG requires SHA-2 for RSA keys.
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing: %w", err)
+	}
+
+	var blob []byte
+	blob = append(blob, "SSHSIG"...)
+	blob = binary.BigEndian.AppendUint32(blob, 1)
+	blob = appendSSHString(blob, s.signer.PublicKey().Marshal())
+	blob = appendSSHString(blob, []byte(sshSigNamespace))
+	blob = appendSSHString(blob, nil)
+	blob = appendSSHString(blob, []byte("sha512"))
+	blob = appendSSHString(blob, ssh.Marshal(sig))
+
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	var b bytes.Buffer
+	b.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		b.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	b.WriteString(encoded + "\n")
+	b.WriteString("-----END SSH SIGNATURE-----\n")
+	return b.Bytes(), nil
+}
+
+// appendSSHString appends s in the SSH wire format: a uint32 length followed
+// by the bytes.
+func appendSSHString(b, s []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s))) //nolint:gosec // lengths are far below 4GiB
+	return append(b, s...)
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/erikh/hydra/internal/tracing"
+	"github.com/go-git/go-git/v5"
+)
+
+// ErrLFSNotInstalled is returned by PullLFS when the repository uses Git LFS
+// but the git-lfs command is not installed.
+var ErrLFSNotInstalled = errors.New("repository uses Git LFS but git-lfs is not installed")
+
+// HasSubmodules reports whether the checked-out tree declares submodules.
+func (r *Repo) HasSubmodules() bool {
+	_, err := os.Stat(filepath.Join(r.Dir, ".gitmodules"))
+	return err == nil
+}
+
+// UsesLFS reports whether the checked-out tree's .gitattributes routes any
+// files through Git LFS.
+func (r *Repo) UsesLFS() bool {
+	data, err := os.ReadFile(filepath.Join(r.Dir, ".gitattributes")) //nolint:gosec // path inside the repository
+	return err == nil && strings.Contains(string(data), "filter=lfs")
+}
+
+// UpdateSubmodules initializes and updates the repository's submodules,
+// recursively, to the commits the checked-out tree records. It does nothing
+// if there are no submodules. Submodules are updated with go-git, or the
+// git CLI for HTTPS remotes. go-git can't handle every setup, such as
+// submodules hosted elsewhere than origin with different credentials, so
+// the CLI is also tried when go-git fails.
+func (r *Repo) UpdateSubmodules() (err error) {
+	if !r.HasSubmodules() {
+		return nil
+	}
+	ctx, span := tracing.StartChild(r.Context(), "submodule update")
+	defer func() { tracing.End(span, err) }()
+	r = r.WithContext(ctx)
+
+	if err := r.ensure(); err != nil {
+		return err
+	}
+	r.resolveAuth()
+	if r.isHTTPS() {
+		return r.updateSubmodulesCLI()
+	}
+
+	w, err := r.repo.Worktree()
+	if err == nil {
+		var subs git.Submodules
+		if subs, err = w.Submodules(); err == nil {
+			err = subs.Update(&git.SubmoduleUpdateOptions{
+				Init:              true,
+				RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
+				Auth:              r.auth,
+			})
+		}
+	}
+	if err != nil {
+		return r.updateSubmodulesCLI()
+	}
+	return nil
+}
+
+// updateSubmodulesCLI runs git submodule update --init --recursive.
+func (r *Repo) updateSubmodulesCLI() error {
+	_, err := r.run("submodule", "update", "--init", "--recursive")
+	return err
+}
+
+// PullLFS downloads the Git LFS objects for the checked-out tree and
+// replaces their pointer files. It does nothing if the repository doesn't
+// use LFS, and returns ErrLFSNotInstalled if it does but git-lfs is
+// missing. go-git has no LFS support, so this always uses the git CLI.
+func (r *Repo) PullLFS() (err error) {
+	if !r.UsesLFS() {
+		return nil
+	}
+	if _, err := exec.LookPath("git-lfs"); err != nil {
+		return ErrLFSNotInstalled
+	}
+	ctx, span := tracing.StartChild(r.Context(), "lfs pull")
+	defer func() { tracing.End(span, err) }()
+	r = r.WithContext(ctx)
+
+	_, err = r.run("lfs", "pull")
+	return err
+}
@@ -0,0 +1,92 @@
+package repo
+
+import "strings"
+
+// rewrittenPrefix is where Fetch keeps the tip each origin branch had
+// before a fetch found it force-pushed. Keeping it in a ref shares it with
+// every worktree of the repository and keeps the old commits from being
+// garbage collected.
+const rewrittenPrefix = "refs/hydra/rewritten/"
+
+// trackingTips returns the commit each of origin's remote-tracking refs
+// points at, keyed by ref name (refs/remotes/origin/<branch>).
+func (r *Repo) trackingTips() map[string]string {
+	out, err := r.run("for-each-ref", "--format=%(refname) %(objectname)", "refs/remotes/origin/")
+	if err != nil {
+		return nil
+	}
+	tips := make(map[string]string)
+	for line := range strings.SplitSeq(out, "\n") {
+		if name, sha, ok := strings.Cut(line, " "); ok {
+			tips[name] = sha
+		}
+	}
+	return tips
+}
+
+// recordRewrites compares origin's remote-tracking refs with their tips
+// before a fetch, and records the old tip of every ref that moved to a
+// commit that doesn't descend from it: a branch whose history was
+// rewritten by a force-push. The record replaces any earlier one for the
+// branch.
+func (r *Repo) recordRewrites(before map[string]string) {
+	for name, sha := range r.trackingTips() {
+		old, ok := before[name]
+		if !ok || old == sha || r.IsAncestor(old, sha) {
+			continue
+		}
+		branch := strings.TrimPrefix(name, "refs/remotes/")
+		_, _ = r.run("update-ref", rewrittenPrefix+branch, old)
+	}
+}
+
+// RewrittenTip returns the tip a remote-tracking ref such as origin/main
+// had before the last fetch that found it force-pushed, if a fetch ever
+// did and the old tip is still not part of the ref's history.
+func (r *Repo) RewrittenTip(ref string) (string, bool) {
+	commit, err := r.resolveCommit(rewrittenPrefix + ref)
+	if err != nil {
+		return "", false
+	}
+	old := commit.Hash.String()
+	if r.IsAncestor(old, ref) {
+		return "", false
+	}
+	return old, true
+}
+
+// RebaseOnto runs git rebase --onto onto upstream, replaying only the
+// current branch's commits after upstream.
+func (r *Repo) RebaseOnto(onto, upstream string) error {
+	_, err := r.run("rebase", "--onto", onto, upstream)
+	return err
+}
+
+// originHead is the symbolic ref recording origin's default branch.
+const originHead = "refs/remotes/origin/HEAD"
+
+// OriginHead returns origin's default branch, the one
+// refs/remotes/origin/HEAD points at, if the repository records it.
+func (r *Repo) OriginHead() (string, bool) {
+	out, err := r.run("symbolic-ref", "--short", originHead)
+	if err != nil {
+		return "", false
+	}
+	return strings.CutPrefix(out, "origin/")
+}
+
+// setOriginHead points refs/remotes/origin/HEAD at origin's branch.
+func (r *Repo) setOriginHead(branch string) error {
+	_, err := r.run("symbolic-ref", originHead, "refs/remotes/origin/"+branch)
+	return err
+}
+
+// ensureOriginHead asks origin for its default branch and records it, if
+// the repository doesn't know it yet. go-git clones don't record it, and
+// fetches never update it.
+func (r *Repo) ensureOriginHead() {
+	if _, ok := r.OriginHead(); ok {
+		return
+	}
+	_, _ = r.run("remote", "set-head", "origin", "--auto")
+}
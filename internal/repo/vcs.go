@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// VCS is the version control backend of a task's work directory. Repo
+// implements it with git; JJ implements it for Jujutsu repositories
+// colocated with git.
+type VCS interface {
+	// Traced returns a copy of the backend whose commands are traced as
+	// children of the span in ctx.
+	Traced(ctx context.Context) VCS
+	Context() context.Context
+	// WorkDir returns the directory of the working copy.
+	WorkDir() string
+
+	CurrentBranch() (string, error)
+	BranchExists(name string) bool
+	OriginHead() (string, bool)
+	CreateBranch(name string) error
+	Checkout(name string) error
+
+	HasChanges() (bool, error)
+	HasSigningKey() bool
+	AddAll() error
+	Commit(message string, sign bool) error
+	Clean() error
+	ResetHard(ref string) error
+	GitDir() (string, error)
+
+	Fetch() error
+	Push(branch string) error
+	ForcePushWithLease(branch string) error
+	PushMain() error
+	DeleteRemoteBranch(name string) error
+	PushRemote() string
+	RemoteURL() (string, error)
+	UpdateSubmodules() error
+	PullLFS() error
+
+	Rebase(onto string) error
+	RebaseOnto(onto, upstream string) error
+	RebaseAbort() error
+	Revert(base, head string) error
+	CherryPick(base, head string) error
+	Conflicts(base, head string) ([]Conflict, error)
+	TrialRebase(ref, onto string) ([]string, error)
+	TrialRebaseWorktree(ref, onto string) (*Repo, []string, func(), error)
+
+	LastCommitSHA() (string, error)
+	IsAncestor(ancestor, ref string) bool
+	RewrittenTip(ref string) (string, bool)
+	MergeBase(a, b string) (string, error)
+	AheadBehind(ref, upstream string) (ahead, behind int, err error)
+	CommitInfo(ref string) (string, time.Time, error)
+	CommitBefore(ref string, t time.Time) (string, error)
+	CommitSubjects(base, head string) ([]string, error)
+	CommitMessages(base, head string) (string, error)
+	CommitDiffs(base, head string) ([]CommitDiff, error)
+	ChangedFiles(base, head string) ([]string, error)
+	DiffRange(base, head string) (string, error)
+	DiffRangePaths(base, head string, paths []string) (string, error)
+	DiffStat(base, head string) (string, error)
+	LineStats(base, head string) (added, deleted int, err error)
+}
+
+var (
+	_ VCS = (*Repo)(nil)
+	_ VCS = (*JJ)(nil)
+)
+
+// Backends that hydra.yml's vcs setting accepts.
+const (
+	// BackendGit keeps work directories as git worktrees. It is the default.
+	BackendGit = "git"
+	// BackendJJ keeps work directories as Jujutsu repositories colocated
+	// with git.
+	BackendJJ = "jj"
+)
+
+// Traced returns a copy of the repo whose git commands are traced as
+// children of the span in ctx.
+func (r *Repo) Traced(ctx context.Context) VCS {
+	return r.WithContext(ctx)
+}
+
+// WorkDir returns r.Dir.
+func (r *Repo) WorkDir() string {
+	return r.Dir
+}
+
+// OpenVCS opens the work directory at dir with the backend that manages it:
+// JJ if it holds a .jj directory, otherwise git.
+func OpenVCS(dir string) VCS {
+	if IsJJRepo(dir) {
+		return OpenJJ(dir)
+	}
+	return Open(dir)
+}
+
+// IsJJRepo returns true if dir contains a .jj directory.
+func IsJJRepo(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ".jj"))
+	return err == nil && info.IsDir()
+}
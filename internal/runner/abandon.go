@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/mattn/go-isatty"
+)
+
+// AbandonOpts controls the cleanup offered when a task is abandoned.
+type AbandonOpts struct {
+	Yes  bool // run every cleanup step without asking
+	Keep bool // skip cleanup and only move the task to abandoned
+}
+
+// confirmAbandon shows the start of the task and asks whether to abandon
+// it. It doesn't ask with opts.Yes or when stdin is not a terminal.
+func confirmAbandon(task *design.Task, opts AbandonOpts) bool {
+	if opts.Yes || !isatty.IsTerminal(os.Stdin.Fd()) {
+		return true
+	}
+	content, err := task.Content()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	fmt.Printf("Task %s (%s):\n%s", taskLabel(*task), task.State, design.Preview(content))
+	return Confirm(fmt.Sprintf("Abandon task %q?", taskLabel(*task)))
+}
+
+// abandonTask moves a task to abandoned and then walks through cleaning up
+// what it left behind: the local work directory, the hydra/* branch on
+// origin, and the originating issue. Each step is confirmed unless opts.Yes
+// is set. Cleanup failures are reported as warnings; the task stays
+// abandoned either way.
+func (r *Runner) abandonTask(task *design.Task, opts AbandonOpts) error {
+	wd := r.workDir(task)
+	branch := task.BranchName()
+	label := taskLabel(*task)
+	if !opts.Keep {
+		release, err := r.claimWorkDir(task)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	if err := r.Design.MoveTask(task, design.StateAbandoned); err != nil {
+		return err
+	}
+	fmt.Printf("Task %q abandoned.\n", label)
+
+	if opts.Keep {
+		return nil
+	}
+
+	ask := func(prompt string) bool {
+		return opts.Yes || Confirm(prompt)
+	}
+
+	if _, err := os.Stat(wd); err == nil && ask(fmt.Sprintf("Delete work directory %s?", wd)) {
+		if err := r.removeWorkDir(wd); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not remove work directory: %v\n", err)
+		} else {
+			fmt.Printf("Removed %s\n", wd)
+		}
+	}
+
+	if r.Config != nil && ask(fmt.Sprintf("Delete branch %s from origin?", branch)) {
+		sourceRepo := repo.Open(r.Config.RepoDir)
+		if err := sourceRepo.DeleteRemoteBranch(branch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not delete remote branch %q: %v\n", branch, err)
+		} else {
+			fmt.Printf("Deleted %s from origin\n", branch)
+		}
+	}
+
+	if r.IssueCloser != nil {
+		if num := issues.IssueNumber(task); num > 0 && ask(fmt.Sprintf("Close issue #%d?", num)) {
+			comment := "Closed by hydra: this task was abandoned and its changes will not be merged."
+			if err := r.IssueCloser.CloseIssue(num, comment); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not close issue #%d: %v\n", num, err)
+			} else {
+				fmt.Printf("Closed issue #%d\n", num)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+)
+
+type recordingCloser struct {
+	closed []int
+}
+
+func (c *recordingCloser) CloseIssue(number int, _ string) error {
+	c.closed = append(c.closed, number)
+	return nil
+}
+
+func TestReviewRemoveCleanup(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	closer := &recordingCloser{}
+	r.IssueCloser = closer
+
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review", "issues"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "issues", "42-fix-bug.md"), "Fix it.")
+	wd := filepath.Join(r.BaseDir, config.HydraDir, "work", "issues", "42-fix-bug")
+	mkdirAll(t, wd)
+
+	if err := r.ReviewRemove("issues/42-fix-bug", AbandonOpts{Yes: true}); err != nil {
+		t.Fatalf("ReviewRemove: %v", err)
+	}
+
+	if _, err := r.Design.FindTaskByState("issues/42-fix-bug", design.StateAbandoned); err != nil {
+		t.Errorf("task should be abandoned: %v", err)
+	}
+	if _, err := os.Stat(wd); !os.IsNotExist(err) {
+		t.Errorf("work directory should be removed, stat err = %v", err)
+	}
+	if len(closer.closed) != 1 || closer.closed[0] != 42 {
+		t.Errorf("closed issues = %v, want [42]", closer.closed)
+	}
+}
+
+func TestReviewRemoveKeep(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	closer := &recordingCloser{}
+	r.IssueCloser = closer
+
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review", "issues"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "issues", "7-typo.md"), "Typo.")
+	wd := filepath.Join(r.BaseDir, config.HydraDir, "work", "issues", "7-typo")
+	mkdirAll(t, wd)
+
+	if err := r.ReviewRemove("issues/7-typo", AbandonOpts{Keep: true}); err != nil {
+		t.Fatalf("ReviewRemove: %v", err)
+	}
+
+	if _, err := os.Stat(wd); err != nil {
+		t.Errorf("work directory should be kept: %v", err)
+	}
+	if len(closer.closed) != 0 {
+		t.Errorf("no issues should be closed, got %v", closer.closed)
+	}
+}
+
+func TestReviewRemoveUnregistersWorktree(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaude
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	wd := workDirForTask(env.BaseDir)
+
+	if err := r.ReviewRemove("add-feature", AbandonOpts{Yes: true}); err != nil {
+		t.Fatalf("ReviewRemove: %v", err)
+	}
+
+	// The work directory is removed as a worktree, so the source
+	// repository doesn't keep a stale entry for it.
+	out, err := exec.CommandContext(context.Background(), "git", "-C", env.Config.RepoDir, "worktree", "list", "--porcelain").Output() //nolint:gosec // test
+	if err != nil {
+		t.Fatalf("git worktree list: %v", err)
+	}
+	if strings.Contains(string(out), wd) {
+		t.Errorf("worktree %s still registered:\n%s", wd, out)
+	}
+}
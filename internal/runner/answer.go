@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/platform"
+	"github.com/mattn/go-isatty"
+)
+
+// question is a yes or no question a run waits on, answered at the prompt
+// or by another hydra command through a file.
+type question struct {
+	prompt  string          // asked when stdin is a terminal, e.g. "Push? [y/N] "
+	waiting string          // printed instead when it is not
+	path    string          // file another hydra command answers through
+	answers map[string]bool // answer given by each content of path
+}
+
+// await waits for the answer to q. The prompt is only asked if interactive
+// is set and stdin is a terminal that can be polled; stdin is read only
+// while a line is waiting, so nothing is left reading it once the question
+// is answered. It returns the answer and whether it came through the file,
+// or ctx's error if ctx ends first.
+func (q question) await(ctx context.Context, interactive bool) (ok, fromFile bool, err error) {
+	if interactive && isatty.IsTerminal(os.Stdin.Fd()) {
+		if _, err := platform.WaitReadable(os.Stdin, 0); err != nil {
+			interactive = false
+		}
+	} else {
+		interactive = false
+	}
+	if interactive {
+		fmt.Print(q.prompt)
+	} else {
+		fmt.Println(q.waiting)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, false, err
+		}
+		if data, err := os.ReadFile(q.path); err == nil { //nolint:gosec // path inside .hydra
+			if ok, answered := q.answers[string(data)]; answered {
+				return ok, true, nil
+			}
+		}
+		if !interactive {
+			select {
+			case <-ctx.Done():
+			case <-time.After(approvalPoll):
+			}
+			continue
+		}
+		ready, err := platform.WaitReadable(os.Stdin, approvalPoll)
+		if err != nil {
+			return false, false, fmt.Errorf("waiting for an answer: %w", err)
+		}
+		if ready {
+			return readAnswer(), false, nil
+		}
+	}
+}
+
+// readAnswer reads the line waiting on stdin and reports whether it is yes.
+// A terminal hands over whole lines, so one read takes the line without
+// blocking or reading past it.
+func readAnswer() bool {
+	buf := make([]byte, 256)
+	n, err := os.Stdin.Read(buf)
+	input := strings.TrimSpace(strings.ToLower(string(buf[:n])))
+	return err == nil && (input == "y" || input == "yes")
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// errPushRejected is returned by a run whose commits were not approved for
+// pushing.
+var errPushRejected = errors.New("push not approved")
+
+// Contents of the approval file of a run waiting for approval.
+const (
+	approvalWaiting  = "waiting\n"
+	approvalApproved = "approved\n"
+)
+
+// approvalPoll is how often a run waiting for approval checks for hydra
+// approve.
+var approvalPoll = time.Second
+
+// approvalPath returns the file through which hydra approve approves a
+// task's run.
+func approvalPath(hydraDir string, task *design.Task) string {
+	if task.Group != "" {
+		return filepath.Join(hydraDir, "approvals", task.Group, task.Name)
+	}
+	return filepath.Join(hydraDir, "approvals", task.Name)
+}
+
+// awaitPushApproval shows the commits a run is about to push and waits until
+// they are approved, at the prompt if interactive or by hydra approve from
+// another terminal. It returns errPushRejected if they are turned down at
+// the prompt, or ctx's error if ctx ends first.
+func awaitPushApproval(ctx context.Context, taskRepo repo.VCS, hydraDir string, task *design.Task, beforeSHA, afterSHA string, interactive bool) error {
+	fmt.Print(pushSummary(taskRepo, beforeSHA, afterSHA))
+
+	path := approvalPath(hydraDir, task)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating approvals dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(approvalWaiting), 0o600); err != nil {
+		return fmt.Errorf("writing approval file: %w", err)
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	label := taskLabel(*task)
+	ok, fromFile, err := question{
+		prompt:  fmt.Sprintf("Push and move %q to review? Answer here or run 'hydra approve %s'. [y/N] ", label, label),
+		waiting: fmt.Sprintf("Waiting for 'hydra approve %s' to push and move it to review.", label),
+		path:    path,
+		answers: map[string]bool{approvalApproved: true},
+	}.await(ctx, interactive)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return fmt.Errorf("%w; the commits are kept in the work directory", errPushRejected)
+	case fromFile:
+		fmt.Println("\nApproved by hydra approve.")
+	}
+	return nil
+}
+
+// pushSummary describes the commits from beforeSHA to afterSHA for approval:
+// a diff stat followed by the commit messages.
+func pushSummary(taskRepo repo.VCS, beforeSHA, afterSHA string) string {
+	var b strings.Builder
+	b.WriteString("\nChanges to push:\n\n")
+	if stat, err := taskRepo.DiffStat(beforeSHA, afterSHA); err == nil {
+		b.WriteString(stat)
+	} else {
+		fmt.Fprintf(&b, "(diff stat unavailable: %v)\n", err)
+	}
+	b.WriteString("\n")
+	if msgs, err := taskRepo.CommitMessages(beforeSHA, afterSHA); err == nil {
+		b.WriteString(msgs + "\n")
+	} else {
+		fmt.Fprintf(&b, "(commit messages unavailable: %v)\n", err)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Approve approves the push of a task's commits by a run waiting for it
+// under approve_before_push.
+func (r *Runner) Approve(taskName string) error {
+	task, err := r.Design.FindTask(taskName)
+	if err != nil {
+		return err
+	}
+
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	path := approvalPath(config.HydraPath(baseDir), task)
+	data, err := os.ReadFile(path) //nolint:gosec // path inside .hydra
+	if err != nil || string(data) != approvalWaiting {
+		return fmt.Errorf("no run of %q is waiting for approval", taskName)
+	}
+	if err := os.WriteFile(path, []byte(approvalApproved), 0o600); err != nil {
+		return fmt.Errorf("writing approval file: %w", err)
+	}
+	fmt.Printf("Approved %q for push.\n", taskName)
+	return nil
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+func TestAwaitPushApprovalByApprove(t *testing.T) {
+	approvalPoll = 10 * time.Millisecond
+	t.Cleanup(func() { approvalPoll = time.Second })
+
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	hydraDir := config.HydraPath(r.BaseDir)
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add it.\n")
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Approve("add-feature"); err == nil {
+		t.Fatal("Approve succeeded with no run waiting")
+	}
+
+	wd := initPreVerifyRepo(t)
+	taskRepo := repo.Open(wd)
+	before, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(wd, "feature.go"), "package main\n")
+	if err := mockCommit(wd); err != nil {
+		t.Fatal(err)
+	}
+	after, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- awaitPushApproval(context.Background(), taskRepo, hydraDir, task, before, after, true) }()
+
+	path := approvalPath(hydraDir, task)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("run never started waiting for approval")
+		}
+	}
+	if err := r.Approve("add-feature"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("awaitPushApproval: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("awaitPushApproval did not return after approval")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("approval file left behind: %v", err)
+	}
+}
+
+func TestPushSummary(t *testing.T) {
+	wd := initPreVerifyRepo(t)
+	taskRepo := repo.Open(wd)
+	before, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(wd, "feature.go"), "package main\n")
+	if err := mockCommit(wd); err != nil {
+		t.Fatal(err)
+	}
+
+	got := pushSummary(taskRepo, before, "HEAD")
+	for _, want := range []string{"feature.go", "mock commit"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestQuestionAwaitCanceled(t *testing.T) {
+	approvalPoll = 10 * time.Millisecond
+	t.Cleanup(func() { approvalPoll = time.Second })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := question{path: filepath.Join(t.TempDir(), "approval")}.await(ctx, false)
+		done <- err
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("await = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("await did not return after its context ended")
+	}
+}
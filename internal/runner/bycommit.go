@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/mattn/go-isatty"
+)
+
+// reviewCommits returns the section of a review document that splits the
+// branch's changes into its commits (hydra review run --by-commit).
+func (r *Runner) reviewCommits(taskRepo repo.VCS, branch string) (string, error) {
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return "", fmt.Errorf("detecting default branch: %w", err)
+	}
+	commits, err := taskRepo.CommitDiffs("origin/"+defaultBranch, branch)
+	if err != nil {
+		return "", fmt.Errorf("listing commits: %w", err)
+	}
+	return commitsSection(commits), nil
+}
+
+// commitsSection lists commits with their messages and diffs and asks
+// Claude to check each message against the changes it describes. It is
+// empty if there are no commits.
+func commitsSection(commits []repo.CommitDiff) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n# Commits Under Review\n\n")
+	b.WriteString("The branch's changes are split into its commits below, oldest first. " +
+		"As part of the review, check each commit's message against its diff: the message must " +
+		"describe what the commit actually changes, without claiming changes it doesn't make or " +
+		"leaving out significant ones it does. Reword any commit whose message doesn't match its " +
+		"contents, keeping the commit's changes as they are, and mention the rewording in your summary.\n")
+	for _, c := range commits {
+		b.WriteString("\n## Commit " + c.SHA + "\n\n")
+		b.WriteString(fence("", c.Message))
+		b.WriteString("\n")
+		if strings.TrimSpace(c.Diff) == "" {
+			b.WriteString("This commit changes no files.\n")
+			continue
+		}
+		b.WriteString(fence("diff", c.Diff))
+	}
+	return b.String()
+}
+
+// formatCommitDiffs renders commits like git log -p: each commit's hash,
+// its indented message, and its diff.
+func formatCommitDiffs(commits []repo.CommitDiff) string {
+	var b strings.Builder
+	for i, c := range commits {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("commit " + c.SHA + "\n\n")
+		for _, line := range strings.Split(c.Message, "\n") {
+			b.WriteString(strings.TrimRight("    "+line, " ") + "\n")
+		}
+		if c.Diff != "" {
+			b.WriteString("\n" + strings.TrimRight(c.Diff, "\n") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// page shows text through $PAGER, less by default, when stdout is a
+// terminal, and prints it otherwise or if the pager can't be started.
+func page(text string) {
+	if !isatty.IsTerminal(os.Stdout.Fd()) {
+		fmt.Print(text)
+		return
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -FRX"
+	}
+	cmd := exec.Command("sh", "-c", pager) //nolint:gosec // the user's own pager
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err == nil || (errors.As(err, &exitErr) && exitErr.ExitCode() != 127) {
+		return
+	}
+	fmt.Print(text)
+}
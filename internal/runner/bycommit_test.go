@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+func TestCommitsSection(t *testing.T) {
+	if got := commitsSection(nil); got != "" {
+		t.Errorf("commitsSection(nil) = %q, want empty", got)
+	}
+
+	commits := []repo.CommitDiff{
+		{SHA: "abc1234", Message: "Add login\n\nWith a form.", Diff: "+login\n"},
+		{SHA: "def5678", Message: "Empty commit"},
+	}
+	got := commitsSection(commits)
+	for _, want := range []string{
+		"# Commits Under Review",
+		"check each commit's message against its diff",
+		"## Commit abc1234",
+		"Add login\n\nWith a form.",
+		"```diff\n+login\n```",
+		"## Commit def5678",
+		"This commit changes no files.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("section missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "abc1234") > strings.Index(got, "def5678") {
+		t.Error("commits are out of order")
+	}
+}
+
+func TestFormatCommitDiffs(t *testing.T) {
+	got := formatCommitDiffs([]repo.CommitDiff{
+		{SHA: "abc1234", Message: "Add login\n\nWith a form.", Diff: "+login\n"},
+		{SHA: "def5678", Message: "Fix typo", Diff: "-a\n+b"},
+	})
+	want := "commit abc1234\n\n    Add login\n\n    With a form.\n\n+login\n" +
+		"\ncommit def5678\n\n    Fix typo\n\n-a\n+b\n"
+	if got != want {
+		t.Errorf("formatCommitDiffs =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+// checklistSection returns a markdown section listing the review.md
+// checklist as numbered checks, with instructions for reporting results.
+// Returns empty string if there are no items.
+func checklistSection(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Review Checklist\n\n")
+	b.WriteString("Verify each of the following checks against this task's changes, " +
+		"fixing anything that fails where possible:\n\n")
+	for i, item := range items {
+		b.WriteString(stepPrefix(i + 1))
+		b.WriteString(item)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nWhen you are finished, end your final message with one line per check, " +
+		"in order, using exactly this format:\n\n")
+	b.WriteString("```\n1. PASS\n2. FAIL: <short reason>\n```\n\n")
+	return b.String()
+}
+
+// collectChecklistResults parses the checklist results from the end of
+// Claude's final message. A message without results yields nil.
+func collectChecklistResults(finalMessage string, items []string) []design.ChecklistResult {
+	if len(items) == 0 {
+		return nil
+	}
+	results := design.ParseChecklistResults(finalMessage, items)
+	if len(results) == 0 {
+		return nil
+	}
+	printChecklistResults(results, len(items))
+	return results
+}
+
+// printChecklistResults prints a one-line-per-item summary of checklist results.
+func printChecklistResults(results []design.ChecklistResult, total int) {
+	passed := 0
+	fmt.Println("Review checklist:")
+	for _, res := range results {
+		status := "FAIL"
+		if res.Passed {
+			status = "PASS"
+			passed++
+		}
+		line := fmt.Sprintf("  [%s] %s", status, res.Item)
+		if res.Note != "" {
+			line += " — " + res.Note
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("  %d/%d passed", passed, total)
+	if missing := total - len(results); missing > 0 {
+		fmt.Printf(", %d not reported", missing)
+	}
+	fmt.Println()
+}
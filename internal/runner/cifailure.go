@@ -0,0 +1,206 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/credstore"
+	"github.com/erikh/hydra/internal/design"
+)
+
+// Bounds on the failure output copied from a CI log into a task.
+const (
+	failureContextBefore = 5   // lines kept before a failure line
+	failureContextAfter  = 20  // lines kept after a failure line
+	maxFailureLines      = 200 // lines kept in total
+	failureTailLines     = 80  // lines kept from the end when no failure line is recognized
+	maxCILogBytes        = 64 << 20
+)
+
+var (
+	// failureLineRe matches lines that report a failing test or build step
+	// in the output of common test runners, compilers, and CI systems.
+	failureLineRe = regexp.MustCompile(`(?i)(^\s*--- FAIL|^FAIL\b|\bFAILED\b|^panic:|\berror\b(:|\[|\s*TS\d)|##\[error\]|^npm ERR!|^Traceback |AssertionError|make: \*\*\*|exit (status|code) [1-9])`)
+
+	ansiEscapeRe  = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	logTimeRe     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z `)
+	actionsJobRe  = regexp.MustCompile(`^/([^/]+)/([^/]+)/actions/runs/(\d+)/job/(\d+)`)
+	actionsRunRe  = regexp.MustCompile(`/actions/runs/(\d+)`)
+	numericPathRe = regexp.MustCompile(`^\d+$`)
+)
+
+// ImportFailure creates a pending fix-ci-{id} task from a failing CI run.
+// source is a log file, "-" for stdin, or a URL; GitHub Actions job URLs
+// are fetched through the API with the stored GitHub token. The task body
+// holds the failing test and build output extracted from the log. An empty
+// id is derived from the source: the run number of a CI URL, or the log's
+// file name.
+func (r *Runner) ImportFailure(ctx context.Context, source, id string) error {
+	log, err := readCILog(ctx, source)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(log) == "" {
+		return fmt.Errorf("CI log %s is empty", source)
+	}
+
+	if id == "" {
+		id = ciFailureID(source, time.Now())
+	}
+	slug := design.Slugify(id)
+	if slug == "" {
+		return fmt.Errorf("invalid task id %q", id)
+	}
+	name := "fix-ci-" + slug
+
+	if _, err := r.Design.CreateTask(name, ciFailureTask(source, extractFailure(log))); err != nil {
+		return err
+	}
+	fmt.Printf("Created task %s. Start it with: hydra run %s\n", name, name)
+	return nil
+}
+
+// ciFailureTask returns the body of a task fixing the failure in output.
+func ciFailureTask(source, output string) string {
+	var b strings.Builder
+	b.WriteString("Fix the CI failure below.\n\n")
+	if source != "-" {
+		b.WriteString("CI log: " + source + "\n\n")
+	}
+	b.WriteString("Find the cause of each failing test or build step and fix the code, " +
+		"not the test, unless the test itself is wrong. Run the failing tests locally " +
+		"to confirm the fix.\n\n")
+	b.WriteString("## Failure output\n\n")
+	b.WriteString(fence("text", output))
+	return b.String()
+}
+
+// extractFailure returns the lines of a CI log around failing tests and
+// build steps, with ANSI escapes and log timestamps removed. Separate
+// regions are joined with "...". When no failure line is recognized, the
+// end of the log is returned instead.
+func extractFailure(log string) string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	for i, line := range lines {
+		line = ansiEscapeRe.ReplaceAllString(line, "")
+		lines[i] = strings.TrimRight(logTimeRe.ReplaceAllString(line, ""), "\r")
+	}
+
+	var regions [][2]int
+	for i, line := range lines {
+		if !failureLineRe.MatchString(line) {
+			continue
+		}
+		start, end := max(i-failureContextBefore, 0), min(i+failureContextAfter+1, len(lines))
+		if n := len(regions); n > 0 && start <= regions[n-1][1] {
+			regions[n-1][1] = max(regions[n-1][1], end)
+			continue
+		}
+		regions = append(regions, [2]int{start, end})
+	}
+	if len(regions) == 0 {
+		return strings.Join(lines[max(len(lines)-failureTailLines, 0):], "\n")
+	}
+
+	var out []string
+	for _, reg := range regions {
+		if len(out) > 0 {
+			out = append(out, "...")
+		}
+		out = append(out, lines[reg[0]:reg[1]]...)
+		if len(out) >= maxFailureLines {
+			out = append(out[:maxFailureLines], "... (truncated)")
+			break
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// ciFailureID derives a task id from a CI log source: the run number of a
+// CI URL, the last numeric path segment of any other URL, or the log's file
+// name. Stdin and sources with nothing usable get a timestamp.
+func ciFailureID(source string, now time.Time) string {
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		if m := actionsRunRe.FindStringSubmatch(u.Path); m != nil {
+			return m[1]
+		}
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			if numericPathRe.MatchString(segments[i]) {
+				return segments[i]
+			}
+		}
+	} else if source != "-" {
+		base := filepath.Base(source)
+		if id := design.Slugify(strings.TrimSuffix(base, filepath.Ext(base))); id != "" {
+			return id
+		}
+	}
+	return now.Format("20060102-150405")
+}
+
+// readCILog reads a CI log from a file, stdin ("-"), or a URL.
+func readCILog(ctx context.Context, source string) (string, error) {
+	var rd io.Reader
+	switch {
+	case source == "-":
+		rd = os.Stdin
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		body, err := fetchCILog(ctx, source)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = body.Close() }()
+		rd = body
+	default:
+		f, err := os.Open(source) //nolint:gosec // path given on the command line
+		if err != nil {
+			return "", fmt.Errorf("opening CI log: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		rd = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rd, maxCILogBytes))
+	if err != nil {
+		return "", fmt.Errorf("reading CI log: %w", err)
+	}
+	return string(data), nil
+}
+
+// fetchCILog downloads a CI log. A GitHub Actions job page URL is turned
+// into the API request for the job's raw log.
+func fetchCILog(ctx context.Context, source string) (io.ReadCloser, error) {
+	logURL, token := source, ""
+	if u, err := url.Parse(source); err == nil && u.Host == "github.com" {
+		if m := actionsJobRe.FindStringSubmatch(u.Path); m != nil {
+			logURL = fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/jobs/%s/logs", m[1], m[2], m[4])
+			token = credstore.Lookup(credstore.ProviderGitHub)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req) //nolint:gosec // URL given on the command line
+	if err != nil {
+		return nil, fmt.Errorf("fetching CI log: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("fetching CI log: %s returned status %d", logURL, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
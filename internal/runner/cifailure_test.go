@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestExtractFailure(t *testing.T) {
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("2026-03-10T12:00:00.1234567Z setup step %d", i))
+	}
+	lines[60] = "2026-03-10T12:00:00.1234567Z \x1b[31m--- FAIL: TestLogin (0.01s)\x1b[0m"
+	lines[61] = "    login_test.go:42: got 401, want 200"
+
+	got := extractFailure(strings.Join(lines, "\n"))
+	if !strings.Contains(got, "--- FAIL: TestLogin (0.01s)\n    login_test.go:42: got 401, want 200") {
+		t.Errorf("failure lines missing or not cleaned:\n%s", got)
+	}
+	if !strings.Contains(got, "setup step 55") || strings.Contains(got, "setup step 54") {
+		t.Errorf("expected %d lines of leading context:\n%s", failureContextBefore, got)
+	}
+	if strings.Contains(got, "setup step 10\n") || strings.Contains(got, "2026-03-10T") {
+		t.Errorf("unrelated lines or timestamps kept:\n%s", got)
+	}
+}
+
+func TestExtractFailureSeparateRegions(t *testing.T) {
+	var lines []string
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[10] = "main.go:3:2: error: undefined: foo"
+	lines[150] = "FAIL\tgithub.com/x/y\t0.2s"
+
+	got := extractFailure(strings.Join(lines, "\n"))
+	if strings.Count(got, "\n...\n") != 1 {
+		t.Errorf("want two regions joined by ...:\n%s", got)
+	}
+	if !strings.Contains(got, "undefined: foo") || !strings.Contains(got, "FAIL\tgithub.com/x/y") {
+		t.Errorf("failure lines missing:\n%s", got)
+	}
+}
+
+func TestExtractFailureFallsBackToTail(t *testing.T) {
+	var lines []string
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+
+	got := extractFailure(strings.Join(lines, "\n"))
+	if n := len(strings.Split(got, "\n")); n != failureTailLines {
+		t.Errorf("got %d lines, want %d", n, failureTailLines)
+	}
+	if !strings.HasSuffix(got, "line 199") {
+		t.Errorf("tail should end with the last line:\n%s", got)
+	}
+}
+
+func TestCIFailureID(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		source, want string
+	}{
+		{"https://github.com/o/r/actions/runs/12345/job/678", "12345"},
+		{"https://ci.example.com/builds/991/log", "991"},
+		{"/tmp/Build Log.txt", "build-log"},
+		{"-", "20260310-123000"},
+		{"https://ci.example.com/log", "20260310-123000"},
+	}
+	for _, tt := range tests {
+		if got := ciFailureID(tt.source, now); got != tt.want {
+			t.Errorf("ciFailureID(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestImportFailure(t *testing.T) {
+	dir := t.TempDir()
+	dd, err := design.NewDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Runner{Design: dd}
+
+	logPath := filepath.Join(dir, "ci.log")
+	if err := os.WriteFile(logPath, []byte("ok\n--- FAIL: TestX\nFAIL\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.ImportFailure(t.Context(), logPath, "42"); err != nil {
+		t.Fatalf("ImportFailure: %v", err)
+	}
+	task, err := dd.FindTask("fix-ci-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := task.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "--- FAIL: TestX") || !strings.Contains(content, "CI log: "+logPath) {
+		t.Errorf("unexpected task content:\n%s", content)
+	}
+
+	if err := r.ImportFailure(t.Context(), logPath, "42"); err == nil {
+		t.Error("importing the same id twice should fail")
+	}
+}
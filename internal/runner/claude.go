@@ -2,25 +2,104 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/erikh/hydra/internal/claude"
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/tracing"
 	"github.com/erikh/hydra/internal/tui"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func invokeClaude(ctx context.Context, cfg ClaudeRunConfig) error {
-	// Try Claude Code CLI first (unless forced to use the built-in TUI).
-	if !cfg.ForceTUI {
+	// Headless sessions have no terminal for the Claude Code CLI or the TUI.
+	if cfg.Headless {
+		return invokeClaudeHeadless(ctx, cfg)
+	}
+
+	// Try Claude Code CLI first (unless forced to use the built-in TUI, or
+	// tools must run through a Shell or approval rules the CLI can't use).
+	if !cfg.ForceTUI && cfg.Shell == nil && len(cfg.Approval) == 0 {
 		if cliPath := claude.FindCLI(); cliPath != "" {
-			return claude.RunCLI(ctx, claude.CLIConfig{
+			hooks, cleanup, err := cliWrapUp(cfg)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			limiter, err := claude.SharedLimiter(claude.LoadRateLimit())
+			if err != nil {
+				return err
+			}
+			if cfg.FinalMessage != nil || cfg.Tokens != nil || limiter != nil {
+				stopHooks, read, cleanupStop, err := cliStopHook()
+				if err != nil {
+					return err
+				}
+				defer cleanupStop()
+				defer func() {
+					session := read()
+					if cfg.FinalMessage != nil {
+						*cfg.FinalMessage = session.FinalMessage
+					}
+					if cfg.Tokens != nil {
+						*cfg.Tokens = session.Tokens
+					}
+					// The tokens the session used are charged once it is
+					// over, as the built-in session does per response.
+					if limiter != nil {
+						if err := limiter.Record(session.Tokens); err != nil {
+							fmt.Fprintf(os.Stderr, "Warning: could not update rate limit state: %v\n", err)
+						}
+					}
+				}()
+				hooks = hooks.merge(stopHooks)
+			}
+			if cfg.TaskName != "" && cfg.HydraDir != "" {
+				transcriptHooks, save, cleanupTranscript, err := cliTranscript(cfg)
+				if err != nil {
+					return err
+				}
+				defer cleanupTranscript()
+				defer save()
+				hooks = hooks.merge(transcriptHooks)
+			}
+			if cfg.Status != nil {
+				progressHooks, progress, cleanupProgress, err := cliProgress()
+				if err != nil {
+					return err
+				}
+				defer cleanupProgress()
+				defer cfg.Status.watch(progress)()
+				hooks = hooks.merge(progressHooks)
+			}
+			cliCfg := claude.CLIConfig{
 				CLIPath:    cliPath,
 				Prompt:     cfg.Document,
 				Model:      modelOrDefault(cfg.Model),
 				WorkDir:    cfg.RepoDir,
 				AutoAccept: cfg.AutoAccept,
 				PlanMode:   cfg.PlanMode,
-			})
+				Settings:   hooks.settings(),
+				Limiter:    limiter,
+			}
+			// The CLI takes one fallback model, so it gets the first.
+			if len(cfg.Fallbacks) > 0 {
+				cliCfg.FallbackModel = cfg.Fallbacks[0]
+			}
+			if cfg.State != nil && len(cfg.State.Server) > 0 {
+				cliCfg.MCPConfig = claude.MCPConfig(cfg.State.Server)
+				cliCfg.AllowedTools = []string{claude.MCPStateTool}
+			}
+			return claude.RunCLI(ctx, cliCfg)
 		}
 	}
 
@@ -28,6 +107,103 @@ func invokeClaude(ctx context.Context, cfg ClaudeRunConfig) error {
 	return invokeClaudeDirect(ctx, cfg)
 }
 
+// cliHookInput returns a hook for event that saves what Claude Code passes
+// it on stdin to a temporary file, and the file's path.
+func cliHookInput(event string) (hooks cliHooks, path string, cleanup func(), err error) {
+	dir, err := os.MkdirTemp("", "hydra-hook-")
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("creating %s hook directory: %w", event, err)
+	}
+	path = filepath.Join(dir, "input.json")
+	hooks = cliHooks{event: {{Hooks: []cliHook{{Type: "command", Command: "cat > " + shellQuote(path)}}}}}
+	return hooks, path, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// cliStopHook returns a Stop hook that saves what Claude Code passes it at
+// the end of each of Claude's turns, and a function that reads the session,
+// with Claude's final message and the tokens it used, through it once the
+// session is over. The function returns an empty session if it can't.
+func cliStopHook() (hooks cliHooks, read func() claude.CLISession, cleanup func(), err error) {
+	hooks, path, cleanup, err := cliHookInput("Stop")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	read = func() claude.CLISession {
+		data, err := os.ReadFile(path) //nolint:gosec // path is in our own temporary directory
+		if err != nil {
+			return claude.CLISession{}
+		}
+		session, err := claude.ReadCLISession(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not read the Claude Code session: %v\n", err)
+			return claude.CLISession{}
+		}
+		return *session
+	}
+	return hooks, read, cleanup, nil
+}
+
+// cliProgress returns a SessionStart hook that saves where Claude Code
+// keeps the session's transcript, and a function that reports the tokens
+// the session has used and its latest tool call from the transcript so
+// far, for the live status file. The function is not safe for concurrent
+// use.
+func cliProgress() (hooks cliHooks, progress func() (tokens int64, tool string), cleanup func(), err error) {
+	hooks, path, cleanup, err := cliHookInput("SessionStart")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var transcript *claude.CLITranscript
+	progress = func() (int64, string) {
+		if transcript == nil {
+			// Until the hook has run, and while it writes, there is no
+			// transcript to read yet.
+			data, err := os.ReadFile(path) //nolint:gosec // path is in our own temporary directory
+			if err != nil {
+				return 0, ""
+			}
+			if transcript, err = claude.NewCLITranscript(data); err != nil {
+				return 0, ""
+			}
+		}
+		session, _ := transcript.Read()
+		return session.Tokens, session.LastTool
+	}
+	return hooks, progress, cleanup, nil
+}
+
+// cliTranscript returns a SessionStart hook that saves where Claude Code
+// keeps the session's transcript, and a function that copies the
+// transcript to the task's transcripts directory once the session is over,
+// as saveTranscript does for sessions in the TUI.
+func cliTranscript(cfg ClaudeRunConfig) (hooks cliHooks, save func(), cleanup func(), err error) {
+	hooks, path, cleanup, err := cliHookInput("SessionStart")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	save = func() {
+		data, err := os.ReadFile(path) //nolint:gosec // path is in our own temporary directory
+		if err != nil {
+			// The session never started.
+			return
+		}
+		transcript, err := claude.NewCLITranscript(data)
+		if err == nil {
+			data, err = os.ReadFile(transcript.Path())
+		}
+		if err == nil {
+			dest := transcriptPath(cfg, time.Now())
+			if err = os.MkdirAll(filepath.Dir(dest), 0o750); err == nil {
+				err = os.WriteFile(dest, data, 0o600)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save session transcript: %v\n", err)
+		}
+	}
+	return hooks, save, cleanup, nil
+}
+
 func modelOrDefault(model string) string {
 	if model == "" {
 		return claude.DefaultModel
@@ -35,38 +211,227 @@ func modelOrDefault(model string) string {
 	return model
 }
 
-func invokeClaudeDirect(ctx context.Context, cfg ClaudeRunConfig) error {
+// startSession starts a direct API session with cfg's document. The
+// returned function must be called when the session is over: it stops the
+// wrap-up reminder and status updates, and fills in cfg's Tokens, UsedModel,
+// and FinalMessage.
+func startSession(ctx context.Context, cfg ClaudeRunConfig) (session *claude.Session, done func(), err error) {
 	creds, err := claude.LoadCredentials()
 	if err != nil {
-		return fmt.Errorf("loading credentials: %w", err)
+		return nil, nil, fmt.Errorf("loading credentials: %w", err)
 	}
 
-	model := modelOrDefault(cfg.Model)
-
-	client, err := claude.NewClient(creds, claude.ClientConfig{
-		Model:   model,
-		RepoDir: cfg.RepoDir,
-	})
+	clientCfg := claude.ClientConfig{
+		Model:     modelOrDefault(cfg.Model),
+		Fallbacks: cfg.Fallbacks,
+		RepoDir:   cfg.RepoDir,
+		RateLimit: claude.LoadRateLimit(),
+		Shell:     cfg.Shell,
+	}
+	if cfg.State != nil {
+		clientCfg.State = cfg.State.Answer
+	}
+	client, err := claude.NewClient(creds, clientCfg)
 	if err != nil {
-		return fmt.Errorf("creating API client: %w", err)
+		return nil, nil, fmt.Errorf("creating API client: %w", err)
 	}
 
-	session := claude.NewSession(client)
+	session = claude.NewSession(client)
 	session.Start(ctx, cfg.Document)
+	stopWrapUp := scheduleWrapUp(cfg, session.Inject)
+	stopStatus := cfg.Status.watch(func() (int64, string) { return session.Tokens(), session.LastTool() })
+	done = func() {
+		stopWrapUp()
+		stopStatus()
+		if cfg.Tokens != nil {
+			*cfg.Tokens = session.Tokens()
+		}
+		if cfg.UsedModel != nil {
+			*cfg.UsedModel = session.Model()
+		}
+		if cfg.FinalMessage != nil {
+			*cfg.FinalMessage = session.FinalText()
+		}
+	}
+	return session, done, nil
+}
 
-	m := tui.New(session, model, cfg.AutoAccept)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+func invokeClaudeDirect(ctx context.Context, cfg ClaudeRunConfig) error {
+	session, done, err := startSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	m := tui.New(session, modelOrDefault(cfg.Model), cfg.AutoAccept)
+	m.SetApprovalRules(cfg.Approval)
+	transcript := m.Transcript()
+	stopMirror := serveMirror(cfg, transcript)
+	defer stopMirror()
+
+	// Save the transcript if the TUI panics or hydra is terminated mid-session.
+	// Bubble Tea turns SIGTERM into a normal quit, so watch for it here too.
+	term := make(chan os.Signal, 1)
+	signal.Notify(term, syscall.SIGTERM)
+	defer signal.Stop(term)
+	defer func() {
+		if r := recover(); r != nil {
+			saveCrashLog(cfg, transcript, fmt.Sprintf("panic: %v\n\n%s", r, debug.Stack()))
+			panic(r)
+		}
+	}()
+
+	// The context ends the TUI at the session's time limit.
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx))
+	if cfg.Output != nil {
+		defer cfg.Output.attach(tui.NewCommandSink(p))()
+	}
 
 	finalModel, err := p.Run()
+	select {
+	case <-term:
+		session.Cancel()
+		saveCrashLog(cfg, transcript, "received SIGTERM")
+		return errors.New("session terminated by SIGTERM")
+	default:
+	}
+	if ctx.Err() != nil {
+		session.Cancel()
+		saveTranscript(cfg, transcript)
+		return ctx.Err()
+	}
 	if err != nil {
-		return fmt.Errorf("TUI error: %w", err)
+		if errors.Is(err, tea.ErrProgramPanic) {
+			saveCrashLog(cfg, transcript, "TUI panic (stack trace printed to the terminal)")
+		}
+		return inCategory(categoryClaude, fmt.Errorf("TUI error: %w", err))
 	}
 
+	saveTranscript(cfg, transcript)
+
 	if fm, ok := finalModel.(tui.Model); ok {
 		if tuiErr := fm.Err(); tuiErr != nil {
-			return fmt.Errorf("session error: %w", tuiErr)
+			return inCategory(categoryClaude, fmt.Errorf("session error: %w", tuiErr))
 		}
 	}
 
 	return nil
 }
+
+// invokeClaudeHeadless runs a direct API session without a terminal,
+// approving tool calls the approval rules allow and streaming the session's output to stdout,
+// for runs in the background (hydra run --detach).
+func invokeClaudeHeadless(ctx context.Context, cfg ClaudeRunConfig) error {
+	session, done, err := startSession(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	// A detached run has no terminal to quit from; SIGTERM ends the session.
+	sessionCtx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	transcript := tui.NewTranscript()
+	stopMirror := serveMirror(cfg, transcript)
+	defer stopMirror()
+	err = tui.RunHeadless(sessionCtx, session, cfg.Approval, os.Stdout, transcript)
+	session.Cancel()
+	switch {
+	case ctx.Err() != nil:
+		saveTranscript(cfg, transcript)
+		return ctx.Err()
+	case sessionCtx.Err() != nil:
+		saveCrashLog(cfg, transcript, "received SIGTERM")
+		return errors.New("session terminated by SIGTERM")
+	case err != nil:
+		saveTranscript(cfg, transcript)
+		return inCategory(categoryClaude, fmt.Errorf("session error: %w", err))
+	}
+	saveTranscript(cfg, transcript)
+	return nil
+}
+
+// crashLogPath returns where the crash transcript for a session is saved:
+// .hydra/crash/<task>-<timestamp>.log.
+func crashLogPath(cfg ClaudeRunConfig, now time.Time) string {
+	hydraDir := cfg.HydraDir
+	if hydraDir == "" {
+		hydraDir = config.HydraPath(".")
+	}
+	name := cfg.TaskName
+	if name == "" {
+		name = "session"
+	}
+	name = strings.NewReplacer("/", "--", ":", "-").Replace(name)
+	return filepath.Join(hydraDir, "crash", name+"-"+now.Format("20060102-150405")+".log")
+}
+
+// saveCrashLog writes the session transcript to the crash directory and
+// reports where it went.
+func saveCrashLog(cfg ClaudeRunConfig, transcript *tui.Transcript, reason string) {
+	path := crashLogPath(cfg, time.Now())
+	if err := transcript.Save(path, reason); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save crash log: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Session transcript saved to %s\n", path)
+}
+
+// transcriptDir returns the directory holding the saved session transcripts
+// of a task, given as name or group/name.
+func transcriptDir(hydraDir, taskName string) string {
+	return filepath.Join(hydraDir, "transcripts", strings.ReplaceAll(taskName, "/", "--"))
+}
+
+// transcriptPath returns where the transcript of a finished task session is
+// saved: <transcriptDir>/<action>-<timestamp>.log, where action is the lock
+// prefix (review, test, merge, plan) or run.
+func transcriptPath(cfg ClaudeRunConfig, now time.Time) string {
+	action, task := "run", cfg.TaskName
+	if a, t, ok := strings.Cut(cfg.TaskName, ":"); ok {
+		action, task = a, t
+	}
+	return filepath.Join(transcriptDir(cfg.HydraDir, task), action+"-"+now.Format("20060102-150405")+".log")
+}
+
+// saveTranscript keeps the transcript of a task session that ended normally,
+// so it can be included in a review handoff. Sessions not tied to a task are
+// not kept.
+func saveTranscript(cfg ClaudeRunConfig, transcript *tui.Transcript) {
+	if cfg.TaskName == "" || cfg.HydraDir == "" {
+		return
+	}
+	if err := transcript.Save(transcriptPath(cfg, time.Now()), "session finished"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save session transcript: %v\n", err)
+	}
+}
+
+// runClaude invokes fn inside a span covering the Claude session. If
+// cfg.Timeout is set, the session is ended at that limit and errSessionTimeout
+// is returned. The session is reported in .hydra/status.json while it runs.
+func runClaude(ctx context.Context, fn ClaudeFunc, cfg ClaudeRunConfig) (err error) {
+	ctx, span := tracing.Start(ctx, "claude session",
+		attribute.String("hydra.model", modelOrDefault(cfg.Model)),
+		attribute.Bool("hydra.plan_mode", cfg.PlanMode),
+		attribute.Bool("hydra.auto_accept", cfg.AutoAccept),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if cfg.Status == nil && cfg.TaskName != "" && cfg.HydraDir != "" {
+		cfg.Status = startStatus(cfg.HydraDir, cfg.TaskName)
+		defer cfg.Status.Done()
+	}
+	cfg.Status.Phase(phaseClaude)
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+		cfg.Status.ExpectBy(time.Now().Add(cfg.Timeout))
+	}
+	err = fn(ctx, cfg)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errSessionTimeout, cfg.Timeout)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/claude"
+)
+
+func TestCrashLogPath(t *testing.T) {
+	now := time.Date(2026, 3, 1, 14, 5, 9, 0, time.UTC)
+
+	got := crashLogPath(ClaudeRunConfig{TaskName: "review:backend/add-api", HydraDir: "/p/.hydra"}, now)
+	want := filepath.Join("/p/.hydra", "crash", "review-backend--add-api-20260301-140509.log")
+	if got != want {
+		t.Errorf("crashLogPath = %q, want %q", got, want)
+	}
+
+	got = crashLogPath(ClaudeRunConfig{}, now)
+	want = filepath.Join(".hydra", "crash", "session-20260301-140509.log")
+	if got != want {
+		t.Errorf("crashLogPath with no task = %q, want %q", got, want)
+	}
+}
+
+func TestTranscriptPath(t *testing.T) {
+	now := time.Date(2026, 3, 1, 14, 5, 9, 0, time.UTC)
+
+	got := transcriptPath(ClaudeRunConfig{TaskName: "review:backend/add-api", HydraDir: "/p/.hydra"}, now)
+	want := filepath.Join("/p/.hydra", "transcripts", "backend--add-api", "review-20260301-140509.log")
+	if got != want {
+		t.Errorf("transcriptPath = %q, want %q", got, want)
+	}
+
+	got = transcriptPath(ClaudeRunConfig{TaskName: "add-feature", HydraDir: "/p/.hydra"}, now)
+	want = filepath.Join("/p/.hydra", "transcripts", "add-feature", "run-20260301-140509.log")
+	if got != want {
+		t.Errorf("transcriptPath for run = %q, want %q", got, want)
+	}
+}
+
+func TestCLIStopHook(t *testing.T) {
+	hooks, read, cleanup, err := cliStopHook()
+	if err != nil {
+		t.Fatalf("cliStopHook: %v", err)
+	}
+	defer cleanup()
+
+	if got := read(); got != (claude.CLISession{}) {
+		t.Errorf("session before the hook ran = %+v, want empty", got)
+	}
+
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, transcript, `{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Finished."}],"usage":{"input_tokens":40,"output_tokens":2}}}`+"\n")
+	stop := hooks["Stop"]
+	if len(stop) != 1 || len(stop[0].Hooks) != 1 {
+		t.Fatalf("unexpected hooks: %+v", hooks)
+	}
+	cmd := exec.CommandContext(t.Context(), "sh", "-c", stop[0].Hooks[0].Command)
+	cmd.Stdin = strings.NewReader(`{"hook_event_name":"Stop","transcript_path":"` + transcript + `"}`)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running hook: %v", err)
+	}
+	if got := read(); got.FinalMessage != "Finished." || got.Tokens != 42 {
+		t.Errorf("session = %+v, want the final message and 42 tokens", got)
+	}
+}
+
+func TestCLIProgress(t *testing.T) {
+	hooks, progress, cleanup, err := cliProgress()
+	if err != nil {
+		t.Fatalf("cliProgress: %v", err)
+	}
+	defer cleanup()
+
+	if tokens, tool := progress(); tokens != 0 || tool != "" {
+		t.Errorf("progress before the hook ran = %d, %q; want nothing", tokens, tool)
+	}
+
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, transcript, "")
+	start := hooks["SessionStart"]
+	if len(start) != 1 || len(start[0].Hooks) != 1 {
+		t.Fatalf("unexpected hooks: %+v", hooks)
+	}
+	cmd := exec.CommandContext(t.Context(), "sh", "-c", start[0].Hooks[0].Command)
+	cmd.Stdin = strings.NewReader(`{"hook_event_name":"SessionStart","transcript_path":"` + transcript + `"}`)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running hook: %v", err)
+	}
+
+	writeFile(t, transcript, `{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":90,"output_tokens":10}}}`+"\n")
+	if tokens, tool := progress(); tokens != 100 || tool != "Bash: go test ./..." {
+		t.Errorf("progress = %d, %q; want 100 tokens and the Bash call", tokens, tool)
+	}
+}
+
+func TestCLITranscript(t *testing.T) {
+	hydraDir := t.TempDir()
+	cfg := ClaudeRunConfig{TaskName: "review:backend/add-api", HydraDir: hydraDir}
+	hooks, save, cleanup, err := cliTranscript(cfg)
+	if err != nil {
+		t.Fatalf("cliTranscript: %v", err)
+	}
+	defer cleanup()
+
+	save()
+	if saved, _ := taskTranscripts(hydraDir, "backend/add-api"); len(saved) != 0 {
+		t.Errorf("transcripts saved before the hook ran: %+v", saved)
+	}
+
+	const line = `{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Done."}]}}` + "\n"
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, transcript, line)
+	start := hooks["SessionStart"]
+	if len(start) != 1 || len(start[0].Hooks) != 1 {
+		t.Fatalf("unexpected hooks: %+v", hooks)
+	}
+	cmd := exec.CommandContext(t.Context(), "sh", "-c", start[0].Hooks[0].Command)
+	cmd.Stdin = strings.NewReader(`{"hook_event_name":"SessionStart","transcript_path":"` + transcript + `"}`)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running hook: %v", err)
+	}
+
+	save()
+	saved, err := taskTranscripts(hydraDir, "backend/add-api")
+	if err != nil {
+		t.Fatalf("taskTranscripts: %v", err)
+	}
+	if len(saved) != 1 || !strings.HasPrefix(saved[0].name, "review-") || saved[0].content != line {
+		t.Errorf("saved transcripts = %+v, want the session's transcript as a review transcript", saved)
+	}
+}
@@ -3,6 +3,11 @@ package runner
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
 )
 
 // Clean runs the clean command in the task's work directory.
@@ -13,16 +18,127 @@ func (r *Runner) Clean(taskName string) error {
 	if err != nil {
 		return err
 	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
 
 	wd := r.workDir(task)
 
 	if r.TaskRunner == nil || !r.TaskRunner.HasCommand("clean", wd) {
 		return errors.New("no clean command configured in hydra.yml and no clean target in Makefile")
 	}
+	if taskBusy(r.hydraDir(), taskLabel(*task)) {
+		return fmt.Errorf("task %q is in use by another hydra command; wait for it to finish", taskLabel(*task))
+	}
 
-	if err := r.TaskRunner.Run("clean", wd); err != nil {
+	if err := r.commands().Run("clean", wd); err != nil {
 		return fmt.Errorf("clean failed: %w", err)
 	}
 
 	return nil
 }
+
+// CleanAll runs the clean command in every work directory under
+// .hydra/work, whether or not it still belongs to a task. Directories with
+// no clean command or Makefile target, and those of tasks another hydra
+// command is using, are skipped. A failure in one directory does not stop
+// the others; all failures are reported together.
+func (r *Runner) CleanAll() error {
+	if r.TaskRunner == nil {
+		return errors.New("no clean command configured in hydra.yml and no clean target in Makefile")
+	}
+
+	dirs, err := r.workDirs()
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		fmt.Println("No work directories.")
+		return nil
+	}
+
+	var errs []error
+	cleaned := 0
+	for _, wd := range dirs {
+		if !r.TaskRunner.HasCommand("clean", wd) {
+			fmt.Printf("Skipping %s: no clean command\n", wd)
+			continue
+		}
+		if r.workDirBusy(wd) {
+			fmt.Printf("Skipping %s: in use\n", wd)
+			continue
+		}
+		fmt.Printf("Cleaning %s\n", wd)
+		if err := r.commands().Run("clean", wd); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", wd, err))
+			continue
+		}
+		cleaned++
+	}
+
+	fmt.Printf("Cleaned %d of %d work directories\n", cleaned, len(dirs))
+	if len(errs) > 0 {
+		return fmt.Errorf("clean failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// workDirs returns every git work directory under the work root: both
+// ungrouped work/{name} and grouped work/{group}/{name} directories.
+func (r *Runner) workDirs() ([]string, error) {
+	root := r.workRoot()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading work directory: %w", err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		p := filepath.Join(root, entry.Name())
+		if repo.IsGitRepo(p) {
+			dirs = append(dirs, p)
+			continue
+		}
+		children, err := os.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading work directory: %w", err)
+		}
+		for _, child := range children {
+			if cp := filepath.Join(p, child.Name()); child.IsDir() && repo.IsGitRepo(cp) {
+				dirs = append(dirs, cp)
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// cleanAfterMerge reclaims disk space once a task is completed, as configured
+// by clean_after_merge and remove_after_merge in hydra.yml. Failures are
+// reported as warnings since the merge itself has already succeeded.
+func (r *Runner) cleanAfterMerge(task *design.Task, wd string) {
+	if r.TaskRunner == nil {
+		return
+	}
+
+	if r.TaskRunner.CleanAfterMerge && r.TaskRunner.HasCommand("clean", wd) {
+		if err := r.commands().Run("clean", wd); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: post-merge clean failed: %v\n", err)
+		}
+	}
+
+	if !r.TaskRunner.RemoveAfterMerge {
+		return
+	}
+	if err := r.removeWorkDir(wd); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not remove work directory for %s: %v\n", task.Name, err)
+		return
+	}
+	fmt.Printf("Removed work directory %s\n", wd)
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// commandLog is the sink for the hydra.yml commands a Runner runs. While a
+// built-in TUI session has the terminal, commands go to it. Otherwise they
+// write to stdout and stderr as usual and are remembered, and the next
+// session starts by showing them, so the checks and hooks that ran before
+// it are in its transcript.
+type commandLog struct {
+	mu      sync.Mutex
+	live    taskrun.Sink
+	pending []*loggedCommand
+}
+
+// loggedCommand is a command run while no session had the terminal.
+type loggedCommand struct {
+	name, cmd string
+
+	mu     sync.Mutex
+	output bytes.Buffer
+	done   bool
+	err    error
+}
+
+// Write implements io.Writer.
+func (c *loggedCommand) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.output.Write(b)
+}
+
+// Begin implements taskrun.Sink.
+func (l *commandLog) Begin(name, cmdStr string, stdout, stderr io.Writer) (io.Writer, io.Writer, func(error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.live != nil {
+		return l.live.Begin(name, cmdStr, stdout, stderr)
+	}
+
+	c := &loggedCommand{name: name, cmd: cmdStr}
+	l.pending = append(l.pending, c)
+	end := func(err error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.done, c.err = true, err
+	}
+	if stdout == stderr {
+		w := io.MultiWriter(stdout, c)
+		return w, w, end
+	}
+	return io.MultiWriter(stdout, c), io.MultiWriter(stderr, c), end
+}
+
+// attach sends commands to sink until the returned function is called,
+// first showing it the commands that finished since the last session.
+func (l *commandLog) attach(sink taskrun.Sink) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.pending {
+		c.mu.Lock()
+		if c.done {
+			w, _, end := sink.Begin(c.name, c.cmd, io.Discard, io.Discard)
+			_, _ = w.Write(c.output.Bytes())
+			end(c.err)
+		}
+		c.mu.Unlock()
+	}
+	l.pending = nil
+	l.live = sink
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.live = nil
+	}
+}
+
+// attached reports whether a session has the terminal.
+func (l *commandLog) attached() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.live != nil
+}
+
+// commands returns hydra.yml's commands with their output going through
+// the runner's command log, or nil if there is no hydra.yml.
+func (r *Runner) commands() *taskrun.Commands {
+	return r.TaskRunner.WithSink(&r.output)
+}
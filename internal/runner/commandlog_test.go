@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// blockSink is a taskrun.Sink that keeps the commands it is given.
+type blockSink struct {
+	blocks []string
+}
+
+func (s *blockSink) Begin(name, _ string, _, _ io.Writer) (io.Writer, io.Writer, func(error)) {
+	var b strings.Builder
+	return &b, &b, func(err error) {
+		s.blocks = append(s.blocks, name+": "+b.String()+" ("+errString(err)+")")
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
+
+func TestCommandLog(t *testing.T) {
+	var log commandLog
+	var before strings.Builder
+	w, _, end := log.Begin("test", "make test", &before, &before)
+	_, _ = w.Write([]byte("FAIL"))
+	end(errors.New("exit status 1"))
+	if before.String() != "FAIL" {
+		t.Errorf("output before the session = %q, want it passed through", before.String())
+	}
+
+	sink := &blockSink{}
+	detach := log.attach(sink)
+	if !log.attached() {
+		t.Error("attached() = false during the session")
+	}
+	var during strings.Builder
+	w, _, end = log.Begin("notify", "notify-send", &during, &during)
+	_, _ = w.Write([]byte("sent"))
+	end(nil)
+	detach()
+
+	want := []string{"test: FAIL (exit status 1)", "notify: sent (ok)"}
+	if strings.Join(sink.blocks, "\n") != strings.Join(want, "\n") {
+		t.Errorf("session blocks = %q, want %q", sink.blocks, want)
+	}
+	if during.String() != "" {
+		t.Errorf("output during the session went to %q, want only the session", during.String())
+	}
+	if log.attached() {
+		t.Error("attached() = true after the session")
+	}
+}
@@ -4,42 +4,90 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/erikh/hydra/internal/repo"
 )
 
 // planModeInstruction is appended to every workflow document so Claude starts in plan mode.
 const planModeInstruction = "\nPlease enter plan mode immediately.\n"
 
 // conflictResolutionSection returns a markdown section instructing Claude to
-// resolve rebase conflicts. Returns empty string if there are no conflicts.
-func conflictResolutionSection(conflictFiles []string) string {
-	if len(conflictFiles) == 0 {
+// resolve rebase conflicts, with each conflict's hunks and the upstream
+// commits behind them. base is the group's base branch, or empty for main.
+// Returns empty string if there are no conflicts.
+func conflictResolutionSection(conflicts []repo.Conflict, base string) string {
+	if len(conflicts) == 0 {
 		return ""
 	}
+	upstream := upstreamRef(base)
 
 	var b strings.Builder
 	b.WriteString("\n## Conflict Resolution\n\n")
-	b.WriteString("A rebase of this branch onto origin/main was attempted but resulted in conflicts. " +
+	b.WriteString("A rebase of this branch onto " + upstream + " was attempted but resulted in conflicts. " +
 		"The rebase has been aborted. You must:\n\n")
-	b.WriteString("1. Run `git rebase origin/main`\n")
+	b.WriteString("1. Run `git rebase " + upstream + "`\n")
 	b.WriteString("2. Resolve the conflicts in the files listed below\n")
 	b.WriteString("3. Stage resolved files with `git add`\n")
 	b.WriteString("4. Run `git rebase --continue`\n")
 	b.WriteString("5. Repeat until the rebase is complete\n\n")
 
 	b.WriteString("### Conflicted Files\n\n")
-	for _, f := range conflictFiles {
+	for _, c := range conflicts {
 		b.WriteString("- ")
-		b.WriteString(f)
+		b.WriteString(c.File)
 		b.WriteString("\n")
 	}
 	b.WriteString("\n")
+
+	for _, c := range conflicts {
+		b.WriteString(conflictDetails(c, upstream))
+	}
+	return b.String()
+}
+
+// conflictDetails describes one conflicted file as found in the first
+// conflicting commit of the aborted rebase onto upstream: both sides of each
+// hunk, and the upstream commits that changed those lines. Returns empty
+// string if there is nothing to add to the file name.
+func conflictDetails(c repo.Conflict, upstream string) string {
+	if len(c.Hunks) == 0 && len(c.Commits) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Conflicts in %s\n\n", c.File)
+	for i, h := range c.Hunks {
+		fmt.Fprintf(&b, "**Hunk %d**", i+1)
+		if h.Truncated {
+			b.WriteString(" (truncated)")
+		}
+		b.WriteString("\n\n" + upstream + ":\n\n")
+		b.WriteString(fence("", h.Upstream))
+		b.WriteString("\nTask branch:\n\n")
+		b.WriteString(fence("", h.Task))
+		b.WriteString("\n")
+	}
+	if c.MoreHunks > 0 {
+		fmt.Fprintf(&b, "%d more conflicting hunk(s) not shown.\n\n", c.MoreHunks)
+	}
+	if len(c.Commits) > 0 {
+		b.WriteString(upstream + " commits that changed these lines since the branch diverged:\n\n")
+		for _, commit := range c.Commits {
+			b.WriteString("- ")
+			b.WriteString(commit)
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
 	return b.String()
 }
 
 // verificationSection returns a markdown section listing the test and lint
-// commands Claude should run before committing. Returns empty string if
-// no commands are configured.
-func verificationSection(commands map[string]string) string {
+// commands Claude should run before committing. When both are configured,
+// Claude is told to run them concurrently, or, with serial set
+// (serial_checks in hydra.yml), one at a time. Returns empty string if no
+// commands are configured.
+func verificationSection(commands map[string]string, serial bool) string {
 	testCmd := commands["test"]
 	lintCmd := commands["lint"]
 
@@ -64,6 +112,15 @@ func verificationSection(commands map[string]string) string {
 		b.WriteString(lintCmd)
 		b.WriteString("`\n")
 	}
+	if testCmd != "" && lintCmd != "" {
+		if serial {
+			b.WriteString("\nRun the tests and the linter one at a time, never concurrently: " +
+				"this project's suites interfere with each other.\n")
+		} else {
+			b.WriteString("\nThe tests and the linter are independent. Save time by running them " +
+				"concurrently, e.g. as two background commands, and wait for both before going on.\n")
+		}
+	}
 
 	b.WriteString("\nIMPORTANT: Multiple hydra tasks may run concurrently, each in its own " +
 		"work directory. Do not modify these commands to use fixed ports, shared temp files, " +
@@ -75,13 +132,32 @@ func verificationSection(commands map[string]string) string {
 // commitInstructions returns a markdown section instructing Claude to
 // run tests/lint, stage changes, and commit with a descriptive message.
 func commitInstructions(sign bool, commands map[string]string) string {
+	return scopedCommitInstructions(sign, commands, "", "", "")
+}
+
+// scopedCommitInstructions is commitInstructions for a focused test session.
+// When focusPattern is non-empty, the ban on running individual tests is
+// relaxed to allow tests matching that pattern (via focusCmd, if known) in
+// addition to the full suite. A non-empty template is the rendered
+// commit_template.md that commit messages must follow.
+func scopedCommitInstructions(sign bool, commands map[string]string, focusPattern, focusCmd, template string) string {
 	var b strings.Builder
 	b.WriteString("\n\n# Commit Instructions\n\n")
 
-	b.WriteString("IMPORTANT: Do NOT run any individual test files, test functions, " +
-		"lint checks, or any other testing/linting tools manually. " +
-		"The ONLY test and lint commands you may run are the exact commands listed below " +
-		"from hydra.yml. Do not invoke test runners, linters, or type checkers in any other way.\n\n")
+	if focusPattern == "" {
+		b.WriteString("IMPORTANT: Do NOT run any individual test files, test functions, " +
+			"lint checks, or any other testing/linting tools manually. " +
+			"The ONLY test and lint commands you may run are the exact commands listed below " +
+			"from hydra.yml. Do not invoke test runners, linters, or type checkers in any other way.\n\n")
+	} else {
+		b.WriteString("IMPORTANT: This is a focused test session. While iterating, you may run only the tests matching `" +
+			focusPattern + "`")
+		if focusCmd != "" {
+			b.WriteString(" with `" + focusCmd + "`")
+		}
+		b.WriteString(" as often as needed. Apart from that, the ONLY test and lint commands you may run " +
+			"are the exact commands listed below from hydra.yml, and they must pass before you commit.\n\n")
+	}
 
 	b.WriteString("After making all code changes, follow the steps below.\n\n")
 
@@ -106,6 +182,11 @@ func commitInstructions(sign bool, commands map[string]string) string {
 	step++
 	b.WriteString(stepPrefix(step))
 	b.WriteString("Commit with a descriptive message. ")
+	if template != "" {
+		b.WriteString("The message must follow the project's commit template. " +
+			"Replace each `<...>` placeholder with your own text and keep everything else as written:\n\n" +
+			"```\n" + template + "\n```\n\n")
+	}
 
 	if sign {
 		b.WriteString("Sign the commit: `git commit -S -m \"<descriptive message>\"`\n")
@@ -155,52 +236,67 @@ func timeoutSection(timeout time.Duration) string {
 	return fmt.Sprintf("\n\n# Time Limit\n\n"+
 		"You have %s to complete this task. "+
 		"If you are running low on time, commit whatever progress you have made so far "+
-		"and stop. A partial commit that builds and passes tests is better than no commit at all.\n", timeout)
+		"and stop. A partial commit that builds and passes tests is better than no commit at all. "+
+		"Hydra will tell you when time is nearly up, and ends the session at the limit; "+
+		"anything not committed by then is lost.\n", timeout)
 }
 
 // suffixOpts holds parameters for the common trailing document sections.
 type suffixOpts struct {
-	Commands    map[string]string
-	Sign        bool
-	Timeout     time.Duration
-	Notify      bool
-	NotifyTitle string
-	Reminder    string // custom reminder text; empty uses default missionReminder()
-	SkipSync    bool   // skip the rebase-and-push section (e.g. merge workflow handles git ops itself)
+	Commands     map[string]string
+	SerialChecks bool // run test and lint one at a time (serial_checks in hydra.yml)
+	Sign         bool
+	Timeout      time.Duration
+	Notify       bool
+	NotifyTitle  string
+	Reminder     string // custom reminder text; empty uses default missionReminder()
+	SkipSync     bool   // skip the rebase-and-push section (e.g. merge workflow handles git ops itself)
+	FocusTest    string // test pattern for a focused test session (hydra test --only)
+	FocusCmd     string // scoped test command for FocusTest, if one could be built
+	CommitTmpl   string // rendered commit_template.md, if the design dir has one
+	SkipPlanMode bool   // omit the plan mode request (e.g. executing an already-approved plan)
+	Base         string // branch to sync with: the group base or default branch; main if empty
+
+	Locale *locale // language of the boilerplate sections; nil for English
 }
 
 // documentSuffix returns the common trailing sections appended to every
 // workflow document: verification, commit, rebase-and-push, timeout,
 // notification, and mission reminder.
 func documentSuffix(opts suffixOpts) string {
+	data := suffixSectionData(opts)
 	var b strings.Builder
-	b.WriteString(verificationSection(opts.Commands))
-	b.WriteString(commitInstructions(opts.Sign, opts.Commands))
+	b.WriteString(opts.Locale.section(sectionVerification, data, verificationSection(opts.Commands, opts.SerialChecks)))
+	b.WriteString(opts.Locale.section(sectionCommit, data, scopedCommitInstructions(opts.Sign, opts.Commands, opts.FocusTest, opts.FocusCmd, opts.CommitTmpl)))
 	if !opts.SkipSync {
-		b.WriteString(rebaseAndPushSection(opts.Commands))
+		b.WriteString(opts.Locale.section(sectionSync, data, rebaseAndPushSection(opts.Commands, opts.Base)))
 	}
-	b.WriteString(timeoutSection(opts.Timeout))
+	b.WriteString(opts.Locale.section(sectionTimeout, data, timeoutSection(opts.Timeout)))
 	if opts.Notify {
-		b.WriteString(notificationSection(opts.NotifyTitle))
+		b.WriteString(opts.Locale.section(sectionNotification, data, notificationSection(opts.NotifyTitle)))
 	}
 	if opts.Reminder != "" {
 		b.WriteString(opts.Reminder)
 	} else {
-		b.WriteString(missionReminder())
+		b.WriteString(opts.Locale.section(sectionReminder, data, missionReminder()))
+	}
+	if !opts.SkipPlanMode {
+		b.WriteString(opts.Locale.section(sectionPlanMode, data, planModeInstruction))
 	}
-	b.WriteString(planModeInstruction)
 	return b.String()
 }
 
 // rebaseAndPushSection returns a markdown section instructing Claude to
-// fetch, rebase, test, and loop until stable before pushing.
-func rebaseAndPushSection(commands map[string]string) string {
+// fetch, rebase onto the base branch (main if empty), test, and loop until
+// stable before pushing.
+func rebaseAndPushSection(commands map[string]string, base string) string {
+	upstream := upstreamRef(base)
 	var b strings.Builder
 	b.WriteString("\n\n# Final Sync\n\n")
 	b.WriteString("After committing your changes, you must sync with origin before pushing. ")
 	b.WriteString("Repeat the following steps until no new changes arrive from origin and all tests pass:\n\n")
 	b.WriteString("1. Fetch origin: `git fetch origin`\n")
-	b.WriteString("2. Rebase against origin/main: `git rebase origin/main`\n")
+	b.WriteString("2. Rebase against " + upstream + ": `git rebase " + upstream + "`\n")
 	b.WriteString("3. If the rebase produces conflicts, resolve them\n")
 
 	if testCmd, ok := commands["test"]; ok && testCmd != "" {
@@ -211,11 +307,22 @@ func rebaseAndPushSection(commands map[string]string) string {
 
 	b.WriteString("5. Fix any failures and commit the fixes\n")
 	b.WriteString("6. Go back to step 1 and repeat until `git fetch` brings nothing new and all tests pass\n\n")
+	b.WriteString("If `git fetch` reports a forced update of " + upstream + ", do not rebase: a plain rebase would bring back the commits the force-push removed. " +
+		"Stop, do not push, and say so in your final message, so the branch can be rebased by hand.\n\n")
 	b.WriteString("Once stable, push the feature branch. Force push if needed.\n\n")
 	b.WriteString("Whenever the term \"rebase loop\" is used elsewhere in this document, it refers to this procedure.\n")
 	return b.String()
 }
 
+// upstreamRef returns the origin branch task branches are rebased onto in
+// Claude's instructions: base, as given by targetBranch, or main if empty.
+func upstreamRef(base string) string {
+	if base == "" {
+		return "origin/main"
+	}
+	return "origin/" + base
+}
+
 // stepPrefix returns a numbered step prefix like "1. ", "2. ", etc.
 func stepPrefix(n int) string {
 	return fmt.Sprintf("%d. ", n)
@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// commitTemplateCheck is a task's rendered commit template and the commits
+// on its branch whose subjects don't follow it.
+type commitTemplateCheck struct {
+	Template   string
+	Mismatched []string
+}
+
+// commitTemplate returns commit_template.md rendered for a task, or "" if
+// the design directory has no commit template.
+func (r *Runner) commitTemplate(task *design.Task) (string, error) {
+	tmpl, err := r.Design.CommitTemplate()
+	if err != nil || tmpl == "" {
+		return "", err
+	}
+
+	vars := design.CommitTemplateVars{Task: task.Name, Group: task.Group}
+	if n := issues.IssueNumber(task); n > 0 {
+		vars.Issue = strconv.Itoa(n)
+	}
+	return design.RenderCommitTemplate(tmpl, vars), nil
+}
+
+// checkCommitTemplate renders the commit template for a task and checks the
+// subjects of the commits its branch adds to the default branch, printing
+// any that don't follow the template.
+func (r *Runner) checkCommitTemplate(taskRepo repo.VCS, task *design.Task) (commitTemplateCheck, error) {
+	tmpl, err := r.commitTemplate(task)
+	if err != nil || tmpl == "" {
+		return commitTemplateCheck{}, err
+	}
+
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return commitTemplateCheck{}, fmt.Errorf("detecting default branch: %w", err)
+	}
+	subjects, err := taskRepo.CommitSubjects("origin/"+defaultBranch, task.BranchName())
+	if err != nil {
+		return commitTemplateCheck{}, fmt.Errorf("listing commits: %w", err)
+	}
+
+	check := commitTemplateCheck{
+		Template:   tmpl,
+		Mismatched: design.MismatchedCommitSubjects(tmpl, subjects),
+	}
+	if len(check.Mismatched) > 0 {
+		fmt.Printf("%d commit(s) do not follow commit_template.md:\n", len(check.Mismatched))
+		for _, s := range check.Mismatched {
+			fmt.Printf("  %s\n", s)
+		}
+	}
+	return check, nil
+}
+
+// commitTemplateSection returns a markdown section asking Claude to reword
+// the commits that don't follow the commit template. Returns empty string if
+// every commit follows it.
+func commitTemplateSection(check commitTemplateCheck) string {
+	if len(check.Mismatched) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Commit Template\n\n")
+	b.WriteString("Commit messages in this project must follow this template. " +
+		"Each `<...>` placeholder stands for your own text; everything else is literal:\n\n")
+	b.WriteString("```\n" + check.Template + "\n```\n\n")
+	b.WriteString("The following commits on this branch do not follow it. " +
+		"Reword each of them (for example with `git rebase -i` and `reword`, " +
+		"using `GIT_SEQUENCE_EDITOR` to edit the todo list non-interactively) " +
+		"so that every commit message follows the template:\n\n")
+	for _, s := range check.Mismatched {
+		b.WriteString("- " + s + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
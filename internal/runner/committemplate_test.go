@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+func TestCommitTemplateRendersIssueNumber(t *testing.T) {
+	r := stubRunner(t)
+	writeFile(t, filepath.Join(r.Design.Path, "commit_template.md"), "fix: <summary>\n\nCloses #{{issue}}\n")
+
+	got, err := r.commitTemplate(&design.Task{Name: "42-fix-bug", Group: "issues"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "fix: <summary>\n\nCloses #42" {
+		t.Errorf("issue task template = %q", got)
+	}
+
+	got, err = r.commitTemplate(&design.Task{Name: "add-feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "fix: <summary>" {
+		t.Errorf("non-issue task template = %q", got)
+	}
+}
+
+func TestCommitInstructionsIncludeTemplate(t *testing.T) {
+	suffix := documentSuffix(suffixOpts{CommitTmpl: "feat: <description>"})
+	if !strings.Contains(suffix, "must follow the project's commit template") ||
+		!strings.Contains(suffix, "```\nfeat: <description>\n```") {
+		t.Errorf("commit instructions should include the template:\n%s", suffix)
+	}
+	if strings.Contains(documentSuffix(suffixOpts{}), "commit template") {
+		t.Error("commit instructions without a template should not mention one")
+	}
+}
+
+func TestCheckCommitTemplate(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	writeFile(t, filepath.Join(env.DesignDir, "commit_template.md"), "<type>: <description>\n")
+
+	gitRun(t, "-C", env.BaseDir, "checkout", "-q", "-b", "hydra/add-feature")
+	for _, msg := range []string{"feat: add the feature", "tweak things"} {
+		gitRun(t, "-C", env.BaseDir, "commit", "-q", "--allow-empty", "-m", msg)
+	}
+	gitRun(t, "-C", env.BaseDir, "checkout", "-q", "main")
+
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check, err := r.checkCommitTemplate(repo.Open(env.BaseDir), task)
+	if err != nil {
+		t.Fatalf("checkCommitTemplate: %v", err)
+	}
+	if check.Template != "<type>: <description>" {
+		t.Errorf("template = %q", check.Template)
+	}
+	if strings.Join(check.Mismatched, "|") != "tweak things" {
+		t.Errorf("mismatched = %q, want [tweak things]", check.Mismatched)
+	}
+
+	section := commitTemplateSection(check)
+	if !strings.Contains(section, "## Commit Template") || !strings.Contains(section, "- tweak things\n") {
+		t.Errorf("section should list the mismatched commit:\n%s", section)
+	}
+	if commitTemplateSection(commitTemplateCheck{Template: "x"}) != "" {
+		t.Error("section should be empty when every commit follows the template")
+	}
+}
@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// Compare shows how the branches of two tasks diverge. It lists the files
+// each task changed relative to the default branch and flags files changed
+// by both, which are likely to conflict when the second task is merged. If
+// patch is set, it also prints each branch's changes since the two diverged.
+func (r *Runner) Compare(taskA, taskB string, patch bool) error {
+	a, err := r.Design.FindTaskAny(taskA)
+	if err != nil {
+		return err
+	}
+	b, err := r.Design.FindTaskAny(taskB)
+	if err != nil {
+		return err
+	}
+	if a.BranchName() == b.BranchName() {
+		return errors.New("cannot compare a task with itself")
+	}
+
+	// Work directories are worktrees of the source repository, so every
+	// task branch is visible from it.
+	mainRepo := repo.Open(r.Config.RepoDir)
+	if err := mainRepo.Fetch(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: fetch failed: %v\n", err)
+	}
+
+	refA, err := taskRef(mainRepo, a)
+	if err != nil {
+		return err
+	}
+	refB, err := taskRef(mainRepo, b)
+	if err != nil {
+		return err
+	}
+
+	defaultBranch, err := r.detectDefaultBranch(mainRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+	originRef := "origin/" + defaultBranch
+
+	mergeBase, err := mainRepo.MergeBase(refA, refB)
+	if err != nil {
+		return fmt.Errorf("finding merge base: %w", err)
+	}
+	filesA, err := mainRepo.ChangedFiles(originRef, refA)
+	if err != nil {
+		return fmt.Errorf("listing changes on %s: %w", refA, err)
+	}
+	filesB, err := mainRepo.ChangedFiles(originRef, refB)
+	if err != nil {
+		return fmt.Errorf("listing changes on %s: %w", refB, err)
+	}
+
+	fmt.Printf("Comparing %s and %s (merge base %s)\n", refA, refB, mergeBase[:12])
+
+	onlyA, onlyB, both := splitFiles(filesA, filesB)
+	printFileList("Only changed by "+taskA, onlyA)
+	printFileList("Only changed by "+taskB, onlyB)
+	if len(both) == 0 {
+		fmt.Println("\nNo files changed by both tasks.")
+	} else {
+		printFileList("Changed by both tasks (may conflict)", both)
+	}
+
+	if !patch {
+		return nil
+	}
+	for _, side := range [][2]string{{refB, refA}, {refA, refB}} {
+		diff, err := mainRepo.DiffRange(side[0], side[1])
+		if err != nil {
+			return fmt.Errorf("getting diff: %w", err)
+		}
+		fmt.Printf("\nChanges on %s since the merge base:\n", side[1])
+		if diff == "" {
+			fmt.Println("No changes.")
+		} else {
+			fmt.Println(diff)
+		}
+	}
+	return nil
+}
+
+// taskRef returns the ref of a task's branch in the source repository,
+// preferring the local branch and falling back to the pushed one.
+func taskRef(sourceRepo *repo.Repo, task *design.Task) (string, error) {
+	branch := task.BranchName()
+	if sourceRepo.BranchExists(branch) {
+		return branch, nil
+	}
+	if remoteRef := sourceRepo.PushRemote() + "/" + branch; sourceRepo.BranchExists(remoteRef) {
+		return remoteRef, nil
+	}
+	return "", fmt.Errorf("task %q has no branch %s; run it first", task.Name, branch)
+}
+
+// splitFiles splits two sorted file lists into the files only in a, only in
+// b, and in both.
+func splitFiles(a, b []string) (onlyA, onlyB, both []string) {
+	inB := make(map[string]bool, len(b))
+	for _, f := range b {
+		inB[f] = true
+	}
+	inA := make(map[string]bool, len(a))
+	for _, f := range a {
+		inA[f] = true
+		if inB[f] {
+			both = append(both, f)
+		} else {
+			onlyA = append(onlyA, f)
+		}
+	}
+	for _, f := range b {
+		if !inA[f] {
+			onlyB = append(onlyB, f)
+		}
+	}
+	return onlyA, onlyB, both
+}
+
+// printFileList prints a titled list of files with its count.
+func printFileList(title string, files []string) {
+	fmt.Printf("\n%s (%d):\n", title, len(files))
+	for _, f := range files {
+		fmt.Printf("  %s\n", f)
+	}
+}
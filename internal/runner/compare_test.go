@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commitOnBranch creates branch from main in dir and commits files to it.
+func commitOnBranch(t *testing.T, dir, branch string, files map[string]string) {
+	t.Helper()
+	gitRun(t, "-C", dir, "checkout", "-q", "-b", branch, "main")
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+		gitRun(t, "-C", dir, "add", name)
+	}
+	gitRun(t, "-C", dir, "commit", "-q", "-m", "work on "+branch)
+	gitRun(t, "-C", dir, "checkout", "-q", "main")
+}
+
+func TestCompareFlagsOverlappingFiles(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+
+	commitOnBranch(t, env.BaseDir, "hydra/add-feature", map[string]string{
+		"feature.go": "package main",
+		"shared.go":  "package main // feature",
+	})
+	commitOnBranch(t, env.BaseDir, "hydra/another-task", map[string]string{
+		"other.go":  "package main",
+		"shared.go": "package main // other",
+	})
+
+	old := os.Stdout
+	rd, w, _ := os.Pipe()
+	os.Stdout = w
+	err = r.Compare("add-feature", "another-task", false)
+	if cerr := w.Close(); cerr != nil {
+		t.Logf("w.Close: %v", cerr)
+	}
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	data, _ := io.ReadAll(rd)
+	out := string(data)
+
+	for _, want := range []string{
+		"Only changed by add-feature (1):\n  feature.go\n",
+		"Only changed by another-task (1):\n  other.go\n",
+		"Changed by both tasks (may conflict) (1):\n  shared.go\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompareRequiresBranches(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+
+	if err := r.Compare("add-feature", "another-task", false); err == nil || !strings.Contains(err.Error(), "run it first") {
+		t.Errorf("Compare without branches error = %v, want missing branch", err)
+	}
+	if err := r.Compare("add-feature", "add-feature", false); err == nil {
+		t.Error("comparing a task with itself should fail")
+	}
+}
+
+func TestSplitFiles(t *testing.T) {
+	onlyA, onlyB, both := splitFiles([]string{"a", "c", "d"}, []string{"b", "c"})
+	if strings.Join(onlyA, ",") != "a,d" || strings.Join(onlyB, ",") != "b" || strings.Join(both, ",") != "c" {
+		t.Errorf("splitFiles = %v, %v, %v", onlyA, onlyB, both)
+	}
+}
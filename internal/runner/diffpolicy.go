@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// hunkHeader matches a unified diff hunk header, capturing the first line
+// of the new side.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// diffFinding is an added line that matches a diff policy check.
+type diffFinding struct {
+	File  string
+	Line  int
+	Check string
+	Text  string
+}
+
+// String formats the finding as a markdown list item.
+func (f diffFinding) String() string {
+	return fmt.Sprintf("- `%s:%d` (%s): `%s`", f.File, f.Line, f.Check, f.Text)
+}
+
+// scanDiff returns the lines a unified diff adds that match any of rules,
+// with their file and line number on the new side. A line matching
+// several rules is reported once, for the first.
+func scanDiff(diff string, rules []taskrun.DiffRule) []diffFinding {
+	var findings []diffFinding
+	var file string
+	line, inHunk := 0, false
+	for text := range strings.SplitSeq(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff "):
+			file, inHunk = "", false
+		case !inHunk && strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeader.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+				inHunk = true
+			}
+		case !inHunk:
+		case strings.HasPrefix(text, "+"):
+			added := strings.TrimPrefix(text, "+")
+			for _, r := range rules {
+				if file != "/dev/null" && r.Re.MatchString(added) {
+					findings = append(findings, diffFinding{File: file, Line: line, Check: r.Name, Text: strings.TrimSpace(added)})
+					break
+				}
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// diffPolicySection tells a follow-up session which added lines break the
+// diff policy and asks it to fix them.
+func diffPolicySection(findings []diffFinding) string {
+	var b strings.Builder
+	b.WriteString("\n\n# Diff Policy Violations\n\n")
+	b.WriteString("The commits made for this task add lines that the project's diff policy forbids. " +
+		"Finish the work these lines stand in for: implement what was left as a TODO or stub, make skipped " +
+		"tests pass instead of skipping them, and fix lint findings instead of suppressing them. " +
+		"Then commit the fixes following the instructions above. Do not rewrite or squash the existing commits.\n\n")
+	for _, f := range findings {
+		b.WriteString(f.String() + "\n")
+	}
+	return b.String()
+}
+
+// enforceDiffPolicy scans the task branch's changes, from where it forks
+// from the default branch on origin, for lines hydra.yml's diff_policy
+// forbids. Commits the branch picked up by rebasing aren't its changes, so
+// they aren't scanned. While there are violations, it runs follow-up
+// sessions with the findings appended to cfg's document, up to the
+// policy's limit, and fails if violations remain after the last one. The
+// follow-ups' tokens are added to cfg.Tokens.
+func (r *Runner) enforceDiffPolicy(ctx context.Context, claudeFn ClaudeFunc, cfg ClaudeRunConfig, taskRepo repo.VCS) error {
+	if r.TaskRunner == nil || r.TaskRunner.DiffPolicy == nil {
+		return nil
+	}
+	policy := r.TaskRunner.DiffPolicy
+	rules, err := policy.Rules()
+	if err != nil {
+		return fmt.Errorf("diff policy: %w", err)
+	}
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return fmt.Errorf("diff policy: detecting default branch: %w", err)
+	}
+
+	doc, total := cfg.Document, cfg.Tokens
+	for followUps := 0; ; followUps++ {
+		head, err := taskRepo.LastCommitSHA()
+		if err != nil {
+			return fmt.Errorf("getting HEAD SHA: %w", err)
+		}
+		base, err := taskRepo.MergeBase("origin/"+defaultBranch, head)
+		if err != nil {
+			return fmt.Errorf("diff policy: finding merge base: %w", err)
+		}
+		diff, err := taskRepo.DiffRange(base, head)
+		if err != nil {
+			return fmt.Errorf("diff policy: reading diff: %w", err)
+		}
+		findings := scanDiff(diff, rules)
+		if len(findings) == 0 {
+			return nil
+		}
+		if followUps == policy.FollowUpLimit() {
+			var list strings.Builder
+			for _, f := range findings {
+				list.WriteString("\n" + f.String())
+			}
+			return fmt.Errorf("diff policy: %d violation(s) remain after %d follow-up session(s):%s", len(findings), followUps, list.String())
+		}
+
+		fmt.Fprintf(os.Stderr, "Diff policy: %d violation(s) in the new commits; starting a follow-up session\n", len(findings))
+		var used int64
+		cfg.Document = doc + diffPolicySection(findings)
+		cfg.PlanMode = false
+		cfg.Tokens = &used
+		if err := continueOnTimeout(runClaude(ctx, claudeFn, cfg)); err != nil {
+			return err
+		}
+		if total != nil {
+			*total += used
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestScanDiff(t *testing.T) {
+	rules, err := (&taskrun.DiffPolicy{}).Rules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,4 +3,6 @@ package main
+ func main() {
+-	// TODO: old note, removed
++	run() //nolint:errcheck
+ 	fmt.Println("hi")
++	// TODO: handle flags
+ }
+diff --git a/main_test.go b/main_test.go
+new file mode 100644
+--- /dev/null
++++ b/main_test.go
+@@ -0,0 +1,3 @@
++func TestMain(t *testing.T) {
++	t.Skip("later")
++}
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-panic("not implemented")
+`
+	got := scanDiff(diff, rules)
+	want := []diffFinding{
+		{File: "main.go", Line: 4, Check: taskrun.CheckNolint, Text: "run() //nolint:errcheck"},
+		{File: "main.go", Line: 6, Check: taskrun.CheckTodo, Text: "// TODO: handle flags"},
+		{File: "main_test.go", Line: 2, Check: taskrun.CheckSkippedTest, Text: `t.Skip("later")`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("scanDiff() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiffPolicySection(t *testing.T) {
+	section := diffPolicySection([]diffFinding{{File: "a.go", Line: 7, Check: "todo", Text: "// TODO"}})
+	if !strings.Contains(section, "# Diff Policy Violations") {
+		t.Error("missing heading")
+	}
+	if !strings.Contains(section, "- `a.go:7` (todo): `// TODO`") {
+		t.Errorf("missing finding in:\n%s", section)
+	}
+}
+
+func TestDiffPolicyIgnoresRebasedUpstreamCommits(t *testing.T) {
+	env := setupTestEnv(t)
+	writeFile(t, filepath.Join(env.DesignDir, "hydra.yml"), "diff_policy:\n  checks: [todo]\n  follow_ups: 0\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+	var sessions int
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		sessions++
+		// Someone else lands a TODO on main while the session runs, and the
+		// session rebases onto it before committing its own change.
+		writeFile(t, filepath.Join(env.BaseDir, "upstream.go"), "// TODO: upstream\n")
+		gitRun(t, "-C", env.BaseDir, "add", "upstream.go")
+		gitRun(t, "-C", env.BaseDir, "commit", "-m", "upstream change")
+		gitRun(t, "-C", env.BaseDir, "push", "origin", "main")
+		gitRun(t, "-C", cfg.RepoDir, "fetch", "origin")
+		gitRun(t, "-C", cfg.RepoDir, "rebase", "origin/main")
+		writeFile(t, filepath.Join(cfg.RepoDir, "feature.go"), "package main\n")
+		return mockCommit(cfg.RepoDir)
+	}
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sessions != 1 {
+		t.Errorf("sessions = %d, want 1: the upstream TODO is not the branch's change", sessions)
+	}
+}
@@ -0,0 +1,239 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+// Estimate projects the duration, token use, and cost of task runs from
+// the history in record.json: a batch of runs for EstimateGroup, or a
+// single run about to start, whose document size picks the past runs it is
+// compared with.
+type Estimate struct {
+	Tasks        int
+	Names        []string      // the tasks, in the order they run; set by EstimateGroup
+	DocTokens    int64         // estimated tokens in a single run's document; 0 for a batch
+	Samples      int           // past runs the estimate is based on
+	Similar      bool          // whether the samples had similarly sized documents
+	AvgDuration  time.Duration // average Claude session duration per run
+	MinDuration  time.Duration // lower quartile of the samples' session durations
+	MaxDuration  time.Duration // upper quartile of the samples' session durations
+	TokenSamples int           // samples with recorded token usage
+	AvgTokens    int64         // average session tokens per run
+	MinTokens    int64         // lower quartile of the samples' session tokens
+	MaxTokens    int64         // upper quartile of the samples' session tokens
+	PricePerMTok float64       // USD per million tokens from hydra.yml; 0 if unknown
+}
+
+// TotalDuration returns the projected wall time for all tasks.
+func (e *Estimate) TotalDuration() time.Duration {
+	return e.AvgDuration * time.Duration(e.Tasks)
+}
+
+// TotalCost returns the projected API cost for all tasks, in USD, from the
+// tokens of past runs at PricePerMTok. It returns 0 if either is unknown.
+func (e *Estimate) TotalCost() float64 {
+	return e.tokenCost(e.AvgTokens) * float64(e.Tasks)
+}
+
+// tokenCost returns what tokens cost at PricePerMTok, in USD.
+func (e *Estimate) tokenCost(tokens int64) float64 {
+	return float64(tokens) * e.PricePerMTok / 1e6
+}
+
+// String returns a one-line human-readable summary of the estimate.
+func (e *Estimate) String() string {
+	if e.DocTokens > 0 {
+		return e.runString()
+	}
+	if e.Samples == 0 {
+		return fmt.Sprintf("%d task(s); no run history yet, so no estimate is available", e.Tasks)
+	}
+
+	s := fmt.Sprintf("%d task(s)", e.Tasks)
+	if e.AvgDuration > 0 {
+		s += fmt.Sprintf(" × ~%s avg = ~%s", e.AvgDuration.Round(time.Second), e.TotalDuration().Round(time.Minute))
+	}
+	if e.TokenSamples > 0 {
+		s += fmt.Sprintf(", ~%s tokens", formatTokens(e.AvgTokens*int64(e.Tasks)))
+	}
+	if cost := e.TotalCost(); cost > 0 {
+		s += fmt.Sprintf(", ~$%.2f", cost)
+	}
+	s += fmt.Sprintf(" (based on %d past run(s))", e.Samples)
+	return s
+}
+
+// runString returns the summary of a single run's estimate.
+func (e *Estimate) runString() string {
+	s := fmt.Sprintf("document ~%s tokens", formatTokens(e.DocTokens))
+	if e.Samples == 0 {
+		return s + "; no run history yet, so no session estimate is available"
+	}
+	if e.TokenSamples > 0 {
+		s += fmt.Sprintf("; session ~%s–%s tokens", formatTokens(e.MinTokens), formatTokens(e.MaxTokens))
+	}
+	if e.PricePerMTok > 0 && e.TokenSamples > 0 {
+		s += fmt.Sprintf(", ~$%.2f–$%.2f", e.tokenCost(e.MinTokens), e.tokenCost(e.MaxTokens))
+	}
+	if e.MaxDuration > 0 {
+		s += fmt.Sprintf(", ~%s–%s", e.MinDuration.Round(time.Minute), e.MaxDuration.Round(time.Minute))
+	}
+	if e.Similar {
+		return s + fmt.Sprintf(" (based on %d past run(s) with similarly sized documents)", e.Samples)
+	}
+	return s + fmt.Sprintf(" (based on %d past run(s))", e.Samples)
+}
+
+// minSimilarRuns is how many past runs with a similarly sized document a
+// run estimate needs before it stops falling back on every past run.
+const minSimilarRuns = 3
+
+// estimateFromRecord computes an Estimate for n tasks from record entries.
+// Only task runs with a recorded duration or token count are considered;
+// review, test, and merge entries are skipped. For a single run whose
+// document has doc tokens, past runs whose documents had between half and
+// twice as many are preferred; with fewer than minSimilarRuns of them,
+// every past run is used. A doc of 0 uses every past run.
+func estimateFromRecord(entries []design.RecordEntry, n int, doc int64) *Estimate {
+	est := &Estimate{Tasks: n, DocTokens: doc}
+
+	var all, similar []design.RecordEntry
+	for _, e := range entries {
+		if strings.Contains(e.TaskName, ":") || (e.DurationSeconds <= 0 && e.Tokens <= 0) {
+			continue
+		}
+		all = append(all, e)
+		if doc > 0 && e.DocTokens > 0 && e.DocTokens*2 >= doc && e.DocTokens <= doc*2 {
+			similar = append(similar, e)
+		}
+	}
+	samples := all
+	if len(similar) >= minSimilarRuns {
+		samples, est.Similar = similar, true
+	}
+	est.Samples = len(samples)
+
+	var tokens []int64
+	var durations []time.Duration
+	var totalTokens int64
+	var totalDuration time.Duration
+	for _, e := range samples {
+		if e.Tokens > 0 {
+			tokens = append(tokens, e.Tokens)
+			totalTokens += e.Tokens
+		}
+		if e.DurationSeconds > 0 {
+			d := time.Duration(e.DurationSeconds * float64(time.Second))
+			durations = append(durations, d)
+			totalDuration += d
+		}
+	}
+
+	if len(durations) > 0 {
+		est.AvgDuration = totalDuration / time.Duration(len(durations))
+		est.MinDuration, est.MaxDuration = quartiles(durations)
+	}
+	if est.TokenSamples = len(tokens); est.TokenSamples > 0 {
+		est.AvgTokens = totalTokens / int64(est.TokenSamples)
+		est.MinTokens, est.MaxTokens = quartiles(tokens)
+	}
+	return est
+}
+
+// quartiles returns the lower and upper quartiles of values, which it sorts.
+func quartiles[T int64 | time.Duration](values []T) (lo, hi T) {
+	slices.Sort(values)
+	return values[(len(values)-1)/4], values[(len(values)-1)*3/4]
+}
+
+// docTokens estimates the tokens in a document at four characters a token.
+func docTokens(doc string) int64 {
+	return int64((len(doc) + 3) / 4)
+}
+
+// pricePerMTok returns cost_per_mtok from hydra.yml, or 0 if it isn't set.
+func (r *Runner) pricePerMTok() float64 {
+	if r.TaskRunner == nil {
+		return 0
+	}
+	return r.TaskRunner.CostPerMTok
+}
+
+// averageRunDuration returns the average Claude session duration of past
+// task runs, or zero if there is no history to go on.
+func (r *Runner) averageRunDuration() time.Duration {
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		return 0
+	}
+	return estimateFromRecord(entries, 1, 0).AvgDuration
+}
+
+// EstimateGroup returns a run-time and cost estimate for the pending tasks in
+// a group, based on historical averages from record.json.
+func (r *Runner) EstimateGroup(groupName string) (*Estimate, error) {
+	tasks, err := r.Design.PendingTasks()
+	if err != nil {
+		return nil, fmt.Errorf("listing pending tasks: %w", err)
+	}
+
+	var names []string
+	for _, t := range tasks {
+		if t.Group == groupName {
+			names = append(names, taskLabel(t))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no pending tasks found in group %q%s", groupName, groupHint(groupName, tasks))
+	}
+	slices.Sort(names)
+
+	entries, err := design.NewRecord(r.Design.Path).Entries()
+	if err != nil {
+		return nil, err
+	}
+	est := estimateFromRecord(entries, len(names), 0)
+	est.Names = names
+	est.PricePerMTok = r.pricePerMTok()
+	return est, nil
+}
+
+// errCostDeclined is returned by a run whose estimate was turned down at the
+// --confirm-cost prompt.
+var errCostDeclined = errors.New("run declined after the cost estimate")
+
+// formatTokens abbreviates a token count, such as 12.3k or 1.2M.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+// estimateTaskRun prints the estimate for a run whose document is doc and,
+// when ConfirmCost is set, asks whether to go ahead. It returns
+// errCostDeclined if the run is turned down.
+func (r *Runner) estimateTaskRun(doc string) error {
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: reading run history for the estimate: %v\n", err)
+	}
+	est := estimateFromRecord(entries, 1, docTokens(doc))
+	est.PricePerMTok = r.pricePerMTok()
+	fmt.Printf("Estimate: %s\n", est)
+	if r.ConfirmCost != nil && !r.ConfirmCost() {
+		return errCostDeclined
+	}
+	return nil
+}
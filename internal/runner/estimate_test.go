@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestEstimateFromRecord(t *testing.T) {
+	entries := []design.RecordEntry{
+		{SHA: "a", TaskName: "one", DurationSeconds: 600, Tokens: 400_000},
+		{SHA: "b", TaskName: "backend/two", DurationSeconds: 1200, Tokens: 600_000},
+		{SHA: "c", TaskName: "three"}, // older entry without timing data
+		{SHA: "d", TaskName: "review:one", DurationSeconds: 9999},
+	}
+
+	est := estimateFromRecord(entries, 3, 0)
+	est.PricePerMTok = 4
+	if est.Samples != 2 {
+		t.Errorf("Samples = %d, want 2", est.Samples)
+	}
+	if est.AvgDuration != 15*time.Minute {
+		t.Errorf("AvgDuration = %s, want 15m", est.AvgDuration)
+	}
+	if est.TotalDuration() != 45*time.Minute {
+		t.Errorf("TotalDuration = %s, want 45m", est.TotalDuration())
+	}
+	if est.TotalCost() != 6 {
+		t.Errorf("TotalCost = %v, want 6", est.TotalCost())
+	}
+	if s := est.String(); !strings.Contains(s, "45m") || !strings.Contains(s, "$6.00") {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestEstimateNoHistory(t *testing.T) {
+	est := estimateFromRecord(nil, 4, 0)
+	if est.TotalDuration() != 0 {
+		t.Errorf("TotalDuration = %s, want 0", est.TotalDuration())
+	}
+	if s := est.String(); !strings.Contains(s, "no run history") {
+		t.Errorf("String = %q", s)
+	}
+}
+
+func TestEstimateGroup(t *testing.T) {
+	r := stubRunner(t)
+	mkdirAll(t, filepath.Join(r.Design.Path, "tasks", "backend"))
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "backend", "a.md"), "A.")
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "backend", "b.md"), "B.")
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "other.md"), "Other.")
+
+	rec := design.NewRecord(r.Design.Path)
+	if err := rec.AddEntry(design.RecordEntry{SHA: "x", TaskName: "other", DurationSeconds: 300}); err != nil {
+		t.Fatal(err)
+	}
+
+	est, err := r.EstimateGroup("backend")
+	if err != nil {
+		t.Fatalf("EstimateGroup: %v", err)
+	}
+	if est.Tasks != 2 || est.TotalDuration() != 10*time.Minute {
+		t.Errorf("estimate = %+v", est)
+	}
+
+	if _, err := r.EstimateGroup("missing"); err == nil {
+		t.Error("expected error for group with no pending tasks")
+	}
+}
+
+func TestEstimateSingleRun(t *testing.T) {
+	entries := []design.RecordEntry{
+		{SHA: "a", TaskName: "one", Tokens: 100_000, DocTokens: 4_000, DurationSeconds: 300},
+		{SHA: "b", TaskName: "two", Tokens: 200_000, DocTokens: 5_000, DurationSeconds: 600},
+		{SHA: "c", TaskName: "three", Tokens: 300_000, DocTokens: 6_000, DurationSeconds: 900},
+		{SHA: "d", TaskName: "four", Tokens: 400_000, DocTokens: 7_000, DurationSeconds: 1200},
+		{SHA: "e", TaskName: "huge", Tokens: 5_000_000, DocTokens: 80_000},
+		{SHA: "f", TaskName: "old", Tokens: 50_000},
+		{SHA: "g", TaskName: "review:one", Tokens: 999_999, DocTokens: 5_000},
+	}
+
+	est := estimateFromRecord(entries, 1, 5_000)
+	est.PricePerMTok = 3
+	if !est.Similar || est.Samples != 4 {
+		t.Fatalf("Similar, Samples = %v, %d; want true, 4", est.Similar, est.Samples)
+	}
+	if est.MinTokens != 100_000 || est.MaxTokens != 300_000 {
+		t.Errorf("tokens = %d–%d, want 100000–300000", est.MinTokens, est.MaxTokens)
+	}
+	if est.MinDuration != 5*time.Minute || est.MaxDuration != 15*time.Minute {
+		t.Errorf("duration = %s–%s, want 5m–15m", est.MinDuration, est.MaxDuration)
+	}
+	s := est.String()
+	for _, want := range []string{"document ~5.0k tokens", "100.0k–300.0k tokens", "$0.30–$0.90", "5m0s–15m0s", "similarly sized"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q, missing %q", s, want)
+		}
+	}
+
+	// Too few runs of a similar size falls back on every run.
+	est = estimateFromRecord(entries, 1, 80_000)
+	if est.Similar || est.Samples != 6 {
+		t.Errorf("Similar, Samples = %v, %d; want false, 6", est.Similar, est.Samples)
+	}
+	if s := est.String(); strings.Contains(s, "$") {
+		t.Errorf("String() without a price = %q", s)
+	}
+
+	if s := estimateFromRecord(nil, 1, 10).String(); !strings.Contains(s, "no run history") {
+		t.Errorf("String() without history = %q", s)
+	}
+
+	// Runs with only a recorded duration still give a time estimate.
+	est = estimateFromRecord([]design.RecordEntry{{SHA: "a", TaskName: "one", DurationSeconds: 600}}, 1, 5_000)
+	if s := est.String(); est.Samples != 1 || !strings.Contains(s, "10m0s–10m0s") || strings.Contains(s, "session ~") {
+		t.Errorf("String() with durations only = %q", s)
+	}
+}
+
+func TestEstimateTaskRunDeclined(t *testing.T) {
+	r := stubRunner(t)
+	r.ConfirmCost = func() bool { return false }
+	if err := r.estimateTaskRun("# Task\n"); !errors.Is(err, errCostDeclined) {
+		t.Errorf("estimateTaskRun = %v, want errCostDeclined", err)
+	}
+	r.ConfirmCost = func() bool { return true }
+	if err := r.estimateTaskRun("# Task\n"); err != nil {
+		t.Errorf("estimateTaskRun = %v, want nil", err)
+	}
+}
+
+func TestEstimateBeforePlanHandoff(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+	r.PlanHandoff = true
+	r.ConfirmCost = func() bool { return false }
+	sessions := 0
+	r.Claude = func(context.Context, ClaudeRunConfig) error {
+		sessions++
+		return nil
+	}
+
+	if err := r.Run("add-feature"); !errors.Is(err, errCostDeclined) {
+		t.Fatalf("Run = %v, want errCostDeclined", err)
+	}
+	if sessions != 0 {
+		t.Errorf("%d session(s) ran before the estimate was declined, want none", sessions)
+	}
+}
@@ -5,19 +5,25 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
 	"github.com/erikh/hydra/internal/lock"
 	"github.com/erikh/hydra/internal/repo"
 )
 
-// fixAction describes a single issue found by the scanner and a function to fix it.
+// fixAction describes a single issue found by the scanner and, when it can
+// be fixed automatically, a function to fix it.
 type fixAction struct {
+	kind        string // stable issue type, reported by fix --check
+	subject     string // task, path, or lock the issue is about
 	description string
-	fix         func() error
+	fix         func() error // nil for issues that can only be reported
 }
 
 // Fix scans the project for issues, reports them, and prompts for confirmation
@@ -36,43 +42,21 @@ func (r *Runner) Fix(autoConfirm bool) error {
 		return fmt.Errorf("checking duplicate tasks: %w", err)
 	}
 
-	// Scan for all other fixable issues.
-	var actions []fixAction
-
-	a, err := r.scanStaleLocks(baseDir)
-	if err != nil {
-		return fmt.Errorf("checking stale locks: %w", err)
-	}
-	actions = append(actions, a...)
-
-	a, err = r.scanWorkDirBranches(baseDir)
-	if err != nil {
-		return fmt.Errorf("checking work directories: %w", err)
-	}
-	actions = append(actions, a...)
-
-	a = r.scanMissingStateDirs()
-	actions = append(actions, a...)
-
-	a, err = r.scanOrphanedWorkDirs(baseDir)
-	if err != nil {
-		return fmt.Errorf("checking orphaned work dirs: %w", err)
-	}
-	actions = append(actions, a...)
-
-	a, err = r.scanStuckMergeTasks()
+	// Scan for all other issues.
+	issues, err := r.scanIssues(baseDir)
 	if err != nil {
-		return fmt.Errorf("checking stuck merge tasks: %w", err)
+		return err
 	}
-	actions = append(actions, a...)
 
-	// Report non-fixable issues (remotes).
-	warns, err := r.scanWorkDirRemotes(baseDir)
-	if err != nil {
-		return fmt.Errorf("checking remotes: %w", err)
-	}
-	for _, w := range warns {
-		fmt.Println(w)
+	// Report issues that can't be fixed automatically.
+	var actions, warns []fixAction
+	for _, a := range issues {
+		if a.fix == nil {
+			warns = append(warns, a)
+			fmt.Println(a.description)
+		} else {
+			actions = append(actions, a)
+		}
 	}
 
 	total := dupes + len(actions) + len(warns)
@@ -94,17 +78,7 @@ func (r *Runner) Fix(autoConfirm bool) error {
 
 	// Prompt for confirmation unless auto-confirmed.
 	if !autoConfirm {
-		fmt.Printf("\nApply %d fix(es)? [y/N] ", len(actions))
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not read input: %v\n", err)
-			fmt.Println("Aborted.")
-			return nil
-		}
-		input = strings.TrimSpace(strings.ToLower(input))
-
-		if input != "y" && input != "yes" {
+		if !Confirm(fmt.Sprintf("\nApply %d fix(es)?", len(actions))) {
 			fmt.Println("Aborted.")
 			return nil
 		}
@@ -123,10 +97,103 @@ func (r *Runner) Fix(autoConfirm bool) error {
 	return nil
 }
 
-// fixDuplicateTaskNames checks for the same task name appearing in multiple states.
-// When duplicates are found, prompts the user to choose which copy to keep.
-// Returns the number of conflicts found.
-func (r *Runner) fixDuplicateTaskNames() (int, error) { //nolint:unparam // error kept for future use
+// FixCheck scans for the same issues as Fix, including duplicate task names,
+// without changing anything. Each issue is printed on its own line as
+// "<kind>\t<subject>\t<description>", sorted, so the output is stable for
+// scripts and scheduled jobs. It returns the number of issues found.
+func (r *Runner) FixCheck() (int, error) {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+
+	var issues []fixAction
+	for name, tasks := range r.duplicateTaskNames() {
+		states := make([]string, len(tasks))
+		for i, t := range tasks {
+			states[i] = string(t.State)
+		}
+		sort.Strings(states)
+		issues = append(issues, fixAction{
+			kind:        "duplicate-task",
+			subject:     name,
+			description: fmt.Sprintf("task %q exists in %d states: %s", name, len(tasks), strings.Join(states, ", ")),
+		})
+	}
+
+	scanned, err := r.scanIssues(baseDir)
+	if err != nil {
+		return 0, err
+	}
+	issues = append(issues, scanned...)
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].kind != issues[j].kind {
+			return issues[i].kind < issues[j].kind
+		}
+		return issues[i].subject < issues[j].subject
+	})
+	for _, a := range issues {
+		fmt.Printf("%s\t%s\t%s\n", a.kind, a.subject, a.description)
+	}
+	return len(issues), nil
+}
+
+// scanIssues runs every non-interactive scanner and returns the issues found,
+// fixable or not.
+func (r *Runner) scanIssues(baseDir string) ([]fixAction, error) {
+	var actions []fixAction
+
+	a, err := r.scanStaleLocks(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("checking stale locks: %w", err)
+	}
+	actions = append(actions, a...)
+
+	a, err = r.scanWorkDirBranches(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("checking work directories: %w", err)
+	}
+	actions = append(actions, a...)
+
+	actions = append(actions, r.scanMissingStateDirs()...)
+
+	a, err = r.scanOrphanedWorkDirs()
+	if err != nil {
+		return nil, fmt.Errorf("checking orphaned work dirs: %w", err)
+	}
+	actions = append(actions, a...)
+
+	a, err = r.scanStuckMergeTasks()
+	if err != nil {
+		return nil, fmt.Errorf("checking stuck merge tasks: %w", err)
+	}
+	actions = append(actions, a...)
+
+	a, err = r.scanWorkDirRemotes(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("checking remotes: %w", err)
+	}
+	actions = append(actions, a...)
+
+	a, err = r.scanOrphanedRemoteBranches()
+	if err != nil {
+		return nil, fmt.Errorf("checking orphaned branches: %w", err)
+	}
+	actions = append(actions, a...)
+
+	a, err = r.scanExpiredTasks(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("checking expired tasks: %w", err)
+	}
+	actions = append(actions, a...)
+
+	return actions, nil
+}
+
+// duplicateTaskNames returns the task names that appear in more than one
+// state, with every copy found.
+func (r *Runner) duplicateTaskNames() map[string][]design.Task {
 	seen := make(map[string][]design.Task)
 
 	for _, state := range []design.TaskState{
@@ -142,12 +209,21 @@ func (r *Runner) fixDuplicateTaskNames() (int, error) { //nolint:unparam // erro
 		}
 	}
 
-	reader := bufio.NewReader(os.Stdin)
-	issues := 0
 	for name, tasks := range seen {
 		if len(tasks) <= 1 {
-			continue
+			delete(seen, name)
 		}
+	}
+	return seen
+}
+
+// fixDuplicateTaskNames checks for the same task name appearing in multiple states.
+// When duplicates are found, prompts the user to choose which copy to keep.
+// Returns the number of conflicts found.
+func (r *Runner) fixDuplicateTaskNames() (int, error) { //nolint:unparam // error kept for future use
+	reader := bufio.NewReader(os.Stdin)
+	issues := 0
+	for name, tasks := range r.duplicateTaskNames() {
 		issues++
 
 		fmt.Printf("CONFLICT: task %q exists in %d states:\n", name, len(tasks))
@@ -225,6 +301,8 @@ func (r *Runner) scanStaleLocks(baseDir string) ([]fixAction, error) {
 		if !isLive {
 			p := path // capture for closure
 			actions = append(actions, fixAction{
+				kind:        "stale-lock",
+				subject:     base,
 				description: "remove stale lock " + base,
 				fix:         func() error { return os.Remove(p) },
 			})
@@ -261,7 +339,7 @@ func (r *Runner) scanWorkDirBranches(_ string) ([]fixAction, error) {
 			continue
 		}
 
-		taskRepo := repo.Open(wd)
+		taskRepo := repo.OpenVCS(wd)
 		currentBranch, err := taskRepo.CurrentBranch()
 		if err != nil {
 			continue
@@ -281,12 +359,18 @@ func (r *Runner) scanWorkDirBranches(_ string) ([]fixAction, error) {
 			cb := currentBranch
 			if taskRepo.BranchExists(expectedBranch) {
 				actions = append(actions, fixAction{
+					kind:        "wrong-branch",
+					subject:     tn,
 					description: fmt.Sprintf("checkout %s to %s (currently %s)", tn, eb, cb),
 					fix:         func() error { return tr.Checkout(eb) },
 				})
 			} else {
 				// Can't fix this one, just warn.
-				fmt.Printf("WARN: %s on %s, expected %s (branch does not exist)\n", tn, cb, eb)
+				actions = append(actions, fixAction{
+					kind:        "missing-branch",
+					subject:     tn,
+					description: fmt.Sprintf("WARN: %s on %s, expected %s (branch does not exist)", tn, cb, eb),
+				})
 			}
 		}
 	}
@@ -295,15 +379,15 @@ func (r *Runner) scanWorkDirBranches(_ string) ([]fixAction, error) {
 }
 
 // scanWorkDirRemotes checks that work directory remotes point to the configured source repo.
-// Returns warning strings since remote mismatches can't be auto-fixed.
-func (r *Runner) scanWorkDirRemotes(baseDir string) ([]string, error) {
+// Remote mismatches can't be auto-fixed, so the issues have no fix.
+func (r *Runner) scanWorkDirRemotes(baseDir string) ([]fixAction, error) {
 	tasks, err := r.Design.AllTasks()
 	if err != nil {
 		return nil, err
 	}
 
 	expected := r.Config.SourceRepoURL
-	var warns []string
+	var warns []fixAction
 
 	for _, task := range tasks {
 		wd := r.workDir(&task)
@@ -311,37 +395,54 @@ func (r *Runner) scanWorkDirRemotes(baseDir string) ([]string, error) {
 			continue
 		}
 
-		taskRepo := repo.Open(wd)
+		taskRepo := repo.OpenVCS(wd)
 		remote, err := taskRepo.RemoteURL()
 		if err != nil {
-			warns = append(warns, fmt.Sprintf("ERROR: %s has no origin remote", task.Name))
+			warns = append(warns, fixAction{
+				kind:        "missing-remote",
+				subject:     task.Name,
+				description: fmt.Sprintf("ERROR: %s has no origin remote", task.Name),
+			})
 			continue
 		}
 
 		if remote != expected {
-			warns = append(warns, fmt.Sprintf("MISMATCH: %s remote is %s, expected %s", task.Name, remote, expected))
+			warns = append(warns, fixAction{
+				kind:        "remote-mismatch",
+				subject:     task.Name,
+				description: fmt.Sprintf("MISMATCH: %s remote is %s, expected %s", task.Name, remote, expected),
+			})
 		}
 	}
 
 	// Also check the special work dirs.
-	for _, name := range []string{"_reconcile", "_verify"} {
-		wd := filepath.Join(baseDir, "work", name)
+	for _, name := range specialWorkDirs {
+		wd := filepath.Join(r.workRoot(), name)
 		if !repo.IsGitRepo(wd) {
 			continue
 		}
-		taskRepo := repo.Open(wd)
+		taskRepo := repo.OpenVCS(wd)
 		remote, err := taskRepo.RemoteURL()
 		if err != nil {
 			continue
 		}
 		if remote != expected {
-			warns = append(warns, fmt.Sprintf("MISMATCH: %s remote is %s, expected %s", name, remote, expected))
+			warns = append(warns, fixAction{
+				kind:        "remote-mismatch",
+				subject:     name,
+				description: fmt.Sprintf("MISMATCH: %s remote is %s, expected %s", name, remote, expected),
+			})
 		}
 	}
 
 	return warns, nil
 }
 
+// specialWorkDirs are the work directories hydra keeps for itself rather
+// than for a task: clean checkouts of the default branch for reconcile,
+// split, and verify.
+var specialWorkDirs = []string{"_reconcile", "_split", "_verify"}
+
 // scanMissingStateDirs finds state directories that don't exist.
 func (r *Runner) scanMissingStateDirs() []fixAction {
 	dirs := []string{
@@ -357,6 +458,8 @@ func (r *Runner) scanMissingStateDirs() []fixAction {
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
 			d := dir // capture
 			actions = append(actions, fixAction{
+				kind:        "missing-dir",
+				subject:     d,
 				description: "create missing directory " + d,
 				fix:         func() error { return os.MkdirAll(d, 0o750) },
 			})
@@ -367,8 +470,8 @@ func (r *Runner) scanMissingStateDirs() []fixAction {
 }
 
 // scanOrphanedWorkDirs finds work directories that have no corresponding task.
-func (r *Runner) scanOrphanedWorkDirs(baseDir string) ([]fixAction, error) {
-	workRoot := filepath.Join(baseDir, "work")
+func (r *Runner) scanOrphanedWorkDirs() ([]fixAction, error) {
+	workRoot := r.workRoot()
 	if _, err := os.Stat(workRoot); os.IsNotExist(err) {
 		return nil, nil
 	}
@@ -390,8 +493,9 @@ func (r *Runner) scanOrphanedWorkDirs(baseDir string) ([]fixAction, error) {
 		}
 	}
 	// Special dirs are also leaves.
-	leafDirs[filepath.Join(baseDir, "work", "_reconcile")] = true
-	leafDirs[filepath.Join(baseDir, "work", "_verify")] = true
+	for _, name := range specialWorkDirs {
+		leafDirs[filepath.Join(workRoot, name)] = true
+	}
 
 	return r.collectOrphanedWorkDirs(workRoot, leafDirs, parentDirs)
 }
@@ -430,10 +534,14 @@ func (r *Runner) collectOrphanedWorkDirs(dir string, leafDirs, parentDirs map[st
 		// Not expected — schedule teardown and removal.
 		p := entryPath // capture
 		actions = append(actions, fixAction{
+			kind:        "orphaned-work-dir",
+			subject:     p,
 			description: "remove orphaned work directory " + p,
 			fix: func() error {
-				r.runTeardown(p)
-				return os.RemoveAll(p)
+				if r.workDirBusy(p) {
+					return fmt.Errorf("%s is in use by another hydra command", p)
+				}
+				return r.removeWorkDir(p)
 			},
 		})
 	}
@@ -471,6 +579,8 @@ func (r *Runner) scanStuckMergeTasks() ([]fixAction, error) {
 		// No lock — this task is stuck in merge state.
 		task := t // capture
 		actions = append(actions, fixAction{
+			kind:        "stuck-merge",
+			subject:     taskName,
 			description: fmt.Sprintf("move stuck task %q from merge back to review", taskName),
 			fix:         func() error { return r.Design.MoveTask(task, design.StateReview) },
 		})
@@ -478,3 +588,86 @@ func (r *Runner) scanStuckMergeTasks() ([]fixAction, error) {
 
 	return actions, nil
 }
+
+// scanOrphanedRemoteBranches finds hydra/* branches on the push remote that
+// have no corresponding task in any state, such as branches left behind by a
+// deleted task or a crashed run. Branches merged into the default branch are
+// deleted; unmerged ones may hold the only copy of someone's work, so they
+// are only reported.
+func (r *Runner) scanOrphanedRemoteBranches() ([]fixAction, error) {
+	if r.Config.RepoDir == "" || !repo.IsGitRepo(r.Config.RepoDir) {
+		return nil, nil
+	}
+
+	sourceRepo := repo.Open(r.Config.RepoDir)
+	if err := sourceRepo.Fetch(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not fetch origin, skipping orphaned branch check: %v\n", err)
+		return nil, nil
+	}
+
+	defaultBranch, err := r.detectDefaultBranch(sourceRepo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not detect default branch, skipping orphaned branch check: %v\n", err)
+		return nil, nil
+	}
+	orphans, err := issues.OrphanedBranches(r.Design, sourceRepo, "")
+	if err != nil {
+		return nil, err
+	}
+	merged, err := issues.OrphanedBranches(r.Design, sourceRepo, defaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	isMerged := make(map[string]bool, len(merged))
+	for _, branch := range merged {
+		isMerged[branch] = true
+	}
+
+	remote := sourceRepo.PushRemote()
+	var actions []fixAction
+	for _, branch := range orphans {
+		b := branch // capture for closure
+		if !isMerged[b] {
+			actions = append(actions, fixAction{
+				kind:        "unmerged-branch",
+				subject:     b,
+				description: fmt.Sprintf("orphaned branch %s on %s is not merged into %s; delete it by hand if it is not needed", b, remote, defaultBranch),
+			})
+			continue
+		}
+		actions = append(actions, fixAction{
+			kind:        "orphaned-branch",
+			subject:     b,
+			description: fmt.Sprintf("delete orphaned branch %s from %s", b, remote),
+			fix:         func() error { return sourceRepo.DeleteRemoteBranch(b) },
+		})
+	}
+
+	return actions, nil
+}
+
+// scanExpiredTasks finds pending and review tasks whose expires: front
+// matter date has passed. The fix abandons them, keeping their work
+// directories and branches; hydra workdirs prune reclaims the former.
+func (r *Runner) scanExpiredTasks(now time.Time) ([]fixAction, error) {
+	stale, err := r.Design.StaleTasks(0, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []fixAction
+	for _, s := range stale {
+		if !s.Expired {
+			continue
+		}
+		task := s.Task // capture
+		label := taskLabel(task)
+		actions = append(actions, fixAction{
+			kind:        "expired-task",
+			subject:     label,
+			description: fmt.Sprintf("abandon %s task %q, which expired %s", task.State, label, s.Expires.Format(time.DateOnly)),
+			fix:         func() error { return r.abandonTask(&task, AbandonOpts{Keep: true}) },
+		})
+	}
+	return actions, nil
+}
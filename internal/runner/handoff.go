@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+)
+
+// Front matter keys for review assignments.
+const (
+	reviewerKey   = "reviewer"
+	assignedAtKey = "assigned_at"
+)
+
+// ReviewAssign records reviewer as the person reviewing a task in review
+// state, in the task file's front matter.
+func (r *Runner) ReviewAssign(taskName, reviewer string) error {
+	if strings.TrimSpace(reviewer) == "" {
+		return errors.New("reviewer name is empty")
+	}
+
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+	if err != nil {
+		return err
+	}
+
+	if err := task.SetFrontmatter(map[string]string{
+		reviewerKey:   reviewer,
+		assignedAtKey: time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		return fmt.Errorf("assigning reviewer: %w", err)
+	}
+	r.Design.Changed([]string{task.FilePath}, "assign %s to %s", taskName, reviewer)
+
+	fmt.Printf("Assigned %s to %s\n", taskName, reviewer)
+	return nil
+}
+
+// Reviewer returns the reviewer assigned to a task, or "" if there is none.
+func Reviewer(task *design.Task) string {
+	meta, err := task.Frontmatter()
+	if err != nil {
+		return ""
+	}
+	return meta[reviewerKey]
+}
+
+// ReviewHandoff writes a markdown file bundling everything a teammate needs
+// to review a task: the task document, the branch diff, and any saved
+// session transcripts. If outPath is empty, the file is written to the
+// current directory as <task>-handoff.md. It returns the path written.
+func (r *Runner) ReviewHandoff(taskName, outPath string) (string, error) {
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := task.Content()
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := r.branchDiff(task)
+	if err != nil {
+		return "", err
+	}
+
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	transcripts, err := taskTranscripts(config.HydraPath(baseDir), taskName)
+	if err != nil {
+		return "", err
+	}
+
+	doc := handoffDocument(taskName, task.BranchName(), Reviewer(task), content, diff, transcripts)
+
+	if outPath == "" {
+		outPath = strings.ReplaceAll(taskName, "/", "--") + "-handoff.md"
+	}
+	if err := os.WriteFile(outPath, []byte(doc), 0o600); err != nil {
+		return "", fmt.Errorf("writing handoff: %w", err)
+	}
+	return outPath, nil
+}
+
+// savedTranscript is a session transcript read from the transcripts directory.
+type savedTranscript struct {
+	name    string // file name, e.g. run-20260301-140509.log
+	content string
+}
+
+// taskTranscripts returns the saved transcripts of a task, oldest first.
+func taskTranscripts(hydraDir, taskName string) ([]savedTranscript, error) {
+	dir := transcriptDir(hydraDir, taskName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading transcripts: %w", err)
+	}
+
+	// File names start with the action, so order by the timestamp that ends them.
+	const stampLen = len("20060102-150405.log")
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") && len(e.Name()) > stampLen {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i][len(names[i])-stampLen:] < names[j][len(names[j])-stampLen:]
+	})
+
+	transcripts := make([]savedTranscript, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name)) //nolint:gosec // path built from hydra's transcript dir
+		if err != nil {
+			return nil, fmt.Errorf("reading transcript %s: %w", name, err)
+		}
+		transcripts = append(transcripts, savedTranscript{name: name, content: string(data)})
+	}
+	return transcripts, nil
+}
+
+// handoffDocument assembles the handoff markdown.
+func handoffDocument(taskName, branch, reviewer, content, diff string, transcripts []savedTranscript) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Review handoff: %s\n\n", taskName)
+	fmt.Fprintf(&b, "- Branch: `%s`\n", branch)
+	if reviewer != "" {
+		fmt.Fprintf(&b, "- Reviewer: %s\n", reviewer)
+	}
+	fmt.Fprintf(&b, "- Generated: %s\n", time.Now().Format(time.RFC3339))
+
+	b.WriteString("\n## Task\n\n")
+	b.WriteString(strings.TrimSpace(content) + "\n")
+
+	b.WriteString("\n## Diff\n\n")
+	if diff == "" {
+		b.WriteString("No changes.\n")
+	} else {
+		b.WriteString(fence("diff", diff))
+	}
+
+	b.WriteString("\n## Transcripts\n\n")
+	if len(transcripts) == 0 {
+		b.WriteString("No session transcripts were saved for this task.\n")
+	}
+	for _, t := range transcripts {
+		fmt.Fprintf(&b, "### %s\n\n", strings.TrimSuffix(t.name, ".log"))
+		b.WriteString(fence("text", t.content))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// fence wraps s in a fenced code block long enough not to be closed by any
+// backtick run inside s.
+func fence(lang, s string) string {
+	ticks := "```"
+	for strings.Contains(s, ticks) {
+		ticks += "`"
+	}
+	return ticks + lang + "\n" + strings.TrimRight(s, "\n") + "\n" + ticks + "\n"
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestReviewAssign(t *testing.T) {
+	r := stubRunner(t)
+
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "add-cache.md"), "Add a cache.")
+
+	if err := r.ReviewAssign("add-cache", "alice"); err != nil {
+		t.Fatalf("ReviewAssign: %v", err)
+	}
+
+	task, err := r.Design.FindTaskByState("add-cache", design.StateReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Reviewer(task); got != "alice" {
+		t.Errorf("Reviewer = %q, want alice", got)
+	}
+	content, err := task.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "Add a cache." {
+		t.Errorf("task content changed: %q", content)
+	}
+
+	if err := r.ReviewAssign("add-cache", " "); err == nil {
+		t.Error("expected error for empty reviewer")
+	}
+}
+
+func TestTaskTranscriptsOrderedByTime(t *testing.T) {
+	hydraDir := t.TempDir()
+	dir := transcriptDir(hydraDir, "grp/task")
+	mkdirAll(t, dir)
+	writeFile(t, filepath.Join(dir, "run-20260301-100000.log"), "first")
+	writeFile(t, filepath.Join(dir, "review-20260302-090000.log"), "third")
+	writeFile(t, filepath.Join(dir, "test-20260301-120000.log"), "second")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	transcripts, err := taskTranscripts(hydraDir, "grp/task")
+	if err != nil {
+		t.Fatalf("taskTranscripts: %v", err)
+	}
+	var got []string
+	for _, tr := range transcripts {
+		got = append(got, tr.content)
+	}
+	if strings.Join(got, ",") != "first,second,third" {
+		t.Errorf("transcripts = %v, want first,second,third", got)
+	}
+
+	none, err := taskTranscripts(hydraDir, "other")
+	if err != nil || len(none) != 0 {
+		t.Errorf("taskTranscripts for task without transcripts = %v, %v", none, err)
+	}
+}
+
+func TestHandoffDocument(t *testing.T) {
+	doc := handoffDocument("add-cache", "hydra/add-cache", "alice", "Add a cache.\n",
+		"diff --git a/x b/x\n", []savedTranscript{{name: "run-20260301-100000.log", content: "User: go\n```\ncode\n```\n"}})
+
+	for _, want := range []string{
+		"# Review handoff: add-cache",
+		"- Branch: `hydra/add-cache`",
+		"- Reviewer: alice",
+		"## Task\n\nAdd a cache.\n",
+		"## Diff\n\n```diff\ndiff --git a/x b/x\n```\n",
+		"### run-20260301-100000\n\n````text\n",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("handoff missing %q:\n%s", want, doc)
+		}
+	}
+
+	empty := handoffDocument("t", "hydra/t", "", "Body", "", nil)
+	if strings.Contains(empty, "Reviewer:") {
+		t.Error("handoff without reviewer should not list one")
+	}
+	if !strings.Contains(empty, "No changes.") || !strings.Contains(empty, "No session transcripts") {
+		t.Errorf("handoff should note missing diff and transcripts:\n%s", empty)
+	}
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// startHeartbeat sends a notification of the batch's progress to the
+// default channels every notifications.heartbeat from hydra.yml, so an
+// unattended batch can be seen to be alive without checking its terminal.
+// The first goes out one interval in, so batches shorter than that send
+// none. It returns a function that stops the heartbeat.
+func (r *Runner) startHeartbeat(ctx context.Context, batch string, progress *groupProgress) func() {
+	n := r.notifications()
+	if n == nil || n.Heartbeat == nil || n.Heartbeat.Duration <= 0 {
+		return func() {}
+	}
+
+	// Tasks may load their group's hydra.yml as the batch goes; the
+	// heartbeat keeps sending with its own copy of the settings the batch
+	// started with.
+	cmds := r.commands()
+	title := "hydra: " + batch
+	if r.Config != nil {
+		title = r.notifyTitle(batch)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(n.Heartbeat.Duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, res := range sendNotification(ctx, cmds, nil, title, progress.summary()) {
+					// Warnings would write over a TUI session.
+					if res.Err != nil && !r.output.attached() {
+						fmt.Fprintf(os.Stderr, "Warning: heartbeat notification to %s failed: %v\n", res.Channel, res.Err)
+					}
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestGroupProgressSummary(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+	p := newGroupProgress(&bytes.Buffer{}, 3, 0)
+	p.start = start
+	p.now = func() time.Time { return clock }
+
+	p.begin("g/a")
+	clock = start.Add(10 * time.Minute)
+	p.finish("g/a")
+	p.begin("g/b")
+	clock = start.Add(15 * time.Minute)
+
+	want := "1 of 3 tasks done, 2 remaining. Working on g/b. Elapsed 15m0s, ETA ~30m0s."
+	if got := p.summary(); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var mu sync.Mutex
+	var messages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		var body struct{ Title, Message string }
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		mu.Lock()
+		messages = append(messages, body.Title+": "+body.Message)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	r := &Runner{TaskRunner: &taskrun.Commands{Notifications: &taskrun.Notifications{
+		Webhook:   srv.URL,
+		Channels:  []string{"webhook"},
+		Heartbeat: &taskrun.Duration{Duration: 10 * time.Millisecond},
+	}}}
+	p := newGroupProgress(&bytes.Buffer{}, 2, 0)
+	p.begin("g/a")
+
+	stop := r.startHeartbeat(context.Background(), "group run g", p)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(messages)
+		mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) == 0 {
+		t.Fatal("no heartbeat sent")
+	}
+	if !strings.HasPrefix(messages[0], "hydra: group run g: 0 of 2 tasks done, 2 remaining. Working on g/a.") {
+		t.Errorf("heartbeat = %q", messages[0])
+	}
+}
+
+func TestHeartbeatDisabled(t *testing.T) {
+	r := &Runner{TaskRunner: &taskrun.Commands{Notifications: &taskrun.Notifications{}}}
+	r.startHeartbeat(context.Background(), "group run g", newGroupProgress(&bytes.Buffer{}, 1, 0))()
+	(&Runner{}).startHeartbeat(context.Background(), "group run g", newGroupProgress(&bytes.Buffer{}, 1, 0))()
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// hookEvent describes the task a hook command runs for. It reaches the
+// command as HYDRA_* environment variables.
+type hookEvent struct {
+	Action string // run, review, test, or merge
+	Task   string
+	State  design.TaskState
+	Branch string
+	SHA    string
+	Err    error // only for on_failure
+}
+
+// env returns the event as environment variable assignments.
+func (e hookEvent) env() []string {
+	env := []string{
+		"HYDRA_ACTION=" + e.Action,
+		"HYDRA_TASK=" + e.Task,
+		"HYDRA_STATE=" + string(e.State),
+		"HYDRA_BRANCH=" + e.Branch,
+		"HYDRA_SHA=" + e.SHA,
+	}
+	if e.Err != nil {
+		env = append(env, "HYDRA_ERROR="+e.Err.Error())
+	}
+	return env
+}
+
+// runHook runs the named hook command from hydra.yml, if configured, in
+// workDir. The step it follows has already happened, so a failing hook is
+// only a warning.
+func (r *Runner) runHook(name, workDir string, ev hookEvent) {
+	if r.TaskRunner == nil {
+		return
+	}
+	if _, err := r.commands().RunHook(name, workDir, ev.env()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// runFailureHook runs the on_failure hook for a failed action on a task,
+// with the task's current state and, when its work directory has one, the
+// work directory's HEAD.
+func (r *Runner) runFailureHook(action, taskName string, err error) {
+	if r.TaskRunner == nil || r.TaskRunner.Commands[taskrun.HookOnFailure] == "" {
+		return
+	}
+	task, findErr := r.Design.FindTaskAny(taskName)
+	if findErr != nil {
+		r.runHook(taskrun.HookOnFailure, "", hookEvent{Action: action, Task: taskName, Err: err})
+		return
+	}
+
+	wd := r.workDir(task)
+	ev := hookEvent{Action: action, Task: taskName, State: task.State, Branch: task.BranchName(), Err: err}
+	if _, statErr := os.Stat(wd); statErr == nil {
+		if sha, shaErr := repo.Open(wd).LastCommitSHA(); shaErr == nil {
+			ev.SHA = sha
+		}
+	}
+	r.runHook(taskrun.HookOnFailure, wd, ev)
+}
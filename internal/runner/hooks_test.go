@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// hookEnvCommand writes the HYDRA_* variables a hook sees to out.
+func hookEnvCommand(out string) string {
+	return "env | grep ^HYDRA_ | sort > " + out
+}
+
+func readHookEnv(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:gosec // test
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	return string(data)
+}
+
+func TestRunHook(t *testing.T) {
+	r := stubRunner(t)
+	out := filepath.Join(t.TempDir(), "hook.env")
+	r.TaskRunner = &taskrun.Commands{Commands: map[string]string{taskrun.HookAfterRun: hookEnvCommand(out)}}
+
+	r.runHook(taskrun.HookAfterRun, t.TempDir(), hookEvent{
+		Action: "run", Task: "backend/add-api", State: design.StateReview, Branch: "hydra/backend/add-api", SHA: "abc123",
+	})
+
+	want := "HYDRA_ACTION=run\nHYDRA_BRANCH=hydra/backend/add-api\nHYDRA_SHA=abc123\nHYDRA_STATE=review\nHYDRA_TASK=backend/add-api\n"
+	if got := readHookEnv(t, out); got != want {
+		t.Errorf("hook environment = %q, want %q", got, want)
+	}
+}
+
+func TestRunHookFailureIsWarning(t *testing.T) {
+	r := stubRunner(t)
+	r.TaskRunner = &taskrun.Commands{Commands: map[string]string{taskrun.HookAfterMerge: "false"}}
+
+	// Must not panic or abort; the failure is only reported.
+	r.runHook(taskrun.HookAfterMerge, t.TempDir(), hookEvent{Action: "merge"})
+
+	r.TaskRunner = nil
+	r.runHook(taskrun.HookAfterMerge, t.TempDir(), hookEvent{Action: "merge"})
+}
+
+func TestOnFailureHook(t *testing.T) {
+	r := stubRunner(t)
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add the feature.")
+	out := filepath.Join(t.TempDir(), "hook.env")
+	r.TaskRunner = &taskrun.Commands{Commands: map[string]string{taskrun.HookOnFailure: hookEnvCommand(out)}}
+	r.BaseDir = t.TempDir()
+
+	r.recordFailure("run", "add-feature", errors.New("claude crashed"))
+
+	got := readHookEnv(t, out)
+	for _, want := range []string{"HYDRA_ACTION=run\n", "HYDRA_TASK=add-feature\n", "HYDRA_STATE=pending\n", "HYDRA_BRANCH=hydra/add-feature\n", "HYDRA_ERROR=claude crashed\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hook environment missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/claude"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// stateTopics are the topics of the hydra_state tool, in the order an
+// answer for all of them lists them.
+var stateTopics = []string{"task", "group", "history", "functional"}
+
+// TaskState answers Claude's read-only hydra_state tool for the task named
+// taskName: its metadata, the other tasks in its group, its record.json
+// history, and the functional.md sections that mention files, or the files
+// its branch changed when files is empty. topic limits the answer to one
+// of those; "" answers all of them.
+func (r *Runner) TaskState(taskName, topic string, files []string) (string, error) {
+	task, err := r.Design.FindTaskAny(taskName)
+	if err != nil {
+		return "", err
+	}
+	if err := r.useGroupConfig(task); err != nil {
+		return "", err
+	}
+
+	topics := stateTopics
+	if topic != "" {
+		if !slices.Contains(stateTopics, topic) {
+			return "", fmt.Errorf("unknown topic %q; use one of %s", topic, strings.Join(stateTopics, ", "))
+		}
+		topics = []string{topic}
+	}
+
+	var b strings.Builder
+	for _, t := range topics {
+		var section string
+		switch t {
+		case "task":
+			section = r.stateTask(task)
+		case "group":
+			section, err = r.stateGroup(task)
+		case "history":
+			section, err = r.stateHistory(task)
+		case "functional":
+			section, err = r.stateFunctional(task, files)
+		}
+		if err != nil {
+			return "", err
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(section)
+	}
+	return b.String(), nil
+}
+
+// stateTask describes the task itself.
+func (r *Runner) stateTask(task *design.Task) string {
+	var b strings.Builder
+	b.WriteString("## Task\n\n")
+	fmt.Fprintf(&b, "Name: %s\n", taskLabel(*task))
+	fmt.Fprintf(&b, "State: %s\n", task.State)
+	fmt.Fprintf(&b, "Branch: %s\n", task.BranchName())
+	if wd := r.workDir(task); repo.IsGitRepo(wd) {
+		fmt.Fprintf(&b, "Work directory: %s\n", wd)
+	}
+	if r.baseBranch != "" {
+		fmt.Fprintf(&b, "Base branch: %s\n", r.baseBranch)
+	}
+	if n := issues.IssueNumber(task); n > 0 {
+		fmt.Fprintf(&b, "Issue: #%d\n", n)
+	}
+	if date, ok := strings.CutPrefix(task.Group, design.MilestoneTaskGroup("")); ok {
+		fmt.Fprintf(&b, "Milestone: %s\n", date)
+	}
+	if reviewer := Reviewer(task); reviewer != "" {
+		fmt.Fprintf(&b, "Reviewer: %s\n", reviewer)
+	}
+	return b.String()
+}
+
+// stateGroup lists the other tasks in the task's group and their states.
+func (r *Runner) stateGroup(task *design.Task) (string, error) {
+	var b strings.Builder
+	b.WriteString("## Group\n\n")
+	if task.Group == "" {
+		b.WriteString("The task is not in a group.\n")
+		return b.String(), nil
+	}
+	tasks, err := r.Design.AllTasks()
+	if err != nil {
+		return "", fmt.Errorf("listing tasks: %w", err)
+	}
+	fmt.Fprintf(&b, "Other tasks in %s:\n\n", task.Group)
+	n := 0
+	for _, t := range tasks {
+		if t.Group != task.Group || t.Name == task.Name {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", taskLabel(t), t.State)
+		n++
+	}
+	if n == 0 {
+		b.WriteString("None.\n")
+	}
+	return b.String(), nil
+}
+
+// stateHistory lists the task's entries in record.json, oldest first.
+func (r *Runner) stateHistory(task *design.Task) (string, error) {
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		return "", err
+	}
+	label := taskLabel(*task)
+
+	var b strings.Builder
+	b.WriteString("## History\n\n")
+	n := 0
+	for _, e := range entries {
+		action, name, ok := strings.Cut(e.TaskName, ":")
+		if !ok {
+			action, name = "run", e.TaskName
+		}
+		if name != label {
+			continue
+		}
+		b.WriteString("- ")
+		if !e.Time.IsZero() {
+			b.WriteString(e.Time.UTC().Format("2006-01-02 15:04") + " ")
+		}
+		fmt.Fprintf(&b, "%s %s", action, shortSHA(e.SHA))
+		var details []string
+		if e.DurationSeconds > 0 {
+			details = append(details, (time.Duration(e.DurationSeconds) * time.Second).String())
+		}
+		if e.Tokens > 0 {
+			details = append(details, fmt.Sprintf("%d tokens", e.Tokens))
+		}
+		if len(e.Checklist) > 0 {
+			passed := 0
+			for _, c := range e.Checklist {
+				if c.Passed {
+					passed++
+				}
+			}
+			details = append(details, fmt.Sprintf("checklist %d/%d passed", passed, len(e.Checklist)))
+		}
+		if len(details) > 0 {
+			b.WriteString(" (" + strings.Join(details, ", ") + ")")
+		}
+		b.WriteString("\n")
+		n++
+	}
+	if n == 0 {
+		b.WriteString("No recorded sessions yet.\n")
+	}
+	return b.String(), nil
+}
+
+// stateFunctional returns the functional.md sections that mention files, or
+// the files the task's branch changed when files is empty.
+func (r *Runner) stateFunctional(task *design.Task, files []string) (string, error) {
+	var b strings.Builder
+	b.WriteString("## Functional requirements\n\n")
+
+	if len(files) == 0 {
+		files = r.branchFiles(task)
+		if len(files) == 0 {
+			b.WriteString("The task's branch has not changed any files yet; ask again with the files you plan to change.\n")
+			return b.String(), nil
+		}
+	}
+	functional, err := r.Design.Functional()
+	if err != nil {
+		return "", err
+	}
+
+	sections := functionalSections(functional, files)
+	if len(sections) == 0 {
+		fmt.Fprintf(&b, "No section of functional.md mentions %s.\n", strings.Join(files, ", "))
+		return b.String(), nil
+	}
+	fmt.Fprintf(&b, "Sections of functional.md that mention %s:\n\n", strings.Join(files, ", "))
+	b.WriteString(strings.Join(sections, "\n"))
+	return b.String(), nil
+}
+
+// branchFiles returns the files the task's branch changed against the
+// default branch, or nil if it has no work directory yet.
+func (r *Runner) branchFiles(task *design.Task) []string {
+	wd := r.workDir(task)
+	if !repo.IsGitRepo(wd) {
+		return nil
+	}
+	taskRepo := repo.OpenVCS(wd)
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return nil
+	}
+	files, err := taskRepo.ChangedFiles("origin/"+defaultBranch, "HEAD")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: listing changed files: %v\n", err)
+		return nil
+	}
+	return files
+}
+
+// functionalSections returns the sections of content that mention any of
+// files.
+func functionalSections(content string, files []string) []string {
+	terms := fileTerms(files)
+	var sections []string
+	for _, section := range design.MarkdownSections(content) {
+		if mentionsAny(section, terms) {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// fileTerms returns what a requirement may call files by: their paths,
+// base names, and directories.
+func fileTerms(files []string) []string {
+	var terms []string
+	for _, f := range files {
+		f = filepath.ToSlash(f)
+		terms = append(terms, f, path.Base(f))
+		if dir := path.Dir(f); dir != "." {
+			terms = append(terms, dir)
+		}
+	}
+	return terms
+}
+
+// mentionsAny reports whether s contains any of terms.
+func mentionsAny(s string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(s, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// stateTool returns the hydra_state tool for a session on task: answered
+// in this process for the built-in TUI, and by a "hydra state-tool" process
+// over MCP for the Claude Code CLI.
+func (r *Runner) stateTool(task *design.Task) *StateTool {
+	label := taskLabel(*task)
+	tool := &StateTool{
+		Answer: func(topic string, files []string) (string, error) {
+			return r.TaskState(label, topic, files)
+		},
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return tool
+	}
+	base := r.BaseDir
+	if base == "" {
+		base = "."
+	}
+	if base, err = filepath.Abs(base); err != nil {
+		return tool
+	}
+	tool.Server = []string{exe}
+	if r.WorkRoot != "" {
+		tool.Server = append(tool.Server, "--work-dir", r.WorkRoot)
+	}
+	tool.Server = append(tool.Server, "state-tool", "--project", base, label)
+	return tool
+}
+
+// StateTool is Claude's hydra_state tool for a session's task.
+type StateTool struct {
+	Answer claude.StateFunc
+	Server []string // command line of a hydra process serving Answer over MCP; nil if unknown
+}
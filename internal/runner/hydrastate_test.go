@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestTaskState(t *testing.T) {
+	r := stubRunner(t)
+	r.configGroup = "backend" // group config already loaded
+	mkdirAll(t, filepath.Join(r.Design.Path, "tasks", "backend"))
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review", "backend"))
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "backend", "add-db.md"), "Add a database.")
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "backend", "add-api.md"), "Add an API.")
+	writeFile(t, filepath.Join(r.Design.Path, "functional.md"),
+		"# Requirements\n\n## API\n\nHandlers in internal/api answer in JSON.\n\n## CLI\n\nThe CLI prints tables.\n")
+	if err := r.Design.Record().AddEntry(design.RecordEntry{SHA: "abc123", TaskName: "backend/add-api", Tokens: 1200}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Design.Record().AddEntry(design.RecordEntry{SHA: "def456", TaskName: "review:backend/add-api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.TaskState("add-api", "", []string{"internal/api/server.go"})
+	if err != nil {
+		t.Fatalf("TaskState: %v", err)
+	}
+	for _, want := range []string{
+		"Name: backend/add-api",
+		"State: review",
+		"Branch: hydra/backend/add-api",
+		"- backend/add-db (pending)",
+		"run abc123 (1200 tokens)",
+		"review def456",
+		"Handlers in internal/api",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("TaskState missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "prints tables") {
+		t.Errorf("TaskState included an unrelated functional.md section:\n%s", got)
+	}
+
+	got, err = r.TaskState("add-api", "group", nil)
+	if err != nil {
+		t.Fatalf("TaskState(group): %v", err)
+	}
+	if strings.Contains(got, "## Task") || !strings.Contains(got, "## Group") {
+		t.Errorf("TaskState(group) = %q", got)
+	}
+
+	if _, err := r.TaskState("add-api", "weather", nil); err == nil {
+		t.Error("expected error for an unknown topic")
+	}
+}
+
+func TestFunctionalSections(t *testing.T) {
+	content := "Intro.\n\n## Storage\n\nSee store.go.\n\n## Web\n\nAll of web/ is static.\n\n## Other\n\nNothing.\n"
+	got := functionalSections(content, []string{"pkg/store.go", "web/index.html"})
+	if len(got) != 2 || !strings.HasPrefix(got[0], "## Storage") || !strings.HasPrefix(got[1], "## Web") {
+		t.Errorf("functionalSections = %q", got)
+	}
+	if got := functionalSections(content, []string{"main.go"}); len(got) != 0 {
+		t.Errorf("functionalSections(main.go) = %q, want none", got)
+	}
+}
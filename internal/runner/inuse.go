@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+// inUsePoll is how often a workflow waiting for a work directory checks
+// whether hydra review dev still serves it.
+var inUsePoll = 2 * time.Second
+
+// inUseName returns the name of the shared lock hydra review dev holds on a
+// task's work directory.
+func inUseName(task *design.Task) string {
+	return "dev:" + taskLabel(*task)
+}
+
+// hydraDir returns the .hydra directory locks are kept in.
+func (r *Runner) hydraDir() string {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	return config.HydraPath(baseDir)
+}
+
+// workflowLocks returns the names of the exclusive locks hydra's workflows
+// take on a task before they check out, reset, or remove its work directory.
+func workflowLocks(label string) []string {
+	return []string{label, "review:" + label, "merge:" + label, "test:" + label}
+}
+
+// taskLocks returns the names of every exclusive lock hydra takes on a
+// task: those of workflowLocks, and split's, which reads the task but not
+// its work directory.
+func taskLocks(label string) []string {
+	return append(workflowLocks(label), "split:"+label)
+}
+
+// holdWorkDir marks the task's work directory as in use until the returned
+// function is called, so other workflows leave its checkout alone. It fails
+// if a workflow already holds one of the task's locks. The mark is made
+// before checking, as workflows take their lock before checkWorkDirFree, so
+// of a dev session and a workflow starting together at least one sees the
+// other.
+func (r *Runner) holdWorkDir(task *design.Task) (func(), error) {
+	hydraDir := r.hydraDir()
+	shared, err := lock.AcquireShared(hydraDir, inUseName(task))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range workflowLocks(taskLabel(*task)) {
+		if lock.New(hydraDir, name).IsHeld() {
+			_ = shared.Release()
+			return nil, fmt.Errorf("task %q has a hydra workflow in progress (%s); wait for it to finish", taskLabel(*task), name)
+		}
+	}
+	return func() { _ = shared.Release() }, nil
+}
+
+// claimWorkDir takes the task's lock for a command that removes its work
+// directory outside a run, review, or merge, such as abandon and requeue.
+// It fails if another workflow holds one of the task's locks or hydra
+// review dev is serving the directory. The returned function releases the
+// lock.
+func (r *Runner) claimWorkDir(task *design.Task) (func(), error) {
+	hydraDir := r.hydraDir()
+	label := taskLabel(*task)
+	lk := lock.New(hydraDir, label)
+	if err := lk.Acquire(); err != nil {
+		return nil, err
+	}
+	release := func() { _ = lk.Release() }
+	for _, name := range workflowLocks(label)[1:] {
+		if lock.New(hydraDir, name).IsHeld() {
+			release()
+			return nil, fmt.Errorf("task %q has a hydra workflow in progress (%s); wait for it to finish", label, name)
+		}
+	}
+	if err := r.checkWorkDirFree(task); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// checkWorkDirFree returns an error if hydra review dev is serving the
+// task's work directory, since checking out, resetting, or removing it
+// would pull the files out from under the running app. With WaitInUse set
+// it waits for the dev session to stop instead.
+func (r *Runner) checkWorkDirFree(task *design.Task) error {
+	hydraDir := r.hydraDir()
+	name := inUseName(task)
+	waiting := false
+	for {
+		holders, err := lock.SharedHolders(hydraDir, name)
+		if err != nil {
+			return err
+		}
+		if len(holders) == 0 {
+			return nil
+		}
+		if !r.WaitInUse {
+			return fmt.Errorf("work directory of %q is in use by hydra review dev (PID %d); stop it first or pass --wait-in-use", taskLabel(*task), holders[0].PID)
+		}
+		if !waiting {
+			fmt.Printf("Waiting for hydra review dev (PID %d) to stop using the work directory of %q...\n", holders[0].PID, taskLabel(*task))
+			waiting = true
+		}
+		time.Sleep(inUsePoll)
+	}
+}
+
+// workDirBusy reports whether the task a directory under the work root
+// belongs to is busy, by the name its path gives, so it also covers
+// directories whose task has gone.
+func (r *Runner) workDirBusy(wd string) bool {
+	rel, err := filepath.Rel(r.workRoot(), wd)
+	if err != nil {
+		return false
+	}
+	return taskBusy(r.hydraDir(), filepath.ToSlash(rel))
+}
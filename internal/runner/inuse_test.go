@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+func TestCheckWorkDirFree(t *testing.T) {
+	inUsePoll = 10 * time.Millisecond
+	t.Cleanup(func() { inUsePoll = 2 * time.Second })
+
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	if err := os.MkdirAll(config.HydraPath(r.BaseDir), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add it.\n")
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.checkWorkDirFree(task); err != nil {
+		t.Fatalf("checkWorkDirFree with no dev session: %v", err)
+	}
+
+	release, err := r.holdWorkDir(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.checkWorkDirFree(task)
+	if err == nil || !strings.Contains(err.Error(), "in use by hydra review dev") {
+		t.Fatalf("checkWorkDirFree during dev session = %v, want an in-use error", err)
+	}
+	if !taskBusy(config.HydraPath(r.BaseDir), "add-feature") {
+		t.Error("taskBusy = false during dev session")
+	}
+
+	r.WaitInUse = true
+	done := make(chan error, 1)
+	go func() { done <- r.checkWorkDirFree(task) }()
+	select {
+	case err := <-done:
+		t.Fatalf("checkWorkDirFree returned %v while the dev session was running", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("checkWorkDirFree after dev session stopped: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("checkWorkDirFree kept waiting after the dev session stopped")
+	}
+}
+
+func TestHoldWorkDirDuringWorkflow(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	hydraDir := config.HydraPath(r.BaseDir)
+	if err := os.MkdirAll(hydraDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add it.\n")
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lk := lock.New(hydraDir, "merge:add-feature")
+	if err := lk.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.holdWorkDir(task); err == nil || !strings.Contains(err.Error(), "workflow in progress") {
+		t.Fatalf("holdWorkDir during a merge = %v, want an in-progress error", err)
+	}
+	if holders, _ := lock.SharedHolders(hydraDir, inUseName(task)); len(holders) != 0 {
+		t.Error("a refused dev session should not keep the work directory marked in use")
+	}
+	if _, err := r.claimWorkDir(task); err == nil {
+		t.Error("claimWorkDir during a merge should fail")
+	}
+	_ = lk.Release()
+
+	release, err := r.claimWorkDir(task)
+	if err != nil {
+		t.Fatalf("claimWorkDir: %v", err)
+	}
+	if _, err := r.holdWorkDir(task); err == nil {
+		t.Error("holdWorkDir while the work directory is claimed should fail")
+	}
+	release()
+
+	if r.workDirBusy(r.workDir(task)) {
+		t.Error("workDirBusy = true with nothing running")
+	}
+	hold, err := r.holdWorkDir(task)
+	if err != nil {
+		t.Fatalf("holdWorkDir: %v", err)
+	}
+	defer hold()
+	if !r.workDirBusy(r.workDir(task)) {
+		t.Error("workDirBusy = false during dev session")
+	}
+}
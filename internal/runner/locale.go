@@ -0,0 +1,295 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// Names of the boilerplate sections of workflow documents that language: in
+// hydra.yml and the templates directory can replace. They are also the file
+// names of their templates, with a .md extension.
+const (
+	sectionVerification = "verification"
+	sectionCommit       = "commit"
+	sectionConflicts    = "conflicts"
+	sectionSync         = "sync"
+	sectionReminder     = "reminder"
+	sectionTimeout      = "timeout"
+	sectionNotification = "notification"
+	sectionPlanMode     = "plan_mode"
+	sectionReview       = "review"
+)
+
+// sectionData is what section templates are rendered with. Fields that
+// don't apply to a section are left empty.
+type sectionData struct {
+	Test            string   // test command from hydra.yml, or ""
+	Lint            string   // lint command from hydra.yml, or ""
+	Serial          bool     // run test and lint one at a time (serial_checks)
+	Sign            bool     // commits must be signed
+	FocusPattern    string   // test pattern of a focused test session (hydra test --only)
+	FocusCmd        string   // command running only FocusPattern's tests, if known
+	CommitTemplate  string   // rendered commit_template.md, or ""
+	Upstream        string   // origin branch to rebase onto, e.g. origin/main
+	Conflicts       []string // files with rebase conflicts
+	ConflictDetails string   // hunks and upstream commits of the conflicts, as markdown
+	Timeout         string   // session time limit, e.g. 30m0s
+	NotifyTitle     string   // title of the session's hydra notify calls
+	PersonaName     string   // reviewer persona of a review session, or ""
+	PersonaFocus    string   // what the persona looks for, or ""
+}
+
+// locale renders the boilerplate sections of workflow documents in the
+// language set by language: in hydra.yml. A section comes from the first
+// of the templates directory's <language>/<section>.md, its <section>.md,
+// and hydra's built-in translation, and is hydra's English text if there is
+// none of them.
+type locale struct {
+	language string // "" for English
+	dir      string // templates directory; need not exist
+}
+
+// locale returns the locale of the workflow documents, or nil if hydra.yml
+// sets no language and the design directory has no templates directory. It
+// is worked out once for each hydra.yml loaded, so a missing language is
+// only warned about once.
+func (r *Runner) locale() *locale {
+	if r.localeSet && r.localeFor == r.TaskRunner {
+		return r.cachedLocale
+	}
+	r.cachedLocale = r.newLocale()
+	r.localeFor, r.localeSet = r.TaskRunner, true
+	return r.cachedLocale
+}
+
+// newLocale implements locale.
+func (r *Runner) newLocale() *locale {
+	var language, dir string
+	if r.TaskRunner != nil {
+		language = strings.ToLower(r.TaskRunner.Language)
+		dir = r.TaskRunner.Templates
+	}
+	if language == "en" {
+		language = ""
+	}
+	if dir == "" {
+		dir = "templates"
+	}
+	if r.Design != nil && !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.Design.Path, dir)
+	}
+
+	if language == "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil
+		}
+	} else if _, ok := builtinSections[language]; !ok {
+		if _, err := os.Stat(filepath.Join(dir, language)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: hydra has no built-in %q text and %s has no templates for it; using English\n", language, dir)
+		}
+	}
+	return &locale{language: language, dir: dir}
+}
+
+// section returns the named section rendered with data, or english if the
+// locale has no template for it. A section that doesn't apply, which
+// english signals by being empty, stays empty.
+func (l *locale) section(name string, data sectionData, english string) string {
+	if l == nil || english == "" {
+		return english
+	}
+
+	text, source := l.template(name)
+	if text == "" {
+		return english
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v; using English\n", source, err)
+		return english
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %v; using English\n", source, err)
+		return english
+	}
+	return "\n\n" + strings.TrimSpace(b.String()) + "\n"
+}
+
+// template returns the template of the named section and where it came
+// from, or "" if there is none.
+func (l *locale) template(name string) (string, string) {
+	var paths []string
+	if l.language != "" {
+		paths = append(paths, filepath.Join(l.dir, l.language, name+".md"))
+	}
+	paths = append(paths, filepath.Join(l.dir, name+".md"))
+	for _, p := range paths {
+		if data, err := os.ReadFile(p); err == nil { //nolint:gosec // templates inside the design dir
+			return string(data), p
+		}
+	}
+	if text, ok := builtinSections[l.language][name]; ok {
+		return text, "built-in " + l.language + " " + name + " section"
+	}
+	return "", ""
+}
+
+// suffixSectionData returns the data of the sections documentSuffix adds.
+func suffixSectionData(opts suffixOpts) sectionData {
+	return sectionData{
+		Test:           opts.Commands["test"],
+		Lint:           opts.Commands["lint"],
+		Serial:         opts.SerialChecks,
+		Sign:           opts.Sign,
+		FocusPattern:   opts.FocusTest,
+		FocusCmd:       opts.FocusCmd,
+		CommitTemplate: opts.CommitTmpl,
+		Upstream:       upstreamRef(opts.Base),
+		Timeout:        opts.Timeout.String(),
+		NotifyTitle:    opts.NotifyTitle,
+	}
+}
+
+// conflictSection returns conflictResolutionSection in the language of the
+// workflow documents.
+func (r *Runner) conflictSection(conflicts []repo.Conflict) string {
+	base := r.targetBranch()
+	english := conflictResolutionSection(conflicts, base)
+	data := sectionData{Upstream: upstreamRef(base)}
+	var details strings.Builder
+	for _, c := range conflicts {
+		data.Conflicts = append(data.Conflicts, c.File)
+		details.WriteString(conflictDetails(c, data.Upstream))
+	}
+	data.ConflictDetails = details.String()
+	return r.locale().section(sectionConflicts, data, english)
+}
+
+// builtinSections are hydra's translations of the boilerplate sections, by
+// language code.
+var builtinSections = map[string]map[string]string{
+	"de": {
+		sectionVerification: `## Überprüfung
+
+Stelle vor dem Commit sicher, dass alle Prüfungen bestehen. Die folgenden Befehle sind die offiziellen Test- und Lint-Befehle des Projekts aus hydra.yml. Führe keine anderen Befehle zum Testen oder Linten aus. Führe nur genau die unten aufgeführten Befehle aus, behebe alle gemeldeten Probleme und wiederhole sie, bis sie bestehen.
+
+{{if .Test}}- Tests ausführen: ` + "`{{.Test}}`" + `
+{{end}}{{if .Lint}}- Linter ausführen: ` + "`{{.Lint}}`" + `
+{{end}}{{if and .Test .Lint}}{{if .Serial}}
+Führe die Tests und den Linter nacheinander aus, niemals gleichzeitig: Die Suiten dieses Projekts stören sich gegenseitig.
+{{else}}
+Die Tests und der Linter sind unabhängig voneinander. Spare Zeit, indem du sie gleichzeitig ausführst, z. B. als zwei Hintergrundbefehle, und warte auf beide, bevor du weitermachst.
+{{end}}{{end}}
+WICHTIG: Mehrere hydra-Aufgaben können gleichzeitig laufen, jede in ihrem eigenen Arbeitsverzeichnis. Ändere diese Befehle nicht so, dass sie feste Ports, gemeinsame temporäre Dateien oder anderen globalen Zustand verwenden, der mit parallelen Läufen kollidieren würde. Alle Test- und Lint-Vorgänge müssen vollständig auf den aktuellen Arbeitsbaum beschränkt sein.
+`,
+
+		sectionCommit: `# Anweisungen zum Commit
+
+{{if .FocusPattern}}WICHTIG: Dies ist eine fokussierte Testsitzung. Während du iterierst, darfst du nur die Tests, die auf ` + "`{{.FocusPattern}}`" + ` passen,{{if .FocusCmd}} mit ` + "`{{.FocusCmd}}`" + `{{end}} so oft wie nötig ausführen. Abgesehen davon sind die EINZIGEN Test- und Lint-Befehle, die du ausführen darfst, genau die unten aufgeführten Befehle aus hydra.yml, und sie müssen vor dem Commit bestehen.
+{{else}}WICHTIG: Führe KEINE einzelnen Testdateien, Testfunktionen, Lint-Prüfungen oder andere Test- und Lint-Werkzeuge manuell aus. Die EINZIGEN Test- und Lint-Befehle, die du ausführen darfst, sind genau die unten aufgeführten Befehle aus hydra.yml. Rufe Test-Runner, Linter oder Typprüfer auf keine andere Weise auf.
+{{end}}
+Nachdem du alle Codeänderungen vorgenommen hast, folge den Schritten unten.
+
+{{if .Test}}- Führe die Testsuite aus: ` + "`{{.Test}}`" + `
+{{end}}{{if .Lint}}- Führe den Linter aus: ` + "`{{.Lint}}`" + `
+{{end}}- Nimm alle Änderungen auf: ` + "`git add -A`" + `
+- Committe mit einer aussagekräftigen Nachricht. {{if .CommitTemplate}}Die Nachricht muss der Commit-Vorlage des Projekts folgen. Ersetze jeden ` + "`<...>`" + `-Platzhalter durch deinen eigenen Text und lass alles andere unverändert:
+
+` + "```" + `
+{{.CommitTemplate}}
+` + "```" + `
+
+{{end}}{{if .Sign}}Signiere den Commit: ` + "`git commit -S -m \"<aussagekräftige Nachricht>\"`" + `
+{{else}}Commit: ` + "`git commit -m \"<aussagekräftige Nachricht>\"`" + `
+{{end}}
+WICHTIG: Du MUSST deine Änderungen committen, bevor du fertig bist. Die Commit-Nachricht soll beschreiben, was getan wurde, nicht nur den Namen der Aufgabe nennen. Füge der Commit-Nachricht KEINE Co-Authored-By- oder andere Trailer hinzu.
+`,
+
+		sectionConflicts: `## Konfliktlösung
+
+Ein Rebase dieses Branches auf {{.Upstream}} wurde versucht, führte aber zu Konflikten. Der Rebase wurde abgebrochen. Du musst:
+
+1. ` + "`git rebase {{.Upstream}}`" + ` ausführen
+2. Die Konflikte in den unten aufgeführten Dateien lösen
+3. Gelöste Dateien mit ` + "`git add`" + ` vormerken
+4. ` + "`git rebase --continue`" + ` ausführen
+5. Wiederholen, bis der Rebase abgeschlossen ist
+
+### Dateien mit Konflikten
+
+{{range .Conflicts}}- {{.}}
+{{end}}
+{{.ConflictDetails}}`,
+
+		sectionSync: `# Abschließende Synchronisierung
+
+Nachdem du deine Änderungen committet hast, musst du vor dem Push mit origin synchronisieren. Wiederhole die folgenden Schritte, bis keine neuen Änderungen mehr von origin kommen und alle Tests bestehen:
+
+1. origin abrufen: ` + "`git fetch origin`" + `
+2. Rebase auf {{.Upstream}}: ` + "`git rebase {{.Upstream}}`" + `
+3. Wenn der Rebase Konflikte erzeugt, löse sie
+4. {{if .Test}}Führe die Testsuite aus: ` + "`{{.Test}}`" + `{{else}}Führe die Tests aus{{end}}
+5. Behebe alle Fehler und committe die Korrekturen
+6. Gehe zurück zu Schritt 1 und wiederhole, bis ` + "`git fetch`" + ` nichts Neues bringt und alle Tests bestehen
+
+Wenn ` + "`git fetch`" + ` ein erzwungenes Update (forced update) von {{.Upstream}} meldet, führe keinen Rebase aus: Ein einfacher Rebase würde die Commits zurückbringen, die der Force-Push entfernt hat. Hör auf, pushe nicht und weise in deiner abschließenden Nachricht darauf hin, damit der Branch von Hand rebased werden kann.
+
+Sobald alles stabil ist, pushe den Feature-Branch. Erzwinge den Push, falls nötig.
+
+Wo in diesem Dokument von der „Rebase-Schleife" (rebase loop) die Rede ist, ist dieses Verfahren gemeint.
+`,
+
+		sectionReminder: `# Erinnerung
+
+Deine EINZIGE Aufgabe ist die im obigen Dokument beschriebene. Nimm keine fremden Änderungen vor, refaktoriere keinen anderen Code und arbeite an nichts außerhalb des Umfangs der Aufgabe. Bleib bei deinem Auftrag.
+`,
+
+		sectionTimeout: `# Zeitlimit
+
+Du hast {{.Timeout}} Zeit, um diese Aufgabe abzuschließen. Wenn die Zeit knapp wird, committe den bisherigen Fortschritt und hör auf. Ein Teil-Commit, der baut und die Tests besteht, ist besser als gar kein Commit. Hydra sagt dir, wenn die Zeit fast abgelaufen ist, und beendet die Sitzung beim Limit; alles, was bis dahin nicht committet ist, geht verloren.
+`,
+
+		sectionNotification: `# Desktop-Benachrichtigungen
+
+Wann immer du eine Bestätigung, Freigabe oder Eingabe des Benutzers brauchst – etwa wenn du einen Plan zur Prüfung vorlegst, auf einen Fehler stößt, den du nicht beheben kannst, oder an einem Entscheidungspunkt ankommst –, führe folgenden Befehl aus:
+
+` + "```" + `
+hydra notify -t "{{.NotifyTitle}}" "<Beschreibung dessen, was Aufmerksamkeit braucht, oder der zu beantwortenden Frage>"
+` + "```" + `
+
+Gib in der Nachricht genug Kontext an, damit der Benutzer versteht, was nötig ist, ohne das ganze Gespräch lesen zu müssen.
+`,
+
+		sectionPlanMode: `Bitte wechsle sofort in den Plan-Modus.
+`,
+
+		sectionReview: `# Anweisungen zur Prüfung
+
+Du prüfst eine Umsetzung der obigen Aufgabe. Überprüfe, ob die Umsetzung korrekt ist, und nimm bei Bedarf Korrekturen vor. Achte auf:
+
+- Korrektheit der Umsetzung
+- Codequalität und Einhaltung der obigen Regeln
+- Randfälle und Fehlerbehandlung
+
+{{if .PersonaFocus}}## Prüfer-Persona: {{.PersonaName}}
+
+Dies ist ein spezialisierter Prüfdurchgang. Prüfe die Änderung als der unten beschriebene Prüfer und gib seinen Schwerpunkten Vorrang vor allgemeinem Feinschliff.
+
+{{.PersonaFocus}}
+
+{{end}}## Prüfung der Commit-Nachrichten
+
+Lies das Git-Log und überprüfe, ob die Commit-Nachricht(en) die vorgenommenen Änderungen zutreffend beschreiben. Vergleiche sie mit dem obigen Aufgabendokument. Wenn die Commit-Nachrichten vage oder irreführend sind oder die tatsächlichen Änderungen nicht widerspiegeln, ändere den letzten Commit mit einer korrigierten Nachricht.
+
+## Prüfung der Testabdeckung
+
+Lies das obige Aufgabendokument sorgfältig und ermittle jede Funktion, jedes Verhalten und jede Änderung, die es beschreibt. Überprüfe, ob es zu jedem Punkt passende Tests gibt. Fehlen zu einer beschriebenen Funktion oder einem Verhalten Tests, füge sie hinzu. Jede testbare Anforderung im Aufgabendokument muss mindestens einen Test haben.
+`,
+	},
+}
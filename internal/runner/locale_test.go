@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestLocaleBuiltinGerman(t *testing.T) {
+	r := stubRunner(t)
+	r.TaskRunner = &taskrun.Commands{Language: "de"}
+
+	suffix := documentSuffix(suffixOpts{
+		Commands:   map[string]string{"test": "go test ./...", "lint": "golangci-lint run"},
+		Sign:       true,
+		CommitTmpl: "feat: <description>",
+		Locale:     r.locale(),
+	})
+	for _, want := range []string{
+		"## Überprüfung",
+		"- Tests ausführen: `go test ./...`",
+		"gleichzeitig ausführst",
+		"# Anweisungen zum Commit",
+		"```\nfeat: <description>\n```",
+		"`git commit -S -m",
+		"# Abschließende Synchronisierung",
+		"`git rebase origin/main`",
+		"# Erinnerung",
+	} {
+		if !strings.Contains(suffix, want) {
+			t.Errorf("German suffix missing %q:\n%s", want, suffix)
+		}
+	}
+	if strings.Contains(suffix, "Commit Instructions") || strings.Contains(suffix, "{{") {
+		t.Errorf("German suffix has English or unrendered text:\n%s", suffix)
+	}
+
+	r.baseBranch = "release"
+	conflicts := r.conflictSection([]repo.Conflict{{File: "main.go"}})
+	if !strings.Contains(conflicts, "## Konfliktlösung") || !strings.Contains(conflicts, "- main.go\n") ||
+		!strings.Contains(conflicts, "`git rebase origin/release`") {
+		t.Errorf("German conflict section:\n%s", conflicts)
+	}
+	if got := r.conflictSection(nil); got != "" {
+		t.Errorf("conflict section without conflicts = %q, want empty", got)
+	}
+}
+
+func TestLocaleTemplatesOverride(t *testing.T) {
+	r := stubRunner(t)
+	tmplDir := filepath.Join(r.Design.Path, "templates")
+	mkdirAll(t, filepath.Join(tmplDir, "de"))
+	writeFile(t, filepath.Join(tmplDir, "reminder.md"), "# Reminder\n\nKeep it short.\n")
+	writeFile(t, filepath.Join(tmplDir, "de", "verification.md"), "## Prüfen\n\nTests: {{.Test}}\n")
+
+	// Without a language, the templates directory alone changes the tone.
+	suffix := documentSuffix(suffixOpts{Commands: map[string]string{"test": "make test"}, Locale: r.locale()})
+	if !strings.Contains(suffix, "Keep it short.") || strings.Contains(suffix, "Stay focused on the mission") {
+		t.Errorf("reminder template not used:\n%s", suffix)
+	}
+	if !strings.Contains(suffix, "## Verification") {
+		t.Errorf("verification without a template should stay English:\n%s", suffix)
+	}
+
+	// The language's own templates come before the built-in translation.
+	r.TaskRunner = &taskrun.Commands{Language: "de"}
+	suffix = documentSuffix(suffixOpts{Commands: map[string]string{"test": "make test"}, Locale: r.locale()})
+	if !strings.Contains(suffix, "## Prüfen\n\nTests: make test\n") || strings.Contains(suffix, "## Überprüfung") {
+		t.Errorf("de/verification.md not used:\n%s", suffix)
+	}
+	if !strings.Contains(suffix, "# Anweisungen zum Commit") {
+		t.Errorf("built-in German commit section not used:\n%s", suffix)
+	}
+}
+
+func TestLocaleFallsBackToEnglish(t *testing.T) {
+	r := stubRunner(t)
+	if r.locale() != nil {
+		t.Fatal("locale without language or templates should be nil")
+	}
+	english := documentSuffix(suffixOpts{})
+
+	// A broken template is reported and replaced by the English text.
+	mkdirAll(t, filepath.Join(r.Design.Path, "templates"))
+	writeFile(t, filepath.Join(r.Design.Path, "templates", "reminder.md"), "{{.Missing")
+	r.TaskRunner = &taskrun.Commands{} // the locale is worked out again for a newly loaded hydra.yml
+	if r.locale() == nil {
+		t.Fatal("locale with a templates directory should not be nil")
+	}
+	if got := documentSuffix(suffixOpts{Locale: r.locale()}); got != english {
+		t.Errorf("broken template should fall back to English:\n%s", got)
+	}
+
+	// A section that doesn't apply stays out in every language.
+	r.TaskRunner = &taskrun.Commands{Language: "de"}
+	if strings.Contains(documentSuffix(suffixOpts{Locale: r.locale()}), "Überprüfung") {
+		t.Error("verification section without commands should be omitted")
+	}
+}
+
+// TestBuiltinSectionsParity checks that every built-in translation covers
+// the sections of the English text, and keeps the commands, branches,
+// files, and other values the English text passes on to Claude.
+func TestBuiltinSectionsParity(t *testing.T) {
+	cmds := map[string]string{"test": "go test ./...", "lint": "golangci-lint run"}
+	data := sectionData{
+		Test:           cmds["test"],
+		Lint:           cmds["lint"],
+		Sign:           true,
+		FocusPattern:   "TestParse",
+		FocusCmd:       "go test -run TestParse ./...",
+		CommitTemplate: "feat: <description>",
+		Upstream:       "origin/release",
+		Conflicts:      []string{"main.go"},
+		Timeout:        (45 * time.Minute).String(),
+		NotifyTitle:    "hydra: add-api",
+		PersonaName:    "security",
+		PersonaFocus:   "Look for injection.",
+	}
+	english := map[string]string{
+		sectionVerification: verificationSection(cmds, false),
+		sectionCommit:       scopedCommitInstructions(true, cmds, data.FocusPattern, data.FocusCmd, data.CommitTemplate),
+		sectionConflicts:    conflictResolutionSection([]repo.Conflict{{File: "main.go"}}, "release"),
+		sectionSync:         rebaseAndPushSection(cmds, "release"),
+		sectionReminder:     missionReminder(),
+		sectionTimeout:      timeoutSection(45 * time.Minute),
+		sectionNotification: notificationSection(data.NotifyTitle),
+		sectionPlanMode:     planModeInstruction,
+		sectionReview:       reviewInstructions(data.PersonaName, data.PersonaFocus),
+	}
+	values := []string{data.Test, data.Lint, data.FocusPattern, data.FocusCmd, data.CommitTemplate,
+		data.Upstream, "main.go", data.Timeout, data.NotifyTitle, data.PersonaName, data.PersonaFocus}
+
+	for language, sections := range builtinSections {
+		for name := range english {
+			if _, ok := sections[name]; !ok {
+				t.Errorf("%s has no %s section", language, name)
+			}
+		}
+		for name := range sections {
+			if _, ok := english[name]; !ok {
+				t.Errorf("%s has a %s section that English doesn't", language, name)
+			}
+		}
+
+		l := &locale{language: language, dir: t.TempDir()}
+		for name, text := range english {
+			translated := l.section(name, data, text)
+			if translated == text {
+				t.Errorf("%s %s section is the English text", language, name)
+				continue
+			}
+			for _, v := range values {
+				if strings.Contains(text, v) && !strings.Contains(translated, v) {
+					t.Errorf("%s %s section lacks %q:\n%s", language, name, v, translated)
+				}
+			}
+		}
+	}
+}
+
+func TestLocaleCached(t *testing.T) {
+	r := stubRunner(t)
+	r.TaskRunner = &taskrun.Commands{Language: "xx"}
+	l := r.locale()
+	if l == nil || r.locale() != l {
+		t.Error("locale should be worked out once for the loaded hydra.yml")
+	}
+	r.TaskRunner = &taskrun.Commands{Language: "de"}
+	if l := r.locale(); l == nil || l.language != "de" {
+		t.Errorf("locale after loading another hydra.yml = %+v, want de", l)
+	}
+}
@@ -14,17 +14,27 @@ import (
 	"github.com/erikh/hydra/internal/issues"
 	"github.com/erikh/hydra/internal/lock"
 	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/erikh/hydra/internal/tracing"
+	"github.com/mattn/go-isatty"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Merge runs the merge workflow:
 //  1. Fetch origin, checkout task branch, abort any in-progress rebase
 //  2. Rebase task branch onto origin/main
-//  3. If conflicts, invoke Claude to resolve them
-//  4. Force-push the branch
-//  5. Checkout main, rebase against origin/main, rebase against feature branch, push
+//  3. If verify is configured, run lint and tests, trying lint_fix and
+//     flaky test retries before anything else
+//  4. Invoke Claude to resolve conflicts and fix what still fails, unless
+//     verification passed and there is nothing else to do
+//  5. Force-push the branch
+//  6. Checkout main, rebase against origin/main, rebase against feature branch, push
 //
 // Accepts tasks in review or merge state (merge state for retries).
-func (r *Runner) Merge(taskName string) error {
+func (r *Runner) Merge(taskName string) (err error) {
+	ctx, span := tracing.Start(context.Background(), "merge", attribute.String("hydra.task", taskName))
+	defer func() { tracing.End(span, err) }()
+
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -36,6 +46,13 @@ func (r *Runner) Merge(taskName string) error {
 	if err != nil {
 		return err
 	}
+	defer func() { r.recordFailure("merge", taskName, err) }()
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
 
 	// Move to merge state if not already there.
 	if task.State != design.StateMerge {
@@ -53,10 +70,11 @@ func (r *Runner) Merge(taskName string) error {
 
 	// Prepare work directory.
 	wd := r.workDir(task)
-	taskRepo, err := r.prepareRepo(wd)
+	taskRepo, err := r.prepareRepo(wd, task.BranchName())
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
 	}
+	taskRepo = taskRepo.Traced(ctx)
 
 	// Step 1: Checkout the task's branch (skip if working tree is dirty).
 	branch := task.BranchName()
@@ -80,63 +98,155 @@ func (r *Runner) Merge(taskName string) error {
 
 	// Step 4: Rebase task branch onto origin/main; collect conflict info if any.
 	// Skip rebase if the working tree is dirty — let Claude handle it.
-	var conflictFiles []string
+	var conflicts []repo.Conflict
 	dirty, err = taskRepo.HasChanges()
 	if err != nil {
 		return fmt.Errorf("checking working tree: %w", err)
 	}
 	if !dirty {
-		conflictFiles, err = r.attemptRebase(taskRepo)
+		conflicts, err = r.attemptRebase(taskRepo)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Step 5: Assemble document and invoke Claude.
-	content, err := task.Content()
-	if err != nil {
-		return fmt.Errorf("reading task content: %w", err)
-	}
-	cmds := r.commandsMap(wd)
-	sign := taskRepo.HasSigningKey()
-	doc, err := r.assembleMergeDocument(content, conflictFiles, cmds, sign, r.timeout(), r.Notify, r.notifyTitle(taskName))
-	if err != nil {
-		return fmt.Errorf("assembling merge document: %w", err)
-	}
-
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
-	claudeFn := r.Claude
-	if claudeFn == nil {
-		claudeFn = invokeClaude
+	// Step 5: Run lint and tests, trying the deterministic fixes first.
+	sign := taskRepo.HasSigningKey()
+	var preVerified preVerifyResult
+	if !dirty && len(conflicts) == 0 {
+		preVerified, err = r.preVerify(taskRepo, wd, sign)
+		if err != nil {
+			return err
+		}
 	}
-	if err := claudeFn(context.Background(), ClaudeRunConfig{
-		RepoDir:    taskRepo.Dir,
-		Document:   doc,
-		Model:      r.Model,
-		AutoAccept: r.AutoAccept,
-		PlanMode:   r.PlanMode,
-		ForceTUI:   r.ForceTUI,
-	}); err != nil {
-		return fmt.Errorf("claude failed: %w", err)
+
+	// Step 6: Assemble document and invoke Claude, unless verification
+	// passed and there is nothing else for it to do.
+	tmplCheck, err := r.checkCommitTemplate(taskRepo, task)
+	if err != nil {
+		return fmt.Errorf("checking commit template: %w", err)
+	}
+	var results []design.ChecklistResult
+	var tokens int64
+	var finalMessage string
+	if preVerified.Passed() && len(tmplCheck.Mismatched) == 0 {
+		fmt.Println("Lint and tests pass after rebase; skipping the merge session.")
+	} else {
+		content, err := task.Content()
+		if err != nil {
+			return fmt.Errorf("reading task content: %w", err)
+		}
+		cmds := r.commandsMap(wd)
+		doc, err := r.assembleMergeDocument(content, mergeDocOpts{
+			Conflicts:   conflicts,
+			Commands:    cmds,
+			Sign:        sign,
+			Timeout:     r.timeout(),
+			Notify:      r.Notify,
+			NotifyTitle: r.notifyTitle(taskName),
+			CommitTmpl:  tmplCheck,
+		})
+		if err != nil {
+			return fmt.Errorf("assembling merge document: %w", err)
+		}
+		doc += preVerifySection(preVerified)
+
+		claudeFn := r.Claude
+		if claudeFn == nil {
+			claudeFn = invokeClaude
+		}
+		runCfg := ClaudeRunConfig{
+			RepoDir:      taskRepo.WorkDir(),
+			Shell:        r.toolShell(taskRepo.WorkDir()),
+			Output:       &r.output,
+			State:        r.stateTool(task),
+			Document:     doc,
+			Model:        r.Model,
+			Fallbacks:    r.modelFallbacks(),
+			Approval:     r.approvalRules(),
+			AutoAccept:   r.AutoAccept,
+			PlanMode:     r.PlanMode,
+			ForceTUI:     r.ForceTUI,
+			TaskName:     "merge:" + taskName,
+			HydraDir:     hydraDir,
+			Timeout:      r.timeout(),
+			WrapUp:       r.wrapUp(),
+			Tokens:       &tokens,
+			FinalMessage: &finalMessage,
+		}
+		if err := runClaude(ctx, claudeFn, runCfg); sessionTimedOut(err) {
+			fmt.Println("Session time limit reached; checking what has been committed.")
+			if err := r.checkTimedOutMerge(taskRepo, wd, branch); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return inCategory(categoryClaude, fmt.Errorf("claude failed: %w", err))
+		}
+		if err := r.enforceDiffPolicy(ctx, claudeFn, runCfg, taskRepo); err != nil {
+			return err
+		}
+
+		checklist, err := r.Design.ReviewChecklist()
+		if err != nil {
+			return err
+		}
+		results = collectChecklistResults(finalMessage, checklist)
 	}
 
-	// Step 6: Force-push the branch (Claude may have added commits).
+	// Step 7: Force-push the branch (Claude may have added commits).
 	if err := taskRepo.ForcePushWithLease(branch); err != nil {
-		return fmt.Errorf("pushing branch: %w", err)
+		return inCategory(categoryPush, fmt.Errorf("pushing branch: %w", err))
 	}
 
-	// Step 7: Checkout main, rebase against origin/main, then against feature branch, push.
-	defaultBranch, err := r.rebaseAndPush(taskRepo, branch)
+	// Step 8: Checkout main, rebase against origin/main, then against feature branch, push.
+	defaultBranch, baseSHA, err := r.rebaseAndPush(taskRepo, branch)
 	if err != nil {
 		return err
 	}
 
-	// Step 8: Record SHA, complete task, close issue, clean up remote branch.
-	return r.finalizeMerge(task, taskRepo, taskName, branch, defaultBranch)
+	// Step 9: Record SHA, complete task, close issue, clean up remote branch.
+	return r.finalizeMerge(task, taskRepo, taskName, branch, defaultBranch, baseSHA, results, tokens)
+}
+
+// checkTimedOutMerge makes sure a merge session cut off at its time limit
+// left the branch ready to land, as the session would have: everything
+// committed on the task branch, on top of the default branch, and passing
+// test and lint.
+func (r *Runner) checkTimedOutMerge(taskRepo repo.VCS, wd, branch string) error {
+	dirty, err := taskRepo.HasChanges()
+	if err != nil {
+		return fmt.Errorf("checking working tree: %w", err)
+	}
+	if current, err := taskRepo.CurrentBranch(); dirty || err != nil || current != branch {
+		return errors.New("merge session hit its time limit before committing its work; finish it in the work directory and run hydra merge run again")
+	}
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+	if !taskRepo.IsAncestor("origin/"+defaultBranch, "HEAD") {
+		return fmt.Errorf("merge session hit its time limit before the branch was rebased onto origin/%s", defaultBranch)
+	}
+	if r.TaskRunner == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range []string{"test", "lint"} {
+		if r.TaskRunner.HasCommand(name, wd) {
+			names = append(names, name)
+		}
+	}
+	for _, res := range r.commands().WithContext(taskRepo.Context()).OutputAll(names, wd) {
+		if res.Err != nil {
+			return fmt.Errorf("merge session hit its time limit and %s fails: %w", res.Name, res.Err)
+		}
+	}
+	return nil
 }
 
 // findMergeTask locates a task in review or merge state.
@@ -154,12 +264,19 @@ func (r *Runner) findMergeTask(taskName string) (*design.Task, error) {
 
 // attemptRebase fetches origin, detects the default branch, and attempts to
 // rebase onto origin/<default>. If the rebase has conflicts, it aborts the
-// rebase and returns the list of conflicted files. On success, returns an
-// empty list.
-func (r *Runner) attemptRebase(taskRepo *repo.Repo) ([]string, error) {
+// rebase and returns the conflicted files, with their conflict hunks and the
+// upstream commits behind them. On success, returns an empty list.
+func (r *Runner) attemptRebase(taskRepo repo.VCS) (conflicts []repo.Conflict, err error) {
+	ctx, span := tracing.StartChild(taskRepo.Context(), "rebase")
+	defer func() {
+		span.SetAttributes(attribute.Int("hydra.conflict_files", len(conflicts)))
+		tracing.End(span, err)
+	}()
+	taskRepo = taskRepo.Traced(ctx)
+
 	// Always fetch origin before rebasing to ensure we have latest refs.
 	if err := taskRepo.Fetch(); err != nil {
-		return nil, fmt.Errorf("fetching origin before rebase: %w", err)
+		return nil, inCategory(categoryRebase, fmt.Errorf("fetching origin before rebase: %w", err))
 	}
 
 	defaultBranch, err := r.detectDefaultBranch(taskRepo)
@@ -174,19 +291,82 @@ func (r *Runner) attemptRebase(taskRepo *repo.Repo) ([]string, error) {
 	}
 
 	// Try the rebase.
-	if err := taskRepo.Rebase(originRef); err == nil {
+	head, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		return nil, fmt.Errorf("getting HEAD SHA: %w", err)
+	}
+	if err := r.rebaseOnUpstream(taskRepo, originRef); err == nil {
 		return nil, nil
+	} else if errors.Is(err, errRebaseOntoDeclined) {
+		return nil, err
 	}
 
-	// Rebase failed — collect conflict files and abort.
-	conflictFiles, cfErr := taskRepo.ConflictFiles()
+	// Rebase failed — collect the conflicts and abort.
+	conflicts, cfErr := taskRepo.Conflicts(originRef, head)
 	if cfErr != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not list conflict files: %v\n", cfErr)
 	}
 	if err := taskRepo.RebaseAbort(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: rebase abort failed: %v\n", err)
 	}
-	return conflictFiles, nil
+	return conflicts, nil
+}
+
+// errRebaseOntoDeclined is returned when origin's branch was force-pushed
+// and the rebase of only the branch's own commits wasn't approved.
+var errRebaseOntoDeclined = errors.New("rebase onto force-pushed branch not approved")
+
+// rebaseOnUpstream rebases the current branch onto originRef. If origin's
+// branch was force-pushed after the current branch forked from it, a plain
+// rebase would replay the rewritten upstream commits along with the
+// branch's own, bringing back what the force-push removed. In that case
+// hydra rebases only the commits after the old fork point, as git rebase
+// --onto does, but only with --rebase-onto or after asking; without a
+// terminal to ask on, it refuses.
+func (r *Runner) rebaseOnUpstream(taskRepo repo.VCS, originRef string) error {
+	fork, ok := rewrittenForkPoint(taskRepo, originRef)
+	if !ok {
+		return taskRepo.Rebase(originRef)
+	}
+	onto := fmt.Sprintf("git rebase --onto %s %s", originRef, fork[:12])
+	fmt.Fprintf(os.Stderr, "Warning: %s was force-pushed since this branch forked from it; "+
+		"a plain rebase would bring back the commits it dropped\n", originRef)
+	if !r.RebaseOnto {
+		if r.Headless || !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("%w: pass --rebase-onto to rebase only the commits after %s (%s)", errRebaseOntoDeclined, fork[:12], onto)
+		}
+		if !Confirm(fmt.Sprintf("Rebase only the commits after %s (%s)?", fork[:12], onto)) {
+			return errRebaseOntoDeclined
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Rebasing only the commits after %s (%s)\n", fork[:12], onto)
+	return taskRepo.RebaseOnto(originRef, fork)
+}
+
+// rewrittenForkPoint returns where HEAD forked from the history originRef
+// had before it was force-pushed, if HEAD is based on commits the
+// force-push dropped.
+func rewrittenForkPoint(taskRepo repo.VCS, originRef string) (string, bool) {
+	old, ok := taskRepo.RewrittenTip(originRef)
+	if !ok {
+		return "", false
+	}
+	fork, err := taskRepo.MergeBase("HEAD", old)
+	if err != nil || taskRepo.IsAncestor(fork, originRef) {
+		return "", false
+	}
+	return fork, true
+}
+
+// mergeDocOpts holds what a merge document covers besides the task itself.
+type mergeDocOpts struct {
+	Conflicts   []repo.Conflict // conflicts left by the trial rebase, if any
+	Commands    map[string]string
+	Sign        bool
+	Timeout     time.Duration
+	Notify      bool
+	NotifyTitle string
+	CommitTmpl  commitTemplateCheck
 }
 
 // assembleMergeDocument builds a single comprehensive document for the merge
@@ -196,7 +376,7 @@ func (r *Runner) attemptRebase(taskRepo *repo.Repo) ([]string, error) {
 // The calling tool handles all git orchestration (fetch, rebase, checkout, push).
 // Claude's job is limited to: resolving conflicts (if any), validating commits,
 // verifying test coverage, and running tests.
-func (r *Runner) assembleMergeDocument(taskContent string, conflictFiles []string, cmds map[string]string, sign bool, timeout time.Duration, notify bool, notifyTitle string) (string, error) {
+func (r *Runner) assembleMergeDocument(taskContent string, opts mergeDocOpts) (string, error) {
 	rules, err := r.Design.Rules()
 	if err != nil {
 		return "", err
@@ -231,9 +411,9 @@ func (r *Runner) assembleMergeDocument(taskContent string, conflictFiles []strin
 	b.WriteString(taskContent)
 	b.WriteString("\n\n")
 
-	b.WriteString(conflictResolutionSection(conflictFiles))
+	b.WriteString(r.conflictSection(opts.Conflicts))
 
-	if len(conflictFiles) > 0 {
+	if len(opts.Conflicts) > 0 {
 		b.WriteString("### Conflict Resolution Report\n\n")
 		b.WriteString("After all conflicts are resolved and the rebase is complete, " +
 			"print a summary of every conflict resolution decision you made. " +
@@ -246,67 +426,88 @@ func (r *Runner) assembleMergeDocument(taskContent string, conflictFiles []strin
 		"accurately describe the changes made according to the task document above. " +
 		"If any commit message is vague, misleading, or does not reflect the actual changes, " +
 		"amend the most recent commit with a corrected message.\n\n")
+	b.WriteString(commitTemplateSection(opts.CommitTmpl))
 
 	b.WriteString("## Test Coverage\n\n")
 	b.WriteString("Verify that every feature, behavior, or change described in the task document " +
 		"has corresponding test coverage. If any requirement lacks tests, add the missing tests.\n\n")
 
+	checklist, err := r.Design.ReviewChecklist()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(checklistSection(checklist))
+
 	b.WriteString(documentSuffix(suffixOpts{
-		Commands:    cmds,
-		Sign:        sign,
-		Timeout:     timeout,
-		Notify:      notify,
-		NotifyTitle: notifyTitle,
-		SkipSync:    true,
+		Commands:     opts.Commands,
+		SerialChecks: r.serialChecks(),
+		Sign:         opts.Sign,
+		Base:         r.targetBranch(),
+		Timeout:      opts.Timeout,
+		Notify:       opts.Notify,
+		NotifyTitle:  opts.NotifyTitle,
+		SkipSync:     true,
+		CommitTmpl:   opts.CommitTmpl.Template,
+		Locale:       r.locale(),
 	}))
 
 	return b.String(), nil
 }
 
-// rebaseAndPush checks out the default branch, rebases it against origin/main
-// to pick up any upstream changes, then rebases against the feature branch to
-// incorporate the task's commits, and pushes.
-func (r *Runner) rebaseAndPush(taskRepo *repo.Repo, branch string) (string, error) {
-	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+// rebaseAndPush checks out the default branch (or the group's base branch),
+// rebases it against its origin branch to pick up any upstream changes, then
+// rebases against the feature branch to incorporate the task's commits, and
+// pushes. It returns the branch and the SHA the task's commits landed on.
+func (r *Runner) rebaseAndPush(taskRepo repo.VCS, branch string) (defaultBranch, baseSHA string, err error) {
+	ctx, span := tracing.StartChild(taskRepo.Context(), "rebase and push main")
+	defer func() { tracing.End(span, err) }()
+	taskRepo = taskRepo.Traced(ctx)
+
+	defaultBranch, err = r.detectDefaultBranch(taskRepo)
 	if err != nil {
-		return "", fmt.Errorf("detecting default branch: %w", err)
+		return "", "", fmt.Errorf("detecting default branch: %w", err)
 	}
 
 	if err := taskRepo.Checkout(defaultBranch); err != nil {
-		return "", fmt.Errorf("checking out %s: %w", defaultBranch, err)
+		return "", "", fmt.Errorf("checking out %s: %w", defaultBranch, err)
 	}
 
 	// Fetch latest and rebase main against origin/main.
 	if err := taskRepo.Fetch(); err != nil {
-		return "", fmt.Errorf("fetching before rebase: %w", err)
+		return "", "", inCategory(categoryRebase, fmt.Errorf("fetching before rebase: %w", err))
 	}
 
 	originRef := "origin/" + defaultBranch
-	if err := taskRepo.Rebase(originRef); err != nil {
-		return "", fmt.Errorf("rebasing %s against %s: %w", defaultBranch, originRef, err)
+	if err := r.rebaseOnUpstream(taskRepo, originRef); err != nil {
+		return "", "", inCategory(categoryRebase, fmt.Errorf("rebasing %s against %s: %w", defaultBranch, originRef, err))
+	}
+	if baseSHA, err = taskRepo.LastCommitSHA(); err != nil {
+		return "", "", fmt.Errorf("getting %s SHA: %w", defaultBranch, err)
 	}
 
 	// Rebase main against the feature branch to incorporate task commits.
 	if err := taskRepo.Rebase(branch); err != nil {
-		return "", fmt.Errorf("rebasing %s against %s: %w", defaultBranch, branch, err)
+		return "", "", inCategory(categoryRebase, fmt.Errorf("rebasing %s against %s: %w", defaultBranch, branch, err))
 	}
 
 	if err := taskRepo.PushMain(); err != nil {
-		return "", fmt.Errorf("pushing main: %w", err)
+		return "", "", inCategory(categoryPush, fmt.Errorf("pushing main: %w", err))
 	}
 
-	return defaultBranch, nil
+	return defaultBranch, baseSHA, nil
 }
 
-// finalizeMerge records the SHA, moves the task to completed, closes the issue,
-// and deletes the remote feature branch.
-func (r *Runner) finalizeMerge(task *design.Task, taskRepo *repo.Repo, taskName, branch, defaultBranch string) error {
+// finalizeMerge records the SHA, the base it landed on, any checklist
+// results, and the tokens spent by the merge session, moves the task to
+// completed, closes the issue, deletes the remote feature branch, and runs
+// any configured post-merge cleanup.
+func (r *Runner) finalizeMerge(task *design.Task, taskRepo repo.VCS, taskName, branch, defaultBranch, baseSHA string, checklist []design.ChecklistResult, tokens int64) error {
 	sha, err := taskRepo.LastCommitSHA()
 	if err != nil {
 		return fmt.Errorf("getting commit SHA: %w", err)
 	}
-	record := design.NewRecord(r.Config.DesignDir)
-	if err := record.Add(sha, "merge:"+taskName); err != nil {
+	record := r.Design.Record()
+	if err := record.AddEntry(design.RecordEntry{SHA: sha, TaskName: "merge:" + taskLabel(*task), Checklist: checklist, Tokens: tokens, BaseSHA: baseSHA}); err != nil {
 		return fmt.Errorf("recording SHA: %w", err)
 	}
 
@@ -321,26 +522,72 @@ func (r *Runner) finalizeMerge(task *design.Task, taskRepo *repo.Repo, taskName,
 	}
 
 	fmt.Printf("Task %q merged to %s and pushed. SHA: %s\n", taskName, defaultBranch, sha[:12])
+	r.runHook(taskrun.HookAfterMerge, taskRepo.WorkDir(), hookEvent{Action: "merge", Task: taskName, State: design.StateCompleted, Branch: branch, SHA: sha})
+
+	r.cleanAfterMerge(task, taskRepo.WorkDir())
 	return nil
 }
 
-// detectDefaultBranch returns the default branch name (main or master).
-func (r *Runner) detectDefaultBranch(taskRepo interface{ BranchExists(string) bool }) (string, error) {
+// branchRepo is the part of a repository detectDefaultBranch looks at.
+type branchRepo interface {
+	BranchExists(name string) bool
+	OriginHead() (string, bool)
+}
+
+// detectDefaultBranch returns the branch tasks start from and merge into:
+// the base branch of the current task's group, if group.md sets one, or
+// else default_branch from hydra.yml, or else origin's default branch as
+// recorded in origin/HEAD, or else main or master.
+func (r *Runner) detectDefaultBranch(taskRepo branchRepo) (string, error) {
+	if r.baseBranch != "" {
+		if !taskRepo.BranchExists("origin/" + r.baseBranch) {
+			return "", fmt.Errorf("group %s base branch %q not found on origin", r.configGroup, r.baseBranch)
+		}
+		return r.baseBranch, nil
+	}
+	if r.TaskRunner != nil && r.TaskRunner.DefaultBranch != "" {
+		if !taskRepo.BranchExists("origin/" + r.TaskRunner.DefaultBranch) {
+			return "", fmt.Errorf("default_branch %q not found on origin", r.TaskRunner.DefaultBranch)
+		}
+		return r.TaskRunner.DefaultBranch, nil
+	}
+	if head, ok := taskRepo.OriginHead(); ok && taskRepo.BranchExists("origin/"+head) {
+		return head, nil
+	}
 	if taskRepo.BranchExists("origin/main") {
 		return "main", nil
 	}
 	if taskRepo.BranchExists("origin/master") {
 		return "master", nil
 	}
-	return "", errors.New("cannot detect default branch (neither main nor master found)")
+	return "", errors.New("cannot detect default branch (origin/HEAD is not set and neither main nor master found; set default_branch in hydra.yml)")
+}
+
+// targetBranch returns the branch task branches start from and are rebased
+// onto, as named in Claude's instructions: detectDefaultBranch's answer for
+// the main repository, or, if it has none, the group's base branch or
+// default_branch, and main without either.
+func (r *Runner) targetBranch() string {
+	if r.Config != nil && r.Config.RepoDir != "" && repo.IsGitRepo(r.Config.RepoDir) {
+		if branch, err := r.detectDefaultBranch(repo.Open(r.Config.RepoDir)); err == nil {
+			return branch
+		}
+	}
+	if r.baseBranch != "" {
+		return r.baseBranch
+	}
+	if r.TaskRunner != nil && r.TaskRunner.DefaultBranch != "" {
+		return r.TaskRunner.DefaultBranch
+	}
+	return "main"
 }
 
 // closeIssueIfNeeded closes the remote issue if the task is an issue task.
 func (r *Runner) closeIssueIfNeeded(task *design.Task, sha string) {
-	if r.IssueCloser == nil || !issues.IsIssueTask(task) {
+	if r.IssueCloser == nil {
 		return
 	}
-	num := issues.ParseIssueTaskNumber(task.Name)
+	num := issues.IssueNumber(task)
 	if num == 0 {
 		return
 	}
@@ -374,11 +621,15 @@ func (r *Runner) MergeGroup(groupName string) error {
 		return groupTasks[i].Name < groupTasks[j].Name
 	})
 
+	progress := newGroupProgress(os.Stdout, len(groupTasks), 0)
+	defer r.startHeartbeat(context.Background(), "group merge "+groupName, progress)()
 	for _, t := range groupTasks {
 		taskRef := groupName + "/" + t.Name
+		progress.begin(taskRef)
 		if err := r.Merge(taskRef); err != nil {
 			return fmt.Errorf("task %s: %w", taskRef, err)
 		}
+		progress.finish(taskRef)
 	}
 
 	return nil
@@ -415,12 +666,17 @@ func (r *Runner) MergeEdit(taskName, editor string) error {
 	return design.RunEditorOnFile(editor, task.FilePath, os.Stdin, os.Stdout, os.Stderr)
 }
 
-// MergeRemove moves a task from merge to abandoned.
-func (r *Runner) MergeRemove(taskName string) error {
+// MergeRemove moves a task from merge to abandoned, once confirmed, and
+// offers to clean up its work directory, remote branch, and issue.
+func (r *Runner) MergeRemove(taskName string, opts AbandonOpts) error {
 	task, err := r.Design.FindTaskByState(taskName, design.StateMerge)
 	if err != nil {
 		return err
 	}
+	if !confirmAbandon(task, opts) {
+		fmt.Println("Aborted.")
+		return nil
+	}
 
-	return r.Design.MoveTask(task, design.StateAbandoned)
+	return r.abandonTask(task, opts)
 }
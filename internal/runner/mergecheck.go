@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// errMergeCheckFailed is returned by MergeCheck when the task would not
+// merge cleanly.
+var errMergeCheckFailed = errors.New("merge check found problems")
+
+// mergeCheck is what hydra merge check found out about a task.
+type mergeCheck struct {
+	Task      string
+	Branch    string // the task branch, or its copy on origin
+	Onto      string // the default branch on origin
+	Ahead     int    // task commits not on Onto
+	Behind    int    // Onto commits not on the task branch
+	Conflicts []string
+	Checks    []taskrun.Result // test and lint on the rebased code; empty on conflicts
+}
+
+// ok reports whether the task would rebase cleanly and pass its checks.
+func (m mergeCheck) ok() bool {
+	if len(m.Conflicts) > 0 {
+		return false
+	}
+	for _, c := range m.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeCheck does a dry run of the merge workflow for a task in review or
+// merge state: it fetches origin, rebases a copy of the task branch onto the
+// default branch in a throwaway worktree, and runs the test and lint
+// commands there if the rebase is clean. It prints the conflicts, failing
+// checks, and how far ahead and behind the branch is, and returns an error
+// if the task would not merge cleanly. The task's work directory, the
+// record, and the task's state are left alone.
+func (r *Runner) MergeCheck(taskName string) error {
+	task, err := r.findMergeTask(taskName)
+	if err != nil {
+		return err
+	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+
+	mainRepo := repo.Open(r.Config.RepoDir)
+	if err := mainRepo.Fetch(); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+	defaultBranch, err := r.detectDefaultBranch(mainRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+
+	m := mergeCheck{Task: taskName, Branch: task.BranchName(), Onto: "origin/" + defaultBranch}
+	if !mainRepo.BranchExists(m.Branch) {
+		return fmt.Errorf("task branch %q does not exist", m.Branch)
+	}
+	if m.Ahead, m.Behind, err = mainRepo.AheadBehind(m.Branch, m.Onto); err != nil {
+		return fmt.Errorf("comparing %s with %s: %w", m.Branch, m.Onto, err)
+	}
+
+	trial, conflicts, cleanup, err := mainRepo.TrialRebaseWorktree(m.Branch, m.Onto)
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if err != nil {
+		return fmt.Errorf("trial rebase onto %s: %w", m.Onto, err)
+	}
+	m.Conflicts = conflicts
+
+	if len(conflicts) == 0 && r.TaskRunner != nil {
+		var names []string
+		for _, name := range []string{"test", "lint"} {
+			if r.TaskRunner.HasCommand(name, trial.Dir) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			if err := r.runBeforeHook(trial.Dir); err != nil {
+				return fmt.Errorf("%w: %w", errBeforeHook, err)
+			}
+			fmt.Printf("Running %s on the rebased branch...\n", strings.Join(names, " and "))
+			m.Checks = r.commands().OutputAll(names, trial.Dir)
+			r.runTeardown(trial.Dir)
+		}
+	}
+
+	fmt.Print(formatMergeCheck(m))
+	if !m.ok() {
+		return errMergeCheckFailed
+	}
+	return nil
+}
+
+// formatMergeCheck renders the result of a merge check.
+func formatMergeCheck(m mergeCheck) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nMerge check for %s (%s onto %s):\n", m.Task, m.Branch, m.Onto)
+	fmt.Fprintf(&b, "  %d commit(s) ahead, %d behind\n", m.Ahead, m.Behind)
+	if len(m.Conflicts) > 0 {
+		b.WriteString("  Rebase: conflicts in\n")
+		for _, f := range m.Conflicts {
+			fmt.Fprintf(&b, "    %s\n", f)
+		}
+	} else {
+		b.WriteString("  Rebase: clean\n")
+	}
+	for _, c := range m.Checks {
+		status := "pass"
+		if c.Err != nil {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", c.Name, status)
+	}
+	if m.ok() {
+		b.WriteString("Ready to merge.\n")
+	} else {
+		b.WriteString("Not ready to merge.\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestMergeCheck(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	mkdirAll(t, filepath.Join(env.DesignDir, "state", "review"))
+	writeFile(t, filepath.Join(env.DesignDir, "state", "review", "add-feature.md"), "Add the feature.")
+
+	commitOnBranch(t, env.BaseDir, "hydra/add-feature", map[string]string{"shared.go": "package main // feature"})
+	if err := r.MergeCheck("add-feature"); err != nil {
+		t.Fatalf("MergeCheck on a clean branch: %v", err)
+	}
+
+	// Upstream changes the same file.
+	writeFile(t, filepath.Join(env.BaseDir, "shared.go"), "package main // upstream")
+	gitRun(t, "-C", env.BaseDir, "add", "shared.go")
+	gitRun(t, "-C", env.BaseDir, "commit", "-q", "-m", "upstream change")
+	gitRun(t, "-C", env.BaseDir, "push", "-q", "origin", "main")
+
+	if err := r.MergeCheck("add-feature"); !errors.Is(err, errMergeCheckFailed) {
+		t.Errorf("MergeCheck with conflicts = %v, want errMergeCheckFailed", err)
+	}
+	if _, err := r.Design.FindTaskByState("add-feature", design.StateReview); err != nil {
+		t.Errorf("task left review: %v", err)
+	}
+}
+
+func TestFormatMergeCheck(t *testing.T) {
+	m := mergeCheck{
+		Task: "add-feature", Branch: "hydra/add-feature", Onto: "origin/main", Ahead: 2, Behind: 3,
+		Checks: []taskrun.Result{{Name: "test"}, {Name: "lint", Err: errors.New("exit 1")}},
+	}
+	got := formatMergeCheck(m)
+	for _, want := range []string{"2 commit(s) ahead, 3 behind", "Rebase: clean", "test: pass", "lint: FAIL", "Not ready to merge."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q:\n%s", want, got)
+		}
+	}
+
+	m.Checks = nil
+	m.Conflicts = []string{"main.go"}
+	if got := formatMergeCheck(m); !strings.Contains(got, "conflicts in\n    main.go\n") {
+		t.Errorf("conflicts not listed:\n%s", got)
+	}
+}
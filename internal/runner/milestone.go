@@ -0,0 +1,272 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MilestoneRun works through a milestone's task group: each pending task is
+// run, reviewed, and merged, each task already in review state is reviewed
+// and merged, and each task in merge state is merged. Tasks are taken in the order their promises appear in the
+// milestone file, with tasks that match no promise last. After every merge
+// the milestone is verified and newly kept promises are reported. Stops on
+// the first error.
+func (r *Runner) MilestoneRun(date string) (err error) {
+	m, err := r.Design.FindMilestone(date)
+	if err != nil {
+		return err
+	}
+	content, err := m.Content()
+	if err != nil {
+		return err
+	}
+	promises := design.ParsePromises(content)
+	group := design.MilestoneTaskGroup(m.Date)
+
+	var tasks []design.Task
+	for _, state := range []design.TaskState{design.StatePending, design.StateReview, design.StateMerge} {
+		stateTasks, err := r.Design.TasksByState(state)
+		if err != nil {
+			return fmt.Errorf("listing %s tasks: %w", state, err)
+		}
+		for _, t := range stateTasks {
+			if t.Group == group {
+				tasks = append(tasks, t)
+			}
+		}
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("no pending, review, or merge tasks found for milestone %s", m.Date)
+	}
+	orderMilestoneTasks(tasks, promises)
+
+	before, err := r.Design.VerifyMilestone(m)
+	if err != nil {
+		return fmt.Errorf("verifying milestone: %w", err)
+	}
+	kept := keptPromises(before)
+
+	ctx, span := tracing.Start(context.Background(), "milestone run",
+		attribute.String("hydra.milestone", m.Date), attribute.Int("hydra.tasks", len(tasks)))
+	defer func() { tracing.End(span, err) }()
+
+	progress := newGroupProgress(os.Stdout, len(tasks), 0)
+	defer r.startHeartbeat(ctx, "milestone "+m.Date, progress)()
+	for _, t := range tasks {
+		taskRef := group + "/" + t.Name
+		progress.begin(taskRef)
+
+		if t.State == design.StatePending {
+			if err := r.runTask(ctx, taskRef); err != nil {
+				return fmt.Errorf("task %s: %w", taskRef, err)
+			}
+			// A run that ends without moving the task to review (for
+			// example, an interrupted session) leaves nothing to merge.
+			if _, err := r.findMergeTask(taskRef); err != nil {
+				return fmt.Errorf("task %s was not moved to review after running", taskRef)
+			}
+		}
+		if t.State != design.StateMerge {
+			if err := r.Review(taskRef); err != nil {
+				return fmt.Errorf("task %s: %w", taskRef, err)
+			}
+		}
+		if err := r.Merge(taskRef); err != nil {
+			return fmt.Errorf("task %s: %w", taskRef, err)
+		}
+
+		result, err := r.Design.VerifyMilestone(m)
+		if err != nil {
+			return fmt.Errorf("verifying milestone: %w", err)
+		}
+		now := keptPromises(result)
+		for _, p := range now {
+			if !slices.Contains(kept, p) {
+				fmt.Printf("Promise kept: %s\n", p)
+			}
+		}
+		kept = now
+		progress.finish(taskRef)
+	}
+
+	result, err := r.Design.VerifyMilestone(m)
+	if err != nil {
+		return fmt.Errorf("verifying milestone: %w", err)
+	}
+	fmt.Printf("Milestone %s: %d of %d promise(s) kept.\n", m.Date, len(keptPromises(result)), len(result.Promises))
+	if result.AllKept {
+		fmt.Printf("All promises kept. Run 'hydra milestone deliver %s' to mark it delivered.\n", m.Date)
+	}
+	for _, s := range result.Missing {
+		fmt.Printf("  Missing task: %s\n", s)
+	}
+	for _, s := range result.Incomplete {
+		fmt.Printf("  Incomplete: %s\n", s)
+	}
+	return nil
+}
+
+// orderMilestoneTasks sorts tasks by the position of their promise in the
+// milestone file, which is the order the milestone's author listed them in.
+// Tasks matching no promise go last, alphabetically.
+func orderMilestoneTasks(tasks []design.Task, promises []design.Promise) {
+	rank := make(map[string]int, len(promises))
+	for i, p := range promises {
+		if _, ok := rank[p.Slug]; !ok {
+			rank[p.Slug] = i
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		ri, iok := rank[tasks[i].Name]
+		rj, jok := rank[tasks[j].Name]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return tasks[i].Name < tasks[j].Name
+		}
+	})
+}
+
+// keptPromises returns the slugs of the promises in a verify result whose
+// tasks are completed.
+func keptPromises(result *design.VerifyResult) []string {
+	var kept []string
+	for _, p := range result.Promises {
+		if !slices.Contains(result.Missing, p.Slug) && !slices.Contains(result.Incomplete, p.Slug) {
+			kept = append(kept, p.Slug)
+		}
+	}
+	return kept
+}
+
+// MilestoneDeliverOpts controls the checks made by MilestoneDeliver.
+type MilestoneDeliverOpts struct {
+	Verified bool // require functional verification to pass for the milestone's promises
+	Force    bool // deliver even if verification finds broken promises
+}
+
+// MilestoneDeliver marks a milestone delivered. With opts.Verified, the
+// functional requirements are verified first: the report saved by the last
+// hydra verify --report is used if it is for the current head of the
+// default branch, otherwise verification is run and its report saved.
+// Delivery is refused if a failed requirement names one of the milestone's
+// promises, unless opts.Force is set. Other failures are reported but don't
+// block delivery.
+func (r *Runner) MilestoneDeliver(date string, opts MilestoneDeliverOpts) error {
+	m, err := r.Design.FindMilestone(date)
+	if err != nil {
+		return err
+	}
+
+	if opts.Verified {
+		content, err := m.Content()
+		if err != nil {
+			return err
+		}
+		promises := design.ParsePromises(content)
+
+		report, err := r.currentVerifyReport(promises)
+		if err != nil {
+			return err
+		}
+		broken := report.PromiseFailures(promises)
+		for _, f := range report.Failures {
+			if !slices.Contains(broken, f) {
+				fmt.Printf("Unrelated verification failure: %s\n", f)
+			}
+		}
+		if len(broken) > 0 {
+			var b strings.Builder
+			fmt.Fprintf(&b, "verification of %s failed for %d promise(s) of milestone %s:", shortSHA(report.SHA), len(broken), m.Date)
+			for _, f := range broken {
+				b.WriteString("\n  - " + f)
+			}
+			if !opts.Force {
+				return errors.New(b.String() + "\nfix them, or pass --force to deliver anyway")
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %s\nDelivering anyway (--force).\n", b.String())
+		} else {
+			fmt.Printf("Verified %s: no promise of milestone %s is broken.\n", shortSHA(report.SHA), m.Date)
+		}
+	}
+
+	if err := r.Design.DeliverMilestone(m); err != nil {
+		return err
+	}
+	fmt.Printf("Delivered milestone %s\n", m.Date)
+	return nil
+}
+
+// currentVerifyReport returns the saved verify report if it is for the
+// current head of origin's default branch, and otherwise runs verification
+// and returns the report it saves.
+func (r *Runner) currentVerifyReport(promises []design.Promise) (*design.VerifyReport, error) {
+	report, err := r.Design.VerifyReport()
+	if err != nil {
+		return nil, err
+	}
+	// A verification limited by --since doesn't cover every requirement.
+	if report != nil && report.Since == "" {
+		head, err := r.defaultBranchHead()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not check the saved verify report: %v\n", err)
+		} else if head == report.SHA {
+			fmt.Printf("Using the verify report of %s from %s.\n", shortSHA(report.SHA), report.Time.Local().Format("2006-01-02 15:04"))
+			return report, nil
+		}
+	}
+
+	fmt.Println("Verifying functional requirements...")
+	started := time.Now().UTC().Truncate(time.Second)
+	r.VerifyReport = true
+	verifyErr := r.verify(promises)
+
+	report, err = r.Design.VerifyReport()
+	if err != nil {
+		return nil, err
+	}
+	if report == nil || report.Time.Before(started) {
+		if verifyErr == nil {
+			verifyErr = errors.New("verification saved no report")
+		}
+		return nil, fmt.Errorf("verifying: %w", verifyErr)
+	}
+	return report, nil
+}
+
+// defaultBranchHead fetches the source repository and returns the SHA of
+// origin's default branch.
+func (r *Runner) defaultBranchHead() (string, error) {
+	if r.Config == nil || r.Config.RepoDir == "" || !repo.IsGitRepo(r.Config.RepoDir) {
+		return "", errors.New("no source repository")
+	}
+	sourceRepo := repo.Open(r.Config.RepoDir)
+	if err := sourceRepo.Fetch(); err != nil {
+		return "", fmt.Errorf("fetching origin: %w", err)
+	}
+	defaultBranch, err := r.detectDefaultBranch(sourceRepo)
+	if err != nil {
+		return "", err
+	}
+	sha, _, err := sourceRepo.CommitInfo("origin/" + defaultBranch)
+	return sha, err
+}
+
+// shortSHA abbreviates a commit SHA for messages.
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 12)]
+}
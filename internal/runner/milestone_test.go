@@ -0,0 +1,174 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+func TestMilestoneRunNoTasks(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mkdirAll(t, filepath.Join(env.DesignDir, "milestone"))
+	writeFile(t, filepath.Join(env.DesignDir, "milestone", "2026-03-01.md"), "## Ship it\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.Claude = mockClaude
+	r.BaseDir = env.BaseDir
+
+	err = r.MilestoneRun("2026-03-01")
+	if err == nil {
+		t.Fatal("expected error for a milestone without tasks")
+	}
+	if !strings.Contains(err.Error(), "no pending, review, or merge tasks found for milestone 2026-03-01") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMilestoneRunReviews(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mkdirAll(t, filepath.Join(env.DesignDir, "milestone"))
+	writeFile(t, filepath.Join(env.DesignDir, "milestone", "2026-03-01.md"), "## Login\n")
+	mkdirAll(t, filepath.Join(env.DesignDir, "tasks", "milestone-2026-03-01"))
+	writeFile(t, filepath.Join(env.DesignDir, "tasks", "milestone-2026-03-01", "login.md"), "Add the login page.")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+
+	var sessions []string
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		sessions = append(sessions, cfg.TaskName)
+		name := strings.NewReplacer(":", "-", "/", "-").Replace(cfg.TaskName) + ".txt"
+		if err := os.WriteFile(filepath.Join(cfg.RepoDir, name), []byte("done"), 0o600); err != nil {
+			return err
+		}
+		return mockCommit(cfg.RepoDir)
+	}
+
+	if err := r.MilestoneRun("2026-03-01"); err != nil {
+		t.Fatalf("MilestoneRun: %v", err)
+	}
+	if !slices.Contains(sessions, "review:milestone-2026-03-01/login") {
+		t.Errorf("sessions = %v, want the task reviewed before its merge", sessions)
+	}
+	if _, err := r.Design.FindTaskByState("milestone-2026-03-01/login", design.StateCompleted); err != nil {
+		t.Errorf("task should be merged: %v", err)
+	}
+}
+
+func TestOrderMilestoneTasks(t *testing.T) {
+	tasks := []design.Task{
+		{Name: "zeta"},
+		{Name: "second"},
+		{Name: "alpha"},
+		{Name: "first"},
+	}
+	promises := []design.Promise{{Slug: "first"}, {Slug: "second"}, {Slug: "unplanned"}}
+
+	orderMilestoneTasks(tasks, promises)
+
+	var got []string
+	for _, task := range tasks {
+		got = append(got, task.Name)
+	}
+	want := []string{"first", "second", "alpha", "zeta"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestKeptPromises(t *testing.T) {
+	result := &design.VerifyResult{
+		Promises:   []design.Promise{{Slug: "a"}, {Slug: "b"}, {Slug: "c"}},
+		Missing:    []string{"b"},
+		Incomplete: []string{"c"},
+	}
+	if got := keptPromises(result); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("keptPromises = %v, want [a]", got)
+	}
+}
+
+func TestMilestoneDeliverVerified(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mkdirAll(t, filepath.Join(env.DesignDir, "milestone"))
+	writeFile(t, filepath.Join(env.DesignDir, "milestone", "2026-03-01.md"), "## Add the login page\n\n## Export to CSV\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+
+	var sessions int
+	failures := "- Export to CSV: the export command is missing\n- Logging: no tests\n"
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		sessions++
+		if !strings.Contains(cfg.Document, "- Add the login page\n- Export to CSV\n") {
+			t.Error("verify document should list the milestone's promises")
+		}
+		return os.WriteFile(filepath.Join(cfg.RepoDir, "verify-failed.txt"), []byte(failures), 0o600)
+	}
+
+	err = r.MilestoneDeliver("2026-03-01", MilestoneDeliverOpts{Verified: true})
+	if err == nil || !strings.Contains(err.Error(), "Export to CSV: the export command is missing") {
+		t.Fatalf("error = %v, want delivery refused for the broken promise", err)
+	}
+	if strings.Contains(err.Error(), "Logging") {
+		t.Errorf("error = %v, should not list failures unrelated to the milestone", err)
+	}
+	if _, err := r.Design.FindMilestone("2026-03-01"); err != nil {
+		t.Errorf("milestone should not be delivered: %v", err)
+	}
+
+	// The saved report is for the current head, so it is reused.
+	if err := r.MilestoneDeliver("2026-03-01", MilestoneDeliverOpts{Verified: true, Force: true}); err != nil {
+		t.Fatalf("MilestoneDeliver --force: %v", err)
+	}
+	if sessions != 1 {
+		t.Errorf("verification ran %d times, want the saved report reused", sessions)
+	}
+	delivered, err := r.Design.DeliveredMilestones()
+	if err != nil || len(delivered) != 1 {
+		t.Errorf("delivered milestones = %v (%v), want 1", delivered, err)
+	}
+}
+
+func TestMilestoneDeliverUnrelatedFailures(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mkdirAll(t, filepath.Join(env.DesignDir, "milestone"))
+	writeFile(t, filepath.Join(env.DesignDir, "milestone", "2026-03-01.md"), "## Add the login page\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		return os.WriteFile(filepath.Join(cfg.RepoDir, "verify-failed.txt"), []byte("- Logging: no tests\n"), 0o600)
+	}
+
+	if err := r.MilestoneDeliver("2026-03-01", MilestoneDeliverOpts{Verified: true}); err != nil {
+		t.Fatalf("MilestoneDeliver: %v", err)
+	}
+	report, err := r.Design.VerifyReport()
+	if err != nil || report == nil {
+		t.Fatalf("VerifyReport = %v, %v; want the report saved", report, err)
+	}
+	if report.Passed || len(report.Failures) != 1 {
+		t.Errorf("report = %+v, want one failure", report)
+	}
+}
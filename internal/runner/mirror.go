@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erikh/hydra/internal/tui"
+)
+
+// mirrorFlushTimeout is how long the mirror keeps writing to its clients
+// after the session ends.
+const mirrorFlushTimeout = time.Second
+
+// mirrorDir returns the directory holding the mirror sockets of a task's
+// running sessions, given as name or group/name.
+func mirrorDir(hydraDir, taskName string) string {
+	return filepath.Join(hydraDir, "attach", strings.ReplaceAll(taskName, "/", "--"))
+}
+
+// mirrorSocketPath returns the socket a session mirrors its output on:
+// <mirrorDir>/<action>.sock, where action is the lock prefix (review, test,
+// merge, plan) or run.
+func mirrorSocketPath(hydraDir, lockName string) string {
+	action, task := "run", lockName
+	if a, t, ok := strings.Cut(lockName, ":"); ok {
+		action, task = a, t
+	}
+	return filepath.Join(mirrorDir(hydraDir, task), action+".sock")
+}
+
+// serveMirror streams the session output recorded in transcript to every
+// client of the session's mirror socket under .hydra/attach, so hydra attach
+// can watch it from another terminal. Clients get the output so far, then
+// the output as it is written; anything they send is ignored. The returned
+// function closes the socket and disconnects the clients. Mirroring is best
+// effort: if the socket can't be created, a warning is printed and the
+// session goes on without it.
+func serveMirror(cfg ClaudeRunConfig, transcript *tui.Transcript) (stop func()) {
+	if cfg.TaskName == "" || cfg.HydraDir == "" {
+		return func() {}
+	}
+	path := mirrorSocketPath(cfg.HydraDir, cfg.TaskName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: session mirror disabled: %v\n", err)
+		return func() {}
+	}
+	// A socket left by a hydra that died is in the way; a live one means the
+	// same session is already being mirrored, which the task lock prevents.
+	_ = os.Remove(path)
+	ln, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: session mirror disabled: %v\n", err)
+		return func() {}
+	}
+
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]struct{})
+		wg    sync.WaitGroup
+		done  = make(chan struct{})
+	)
+	wg.Go(func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns[conn] = struct{}{}
+			mu.Unlock()
+			wg.Go(func() {
+				defer func() {
+					mu.Lock()
+					delete(conns, conn)
+					mu.Unlock()
+					_ = conn.Close()
+				}()
+				backlog, updates, stopFollow := transcript.Follow()
+				defer stopFollow()
+				if _, err := io.WriteString(conn, backlog); err != nil {
+					return
+				}
+				for {
+					select {
+					case s, ok := <-updates:
+						if !ok {
+							return
+						}
+						if _, err := io.WriteString(conn, s); err != nil {
+							return
+						}
+					case <-done:
+						// Send what the session wrote last before hanging up.
+						for {
+							select {
+							case s, ok := <-updates:
+								if !ok {
+									return
+								}
+								_, _ = io.WriteString(conn, s)
+							default:
+								return
+							}
+						}
+					}
+				}
+			})
+		}
+	})
+
+	return func() {
+		_ = ln.Close()
+		close(done)
+		// Unblock writes to clients that stopped reading.
+		mu.Lock()
+		for conn := range conns {
+			_ = conn.SetWriteDeadline(time.Now().Add(mirrorFlushTimeout))
+		}
+		mu.Unlock()
+		wg.Wait()
+		_ = os.Remove(path)
+		_ = os.Remove(filepath.Dir(path)) // only if no other session of the task is mirrored
+	}
+}
+
+// ErrNoMirror is returned by AttachMirror when the task has no session
+// running that can be mirrored.
+var ErrNoMirror = errors.New("no running session to attach to")
+
+// AttachMirror copies the output of a running session of taskName, given as
+// name or group/name, to w until the session ends. Only sessions in the
+// built-in TUI, or run headless, are mirrored. If the task has sessions of
+// several actions running, the first that answers is used. A grouped task
+// matches whether its session was started as group/name or as name.
+func AttachMirror(hydraDir, taskName string, w io.Writer) error {
+	sockets, _ := filepath.Glob(filepath.Join(mirrorDir(hydraDir, taskName), "*.sock"))
+	if group, name, ok := strings.Cut(taskName, "/"); ok && group != "" {
+		more, _ := filepath.Glob(filepath.Join(mirrorDir(hydraDir, name), "*.sock"))
+		sockets = append(sockets, more...)
+	} else {
+		more, _ := filepath.Glob(filepath.Join(hydraDir, "attach", "*--"+taskName, "*.sock"))
+		sockets = append(sockets, more...)
+	}
+	for _, path := range sockets {
+		conn, err := (&net.Dialer{}).DialContext(context.Background(), "unix", path)
+		if err != nil {
+			continue
+		}
+		defer func() { _ = conn.Close() }()
+		_, err = io.Copy(w, conn)
+		return err
+	}
+	return ErrNoMirror
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/claude"
+	"github.com/erikh/hydra/internal/tui"
+)
+
+func TestMirror(t *testing.T) {
+	hydraDir := t.TempDir()
+	transcript := tui.NewTranscript()
+	stop := serveMirror(ClaudeRunConfig{TaskName: "review:grp/foo", HydraDir: hydraDir}, transcript)
+
+	if err := AttachMirror(hydraDir, "other", io.Discard); !errors.Is(err, ErrNoMirror) {
+		t.Errorf("AttachMirror(other) = %v, want ErrNoMirror", err)
+	}
+
+	events := make(chan claude.Event, 2)
+	session := &claude.Session{Events: events, ToolAnswer: make(chan claude.ToolAnswer, 1)}
+	events <- claude.EventText{Text: "hello\n"}
+	events <- claude.EventDone{StopReason: "end_turn"}
+	if err := tui.RunHeadless(context.Background(), session, nil, io.Discard, transcript); err != nil {
+		t.Fatal(err)
+	}
+
+	// A client that connects mid-session gets the output so far, by the
+	// task's short name too, and the rest until the session ends.
+	pr, pw := io.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- AttachMirror(hydraDir, "foo", pw)
+		_ = pw.Close()
+	}()
+	buf := make([]byte, len("hello"))
+	if _, err := io.ReadFull(pr, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("read %q, %v; want hello", buf, err)
+	}
+	stop()
+	rest, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("AttachMirror: %v", err)
+	}
+	if !strings.Contains(string(rest), "Session complete (end_turn).") {
+		t.Errorf("rest of the stream = %q", rest)
+	}
+
+	if _, err := os.Stat(mirrorSocketPath(hydraDir, "review:grp/foo")); !os.IsNotExist(err) {
+		t.Errorf("socket left behind: %v", err)
+	}
+}
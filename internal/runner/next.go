@@ -0,0 +1,266 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+// defaultStaleAfter is how long a task can sit in review before Next calls
+// it stale, when hydra.yml sets no stale_after.
+const defaultStaleAfter = 7 * 24 * time.Hour
+
+// milestoneWarning is how far ahead of a milestone date outstanding promises
+// start to be recommended.
+const milestoneWarning = 7 * 24 * time.Hour
+
+// Recommendation is a suggested next action produced by Next.
+type Recommendation struct {
+	Priority int    // higher is more urgent
+	Reason   string // why this is recommended
+	Command  string // hydra command that acts on it
+}
+
+// Next returns recommended next actions ranked by urgency, synthesized from
+// task states, running locks, record.json, and milestone data. now is the
+// reference time for ages and due dates.
+func (r *Runner) Next(now time.Time) ([]Recommendation, error) {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+
+	running := make(map[string]bool)
+	if locks, err := lock.ReadAll(config.HydraPath(baseDir)); err == nil {
+		for _, rt := range locks {
+			_, name := splitLockName(rt.TaskName)
+			running[name] = true
+		}
+	}
+
+	entries, err := design.NewRecord(r.Design.Path).Entries()
+	if err != nil {
+		return nil, err
+	}
+	lastReview := make(map[string]design.RecordEntry)
+	lastRun := make(map[string]time.Time)
+	for _, e := range entries {
+		switch action, name := splitLockName(e.TaskName); action {
+		case "review":
+			lastReview[name] = e
+		case "":
+			if e.Time.After(lastRun[name]) {
+				lastRun[name] = e.Time
+			}
+		}
+	}
+
+	staleAfter := defaultStaleAfter
+	if r.TaskRunner != nil && r.TaskRunner.StaleAfter != nil {
+		staleAfter = r.TaskRunner.StaleAfter.Duration
+	}
+	staleTasks, err := r.Design.StaleTasks(staleAfter, now)
+	if err != nil {
+		return nil, err
+	}
+	stale := make(map[string]design.StaleTask, len(staleTasks))
+	for _, st := range staleTasks {
+		stale[taskLabel(st.Task)] = st
+	}
+
+	var recs []Recommendation
+
+	milestoneRecs, err := r.milestoneRecommendations(now)
+	if err != nil {
+		return nil, err
+	}
+	recs = append(recs, milestoneRecs...)
+
+	merging, err := r.Design.TasksByState(design.StateMerge)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range merging {
+		if running[taskLabel(t)] {
+			continue
+		}
+		recs = append(recs, Recommendation{
+			Priority: 90,
+			Reason:   fmt.Sprintf("%s is stuck in merge state", taskLabel(t)),
+			Command:  "hydra merge run " + taskLabel(t),
+		})
+	}
+
+	reviewing, err := r.Design.TasksByState(design.StateReview)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range reviewing {
+		label := taskLabel(t)
+		if running[label] {
+			continue
+		}
+
+		age := taskAge(t, lastRun[label], now)
+		rec := Recommendation{
+			Priority: 50 + min(int(age/(24*time.Hour)), 20),
+			Reason:   fmt.Sprintf("%s is awaiting review", label),
+			Command:  "hydra review run " + label,
+		}
+		if e, ok := lastReview[label]; ok {
+			if failed := failedChecks(e.Checklist); failed > 0 {
+				rec.Priority += 15
+				rec.Reason = fmt.Sprintf("%s failed %d review checklist item(s)", label, failed)
+			} else {
+				rec.Priority -= 10
+				rec.Reason = fmt.Sprintf("%s has been reviewed and is ready to merge", label)
+				rec.Command = "hydra merge run " + label
+			}
+		}
+		if st, ok := stale[label]; ok {
+			rec.Reason += staleNote(st)
+		}
+		recs = append(recs, rec)
+	}
+
+	pending, err := r.Design.PendingTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range pending {
+		label := taskLabel(t)
+		if running[label] {
+			continue
+		}
+		reason := fmt.Sprintf("%s is pending", label)
+		if st, ok := stale[label]; ok {
+			reason += staleNote(st)
+		}
+		recs = append(recs, Recommendation{
+			Priority: 10,
+			Reason:   reason,
+			Command:  "hydra run " + label,
+		})
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].Priority > recs[j].Priority
+	})
+	return recs, nil
+}
+
+// milestoneRecommendations returns recommendations for undelivered milestones
+// that are overdue or due soon and still have outstanding promises.
+func (r *Runner) milestoneRecommendations(now time.Time) ([]Recommendation, error) {
+	milestones, err := r.Design.Milestones()
+	if err != nil {
+		return nil, err
+	}
+
+	// Milestones are due on a day in local time, not UTC.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var recs []Recommendation
+	for _, m := range milestones {
+		due, err := time.ParseInLocation(time.DateOnly, m.Date, now.Location())
+		if err != nil {
+			continue
+		}
+
+		// Rounded, since a day across a daylight saving change isn't 24 hours.
+		until := due.Sub(today).Round(24 * time.Hour)
+		if until > milestoneWarning {
+			continue
+		}
+
+		result, err := r.Design.VerifyMilestone(&m)
+		if err != nil {
+			return nil, err
+		}
+		outstanding := len(result.Missing) + len(result.Incomplete)
+
+		switch {
+		case until <= 0 && outstanding == 0:
+			recs = append(recs, Recommendation{
+				Priority: 95,
+				Reason:   fmt.Sprintf("milestone %s is due and all promises are kept", m.Date),
+				Command:  "hydra milestone verify",
+			})
+		case until < 0:
+			recs = append(recs, Recommendation{
+				Priority: 100,
+				Reason:   fmt.Sprintf("milestone %s is overdue with %d outstanding promise(s)", m.Date, outstanding),
+				Command:  "hydra milestone verify",
+			})
+		case len(result.Missing) > 0:
+			recs = append(recs, Recommendation{
+				Priority: 85,
+				Reason:   fmt.Sprintf("milestone %s is due in %d day(s) and %d promise(s) have no task", m.Date, int(until/(24*time.Hour)), len(result.Missing)),
+				Command:  "hydra milestone repair " + m.Date,
+			})
+		case outstanding > 0:
+			recs = append(recs, Recommendation{
+				Priority: 80,
+				Reason:   fmt.Sprintf("milestone %s is due in %d day(s) with %d incomplete promise(s)", m.Date, int(until/(24*time.Hour)), outstanding),
+				Command:  "hydra group run " + design.MilestoneTaskGroup(m.Date),
+			})
+		}
+	}
+	return recs, nil
+}
+
+// splitLockName splits a lock or record task name like "review:foo" into its
+// action and task name. Plain task names have an empty action.
+func splitLockName(s string) (string, string) {
+	if prefix, rest, ok := strings.Cut(s, ":"); ok {
+		switch prefix {
+		case "review", "test", "merge":
+			return prefix, rest
+		}
+	}
+	return "", s
+}
+
+// taskLabel returns the group-qualified name of a task.
+func taskLabel(t design.Task) string {
+	return t.Label()
+}
+
+// staleNote describes why a recommended task is stale.
+func staleNote(st design.StaleTask) string {
+	if st.Expired {
+		return " (stale: expired " + st.Expires.Format(time.DateOnly) + ")"
+	}
+	return fmt.Sprintf(" (stale: untouched for %d days)", int(st.Idle/(24*time.Hour)))
+}
+
+// taskAge returns how long a task has been in its state: since entered,
+// the time record.json has for the run that moved it there, or, without
+// one, since its file was last modified. Moving a task keeps its file's
+// modification time, so that is only a fallback.
+func taskAge(t design.Task, entered, now time.Time) time.Duration {
+	if !entered.IsZero() {
+		return now.Sub(entered)
+	}
+	info, err := os.Stat(t.FilePath)
+	if err != nil {
+		return 0
+	}
+	return now.Sub(info.ModTime())
+}
+
+// failedChecks counts failed items in a review checklist.
+func failedChecks(results []design.ChecklistResult) int {
+	n := 0
+	for _, c := range results {
+		if !c.Passed {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,185 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestNext(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	dd := r.Design.Path
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	writeFile(t, filepath.Join(dd, "tasks", "todo.md"), "Pending.")
+	mkdirAll(t, filepath.Join(dd, "state", "review"))
+	mkdirAll(t, filepath.Join(dd, "state", "merge"))
+	writeFile(t, filepath.Join(dd, "state", "review", "fresh.md"), "Fresh.")
+	writeFile(t, filepath.Join(dd, "state", "review", "old.md"), "Old.")
+	writeFile(t, filepath.Join(dd, "state", "review", "failing.md"), "Failing.")
+	writeFile(t, filepath.Join(dd, "state", "merge", "stuck.md"), "Stuck.")
+
+	for name, age := range map[string]time.Duration{
+		"fresh.md":   time.Hour,
+		"old.md":     10 * 24 * time.Hour,
+		"failing.md": time.Hour,
+	} {
+		mtime := now.Add(-age)
+		if err := os.Chtimes(filepath.Join(dd, "state", "review", name), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := design.NewRecord(dd)
+	if err := rec.AddEntry(design.RecordEntry{
+		SHA:       "abc",
+		TaskName:  "review:failing",
+		Checklist: []design.ChecklistResult{{Item: "Docs", Passed: false}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Overdue milestone with a promise that has no task.
+	mkdirAll(t, filepath.Join(dd, "milestone"))
+	writeFile(t, filepath.Join(dd, "milestone", "2026-03-01.md"), "## Ship it\n")
+	// Far-future milestone is not yet worth mentioning.
+	writeFile(t, filepath.Join(dd, "milestone", "2026-12-01.md"), "## Later\n")
+
+	recs, err := r.Next(now)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	var reasons []string
+	for _, rec := range recs {
+		reasons = append(reasons, rec.Reason)
+	}
+	want := []string{
+		"milestone 2026-03-01 is overdue",
+		"stuck is stuck in merge state",
+		"failing failed 1 review checklist item(s)",
+		"old is awaiting review (stale",
+		"fresh is awaiting review",
+		"todo is pending",
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("got %d recommendations, want %d:\n%s", len(recs), len(want), strings.Join(reasons, "\n"))
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(recs[i].Reason, w) {
+			t.Errorf("recommendation %d = %q, want prefix %q", i, recs[i].Reason, w)
+		}
+	}
+	if recs[1].Command != "hydra merge run stuck" {
+		t.Errorf("merge command = %q", recs[1].Command)
+	}
+}
+
+func TestNextReviewAgeFromRecord(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	dd := r.Design.Path
+	now := time.Now()
+
+	// A task written long ago and only just run, which moves it to review
+	// with its file's modification time.
+	path := filepath.Join(dd, "tasks", "backlog.md")
+	writeFile(t, path, "Backlog.")
+	mtime := now.Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	task, err := r.Design.FindTask("backlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Design.MoveTask(task, design.StateReview); err != nil {
+		t.Fatal(err)
+	}
+	if err := design.NewRecord(dd).AddEntry(design.RecordEntry{
+		SHA:      "abc",
+		TaskName: "backlog",
+		Time:     now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := r.Next(now)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Priority != 50 {
+		t.Errorf("recommendations = %+v, want backlog awaiting review for under a day", recs)
+	}
+}
+
+func TestNextMilestoneLocalDay(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	dd := r.Design.Path
+	mkdirAll(t, filepath.Join(dd, "milestone"))
+	writeFile(t, filepath.Join(dd, "milestone", "2026-03-10.md"), "## Ship it\n")
+
+	// Late evening west of UTC, when it is already the next day in UTC, the
+	// milestone is due today, not overdue.
+	now := time.Date(2026, 3, 10, 22, 0, 0, 0, time.FixedZone("PST", -8*60*60))
+	recs, err := r.Next(now)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(recs) != 1 || !strings.HasPrefix(recs[0].Reason, "milestone 2026-03-10 is due in 0 day(s)") {
+		t.Errorf("recommendations = %+v, want the milestone due today", recs)
+	}
+}
+
+func TestNextEmpty(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+
+	recs, err := r.Next(time.Now())
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(recs) != 0 {
+		t.Errorf("expected no recommendations, got %+v", recs)
+	}
+}
+
+func TestNextStaleAfter(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	dd := r.Design.Path
+	now := time.Now()
+
+	mkdirAll(t, filepath.Join(dd, "state", "review"))
+	path := filepath.Join(dd, "state", "review", "old.md")
+	writeFile(t, path, "Old.")
+	mtime := now.Add(-10 * 24 * time.Hour)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	r.TaskRunner = &taskrun.Commands{StaleAfter: &taskrun.Duration{Duration: 30 * 24 * time.Hour}}
+	recs, err := r.Next(now)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(recs) != 1 || strings.Contains(recs[0].Reason, "stale") {
+		t.Errorf("with stale_after 30d, recs = %+v, want old not stale", recs)
+	}
+
+	// A recent review counts as a change.
+	r.TaskRunner.StaleAfter.Duration = 5 * 24 * time.Hour
+	if err := r.Design.Record().AddEntry(design.RecordEntry{SHA: "abc", TaskName: "review:old", Time: now.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if recs, err = r.Next(now); err != nil || strings.Contains(recs[0].Reason, "stale") {
+		t.Errorf("after a recent review, recs = %+v, %v", recs, err)
+	}
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// noChangesMarker is the line Claude starts its final message with when it
+// concludes a task needs no changes, for example because it is already
+// implemented.
+const noChangesMarker = "HYDRA: NO CHANGES NEEDED"
+
+// noChangesSection returns a markdown section telling Claude how to report
+// a task that needs no changes.
+func noChangesSection() string {
+	return "\n\n# No Changes Needed\n\n" +
+		"If you conclude that the task is already fully implemented and nothing needs to change, " +
+		"do not make an empty or cosmetic commit. Instead, end the session with a final message whose " +
+		"first line is exactly `" + noChangesMarker + "`, followed by a short explanation of where and how " +
+		"the existing code already does what the task asks. Only do this when you are sure; if anything " +
+		"is missing, implement it.\n"
+}
+
+// noChangesReason looks for noChangesMarker on a line of its own in
+// Claude's final message. It returns the explanation that follows the
+// marker, or the text before it if nothing follows.
+func noChangesReason(message string) (reason string, ok bool) {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		if strings.Trim(line, " \t*_`#>") != noChangesMarker {
+			continue
+		}
+		reason = strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		if reason == "" {
+			reason = strings.TrimSpace(strings.Join(lines[:i], "\n"))
+		}
+		return reason, true
+	}
+	return "", false
+}
+
+// finishUnchanged moves a task whose run made no commits to review because
+// Claude reported that it needs no changes. Claude's explanation is left as
+// a comment for the review session, and the branch is pushed as it is so
+// the review has one to check out.
+func (r *Runner) finishUnchanged(task *design.Task, taskRepo repo.VCS, taskName, branch, reason string) error {
+	comment := "The run made no changes: Claude concluded the task needs none."
+	if reason != "" {
+		comment += "\n\n" + reason
+	}
+	if err := r.Design.AddComment(task, comment); err != nil {
+		return err
+	}
+	if err := taskRepo.Push(branch); err != nil {
+		return inCategory(categoryPush, fmt.Errorf("pushing: %w", err))
+	}
+	if err := r.Design.MoveTask(task, design.StateReview); err != nil {
+		return fmt.Errorf("moving task to review: %w", err)
+	}
+
+	fmt.Printf("Task %q needs no changes according to Claude; moved to review. Branch: %s\n", taskName, branch)
+	sha, _ := taskRepo.LastCommitSHA()
+	r.runHook(taskrun.HookAfterRun, taskRepo.WorkDir(), hookEvent{Action: "run", Task: taskName, State: design.StateReview, Branch: branch, SHA: sha})
+	return nil
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// notesDir holds the scratch file in a task's work directory where Claude
+// keeps notes for its later sessions on the task. It ignores itself through
+// a .gitignore of its own, which keeps the notes out of the task's commits
+// without touching git state shared with other checkouts.
+const notesDir = ".hydra-notes"
+
+// notesFile is the notes file, relative to the work directory.
+const notesFile = notesDir + "/notes.md"
+
+// readNotes returns the notes left in a work directory, or "" if there are
+// none.
+func readNotes(wd string) string {
+	data, err := os.ReadFile(filepath.Join(wd, notesFile)) //nolint:gosec // path is constructed from our own work dir
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Warning: reading %s: %v\n", notesFile, err)
+		}
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// notesSection returns the notes from earlier sessions on the task, if any,
+// and asks Claude to keep them up to date for the next session.
+func notesSection(notes string) string {
+	var b strings.Builder
+	if notes != "" {
+		b.WriteString("\n\n# Previous Session Notes\n\n")
+		b.WriteString("Earlier sessions on this task left these notes. Use them instead of rediscovering " +
+			"the same context:\n\n")
+		b.WriteString(notes + "\n")
+	}
+	b.WriteString("\n\n# Session Notes\n\n")
+	b.WriteString("Before you finish, update `" + notesFile + "` in the repository root with what a later " +
+		"session on this task should know: decisions made and why, approaches that failed, open " +
+		"questions, and anything left to do. Keep it short, and rewrite stale notes rather than " +
+		"appending forever. Do NOT commit `" + notesFile + "`.\n")
+	return b.String()
+}
+
+// prepareNotes keeps the notes file out of the task's commits and returns
+// the document section for the session's notes.
+func prepareNotes(wd string) string {
+	ignore := filepath.Join(wd, notesDir, ".gitignore")
+	if err := os.MkdirAll(filepath.Dir(ignore), 0o750); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not create %s: %v\n", notesDir, err)
+	} else if err := os.WriteFile(ignore, []byte("*\n"), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not exclude %s from git: %v\n", notesDir, err)
+	}
+	return notesSection(readNotes(wd))
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+func TestNotesSection(t *testing.T) {
+	wd := t.TempDir()
+	if notes := readNotes(wd); notes != "" {
+		t.Errorf("readNotes with no file = %q", notes)
+	}
+
+	section := notesSection("")
+	if strings.Contains(section, "# Previous Session Notes") {
+		t.Error("notes section has previous notes when there are none")
+	}
+	if !strings.Contains(section, "# Session Notes") || !strings.Contains(section, notesFile) {
+		t.Errorf("notes section missing update instructions:\n%s", section)
+	}
+
+	mkdirAll(t, filepath.Join(wd, notesDir))
+	writeFile(t, filepath.Join(wd, notesFile), "\nThe parser rejects tabs; see lexer.go.\n\n")
+	notes := readNotes(wd)
+	if notes != "The parser rejects tabs; see lexer.go." {
+		t.Errorf("readNotes = %q", notes)
+	}
+	section = notesSection(notes)
+	prev := strings.Index(section, "# Previous Session Notes")
+	if prev < 0 || !strings.Contains(section[prev:], notes) {
+		t.Errorf("notes section missing previous notes:\n%s", section)
+	}
+	if strings.Index(section, "# Session Notes") < prev {
+		t.Errorf("update instructions come before the previous notes:\n%s", section)
+	}
+}
+
+func TestPrepareNotesIgnored(t *testing.T) {
+	wd := t.TempDir()
+	gitRun(t, "init", "-q", wd)
+	gitRun(t, "-C", wd, "-c", "user.name=test", "-c", "user.email=test@test", "commit", "-q", "--allow-empty", "-m", "init")
+
+	prepareNotes(wd)
+	writeFile(t, filepath.Join(wd, notesFile), "Remember the lexer.\n")
+	if dirty, err := repo.Open(wd).HasChanges(); err != nil || dirty {
+		t.Errorf("HasChanges with session notes = %v, %v; want false", dirty, err)
+	}
+	if _, err := os.Stat(filepath.Join(wd, ".git", "info", "exclude")); err == nil {
+		data, _ := os.ReadFile(filepath.Join(wd, ".git", "info", "exclude")) //nolint:gosec // test
+		if strings.Contains(string(data), notesDir) {
+			t.Errorf("notes excluded through the shared info/exclude:\n%s", data)
+		}
+	}
+}
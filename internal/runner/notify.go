@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/erikh/hydra/internal/notify"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// NotifyResult is the outcome of sending a notification to one channel.
+type NotifyResult struct {
+	Channel string
+	Err     error
+}
+
+// notifications returns the notification settings from hydra.yml, or nil.
+func (r *Runner) notifications() *taskrun.Notifications {
+	if r.TaskRunner == nil {
+		return nil
+	}
+	return r.TaskRunner.Notifications
+}
+
+// NotifyChannels returns the notification channels that can be sent to.
+func (r *Runner) NotifyChannels() []string {
+	return r.notifications().Configured()
+}
+
+// SendNotification sends a notification to each of channels, or to the
+// default channels from hydra.yml if none are given, and reports how each
+// went. A channel that is unknown or has no URL configured fails.
+func (r *Runner) SendNotification(ctx context.Context, channels []string, title, message string) []NotifyResult {
+	return sendNotification(ctx, r.commands(), channels, title, message)
+}
+
+// sendNotification implements SendNotification with cmds, hydra.yml's
+// commands, which may be nil.
+func sendNotification(ctx context.Context, cmds *taskrun.Commands, channels []string, title, message string) []NotifyResult {
+	var n *taskrun.Notifications
+	if cmds != nil {
+		n = cmds.Notifications
+	}
+	if len(channels) == 0 {
+		channels = n.Default()
+	}
+
+	results := make([]NotifyResult, 0, len(channels))
+	for _, ch := range channels {
+		var err error
+		switch {
+		case !slices.Contains(notify.Channels, ch):
+			err = fmt.Errorf("unknown channel (want one of %v)", notify.Channels)
+		case !slices.Contains(n.Configured(), ch):
+			err = fmt.Errorf("no %s URL configured under notifications in hydra.yml", ch)
+		case ch == notify.ChannelSlack:
+			err = notify.SendSlack(ctx, n.Slack, title, message)
+		case ch == notify.ChannelWebhook:
+			err = notify.SendWebhook(ctx, n.Webhook, title, message)
+		default:
+			err = sendDesktop(cmds, title, message)
+		}
+		results = append(results, NotifyResult{Channel: ch, Err: err})
+	}
+	return results
+}
+
+// sendDesktop runs the notify command from hydra.yml, or sends a native
+// desktop notification if there is none.
+func sendDesktop(cmds *taskrun.Commands, title, message string) error {
+	if cmds != nil {
+		if handled, err := cmds.RunNotify(title, message); handled {
+			return err
+		}
+	}
+	return notify.Send(title, message)
+}
@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestSendNotificationRouting(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { hits++ }))
+	defer srv.Close()
+
+	r := &Runner{TaskRunner: &taskrun.Commands{
+		Notify:        "true",
+		Notifications: &taskrun.Notifications{Webhook: srv.URL},
+	}}
+
+	results := r.SendNotification(context.Background(), []string{"desktop", "webhook", "slack", "pager"}, "hydra", "hello")
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for i, wantOK := range []bool{true, true, false, false} {
+		if (results[i].Err == nil) != wantOK {
+			t.Errorf("%s: err = %v, want ok %v", results[i].Channel, results[i].Err, wantOK)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("webhook hit %d times, want 1", hits)
+	}
+
+	// Without channels, every configured channel is used.
+	results = r.SendNotification(context.Background(), nil, "hydra", "hello")
+	if len(results) != 2 || results[0].Channel != "desktop" || results[1].Channel != "webhook" {
+		t.Errorf("default channels = %+v", results)
+	}
+}
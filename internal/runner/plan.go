@@ -0,0 +1,215 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// planResultFile is where Claude writes its approved plan in the work directory.
+const planResultFile = "hydra-plan.md"
+
+// planPath returns where the saved plan for a task lives:
+// .hydra/plans/{name}.md, or .hydra/plans/{group}/{name}.md for grouped tasks.
+func planPath(hydraDir string, task *design.Task) string {
+	if task.Group != "" {
+		return filepath.Join(hydraDir, "plans", task.Group, task.Name+".md")
+	}
+	return filepath.Join(hydraDir, "plans", task.Name+".md")
+}
+
+// planCaptureSection returns a markdown section asking Claude to record the
+// approved plan so hydra can save it.
+func planCaptureSection() string {
+	return "\n\n# Plan Record\n\n" +
+		"As soon as your plan is approved, and before you change any code, write the approved plan " +
+		"as markdown to `" + planResultFile + "` in the repository root. " +
+		"Do NOT commit `" + planResultFile + "`.\n"
+}
+
+// planOnlySection returns the instructions for a planning-only session.
+func planOnlySection() string {
+	return "\n\n# Planning Only\n\n" +
+		"This is a planning session. Do NOT implement the task, and do NOT modify, create, " +
+		"stage, or commit any file other than `" + planResultFile + "`.\n\n" +
+		"1. Read the relevant code and design a step-by-step implementation plan for the task above\n" +
+		"2. Present the plan for approval\n" +
+		"3. Once approved, write the plan as markdown to `" + planResultFile + "` in the repository root\n" +
+		"4. Stop\n\n" +
+		"The plan will be reviewed, possibly edited, and executed in a later session, so make it " +
+		"specific enough to follow without this conversation: list the files to change, the " +
+		"changes to make, and the tests to add.\n"
+}
+
+// approvedPlanSection returns a markdown section containing a previously
+// approved plan for Claude to execute.
+func approvedPlanSection(plan string) string {
+	return "\n\n# Approved Plan\n\n" +
+		"The following plan has already been reviewed and approved. Implement it as written. " +
+		"Do not enter plan mode or re-plan; if part of the plan turns out to be impossible, " +
+		"follow its intent as closely as you can and explain the deviation in your commit message.\n\n" +
+		strings.TrimSpace(plan) + "\n"
+}
+
+// loadPlan reads the saved plan for a task.
+func loadPlan(hydraDir string, task *design.Task, taskName string) (string, error) {
+	data, err := os.ReadFile(planPath(hydraDir, task)) //nolint:gosec // path is constructed from the hydra dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("no saved plan for %q; run 'hydra plan %s' first", taskName, taskName)
+		}
+		return "", fmt.Errorf("reading plan: %w", err)
+	}
+	return string(data), nil
+}
+
+// collectPlan moves the plan Claude wrote in the work directory to dest.
+// It returns false if Claude did not write one.
+func collectPlan(wd, dest string) (bool, error) {
+	src := filepath.Join(wd, planResultFile)
+	data, err := os.ReadFile(src) //nolint:gosec // path is constructed from our own work dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading %s: %w", planResultFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return false, fmt.Errorf("creating plans directory: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0o600); err != nil {
+		return false, fmt.Errorf("saving plan: %w", err)
+	}
+	if err := os.Remove(src); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: removing %s: %v\n", planResultFile, err)
+	}
+
+	fmt.Printf("Plan saved to %s\n", dest)
+	return true, nil
+}
+
+// Plan runs only the planning phase of a pending task. Claude designs a plan,
+// and once it is approved the plan is saved to .hydra/plans/ for review and
+// editing. The task stays pending; run it later with UsePlan to execute the
+// saved plan.
+func (r *Runner) Plan(taskName string) error {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	hydraDir := config.HydraPath(baseDir)
+
+	task, err := r.Design.FindTask(taskName)
+	if err != nil {
+		return err
+	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+
+	// Planning takes the task's run lock: a plan and a run of the same task
+	// would share its work directory and its saved plan.
+	lk := lock.New(hydraDir, taskName)
+	if err := lk.Acquire(); err != nil {
+		return err
+	}
+	defer func() { _ = lk.Release() }()
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
+
+	wd := r.workDir(task)
+	branch := task.BranchName()
+	taskRepo, err := r.prepareRepo(wd, branch)
+	if err != nil {
+		return fmt.Errorf("preparing work directory: %w", err)
+	}
+	if err := r.ensureBranch(taskRepo, branch); err != nil {
+		return err
+	}
+
+	if err := r.planSession(context.Background(), task, taskName, taskRepo, hydraDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("Review or edit the plan, then run: hydra run --use-plan %s\n", taskName)
+	return nil
+}
+
+// planSession runs a planning-only Claude session for task in taskRepo and
+// saves the plan it writes to .hydra/plans/.
+func (r *Runner) planSession(ctx context.Context, task *design.Task, taskName string, taskRepo repo.VCS, hydraDir string) error {
+	content, err := task.Content()
+	if err != nil {
+		return err
+	}
+	groupContent, err := r.Design.GroupContent(task.Group)
+	if err != nil {
+		return fmt.Errorf("reading group content: %w", err)
+	}
+	doc, err := r.Design.AssembleDocument(content, groupContent)
+	if err != nil {
+		return fmt.Errorf("assembling document: %w", err)
+	}
+
+	l := r.locale()
+	data := sectionData{Timeout: r.timeout().String(), NotifyTitle: r.notifyTitle(taskName)}
+	doc += planOnlySection()
+	doc += l.section(sectionTimeout, data, timeoutSection(r.timeout()))
+	if r.Notify {
+		doc += l.section(sectionNotification, data, notificationSection(data.NotifyTitle))
+	}
+	doc += l.section(sectionReminder, data, missionReminder())
+	if !r.Headless {
+		doc += l.section(sectionPlanMode, data, planModeInstruction)
+	}
+
+	wd := taskRepo.WorkDir()
+	if err := r.runBeforeHook(wd); err != nil {
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
+	}
+
+	claudeFn := r.Claude
+	if claudeFn == nil {
+		claudeFn = invokeClaude
+	}
+	runCfg := ClaudeRunConfig{
+		RepoDir:    taskRepo.WorkDir(),
+		Shell:      r.toolShell(taskRepo.WorkDir()),
+		Output:     &r.output,
+		State:      r.stateTool(task),
+		Document:   doc,
+		Model:      r.Model,
+		Fallbacks:  r.modelFallbacks(),
+		Approval:   r.approvalRules(),
+		AutoAccept: r.AutoAccept,
+		PlanMode:   !r.Headless,
+		ForceTUI:   r.ForceTUI,
+		Headless:   r.Headless,
+		TaskName:   "plan:" + taskName,
+		HydraDir:   hydraDir,
+		Timeout:    r.timeout(),
+		WrapUp:     r.wrapUp(),
+	}
+	if err := runClaude(ctx, claudeFn, runCfg); err != nil {
+		return err
+	}
+
+	saved, err := collectPlan(wd, planPath(hydraDir, task))
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return fmt.Errorf("claude did not write %s; no plan was saved", planResultFile)
+	}
+	return nil
+}
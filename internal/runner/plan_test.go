@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+func TestPlanPath(t *testing.T) {
+	if got := planPath("/h", &design.Task{Name: "a"}); got != filepath.Join("/h", "plans", "a.md") {
+		t.Errorf("planPath = %q", got)
+	}
+	if got := planPath("/h", &design.Task{Name: "a", Group: "g"}); got != filepath.Join("/h", "plans", "g", "a.md") {
+		t.Errorf("grouped planPath = %q", got)
+	}
+}
+
+func TestCollectAndLoadPlan(t *testing.T) {
+	wd := t.TempDir()
+	hydraDir := t.TempDir()
+	task := &design.Task{Name: "add-auth", Group: "backend"}
+	dest := planPath(hydraDir, task)
+
+	saved, err := collectPlan(wd, dest)
+	if err != nil || saved {
+		t.Fatalf("collectPlan without a plan = %v, %v; want false, nil", saved, err)
+	}
+	if _, err := loadPlan(hydraDir, task, "backend/add-auth"); err == nil || !strings.Contains(err.Error(), "hydra plan backend/add-auth") {
+		t.Errorf("loadPlan without a plan: %v", err)
+	}
+
+	writeFile(t, filepath.Join(wd, planResultFile), "1. Add middleware\n2. Add tests\n")
+	saved, err = collectPlan(wd, dest)
+	if err != nil || !saved {
+		t.Fatalf("collectPlan = %v, %v; want true, nil", saved, err)
+	}
+	if _, err := os.Stat(filepath.Join(wd, planResultFile)); !os.IsNotExist(err) {
+		t.Error("plan file should be removed from the work directory")
+	}
+
+	plan, err := loadPlan(hydraDir, task, "backend/add-auth")
+	if err != nil {
+		t.Fatalf("loadPlan: %v", err)
+	}
+	if !strings.Contains(plan, "Add middleware") {
+		t.Errorf("plan = %q", plan)
+	}
+}
+
+func TestPlanDocumentSections(t *testing.T) {
+	section := approvedPlanSection("1. Do the thing\n")
+	if !strings.Contains(section, "# Approved Plan") || !strings.Contains(section, "1. Do the thing") {
+		t.Errorf("approved plan section:\n%s", section)
+	}
+	if !strings.Contains(planCaptureSection(), planResultFile) {
+		t.Error("capture section should name the plan file")
+	}
+	if !strings.Contains(planOnlySection(), "Do NOT implement") {
+		t.Error("plan-only section should forbid implementation")
+	}
+
+	if strings.Contains(documentSuffix(suffixOpts{SkipPlanMode: true}), planModeInstruction) {
+		t.Error("SkipPlanMode should omit the plan mode request")
+	}
+	if !strings.Contains(documentSuffix(suffixOpts{}), planModeInstruction) {
+		t.Error("suffix should request plan mode by default")
+	}
+}
+
+func TestPlanWhileRunning(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+
+	lk := lock.New(filepath.Join(env.BaseDir, ".hydra"), "add-feature")
+	if err := lk.Acquire(); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer func() { _ = lk.Release() }()
+
+	if err := r.Plan("add-feature"); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("Plan during a run: err = %v", err)
+	}
+}
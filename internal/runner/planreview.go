@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+)
+
+// errPlanRejected is returned by a run whose plan was turned down during a
+// plan handoff.
+var errPlanRejected = errors.New("plan rejected")
+
+// approvalRejected is the content of a plan review file once the plan is
+// rejected.
+const approvalRejected = "rejected\n"
+
+// planReviewPath returns the file through which hydra plan approve and
+// reject answer a run waiting for its plan to be reviewed.
+func planReviewPath(hydraDir string, task *design.Task) string {
+	if task.Group != "" {
+		return filepath.Join(hydraDir, "plan-reviews", task.Group, task.Name)
+	}
+	return filepath.Join(hydraDir, "plan-reviews", task.Name)
+}
+
+// planHandoff reports whether runs hand their plan to a human before
+// executing it, from PlanHandoff or plan_handoff in hydra.yml.
+func (r *Runner) planHandoff() bool {
+	return r.PlanHandoff || (r.TaskRunner != nil && r.TaskRunner.PlanHandoff)
+}
+
+// awaitPlanReview waits until the plan saved for task is approved or
+// rejected, at the prompt or by hydra plan approve or reject from another
+// terminal, after sending a notification that it is ready. The plan may be
+// edited in the meantime with hydra plan edit. It returns errPlanRejected
+// if the plan is turned down.
+func (r *Runner) awaitPlanReview(ctx context.Context, hydraDir string, task *design.Task) error {
+	path := planReviewPath(hydraDir, task)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating plan reviews dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(approvalWaiting), 0o600); err != nil {
+		return fmt.Errorf("writing plan review file: %w", err)
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	label := taskLabel(*task)
+	if r.Notify {
+		for _, res := range r.SendNotification(ctx, nil, r.notifyTitle(label), "Plan ready for review: hydra plan approve "+label) {
+			if res.Err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s notification failed: %v\n", res.Channel, res.Err)
+			}
+		}
+	}
+
+	fmt.Printf("\nPlan saved to %s. Edit it with 'hydra plan edit %s'.\n", planPath(hydraDir, task), label)
+	ok, fromFile, err := question{
+		prompt:  fmt.Sprintf("Execute the plan? Answer here or run 'hydra plan approve %s' or 'hydra plan reject %s'. [y/N] ", label, label),
+		waiting: fmt.Sprintf("Waiting for 'hydra plan approve %s' or 'hydra plan reject %s'.", label, label),
+		path:    path,
+		answers: map[string]bool{approvalApproved: true, approvalRejected: false},
+	}.await(ctx, !r.Headless)
+	switch {
+	case err != nil:
+		return err
+	case !ok && fromFile:
+		return fmt.Errorf("%w by hydra plan reject; the task stays pending and the plan is kept", errPlanRejected)
+	case !ok:
+		return fmt.Errorf("%w; the task stays pending and the plan is kept", errPlanRejected)
+	case fromFile:
+		fmt.Println("\nPlan approved by hydra plan approve.")
+	}
+	return nil
+}
+
+// answerPlanReview answers the plan review of a run waiting on taskName's
+// plan.
+func (r *Runner) answerPlanReview(taskName, answer string) error {
+	task, err := r.Design.FindTask(taskName)
+	if err != nil {
+		return err
+	}
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	path := planReviewPath(config.HydraPath(baseDir), task)
+	data, err := os.ReadFile(path) //nolint:gosec // path inside .hydra
+	if err != nil || string(data) != approvalWaiting {
+		return fmt.Errorf("no run of %q is waiting for its plan to be reviewed", taskName)
+	}
+	if err := os.WriteFile(path, []byte(answer), 0o600); err != nil {
+		return fmt.Errorf("writing plan review file: %w", err)
+	}
+	return nil
+}
+
+// ApprovePlan lets a run waiting on the task's plan go on to execute it.
+func (r *Runner) ApprovePlan(taskName string) error {
+	if err := r.answerPlanReview(taskName, approvalApproved); err != nil {
+		return err
+	}
+	fmt.Printf("Approved the plan of %q.\n", taskName)
+	return nil
+}
+
+// RejectPlan stops a run waiting on the task's plan. The task stays
+// pending.
+func (r *Runner) RejectPlan(taskName string) error {
+	if err := r.answerPlanReview(taskName, approvalRejected); err != nil {
+		return err
+	}
+	fmt.Printf("Rejected the plan of %q.\n", taskName)
+	return nil
+}
+
+// PlanPath returns the file holding the saved plan of a pending task.
+func (r *Runner) PlanPath(taskName string) (string, error) {
+	task, err := r.Design.FindTask(taskName)
+	if err != nil {
+		return "", err
+	}
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	path := planPath(config.HydraPath(baseDir), task)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no saved plan for %q; run 'hydra plan %s' first", taskName, taskName)
+	}
+	return path, nil
+}
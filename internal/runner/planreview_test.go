@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+)
+
+func TestAwaitPlanReview(t *testing.T) {
+	approvalPoll = 10 * time.Millisecond
+	t.Cleanup(func() { approvalPoll = time.Second })
+
+	tests := []struct {
+		name    string
+		answer  func(r *Runner) error
+		wantErr error
+	}{
+		{"approve", func(r *Runner) error { return r.ApprovePlan("add-feature") }, nil},
+		{"reject", func(r *Runner) error { return r.RejectPlan("add-feature") }, errPlanRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := stubRunner(t)
+			r.BaseDir = t.TempDir()
+			hydraDir := config.HydraPath(r.BaseDir)
+			writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add it.\n")
+			task, err := r.Design.FindTask("add-feature")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.answer(r); err == nil {
+				t.Fatal("answered a plan review with no run waiting")
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- r.awaitPlanReview(context.Background(), hydraDir, task) }()
+
+			path := planReviewPath(hydraDir, task)
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+				if _, err := os.Stat(path); err == nil {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("run never started waiting for plan review")
+				}
+			}
+			if err := tt.answer(r); err != nil {
+				t.Fatalf("answering plan review: %v", err)
+			}
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+					t.Fatalf("awaitPlanReview = %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("awaitPlanReview did not return after the answer")
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("plan review file left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlanPathForEdit(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "Add it.\n")
+	if _, err := r.PlanPath("add-feature"); err == nil {
+		t.Fatal("PlanPath succeeded with no saved plan")
+	}
+
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := planPath(config.HydraPath(r.BaseDir), task)
+	if err := os.MkdirAll(filepath.Dir(want), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, want, "1. Add it\n")
+	got, err := r.PlanPath("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("PlanPath = %q, want %q", got, want)
+	}
+}
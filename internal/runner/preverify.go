@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// preVerifyOutputLines is how much of a failing command's output is passed
+// on to Claude.
+const preVerifyOutputLines = 100
+
+// lintFixMessage is the commit message for changes made by lint_fix.
+const lintFixMessage = "Apply automatic lint fixes"
+
+// preVerifyResult classifies the test and lint run hydra does itself after
+// rebasing a task for merge.
+type preVerifyResult struct {
+	Ran       bool
+	Flaky     bool // the test command failed, then passed on a retry
+	LintFixed bool // lint failed, then passed after running lint_fix
+	Failures  []commandFailure
+}
+
+// Passed reports whether verification ran and nothing is left failing.
+func (p preVerifyResult) Passed() bool {
+	return p.Ran && len(p.Failures) == 0
+}
+
+// commandFailure is a command that still fails after the deterministic
+// fixes were tried.
+type commandFailure struct {
+	Name     string
+	Attempts int
+	Output   string // tail of the last attempt's output
+}
+
+// preVerify runs the lint and test commands in the task's work directory
+// and attempts the cheap fixes before a Claude session is considered: a
+// failing lint is retried after running lint_fix, whose changes are
+// committed, and a failing test command is rerun up to verify.flaky_retries
+// times. Returns a zero result if verification isn't configured.
+func (r *Runner) preVerify(taskRepo repo.VCS, wd string, sign bool) (preVerifyResult, error) {
+	var result preVerifyResult
+	if r.TaskRunner == nil || r.TaskRunner.Verify == nil {
+		return result, nil
+	}
+	hasLint := r.TaskRunner.HasCommand("lint", wd)
+	hasTest := r.TaskRunner.HasCommand("test", wd)
+	if !hasLint && !hasTest {
+		return result, nil
+	}
+	result.Ran = true
+
+	// The first attempts of lint and test are independent, so they run
+	// together unless serial_checks is set.
+	var names []string
+	if hasLint {
+		names = append(names, "lint")
+	}
+	if hasTest {
+		names = append(names, "test")
+	}
+	cmds := r.commands().WithContext(taskRepo.Context())
+	fmt.Printf("Running %s...\n", strings.Join(names, " and "))
+	first := make(map[string]taskrun.Result, len(names))
+	for _, res := range cmds.OutputAll(names, wd) {
+		first[res.Name] = res
+	}
+
+	if hasLint {
+		out, err := first["lint"].Output, first["lint"].Err
+		attempts := 1
+		if err != nil && r.TaskRunner.HasCommand("lint_fix", wd) {
+			fmt.Println("Lint failed; running lint_fix...")
+			if fixErr := cmds.Run("lint_fix", wd); fixErr != nil {
+				fmt.Printf("lint_fix failed: %v\n", fixErr)
+			} else {
+				if err := commitLintFixes(taskRepo, sign); err != nil {
+					return result, err
+				}
+				attempts++
+				out, err = cmds.Output("lint", wd)
+				result.LintFixed = err == nil
+			}
+		}
+		if err != nil {
+			result.Failures = append(result.Failures, commandFailure{Name: "lint", Attempts: attempts, Output: tailLines(out, preVerifyOutputLines)})
+		}
+	}
+
+	if hasTest {
+		retries := max(r.TaskRunner.Verify.FlakyRetries, 0)
+		out, err := first["test"].Output, first["test"].Err
+		attempts := 1
+		for err != nil && attempts <= retries {
+			attempts++
+			fmt.Printf("Tests failed; retrying (attempt %d/%d)...\n", attempts, retries+1)
+			out, err = cmds.Output("test", wd)
+		}
+		if err != nil {
+			result.Failures = append(result.Failures, commandFailure{Name: "test", Attempts: attempts, Output: tailLines(out, preVerifyOutputLines)})
+		} else if attempts > 1 {
+			result.Flaky = true
+		}
+	}
+
+	if result.Passed() {
+		fmt.Println("Verification passed.")
+	}
+	for _, f := range result.Failures {
+		fmt.Printf("Verification: %s failed after %d attempt(s).\n", f.Name, f.Attempts)
+	}
+	return result, nil
+}
+
+// commitLintFixes commits the changes lint_fix made, if any.
+func commitLintFixes(taskRepo repo.VCS, sign bool) error {
+	changed, err := taskRepo.HasChanges()
+	if err != nil {
+		return fmt.Errorf("checking lint_fix changes: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+	if err := taskRepo.AddAll(); err != nil {
+		return fmt.Errorf("staging lint fixes: %w", err)
+	}
+	if err := taskRepo.Commit(lintFixMessage, sign); err != nil {
+		return fmt.Errorf("committing lint fixes: %w", err)
+	}
+	fmt.Println("Committed lint fixes.")
+	return nil
+}
+
+// preVerifySection returns a markdown section telling Claude what hydra's
+// own verification found. Returns empty string if verification didn't run.
+func preVerifySection(result preVerifyResult) string {
+	if !result.Ran {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Verification Results\n\n")
+	b.WriteString("Hydra already ran the lint and test commands after rebasing.\n\n")
+	if result.LintFixed {
+		b.WriteString("- Lint failed at first and was fixed by the lint_fix command; the fixes are committed as \"" +
+			lintFixMessage + "\".\n")
+	}
+	if result.Flaky {
+		b.WriteString("- The tests failed at first and passed on a retry, so at least one test is likely flaky. " +
+			"If you can identify it, make it deterministic.\n")
+	}
+	if len(result.Failures) == 0 {
+		b.WriteString("- Lint and tests pass.\n\n")
+		return b.String()
+	}
+	for _, f := range result.Failures {
+		fmt.Fprintf(&b, "- `%s` still fails after %d attempt(s). Fix the cause. The end of its output:\n\n", f.Name, f.Attempts)
+		b.WriteString("```\n" + f.Output + "\n```\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// initPreVerifyRepo creates a git repo with one commit for preVerify to run in.
+func initPreVerifyRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	gitRun(t, "init", dir)
+	gitRun(t, "-C", dir, "config", "user.email", "test@test.com")
+	gitRun(t, "-C", dir, "config", "user.name", "Test")
+	gitRun(t, "-C", dir, "config", "commit.gpgsign", "false")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Test")
+	gitRun(t, "-C", dir, "add", "-A")
+	gitRun(t, "-C", dir, "commit", "-m", "initial")
+	return dir
+}
+
+func TestPreVerifyNotConfigured(t *testing.T) {
+	wd := initPreVerifyRepo(t)
+	r := &Runner{TaskRunner: &taskrun.Commands{Commands: map[string]string{"test": "false"}}}
+
+	result, err := r.preVerify(repo.Open(wd), wd, false)
+	if err != nil {
+		t.Fatalf("preVerify: %v", err)
+	}
+	if result.Ran {
+		t.Error("preVerify should not run without a verify section")
+	}
+}
+
+func TestPreVerifyFlakyTests(t *testing.T) {
+	wd := initPreVerifyRepo(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	r := &Runner{TaskRunner: &taskrun.Commands{
+		Commands: map[string]string{"test": "test -f " + marker + " || { touch " + marker + "; exit 1; }"},
+		Verify:   &taskrun.Verify{FlakyRetries: 2},
+	}}
+
+	result, err := r.preVerify(repo.Open(wd), wd, false)
+	if err != nil {
+		t.Fatalf("preVerify: %v", err)
+	}
+	if !result.Passed() || !result.Flaky {
+		t.Errorf("result = %+v, want passed and flaky", result)
+	}
+}
+
+func TestPreVerifyPersistentFailure(t *testing.T) {
+	wd := initPreVerifyRepo(t)
+	r := &Runner{TaskRunner: &taskrun.Commands{
+		Commands: map[string]string{"test": "echo boom; exit 1"},
+		Verify:   &taskrun.Verify{FlakyRetries: 2},
+	}}
+
+	result, err := r.preVerify(repo.Open(wd), wd, false)
+	if err != nil {
+		t.Fatalf("preVerify: %v", err)
+	}
+	if result.Passed() || len(result.Failures) != 1 {
+		t.Fatalf("result = %+v, want one failure", result)
+	}
+	f := result.Failures[0]
+	if f.Name != "test" || f.Attempts != 3 || f.Output != "boom" {
+		t.Errorf("failure = %+v, want test failing 3 times with output boom", f)
+	}
+}
+
+func TestPreVerifyLintFix(t *testing.T) {
+	wd := initPreVerifyRepo(t)
+	r := &Runner{TaskRunner: &taskrun.Commands{
+		Commands: map[string]string{
+			"lint":     "test -f fixed.txt",
+			"lint_fix": "touch fixed.txt",
+			"test":     "true",
+		},
+		Verify: &taskrun.Verify{},
+	}}
+
+	taskRepo := repo.Open(wd)
+	result, err := r.preVerify(taskRepo, wd, false)
+	if err != nil {
+		t.Fatalf("preVerify: %v", err)
+	}
+	if !result.Passed() || !result.LintFixed {
+		t.Errorf("result = %+v, want passed and lint fixed", result)
+	}
+
+	subjects, err := taskRepo.CommitSubjects("HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("CommitSubjects: %v", err)
+	}
+	if len(subjects) != 1 || subjects[0] != lintFixMessage {
+		t.Errorf("commits = %v, want the lint fix commit", subjects)
+	}
+	if dirty, _ := taskRepo.HasChanges(); dirty {
+		t.Error("lint fixes should be committed")
+	}
+}
+
+func TestPreVerifySection(t *testing.T) {
+	if s := preVerifySection(preVerifyResult{}); s != "" {
+		t.Errorf("section without a run = %q, want empty", s)
+	}
+
+	s := preVerifySection(preVerifyResult{
+		Ran:       true,
+		LintFixed: true,
+		Failures:  []commandFailure{{Name: "test", Attempts: 3, Output: "FAIL: TestThing"}},
+	})
+	for _, want := range []string{"## Verification Results", "lint_fix", "`test` still fails after 3 attempt(s)", "FAIL: TestThing"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("section missing %q:\n%s", want, s)
+		}
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := tailLines("a\nb\nc\n", 2); got != "b\nc" {
+		t.Errorf("tailLines = %q, want %q", got, "b\nc")
+	}
+	if got := tailLines("a\n", 5); got != "a" {
+		t.Errorf("tailLines = %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// groupProgress reports overall progress through a batch of tasks (hydra
+// group run, hydra group merge) so long batches show how far along they are.
+type groupProgress struct {
+	out     io.Writer
+	total   int
+	done    int
+	current string // task being worked on
+	start   time.Time
+	perTask time.Duration // historical average used for the ETA before any task completes
+	now     func() time.Time
+
+	mu sync.Mutex // guards done and current, which heartbeats read
+}
+
+// newGroupProgress returns a progress reporter for total tasks. perTask seeds
+// the ETA before the first task finishes; zero means no ETA until then.
+func newGroupProgress(out io.Writer, total int, perTask time.Duration) *groupProgress {
+	return &groupProgress{
+		out:     out,
+		total:   total,
+		start:   time.Now(),
+		perTask: perTask,
+		now:     time.Now,
+	}
+}
+
+// eta returns the projected time remaining, or zero if it cannot be estimated.
+func (p *groupProgress) eta() time.Duration {
+	avg := p.perTask
+	if p.done > 0 {
+		avg = p.now().Sub(p.start) / time.Duration(p.done)
+	}
+	return avg * time.Duration(p.total-p.done)
+}
+
+// line formats the progress line shown before the next task starts.
+func (p *groupProgress) line(taskRef string) string {
+	s := fmt.Sprintf("[%d/%d] %s — elapsed %s", p.done+1, p.total, taskRef,
+		p.now().Sub(p.start).Round(time.Second))
+	if eta := p.eta(); eta > 0 {
+		s += fmt.Sprintf(", ETA ~%s", eta.Round(time.Second))
+	}
+	return s
+}
+
+// begin prints the progress line for the next task.
+func (p *groupProgress) begin(taskRef string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = taskRef
+	_, _ = fmt.Fprintln(p.out, p.line(taskRef))
+}
+
+// finish marks the current task complete and prints a status line.
+func (p *groupProgress) finish(taskRef string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.current = ""
+	_, _ = fmt.Fprintf(p.out, "[%d/%d] %s done — elapsed %s\n", p.done, p.total, taskRef,
+		p.now().Sub(p.start).Round(time.Second))
+}
+
+// summary describes how far along the batch is, for heartbeat
+// notifications.
+func (p *groupProgress) summary() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := fmt.Sprintf("%d of %d tasks done, %d remaining.", p.done, p.total, p.total-p.done)
+	if p.current != "" {
+		s += " Working on " + p.current + "."
+	}
+	s += fmt.Sprintf(" Elapsed %s", p.now().Sub(p.start).Round(time.Second))
+	if eta := p.eta(); eta > 0 {
+		s += fmt.Sprintf(", ETA ~%s", eta.Round(time.Second))
+	}
+	return s + "."
+}
+
+// batchResult is the outcome of one task in a batch run.
+type batchResult struct {
+	task    string
+	err     error
+	elapsed time.Duration
+}
+
+// printBatchSummary prints one line per task and a totals line, and returns
+// the number of tasks that failed.
+func printBatchSummary(out io.Writer, results []batchResult) int {
+	failed := 0
+	_, _ = fmt.Fprintln(out, "\nSummary:")
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+			_, _ = fmt.Fprintf(out, "  FAILED  %s (%s): %v\n", res.task, res.elapsed.Round(time.Second), res.err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "  ok      %s (%s)\n", res.task, res.elapsed.Round(time.Second))
+	}
+	_, _ = fmt.Fprintf(out, "%d task(s): %d succeeded, %d failed\n", len(results), len(results)-failed, failed)
+	return failed
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupProgress(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+
+	p := newGroupProgress(&buf, 3, 0)
+	p.start = start
+	p.now = func() time.Time { return clock }
+
+	// No history and nothing finished yet: no ETA.
+	if got := p.line("g/a"); got != "[1/3] g/a — elapsed 0s" {
+		t.Errorf("first line = %q", got)
+	}
+
+	clock = start.Add(10 * time.Minute)
+	p.finish("g/a")
+	if got := p.line("g/b"); got != "[2/3] g/b — elapsed 10m0s, ETA ~20m0s" {
+		t.Errorf("second line = %q", got)
+	}
+
+	p.begin("g/b")
+	if !strings.Contains(buf.String(), "[1/3] g/a done — elapsed 10m0s") {
+		t.Errorf("output missing finish line:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "[2/3] g/b") {
+		t.Errorf("output missing begin line:\n%s", buf.String())
+	}
+}
+
+func TestGroupProgressSeededETA(t *testing.T) {
+	p := newGroupProgress(&bytes.Buffer{}, 4, 5*time.Minute)
+	p.now = func() time.Time { return p.start }
+
+	if got := p.line("g/a"); !strings.HasSuffix(got, "ETA ~20m0s") {
+		t.Errorf("line = %q, want ETA from historical average", got)
+	}
+}
+
+func TestPrintBatchSummary(t *testing.T) {
+	var buf bytes.Buffer
+	failed := printBatchSummary(&buf, []batchResult{
+		{task: "add-feature", elapsed: 90 * time.Second},
+		{task: "backend/add-api", err: errors.New("task \"backend/add-api\" is already running (PID 42)")},
+	})
+
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"  ok      add-feature (1m30s)\n",
+		"  FAILED  backend/add-api (0s): task \"backend/add-api\" is already running (PID 42)\n",
+		"2 task(s): 1 succeeded, 1 failed\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// ReviewPR opens a pull request for a task in review, from its pushed branch
+// into the branch it merges into, titled with the task's label and described
+// by its content. With push_remote set, the branch is on the fork and the
+// pull request is opened upstream with the fork's owner in its head. If the
+// branch already has an open pull request, that one is reported instead.
+// Only forges hydra can open pull requests on are supported.
+func (r *Runner) ReviewPR(taskName string) error {
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+	if err != nil {
+		return err
+	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+	source, err := r.issueSource()
+	if err != nil {
+		return err
+	}
+	creator, ok := source.(issues.PullCreator)
+	if !ok {
+		return errors.New("hydra can't open pull requests on this repository's forge")
+	}
+
+	label := taskLabel(*task)
+	branch := task.BranchName()
+	head, err := r.pullHead(branch)
+	if err != nil {
+		return err
+	}
+
+	mainRepo := repo.Open(r.Config.RepoDir)
+	if err := mainRepo.Fetch(); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+	if !mainRepo.RemoteBranchExists(branch) {
+		return fmt.Errorf("branch %s is not on %s; run a review session to push it", branch, mainRepo.PushRemote())
+	}
+
+	ctx := context.Background()
+	if pulls, ok := source.(issues.PullSource); ok {
+		pr, err := openPull(ctx, pulls, branch)
+		if err != nil {
+			return err
+		}
+		if pr != nil {
+			fmt.Printf("%s already has pull request #%d: %s\n", label, pr.Number, pr.URL)
+			return nil
+		}
+	}
+
+	base, err := r.detectDefaultBranch(mainRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+	content, err := task.Content()
+	if err != nil {
+		return err
+	}
+	pr, err := creator.CreatePullRequest(ctx, head, base, label, strings.TrimSpace(content))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Opened pull request #%d for %s: %s\n", pr.Number, label, pr.URL)
+	return nil
+}
+
+// pullHead returns the head a pull request for branch is opened from: the
+// branch itself, or <owner>:<branch> when branches are pushed to the
+// push_remote fork.
+func (r *Runner) pullHead(branch string) (string, error) {
+	if r.TaskRunner == nil || r.TaskRunner.PushRemote == "" {
+		return branch, nil
+	}
+	_, owner, _, ok := issues.ParseGiteaURL(r.TaskRunner.PushRemote)
+	if !ok {
+		return "", fmt.Errorf("can't find the fork's owner in push_remote %q", r.TaskRunner.PushRemote)
+	}
+	return owner + ":" + branch, nil
+}
+
+// openPull returns the open pull request whose head is branch, or nil if
+// there is none.
+func openPull(ctx context.Context, pulls issues.PullSource, branch string) (*issues.PullRequest, error) {
+	prs, err := pulls.FetchPullRequests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching pull requests: %w", err)
+	}
+	for _, pr := range prs {
+		if pr.Branch == branch && pr.State == issues.PullOpen {
+			return &pr, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestReviewPRUnsupportedForge(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaude
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if err := r.ReviewPR("another-task"); err == nil {
+		t.Error("ReviewPR succeeded for a task that isn't in review")
+	}
+
+	r.Config.SourceRepoURL = "https://forge.example/owner/repo"
+	r.TaskRunner.APIType = "forgejo"
+	err = r.ReviewPR("add-feature")
+	if err == nil || !strings.Contains(err.Error(), "can't open pull requests") {
+		t.Errorf("ReviewPR on a forge without pull request creation = %v", err)
+	}
+}
+
+func TestPullHead(t *testing.T) {
+	r := &Runner{TaskRunner: &taskrun.Commands{}}
+	if head, err := r.pullHead("hydra/a"); err != nil || head != "hydra/a" {
+		t.Errorf("pullHead without a fork = %q, %v", head, err)
+	}
+
+	for _, url := range []string{"https://bitbucket.org/me/repo.git", "git@bitbucket.org:me/repo.git"} {
+		r.TaskRunner.PushRemote = url
+		if head, err := r.pullHead("hydra/a"); err != nil || head != "me:hydra/a" {
+			t.Errorf("pullHead with push_remote %s = %q, %v; want me:hydra/a", url, head, err)
+		}
+	}
+
+	r.TaskRunner.PushRemote = "/srv/git/fork.git"
+	if _, err := r.pullHead("hydra/a"); err == nil {
+		t.Error("pullHead succeeded for a push_remote without an owner")
+	}
+}
@@ -8,13 +8,23 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
 )
 
+// ReconcileOpts controls which completed tasks Reconcile processes and how.
+type ReconcileOpts struct {
+	Group     string // only reconcile completed tasks in this group
+	BatchSize int    // reconcile this many tasks per Claude session; 0 means all at once
+}
+
 // Reconcile reads all completed tasks, uses Claude to merge their requirements
 // into functional.md, then deletes the completed task files.
-func (r *Runner) Reconcile() error {
+//
+// With opts.BatchSize set, tasks are reconciled in batches: each batch gets
+// its own Claude session, and functional.md is written back and the batch's
+// task files deleted before the next batch starts. A failure then only loses
+// the batch in progress.
+func (r *Runner) Reconcile(opts ReconcileOpts) error {
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -25,32 +35,18 @@ func (r *Runner) Reconcile() error {
 	if err != nil {
 		return fmt.Errorf("listing completed tasks: %w", err)
 	}
+	if opts.Group != "" {
+		completed = tasksInGroup(completed, opts.Group)
+		if len(completed) == 0 {
+			return fmt.Errorf("no completed tasks in group %q to reconcile", opts.Group)
+		}
+	}
 	if len(completed) == 0 {
 		return errors.New("no completed tasks to reconcile")
 	}
 
-	// Read current functional.md.
-	functional, err := r.Design.Functional()
-	if err != nil {
-		return fmt.Errorf("reading functional.md: %w", err)
-	}
-
-	// Read all completed task contents.
-	var taskContents []taskEntry
-	for _, task := range completed {
-		content, err := task.Content()
-		if err != nil {
-			return fmt.Errorf("reading task %s: %w", task.Name, err)
-		}
-		name := task.Name
-		if task.Group != "" {
-			name = task.Group + "/" + task.Name
-		}
-		taskContents = append(taskContents, taskEntry{name: name, content: content})
-	}
-
 	// Prepare work directory.
-	wd := filepath.Join(baseDir, config.HydraDir, "work", "_reconcile")
+	wd := filepath.Join(r.workRoot(), "_reconcile")
 	reconcileRepo, err := r.prepareRepo(wd, "hydra/_reconcile")
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
@@ -68,6 +64,52 @@ func (r *Runner) Reconcile() error {
 		return fmt.Errorf("resetting work directory: %w", err)
 	}
 
+	batches := reconcileBatches(completed, opts.BatchSize)
+	deleted := 0
+	for i, batch := range batches {
+		if len(batches) > 1 {
+			fmt.Printf("Reconciling batch %d/%d (%d task(s))...\n", i+1, len(batches), len(batch))
+		}
+		if err := r.reconcileBatch(wd, batch); err != nil {
+			if deleted > 0 {
+				fmt.Printf("Reconciled %d task(s) before the failure.\n", deleted)
+			}
+			if len(batches) > 1 {
+				return fmt.Errorf("batch %d/%d: %w", i+1, len(batches), err)
+			}
+			return err
+		}
+		deleted += len(batch)
+	}
+
+	fmt.Printf("Deleted %d completed task(s).\n", deleted)
+	return nil
+}
+
+// reconcileBatch runs one Claude session merging a batch of completed tasks
+// into functional.md, copies the result back to the design directory, and
+// deletes the batch's task files.
+func (r *Runner) reconcileBatch(wd string, batch []design.Task) error {
+	// Read current functional.md, including earlier batches' updates.
+	functional, err := r.Design.Functional()
+	if err != nil {
+		return fmt.Errorf("reading functional.md: %w", err)
+	}
+
+	// Read the batch's task contents.
+	var taskContents []taskEntry
+	for _, task := range batch {
+		content, err := task.Content()
+		if err != nil {
+			return fmt.Errorf("reading task %s: %w", task.Name, err)
+		}
+		name := task.Name
+		if task.Group != "" {
+			name = task.Group + "/" + task.Name
+		}
+		taskContents = append(taskContents, taskEntry{name: name, content: content})
+	}
+
 	// Copy current functional.md into the work directory for Claude to edit.
 	functionalPath := filepath.Join(wd, "functional.md")
 	if err := os.WriteFile(functionalPath, []byte(functional), 0o600); err != nil {
@@ -79,7 +121,7 @@ func (r *Runner) Reconcile() error {
 
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
 	// Invoke Claude.
@@ -87,16 +129,21 @@ func (r *Runner) Reconcile() error {
 	if claudeFn == nil {
 		claudeFn = invokeClaude
 	}
-	err = claudeFn(context.Background(), ClaudeRunConfig{
+	err = runClaude(context.Background(), claudeFn, ClaudeRunConfig{
 		RepoDir:    wd,
+		Shell:      r.toolShell(wd),
+		Output:     &r.output,
 		Document:   doc,
 		Model:      r.Model,
+		Fallbacks:  r.modelFallbacks(),
+		Approval:   r.approvalRules(),
 		AutoAccept: r.AutoAccept,
 		PlanMode:   r.PlanMode,
 		ForceTUI:   r.ForceTUI,
+		TaskName:   "reconcile",
 	})
 	if err != nil {
-		return fmt.Errorf("claude failed: %w", err)
+		return inCategory(categoryClaude, fmt.Errorf("claude failed: %w", err))
 	}
 
 	// Read updated functional.md from work dir.
@@ -107,25 +154,47 @@ func (r *Runner) Reconcile() error {
 	updated := string(updatedData)
 
 	// Copy back to design dir if changed.
+	var written []string
 	if updated != functional {
 		designFunctionalPath := filepath.Join(r.Design.Path, "functional.md")
 		if err := os.WriteFile(designFunctionalPath, []byte(updated), 0o600); err != nil {
 			return fmt.Errorf("writing functional.md to design dir: %w", err)
 		}
+		written = append(written, designFunctionalPath)
 		fmt.Println("Updated functional.md with reconciled requirements.")
 	} else {
 		fmt.Println("functional.md unchanged.")
 	}
 
-	// Delete completed task files.
-	for i := range completed {
-		if err := r.Design.DeleteTask(&completed[i]); err != nil {
-			return fmt.Errorf("deleting completed task %s: %w", completed[i].Name, err)
+	// Delete completed task files, together with the functional.md update
+	// as one change, so each batch is its own design_autocommit commit.
+	return r.Design.DeleteTasks(batch, written, "reconcile %d task(s) into functional.md", len(batch))
+}
+
+// tasksInGroup returns the tasks belonging to group.
+func tasksInGroup(tasks []design.Task, group string) []design.Task {
+	var result []design.Task
+	for _, t := range tasks {
+		if t.Group == group {
+			result = append(result, t)
 		}
 	}
+	return result
+}
 
-	fmt.Printf("Deleted %d completed task(s).\n", len(completed))
-	return nil
+// reconcileBatches splits tasks into batches of at most size tasks. A size
+// of zero or less puts every task in one batch.
+func reconcileBatches(tasks []design.Task, size int) [][]design.Task {
+	if size <= 0 || size >= len(tasks) {
+		return [][]design.Task{tasks}
+	}
+	var batches [][]design.Task
+	for len(tasks) > 0 {
+		n := min(size, len(tasks))
+		batches = append(batches, tasks[:n])
+		tasks = tasks[n:]
+	}
+	return batches
 }
 
 // taskEntry holds a task name and its content for document assembly.
@@ -46,11 +46,18 @@ func TestReconcileFullWorkflow(t *testing.T) {
 		functionalPath := filepath.Join(cfg.RepoDir, "functional.md")
 		return os.WriteFile(functionalPath, []byte("# Updated Spec\n\nFeature: add-feature is implemented.\n"), 0o600)
 	}
+	var changes [][]string
+	r.Design.OnChange = func(_ string, paths []string) { changes = append(changes, paths) }
 
-	if err := r.Reconcile(); err != nil {
+	if err := r.Reconcile(ReconcileOpts{}); err != nil {
 		t.Fatalf("Reconcile: %v", err)
 	}
 
+	// The functional.md update and the task deletion are one change.
+	if len(changes) != 1 || len(changes[0]) < 2 || filepath.Base(changes[0][0]) != "functional.md" {
+		t.Errorf("changes = %q, want one holding functional.md and the task", changes)
+	}
+
 	// Verify functional.md was updated in the design dir.
 	content, err := os.ReadFile(filepath.Join(env.DesignDir, "functional.md"))
 	if err != nil {
@@ -77,7 +84,7 @@ func TestReconcileNoCompletedTasks(t *testing.T) {
 	r.Claude = mockClaude
 	r.BaseDir = env.BaseDir
 
-	err = r.Reconcile()
+	err = r.Reconcile(ReconcileOpts{})
 	if err == nil {
 		t.Fatal("expected error when no completed tasks")
 	}
@@ -107,7 +114,7 @@ func TestReconcileDocumentContents(t *testing.T) {
 		return nil
 	}
 
-	if err := r.Reconcile(); err != nil {
+	if err := r.Reconcile(ReconcileOpts{}); err != nil {
 		t.Fatalf("Reconcile: %v", err)
 	}
 
@@ -161,7 +168,7 @@ func TestReconcilePreservesOtherStates(t *testing.T) {
 	r.BaseDir = env.BaseDir
 	r.Claude = func(_ context.Context, _ ClaudeRunConfig) error { return nil }
 
-	if err := r.Reconcile(); err != nil {
+	if err := r.Reconcile(ReconcileOpts{}); err != nil {
 		t.Fatalf("Reconcile: %v", err)
 	}
 
@@ -196,7 +203,7 @@ func TestReconcileClaudeFailure(t *testing.T) {
 	r.BaseDir = env.BaseDir
 	r.Claude = mockClaudeFailing
 
-	err = r.Reconcile()
+	err = r.Reconcile(ReconcileOpts{})
 	if err == nil {
 		t.Fatal("expected error when Claude fails")
 	}
@@ -244,7 +251,7 @@ func TestReconcileAlwaysFreshCheckout(t *testing.T) {
 		return nil
 	}
 
-	if err := r.Reconcile(); err != nil {
+	if err := r.Reconcile(ReconcileOpts{}); err != nil {
 		t.Fatalf("Reconcile: %v", err)
 	}
 
@@ -252,3 +259,78 @@ func TestReconcileAlwaysFreshCheckout(t *testing.T) {
 		t.Error("stale file should be cleaned before Claude runs")
 	}
 }
+
+func TestReconcileGroupNoCompletedTasks(t *testing.T) {
+	env := setupTestEnv(t)
+
+	mkdirAll(t, filepath.Join(env.DesignDir, "state", "completed"))
+	writeFile(t, filepath.Join(env.DesignDir, "state", "completed", "done-task.md"), "Done task content")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.Claude = mockClaude
+	r.BaseDir = env.BaseDir
+
+	err = r.Reconcile(ReconcileOpts{Group: "backend"})
+	if err == nil {
+		t.Fatal("expected error when the group has no completed tasks")
+	}
+	if !strings.Contains(err.Error(), `no completed tasks in group "backend"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTasksInGroup(t *testing.T) {
+	tasks := []design.Task{
+		{Name: "a"},
+		{Name: "b", Group: "backend"},
+		{Name: "c", Group: "frontend"},
+		{Name: "d", Group: "backend"},
+	}
+
+	got := tasksInGroup(tasks, "backend")
+	if len(got) != 2 || got[0].Name != "b" || got[1].Name != "d" {
+		t.Errorf("tasksInGroup(backend) = %v", got)
+	}
+	if got := tasksInGroup(tasks, "missing"); len(got) != 0 {
+		t.Errorf("tasksInGroup(missing) = %v, want none", got)
+	}
+}
+
+func TestReconcileBatches(t *testing.T) {
+	tasks := make([]design.Task, 5)
+	for i := range tasks {
+		tasks[i].Name = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		size  int
+		sizes []int
+	}{
+		{0, []int{5}},
+		{-1, []int{5}},
+		{2, []int{2, 2, 1}},
+		{5, []int{5}},
+		{10, []int{5}},
+		{1, []int{1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		batches := reconcileBatches(tasks, tt.size)
+		if len(batches) != len(tt.sizes) {
+			t.Errorf("size %d: got %d batches, want %d", tt.size, len(batches), len(tt.sizes))
+			continue
+		}
+		for i, b := range batches {
+			if len(b) != tt.sizes[i] {
+				t.Errorf("size %d: batch %d has %d tasks, want %d", tt.size, i, len(b), tt.sizes[i])
+			}
+		}
+	}
+
+	batches := reconcileBatches(tasks, 2)
+	if batches[2][0].Name != "e" {
+		t.Errorf("last batch starts with %q, want %q", batches[2][0].Name, "e")
+	}
+}
@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// RenameTask renames a task, in whatever state it is, to newName within its
+// group. Besides the task file, it renames what hydra keeps under the
+// task's name: the work directory, the local and remote branches, the saved
+// plan and session transcripts, and the task's entries in record.json. The
+// task must not be in use by another hydra. Once the task file is renamed,
+// failures to rename the rest are reported as warnings.
+func (r *Runner) RenameTask(oldName, newName string) error {
+	task, err := r.Design.FindTaskAny(oldName)
+	if err != nil {
+		return err
+	}
+	if newName == task.Name {
+		return fmt.Errorf("task %q already has that name", taskLabel(*task))
+	}
+
+	hydraDir := r.hydraDir()
+	oldLabel := taskLabel(*task)
+	if taskBusy(hydraDir, oldLabel) || taskBusy(hydraDir, oldName) {
+		return fmt.Errorf("task %q is in use by another hydra; wait for it to finish first", oldLabel)
+	}
+
+	if r.Config != nil {
+		if err := r.checkNoOpenPull(task.BranchName()); err != nil {
+			return err
+		}
+	}
+
+	old := *task
+	if err := issues.PinIssueNumber(task); err != nil {
+		return err
+	}
+	if err := r.Design.RenameTask(task, newName); err != nil {
+		return err
+	}
+	newLabel := taskLabel(*task)
+	fmt.Printf("Renamed task %q to %q.\n", oldLabel, newLabel)
+
+	r.renameWorkDir(&old, task)
+	if r.Config != nil {
+		r.renameBranch(&old, task)
+	}
+
+	for _, move := range [][2]string{
+		{planPath(hydraDir, &old), planPath(hydraDir, task)},
+		{transcriptDir(hydraDir, oldLabel), transcriptDir(hydraDir, newLabel)},
+	} {
+		if _, err := os.Stat(move[0]); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(move[1]), 0o750); err == nil {
+			err = os.Rename(move[0], move[1])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not move %s: %v\n", move[0], err)
+		}
+	}
+
+	n, err := r.Design.Record().RenameTask(oldLabel, newLabel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not rename the task in record.json: %v\n", err)
+	} else if n > 0 {
+		fmt.Printf("Renamed %d record.json entries\n", n)
+	}
+	return nil
+}
+
+// renameWorkDir moves the work directory of the task renamed from old, if
+// it has one.
+func (r *Runner) renameWorkDir(old, task *design.Task) {
+	oldWD, newWD := r.workDir(old), r.workDir(task)
+	if _, err := os.Stat(oldWD); err != nil {
+		return
+	}
+	if _, err := os.Stat(newWD); err == nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s already exists; the work directory stays at %s\n", newWD, oldWD)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(newWD), 0o750); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not move work directory: %v\n", err)
+		return
+	}
+	// Worktrees move with git so the main repository keeps track of them;
+	// anything else, such as a jj clone, is moved as is.
+	moved := false
+	if r.Config != nil {
+		moved = repo.Open(r.Config.RepoDir).WorktreeMove(oldWD, newWD) == nil
+	}
+	if !moved {
+		if err := os.Rename(oldWD, newWD); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not move work directory: %v\n", err)
+			return
+		}
+	}
+	fmt.Printf("Moved %s to %s\n", oldWD, newWD)
+}
+
+// checkNoOpenPull refuses to rename a task whose branch on origin has an
+// open pull request: the forge closes a pull request whose head branch is
+// deleted, and it can't be moved to the new branch. If the forge can't be
+// asked, a warning is printed and the rename goes ahead.
+func (r *Runner) checkNoOpenPull(branch string) error {
+	if !repo.Open(r.Config.RepoDir).RemoteBranchExists(branch) {
+		return nil
+	}
+	source, err := r.issueSource()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check %s for an open pull request (%v); renaming it on origin closes any\n", branch, err)
+		return nil
+	}
+	pulls, ok := source.(issues.PullSource)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Warning: cannot check %s for an open pull request; renaming it on origin closes any\n", branch)
+		return nil
+	}
+	pr, err := openPull(context.Background(), pulls, branch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check %s for an open pull request (%v); renaming it on origin closes any\n", branch, err)
+		return nil
+	}
+	if pr != nil {
+		return fmt.Errorf("branch %s has open pull request #%d (%s), which renaming the branch would close; merge or close it first", branch, pr.Number, pr.URL)
+	}
+	return nil
+}
+
+// renameBranch renames the branch of the task renamed from old, locally and
+// on origin. A work directory that is a clone of its own, rather than a
+// worktree sharing the main repository's branches, has its branch renamed
+// too.
+func (r *Runner) renameBranch(old, task *design.Task) {
+	oldBranch, newBranch := old.BranchName(), task.BranchName()
+	if oldBranch == newBranch {
+		return
+	}
+
+	mainRepo := repo.Open(r.Config.RepoDir)
+	pushFrom := mainRepo
+	for _, rp := range []*repo.Repo{mainRepo, repo.Open(r.workDir(task))} {
+		if !repo.IsGitRepo(rp.Dir) || !rp.BranchExists(oldBranch) {
+			continue
+		}
+		if err := rp.RenameBranch(oldBranch, newBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not rename branch %q in %s: %v\n", oldBranch, rp.Dir, err)
+			return
+		}
+		fmt.Printf("Renamed branch %s to %s in %s\n", oldBranch, newBranch, rp.Dir)
+		pushFrom = rp
+	}
+
+	if !mainRepo.RemoteBranchExists(oldBranch) {
+		return
+	}
+	if err := pushFrom.Push(newBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not push %q; %q is left on origin: %v\n", newBranch, oldBranch, err)
+		return
+	}
+	if err := mainRepo.DeleteRemoteBranch(oldBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not delete branch %q from origin: %v\n", oldBranch, err)
+		return
+	}
+	fmt.Printf("Renamed %s to %s on origin\n", oldBranch, newBranch)
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+func TestRenameTask(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	r.WorkRoot = t.TempDir()
+	hydraDir := config.HydraPath(r.BaseDir)
+	mkdirAll(t, hydraDir)
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review", "backend"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "backend", "add-api.md"), "Build API.\n")
+	mkdirAll(t, filepath.Join(r.WorkRoot, "backend", "add-api"))
+	writeFile(t, filepath.Join(r.WorkRoot, "backend", "add-api", "main.go"), "package main\n")
+	mkdirAll(t, filepath.Join(hydraDir, "plans", "backend"))
+	writeFile(t, filepath.Join(hydraDir, "plans", "backend", "add-api.md"), "The plan.\n")
+	if err := r.Design.Record().AddEntry(design.RecordEntry{SHA: "abc", TaskName: "review:backend/add-api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A task in use is left alone.
+	lk := lock.New(hydraDir, "review:backend/add-api")
+	if err := lk.Acquire(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenameTask("backend/add-api", "add-endpoint"); err == nil {
+		t.Fatal("RenameTask succeeded while the task was being reviewed")
+	}
+	if err := lk.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.RenameTask("backend/add-api", "add-endpoint"); err != nil {
+		t.Fatalf("RenameTask: %v", err)
+	}
+	if _, err := r.Design.FindTaskByState("backend/add-endpoint", design.StateReview); err != nil {
+		t.Errorf("renamed task not in review: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(r.WorkRoot, "backend", "add-endpoint", "main.go")); err != nil {
+		t.Errorf("work directory not moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hydraDir, "plans", "backend", "add-endpoint.md")); err != nil {
+		t.Errorf("plan not moved: %v", err)
+	}
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].TaskName != "review:backend/add-endpoint" {
+		t.Errorf("record entries = %+v, want review:backend/add-endpoint", entries)
+	}
+
+	if err := r.RenameTask("backend/add-endpoint", "add-endpoint"); err == nil {
+		t.Error("RenameTask to the same name succeeded")
+	}
+}
+
+func TestRenameTaskRefusesOpenPull(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaude
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	state := "open"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/repos/owner/repo/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 7, "state": state, "html_url": "https://forge.example/owner/repo/pulls/7", "head": map[string]string{"ref": "hydra/add-feature"}},
+		})
+	}))
+	defer ts.Close()
+	r.Config.SourceRepoURL = "https://forge.example/owner/repo"
+	r.TaskRunner.APIType = "forgejo"
+	r.TaskRunner.GiteaURL = ts.URL
+
+	err = r.RenameTask("add-feature", "add-widget")
+	if err == nil || !strings.Contains(err.Error(), "#7") {
+		t.Fatalf("RenameTask with an open pull request = %v, want a refusal naming #7", err)
+	}
+	if _, err := r.Design.FindTaskAny("add-feature"); err != nil {
+		t.Errorf("task renamed despite the refusal: %v", err)
+	}
+
+	state = "closed"
+	if err := r.RenameTask("add-feature", "add-widget"); err != nil {
+		t.Fatalf("RenameTask after the pull request closed: %v", err)
+	}
+	if !repo.Open(env.BaseDir).RemoteBranchExists("hydra/add-widget") {
+		t.Error("branch not renamed on origin")
+	}
+}
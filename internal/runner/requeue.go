@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// RequeueOpts controls what hydra review requeue does with a task's first
+// attempt.
+type RequeueOpts struct {
+	Editor     string // editor for a note on what went wrong; empty skips the note
+	KeepBranch bool   // keep the work directory and branch instead of discarding them
+}
+
+// ReviewRequeue moves a task in review back to pending, in its group, so it
+// can be run again. With opts.Editor set, a note on what went wrong with
+// the attempt is appended to the task, for the next run to take into
+// account. Unless opts.KeepBranch is set, the attempt is thrown away: the
+// work directory is removed and the branch deleted, locally and on origin,
+// so the next run starts from the default branch. Cleanup failures are
+// reported as warnings; the task is pending either way.
+func (r *Runner) ReviewRequeue(taskName string, opts RequeueOpts) error {
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+	if err != nil {
+		return err
+	}
+	label := taskLabel(*task)
+	if _, err := r.Design.FindTask(label); err == nil {
+		return fmt.Errorf("a pending task named %q already exists", label)
+	}
+
+	if !opts.KeepBranch && r.Config != nil {
+		release, err := r.claimWorkDir(task)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	var note string
+	if opts.Editor != "" {
+		if note, err = editNote(opts.Editor, "hydra-requeue-*.md"); err != nil {
+			return err
+		}
+	}
+
+	wd := r.workDir(task)
+	branch := task.BranchName()
+
+	if err := r.Design.MoveTask(task, design.StatePending); err != nil {
+		return err
+	}
+	if note != "" {
+		if err := appendAttemptNote(task, note); err != nil {
+			return err
+		}
+		r.Design.Changed([]string{task.FilePath}, "note previous attempt at %s", label)
+	}
+	fmt.Printf("Task %q requeued.\n", label)
+
+	if opts.KeepBranch || r.Config == nil {
+		return nil
+	}
+	r.discardAttempt(wd, branch)
+	return nil
+}
+
+// appendAttemptNote adds a section on what went wrong with the previous
+// attempt to the end of a task file.
+func appendAttemptNote(task *design.Task, note string) error {
+	content, err := os.ReadFile(task.FilePath) //nolint:gosec // task path inside our own design dir
+	if err != nil {
+		return fmt.Errorf("reading task: %w", err)
+	}
+	updated := strings.TrimRight(string(content), "\n") +
+		"\n\n## Previous Attempt\n\nAn earlier attempt at this task was discarded. What went wrong:\n\n" +
+		note + "\n"
+	if err := os.WriteFile(task.FilePath, []byte(updated), 0o600); err != nil {
+		return fmt.Errorf("writing task: %w", err)
+	}
+	return nil
+}
+
+// discardAttempt removes a task's work directory and deletes its branch
+// from the main repository and from origin.
+func (r *Runner) discardAttempt(wd, branch string) {
+	mainRepo := repo.Open(r.Config.RepoDir)
+
+	if _, err := os.Stat(wd); err == nil {
+		if err := r.removeWorkDir(wd); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not remove work directory: %v\n", err)
+		} else {
+			fmt.Printf("Removed %s\n", wd)
+		}
+	}
+
+	if mainRepo.BranchExists(branch) {
+		if err := mainRepo.DeleteBranch(branch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not delete branch %q: %v\n", branch, err)
+		}
+	}
+	if err := mainRepo.DeleteRemoteBranch(branch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not delete remote branch %q: %v\n", branch, err)
+	} else {
+		fmt.Printf("Deleted %s from origin\n", branch)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+)
+
+// writeNoteEditor returns an editor script that writes note to its file.
+func writeNoteEditor(t *testing.T, note string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf '%s\\n' '" + note + "' > \"$1\"\n"
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil { //nolint:gosec // must be executable
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReviewRequeue(t *testing.T) {
+	r := stubRunner(t)
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review", "backend"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "backend", "add-api.md"), "Build API.\n")
+
+	var messages []string
+	r.Design.OnChange = func(message string, _ []string) { messages = append(messages, message) }
+
+	opts := RequeueOpts{Editor: writeNoteEditor(t, "It rewrote the router instead of extending it."), KeepBranch: true}
+	if err := r.ReviewRequeue("backend/add-api", opts); err != nil {
+		t.Fatalf("ReviewRequeue: %v", err)
+	}
+	if got := strings.Join(messages, "\n"); !strings.Contains(got, "note previous attempt at backend/add-api") {
+		t.Errorf("changes = %q, want the attempt note reported", got)
+	}
+
+	task, err := r.Design.FindTask("backend/add-api")
+	if err != nil {
+		t.Fatalf("task not pending: %v", err)
+	}
+	content, err := task.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(content, "Build API.\n") || !strings.Contains(content, "## Previous Attempt") ||
+		!strings.Contains(content, "It rewrote the router instead of extending it.") {
+		t.Errorf("task content = %q", content)
+	}
+	if _, err := r.Design.FindTaskByState("backend/add-api", design.StateReview); err == nil {
+		t.Error("task still in review")
+	}
+}
+
+func TestReviewRequeuePendingExists(t *testing.T) {
+	r := stubRunner(t)
+	mkdirAll(t, filepath.Join(r.Design.Path, "state", "review"))
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "add-feature.md"), "Old attempt.\n")
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "add-feature.md"), "New task.\n")
+
+	if err := r.ReviewRequeue("add-feature", RequeueOpts{KeepBranch: true}); err == nil {
+		t.Fatal("expected an error when a pending task of the same name exists")
+	}
+}
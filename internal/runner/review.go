@@ -5,23 +5,42 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ReviewDev runs the dev command from hydra.yml in the task's work directory.
+// The command gets the variables in the design directory's .env.hydra and
+// HYDRA_TASK, HYDRA_BRANCH, and HYDRA_TASK_DIR, and the ports it declares
+// in dev_ports are printed before it starts.
 // The process runs until it exits or the context is cancelled.
 func (r *Runner) ReviewDev(ctx context.Context, taskName string) error {
 	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
 	if err != nil {
 		return err
 	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+
+	release, err := r.holdWorkDir(task)
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	wd := r.workDir(task)
 
-	taskRepo, err := r.prepareRepo(wd)
+	taskRepo, err := r.prepareRepo(wd, task.BranchName())
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
 	}
@@ -43,7 +62,25 @@ func (r *Runner) ReviewDev(ctx context.Context, taskName string) error {
 		return errors.New("no dev command configured in hydra.yml and no dev target in Makefile")
 	}
 
-	err = r.TaskRunner.RunDev(ctx, wd)
+	absWD, err := filepath.Abs(wd)
+	if err != nil {
+		absWD = wd
+	}
+	vars := map[string]string{
+		"HYDRA_TASK":     taskLabel(*task),
+		"HYDRA_BRANCH":   branch,
+		"HYDRA_TASK_DIR": absWD,
+	}
+	env, err := r.Design.DevEnv(vars)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"HYDRA_TASK", "HYDRA_BRANCH", "HYDRA_TASK_DIR"} {
+		env = append(env, name+"="+vars[name])
+	}
+	fmt.Print(devPortHints(r.TaskRunner.DevPorts, env, os.Getenv("SSH_CONNECTION") != ""))
+
+	err = r.commands().RunDev(ctx, wd, env)
 	if err != nil && ctx.Err() != nil {
 		fmt.Println("\nDev server stopped.")
 		return nil //nolint:nilerr // intentional: replace signal error with friendly message
@@ -51,9 +88,54 @@ func (r *Runner) ReviewDev(ctx context.Context, taskName string) error {
 	return err
 }
 
+// devPortHints lists where the dev ports can be reached, expanding their
+// URLs from env. Over SSH it also suggests the ssh -L flags that forward
+// them to the reviewer's machine.
+func devPortHints(ports []taskrun.DevPort, env []string, overSSH bool) string {
+	if len(ports) == 0 {
+		return ""
+	}
+	values := make(map[string]string, len(env))
+	for _, kv := range env {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			values[name] = value
+		}
+	}
+	lookup := func(name string) string {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+
+	var b strings.Builder
+	b.WriteString("Dev server:\n")
+	var forwards []string
+	for _, p := range ports {
+		name := p.Name
+		if name == "" {
+			name = strconv.Itoa(p.Port)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, p.Address(lookup))
+		forwards = append(forwards, fmt.Sprintf("-L %d:localhost:%d", p.Port, p.Port))
+	}
+	if overSSH {
+		host, err := os.Hostname()
+		if err != nil {
+			host = "<host>"
+		}
+		fmt.Fprintf(&b, "Forward the ports over SSH with: ssh %s %s\n", strings.Join(forwards, " "), host)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 // Review runs an interactive review session on a task in review state.
 // The task stays in review state after the review session.
-func (r *Runner) Review(taskName string) error {
+func (r *Runner) Review(taskName string) (err error) {
+	ctx, span := tracing.Start(context.Background(), "review", attribute.String("hydra.task", taskName))
+	defer func() { tracing.End(span, err) }()
+
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -65,6 +147,16 @@ func (r *Runner) Review(taskName string) error {
 	if err != nil {
 		return err
 	}
+	defer func() { r.recordFailure("review", taskName, err) }()
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+	var persona *design.Persona
+	if r.Persona != "" {
+		if persona, err = r.Design.Persona(r.Persona); err != nil {
+			return err
+		}
+	}
 
 	// Acquire lock.
 	lk := lock.New(hydraDir, "review:"+taskName)
@@ -72,13 +164,17 @@ func (r *Runner) Review(taskName string) error {
 		return err
 	}
 	defer func() { _ = lk.Release() }()
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
 
 	// Prepare work directory (should exist from run).
 	wd := r.workDir(task)
-	taskRepo, err := r.prepareRepo(wd)
+	taskRepo, err := r.prepareRepo(wd, task.BranchName())
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
 	}
+	taskRepo = taskRepo.Traced(ctx)
 
 	// Checkout the task's branch (skip if working tree is dirty).
 	branch := task.BranchName()
@@ -86,16 +182,28 @@ func (r *Runner) Review(taskName string) error {
 		return err
 	}
 
+	// Report would-be rebase conflicts before spending a Claude session.
+	if r.Precheck {
+		proceed, err := r.precheckRebase(taskRepo, branch)
+		if err != nil {
+			return fmt.Errorf("precheck: %w", err)
+		}
+		if !proceed {
+			fmt.Println("Review cancelled.")
+			return nil
+		}
+	}
+
 	// Rebase onto latest remote main if requested (only if clean tree).
-	var conflictFiles []string
+	var conflicts []repo.Conflict
 	dirty, err := taskRepo.HasChanges()
 	if err != nil {
 		return fmt.Errorf("checking working tree: %w", err)
 	}
 	if r.Rebase && !dirty {
-		conflictFiles, err = r.attemptRebase(taskRepo)
+		conflicts, err = r.attemptRebase(taskRepo)
 		if err != nil {
-			return fmt.Errorf("rebasing onto main: %w", err)
+			return inCategory(categoryRebase, fmt.Errorf("rebasing onto main: %w", err))
 		}
 	}
 
@@ -105,25 +213,60 @@ func (r *Runner) Review(taskName string) error {
 		return err
 	}
 
-	doc, err := r.assembleReviewDocument(content, conflictFiles)
+	comments, err := task.Comments()
+	if err != nil {
+		return err
+	}
+
+	doc, err := r.assembleReviewDocument(content, comments, conflicts, persona)
 	if err != nil {
 		return fmt.Errorf("assembling review document: %w", err)
 	}
 
+	// Limit the review to the focus paths, with their part of the diff.
+	if len(r.Focus) > 0 {
+		section, err := r.reviewFocus(taskRepo, branch)
+		if err != nil {
+			return err
+		}
+		doc += section
+	}
+
+	// Split the changes by commit so their messages can be checked.
+	if r.ByCommit {
+		section, err := r.reviewCommits(taskRepo, branch)
+		if err != nil {
+			return err
+		}
+		doc += section
+	}
+
+	// Check commit messages against commit_template.md, if there is one.
+	tmplCheck, err := r.checkCommitTemplate(taskRepo, task)
+	if err != nil {
+		return fmt.Errorf("checking commit template: %w", err)
+	}
+	doc += commitTemplateSection(tmplCheck)
+	doc += prepareNotes(wd)
+
 	// Append verification and commit instructions so Claude handles test/lint/staging/committing.
 	sign := taskRepo.HasSigningKey()
 	cmds := r.commandsMap(wd)
 	doc += documentSuffix(suffixOpts{
-		Commands:    cmds,
-		Sign:        sign,
-		Timeout:     r.timeout(),
-		Notify:      r.Notify,
-		NotifyTitle: r.notifyTitle(taskName),
+		Commands:     cmds,
+		SerialChecks: r.serialChecks(),
+		Base:         r.targetBranch(),
+		Sign:         sign,
+		Timeout:      r.timeout(),
+		Notify:       r.Notify,
+		NotifyTitle:  r.notifyTitle(taskName),
+		CommitTmpl:   tmplCheck.Template,
+		Locale:       r.locale(),
 	})
 
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
 	// Capture HEAD before invoking Claude.
@@ -137,49 +280,96 @@ func (r *Runner) Review(taskName string) error {
 	if claudeFn == nil {
 		claudeFn = invokeClaude
 	}
+	var tokens int64
+	var finalMessage string
+	usedModel := modelOrDefault(r.Model)
 	runCfg := ClaudeRunConfig{
-		RepoDir:    taskRepo.Dir,
-		Document:   doc,
-		Model:      r.Model,
-		AutoAccept: r.AutoAccept,
-		PlanMode:   r.PlanMode,
-		ForceTUI:   r.ForceTUI,
-	}
-	if err := claudeFn(context.Background(), runCfg); err != nil {
+		RepoDir:      taskRepo.WorkDir(),
+		Shell:        r.toolShell(taskRepo.WorkDir()),
+		Output:       &r.output,
+		State:        r.stateTool(task),
+		Document:     doc,
+		Model:        r.Model,
+		Fallbacks:    r.modelFallbacks(),
+		Approval:     r.approvalRules(),
+		AutoAccept:   r.AutoAccept,
+		PlanMode:     r.PlanMode,
+		ForceTUI:     r.ForceTUI,
+		TaskName:     "review:" + taskName,
+		HydraDir:     hydraDir,
+		Timeout:      r.timeout(),
+		WrapUp:       r.wrapUp(),
+		Tokens:       &tokens,
+		UsedModel:    &usedModel,
+		FinalMessage: &finalMessage,
+	}
+	if err := continueOnTimeout(runClaude(ctx, claudeFn, runCfg)); err != nil {
 		return err
 	}
 
+	// The session has seen the reviewer's comments.
+	if err := r.Design.ClearComments(task, comments); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not clear review comments: %v\n", err)
+	}
+
 	// Check if Claude committed (HEAD moved).
 	afterSHA, err := taskRepo.LastCommitSHA()
 	if err != nil {
 		return fmt.Errorf("getting HEAD SHA after claude: %w", err)
 	}
 
+	// Collect review checklist results, if Claude reported any.
+	checklist, err := r.reviewChecklist(persona)
+	if err != nil {
+		return err
+	}
+	results := collectChecklistResults(finalMessage, checklist)
+
+	record := r.Design.Record()
+	entry := design.RecordEntry{SHA: afterSHA, TaskName: "review:" + taskLabel(*task), Checklist: results, Tokens: tokens, Model: usedModel}
+
 	if afterSHA == beforeSHA {
+		if len(results) > 0 {
+			if err := record.AddEntry(entry); err != nil {
+				return fmt.Errorf("recording checklist: %w", err)
+			}
+		}
 		fmt.Printf("Review of %q: no changes made.\n", taskName)
+		r.runHook(taskrun.HookAfterReview, wd, hookEvent{Action: "review", Task: taskName, State: design.StateReview, Branch: branch, SHA: afterSHA})
 		return nil
 	}
 
+	if err := r.enforceDiffPolicy(ctx, claudeFn, runCfg, taskRepo); err != nil {
+		return err
+	}
+	if entry.SHA, err = taskRepo.LastCommitSHA(); err != nil {
+		return fmt.Errorf("getting HEAD SHA: %w", err)
+	}
+	afterSHA = entry.SHA
+
 	// Record SHA and push.
-	record := design.NewRecord(r.Config.DesignDir)
-	if err := record.Add(afterSHA, "review:"+taskName); err != nil {
+	entry.LinesAdded, entry.LinesDeleted = lineStats(taskRepo, beforeSHA, afterSHA)
+	if err := record.AddEntry(entry); err != nil {
 		return fmt.Errorf("recording SHA: %w", err)
 	}
 
 	if err := taskRepo.Push(branch); err != nil {
 		// Try force push with lease if normal push fails (rebased branch).
 		if fpErr := taskRepo.ForcePushWithLease(branch); fpErr != nil {
-			return fmt.Errorf("pushing: %w", fpErr)
+			return inCategory(categoryPush, fmt.Errorf("pushing: %w", fpErr))
 		}
 	}
 	fmt.Printf("Review of %q: changes committed and pushed.\n", taskName)
+	r.runHook(taskrun.HookAfterReview, wd, hookEvent{Action: "review", Task: taskName, State: design.StateReview, Branch: branch, SHA: afterSHA})
 
 	// Task stays in review state.
 	return nil
 }
 
-// assembleReviewDocument builds a document for the review session.
-func (r *Runner) assembleReviewDocument(taskContent string, conflictFiles []string) (string, error) {
+// assembleReviewDocument builds a document for the review session,
+// including any comments left by reviewers with hydra review comment and
+// the reviewer persona, if one was chosen.
+func (r *Runner) assembleReviewDocument(taskContent, comments string, conflicts []repo.Conflict, persona *design.Persona) (string, error) {
 	rules, err := r.Design.Rules()
 	if err != nil {
 		return "", err
@@ -202,15 +392,47 @@ func (r *Runner) assembleReviewDocument(taskContent string, conflictFiles []stri
 
 	doc += "# Task\n\n" + taskContent + "\n\n"
 
-	doc += conflictResolutionSection(conflictFiles)
+	if strings.TrimSpace(comments) != "" {
+		doc += "# Reviewer Feedback\n\n" +
+			"A human reviewer left the notes below on this implementation. " +
+			"Address each of them in this session.\n\n" + strings.TrimSpace(comments) + "\n\n"
+	}
+
+	doc += r.conflictSection(conflicts)
+
+	var data sectionData
+	if persona != nil && persona.Focus != "" {
+		data.PersonaName, data.PersonaFocus = persona.Name, persona.Focus
+	}
+	doc += r.locale().section(sectionReview, data, reviewInstructions(data.PersonaName, data.PersonaFocus))
+
+	checklist, err := r.reviewChecklist(persona)
+	if err != nil {
+		return "", err
+	}
+	if len(checklist) > 0 {
+		doc += "\n" + checklistSection(checklist)
+	}
 
-	doc += "# Review Instructions\n\n"
+	return doc, nil
+}
+
+// reviewInstructions returns the Review Instructions section of a review
+// document, taking on the persona named name if focus is set.
+func reviewInstructions(name, focus string) string {
+	doc := "# Review Instructions\n\n"
 	doc += "You are reviewing an implementation of the above task. " +
 		"Please verify the implementation is correct and make corrections as needed. Focus on:\n\n" +
 		"- Correctness of the implementation\n" +
 		"- Code quality and adherence to the rules above\n" +
 		"- Edge cases and error handling\n\n"
 
+	if focus != "" {
+		doc += "## Reviewer Persona: " + name + "\n\n"
+		doc += "This is a specialized review pass. Review the change as the reviewer described below, " +
+			"giving their focus areas priority over general polish.\n\n" + focus + "\n\n"
+	}
+
 	doc += "## Commit Message Validation\n\n"
 	doc += "Read the git log and verify that the commit message(s) accurately describe " +
 		"the changes made. Compare them against the task document above. " +
@@ -222,8 +444,55 @@ func (r *Runner) assembleReviewDocument(taskContent string, conflictFiles []stri
 		"Verify that each item has corresponding test coverage. " +
 		"If any described feature or behavior lacks tests, add the missing tests. " +
 		"Every testable requirement in the task document must have at least one test.\n"
+	return doc
+}
 
-	return doc, nil
+// reviewChecklist returns the checks of a review session: those in
+// review.md, followed by the persona's, if there is one.
+func (r *Runner) reviewChecklist(persona *design.Persona) ([]string, error) {
+	checklist, err := r.Design.ReviewChecklist()
+	if err != nil {
+		return nil, err
+	}
+	if persona != nil {
+		checklist = append(checklist, persona.Checklist...)
+	}
+	return checklist, nil
+}
+
+// reviewFocus returns the review focus section for r.Focus, with the part of
+// the branch's diff under those paths. It fails if the branch changes
+// nothing there.
+func (r *Runner) reviewFocus(taskRepo repo.VCS, branch string) (string, error) {
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return "", fmt.Errorf("detecting default branch: %w", err)
+	}
+	diff, err := taskRepo.DiffRangePaths("origin/"+defaultBranch, branch, r.Focus)
+	if err != nil {
+		return "", fmt.Errorf("getting focus diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("branch %s has no changes under %s", branch, strings.Join(r.Focus, ", "))
+	}
+	return reviewFocusSection(r.Focus, diff), nil
+}
+
+// reviewFocusSection limits a review session to paths and includes the
+// changes under them, so a large branch can be reviewed one area at a time.
+func reviewFocusSection(paths []string, diff string) string {
+	var b strings.Builder
+	b.WriteString("\n\n# Review Focus\n\n")
+	b.WriteString("This session reviews only the changes under these paths:\n\n")
+	for _, p := range paths {
+		b.WriteString("- `" + p + "`\n")
+	}
+	b.WriteString("\nOther parts of the branch are reviewed in separate sessions. Do not review, " +
+		"change, or add tests for code outside these paths, except where a fix here requires it. " +
+		"Apply the instructions above only to these changes.\n\n")
+	b.WriteString("## Changes Under Review\n\n")
+	b.WriteString(fence("diff", diff))
+	return b.String()
 }
 
 // ReviewList prints tasks in review state.
@@ -257,63 +526,186 @@ func (r *Runner) ReviewEdit(taskName, editor string) error {
 	return design.RunEditorOnFile(editor, task.FilePath, os.Stdin, os.Stdout, os.Stderr)
 }
 
+// ReviewComment opens the editor for a note on a task in review state and
+// appends it to the task's comments file, to be addressed by the next
+// review session. An empty note is discarded.
+func (r *Runner) ReviewComment(taskName, editor string) error {
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+	if err != nil {
+		return err
+	}
+
+	comment, err := editNote(editor, "hydra-comment-*.md")
+	if err != nil {
+		return err
+	}
+	if comment == "" {
+		fmt.Println("Empty comment, nothing added.")
+		return nil
+	}
+
+	if err := r.Design.AddComment(task, comment); err != nil {
+		return err
+	}
+	fmt.Printf("Comment added to %q for the next review session.\n", taskName)
+	return nil
+}
+
+// editNote opens the editor on an empty temporary file named after pattern
+// and returns what was written, trimmed.
+func editNote(editor, pattern string) (string, error) {
+	tmp, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not close temp file: %v\n", err)
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := design.RunEditorOnFile(editor, tmpPath, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		return "", err
+	}
+	note, err := os.ReadFile(tmpPath) //nolint:gosec // path is from our own temp file
+	if err != nil {
+		return "", fmt.Errorf("reading temp file: %w", err)
+	}
+	return strings.TrimSpace(string(note)), nil
+}
+
 // ReviewDiff fetches the latest remote and shows the git diff between
-// origin/main and the task's branch.
+// origin/main and the task's branch. With ByCommit set, it shows each commit
+// on the branch with its message and its own diff instead, through $PAGER.
 func (r *Runner) ReviewDiff(taskName string) error {
 	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
 	if err != nil {
 		return err
 	}
 
+	if r.ByCommit {
+		taskRepo, base, err := r.branchRepo(task)
+		if err != nil {
+			return err
+		}
+		commits, err := taskRepo.CommitDiffs(base, task.BranchName())
+		if err != nil {
+			return fmt.Errorf("listing commits: %w", err)
+		}
+		if len(commits) == 0 {
+			fmt.Println("No changes.")
+			return nil
+		}
+		page(formatCommitDiffs(commits))
+		return nil
+	}
+
+	diff, err := r.branchDiff(task)
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	fmt.Println(diff)
+	return nil
+}
+
+// branchDiff fetches the latest remote and returns the diff between the
+// default branch and the task's branch.
+func (r *Runner) branchDiff(task *design.Task) (string, error) {
+	taskRepo, base, err := r.branchRepo(task)
+	if err != nil {
+		return "", err
+	}
+	diff, err := taskRepo.DiffRange(base, task.BranchName())
+	if err != nil {
+		return "", fmt.Errorf("getting diff: %w", err)
+	}
+	return diff, nil
+}
+
+// branchRepo prepares the task's work directory, checks out its branch if
+// the tree is clean, and fetches. It returns the work directory's repository
+// and the remote default branch the task's branch is compared against.
+func (r *Runner) branchRepo(task *design.Task) (repo.VCS, string, error) {
 	wd := r.workDir(task)
-	taskRepo, err := r.prepareRepo(wd)
+	taskRepo, err := r.prepareRepo(wd, task.BranchName())
 	if err != nil {
-		return fmt.Errorf("preparing work directory: %w", err)
+		return nil, "", fmt.Errorf("preparing work directory: %w", err)
 	}
 
 	branch := task.BranchName()
 	if !taskRepo.BranchExists(branch) {
-		return fmt.Errorf("branch %q does not exist", branch)
+		return nil, "", fmt.Errorf("branch %q does not exist", branch)
 	}
 	dirty, err := taskRepo.HasChanges()
 	if err != nil {
-		return fmt.Errorf("checking working tree: %w", err)
+		return nil, "", fmt.Errorf("checking working tree: %w", err)
 	}
 	if !dirty {
 		if err := taskRepo.Checkout(branch); err != nil {
-			return fmt.Errorf("checking out branch: %w", err)
+			return nil, "", fmt.Errorf("checking out branch: %w", err)
 		}
 	}
 
 	if err := taskRepo.Fetch(); err != nil {
-		return fmt.Errorf("fetching: %w", err)
+		return nil, "", fmt.Errorf("fetching: %w", err)
 	}
 
 	defaultBranch, err := r.detectDefaultBranch(taskRepo)
 	if err != nil {
-		return fmt.Errorf("detecting default branch: %w", err)
+		return nil, "", fmt.Errorf("detecting default branch: %w", err)
 	}
+	return taskRepo, "origin/" + defaultBranch, nil
+}
 
-	diff, err := taskRepo.DiffRange("origin/"+defaultBranch, branch)
+// ReviewRemove moves a task from review to abandoned, once confirmed, and
+// offers to clean up its work directory, remote branch, and issue.
+func (r *Runner) ReviewRemove(taskName string, opts AbandonOpts) error {
+	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
 	if err != nil {
-		return fmt.Errorf("getting diff: %w", err)
+		return err
 	}
-
-	if diff == "" {
-		fmt.Println("No changes.")
+	if !confirmAbandon(task, opts) {
+		fmt.Println("Aborted.")
 		return nil
 	}
 
-	fmt.Println(diff)
-	return nil
+	return r.abandonTask(task, opts)
 }
 
-// ReviewRemove moves a task from review to abandoned.
-func (r *Runner) ReviewRemove(taskName string) error {
-	task, err := r.Design.FindTaskByState(taskName, design.StateReview)
+// precheckRebase fetches origin and trial-rebases the task branch onto the
+// default branch in a temporary worktree, printing any files that would
+// conflict. It returns false if conflicts were found and the user declined
+// to continue.
+func (r *Runner) precheckRebase(taskRepo repo.VCS, branch string) (bool, error) {
+	if err := taskRepo.Fetch(); err != nil {
+		return false, fmt.Errorf("fetching origin: %w", err)
+	}
+
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("detecting default branch: %w", err)
+	}
+	originRef := "origin/" + defaultBranch
+
+	conflictFiles, err := taskRepo.TrialRebase(branch, originRef)
+	if err != nil {
+		return false, fmt.Errorf("trial rebase onto %s: %w", originRef, err)
 	}
 
-	return r.Design.MoveTask(task, design.StateAbandoned)
+	if len(conflictFiles) == 0 {
+		fmt.Printf("Precheck: %s rebases cleanly onto %s\n", branch, originRef)
+		return true, nil
+	}
+
+	fmt.Printf("Precheck: rebasing %s onto %s would conflict in:\n", branch, originRef)
+	for _, f := range conflictFiles {
+		fmt.Printf("  %s\n", f)
+	}
+	return Confirm("Start the review session anyway?"), nil
 }
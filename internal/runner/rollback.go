@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/issues"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// lastMerge returns the record entry of the most recent merge of the task
+// labeled label.
+func lastMerge(entries []design.RecordEntry, label string) (design.RecordEntry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].TaskName == "merge:"+label {
+			return entries[i], true
+		}
+	}
+	return design.RecordEntry{}, false
+}
+
+// MergeRollback undoes the merge of a completed task. It reverts the task's
+// commits on the default branch and pushes the reverts, or with reset,
+// moves the default branch back to where it was before the merge and
+// force-pushes it, which is only allowed while nothing has landed on top of
+// the merge. The task's branch is pushed again with its changes reapplied on
+// top, the task moves back to review, the rollback is recorded in
+// record.json, and the task's issue, if any, is reopened.
+func (r *Runner) MergeRollback(taskName string, reset bool) error {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	hydraDir := config.HydraPath(baseDir)
+
+	task, err := r.Design.FindTaskByState(taskName, design.StateCompleted)
+	if err != nil {
+		return err
+	}
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
+	label := taskLabel(*task)
+
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		return err
+	}
+	merge, ok := lastMerge(entries, label)
+	if !ok {
+		return fmt.Errorf("no merge of %q in record.json", label)
+	}
+	if merge.BaseSHA == "" {
+		return fmt.Errorf("the merge of %q at %s was recorded without the commit it landed on; revert it by hand", label, shortSHA(merge.SHA))
+	}
+
+	lk := lock.New(hydraDir, "merge:"+taskName)
+	if err := lk.Acquire(); err != nil {
+		return err
+	}
+	defer func() { _ = lk.Release() }()
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
+
+	wd := r.workDir(task)
+	branch := task.BranchName()
+	taskRepo, err := r.prepareRepo(wd, branch)
+	if err != nil {
+		return fmt.Errorf("preparing work directory: %w", err)
+	}
+	dirty, err := taskRepo.HasChanges()
+	if err != nil {
+		return fmt.Errorf("checking working tree: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("work directory %s has uncommitted changes; commit or discard them first", wd)
+	}
+
+	if err := taskRepo.Fetch(); err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+	defaultBranch, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+	originRef := "origin/" + defaultBranch
+	if !taskRepo.IsAncestor(merge.SHA, originRef) {
+		return fmt.Errorf("the merge of %q at %s is not on %s", label, shortSHA(merge.SHA), originRef)
+	}
+	if err := taskRepo.Checkout(defaultBranch); err != nil {
+		return fmt.Errorf("checking out %s: %w", defaultBranch, err)
+	}
+	if err := r.rebaseOnUpstream(taskRepo, originRef); err != nil {
+		return inCategory(categoryRebase, fmt.Errorf("rebasing %s against %s: %w", defaultBranch, originRef, err))
+	}
+
+	if reset {
+		head, err := taskRepo.LastCommitSHA()
+		if err != nil {
+			return fmt.Errorf("getting %s SHA: %w", defaultBranch, err)
+		}
+		if head != merge.SHA {
+			return fmt.Errorf("cannot reset %s: commits landed after the merge of %q; roll back without --reset to revert it instead", defaultBranch, label)
+		}
+		if err := taskRepo.ResetHard(merge.BaseSHA); err != nil {
+			return fmt.Errorf("resetting %s: %w", defaultBranch, err)
+		}
+		if err := taskRepo.ForcePushWithLease(defaultBranch); err != nil {
+			return inCategory(categoryPush, fmt.Errorf("force-pushing %s: %w", defaultBranch, err))
+		}
+	} else {
+		if err := taskRepo.Revert(merge.BaseSHA, merge.SHA); err != nil {
+			return fmt.Errorf("reverting the merge of %q: %w", label, err)
+		}
+		if err := taskRepo.PushMain(); err != nil {
+			return inCategory(categoryPush, fmt.Errorf("pushing main: %w", err))
+		}
+	}
+	sha, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		return fmt.Errorf("getting commit SHA: %w", err)
+	}
+
+	how := "reverted"
+	if reset {
+		how = "reset away"
+	}
+
+	// The default branch has changed on origin, so the rollback is
+	// recorded either way. Without its changes on the branch, the task
+	// can't be reviewed again, so it stays completed.
+	restoreErr := restoreBranch(taskRepo, branch, merge, sha, reset)
+	if err := r.Design.Record().AddEntry(design.RecordEntry{SHA: sha, TaskName: "rollback:" + label}); err != nil {
+		return fmt.Errorf("recording rollback: %w", err)
+	}
+	if restoreErr != nil {
+		return fmt.Errorf("merge of %q %s on %s at %s, but restoring branch %q failed, so the task stays completed: %w",
+			label, how, defaultBranch, shortSHA(sha), branch, restoreErr)
+	}
+	if err := r.Design.MoveTask(task, design.StateReview); err != nil {
+		return fmt.Errorf("moving task to review: %w", err)
+	}
+	r.reopenIssueIfNeeded(task, sha)
+
+	fmt.Printf("Merge of %q %s on %s. SHA: %s\n", label, how, defaultBranch, shortSHA(sha))
+	fmt.Printf("Task moved back to review; branch %s holds its changes.\n", branch)
+	return nil
+}
+
+// restoreBranch points the task's branch at its changes again and pushes
+// it: at the merged commits after a reset, or reapplied on top of the
+// reverts at head.
+func restoreBranch(taskRepo repo.VCS, branch string, merge design.RecordEntry, head string, reset bool) error {
+	if err := taskRepo.Checkout(branch); err != nil {
+		return err
+	}
+	if reset {
+		if err := taskRepo.ResetHard(merge.SHA); err != nil {
+			return err
+		}
+	} else {
+		if err := taskRepo.ResetHard(head); err != nil {
+			return err
+		}
+		if err := taskRepo.CherryPick(merge.BaseSHA, merge.SHA); err != nil {
+			return err
+		}
+	}
+	return taskRepo.ForcePushWithLease(branch)
+}
+
+// reopenIssueIfNeeded reopens the remote issue of an issue task whose merge
+// was rolled back.
+func (r *Runner) reopenIssueIfNeeded(task *design.Task, sha string) {
+	num := issues.IssueNumber(task)
+	if num == 0 || r.IssueCloser == nil {
+		return
+	}
+	reopener, ok := r.IssueCloser.(issues.Reopener)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Warning: cannot reopen issue #%d with this issue tracker\n", num)
+		return
+	}
+	comment := "Reopened by hydra: the merge was rolled back. Commit: " + sha
+	if err := reopener.ReopenIssue(num, comment); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not reopen issue #%d: %v\n", num, err)
+	}
+}
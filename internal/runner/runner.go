@@ -2,32 +2,62 @@
 package runner
 
 import (
+	"bufio"
 	"context"
-	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/erikh/hydra/internal/claude"
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/issues"
 	"github.com/erikh/hydra/internal/lock"
 	"github.com/erikh/hydra/internal/repo"
 	"github.com/erikh/hydra/internal/taskrun"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ClaudeRunConfig holds the parameters for a Claude invocation.
 type ClaudeRunConfig struct {
-	RepoDir    string
-	Document   string
-	Model      string
-	AutoAccept bool
-	PlanMode   bool
-	ForceTUI   bool
+	RepoDir      string
+	Document     string
+	Model        string
+	AutoAccept   bool
+	PlanMode     bool
+	ForceTUI     bool
+	Headless     bool                  // run without a terminal, approving tool calls and streaming output to stdout
+	Approval     []claude.ApprovalRule // approval rules from hydra.yml; sessions with rules use the built-in TUI
+	TaskName     string                // lock-style task name (e.g. "review:foo"), used to name crash logs
+	HydraDir     string                // .hydra directory; crash logs are saved under its crash/ subdirectory
+	Timeout      time.Duration         // session deadline; zero for none
+	WrapUp       time.Duration         // how long before the deadline Claude is told to commit and stop
+	Tokens       *int64                // if set, receives the API tokens the session used, when known
+	Fallbacks    []string              // models to switch to, in order, if Model stays overloaded
+	UsedModel    *string               // if set, receives the model that finished the session
+	Status       *statusReporter       // if set, the session's entry in .hydra/status.json; otherwise one is made from TaskName
+	FinalMessage *string               // if set, receives Claude's final message, when known
+
+	// Shell builds the commands Claude's bash tool runs, e.g. in a
+	// container; nil runs them on the host. Sessions with a Shell use the
+	// built-in TUI, since the Claude Code CLI runs its own tools.
+	Shell func(command string) *exec.Cmd
+
+	// Output is the runner's command log. While the built-in TUI has the
+	// terminal, the output of hydra.yml commands hydra runs is shown in it,
+	// after those that ran before the session.
+	Output *commandLog
+
+	// State is Claude's read-only hydra_state tool for the session's task;
+	// nil for sessions not about one task.
+	State *StateTool
 }
 
 // ClaudeFunc is the function signature for invoking claude.
@@ -35,23 +65,48 @@ type ClaudeFunc func(ctx context.Context, cfg ClaudeRunConfig) error
 
 // Runner orchestrates the full hydra run workflow.
 type Runner struct {
-	Config      *config.Config
-	Design      *design.Dir
-	Claude      ClaudeFunc
-	TaskRunner  *taskrun.Commands // loaded from hydra.yml; nil if not present
-	BaseDir     string            // working directory for lock file; defaults to "."
-	Model       string            // model name override
-	AutoAccept  bool              // auto-accept all tool calls
-	PlanMode    bool              // start Claude in plan mode
-	ForceTUI    bool              // force built-in TUI instead of Claude Code CLI
-	Rebase      bool              // rebase onto origin/main before running
-	Notify      bool              // send desktop notifications on confirmation
-	IssueCloser issues.Closer     // set by merge workflow
+	Config       *config.Config
+	Design       *design.Dir
+	Claude       ClaudeFunc
+	TaskRunner   *taskrun.Commands // loaded from hydra.yml; nil if not present
+	BaseDir      string            // working directory for lock file; defaults to "."
+	WorkRoot     string            // where work directories live, overriding work_dir in hydra.yml
+	Model        string            // model name override
+	AutoAccept   bool              // auto-accept all tool calls
+	PlanMode     bool              // start Claude in plan mode
+	ForceTUI     bool              // force built-in TUI instead of Claude Code CLI
+	Headless     bool              // run sessions without a terminal, for hydra run --detach
+	Rebase       bool              // rebase onto origin/main before running
+	Notify       bool              // send desktop notifications on confirmation
+	IssueCloser  issues.Closer     // set by merge workflow
+	TestOnly     string            // test pattern for focused test sessions (hydra test --only)
+	Precheck     bool              // trial-rebase before review and report conflicts (hydra review run --precheck)
+	UsePlan      bool              // execute the plan saved by hydra plan instead of planning (hydra run --use-plan)
+	PlanHandoff  bool              // plan first and wait for hydra plan approve before executing (hydra run --plan-handoff)
+	ConfirmCost  func() bool       // asks whether to start a run after its estimate is printed (hydra run --confirm-cost)
+	Focus        []string          // paths a review session is limited to (hydra review run --focus)
+	Persona      string            // reviewer persona a review session takes on (hydra review run --persona)
+	ByCommit     bool              // split the branch's changes by commit (hydra review run/diff --by-commit)
+	ForceSetup   bool              // rerun the setup command in work directories that already ran it (--force-setup)
+	VerifyReport bool              // save the outcome of Verify to state/verify.json (hydra verify --report)
+	VerifySince  string            // verify only requirements touched since this commit or date (hydra verify --since)
+	WaitInUse    bool              // wait for hydra review dev to stop serving a work directory instead of refusing (--wait-in-use)
+	RebaseOnto   bool              // rebase only a branch's own commits onto a force-pushed origin branch without asking (--rebase-onto)
+
+	output commandLog // where hydra.yml commands' output goes; see commands
+
+	configGroup  string // group whose hydra.yml overrides are loaded into TaskRunner
+	baseBranch   string // configGroup's base: branch from group.md; empty for the default branch
+	modelFromCLI bool   // Model was given on the command line; see SetModel
+
+	localeSet    bool              // cachedLocale is set; see locale
+	localeFor    *taskrun.Commands // TaskRunner that cachedLocale was made for
+	cachedLocale *locale
 }
 
 // New creates a Runner from the given config.
 func New(cfg *config.Config) (*Runner, error) {
-	dd, err := design.NewDir(cfg.DesignDir())
+	dd, err := design.NewDir(cfg.DesignDir)
 	if err != nil {
 		return nil, err
 	}
@@ -64,34 +119,108 @@ func New(cfg *config.Config) (*Runner, error) {
 		Rebase:  true,
 	}
 
-	if err := r.loadHydraYml(cfg); err != nil {
+	if err := r.loadHydraYml(cfg, ""); err != nil {
 		return nil, err
 	}
 
+	r.configurePushRemote()
+	if r.TaskRunner.DesignAutoCommit {
+		dd.EnableAutoCommit(r.TaskRunner.DesignAutoPush)
+	}
+
 	return r, nil
 }
 
+// configurePushRemote applies the push_remote setting from hydra.yml to the
+// main repository. Work directories are worktrees of it and share its git
+// config, so hydra/* branches pushed from any of them go to the fork.
+func (r *Runner) configurePushRemote() {
+	if r.TaskRunner == nil || r.Config.RepoDir == "" || !repo.IsGitRepo(r.Config.RepoDir) {
+		return
+	}
+	if err := repo.Open(r.Config.RepoDir).ConfigurePushRemote(r.TaskRunner.PushRemote); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not configure push remote: %v\n", err)
+	}
+}
+
 // loadHydraYml loads hydra.yml and resolves issue closer.
 // If the file does not exist, it is created with placeholder content.
-func (r *Runner) loadHydraYml(cfg *config.Config) error {
-	if err := design.EnsureHydraYml(cfg.DesignDir()); err != nil {
+// When group is set and tasks/<group>/hydra.yml exists, its model and
+// commands are layered over the root config.
+func (r *Runner) loadHydraYml(cfg *config.Config, group string) error {
+	if err := design.EnsureHydraYml(cfg.DesignDir); err != nil {
 		return fmt.Errorf("ensuring hydra.yml: %w", err)
 	}
-	ymlPath := filepath.Join(cfg.DesignDir(), "hydra.yml")
+	ymlPath := filepath.Join(cfg.DesignDir, "hydra.yml")
 
 	cmds, err := taskrun.Load(ymlPath)
 	if err != nil {
 		return fmt.Errorf("loading hydra.yml: %w", err)
 	}
-	r.TaskRunner = cmds
-	if cmds.Model != "" {
+
+	if group != "" {
+		groupPath := filepath.Join(cfg.DesignDir, "tasks", group, "hydra.yml")
+		if _, err := os.Stat(groupPath); err == nil {
+			groupCmds, err := taskrun.Load(groupPath)
+			if err != nil {
+				return fmt.Errorf("loading %s/hydra.yml: %w", group, err)
+			}
+			cmds = cmds.Overlay(groupCmds)
+		}
+	}
+
+	if !r.modelFromCLI {
 		r.Model = cmds.Model
 	}
+	r.TaskRunner = cmds
 
 	r.resolveIssueCloser(cfg.SourceRepoURL, cmds.APIType, cmds.GiteaURL)
 	return nil
 }
 
+// SetModel sets the model given on the command line. It replaces the one
+// from hydra.yml and survives reloading hydra.yml for a group.
+func (r *Runner) SetModel(model string) {
+	r.Model = model
+	r.modelFromCLI = true
+}
+
+// useGroupConfig reloads hydra.yml with the task's group overrides applied,
+// or without any for an ungrouped task. It is a no-op when that config is
+// already loaded.
+func (r *Runner) useGroupConfig(task *design.Task) error {
+	if task.Group == r.configGroup {
+		return nil
+	}
+	if err := r.loadHydraYml(r.Config, task.Group); err != nil {
+		return err
+	}
+	base, err := r.Design.GroupBaseBranch(task.Group)
+	if err != nil {
+		return err
+	}
+	r.configGroup = task.Group
+	r.baseBranch = base
+	return nil
+}
+
+// modelFallbacks returns the models to fall back to, from model_fallbacks in
+// hydra.yml.
+func (r *Runner) modelFallbacks() []string {
+	if r.TaskRunner == nil {
+		return nil
+	}
+	return r.TaskRunner.ModelFallbacks
+}
+
+// approvalRules returns the tool approval rules from hydra.yml.
+func (r *Runner) approvalRules() []claude.ApprovalRule {
+	if r.TaskRunner == nil {
+		return nil
+	}
+	return r.TaskRunner.Approval
+}
+
 // timeout returns the configured task timeout, or zero if none is set.
 func (r *Runner) timeout() time.Duration {
 	if r.TaskRunner != nil && r.TaskRunner.Timeout != nil {
@@ -100,6 +229,21 @@ func (r *Runner) timeout() time.Duration {
 	return 0
 }
 
+// wrapUp returns how long before the timeout Claude is told to wrap up:
+// the configured wrap_up, or defaultWrapUp, but never more than half the
+// timeout. Zero if no timeout is set.
+func (r *Runner) wrapUp() time.Duration {
+	timeout := r.timeout()
+	if timeout <= 0 {
+		return 0
+	}
+	wrap := defaultWrapUp
+	if r.TaskRunner.WrapUp != nil {
+		wrap = r.TaskRunner.WrapUp.Duration
+	}
+	return min(max(wrap, 0), timeout/2)
+}
+
 // resolveIssueCloser attempts to set the issue closer from the source URL.
 func (r *Runner) resolveIssueCloser(repoURL, apiType, giteaURL string) {
 	source, err := issues.ResolveSource(repoURL, apiType, giteaURL)
@@ -118,19 +262,36 @@ func (r *Runner) commandsMap(workDir string) map[string]string {
 	return nil
 }
 
+// serialChecks reports whether test and lint must run one at a time.
+func (r *Runner) serialChecks() bool {
+	return r.TaskRunner != nil && r.TaskRunner.SerialChecks
+}
+
 // notifyTitle returns a notification title like "repo: taskName".
 func (r *Runner) notifyTitle(taskName string) string {
 	repoName := path.Base(strings.TrimSuffix(r.Config.SourceRepoURL, ".git"))
 	return repoName + ": " + taskName
 }
 
+// toolShell returns the Shell for a Claude session in workDir: commands run
+// in a container with executor: docker in hydra.yml, and on the host (nil)
+// otherwise.
+func (r *Runner) toolShell(workDir string) func(string) *exec.Cmd {
+	if !r.TaskRunner.Containerized() {
+		return nil
+	}
+	return func(command string) *exec.Cmd {
+		return r.TaskRunner.Command(context.Background(), workDir, command)
+	}
+}
+
 // runTeardown runs the "teardown" command from hydra.yml if configured.
 // This runs in a work directory before it is removed.
 func (r *Runner) runTeardown(workDir string) {
 	if r.TaskRunner == nil {
 		return
 	}
-	if err := r.TaskRunner.RunTeardown(workDir); err != nil {
+	if err := r.commands().RunTeardown(workDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: teardown failed in %s: %v\n", workDir, err)
 	}
 }
@@ -141,28 +302,53 @@ func (r *Runner) runBeforeHook(workDir string) error {
 	if r.TaskRunner == nil {
 		return nil
 	}
-	return r.TaskRunner.Run("before", workDir)
+	return r.commands().Run("before", workDir)
 }
 
 // workDir returns the work directory path for a task.
-// Ungrouped tasks: .hydra/work/{name}, grouped tasks: .hydra/work/{group}/{name}.
+// Ungrouped tasks: {work root}/{name}, grouped tasks: {work root}/{group}/{name}.
 func (r *Runner) workDir(task *design.Task) string {
+	if task.Group != "" {
+		return filepath.Join(r.workRoot(), task.Group, task.Name)
+	}
+	return filepath.Join(r.workRoot(), task.Name)
+}
+
+// workRoot returns the directory work directories live in: WorkRoot if
+// set, else work_dir from hydra.yml, else .hydra/work.
+func (r *Runner) workRoot() string {
+	if r.WorkRoot != "" {
+		return r.WorkRoot
+	}
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
 	}
-	if task.Group != "" {
-		return filepath.Join(baseDir, config.HydraDir, "work", task.Group, task.Name)
-	}
-	return filepath.Join(baseDir, config.HydraDir, "work", task.Name)
+	return WorkRoot(baseDir, r.TaskRunner)
 }
 
-// prepareRepo sets up the work directory for a task using git worktrees.
+// prepareRepo sets up the work directory for a task using git worktrees,
+// or colocated Jujutsu clones with vcs: jj in hydra.yml.
 // If the directory exists and is a valid git repo (worktree), it fetches.
 // Otherwise, it creates a new worktree from the main repo.
 // The branchName parameter is used when creating a new worktree.
-func (r *Runner) prepareRepo(workDir, branchName string) (*repo.Repo, error) {
+// The setup command from hydra.yml then runs if the directory hasn't been
+// set up yet.
+func (r *Runner) prepareRepo(workDir, branchName string) (repo.VCS, error) {
+	taskRepo, err := r.checkoutWorkDir(workDir, branchName)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.runSetup(taskRepo); err != nil {
+		return nil, err
+	}
+	return taskRepo, nil
+}
+
+// checkoutWorkDir syncs or creates the work directory for prepareRepo.
+func (r *Runner) checkoutWorkDir(workDir, branchName string) (repo.VCS, error) {
 	if taskRepo, ok := r.trySyncExisting(workDir); ok {
+		updateCheckout(taskRepo)
 		return taskRepo, nil
 	}
 
@@ -171,8 +357,12 @@ func (r *Runner) prepareRepo(workDir, branchName string) (*repo.Repo, error) {
 		return nil, fmt.Errorf("creating work dir parent: %w", err)
 	}
 
+	if r.TaskRunner != nil && r.TaskRunner.VCS == repo.BackendJJ {
+		return r.prepareJJRepo(workDir, branchName)
+	}
+
 	// Open the main repo and create a worktree.
-	mainRepo := repo.Open(r.Config.BaseDir)
+	mainRepo := repo.Open(r.Config.RepoDir)
 	if err := mainRepo.Fetch(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: fetch failed: %v\n", err)
 	}
@@ -186,14 +376,75 @@ func (r *Runner) prepareRepo(workDir, branchName string) (*repo.Repo, error) {
 		if err := mainRepo.WorktreeAdd(workDir, branchName); err != nil {
 			return nil, fmt.Errorf("creating worktree: %w", err)
 		}
+		if err := r.startFromBase(repo.Open(workDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	taskRepo := repo.Open(workDir)
+	updateCheckout(taskRepo)
+	return taskRepo, nil
+}
+
+// startFromBase moves a newly created task branch to the group's base
+// branch on origin, if the group has one, or else to the default branch on
+// origin, default_branch if it is set.
+func (r *Runner) startFromBase(taskRepo repo.VCS) error {
+	base, err := r.detectDefaultBranch(taskRepo)
+	if err != nil {
+		return err
+	}
+	if err := taskRepo.ResetHard("origin/" + base); err != nil {
+		return fmt.Errorf("starting branch from %s: %w", base, err)
 	}
+	return nil
+}
+
+// prepareJJRepo clones the source repository into workDir as a Jujutsu
+// repository colocated with git, and checks out branchName, creating it from
+// the default branch, or the group's base branch, if it doesn't exist yet. jj can't colocate with a git
+// worktree, so each work directory is a clone of its own.
+func (r *Runner) prepareJJRepo(workDir, branchName string) (repo.VCS, error) {
+	url, err := repo.Open(r.Config.RepoDir).RemoteURL()
+	if err != nil {
+		return nil, fmt.Errorf("finding source repository: %w", err)
+	}
+	taskRepo, err := repo.CloneJJ(url, workDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating jj work directory: %w", err)
+	}
+	// A clone doesn't share the main repository's git config.
+	if err := taskRepo.ConfigurePushRemote(r.TaskRunner.PushRemote); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not configure push remote: %v\n", err)
+	}
+
+	if taskRepo.BranchExists("origin/" + branchName) {
+		err = taskRepo.Checkout(branchName)
+	} else if err = taskRepo.CreateBranch(branchName); err == nil {
+		err = r.startFromBase(taskRepo)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking out %s: %w", branchName, err)
+	}
+	updateCheckout(taskRepo)
+	return taskRepo, nil
+}
 
-	return repo.Open(workDir), nil
+// updateCheckout brings a work directory's submodules and Git LFS objects
+// in line with its checked-out tree. Failures are reported as warnings, since
+// many tasks don't touch the parts of the tree they cover.
+func updateCheckout(taskRepo repo.VCS) {
+	if err := taskRepo.UpdateSubmodules(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: submodule update failed: %v\n", err)
+	}
+	if err := taskRepo.PullLFS(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: git lfs pull failed: %v\n", err)
+	}
 }
 
 // trySyncExisting attempts to sync an existing work directory.
 // Returns the repo and true if successful, or nil and false if a fresh worktree is needed.
-func (r *Runner) trySyncExisting(workDir string) (*repo.Repo, bool) {
+func (r *Runner) trySyncExisting(workDir string) (repo.VCS, bool) {
 	info, err := os.Stat(workDir)
 	if err != nil || !info.IsDir() {
 		return nil, false
@@ -207,21 +458,15 @@ func (r *Runner) trySyncExisting(workDir string) (*repo.Repo, bool) {
 	}
 
 	// Not a git repo or sync failed; teardown and remove it.
-	r.runTeardown(workDir)
-	// Try to remove the worktree cleanly first.
-	mainRepo := repo.Open(r.Config.BaseDir)
-	if err := mainRepo.WorktreeRemove(workDir); err != nil {
-		// Fall back to direct removal.
-		if rmErr := os.RemoveAll(workDir); rmErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: could not remove %s: %v\n", workDir, rmErr)
-		}
+	if err := r.removeWorkDir(workDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not remove %s: %v\n", workDir, err)
 	}
 	return nil, false
 }
 
 // syncGitRepo fetches an existing git repo without resetting the working tree.
-func (r *Runner) syncGitRepo(workDir string) (*repo.Repo, error) {
-	taskRepo := repo.Open(workDir)
+func (r *Runner) syncGitRepo(workDir string) (repo.VCS, error) {
+	taskRepo := repo.OpenVCS(workDir)
 	if err := taskRepo.Fetch(); err != nil {
 		return nil, err
 	}
@@ -229,8 +474,10 @@ func (r *Runner) syncGitRepo(workDir string) (*repo.Repo, error) {
 }
 
 // resetWorktree forces a worktree to a clean state on the given remote ref.
-// It aborts any in-progress rebase, hard-resets to the ref, and removes untracked files.
-func (r *Runner) resetWorktree(taskRepo *repo.Repo, remoteRef string) error {
+// It aborts any in-progress rebase, hard-resets to the ref, and removes
+// untracked files. Cleaning removes what the setup command made too, so
+// setup runs again afterwards.
+func (r *Runner) resetWorktree(taskRepo repo.VCS, remoteRef string) error {
 	_ = taskRepo.RebaseAbort() // safe no-op if not mid-rebase
 	if err := taskRepo.ResetHard(remoteRef); err != nil {
 		return fmt.Errorf("resetting to %s: %w", remoteRef, err)
@@ -238,11 +485,67 @@ func (r *Runner) resetWorktree(taskRepo *repo.Repo, remoteRef string) error {
 	if err := taskRepo.Clean(); err != nil {
 		return fmt.Errorf("cleaning working tree: %w", err)
 	}
-	return nil
+	if marker, err := setupMarkerPath(taskRepo); err == nil {
+		_ = os.Remove(marker)
+	}
+	return r.runSetup(taskRepo)
 }
 
 // Run executes the full task lifecycle: lock, branch, assemble, claude, test, lint, commit, push, record, move to review.
 func (r *Runner) Run(taskName string) error {
+	return r.runTask(context.Background(), taskName)
+}
+
+// RunTasks runs several tasks one after another, as if by separate hydra run
+// invocations. A task that fails, including one whose lock is held by
+// another hydra, does not stop the batch. A summary of every task's outcome
+// is printed at the end, and an error is returned if any task failed.
+func (r *Runner) RunTasks(taskNames []string) (err error) {
+	seen := make(map[string]bool, len(taskNames))
+	for _, name := range taskNames {
+		if seen[name] {
+			return fmt.Errorf("task %s given more than once", name)
+		}
+		seen[name] = true
+		if _, err := r.Design.FindTask(name); err != nil {
+			return err
+		}
+	}
+
+	var perTask time.Duration
+	if entries, err := design.NewRecord(r.Design.Path).Entries(); err == nil {
+		perTask = estimateFromRecord(entries, len(taskNames), 0).AvgDuration
+	}
+
+	ctx, span := tracing.Start(context.Background(), "batch run", attribute.Int("hydra.tasks", len(taskNames)))
+	defer func() { tracing.End(span, err) }()
+
+	results := make([]batchResult, 0, len(taskNames))
+	progress := newGroupProgress(os.Stdout, len(taskNames), perTask)
+	defer r.startHeartbeat(ctx, "batch run", progress)()
+	for _, name := range taskNames {
+		progress.begin(name)
+		start := time.Now()
+		runErr := r.runTask(ctx, name)
+		results = append(results, batchResult{task: name, err: runErr, elapsed: time.Since(start)})
+		if runErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: task %s: %v\n", name, runErr)
+		}
+		progress.finish(name)
+	}
+
+	failed := printBatchSummary(os.Stdout, results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d task(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// runTask implements Run, tracing the run as a child of any span in ctx.
+func (r *Runner) runTask(ctx context.Context, taskName string) (err error) {
+	ctx, span := tracing.Start(ctx, "run", attribute.String("hydra.task", taskName))
+	defer func() { tracing.End(span, err) }()
+
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -254,6 +557,10 @@ func (r *Runner) Run(taskName string) error {
 	if err != nil {
 		return err
 	}
+	defer func() { r.recordFailure("run", taskName, err) }()
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
 
 	// Acquire lock
 	lk := lock.New(hydraDir, taskName)
@@ -261,14 +568,23 @@ func (r *Runner) Run(taskName string) error {
 		return err
 	}
 	defer func() { _ = lk.Release() }()
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
+	status := startStatus(hydraDir, taskName)
+	defer status.Done()
+	status.Phase(phasePreparing)
 
 	// Prepare work directory
 	wd := r.workDir(task)
 	branch := task.BranchName()
+	_, prepSpan := tracing.Start(ctx, "prepare work dir", attribute.String("hydra.work_dir", wd))
 	taskRepo, err := r.prepareRepo(wd, branch)
+	tracing.End(prepSpan, err)
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
 	}
+	taskRepo = taskRepo.Traced(ctx)
 
 	// Check out existing task branch, or create a new one.
 	// If the working tree is dirty, skip branch operations — let Claude work on it as-is.
@@ -277,50 +593,86 @@ func (r *Runner) Run(taskName string) error {
 	}
 
 	// Rebase onto latest remote main if requested (only if clean tree).
-	var conflictFiles []string
+	var conflicts []repo.Conflict
 	dirty, err := taskRepo.HasChanges()
 	if err != nil {
 		return fmt.Errorf("checking working tree: %w", err)
 	}
 	if r.Rebase && !dirty {
-		conflictFiles, err = r.attemptRebase(taskRepo)
+		conflicts, err = r.attemptRebase(taskRepo)
 		if err != nil {
-			return fmt.Errorf("rebasing onto main: %w", err)
+			return inCategory(categoryRebase, fmt.Errorf("rebasing onto main: %w", err))
 		}
 	}
 
 	// Read task content and assemble document
-	content, err := task.Content()
+	doc, err := r.assembleTaskDocument(ctx, task)
 	if err != nil {
 		return err
 	}
 
-	groupContent, err := r.Design.GroupContent(task.Group)
+	doc += r.conflictSection(conflicts)
+	doc += prepareNotes(wd)
+
+	// Verification and commit instructions so Claude handles test/lint/commit.
+	planMode := r.PlanMode
+	handoff := !r.UsePlan && r.planHandoff()
+	sign := taskRepo.HasSigningKey()
+	cmds := r.commandsMap(wd)
+	commitTmpl, err := r.commitTemplate(task)
 	if err != nil {
-		return fmt.Errorf("reading group content: %w", err)
+		return err
 	}
+	suffix := noChangesSection() + documentSuffix(suffixOpts{
+		Commands:     cmds,
+		SerialChecks: r.serialChecks(),
+		Base:         r.targetBranch(),
+		Sign:         sign,
+		Timeout:      r.timeout(),
+		Notify:       r.Notify,
+		NotifyTitle:  r.notifyTitle(taskName),
+		SkipPlanMode: r.UsePlan || handoff || r.Headless,
+		CommitTmpl:   commitTmpl,
+		Locale:       r.locale(),
+	})
 
-	doc, err := r.Design.AssembleDocument(content, groupContent)
-	if err != nil {
-		return fmt.Errorf("assembling document: %w", err)
+	// Estimate before any session starts, the planning session of a plan
+	// handoff included.
+	if err := r.estimateTaskRun(doc + suffix); err != nil {
+		return err
 	}
 
-	doc += conflictResolutionSection(conflictFiles)
+	// Execute a saved plan, or ask Claude to record the one it gets approved.
+	// Under a plan handoff, plan in a separate session and wait for a human
+	// to review the plan before executing it.
+	if handoff {
+		status.Phase(phaseClaude)
+		if err := r.planSession(ctx, task, taskName, taskRepo, hydraDir); err != nil {
+			return err
+		}
+		status.Phase(phasePlanReview)
+		if err := r.awaitPlanReview(ctx, hydraDir, task); err != nil {
+			return err
+		}
+		status.Phase(phasePreparing)
+	}
+	switch {
+	case r.UsePlan || handoff:
+		plan, err := loadPlan(hydraDir, task, taskName)
+		if err != nil {
+			return err
+		}
+		doc += approvedPlanSection(plan)
+		planMode = false
+	case planMode:
+		doc += planCaptureSection()
+	}
 
-	// Append verification and commit instructions so Claude handles test/lint/commit.
-	sign := taskRepo.HasSigningKey()
-	cmds := r.commandsMap(wd)
-	doc += documentSuffix(suffixOpts{
-		Commands:    cmds,
-		Sign:        sign,
-		Timeout:     r.timeout(),
-		Notify:      r.Notify,
-		NotifyTitle: r.notifyTitle(taskName),
-	})
+	doc += suffix
 
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
 	// Capture HEAD before invoking Claude.
@@ -334,17 +686,45 @@ func (r *Runner) Run(taskName string) error {
 	if claudeFn == nil {
 		claudeFn = invokeClaude
 	}
+	var tokens int64
+	var finalMessage string
+	usedModel := modelOrDefault(r.Model)
 	runCfg := ClaudeRunConfig{
-		RepoDir:    taskRepo.Dir,
-		Document:   doc,
-		Model:      r.Model,
-		AutoAccept: r.AutoAccept,
-		PlanMode:   r.PlanMode,
-		ForceTUI:   r.ForceTUI,
-	}
-	if err := claudeFn(context.Background(), runCfg); err != nil {
+		RepoDir:      taskRepo.WorkDir(),
+		Shell:        r.toolShell(taskRepo.WorkDir()),
+		Output:       &r.output,
+		State:        r.stateTool(task),
+		Document:     doc,
+		Model:        r.Model,
+		Fallbacks:    r.modelFallbacks(),
+		Approval:     r.approvalRules(),
+		AutoAccept:   r.AutoAccept,
+		PlanMode:     planMode,
+		ForceTUI:     r.ForceTUI,
+		Headless:     r.Headless,
+		TaskName:     taskName,
+		HydraDir:     hydraDir,
+		Timeout:      r.timeout(),
+		WrapUp:       r.wrapUp(),
+		Tokens:       &tokens,
+		UsedModel:    &usedModel,
+		Status:       status,
+		FinalMessage: &finalMessage,
+	}
+	started := time.Now()
+	if avg := r.averageRunDuration(); avg > 0 {
+		status.ExpectBy(started.Add(avg))
+	}
+	if err := continueOnTimeout(runClaude(ctx, claudeFn, runCfg)); err != nil {
 		return err
 	}
+	elapsed := time.Since(started)
+
+	if planMode {
+		if _, err := collectPlan(wd, planPath(hydraDir, task)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save plan: %v\n", err)
+		}
+	}
 
 	// Check if Claude committed (HEAD moved).
 	afterSHA, err := taskRepo.LastCommitSHA()
@@ -352,17 +732,48 @@ func (r *Runner) Run(taskName string) error {
 		return fmt.Errorf("getting HEAD SHA after claude: %w", err)
 	}
 	if afterSHA == beforeSHA {
-		return errors.New("claude produced no changes")
+		if reason, ok := noChangesReason(finalMessage); ok {
+			status.Phase(phasePushing)
+			return r.finishUnchanged(task, taskRepo, taskName, branch, reason)
+		}
+		return errNoChanges
+	}
+
+	if err := r.enforceDiffPolicy(ctx, claudeFn, runCfg, taskRepo); err != nil {
+		return err
+	}
+	elapsed = time.Since(started)
+	if afterSHA, err = taskRepo.LastCommitSHA(); err != nil {
+		return fmt.Errorf("getting HEAD SHA after claude: %w", err)
 	}
 
-	// Record SHA -> task name
-	record := design.NewRecord(r.Config.DesignDir())
-	if err := record.Add(afterSHA, taskName); err != nil {
+	if r.TaskRunner != nil && r.TaskRunner.ApproveBeforePush {
+		status.Phase(phaseApproval)
+		if err := awaitPushApproval(ctx, taskRepo, hydraDir, task, beforeSHA, afterSHA, !r.Headless); err != nil {
+			return err
+		}
+	}
+	status.Phase(phasePushing)
+
+	// Record SHA -> task name, with the session duration for future estimates
+	// and its usage for hydra stats.
+	added, deleted := lineStats(taskRepo, beforeSHA, afterSHA)
+	record := r.Design.Record()
+	if err := record.AddEntry(design.RecordEntry{
+		SHA:             afterSHA,
+		TaskName:        taskLabel(*task),
+		DurationSeconds: elapsed.Seconds(),
+		Tokens:          tokens,
+		DocTokens:       docTokens(doc),
+		Model:           usedModel,
+		LinesAdded:      added,
+		LinesDeleted:    deleted,
+	}); err != nil {
 		return fmt.Errorf("recording SHA: %w", err)
 	}
 
 	if err := taskRepo.Push(branch); err != nil {
-		return fmt.Errorf("pushing: %w", err)
+		return inCategory(categoryPush, fmt.Errorf("pushing: %w", err))
 	}
 
 	// Move task to review
@@ -371,13 +782,38 @@ func (r *Runner) Run(taskName string) error {
 	}
 
 	fmt.Printf("Task %q completed successfully. Branch: %s\n", taskName, branch)
+	r.runHook(taskrun.HookAfterRun, wd, hookEvent{Action: "run", Task: taskName, State: design.StateReview, Branch: branch, SHA: afterSHA})
 	return nil
 }
 
+// assembleTaskDocument reads a task and its group content and assembles the
+// base document handed to Claude.
+func (r *Runner) assembleTaskDocument(ctx context.Context, task *design.Task) (doc string, err error) {
+	_, span := tracing.Start(ctx, "assemble document")
+	defer func() { tracing.End(span, err) }()
+
+	content, err := task.Content()
+	if err != nil {
+		return "", err
+	}
+
+	groupContent, err := r.Design.GroupContent(task.Group)
+	if err != nil {
+		return "", fmt.Errorf("reading group content: %w", err)
+	}
+
+	doc, err = r.Design.AssembleDocument(content, groupContent)
+	if err != nil {
+		return "", fmt.Errorf("assembling document: %w", err)
+	}
+	span.SetAttributes(attribute.Int("hydra.document_bytes", len(doc)))
+	return doc, nil
+}
+
 // ensureBranch verifies the worktree is on the correct branch. If the
 // working tree is dirty, it warns but continues. If the branch needs
 // to be checked out (e.g., worktree was reused), it checks it out.
-func (r *Runner) ensureBranch(taskRepo *repo.Repo, branch string) error {
+func (r *Runner) ensureBranch(taskRepo repo.VCS, branch string) error {
 	currentBranch, err := taskRepo.CurrentBranch()
 	if err != nil {
 		return fmt.Errorf("getting current branch: %w", err)
@@ -500,26 +936,38 @@ func (r *Runner) GroupTasks(groupName string) error {
 // It resolves the issue source from TaskRunner config, syncs issues into the
 // design directory, then deletes remote branches and closes issues for
 // completed/abandoned tasks.
-func (r *Runner) Sync(labels []string) error {
-	apiType := ""
-	giteaURL := ""
-	if r.TaskRunner != nil {
-		apiType = r.TaskRunner.APIType
-		giteaURL = r.TaskRunner.GiteaURL
+// Merged hydra/* branches on origin that no longer match any task are
+// offered for deletion; autoConfirm deletes them without prompting.
+func (r *Runner) Sync(labels []string, autoConfirm bool) error {
+	if err := r.syncIssues(labels); err != nil {
+		return err
 	}
-	source, err := issues.ResolveSource(r.Config.SourceRepoURL, apiType, giteaURL)
+	return r.cleanupOrphanedBranches(repo.Open(r.Config.RepoDir), autoConfirm)
+}
+
+// syncIssues is Sync without the orphaned branch check, which asks before
+// deleting anything, for callers that must not prompt.
+func (r *Runner) syncIssues(labels []string) error {
+	source, err := r.issueSource()
 	if err != nil {
 		return err
 	}
 
-	created, skipped, err := issues.Sync(context.Background(), r.Config.DesignDir(), source, labels)
+	result, err := issues.Sync(context.Background(), r.Config.DesignDir, source, r.syncOptions(labels))
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Synced issues: %d created, %d skipped\n", created, skipped)
+	fmt.Printf("Synced issues: %d created, %d skipped", result.Created, result.Skipped)
+	if result.Ignored > 0 {
+		fmt.Printf(", %d ignored by rules", result.Ignored)
+	}
+	fmt.Println()
+	for _, date := range result.Milestones {
+		fmt.Printf("Milestone %s updated from issues\n", date)
+	}
 
-	sourceRepo := repo.Open(r.Config.BaseDir)
+	sourceRepo := repo.Open(r.Config.RepoDir)
 	closer := issues.ResolveCloser(source)
 
 	cleanup, err := issues.Cleanup(r.Design, sourceRepo, closer)
@@ -532,12 +980,157 @@ func (r *Runner) Sync(labels []string) error {
 			cleanup.BranchesDeleted, cleanup.IssuesClosed)
 	}
 
+	if pulls, ok := source.(issues.PullSource); ok {
+		r.syncPulls(pulls)
+	}
+	return nil
+}
+
+// syncPulls copies the comments people left on the pull requests of tasks
+// in review into the tasks' comments files, and flags tasks whose pull
+// requests were merged or closed outside hydra. Failures are only warnings,
+// since a token may be able to read issues but not pull requests.
+func (r *Runner) syncPulls(source issues.PullSource) {
+	result, err := issues.SyncPulls(context.Background(), r.Design, source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not sync pull requests: %v\n", err)
+		return
+	}
+	if result.Comments > 0 {
+		fmt.Printf("Pull requests: %d comment(s) added for the next review sessions\n", result.Comments)
+	}
+	for _, d := range result.Drifted {
+		if d.Pull.State == issues.PullOpen {
+			fmt.Printf("Task %s is %s, but its pull request #%d is still open: %s\n", d.Task, d.State, d.Pull.Number, d.Pull.URL)
+			continue
+		}
+		fmt.Printf("Task %s is in %s, but its pull request #%d was %s outside hydra: %s\n", d.Task, d.State, d.Pull.Number, d.Pull.State, d.Pull.URL)
+	}
+}
+
+// ImportIssue imports the issue with the given number as a task, as Sync
+// would, and returns the task's name for Run. An issue that was already
+// imported is not fetched again; its task is returned wherever it is.
+func (r *Runner) ImportIssue(number int) (string, error) {
+	source, err := r.issueSource()
+	if err != nil {
+		return "", err
+	}
+	task, err := issues.Import(context.Background(), r.Config.DesignDir, source, number, r.syncOptions(nil))
+	if err != nil {
+		return "", err
+	}
+	if task.State != design.StatePending {
+		return "", fmt.Errorf("issue #%d was imported as %s, which is in %s, not pending", number, taskLabel(*task), task.State)
+	}
+	fmt.Printf("Issue #%d is task %s\n", number, taskLabel(*task))
+	return taskLabel(*task), nil
+}
+
+// issueSource resolves the issue tracker of the source repository, using
+// api_type and gitea_url from hydra.yml.
+func (r *Runner) issueSource() (issues.Source, error) {
+	apiType := ""
+	giteaURL := ""
+	if r.TaskRunner != nil {
+		apiType = r.TaskRunner.APIType
+		giteaURL = r.TaskRunner.GiteaURL
+	}
+	return issues.ResolveSource(r.Config.SourceRepoURL, apiType, giteaURL)
+}
+
+// syncOptions builds the issue sync options from the labels given on the
+// command line and the sync section of hydra.yml.
+func (r *Runner) syncOptions(labels []string) issues.SyncOptions {
+	opts := issues.SyncOptions{Labels: labels}
+	if r.TaskRunner == nil || r.TaskRunner.Sync == nil {
+		return opts
+	}
+	for _, rule := range r.TaskRunner.Sync.Rules {
+		opts.Rules = append(opts.Rules, issues.Rule{
+			Label:    rule.Label,
+			Group:    rule.Group,
+			Priority: rule.Priority,
+			Skip:     rule.Skip,
+		})
+	}
+	opts.Milestones = r.TaskRunner.Sync.Milestones
+	return opts
+}
+
+// cleanupOrphanedBranches finds hydra/* branches on origin that are merged
+// into the default branch but have no corresponding task (e.g. the task was
+// deleted or renamed) and offers to delete them.
+func (r *Runner) cleanupOrphanedBranches(sourceRepo *repo.Repo, autoConfirm bool) error {
+	if err := sourceRepo.Fetch(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not fetch origin, skipping orphaned branch check: %v\n", err)
+		return nil
+	}
+
+	defaultBranch, err := r.detectDefaultBranch(sourceRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+
+	orphans, err := issues.OrphanedBranches(r.Design, sourceRepo, defaultBranch)
+	if err != nil {
+		return fmt.Errorf("checking orphaned branches: %w", err)
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\nMerged hydra branches with no matching task:\n")
+	for _, b := range orphans {
+		fmt.Printf("  %s\n", b)
+	}
+
+	if !autoConfirm && !Confirm(fmt.Sprintf("\nDelete %d branch(es) from origin?", len(orphans))) {
+		fmt.Println("Skipped.")
+		return nil
+	}
+
+	deleted := 0
+	for _, b := range orphans {
+		if err := sourceRepo.DeleteRemoteBranch(b); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not delete %s: %v\n", b, err)
+			continue
+		}
+		deleted++
+	}
+	fmt.Printf("Deleted %d orphaned branch(es)\n", deleted)
 	return nil
 }
 
+// Confirm prints prompt followed by "[y/N]" and reports whether the user
+// answered yes. Read errors are treated as no.
+func Confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read input: %v\n", err)
+		return false
+	}
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes"
+}
+
+// groupHint suggests the group among tasks' groups closest to name, for a
+// group name that matched no tasks.
+func groupHint(name string, tasks []design.Task) string {
+	var groups []string
+	for _, t := range tasks {
+		if t.Group != "" && !slices.Contains(groups, t.Group) {
+			groups = append(groups, t.Group)
+		}
+	}
+	return design.DidYouMean(name, groups)
+}
+
 // RunGroup executes all pending tasks in a group sequentially.
 // Each task gets its own cloned work directory.
-func (r *Runner) RunGroup(groupName string) error {
+func (r *Runner) RunGroup(groupName string) (err error) {
 	tasks, err := r.Design.PendingTasks()
 	if err != nil {
 		return fmt.Errorf("listing pending tasks: %w", err)
@@ -551,18 +1144,32 @@ func (r *Runner) RunGroup(groupName string) error {
 	}
 
 	if len(groupTasks) == 0 {
-		return fmt.Errorf("no pending tasks found in group %q", groupName)
+		return fmt.Errorf("no pending tasks found in group %q%s", groupName, groupHint(groupName, tasks))
 	}
 
 	sort.Slice(groupTasks, func(i, j int) bool {
 		return groupTasks[i].Name < groupTasks[j].Name
 	})
 
+	// Seed the ETA with the historical per-run average, if there is one.
+	var perTask time.Duration
+	if entries, err := design.NewRecord(r.Design.Path).Entries(); err == nil {
+		perTask = estimateFromRecord(entries, len(groupTasks), 0).AvgDuration
+	}
+
+	ctx, span := tracing.Start(context.Background(), "group run",
+		attribute.String("hydra.group", groupName), attribute.Int("hydra.tasks", len(groupTasks)))
+	defer func() { tracing.End(span, err) }()
+
+	progress := newGroupProgress(os.Stdout, len(groupTasks), perTask)
+	defer r.startHeartbeat(ctx, "group run "+groupName, progress)()
 	for _, t := range groupTasks {
 		taskRef := groupName + "/" + t.Name
-		if err := r.Run(taskRef); err != nil {
+		progress.begin(taskRef)
+		if err := r.runTask(ctx, taskRef); err != nil {
 			return fmt.Errorf("task %s: %w", taskRef, err)
 		}
+		progress.finish(taskRef)
 	}
 
 	return nil
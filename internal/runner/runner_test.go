@@ -17,6 +17,7 @@ import (
 	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/lock"
 	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
 )
 
 // testEnv sets up the full environment needed for runner tests:
@@ -77,7 +78,8 @@ func setupTestEnv(t *testing.T) *testEnv {
 
 	cfg := &config.Config{
 		SourceRepoURL: bareDir,
-		BaseDir:       base,
+		DesignDir:     designDir,
+		RepoDir:       base,
 	}
 	if err := cfg.Save(base); err != nil {
 		t.Fatal(err)
@@ -401,6 +403,61 @@ func TestRunNoChangesError(t *testing.T) {
 	}
 }
 
+func TestRunNoChangesNeeded(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var doc string
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		doc = cfg.Document
+		*cfg.FinalMessage = "I checked the code.\n\n**" + noChangesMarker + "**\nmain.go already handles this."
+		return nil
+	}
+	r.BaseDir = env.BaseDir
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(doc, "# No Changes Needed") {
+		t.Error("document missing the No Changes Needed section")
+	}
+	task, err := r.Design.FindTaskAny("add-feature")
+	if err != nil {
+		t.Fatalf("FindTask: %v", err)
+	}
+	if task.State != design.StateReview {
+		t.Errorf("state = %s, want review", task.State)
+	}
+	comments, err := task.Comments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(comments, "main.go already handles this.") {
+		t.Errorf("comments = %q, want Claude's explanation", comments)
+	}
+}
+
+func TestNoChangesReason(t *testing.T) {
+	for _, tc := range []struct {
+		message, reason string
+		ok              bool
+	}{
+		{noChangesMarker + "\nAlready done in auth.go.", "Already done in auth.go.", true},
+		{"Already done in auth.go.\n\n`" + noChangesMarker + "`\n", "Already done in auth.go.", true},
+		{"Implemented the feature and committed.", "", false},
+		{"I did not print " + noChangesMarker + " here.", "", false},
+		{"", "", false},
+	} {
+		reason, ok := noChangesReason(tc.message)
+		if reason != tc.reason || ok != tc.ok {
+			t.Errorf("noChangesReason(%q) = %q, %v; want %q, %v", tc.message, reason, ok, tc.reason, tc.ok)
+		}
+	}
+}
+
 func TestRunClaudeError(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -682,7 +739,7 @@ func TestRunRecordsSHA(t *testing.T) {
 		t.Fatalf("reading record.json: %v", err)
 	}
 
-	var entries []map[string]string
+	var entries []map[string]any
 	if err := json.Unmarshal(data, &entries); err != nil {
 		t.Fatalf("parsing record.json: %v", err)
 	}
@@ -866,7 +923,7 @@ func TestPrepareRepoFreshClone(t *testing.T) {
 	if err != nil {
 		t.Fatalf("prepareRepo: %v", err)
 	}
-	if !repo.IsGitRepo(taskRepo.Dir) {
+	if !repo.IsGitRepo(taskRepo.WorkDir()) {
 		t.Error("expected git repo after fresh clone")
 	}
 }
@@ -896,7 +953,7 @@ func TestPrepareRepoExistingGitDir(t *testing.T) {
 	if err != nil {
 		t.Fatalf("second prepareRepo: %v", err)
 	}
-	if !repo.IsGitRepo(taskRepo.Dir) {
+	if !repo.IsGitRepo(taskRepo.WorkDir()) {
 		t.Error("expected git repo after sync")
 	}
 
@@ -923,7 +980,7 @@ func TestPrepareRepoNotGitDir(t *testing.T) {
 	if err != nil {
 		t.Fatalf("prepareRepo: %v", err)
 	}
-	if !repo.IsGitRepo(taskRepo.Dir) {
+	if !repo.IsGitRepo(taskRepo.WorkDir()) {
 		t.Error("expected git repo after re-clone")
 	}
 }
@@ -1223,7 +1280,7 @@ func TestMergeCRUDOperatesOnMergeState(t *testing.T) {
 	}
 
 	// MergeRemove should move to abandoned.
-	if err := r.MergeRemove("add-feature"); err != nil {
+	if err := r.MergeRemove("add-feature", AbandonOpts{Keep: true}); err != nil {
 		t.Errorf("MergeRemove: %v", err)
 	}
 
@@ -1255,6 +1312,232 @@ func TestRunWithModelOverride(t *testing.T) {
 	}
 }
 
+func TestGroupHydraYmlOverrides(t *testing.T) {
+	env := setupTestEnv(t)
+	writeFile(t, filepath.Join(env.DesignDir, "hydra.yml"),
+		"model: root-model\ncommands:\n  test: \"echo root test\"\n  lint: \"echo root lint\"\n")
+	writeFile(t, filepath.Join(env.DesignDir, "tasks", testGroupBackend, "hydra.yml"),
+		"model: group-model\ncommands:\n  test: \"echo group test\"\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if r.Model != "root-model" {
+		t.Errorf("Model = %q, want root-model", r.Model)
+	}
+
+	// Ungrouped tasks keep the root config.
+	if err := r.useGroupConfig(&design.Task{Name: "add-feature"}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if got := r.TaskRunner.Commands["test"]; got != "echo root test" {
+		t.Errorf("ungrouped test command = %q, want root", got)
+	}
+
+	if err := r.useGroupConfig(&design.Task{Name: "add-api", Group: testGroupBackend}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if r.Model != "group-model" {
+		t.Errorf("Model = %q, want group-model", r.Model)
+	}
+	cmds := r.commandsMap(env.BaseDir)
+	if cmds["test"] != "echo group test" {
+		t.Errorf("test command = %q, want the group's", cmds["test"])
+	}
+	if cmds["lint"] != "echo root lint" {
+		t.Errorf("lint command = %q, want the root's", cmds["lint"])
+	}
+
+	// An ungrouped task after a grouped one, as in a multi-task run, drops
+	// the group's overrides again.
+	if err := r.useGroupConfig(&design.Task{Name: "add-feature"}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if got := r.TaskRunner.Commands["test"]; got != "echo root test" {
+		t.Errorf("test command after the group's task = %q, want root", got)
+	}
+	if r.Model != "root-model" {
+		t.Errorf("Model after the group's task = %q, want root-model", r.Model)
+	}
+
+	// A model given on the command line wins over the group's.
+	r, err = New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.SetModel("cli-model")
+	if err := r.useGroupConfig(&design.Task{Name: "add-api", Group: testGroupBackend}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if r.Model != "cli-model" {
+		t.Errorf("Model after group reload = %q, want cli-model", r.Model)
+	}
+
+	// Even when it names the same model as hydra.yml.
+	r, err = New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.SetModel("root-model")
+	if err := r.useGroupConfig(&design.Task{Name: "add-api", Group: testGroupBackend}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if r.Model != "root-model" {
+		t.Errorf("Model after group reload = %q, want the command line's root-model", r.Model)
+	}
+}
+
+// remoteBranches is a fake repository holding the given branches.
+type remoteBranches []string
+
+func (b remoteBranches) BranchExists(name string) bool { return slices.Contains(b, name) }
+
+func (b remoteBranches) OriginHead() (string, bool) { return "", false }
+
+// originHeadBranches is a fake repository whose origin/HEAD points at head.
+type originHeadBranches struct {
+	remoteBranches
+	head string
+}
+
+func (b originHeadBranches) OriginHead() (string, bool) { return b.head, b.head != "" }
+
+func TestGroupBaseBranch(t *testing.T) {
+	env := setupTestEnv(t)
+	writeFile(t, filepath.Join(env.DesignDir, "tasks", testGroupBackend, "group.md"),
+		"---\nbase: release/2.0\n---\nBackend work.\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	branches := remoteBranches{"origin/main", "origin/release/2.0"}
+
+	if err := r.useGroupConfig(&design.Task{Name: "add-api", Group: testGroupBackend}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if got, err := r.detectDefaultBranch(branches); err != nil || got != "release/2.0" {
+		t.Errorf("grouped task's branch = %q, %v; want release/2.0", got, err)
+	}
+	if _, err := r.detectDefaultBranch(remoteBranches{"origin/main"}); err == nil {
+		t.Error("expected an error for a base branch missing on origin")
+	}
+
+	// Ungrouped tasks go back to the default branch.
+	if err := r.useGroupConfig(&design.Task{Name: "add-feature"}); err != nil {
+		t.Fatalf("useGroupConfig: %v", err)
+	}
+	if got, err := r.detectDefaultBranch(branches); err != nil || got != "main" {
+		t.Errorf("ungrouped task's branch = %q, %v; want main", got, err)
+	}
+}
+
+func TestDetectDefaultBranch(t *testing.T) {
+	r := &Runner{}
+	trunk := originHeadBranches{remoteBranches{"origin/main", "origin/trunk", "origin/develop"}, "trunk"}
+
+	if got, err := r.detectDefaultBranch(trunk); err != nil || got != "trunk" {
+		t.Errorf("with origin/HEAD = %q, %v; want trunk", got, err)
+	}
+	if got, err := r.detectDefaultBranch(remoteBranches{"origin/master"}); err != nil || got != "master" {
+		t.Errorf("without origin/HEAD = %q, %v; want master", got, err)
+	}
+	if _, err := r.detectDefaultBranch(remoteBranches{"origin/develop"}); err == nil {
+		t.Error("expected an error when no default branch can be found")
+	}
+
+	r.TaskRunner = &taskrun.Commands{DefaultBranch: "develop"}
+	if got, err := r.detectDefaultBranch(trunk); err != nil || got != "develop" {
+		t.Errorf("with default_branch = %q, %v; want develop", got, err)
+	}
+	r.TaskRunner.DefaultBranch = "release"
+	if _, err := r.detectDefaultBranch(trunk); err == nil {
+		t.Error("expected an error for a default_branch missing on origin")
+	}
+}
+
+func TestRunStartsFromDefaultBranch(t *testing.T) {
+	env := setupTestEnv(t)
+
+	// trunk is ahead of main and set as default_branch.
+	gitRun(t, "-C", env.BaseDir, "checkout", "-b", "trunk")
+	writeFile(t, filepath.Join(env.BaseDir, "trunk.txt"), "trunk")
+	gitRun(t, "-C", env.BaseDir, "add", "trunk.txt")
+	gitRun(t, "-C", env.BaseDir, "commit", "-m", "trunk change")
+	gitRun(t, "-C", env.BaseDir, "push", "origin", "trunk")
+	gitRun(t, "-C", env.BaseDir, "checkout", "main")
+	writeFile(t, filepath.Join(env.DesignDir, "hydra.yml"), "default_branch: trunk\ncommands:\n  test: \"true\"\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var captured ClaudeRunConfig
+	r.Claude = mockClaudeCaptureConfig(&captured)
+	r.BaseDir = env.BaseDir
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDirForTask(env.BaseDir), "trunk.txt")); err != nil {
+		t.Error("task branch did not start from origin/trunk")
+	}
+	if !strings.Contains(captured.Document, "`git rebase origin/trunk`") {
+		t.Error("document does not rebase onto origin/trunk")
+	}
+}
+
+func TestRunTasksContinuesPastLockedTask(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.Claude = mockClaude
+	r.BaseDir = env.BaseDir
+
+	// Another hydra holds both tasks' locks.
+	for _, name := range []string{"add-feature", "another-task"} {
+		lk := lock.New(filepath.Join(env.BaseDir, ".hydra"), name)
+		if err := lk.Acquire(); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = lk.Release() }()
+	}
+
+	err = r.RunTasks([]string{"add-feature", "another-task"})
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 task(s) failed") {
+		t.Fatalf("RunTasks error = %v, want both tasks failed", err)
+	}
+}
+
+func TestRunTasksRejectsUnknownTask(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var calls int
+	r.Claude = func(_ context.Context, _ ClaudeRunConfig) error {
+		calls++
+		return nil
+	}
+	r.BaseDir = env.BaseDir
+
+	if err := r.RunTasks([]string{"add-feature", "no-such-task"}); err == nil {
+		t.Fatal("RunTasks should fail for an unknown task")
+	}
+	if calls != 0 {
+		t.Errorf("claude invoked %d times; no task should run when one is unknown", calls)
+	}
+	if err := r.RunTasks([]string{"add-feature", "add-feature"}); err == nil {
+		t.Fatal("RunTasks should reject a task given twice")
+	}
+}
+
 func TestRunForceTUIPropagated(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -1321,7 +1604,7 @@ func TestVerificationSectionExclusiveCommands(t *testing.T) {
 	result := verificationSection(map[string]string{
 		"test": "go test ./...",
 		"lint": "golangci-lint run",
-	})
+	}, false)
 
 	if !strings.Contains(result, "Do not run other commands") {
 		t.Error("missing exclusive commands directive in verification section")
@@ -1337,7 +1620,7 @@ func TestMergeDocumentExclusiveCommands(t *testing.T) {
 		"test": "go test ./...",
 		"lint": "golangci-lint run",
 	}
-	result, err := r.assembleMergeDocument("Task content", nil, cmds, false, 0, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -1436,7 +1719,7 @@ func TestVerificationSectionWithCommands(t *testing.T) {
 	result := verificationSection(map[string]string{
 		"test": "go test ./...",
 		"lint": "golangci-lint run",
-	})
+	}, false)
 
 	if !strings.Contains(result, "## Verification") {
 		t.Error("missing Verification header")
@@ -1452,15 +1735,29 @@ func TestVerificationSectionWithCommands(t *testing.T) {
 	}
 }
 
+func TestVerificationSectionParallelChecks(t *testing.T) {
+	both := map[string]string{"test": "go test ./...", "lint": "golangci-lint run"}
+	if result := verificationSection(both, false); !strings.Contains(result, "running them concurrently") {
+		t.Errorf("missing parallel guidance:\n%s", result)
+	}
+	if result := verificationSection(both, true); !strings.Contains(result, "one at a time") ||
+		strings.Contains(result, "running them concurrently") {
+		t.Errorf("serial_checks should ask for one at a time:\n%s", result)
+	}
+	if result := verificationSection(map[string]string{"test": "go test ./..."}, false); strings.Contains(result, "running them concurrently") {
+		t.Errorf("parallel guidance with only a test command:\n%s", result)
+	}
+}
+
 func TestVerificationSectionNilCommands(t *testing.T) {
-	result := verificationSection(nil)
+	result := verificationSection(nil, false)
 	if result != "" {
 		t.Errorf("expected empty string for nil commands, got %q", result)
 	}
 }
 
 func TestVerificationSectionEmptyCommands(t *testing.T) {
-	result := verificationSection(map[string]string{})
+	result := verificationSection(map[string]string{}, false)
 	if result != "" {
 		t.Errorf("expected empty string for empty commands, got %q", result)
 	}
@@ -1640,7 +1937,7 @@ func TestReviewCommitsAndPushes(t *testing.T) {
 	if err != nil {
 		t.Fatalf("reading record.json: %v", err)
 	}
-	var entries []map[string]string
+	var entries []map[string]any
 	if err := json.Unmarshal(data, &entries); err != nil {
 		t.Fatalf("parsing record.json: %v", err)
 	}
@@ -1950,7 +2247,7 @@ func TestTestPushesChanges(t *testing.T) {
 	if err != nil {
 		t.Fatalf("reading record.json: %v", err)
 	}
-	var entries []map[string]string
+	var entries []map[string]any
 	if err := json.Unmarshal(data, &entries); err != nil {
 		t.Fatalf("parsing record.json: %v", err)
 	}
@@ -1997,6 +2294,58 @@ func TestReviewDevRunsCommand(t *testing.T) {
 	}
 }
 
+func TestReviewDevEnv(t *testing.T) {
+	env := setupTestEnv(t)
+	out := filepath.Join(t.TempDir(), "dev-env")
+	writeFile(t, filepath.Join(env.DesignDir, "hydra.yml"),
+		"commands:\n  dev: 'echo \"$HYDRA_TASK $HYDRA_BRANCH $DB\" > "+out+"'\n  test: \"true\"\n  lint: \"true\"\n")
+	writeFile(t, filepath.Join(env.DesignDir, design.DevEnvFile), "DB=app_${HYDRA_TASK}\n")
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.Claude = mockClaude
+	r.BaseDir = env.BaseDir
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if err := r.ReviewDev(context.Background(), "add-feature"); err != nil {
+		t.Fatalf("ReviewDev: %v", err)
+	}
+	data, err := os.ReadFile(out) //nolint:gosec // test file
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(data)), "add-feature hydra/add-feature app_add-feature"; got != want {
+		t.Errorf("dev command saw %q, want %q", got, want)
+	}
+}
+
+func TestDevPortHints(t *testing.T) {
+	if got := devPortHints(nil, nil, true); got != "" {
+		t.Errorf("hints without ports = %q, want none", got)
+	}
+
+	ports := []taskrun.DevPort{
+		{Name: "web", Port: 3000},
+		{Port: 8080, URL: "http://${HYDRA_TASK}.localhost:8080"},
+	}
+	got := devPortHints(ports, []string{"HYDRA_TASK=login"}, false)
+	for _, want := range []string{"web: http://localhost:3000", "8080: http://login.localhost:8080"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("hints missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "ssh") {
+		t.Errorf("hints suggest ssh outside an SSH session:\n%s", got)
+	}
+	if got := devPortHints(ports, nil, true); !strings.Contains(got, "ssh -L 3000:localhost:3000 -L 8080:localhost:8080") {
+		t.Errorf("hints missing ssh forwarding:\n%s", got)
+	}
+}
+
 func TestReviewDevMissingCommand(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -2275,8 +2624,8 @@ func TestMergeDocumentWithConflicts(t *testing.T) {
 		"test": "go test ./...",
 		"lint": "golangci-lint run",
 	}
-	conflictFiles := []string{"main.go", "config.go"}
-	result, err := r.assembleMergeDocument("Task content", conflictFiles, cmds, false, 0, false, "")
+	conflicts := []repo.Conflict{{File: "main.go"}, {File: "config.go"}}
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Conflicts: conflicts, Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2559,7 +2908,7 @@ func TestMergeDocumentNoFetchInstruction(t *testing.T) {
 		"test": "go test ./...",
 		"lint": "golangci-lint run",
 	}
-	result, err := r.assembleMergeDocument("Task content", nil, cmds, false, 0, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2578,8 +2927,8 @@ func TestMergeDocumentConflictResolutionReport(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	conflictFiles := []string{"main.go", "config.go"}
-	result, err := r.assembleMergeDocument("Task content", conflictFiles, cmds, false, 0, false, "")
+	conflicts := []repo.Conflict{{File: "main.go"}, {File: "config.go"}}
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Conflicts: conflicts, Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2597,8 +2946,8 @@ func TestMergeDocumentConflictInstructions(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	conflictFiles := []string{"main.go"}
-	result, err := r.assembleMergeDocument("Task content", conflictFiles, cmds, false, 0, false, "")
+	conflicts := []repo.Conflict{{File: "main.go"}}
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Conflicts: conflicts, Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2621,7 +2970,7 @@ func TestMergeDocumentIncludesNotification(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	result, err := r.assembleMergeDocument("Task content", nil, cmds, false, 0, true, "repo: task")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds, Notify: true, NotifyTitle: "repo: task"})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2630,7 +2979,7 @@ func TestMergeDocumentIncludesNotification(t *testing.T) {
 		t.Error("merge document missing notification section when notify=true")
 	}
 
-	result, err = r.assembleMergeDocument("Task content", nil, cmds, false, 0, false, "")
+	result, err = r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2644,7 +2993,7 @@ func TestMergeDocumentIncludesTimeout(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	result, err := r.assembleMergeDocument("Task content", nil, cmds, false, 30*60*1e9, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds, Timeout: 30 * 60 * 1e9})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2659,7 +3008,7 @@ func TestVerifyDocumentIncludesTestCoverage(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	result, err := r.assembleVerifyDocument("Feature X must do Y.", false, cmds)
+	result, err := r.assembleVerifyDocument("Feature X must do Y.", "", false, cmds, nil)
 	if err != nil {
 		t.Fatalf("assembleVerifyDocument: %v", err)
 	}
@@ -2677,7 +3026,7 @@ func TestVerifyDocumentSpecIsAuthoritative(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	result, err := r.assembleVerifyDocument("Feature X must do Y.", false, cmds)
+	result, err := r.assembleVerifyDocument("Feature X must do Y.", "", false, cmds, nil)
 	if err != nil {
 		t.Fatalf("assembleVerifyDocument: %v", err)
 	}
@@ -2810,6 +3159,43 @@ func TestMergeGroupWorkflow(t *testing.T) {
 	}
 }
 
+func TestRecordEntriesUseTaskLabel(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaude
+
+	// The grouped task is named without its group throughout.
+	if err := r.Run("add-api"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	r, err = New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaudeNoChanges
+	if err := r.Merge("add-api"); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	entries, err := r.Design.Record().Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.TaskName)
+	}
+	if !slices.Equal(names, []string{"backend/add-api", "merge:backend/add-api"}) {
+		t.Errorf("record entries = %v, want them under the task's label", names)
+	}
+}
+
 func TestMergeGroupEmptyError(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -2880,7 +3266,7 @@ func TestMergeDocumentStayOnFeatureBranch(t *testing.T) {
 		"test": "go test ./...",
 	}
 
-	result, err := r.assembleMergeDocument("Task content", nil, cmds, false, 0, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2904,9 +3290,9 @@ func TestMergeDocumentStayOnFeatureBranchWithConflicts(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	conflictFiles := []string{"main.go"}
+	conflicts := []repo.Conflict{{File: "main.go"}}
 
-	result, err := r.assembleMergeDocument("Task content", conflictFiles, cmds, false, 0, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Conflicts: conflicts, Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -2930,9 +3316,9 @@ func TestMergeDocumentNoRebaseLoop(t *testing.T) {
 	cmds := map[string]string{
 		"test": "go test ./...",
 	}
-	conflictFiles := []string{"main.go"}
+	conflicts := []repo.Conflict{{File: "main.go"}}
 
-	result, err := r.assembleMergeDocument("Task content", conflictFiles, cmds, false, 0, false, "")
+	result, err := r.assembleMergeDocument("Task content", mergeDocOpts{Conflicts: conflicts, Commands: cmds})
 	if err != nil {
 		t.Fatalf("assembleMergeDocument: %v", err)
 	}
@@ -3019,6 +3405,46 @@ func TestMergeClaudeRunsOnFeatureBranch(t *testing.T) {
 	}
 }
 
+func TestMergeContinuesAfterTimeout(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Claude = mockClaude
+	r.BaseDir = env.BaseDir
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// A session cut off with its work uncommitted doesn't land.
+	wd := workDirForTask(env.BaseDir)
+	r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+		writeFile(t, filepath.Join(cfg.RepoDir, "half-done.txt"), "wip")
+		return fmt.Errorf("%w after 1m0s", errSessionTimeout)
+	}
+	if err := r.Merge("add-feature"); err == nil {
+		t.Fatal("expected a merge cut off with uncommitted work to fail")
+	}
+	if err := os.Remove(filepath.Join(wd, "half-done.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Otherwise it carries on with what it committed, as run and review
+	// sessions do.
+	r.Claude = func(context.Context, ClaudeRunConfig) error {
+		return fmt.Errorf("%w after 1m0s", errSessionTimeout)
+	}
+	if err := r.Merge("add-feature"); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if _, err := r.Design.FindTaskByState("add-feature", design.StateCompleted); err != nil {
+		t.Errorf("task should be completed: %v", err)
+	}
+}
+
 func TestMergeMainRebasedAgainstOrigin(t *testing.T) {
 	// After merge, local main should be up-to-date with origin/main.
 	env := setupTestEnv(t)
@@ -3458,6 +3884,106 @@ func TestGroupTasksSorted(t *testing.T) {
 	}
 }
 
+// fixCheckOutput runs FixCheck and returns the issue count and its output.
+func fixCheckOutput(t *testing.T, r *Runner) (int, string) {
+	t.Helper()
+
+	old := os.Stdout
+	rd, w, _ := os.Pipe()
+	os.Stdout = w
+
+	n, err := r.FixCheck()
+	if cerr := w.Close(); cerr != nil {
+		t.Logf("w.Close: %v", cerr)
+	}
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("FixCheck: %v", err)
+	}
+	out, _ := io.ReadAll(rd)
+	return n, string(out)
+}
+
+func TestFixCheckReportsWithoutFixing(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+
+	// Bring the project to a clean state first.
+	if err := r.Fix(true); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if n, out := fixCheckOutput(t, r); n != 0 {
+		t.Fatalf("FixCheck found %d issues after Fix, want 0; output: %q", n, out)
+	}
+
+	// A lock file held by no live process.
+	lockPath := filepath.Join(env.BaseDir, ".hydra", "hydra-ghost.lock")
+	if err := os.WriteFile(lockPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, out := fixCheckOutput(t, r)
+	if n != 1 {
+		t.Fatalf("FixCheck found %d issues, want 1; output: %q", n, out)
+	}
+	want := "stale-lock\thydra-ghost.lock\tremove stale lock hydra-ghost.lock\n"
+	if out != want {
+		t.Errorf("FixCheck output = %q, want %q", out, want)
+	}
+
+	// Nothing should have been fixed.
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Errorf("FixCheck should not remove the stale lock: %v", err)
+	}
+}
+
+func TestFixOrphanedRemoteBranchesDeleted(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+
+	// Hydra branches on origin with no task in any state, one merged into
+	// main and one holding a commit main doesn't have.
+	gitRun(t, "-C", env.BaseDir, "push", "origin", "main:refs/heads/hydra/ghost-task")
+	gitRun(t, "-C", env.BaseDir, "checkout", "-q", "-b", "unmerged")
+	gitRun(t, "-C", env.BaseDir, "commit", "-q", "--allow-empty", "-m", "unmerged work")
+	gitRun(t, "-C", env.BaseDir, "push", "origin", "unmerged:refs/heads/hydra/unmerged-ghost")
+	gitRun(t, "-C", env.BaseDir, "checkout", "-q", "main")
+
+	n, out := fixCheckOutput(t, r)
+	if !strings.Contains(out, "orphaned-branch\thydra/ghost-task\t") {
+		t.Fatalf("FixCheck should report the orphaned branch (%d issues): %q", n, out)
+	}
+	if !strings.Contains(out, "unmerged-branch\thydra/unmerged-ghost\t") {
+		t.Fatalf("FixCheck should report the unmerged orphaned branch (%d issues): %q", n, out)
+	}
+
+	if err := r.Fix(true); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+
+	cmd := exec.CommandContext(context.Background(), "git", "-C", env.BareDir, "branch", "--list", "hydra/*")
+	branches, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git branch: %v", err)
+	}
+	if strings.Contains(string(branches), "hydra/ghost-task") {
+		t.Errorf("orphaned branch should have been deleted from origin, got %q", string(branches))
+	}
+	if !strings.Contains(string(branches), "hydra/unmerged-ghost") {
+		t.Errorf("unmerged orphaned branch should have been kept on origin, got %q", string(branches))
+	}
+}
+
 func TestFixStuckMergeTasksMovedToReview(t *testing.T) {
 	env := setupTestEnv(t)
 
@@ -3511,20 +4037,51 @@ func TestFixStuckMergeTasksMovedToReview(t *testing.T) {
 	}
 }
 
+func TestFixAbandonsExpiredTasks(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+
+	task, err := r.Design.FindTask("add-feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := task.SetFrontmatter(map[string]string{"expires": "2020-01-01"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, out := fixCheckOutput(t, r)
+	want := "expired-task\tadd-feature\tabandon pending task \"add-feature\", which expired 2020-01-01\n"
+	if !strings.Contains(out, want) {
+		t.Fatalf("FixCheck found %d issues, want the expired task; output: %q", n, out)
+	}
+
+	if err := r.Fix(true); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if _, err := r.Design.FindTaskByState("add-feature", design.StateAbandoned); err != nil {
+		t.Errorf("expired task should have been abandoned: %v", err)
+	}
+}
+
 func TestConflictResolutionSectionEmpty(t *testing.T) {
-	result := conflictResolutionSection(nil)
+	result := conflictResolutionSection(nil, "")
 	if result != "" {
 		t.Error("conflictResolutionSection should return empty string for nil files")
 	}
 
-	result = conflictResolutionSection([]string{})
+	result = conflictResolutionSection([]repo.Conflict{}, "")
 	if result != "" {
 		t.Error("conflictResolutionSection should return empty string for empty slice")
 	}
 }
 
 func TestConflictResolutionSectionContent(t *testing.T) {
-	result := conflictResolutionSection([]string{"main.go", "config.go"})
+	result := conflictResolutionSection([]repo.Conflict{{File: "main.go"}, {File: "config.go"}}, "")
 
 	if !strings.Contains(result, "Conflict Resolution") {
 		t.Error("missing Conflict Resolution heading")
@@ -3543,10 +4100,40 @@ func TestConflictResolutionSectionContent(t *testing.T) {
 	}
 }
 
+func TestConflictResolutionSectionDetails(t *testing.T) {
+	result := conflictResolutionSection([]repo.Conflict{
+		{
+			File:      "main.go",
+			Hunks:     []repo.ConflictHunk{{Upstream: "x := 2", Task: "x := \"```\"", Truncated: true}},
+			MoreHunks: 2,
+			Commits:   []string{"abc1234 Change x"},
+		},
+		{File: "config.go"},
+	}, "release/2.0")
+
+	for _, want := range []string{
+		"git rebase origin/release/2.0",
+		"### Conflicts in main.go",
+		"**Hunk 1** (truncated)",
+		"```\nx := 2\n```",
+		"````\nx := \"```\"\n````",
+		"2 more conflicting hunk(s) not shown",
+		"origin/release/2.0 commits that changed these lines",
+		"- abc1234 Change x",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("missing %q in:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "Conflicts in config.go") {
+		t.Error("a conflict without details should only be listed")
+	}
+}
+
 func TestReviewDocumentWithConflicts(t *testing.T) {
 	r := stubRunner(t)
-	conflictFiles := []string{"handler.go"}
-	result, err := r.assembleReviewDocument("Task content", conflictFiles)
+	conflicts := []repo.Conflict{{File: "handler.go"}}
+	result, err := r.assembleReviewDocument("Task content", "", conflicts, nil)
 	if err != nil {
 		t.Fatalf("assembleReviewDocument: %v", err)
 	}
@@ -3564,7 +4151,7 @@ func TestReviewDocumentWithConflicts(t *testing.T) {
 
 func TestReviewDocumentWithoutConflicts(t *testing.T) {
 	r := stubRunner(t)
-	result, err := r.assembleReviewDocument("Task content", nil)
+	result, err := r.assembleReviewDocument("Task content", "", nil, nil)
 	if err != nil {
 		t.Fatalf("assembleReviewDocument: %v", err)
 	}
@@ -3574,10 +4161,45 @@ func TestReviewDocumentWithoutConflicts(t *testing.T) {
 	}
 }
 
+func TestReviewDocumentWithComments(t *testing.T) {
+	r := stubRunner(t)
+	result, err := r.assembleReviewDocument("Task content", "Rename the handler.\n", nil, nil)
+	if err != nil {
+		t.Fatalf("assembleReviewDocument: %v", err)
+	}
+
+	feedback := strings.Index(result, "# Reviewer Feedback")
+	if feedback < 0 || !strings.Contains(result[feedback:], "Rename the handler.") {
+		t.Fatalf("review document missing reviewer feedback:\n%s", result)
+	}
+	if feedback < strings.Index(result, "# Task") || feedback > strings.Index(result, "# Review Instructions") {
+		t.Error("reviewer feedback should follow the task and precede the instructions")
+	}
+
+	result, err = r.assembleReviewDocument("Task content", "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(result, "Reviewer Feedback") {
+		t.Error("review document should not contain Reviewer Feedback without comments")
+	}
+}
+
+func TestReviewFocusSection(t *testing.T) {
+	diff := "diff --git a/pkg/api/a.go b/pkg/api/a.go\n+package api\n"
+	result := reviewFocusSection([]string{"pkg/api", "cmd"}, diff)
+
+	for _, want := range []string{"# Review Focus", "- `pkg/api`", "- `cmd`", "```diff\n" + diff + "```"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("focus section missing %q:\n%s", want, result)
+		}
+	}
+}
+
 func TestTestDocumentWithConflicts(t *testing.T) {
 	r := stubRunner(t)
-	conflictFiles := []string{"service.go"}
-	result, err := r.assembleTestDocument("Task content", conflictFiles)
+	conflicts := []repo.Conflict{{File: "service.go"}}
+	result, err := r.assembleTestDocument("Task content", conflicts)
 	if err != nil {
 		t.Fatalf("assembleTestDocument: %v", err)
 	}
@@ -3641,14 +4263,14 @@ func TestAttemptRebaseFetchesOrigin(t *testing.T) {
 	}
 
 	// attemptRebase should fetch origin itself and detect the divergence.
-	conflictFiles, err := r.attemptRebase(taskRepo)
+	conflicts, err := r.attemptRebase(taskRepo)
 	if err != nil {
 		t.Fatalf("attemptRebase: %v", err)
 	}
 
 	// No conflicts expected — the upstream change is in a different file.
-	if len(conflictFiles) != 0 {
-		t.Errorf("expected no conflicts, got: %v", conflictFiles)
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got: %v", conflicts)
 	}
 
 	// Verify the upstream commit is now an ancestor of HEAD (rebase incorporated it).
@@ -3657,6 +4279,79 @@ func TestAttemptRebaseFetchesOrigin(t *testing.T) {
 	}
 }
 
+func TestAttemptRebaseAfterForcePush(t *testing.T) {
+	env := setupTestEnv(t)
+
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.BaseDir = env.BaseDir
+	r.Claude = mockClaude
+
+	if err := r.Run("add-feature"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	pushDir := filepath.Join(t.TempDir(), "push-repo")
+	gitRun(t, "clone", env.BareDir, pushDir)
+	gitRun(t, "-C", pushDir, "config", "user.email", "test@test.com")
+	gitRun(t, "-C", pushDir, "config", "user.name", "Test")
+	gitRun(t, "-C", pushDir, "config", "commit.gpgsign", "false")
+	writeFile(t, filepath.Join(pushDir, "secret.txt"), "oops")
+	gitRun(t, "-C", pushDir, "add", "-A")
+	gitRun(t, "-C", pushDir, "commit", "-m", "commit a secret")
+	gitRun(t, "-C", pushDir, "push", "origin", "main")
+
+	// Rebase the task branch onto the commit that is about to be dropped.
+	wd := workDirForTask(env.BaseDir)
+	taskRepo := repo.Open(wd)
+	if err := taskRepo.Checkout(testBranchAddFeature); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if _, err := r.attemptRebase(taskRepo); err != nil {
+		t.Fatalf("attemptRebase: %v", err)
+	}
+
+	// Rewrite main to drop the secret, and force-push it.
+	gitRun(t, "-C", pushDir, "reset", "--hard", "HEAD~1")
+	writeFile(t, filepath.Join(pushDir, "upstream-change.txt"), "upstream")
+	gitRun(t, "-C", pushDir, "add", "-A")
+	gitRun(t, "-C", pushDir, "commit", "-m", "upstream commit")
+	gitRun(t, "-C", pushDir, "push", "--force", "origin", "main")
+
+	// Without --rebase-onto or a terminal to ask on, hydra refuses.
+	r.Headless = true
+	before, err := taskRepo.LastCommitSHA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.attemptRebase(taskRepo); !errors.Is(err, errRebaseOntoDeclined) {
+		t.Fatalf("attemptRebase without --rebase-onto = %v, want errRebaseOntoDeclined", err)
+	}
+	if after, _ := taskRepo.LastCommitSHA(); after != before {
+		t.Error("a refused rebase moved the branch")
+	}
+
+	r.RebaseOnto = true
+	conflicts, err := r.attemptRebase(taskRepo)
+	if err != nil {
+		t.Fatalf("attemptRebase: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got: %v", conflicts)
+	}
+	if !taskRepo.IsAncestor("origin/main", "HEAD") {
+		t.Error("after rebase, origin/main should be ancestor of HEAD")
+	}
+	if _, err := os.Stat(filepath.Join(wd, "secret.txt")); err == nil {
+		t.Error("the commit dropped by the force-push came back")
+	}
+	if _, err := os.Stat(filepath.Join(wd, "upstream-change.txt")); err != nil {
+		t.Error("upstream-change.txt missing after rebase")
+	}
+}
+
 func TestAttemptRebaseWithConflicts(t *testing.T) {
 	// Set up env.
 	env := setupTestEnv(t)
@@ -3699,18 +4394,26 @@ func TestAttemptRebaseWithConflicts(t *testing.T) {
 	}
 
 	// attemptRebase should detect conflicts and return them.
-	conflictFiles, err := r.attemptRebase(taskRepo)
+	conflicts, err := r.attemptRebase(taskRepo)
 	if err != nil {
 		t.Fatalf("attemptRebase: %v", err)
 	}
 
-	if len(conflictFiles) == 0 {
-		t.Error("expected conflicts, got none")
+	if len(conflicts) == 0 {
+		t.Fatal("expected conflicts, got none")
 	}
 
-	// Verify README.md is in the conflict list.
-	if !slices.Contains(conflictFiles, "README.md") {
-		t.Errorf("expected README.md in conflict files, got: %v", conflictFiles)
+	// Verify README.md is in the conflict list, with both sides of the
+	// hunk and the upstream commit that caused it.
+	c := conflicts[0]
+	if c.File != "README.md" {
+		t.Errorf("expected README.md in conflict files, got: %v", conflicts)
+	}
+	if len(c.Hunks) != 1 || c.Hunks[0].Upstream != "# Upstream change" || c.Hunks[0].Task != "# Feature branch" {
+		t.Errorf("unexpected hunks: %+v", c.Hunks)
+	}
+	if len(c.Commits) != 1 || !strings.HasSuffix(c.Commits[0], "upstream conflicting commit") {
+		t.Errorf("unexpected commits: %v", c.Commits)
 	}
 }
 
@@ -3938,7 +4641,7 @@ func TestAssembleReconcileDocumentEndsPlanMode(t *testing.T) {
 
 func TestAssembleVerifyDocumentEndsPlanMode(t *testing.T) {
 	r := stubRunner(t)
-	doc, err := r.assembleVerifyDocument("spec content", false, map[string]string{"test": "go test ./..."})
+	doc, err := r.assembleVerifyDocument("spec content", "", false, map[string]string{"test": "go test ./..."}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3996,3 +4699,136 @@ func TestResetWorktree(t *testing.T) {
 		t.Error("working tree should be clean after resetWorktree")
 	}
 }
+
+func TestReviewDocumentChecklist(t *testing.T) {
+	r := stubRunner(t)
+	writeFile(t, filepath.Join(r.Design.Path, "review.md"), "# Checklist\n\n- Public API documented\n- No debug output\n")
+
+	result, err := r.assembleReviewDocument("Task content", "", nil, nil)
+	if err != nil {
+		t.Fatalf("assembleReviewDocument: %v", err)
+	}
+	for _, want := range []string{"## Review Checklist", "1. Public API documented", "2. No debug output", "end your final message"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("review document missing %q", want)
+		}
+	}
+
+	merged, err := r.assembleMergeDocument("Task content", mergeDocOpts{Commands: map[string]string{}})
+	if err != nil {
+		t.Fatalf("assembleMergeDocument: %v", err)
+	}
+	if !strings.Contains(merged, "1. Public API documented") {
+		t.Error("merge document missing checklist items")
+	}
+}
+
+func TestReviewDocumentPersona(t *testing.T) {
+	r := stubRunner(t)
+	writeFile(t, filepath.Join(r.Design.Path, "review.md"), "- Public API documented\n")
+	persona := &design.Persona{Name: "security", Focus: "Look for injection.", Checklist: []string{"Input is escaped"}}
+
+	result, err := r.assembleReviewDocument("Task content", "", nil, persona)
+	if err != nil {
+		t.Fatalf("assembleReviewDocument: %v", err)
+	}
+	for _, want := range []string{"## Reviewer Persona: security", "Look for injection.", "1. Public API documented", "2. Input is escaped"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("review document missing %q", want)
+		}
+	}
+}
+
+func TestReviewDocumentNoChecklist(t *testing.T) {
+	r := stubRunner(t)
+	result, err := r.assembleReviewDocument("Task content", "", nil, nil)
+	if err != nil {
+		t.Fatalf("assembleReviewDocument: %v", err)
+	}
+	if strings.Contains(result, "Review Checklist") {
+		t.Error("review document should not contain a checklist without review.md")
+	}
+}
+
+func TestCollectChecklistResults(t *testing.T) {
+	items := []string{"Docs", "Tests"}
+
+	if got := collectChecklistResults("Done, everything is committed.", items); got != nil {
+		t.Errorf("results without a checklist = %v, want nil", got)
+	}
+
+	results := collectChecklistResults("Done.\n\n1. PASS\n2. FAIL: no tests for parser\n", items)
+	if len(results) != 2 || !results[0].Passed || results[1].Passed {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestFocusedTestDocument(t *testing.T) {
+	cmds := map[string]string{"test": "go test ./...", "test-only": "go test -count=1 {pattern}"}
+
+	focusCmd := focusTestCommand(cmds, "./internal/foo/...")
+	if focusCmd != "go test -count=1 ./internal/foo/..." {
+		t.Errorf("focusTestCommand = %q", focusCmd)
+	}
+	if got := focusTestCommand(cmds, ""); got != "" {
+		t.Errorf("focusTestCommand without pattern = %q, want empty", got)
+	}
+	if got := focusTestCommand(map[string]string{"test": "go test ./..."}, "x"); got != "" {
+		t.Errorf("focusTestCommand without template = %q, want empty", got)
+	}
+
+	section := focusedTestSection("./internal/foo/...", focusCmd)
+	if !strings.Contains(section, "# Focus") || !strings.Contains(section, focusCmd) {
+		t.Errorf("focused section missing content:\n%s", section)
+	}
+	if focusedTestSection("", "") != "" {
+		t.Error("focused section should be empty without a pattern")
+	}
+
+	suffix := documentSuffix(suffixOpts{Commands: cmds, FocusTest: "./internal/foo/...", FocusCmd: focusCmd})
+	if strings.Contains(suffix, "Do NOT run any individual test files") {
+		t.Error("focused session should relax the individual test prohibition")
+	}
+	if !strings.Contains(suffix, "focused test session") || !strings.Contains(suffix, focusCmd) {
+		t.Error("focused session suffix missing scoped command")
+	}
+
+	plain := documentSuffix(suffixOpts{Commands: cmds})
+	if !strings.Contains(plain, "Do NOT run any individual test files") {
+		t.Error("regular session should keep the individual test prohibition")
+	}
+}
+
+func TestCleanAll(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+
+	work := filepath.Join(r.BaseDir, config.HydraDir, "work")
+	var dirs []string
+	for _, rel := range []string{"solo", filepath.Join("backend", "api"), filepath.Join("backend", "db")} {
+		wd := filepath.Join(work, rel)
+		mkdirAll(t, filepath.Join(wd, ".git"))
+		dirs = append(dirs, wd)
+	}
+	// A group directory with a non-repo child is not a work directory.
+	mkdirAll(t, filepath.Join(work, "backend", "notes"))
+
+	r.TaskRunner = &taskrun.Commands{Commands: map[string]string{"clean": "touch cleaned"}}
+
+	if err := r.CleanAll(); err != nil {
+		t.Fatalf("CleanAll: %v", err)
+	}
+	for _, wd := range dirs {
+		if _, err := os.Stat(filepath.Join(wd, "cleaned")); err != nil {
+			t.Errorf("clean did not run in %s", wd)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(work, "backend", "notes", "cleaned")); err == nil {
+		t.Error("clean should not run in a non-repo directory")
+	}
+
+	r.TaskRunner = &taskrun.Commands{Commands: map[string]string{"clean": "false"}}
+	if err := r.CleanAll(); err == nil {
+		t.Error("expected error when clean fails")
+	}
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// setupMarker is the file in a work directory's git directory recording
+// that the setup command has run there. Kept out of the work tree, it
+// survives git clean, and it goes away with the worktree, so a re-created
+// work directory is set up again.
+const setupMarker = "hydra-setup"
+
+// setupMarkerPath returns where taskRepo's setup marker is kept.
+func setupMarkerPath(taskRepo repo.VCS) (string, error) {
+	gitDir, err := taskRepo.GitDir()
+	if err != nil {
+		return "", fmt.Errorf("finding git dir: %w", err)
+	}
+	return filepath.Join(gitDir, setupMarker), nil
+}
+
+// runSetup runs the setup command from hydra.yml in a work directory the
+// first time it is prepared, and again only with r.ForceSetup. The marker is
+// written once the command succeeds, so a failed setup is retried by the
+// next command that prepares the directory.
+func (r *Runner) runSetup(taskRepo repo.VCS) error {
+	wd := taskRepo.WorkDir()
+	if r.TaskRunner == nil || !r.TaskRunner.HasCommand("setup", wd) {
+		return nil
+	}
+	marker, err := setupMarkerPath(taskRepo)
+	if err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	if _, err := os.Stat(marker); err == nil && !r.ForceSetup {
+		return nil
+	}
+
+	fmt.Printf("Setting up %s\n", wd)
+	if err := r.commands().Run("setup", wd); err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	return os.WriteFile(marker, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600)
+}
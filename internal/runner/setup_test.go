@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestRunSetup(t *testing.T) {
+	wd := t.TempDir()
+	gitRun(t, "init", "-q", wd)
+	gitRun(t, "-C", wd, "-c", "user.name=test", "-c", "user.email=test@test", "commit", "-q", "--allow-empty", "-m", "init")
+	r := &Runner{TaskRunner: &taskrun.Commands{Commands: map[string]string{"setup": "echo ran >> setup.log"}}}
+	taskRepo := repo.Open(wd)
+
+	runs := func() int {
+		data, _ := os.ReadFile(filepath.Join(wd, "setup.log"))
+		return strings.Count(string(data), "ran")
+	}
+
+	if err := r.runSetup(taskRepo); err != nil {
+		t.Fatalf("runSetup: %v", err)
+	}
+	if err := r.runSetup(taskRepo); err != nil {
+		t.Fatalf("runSetup again: %v", err)
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("setup ran %d times, want once", n)
+	}
+
+	r.ForceSetup = true
+	if err := r.runSetup(taskRepo); err != nil {
+		t.Fatalf("runSetup forced: %v", err)
+	}
+	if n := runs(); n != 2 {
+		t.Errorf("setup ran %d times after --force-setup, want 2", n)
+	}
+
+	if _, err := os.Stat(filepath.Join(wd, ".git", setupMarker)); err != nil {
+		t.Errorf("marker not kept in the git dir: %v", err)
+	}
+
+	// Resetting the work directory cleans out what setup made, so it runs
+	// setup again.
+	if err := r.resetWorktree(taskRepo, "HEAD"); err != nil {
+		t.Fatalf("resetWorktree: %v", err)
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("setup ran %d time(s) since the reset cleaned its log, want 1", n)
+	}
+
+	// A failed setup leaves no marker, so it is retried.
+	r.ForceSetup = false
+	failing := t.TempDir()
+	gitRun(t, "init", "-q", failing)
+	r.TaskRunner.Commands["setup"] = "exit 1"
+	if err := r.runSetup(repo.Open(failing)); err == nil {
+		t.Fatal("expected error from a failing setup")
+	}
+	if _, err := os.Stat(filepath.Join(failing, ".git", setupMarker)); err == nil {
+		t.Error("failed setup should not write the marker")
+	}
+}
@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+// splitResultDir is where Claude writes the proposed tasks in the work
+// directory, one markdown file per task.
+const splitResultDir = "hydra-split"
+
+// splitSection returns the instructions for a session that splits a task.
+func splitSection() string {
+	return "\n\n# Split Into Smaller Tasks\n\n" +
+		"Do NOT implement the task above. It is too large to do in one session; your job is to " +
+		"split it into several smaller tasks that can each be implemented, reviewed, and merged " +
+		"on their own.\n\n" +
+		"1. Read the relevant code to understand what the task involves\n" +
+		"2. Divide the work into two or more tasks, each a self-contained change that leaves the " +
+		"code building and its tests passing\n" +
+		"3. Write each task as markdown to its own file in the `" + splitResultDir + "/` directory " +
+		"of the repository root, named after the task in kebab-case (for example `" +
+		splitResultDir + "/add-session-store.md`)\n" +
+		"4. Stop\n\n" +
+		"The original task is kept as shared context for every new task, so do not repeat it. " +
+		"Each file should say what that task does, which files it touches, and how to tell it is " +
+		"done. Do NOT modify, stage, or commit any other file.\n"
+}
+
+// collectSplit reads the tasks Claude proposed in the work directory and
+// removes them. Tasks are returned sorted by name.
+func collectSplit(wd string) ([]design.SplitPart, error) {
+	dir := filepath.Join(wd, splitResultDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", splitResultDir, err)
+	}
+
+	var parts []design.SplitPart
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name())) //nolint:gosec // path is constructed from our own work dir
+		if err != nil {
+			return nil, fmt.Errorf("reading proposed task %s: %w", e.Name(), err)
+		}
+		parts = append(parts, design.SplitPart{Name: strings.TrimSuffix(e.Name(), ".md"), Content: string(data)})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Name < parts[j].Name })
+
+	if err := os.RemoveAll(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: removing %s: %v\n", splitResultDir, err)
+	}
+	return parts, nil
+}
+
+// formatSplit renders a proposed split for confirmation.
+func formatSplit(taskName string, parts []design.SplitPart) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nProposed split of %s into %d tasks:\n", taskName, len(parts))
+	for _, p := range parts {
+		fmt.Fprintf(&b, "\n--- %s/%s ---\n%s\n", taskName, p.Name, strings.TrimSpace(p.Content))
+	}
+	fmt.Fprintf(&b, "\nThe original task will become tasks/%s/group.md.\n", taskName)
+	return b.String()
+}
+
+// Split asks Claude to break a large pending task into several smaller
+// ones. The proposal is printed and passed to confirm; if confirmed, the
+// task is replaced by a group of the same name, with the original content
+// as the group's context. The code is read from a clean checkout of the
+// default branch in .hydra/work/_split.
+func (r *Runner) Split(taskName string, confirm func(prompt string) bool) error {
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	hydraDir := config.HydraPath(baseDir)
+
+	task, err := r.Design.FindTask(taskName)
+	if err != nil {
+		return err
+	}
+	if task.Group != "" {
+		return fmt.Errorf("task %s is already in group %s; only ungrouped tasks can be split", taskName, task.Group)
+	}
+
+	lk := lock.New(hydraDir, "split:"+taskName)
+	if err := lk.Acquire(); err != nil {
+		return err
+	}
+	defer func() { _ = lk.Release() }()
+
+	// Every split shares the _split work directory, so only one runs at a
+	// time.
+	dirLock := lock.New(hydraDir, "_split")
+	if err := dirLock.Acquire(); err != nil {
+		return fmt.Errorf("another split is using the work directory: %w", err)
+	}
+	defer func() { _ = dirLock.Release() }()
+
+	wd := filepath.Join(r.workRoot(), "_split")
+	splitRepo, err := r.prepareRepo(wd, "hydra/_split")
+	if err != nil {
+		return fmt.Errorf("preparing work directory: %w", err)
+	}
+	if err := splitRepo.Fetch(); err != nil {
+		return fmt.Errorf("fetching origin: %w", err)
+	}
+	defaultBranch, err := r.detectDefaultBranch(splitRepo)
+	if err != nil {
+		return fmt.Errorf("detecting default branch: %w", err)
+	}
+	if err := r.resetWorktree(splitRepo, "origin/"+defaultBranch); err != nil {
+		return fmt.Errorf("resetting work directory: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(wd, splitResultDir)); err != nil {
+		return fmt.Errorf("removing stale %s: %w", splitResultDir, err)
+	}
+
+	content, err := task.Content()
+	if err != nil {
+		return err
+	}
+	doc, err := r.Design.AssembleDocument(content, "")
+	if err != nil {
+		return fmt.Errorf("assembling document: %w", err)
+	}
+	doc += splitSection()
+	if r.Notify {
+		title := r.notifyTitle(taskName)
+		doc += r.locale().section(sectionNotification, sectionData{NotifyTitle: title}, notificationSection(title))
+	}
+
+	claudeFn := r.Claude
+	if claudeFn == nil {
+		claudeFn = invokeClaude
+	}
+	err = runClaude(context.Background(), claudeFn, ClaudeRunConfig{
+		RepoDir:    wd,
+		Shell:      r.toolShell(wd),
+		Output:     &r.output,
+		State:      r.stateTool(task),
+		Document:   doc,
+		Model:      r.Model,
+		Fallbacks:  r.modelFallbacks(),
+		Approval:   r.approvalRules(),
+		AutoAccept: r.AutoAccept,
+		ForceTUI:   r.ForceTUI,
+		TaskName:   "split:" + taskName,
+		HydraDir:   hydraDir,
+	})
+	if err != nil {
+		return inCategory(categoryClaude, fmt.Errorf("claude failed: %w", err))
+	}
+
+	parts, err := collectSplit(wd)
+	if err != nil {
+		return err
+	}
+	if len(parts) < 2 {
+		return fmt.Errorf("claude proposed %d task(s) in %s; nothing to split", len(parts), splitResultDir)
+	}
+
+	fmt.Print(formatSplit(taskName, parts))
+	if !confirm(fmt.Sprintf("Replace %s with these %d tasks?", taskName, len(parts))) {
+		fmt.Println("Split discarded.")
+		return nil
+	}
+	if err := r.Design.SplitTask(task, parts); err != nil {
+		return err
+	}
+	fmt.Printf("Split %s into group %s.\n", taskName, taskName)
+	return nil
+}
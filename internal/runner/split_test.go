@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+func TestCollectSplit(t *testing.T) {
+	wd := t.TempDir()
+	if parts, err := collectSplit(wd); err != nil || parts != nil {
+		t.Fatalf("collectSplit without a split = %v, %v; want nil, nil", parts, err)
+	}
+
+	mkdirAll(t, filepath.Join(wd, splitResultDir))
+	writeFile(t, filepath.Join(wd, splitResultDir, "sessions.md"), "Add sessions.")
+	writeFile(t, filepath.Join(wd, splitResultDir, "login.md"), "Add login.")
+	writeFile(t, filepath.Join(wd, splitResultDir, "notes.txt"), "ignored")
+
+	parts, err := collectSplit(wd)
+	if err != nil {
+		t.Fatalf("collectSplit: %v", err)
+	}
+	want := []design.SplitPart{{Name: "login", Content: "Add login."}, {Name: "sessions", Content: "Add sessions."}}
+	if len(parts) != len(want) || parts[0] != want[0] || parts[1] != want[1] {
+		t.Errorf("parts = %+v, want %+v", parts, want)
+	}
+	if _, err := os.Stat(filepath.Join(wd, splitResultDir)); !os.IsNotExist(err) {
+		t.Error("split directory should be removed from the work directory")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, confirmed := range []bool{true, false} {
+		env := setupTestEnv(t)
+		r, err := New(env.Config)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		r.BaseDir = env.BaseDir
+
+		var doc string
+		r.Claude = func(_ context.Context, cfg ClaudeRunConfig) error {
+			doc = cfg.Document
+			dir := filepath.Join(cfg.RepoDir, splitResultDir)
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				return err
+			}
+			for name, content := range map[string]string{"api": "Add the API.", "ui": "Add the UI."} {
+				if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0o600); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var prompt string
+		if err := r.Split("add-feature", func(p string) bool { prompt = p; return confirmed }); err != nil {
+			t.Fatalf("Split: %v", err)
+		}
+		if !strings.Contains(doc, "Add the feature.") || !strings.Contains(doc, "# Split Into Smaller Tasks") {
+			t.Errorf("document missing task or split instructions:\n%s", doc)
+		}
+		if !strings.Contains(prompt, "2 tasks") {
+			t.Errorf("prompt = %q", prompt)
+		}
+
+		_, findErr := r.Design.FindTask("add-feature/api")
+		if confirmed && findErr != nil {
+			t.Errorf("confirmed split should create add-feature/api: %v", findErr)
+		}
+		if !confirmed {
+			if findErr == nil {
+				t.Error("discarded split should not create tasks")
+			}
+			if _, err := r.Design.FindTask("add-feature"); err != nil {
+				t.Errorf("discarded split should keep the original task: %v", err)
+			}
+		}
+	}
+}
+
+func TestSplitRejectsGroupedTask(t *testing.T) {
+	r := stubRunner(t)
+	mkdirAll(t, filepath.Join(r.Design.Path, "tasks", "backend"))
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "backend", "add-api.md"), "Build API.")
+	err := r.Split("backend/add-api", func(string) bool { return true })
+	if err == nil || !strings.Contains(err.Error(), "already in group") {
+		t.Errorf("Split grouped task: err = %v", err)
+	}
+}
+
+func TestSplitWaitsForOtherSplits(t *testing.T) {
+	env := setupTestEnv(t)
+	r, err := New(env.Config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	r.BaseDir = env.BaseDir
+
+	// Another split, of a different task, has the shared work directory.
+	lk := lock.New(filepath.Join(env.BaseDir, ".hydra"), "_split")
+	if err := lk.Acquire(); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer func() { _ = lk.Release() }()
+
+	r.Claude = func(context.Context, ClaudeRunConfig) error {
+		t.Error("Claude should not run while another split holds the work directory")
+		return nil
+	}
+	err = r.Split("add-feature", func(string) bool { return true })
+	if err == nil || !strings.Contains(err.Error(), "another split") {
+		t.Errorf("Split: err = %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+)
+
+// errNoChanges is returned by a run whose Claude session made no commits.
+var errNoChanges = errors.New("claude produced no changes")
+
+// Stats summarizes hydra's activity over a date range, from record.json and
+// the failure log.
+type Stats struct {
+	Since              time.Time      `json:"since" yaml:"since"`
+	Until              time.Time      `json:"until" yaml:"until"`
+	TasksCompleted     int            `json:"tasks_completed" yaml:"tasks_completed"`
+	Merges             int            `json:"merges" yaml:"merges"`
+	AvgReviewRounds    float64        `json:"avg_review_rounds" yaml:"avg_review_rounds"` // review sessions per completed task
+	Tokens             int64          `json:"tokens" yaml:"tokens"`
+	LinesAdded         int            `json:"lines_added" yaml:"lines_added"`
+	LinesDeleted       int            `json:"lines_deleted" yaml:"lines_deleted"`
+	Failures           int            `json:"failures" yaml:"failures"`
+	FailuresByCategory map[string]int `json:"failures_by_category,omitempty" yaml:"failures_by_category,omitempty"`
+}
+
+// Stats summarizes the record entries and failures from since (inclusive)
+// to until (exclusive). Entries recorded before entries carried a time are
+// not counted. Archived entries are included, so ranges before a
+// 'hydra record archive' still report.
+func (r *Runner) Stats(since, until time.Time) (*Stats, error) {
+	rec := r.Design.Record()
+	archived, err := rec.ArchivedEntries()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := rec.Entries()
+	if err != nil {
+		return nil, err
+	}
+	entries = append(archived, entries...)
+	failures, err := r.Design.Failures().Entries()
+	if err != nil {
+		return nil, err
+	}
+	return computeStats(entries, failures, since, until), nil
+}
+
+// computeStats implements Stats. A task's review rounds are all of its
+// review sessions up to its merge, including those before since. A task
+// whose merge was rolled back no longer counts as completed.
+func computeStats(entries []design.RecordEntry, failures []design.Failure, since, until time.Time) *Stats {
+	s := &Stats{Since: since, Until: until}
+	in := func(t time.Time) bool {
+		return !t.IsZero() && !t.Before(since) && t.Before(until)
+	}
+
+	reviews := make(map[string]int)
+	completed := make(map[string]int) // task -> review rounds before its merge
+	for _, e := range entries {
+		action, task, ok := strings.Cut(e.TaskName, ":")
+		if !ok {
+			action, task = "run", e.TaskName
+		}
+		if action == "review" {
+			reviews[task]++
+		}
+		if !in(e.Time) {
+			continue
+		}
+
+		s.Tokens += e.Tokens
+		s.LinesAdded += e.LinesAdded
+		s.LinesDeleted += e.LinesDeleted
+		if action == "merge" {
+			s.Merges++
+			completed[task] = reviews[task]
+		}
+		if action == "rollback" {
+			delete(completed, task)
+		}
+	}
+
+	s.TasksCompleted = len(completed)
+	if len(completed) > 0 {
+		total := 0
+		for _, rounds := range completed {
+			total += rounds
+		}
+		s.AvgReviewRounds = float64(total) / float64(len(completed))
+	}
+
+	for _, f := range failures {
+		if !in(f.Time) {
+			continue
+		}
+		if s.FailuresByCategory == nil {
+			s.FailuresByCategory = make(map[string]int)
+		}
+		s.Failures++
+		s.FailuresByCategory[f.Category]++
+	}
+	return s
+}
+
+// String renders the stats as a short text report.
+func (s *Stats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Stats from %s to %s\n\n", s.Since.Format(time.DateOnly), s.Until.Add(-time.Second).Format(time.DateOnly))
+	fmt.Fprintf(&b, "  Tasks completed:    %d\n", s.TasksCompleted)
+	fmt.Fprintf(&b, "  Merges:             %d\n", s.Merges)
+	fmt.Fprintf(&b, "  Avg review rounds:  %.1f\n", s.AvgReviewRounds)
+	fmt.Fprintf(&b, "  Tokens:             %d\n", s.Tokens)
+	fmt.Fprintf(&b, "  Lines changed:      +%d -%d\n", s.LinesAdded, s.LinesDeleted)
+	fmt.Fprintf(&b, "  Failures:           %d\n", s.Failures)
+
+	categories := make([]string, 0, len(s.FailuresByCategory))
+	for c := range s.FailuresByCategory {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		ci, cj := s.FailuresByCategory[categories[i]], s.FailuresByCategory[categories[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return categories[i] < categories[j]
+	})
+	for _, c := range categories {
+		fmt.Fprintf(&b, "    %-18s%d\n", c, s.FailuresByCategory[c])
+	}
+	return b.String()
+}
+
+// errBeforeHook wraps failures of the before command.
+var errBeforeHook = errors.New("before hook")
+
+// Failure categories of hydra stats for errors without a sentinel of their
+// own, which are labeled with inCategory where they happen.
+const (
+	categoryRebase = "rebase"
+	categoryPush   = "push"
+	categoryClaude = "claude"
+)
+
+// categorizedError labels an error with the hydra stats category it counts
+// under.
+type categorizedError struct {
+	category string
+	err      error
+}
+
+func (e *categorizedError) Error() string { return e.err.Error() }
+func (e *categorizedError) Unwrap() error { return e.err }
+
+// inCategory labels err with a hydra stats failure category.
+func inCategory(category string, err error) error {
+	return &categorizedError{category: category, err: err}
+}
+
+// failureCategory returns a coarse cause for a failed workflow.
+func failureCategory(err error) string {
+	var categorized *categorizedError
+	switch {
+	case errors.Is(err, errSessionTimeout):
+		return "timeout"
+	case errors.Is(err, errNoChanges):
+		return "no_changes"
+	case errors.Is(err, errPushRejected), errors.Is(err, errPlanRejected), errors.Is(err, errCostDeclined):
+		return "rejected"
+	case errors.Is(err, lock.ErrHeld):
+		return "locked"
+	case errors.Is(err, errBeforeHook):
+		return "before_hook"
+	case errors.Is(err, errRebaseOntoDeclined):
+		return categoryRebase
+	case errors.As(err, &categorized):
+		return categorized.category
+	}
+	return "other"
+}
+
+// recordFailure logs a failed run, review, test, or merge of a task for
+// hydra stats. It does nothing if err is nil.
+func (r *Runner) recordFailure(action, taskName string, err error) {
+	if err == nil {
+		return
+	}
+	f := design.Failure{TaskName: taskName, Action: action, Category: failureCategory(err), Message: err.Error()}
+	if logErr := r.Design.Failures().Add(f); logErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record failure: %v\n", logErr)
+	}
+	r.runFailureHook(action, taskName, err)
+}
+
+// lineStats returns the lines added and deleted by the commits from before
+// to after, or zeros, with a warning, if they can't be counted.
+func lineStats(taskRepo repo.VCS, before, after string) (added, deleted int) {
+	added, deleted, err := taskRepo.LineStats(before, after)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not count changed lines: %v\n", err)
+		return 0, 0
+	}
+	return added, deleted
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+)
+
+func TestComputeStats(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	since, until := day(10, 0), day(17, 0)
+
+	entries := []design.RecordEntry{
+		{TaskName: "old", Tokens: 999, LinesAdded: 999},                     // no time
+		{TaskName: "a", Time: day(8, 9), Tokens: 100},                       // before range
+		{TaskName: "review:a", Time: day(9, 9), Tokens: 50},                 // before range, still a round
+		{TaskName: "review:a", Time: day(10, 9), Tokens: 50, LinesAdded: 3}, // in range
+		{TaskName: "merge:a", Time: day(11, 9), Tokens: 20},                 // a completed after 2 rounds
+		{TaskName: "b", Time: day(12, 9), Tokens: 200, LinesAdded: 40, LinesDeleted: 5},
+		{TaskName: "test:b", Time: day(12, 10), Tokens: 30, LinesAdded: 10},
+		{TaskName: "merge:b", Time: day(13, 9)}, // b completed without review
+		{TaskName: "merge:c", Time: day(17, 0)}, // until is exclusive
+	}
+	failures := []design.Failure{
+		{Category: "timeout", Time: day(10, 1)},
+		{Category: "timeout", Time: day(11, 1)},
+		{Category: "push", Time: day(12, 1)},
+		{Category: "push", Time: day(1, 1)}, // before range
+	}
+
+	s := computeStats(entries, failures, since, until)
+	if s.TasksCompleted != 2 || s.Merges != 2 {
+		t.Errorf("completed = %d, merges = %d, want 2 and 2", s.TasksCompleted, s.Merges)
+	}
+	if s.AvgReviewRounds != 1 {
+		t.Errorf("avg review rounds = %v, want 1", s.AvgReviewRounds)
+	}
+	if s.Tokens != 300 {
+		t.Errorf("tokens = %d, want 300", s.Tokens)
+	}
+	if s.LinesAdded != 53 || s.LinesDeleted != 5 {
+		t.Errorf("lines = +%d -%d, want +53 -5", s.LinesAdded, s.LinesDeleted)
+	}
+	if s.Failures != 3 || s.FailuresByCategory["timeout"] != 2 || s.FailuresByCategory["push"] != 1 {
+		t.Errorf("failures = %d %v", s.Failures, s.FailuresByCategory)
+	}
+
+	out := s.String()
+	for _, want := range []string{"2026-03-10 to 2026-03-16", "Tasks completed:    2", "+53 -5", "timeout"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "timeout") > strings.Index(out, "push") {
+		t.Errorf("categories should be listed most frequent first:\n%s", out)
+	}
+}
+
+func TestComputeStatsRollback(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC) }
+	entries := []design.RecordEntry{
+		{TaskName: "merge:a", Time: day(11)},
+		{TaskName: "merge:b", Time: day(11)},
+		{TaskName: "rollback:a", Time: day(12)},
+	}
+	s := computeStats(entries, nil, day(10), day(17))
+	if s.TasksCompleted != 1 || s.Merges != 2 {
+		t.Errorf("completed = %d, merges = %d, want 1 and 2", s.TasksCompleted, s.Merges)
+	}
+
+	if m, ok := lastMerge(append(entries, design.RecordEntry{TaskName: "merge:a", SHA: "again"}), "a"); !ok || m.SHA != "again" {
+		t.Errorf("lastMerge = %+v, %v; want the latest merge of a", m, ok)
+	}
+	if _, ok := lastMerge(entries, "c"); ok {
+		t.Error("lastMerge found a merge of a task that was never merged")
+	}
+}
+
+func TestFailureCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("wrapped: %w", errSessionTimeout), "timeout"},
+		{errNoChanges, "no_changes"},
+		{fmt.Errorf(`task "x" is %w (PID 12)`, lock.ErrHeld), "locked"},
+		{fmt.Errorf("%w: %w", errBeforeHook, errors.New("exit status 1")), "before_hook"},
+		{inCategory(categoryRebase, errors.New("rebasing onto main: conflict")), "rebase"},
+		{inCategory(categoryPush, errors.New("pushing: rejected")), "push"},
+		{fmt.Errorf("task x: %w", inCategory(categoryClaude, errors.New("claude failed: exit status 1"))), "claude"},
+		{errors.New("rebasing onto main: conflict"), "other"},
+	}
+	for _, tt := range tests {
+		if got := failureCategory(tt.err); got != tt.want {
+			t.Errorf("failureCategory(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	dd, err := design.NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Runner{Design: dd}
+
+	r.recordFailure("run", "ok-task", nil)
+	r.recordFailure("run", "bad-task", errNoChanges)
+
+	failures, err := dd.Failures().Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("got %d failures, want 1", len(failures))
+	}
+	f := failures[0]
+	if f.TaskName != "bad-task" || f.Action != "run" || f.Category != "no_changes" || f.Time.IsZero() {
+		t.Errorf("unexpected failure: %+v", f)
+	}
+}
@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/erikh/hydra/internal/platform"
+)
+
+// statusFileName is the live status file in the .hydra directory. It is
+// rewritten as sessions progress so that tmux status lines, editor
+// extensions, and dashboards can poll it instead of invoking hydra.
+const statusFileName = "status.json"
+
+// statusInterval is how often a Claude session's tokens and last tool call
+// are written to the status file.
+const statusInterval = 2 * time.Second
+
+// Phases a session reports in the status file.
+const (
+	phasePreparing  = "preparing"            // setting up the work directory and document
+	phaseClaude     = "claude"               // the Claude session is running
+	phaseApproval   = "awaiting approval"    // waiting for approve_before_push
+	phasePlanReview = "awaiting plan review" // waiting for hydra plan approve under plan_handoff
+	phasePushing    = "pushing"              // recording and pushing Claude's commits
+)
+
+// liveStatus is the content of .hydra/status.json.
+type liveStatus struct {
+	Updated  time.Time       `json:"updated"`
+	Sessions []sessionStatus `json:"sessions"`
+}
+
+// sessionStatus is one running session in the live status file.
+type sessionStatus struct {
+	Task     string     `json:"task"`
+	Action   string     `json:"action"` // run, review, test, merge, plan, or split
+	PID      int        `json:"pid"`
+	Phase    string     `json:"phase"`
+	Started  time.Time  `json:"started"`
+	LastTool string     `json:"last_tool,omitempty"`
+	Tokens   int64      `json:"tokens,omitempty"`
+	ETA      *time.Time `json:"eta,omitempty"`
+	Updated  time.Time  `json:"updated"`
+}
+
+// statusReporter keeps one session's entry in the live status file up to
+// date. A nil reporter does nothing, so callers need not check.
+type statusReporter struct {
+	path string
+
+	mu     sync.Mutex
+	entry  sessionStatus
+	warned bool
+}
+
+// startStatus adds an entry for the session holding the lock-style task
+// name (e.g. "review:foo") to the status file in hydraDir.
+func startStatus(hydraDir, taskName string) *statusReporter {
+	action, task, ok := strings.Cut(taskName, ":")
+	if !ok {
+		action, task = "run", taskName
+	}
+	s := &statusReporter{
+		path: filepath.Join(hydraDir, statusFileName),
+		entry: sessionStatus{
+			Task:    task,
+			Action:  action,
+			PID:     os.Getpid(),
+			Started: time.Now(),
+		},
+	}
+	s.update(func(*sessionStatus) {})
+	return s
+}
+
+// Phase records the phase the session has reached.
+func (s *statusReporter) Phase(phase string) {
+	s.update(func(e *sessionStatus) { e.Phase = phase })
+}
+
+// ExpectBy records when the session is expected to finish. A later time
+// than the one already recorded is ignored, so a time limit can cap an
+// estimate from run history.
+func (s *statusReporter) ExpectBy(eta time.Time) {
+	s.update(func(e *sessionStatus) {
+		if e.ETA == nil || eta.Before(*e.ETA) {
+			e.ETA = &eta
+		}
+	})
+}
+
+// watch copies the token count and last tool call progress reports into
+// the status file every statusInterval until the returned function is
+// called.
+func (s *statusReporter) watch(progress func() (tokens int64, tool string)) (stop func()) {
+	if s == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		t := time.NewTicker(statusInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				tokens, tool := progress()
+				s.update(func(e *sessionStatus) { e.Tokens, e.LastTool = tokens, tool })
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// Done removes the session's entry from the status file.
+func (s *statusReporter) Done() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report(editStatusFile(s.path, func(st *liveStatus) { st.remove(s.entry) }))
+}
+
+// update applies fn to the session's entry and writes it out.
+func (s *statusReporter) update(fn func(*sessionStatus)) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.entry)
+	s.entry.Updated = time.Now()
+	entry := s.entry
+	s.report(editStatusFile(s.path, func(st *liveStatus) {
+		st.remove(entry)
+		st.Sessions = append(st.Sessions, entry)
+	}))
+}
+
+// report warns about the first failure to write the status file; the
+// status file is best effort and never fails a run.
+func (s *statusReporter) report(err error) {
+	if err != nil && !s.warned {
+		s.warned = true
+		fmt.Fprintf(os.Stderr, "Warning: could not update %s: %v\n", s.path, err)
+	}
+}
+
+// remove drops the entry for the same session as e.
+func (st *liveStatus) remove(e sessionStatus) {
+	st.Sessions = slices.DeleteFunc(st.Sessions, func(o sessionStatus) bool {
+		return o.PID == e.PID && o.Action == e.Action && o.Task == e.Task
+	})
+}
+
+// editStatusFile rewrites the status file at path with fn applied, under an
+// exclusive lock on its directory so concurrent hydra processes don't lose
+// each other's entries. Entries of processes that have exited are dropped.
+func editStatusFile(path string, fn func(*liveStatus)) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	unlock, err := platform.LockDir(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var st liveStatus
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // path is in the .hydra directory
+		// A corrupt file is replaced rather than reported.
+		_ = json.Unmarshal(data, &st)
+	}
+	st.Sessions = slices.DeleteFunc(st.Sessions, func(e sessionStatus) bool {
+		return !platform.ProcessAlive(e.PID)
+	})
+	fn(&st)
+	sort.SliceStable(st.Sessions, func(i, j int) bool { return st.Sessions[i].Started.Before(st.Sessions[j].Started) })
+	if st.Sessions == nil {
+		st.Sessions = []sessionStatus{}
+	}
+	st.Updated = time.Now()
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+statusFileName+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
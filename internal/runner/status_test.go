@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readLiveStatus(t *testing.T, hydraDir string) liveStatus {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(hydraDir, statusFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st liveStatus
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatalf("parsing status file: %v\n%s", err, data)
+	}
+	return st
+}
+
+func TestStatusReporter(t *testing.T) {
+	hydraDir := t.TempDir()
+
+	// An entry left behind by a process that has exited is dropped.
+	stale := liveStatus{Sessions: []sessionStatus{{Task: "old", Action: "run", PID: 1 << 30}}}
+	data, _ := json.Marshal(stale)
+	writeFile(t, filepath.Join(hydraDir, statusFileName), string(data))
+
+	run := startStatus(hydraDir, "add-auth")
+	run.Phase(phasePreparing)
+	review := startStatus(hydraDir, "review:backend/add-api")
+	review.Phase(phaseClaude)
+
+	deadline := time.Now().Add(time.Hour)
+	run.ExpectBy(deadline)
+	run.ExpectBy(deadline.Add(time.Hour)) // later than the first: ignored
+
+	st := readLiveStatus(t, hydraDir)
+	if len(st.Sessions) != 2 {
+		t.Fatalf("sessions = %+v, want add-auth and the review", st.Sessions)
+	}
+	got := st.Sessions[0]
+	if got.Task != "add-auth" || got.Action != "run" || got.Phase != phasePreparing || got.PID != os.Getpid() {
+		t.Errorf("first session = %+v", got)
+	}
+	if got.ETA == nil || !got.ETA.Equal(deadline) {
+		t.Errorf("ETA = %v, want %v", got.ETA, deadline)
+	}
+	if got := st.Sessions[1]; got.Task != "backend/add-api" || got.Action != "review" || got.Phase != phaseClaude {
+		t.Errorf("second session = %+v", got)
+	}
+
+	run.Done()
+	review.Done()
+	if st := readLiveStatus(t, hydraDir); len(st.Sessions) != 0 {
+		t.Errorf("sessions after Done = %+v, want none", st.Sessions)
+	}
+
+	// A nil reporter is a no-op.
+	var none *statusReporter
+	none.Phase(phaseClaude)
+	none.watch(nil)()
+	none.Done()
+}
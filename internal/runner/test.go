@@ -8,12 +8,18 @@ import (
 	"github.com/erikh/hydra/internal/config"
 	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Test runs a test-focused session on a task in review state.
 // Claude adds missing tests, runs test/lint commands, and fixes any issues.
 // The task stays in review state after the session.
-func (r *Runner) Test(taskName string) error {
+func (r *Runner) Test(taskName string) (err error) {
+	ctx, span := tracing.Start(context.Background(), "test", attribute.String("hydra.task", taskName))
+	defer func() { tracing.End(span, err) }()
+
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -25,6 +31,10 @@ func (r *Runner) Test(taskName string) error {
 	if err != nil {
 		return err
 	}
+	defer func() { r.recordFailure("test", taskName, err) }()
+	if err := r.useGroupConfig(task); err != nil {
+		return err
+	}
 
 	// Acquire lock.
 	lk := lock.New(hydraDir, "test:"+taskName)
@@ -32,13 +42,17 @@ func (r *Runner) Test(taskName string) error {
 		return err
 	}
 	defer func() { _ = lk.Release() }()
+	if err := r.checkWorkDirFree(task); err != nil {
+		return err
+	}
 
 	// Prepare work directory (should exist from run).
 	wd := r.workDir(task)
-	taskRepo, err := r.prepareRepo(wd)
+	taskRepo, err := r.prepareRepo(wd, task.BranchName())
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
 	}
+	taskRepo = taskRepo.Traced(ctx)
 
 	// Checkout the task's branch.
 	branch := task.BranchName()
@@ -53,11 +67,11 @@ func (r *Runner) Test(taskName string) error {
 	}
 
 	// Rebase onto latest remote main if requested.
-	var conflictFiles []string
+	var conflicts []repo.Conflict
 	if r.Rebase {
-		conflictFiles, err = r.attemptRebase(taskRepo)
+		conflicts, err = r.attemptRebase(taskRepo)
 		if err != nil {
-			return fmt.Errorf("rebasing onto main: %w", err)
+			return inCategory(categoryRebase, fmt.Errorf("rebasing onto main: %w", err))
 		}
 	}
 
@@ -68,24 +82,38 @@ func (r *Runner) Test(taskName string) error {
 	}
 
 	cmds := r.commandsMap(wd)
-	doc, err := r.assembleTestDocument(content, conflictFiles)
+	doc, err := r.assembleTestDocument(content, conflicts)
 	if err != nil {
 		return fmt.Errorf("assembling test document: %w", err)
 	}
 
+	focusCmd := focusTestCommand(cmds, r.TestOnly)
+	doc += focusedTestSection(r.TestOnly, focusCmd)
+	doc += prepareNotes(wd)
+
 	// Append verification and commit instructions so Claude handles test/lint/staging/committing.
 	sign := taskRepo.HasSigningKey()
+	commitTmpl, err := r.commitTemplate(task)
+	if err != nil {
+		return err
+	}
 	doc += documentSuffix(suffixOpts{
-		Commands:    cmds,
-		Sign:        sign,
-		Timeout:     r.timeout(),
-		Notify:      r.Notify,
-		NotifyTitle: r.notifyTitle(taskName),
+		Commands:     cmds,
+		SerialChecks: r.serialChecks(),
+		Base:         r.targetBranch(),
+		Sign:         sign,
+		Timeout:      r.timeout(),
+		Notify:       r.Notify,
+		NotifyTitle:  r.notifyTitle(taskName),
+		FocusTest:    r.TestOnly,
+		FocusCmd:     focusCmd,
+		CommitTmpl:   commitTmpl,
+		Locale:       r.locale(),
 	})
 
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
 	// Capture HEAD before invoking Claude.
@@ -99,15 +127,28 @@ func (r *Runner) Test(taskName string) error {
 	if claudeFn == nil {
 		claudeFn = invokeClaude
 	}
+	var tokens int64
+	usedModel := modelOrDefault(r.Model)
 	runCfg := ClaudeRunConfig{
-		RepoDir:    taskRepo.Dir,
+		RepoDir:    taskRepo.WorkDir(),
+		Shell:      r.toolShell(taskRepo.WorkDir()),
+		Output:     &r.output,
+		State:      r.stateTool(task),
 		Document:   doc,
 		Model:      r.Model,
+		Fallbacks:  r.modelFallbacks(),
+		Approval:   r.approvalRules(),
 		AutoAccept: r.AutoAccept,
 		PlanMode:   r.PlanMode,
 		ForceTUI:   r.ForceTUI,
+		TaskName:   "test:" + taskName,
+		HydraDir:   hydraDir,
+		Timeout:    r.timeout(),
+		WrapUp:     r.wrapUp(),
+		Tokens:     &tokens,
+		UsedModel:  &usedModel,
 	}
-	if err := claudeFn(context.Background(), runCfg); err != nil {
+	if err := continueOnTimeout(runClaude(ctx, claudeFn, runCfg)); err != nil {
 		return err
 	}
 
@@ -122,15 +163,30 @@ func (r *Runner) Test(taskName string) error {
 		return nil
 	}
 
+	if err := r.enforceDiffPolicy(ctx, claudeFn, runCfg, taskRepo); err != nil {
+		return err
+	}
+	if afterSHA, err = taskRepo.LastCommitSHA(); err != nil {
+		return fmt.Errorf("getting HEAD SHA: %w", err)
+	}
+
 	// Record SHA and push.
-	record := design.NewRecord(r.Config.DesignDir)
-	if err := record.Add(afterSHA, "test:"+taskName); err != nil {
+	record := r.Design.Record()
+	added, deleted := lineStats(taskRepo, beforeSHA, afterSHA)
+	if err := record.AddEntry(design.RecordEntry{
+		SHA:          afterSHA,
+		TaskName:     "test:" + taskLabel(*task),
+		Tokens:       tokens,
+		Model:        usedModel,
+		LinesAdded:   added,
+		LinesDeleted: deleted,
+	}); err != nil {
 		return fmt.Errorf("recording SHA: %w", err)
 	}
 
 	if err := taskRepo.Push(branch); err != nil {
 		if fpErr := taskRepo.ForcePushWithLease(branch); fpErr != nil {
-			return fmt.Errorf("pushing: %w", fpErr)
+			return inCategory(categoryPush, fmt.Errorf("pushing: %w", fpErr))
 		}
 	}
 	fmt.Printf("Test session for %q: tests added, committed, and pushed.\n", taskName)
@@ -140,7 +196,7 @@ func (r *Runner) Test(taskName string) error {
 }
 
 // assembleTestDocument builds a document for the test session.
-func (r *Runner) assembleTestDocument(taskContent string, conflictFiles []string) (string, error) {
+func (r *Runner) assembleTestDocument(taskContent string, conflicts []repo.Conflict) (string, error) {
 	rules, err := r.Design.Rules()
 	if err != nil {
 		return "", err
@@ -172,7 +228,7 @@ func (r *Runner) assembleTestDocument(taskContent string, conflictFiles []string
 	b.WriteString(taskContent)
 	b.WriteString("\n\n")
 
-	b.WriteString(conflictResolutionSection(conflictFiles))
+	b.WriteString(r.conflictSection(conflicts))
 
 	b.WriteString("# Test Instructions\n\n")
 	b.WriteString("You are adding tests for an implementation of the above task. ")
@@ -186,3 +242,34 @@ func (r *Runner) assembleTestDocument(taskContent string, conflictFiles []string
 
 	return b.String(), nil
 }
+
+// focusTestCommand builds the scoped test command for a focused test session
+// from the "test-only" command template in hydra.yml, replacing "{pattern}"
+// with the pattern. Returns empty string if no pattern or template is set.
+func focusTestCommand(cmds map[string]string, pattern string) string {
+	tmpl := cmds["test-only"]
+	if pattern == "" || tmpl == "" {
+		return ""
+	}
+	return strings.ReplaceAll(tmpl, "{pattern}", pattern)
+}
+
+// focusedTestSection returns a markdown section narrowing the test session to
+// tests matching pattern. Returns empty string if pattern is empty.
+func focusedTestSection(pattern, focusCmd string) string {
+	if pattern == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("# Focus\n\n")
+	b.WriteString("Limit this session to tests matching `" + pattern + "` (a package, path, or test name pattern). " +
+		"Add and fix tests only in that scope.\n\n")
+	if focusCmd != "" {
+		b.WriteString("Run the scoped tests with: `" + focusCmd + "`\n\n")
+	} else {
+		b.WriteString("Use the project's test runner to run just the tests matching this pattern " +
+			"(for example, by package path or a test name filter).\n\n")
+	}
+	return b.String()
+}
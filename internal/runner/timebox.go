@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultWrapUp is how long before a session's time limit Claude is told to
+// wrap up when hydra.yml doesn't set wrap_up.
+const defaultWrapUp = 5 * time.Minute
+
+// errSessionTimeout is returned for a Claude session that hydra ended at its
+// time limit.
+var errSessionTimeout = errors.New("session time limit reached")
+
+// wrapUpMessage is the turn sent to Claude when remaining time is left
+// before the session is ended.
+func wrapUpMessage(remaining time.Duration) string {
+	return fmt.Sprintf("Time is nearly up: this session will be ended in %s. "+
+		"Do not start anything new. Make sure what you have builds and passes tests, "+
+		"commit it now, and finish.", remaining)
+}
+
+// scheduleWrapUp calls inject with the wrap-up message once the session is
+// cfg.WrapUp away from its time limit. The returned function cancels it.
+func scheduleWrapUp(cfg ClaudeRunConfig, inject func(string)) (stop func()) {
+	if cfg.Timeout <= 0 || cfg.WrapUp <= 0 {
+		return func() {}
+	}
+	t := time.AfterFunc(cfg.Timeout-cfg.WrapUp, func() { inject(wrapUpMessage(cfg.WrapUp)) })
+	return func() { t.Stop() }
+}
+
+// cliWrapUp arranges the wrap-up turn for a Claude Code CLI session, which
+// hydra cannot type into. The message is written to a file at wrap-up time,
+// and a PostToolUse hook in the returned hooks hands it to Claude after its
+// next tool call, once. Returns no hooks if the session has no time limit.
+func cliWrapUp(cfg ClaudeRunConfig) (hooks cliHooks, cleanup func(), err error) {
+	if cfg.Timeout <= 0 || cfg.WrapUp <= 0 {
+		return nil, func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "hydra-wrap-up-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating wrap-up directory: %w", err)
+	}
+	path := filepath.Join(dir, "message")
+	stop := scheduleWrapUp(cfg, func(msg string) {
+		if err := os.WriteFile(path, []byte(msg+"\n"), 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not queue wrap-up message: %v\n", err)
+		}
+	})
+	cleanup = func() {
+		stop()
+		_ = os.RemoveAll(dir)
+	}
+	return wrapUpHooks(path), cleanup, nil
+}
+
+// wrapUpHooks returns a PostToolUse hook that, if path exists, prints it to
+// stderr, removes it, and exits 2, which Claude Code feeds back to Claude.
+func wrapUpHooks(path string) cliHooks {
+	command := "f=" + shellQuote(path) + `; [ -f "$f" ] || exit 0; cat "$f" >&2; rm -f "$f"; exit 2`
+	return cliHooks{"PostToolUse": {{Matcher: "*", Hooks: []cliHook{{Type: "command", Command: command}}}}}
+}
+
+// cliHook is one command hook in Claude Code settings.
+type cliHook struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// cliMatcher runs its hooks for the tools Matcher matches.
+type cliMatcher struct {
+	Matcher string    `json:"matcher,omitempty"` // tool name pattern; unused by events like Stop
+	Hooks   []cliHook `json:"hooks"`
+}
+
+// cliHooks holds Claude Code hooks by event name, such as "PostToolUse".
+type cliHooks map[string][]cliMatcher
+
+// merge returns the hooks of h and other together.
+func (h cliHooks) merge(other cliHooks) cliHooks {
+	merged := make(cliHooks, len(h)+len(other))
+	for _, hooks := range []cliHooks{h, other} {
+		for event, matchers := range hooks {
+			merged[event] = append(merged[event], matchers...)
+		}
+	}
+	return merged
+}
+
+// settings returns Claude Code settings JSON holding the hooks, or "" if
+// there are none.
+func (h cliHooks) settings() string {
+	if len(h) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(map[string]any{"hooks": h}) // plain strings and maps always marshal
+	return string(data)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sessionTimedOut reports whether err is a Claude session ended at its time
+// limit.
+func sessionTimedOut(err error) bool {
+	return errors.Is(err, errSessionTimeout)
+}
+
+// continueOnTimeout returns err, unless it is a Claude session ended at its
+// time limit, which it reports and returns nil for, so the caller carries
+// on with whatever Claude committed.
+func continueOnTimeout(err error) error {
+	if !sessionTimedOut(err) {
+		return err
+	}
+	fmt.Println("Session time limit reached; continuing with what has been committed.")
+	return nil
+}
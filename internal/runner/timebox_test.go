@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+func TestWrapUp(t *testing.T) {
+	minutes := func(n int) *taskrun.Duration {
+		return &taskrun.Duration{Duration: time.Duration(n) * time.Minute}
+	}
+	tests := []struct {
+		name   string
+		cmds   *taskrun.Commands
+		wrapUp time.Duration
+	}{
+		{"no hydra.yml", nil, 0},
+		{"no timeout", &taskrun.Commands{WrapUp: minutes(3)}, 0},
+		{"default", &taskrun.Commands{Timeout: minutes(60)}, defaultWrapUp},
+		{"configured", &taskrun.Commands{Timeout: minutes(60), WrapUp: minutes(10)}, 10 * time.Minute},
+		{"disabled", &taskrun.Commands{Timeout: minutes(60), WrapUp: minutes(0)}, 0},
+		{"clamped to half the timeout", &taskrun.Commands{Timeout: minutes(6)}, 3 * time.Minute},
+	}
+	for _, tt := range tests {
+		r := &Runner{TaskRunner: tt.cmds}
+		if got := r.wrapUp(); got != tt.wrapUp {
+			t.Errorf("%s: wrapUp = %s, want %s", tt.name, got, tt.wrapUp)
+		}
+	}
+}
+
+func TestScheduleWrapUp(t *testing.T) {
+	got := make(chan string, 1)
+	stop := scheduleWrapUp(ClaudeRunConfig{Timeout: 60 * time.Millisecond, WrapUp: 50 * time.Millisecond},
+		func(msg string) { got <- msg })
+	defer stop()
+
+	select {
+	case msg := <-got:
+		if !strings.Contains(msg, "commit it now") {
+			t.Errorf("wrap-up message = %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("wrap-up message was not sent")
+	}
+
+	stop = scheduleWrapUp(ClaudeRunConfig{}, func(string) { t.Error("wrap-up sent without a time limit") })
+	stop()
+}
+
+func TestWrapUpHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "it's here")
+
+	var settings struct {
+		Hooks map[string][]struct {
+			Matcher string `json:"matcher"`
+			Hooks   []struct {
+				Type    string `json:"type"`
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal([]byte(wrapUpHooks(path).settings()), &settings); err != nil {
+		t.Fatalf("settings are not valid JSON: %v", err)
+	}
+	post := settings.Hooks["PostToolUse"]
+	if len(post) != 1 || len(post[0].Hooks) != 1 || post[0].Hooks[0].Type != "command" {
+		t.Fatalf("unexpected hooks: %+v", settings.Hooks)
+	}
+	command := post[0].Hooks[0].Command
+
+	runHook := func() (int, string) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(t.Context(), "sh", "-c", command)
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), stderr.String()
+		}
+		if err != nil {
+			t.Fatalf("running hook: %v", err)
+		}
+		return 0, stderr.String()
+	}
+
+	if code, _ := runHook(); code != 0 {
+		t.Errorf("hook without a message exited %d, want 0", code)
+	}
+	writeFile(t, path, "wrap up\n")
+	if code, out := runHook(); code != 2 || out != "wrap up\n" {
+		t.Errorf("hook with a message = %d %q, want 2 and the message", code, out)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("hook should remove the message once delivered")
+	}
+	if code, _ := runHook(); code != 0 {
+		t.Errorf("hook after delivery exited %d, want 0", code)
+	}
+}
+
+func TestRunClaudeTimeout(t *testing.T) {
+	waitForDeadline := func(ctx context.Context, _ ClaudeRunConfig) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	err := runClaude(context.Background(), waitForDeadline, ClaudeRunConfig{Timeout: 10 * time.Millisecond})
+	if !errors.Is(err, errSessionTimeout) {
+		t.Errorf("err = %v, want errSessionTimeout", err)
+	}
+
+	failing := func(context.Context, ClaudeRunConfig) error { return errors.New("boom") }
+	err = runClaude(context.Background(), failing, ClaudeRunConfig{Timeout: time.Minute})
+	if err == nil || errors.Is(err, errSessionTimeout) {
+		t.Errorf("err = %v, want the session's own error", err)
+	}
+}
@@ -7,14 +7,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
 	"github.com/erikh/hydra/internal/repo"
 )
 
 // Verify uses Claude to verify that all items in functional.md are satisfied
-// by the current codebase.
+// by the current codebase, or with VerifySince set, only those touched since
+// then. With VerifyReport set, the outcome is saved to state/verify.json.
 func (r *Runner) Verify() error {
+	return r.verify(nil)
+}
+
+// verify runs Verify. Claude is asked to name the promise in failures
+// concerning one of promises, so they can be matched to a milestone.
+func (r *Runner) verify(promises []design.Promise) error {
 	baseDir := r.BaseDir
 	if baseDir == "" {
 		baseDir = "."
@@ -30,7 +38,7 @@ func (r *Runner) Verify() error {
 	}
 
 	// Prepare work directory.
-	wd := filepath.Join(baseDir, config.HydraDir, "work", "_verify")
+	wd := filepath.Join(r.workRoot(), "_verify")
 	verifyRepo, err := r.prepareRepo(wd, "hydra/_verify")
 	if err != nil {
 		return fmt.Errorf("preparing work directory: %w", err)
@@ -48,15 +56,29 @@ func (r *Runner) Verify() error {
 		return fmt.Errorf("resetting work directory: %w", err)
 	}
 
+	scope := ""
+	if r.VerifySince != "" {
+		scoped, since, err := r.scopeVerify(verifyRepo, "origin/"+defaultBranch, functional)
+		if err != nil {
+			return err
+		}
+		if len(scoped) == 0 {
+			fmt.Printf("No requirements changed since %s; nothing to verify.\n", since)
+			return nil
+		}
+		fmt.Printf("Verifying %d requirement(s) changed since %s.\n", len(scoped), since)
+		functional, scope = strings.Join(scoped, "\n"), since
+	}
+
 	// Run before hook.
 	if err := r.runBeforeHook(wd); err != nil {
-		return fmt.Errorf("before hook: %w", err)
+		return fmt.Errorf("%w: %w", errBeforeHook, err)
 	}
 
 	// Assemble document.
 	sign := verifyRepo.HasSigningKey()
 	cmds := r.commandsMap(wd)
-	doc, err := r.assembleVerifyDocument(functional, sign, cmds)
+	doc, err := r.assembleVerifyDocument(functional, scope, sign, cmds, promises)
 	if err != nil {
 		return fmt.Errorf("assembling verify document: %w", err)
 	}
@@ -72,16 +94,21 @@ func (r *Runner) Verify() error {
 	if claudeFn == nil {
 		claudeFn = invokeClaude
 	}
-	err = claudeFn(context.Background(), ClaudeRunConfig{
+	err = runClaude(context.Background(), claudeFn, ClaudeRunConfig{
 		RepoDir:    wd,
+		Shell:      r.toolShell(wd),
+		Output:     &r.output,
 		Document:   doc,
 		Model:      r.Model,
+		Fallbacks:  r.modelFallbacks(),
+		Approval:   r.approvalRules(),
 		AutoAccept: r.AutoAccept,
 		PlanMode:   r.PlanMode,
 		ForceTUI:   r.ForceTUI,
+		TaskName:   "verify",
 	})
 	if err != nil {
-		return fmt.Errorf("claude failed: %w", err)
+		return inCategory(categoryClaude, fmt.Errorf("claude failed: %w", err))
 	}
 
 	// Check for verify-passed.txt or verify-failed.txt.
@@ -94,8 +121,17 @@ func (r *Runner) Verify() error {
 		if err := r.pushVerifyFixes(verifyRepo, beforeSHA); err != nil {
 			return err
 		}
+		verifiedSHA, err := verifyRepo.LastCommitSHA()
+		if err != nil {
+			return fmt.Errorf("getting HEAD SHA after verify: %w", err)
+		}
+		if err := r.saveVerifyReport(verifiedSHA, true, ""); err != nil {
+			return err
+		}
 
-		if syncErr := r.Sync(nil); syncErr != nil {
+		// Verify runs unattended, so it skips Sync's prompt to delete
+		// orphaned branches.
+		if syncErr := r.syncIssues(nil); syncErr != nil {
 			fmt.Fprintf(os.Stderr, "Warning: post-verify sync failed: %v\n", syncErr)
 		}
 		return nil
@@ -108,14 +144,117 @@ func (r *Runner) Verify() error {
 		}
 		fmt.Println("Verification failed:")
 		fmt.Println(string(data))
+		// Fixes are only pushed when everything passes, so the failures
+		// are those of the default branch as it was.
+		if err := r.saveVerifyReport(beforeSHA, false, string(data)); err != nil {
+			return err
+		}
 		return errors.New("functional requirements verification failed")
 	}
 
 	return errors.New("claude did not produce verify-passed.txt or verify-failed.txt")
 }
 
+// saveVerifyReport saves the outcome of a verification of sha, with the
+// contents of verify-failed.txt if it failed, if VerifyReport is set.
+func (r *Runner) saveVerifyReport(sha string, passed bool, failed string) error {
+	if !r.VerifyReport {
+		return nil
+	}
+	failures, listed := design.ParseVerifyFailures(failed)
+	return r.Design.SaveVerifyReport(design.VerifyReport{
+		Time:     time.Now().UTC().Truncate(time.Second),
+		SHA:      sha,
+		Since:    r.VerifySince,
+		Passed:   passed,
+		Failures: failures,
+		Unlisted: !passed && !listed,
+	})
+}
+
+// scopeVerify returns the requirements of functional touched since
+// VerifySince, a commit or a date: those whose text changed in
+// functional.md since then, and those that mention a file changed on ref
+// since then. since describes the commit it resolved to.
+func (r *Runner) scopeVerify(verifyRepo repo.VCS, ref, functional string) (scoped []string, since string, err error) {
+	sha, when, err := resolveSince(verifyRepo, ref, r.VerifySince)
+	if err != nil {
+		return nil, "", err
+	}
+	since = shortSHA(sha) + " (" + when.Local().Format("2006-01-02 15:04") + ")"
+
+	files, err := verifyRepo.ChangedFiles(sha, ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("listing files changed since %s: %w", shortSHA(sha), err)
+	}
+
+	// The design directory's git history holds functional.md as it was.
+	// Without it, only requirements covering changed code are in scope.
+	designRepo := &repo.Repo{Dir: r.Design.Path}
+	old, ok, err := designRepo.FileBefore("functional.md", when)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: no history of functional.md (%v); verifying requirements that cover changed files only\n", err)
+		old = functional
+	case !ok:
+		old = ""
+	}
+	return changedRequirements(functional, old, files), since, nil
+}
+
+// resolveSince resolves hydra verify --since to a commit on ref and the
+// time it stands for: for a date (YYYY-MM-DD or RFC 3339), the newest
+// commit before it, and for a commit, its committer time.
+func resolveSince(verifyRepo repo.VCS, ref, since string) (string, time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		t, err := time.ParseInLocation(layout, since, time.Local)
+		if err != nil {
+			continue
+		}
+		sha, err := verifyRepo.CommitBefore(ref, t)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("finding the last commit before %s: %w", since, err)
+		}
+		if sha == "" {
+			return "", time.Time{}, fmt.Errorf("%s has no commits before %s", ref, since)
+		}
+		return sha, t, nil
+	}
+
+	sha, when, err := verifyRepo.CommitInfo(since)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("--since %q is neither a date nor a commit: %w", since, err)
+	}
+	if !verifyRepo.IsAncestor(sha, ref) {
+		return "", time.Time{}, fmt.Errorf("commit %s is not on %s", shortSHA(sha), ref)
+	}
+	return sha, when, nil
+}
+
+// changedRequirements returns the sections of functional that are not in
+// old, word for word, or that mention one of files.
+func changedRequirements(functional, old string, files []string) []string {
+	before := make(map[string]bool)
+	for _, section := range design.MarkdownSections(old) {
+		before[strings.TrimSpace(section)] = true
+	}
+	terms := fileTerms(files)
+	var changed []string
+	for _, section := range design.MarkdownSections(functional) {
+		if strings.TrimSpace(section) == "" {
+			continue
+		}
+		if !before[strings.TrimSpace(section)] || mentionsAny(section, terms) {
+			changed = append(changed, section)
+		}
+	}
+	return changed
+}
+
 // assembleVerifyDocument builds the prompt for the verify workflow.
-func (r *Runner) assembleVerifyDocument(functional string, sign bool, cmds map[string]string) (string, error) {
+// A non-empty scope says functional holds only the requirements touched
+// since then.
+func (r *Runner) assembleVerifyDocument(functional, scope string, sign bool, cmds map[string]string, promises []design.Promise) (string, error) {
 	rules, err := r.Design.Rules()
 	if err != nil {
 		return "", err
@@ -128,8 +267,15 @@ func (r *Runner) assembleVerifyDocument(functional string, sign bool, cmds map[s
 
 	var b strings.Builder
 
-	b.WriteString("# Mission\n\nYour objective is to verify that every requirement in the functional specification " +
-		"below is satisfied by the current codebase. If code does not match the specification, fix the code.\n\n")
+	if scope == "" {
+		b.WriteString("# Mission\n\nYour objective is to verify that every requirement in the functional specification " +
+			"below is satisfied by the current codebase. If code does not match the specification, fix the code.\n\n")
+	} else {
+		b.WriteString("# Mission\n\nYour objective is to verify that every requirement in the functional specification " +
+			"below is satisfied by the current codebase. These are the requirements that changed, or that cover code " +
+			"that changed, since " + scope + "; the rest of functional.md is out of scope. " +
+			"If code does not match the specification, fix the code.\n\n")
+	}
 
 	if rules != "" {
 		b.WriteString("# Rules\n\n")
@@ -152,33 +298,49 @@ func (r *Runner) assembleVerifyDocument(functional string, sign bool, cmds map[s
 	b.WriteString("2. Confirm the implementation matches the specification\n")
 	b.WriteString("3. If the code does not satisfy a requirement, fix the code to match the specification\n")
 	b.WriteString("4. Verify that the requirement has adequate test coverage — there should be tests that exercise the described behavior, including edge cases and error paths\n")
-	b.WriteString("5. Run tests according to the hydra.yml test task, serially\n\n")
+	if cmds["test"] != "" || cmds["lint"] != "" {
+		// How the checks run, serially or side by side, is up to the
+		// Verification section, so the step only points at it.
+		b.WriteString("5. Run the checks listed under Verification below\n")
+	}
+	b.WriteString("\n")
 
-	b.WriteString(verificationSection(cmds))
+	l := r.locale()
+	data := sectionData{Test: cmds["test"], Lint: cmds["lint"], Serial: r.serialChecks(), Sign: sign, Upstream: upstreamRef(r.targetBranch())}
+	b.WriteString(l.section(sectionVerification, data, verificationSection(cmds, r.serialChecks())))
 
 	b.WriteString("\nIf ALL requirements are satisfied, all have adequate test coverage, and all tests pass, " +
 		"create a file called `verify-passed.txt` containing \"PASS\" and nothing else.\n\n")
 
 	b.WriteString("If ANY requirement is NOT satisfied or lacks adequate test coverage, " +
 		"create a file called `verify-failed.txt` listing each failed requirement and why it failed " +
-		"(including any that lack tests).\n\n")
+		"(including any that lack tests), one `- ` list item per requirement.\n\n")
+
+	if len(promises) > 0 {
+		b.WriteString("These requirements were promised for a milestone:\n\n")
+		for _, p := range promises {
+			b.WriteString("- " + p.Heading + "\n")
+		}
+		b.WriteString("\nIf a failed requirement concerns one of them, repeat the promise exactly as written above " +
+			"in its list item.\n\n")
+	}
 
 	b.WriteString("Do not modify the functional specification. " +
 		"The specification is the source of truth — if code does not match the specification, fix the code.\n")
 
-	b.WriteString(commitInstructions(sign, cmds))
-	b.WriteString(rebaseAndPushSection(cmds))
+	b.WriteString(l.section(sectionCommit, data, commitInstructions(sign, cmds)))
+	b.WriteString(l.section(sectionSync, data, rebaseAndPushSection(cmds, "")))
 
 	b.WriteString("\n# Reminder\n\n")
 	b.WriteString("The functional specification is authoritative. Fix code to match it, never the reverse. " +
 		"Commit your changes, then create verify-passed.txt or verify-failed.txt when done.\n")
 
-	b.WriteString(planModeInstruction)
+	b.WriteString(l.section(sectionPlanMode, data, planModeInstruction))
 	return b.String(), nil
 }
 
 // pushVerifyFixes rebases and pushes if Claude committed changes during verify.
-func (r *Runner) pushVerifyFixes(verifyRepo *repo.Repo, beforeSHA string) error {
+func (r *Runner) pushVerifyFixes(verifyRepo repo.VCS, beforeSHA string) error {
 	afterSHA, err := verifyRepo.LastCommitSHA()
 	if err != nil {
 		return fmt.Errorf("getting HEAD SHA after verify: %w", err)
@@ -194,11 +356,11 @@ func (r *Runner) pushVerifyFixes(verifyRepo *repo.Repo, beforeSHA string) error
 	if err != nil {
 		return fmt.Errorf("detecting default branch: %w", err)
 	}
-	if err := verifyRepo.Rebase("origin/" + defaultBranch); err != nil {
-		return fmt.Errorf("rebasing against origin/%s before push: %w", defaultBranch, err)
+	if err := r.rebaseOnUpstream(verifyRepo, "origin/"+defaultBranch); err != nil {
+		return inCategory(categoryRebase, fmt.Errorf("rebasing against origin/%s before push: %w", defaultBranch, err))
 	}
 	if err := verifyRepo.PushMain(); err != nil {
-		return fmt.Errorf("pushing: %w", err)
+		return inCategory(categoryPush, fmt.Errorf("pushing: %w", err))
 	}
 	fmt.Println("Pushed verify fixes to origin.")
 	return nil
@@ -124,8 +124,13 @@ func TestVerifyDocumentContents(t *testing.T) {
 	if !strings.Contains(captured, "Do not modify the functional specification") {
 		t.Error("document missing 'Do not modify the functional specification' instruction")
 	}
-	if !strings.Contains(captured, "serially") {
-		t.Error("document missing serial test running instruction")
+	if !strings.Contains(captured, "5. Run the checks listed under Verification below") {
+		t.Error("document missing the step to run the checks")
+	}
+	// Without serial_checks the Verification section runs the checks
+	// concurrently, and nothing else in the document may say otherwise.
+	if !strings.Contains(captured, "running them concurrently") || strings.Contains(captured, "serially") {
+		t.Error("document should only ask for the checks to run concurrently")
 	}
 
 	// Verify rules and lint are included.
@@ -276,3 +281,38 @@ func TestVerifyRecoversFromMidRebase(t *testing.T) {
 		t.Fatalf("Verify should recover from mid-rebase, got: %v", err)
 	}
 }
+
+func TestChangedRequirements(t *testing.T) {
+	old := "# Spec\n\n## Login\n\nUsers log in with a password.\n\n## Export\n\nExports in internal/export are CSV.\n\n## Search\n\nSearch is fuzzy.\n"
+	functional := "# Spec\n\n## Login\n\nUsers log in with a password or a passkey.\n\n## Export\n\nExports in internal/export are CSV.\n\n## Search\n\nSearch in internal/search is fuzzy.\n\n## Audit\n\nChanges are logged.\n"
+
+	got := changedRequirements(functional, old, nil)
+	var headings []string
+	for _, s := range got {
+		headings = append(headings, strings.SplitN(s, "\n", 2)[0])
+	}
+	if strings.Join(headings, ",") != "## Login,## Search,## Audit" {
+		t.Errorf("changed requirements = %v, want Login, Search, Audit", headings)
+	}
+
+	// Unchanged requirements that cover changed code are in scope too.
+	got = changedRequirements(old, old, []string{"internal/export/csv.go"})
+	if len(got) != 1 || !strings.HasPrefix(got[0], "## Export") {
+		t.Errorf("requirements covering changed files = %q, want Export", got)
+	}
+
+	if got := changedRequirements(old, old, []string{"main.go"}); len(got) != 0 {
+		t.Errorf("nothing changed, got %q", got)
+	}
+}
+
+func TestVerifyDocumentScope(t *testing.T) {
+	r := stubRunner(t)
+	doc, err := r.assembleVerifyDocument("## Login\n\nUsers log in.", "abc123 (2026-03-01 10:00)", false, nil, nil)
+	if err != nil {
+		t.Fatalf("assembleVerifyDocument: %v", err)
+	}
+	if !strings.Contains(doc, "since abc123 (2026-03-01 10:00); the rest of functional.md is out of scope") {
+		t.Errorf("scoped document does not name its scope:\n%s", doc)
+	}
+}
@@ -0,0 +1,264 @@
+package runner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/erikh/hydra/internal/config"
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/lock"
+	"github.com/erikh/hydra/internal/repo"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// WorkDir describes a work directory for hydra workdirs. Directories that
+// belong to no task are named by their path under the work root and have
+// no state.
+type WorkDir struct {
+	Task     string           `json:"task" yaml:"task"`
+	State    design.TaskState `json:"state,omitempty" yaml:"state,omitempty"`
+	Orphaned bool             `json:"orphaned,omitempty" yaml:"orphaned,omitempty"` // its task is gone; hydra fix removes it
+	Path     string           `json:"path" yaml:"path"`
+	Branch   string           `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Size     int64            `json:"size" yaml:"size"`           // bytes
+	LastUsed time.Time        `json:"last_used" yaml:"last_used"` // newest modification time in the directory
+}
+
+// WorkRoot returns the directory work directories live in for the project
+// in baseDir: work_dir from cmds, with a leading ~/ expanded and a relative
+// path taken from baseDir, or .hydra/work when cmds is nil or has no
+// work_dir.
+func WorkRoot(baseDir string, cmds *taskrun.Commands) string {
+	if cmds == nil || cmds.WorkDir == "" {
+		return filepath.Join(config.HydraPath(baseDir), "work")
+	}
+	dir := cmds.WorkDir
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	return dir
+}
+
+// WorkDirs returns the work directory of every task that has one, and the
+// other git checkouts under the work root: hydra's own, such as _verify, and
+// those left behind by tasks that are gone. They are sorted largest first.
+func (r *Runner) WorkDirs() ([]WorkDir, error) {
+	tasks, err := r.Design.AllTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []WorkDir
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		wd := r.workDir(&t)
+		seen[wd] = true
+		if info, err := os.Stat(wd); err != nil || !info.IsDir() {
+			continue
+		}
+		d := WorkDir{Task: taskLabel(t), State: t.State, Path: wd}
+		if err := d.measure(); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, d)
+	}
+
+	others, err := r.workDirs()
+	if err != nil {
+		return nil, err
+	}
+	for _, wd := range others {
+		if seen[wd] {
+			continue
+		}
+		rel, err := filepath.Rel(r.workRoot(), wd)
+		if err != nil {
+			return nil, err
+		}
+		d := WorkDir{Task: filepath.ToSlash(rel), Path: wd, Orphaned: !slices.Contains(specialWorkDirs, rel)}
+		if err := d.measure(); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, d)
+	}
+
+	sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].Size > dirs[j].Size })
+	return dirs, nil
+}
+
+// measure fills in the directory's size, last use, and checked-out branch.
+func (d *WorkDir) measure() error {
+	size, lastUsed, err := dirUsage(d.Path)
+	if err != nil {
+		return fmt.Errorf("measuring %s: %w", d.Path, err)
+	}
+	d.Size, d.LastUsed = size, lastUsed
+	if repo.IsGitRepo(d.Path) {
+		if branch, err := repo.OpenVCS(d.Path).CurrentBranch(); err == nil {
+			d.Branch = branch
+		}
+	}
+	return nil
+}
+
+// dirUsage returns the total size of the regular files under dir and the
+// newest modification time of anything in it.
+func dirUsage(dir string) (size int64, lastUsed time.Time, err error) {
+	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(lastUsed) {
+			lastUsed = info.ModTime()
+		}
+		return nil
+	})
+	return size, lastUsed, err
+}
+
+// FormatWorkDirs renders work directories as a table, with a total.
+func FormatWorkDirs(dirs []WorkDir, now time.Time) string {
+	if len(dirs) == 0 {
+		return "No work directories.\n"
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TASK\tSTATE\tBRANCH\tSIZE\tLAST USED")
+	var total int64
+	for _, d := range dirs {
+		total += d.Size
+		state := string(d.State)
+		switch {
+		case d.Orphaned:
+			state = "orphaned"
+		case state == "":
+			state = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s ago\n", d.Task, state, d.Branch, FormatSize(d.Size), formatAge(now.Sub(d.LastUsed)))
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(&b, "\n%d work directories, %s total\n", len(dirs), FormatSize(total))
+	return b.String()
+}
+
+// FormatSize renders a byte count with a binary unit, e.g. "1.5 GiB".
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. "3d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+// PruneOpts selects the work directories hydra workdirs prune removes.
+type PruneOpts struct {
+	OlderThan time.Duration      // only directories unused for at least this long
+	States    []design.TaskState // only directories of tasks in these states
+	DryRun    bool               // list what would be removed without removing it
+}
+
+// PruneWorkDirs removes the work directories selected by opts, running the
+// teardown command in each first, and returns them. Directories of tasks
+// with a run, review, or merge in progress are skipped.
+func (r *Runner) PruneWorkDirs(opts PruneOpts, now time.Time) ([]WorkDir, error) {
+	dirs, err := r.WorkDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	baseDir := r.BaseDir
+	if baseDir == "" {
+		baseDir = "."
+	}
+	hydraDir := config.HydraPath(baseDir)
+
+	var pruned []WorkDir
+	for _, d := range dirs {
+		if !slices.Contains(opts.States, d.State) || now.Sub(d.LastUsed) < opts.OlderThan {
+			continue
+		}
+		if taskBusy(hydraDir, d.Task) {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s, which is in use\n", d.Task)
+			continue
+		}
+		if opts.DryRun {
+			pruned = append(pruned, d)
+			continue
+		}
+
+		task, err := r.Design.FindTaskAny(d.Task)
+		if err != nil {
+			return pruned, err
+		}
+		if err := r.useGroupConfig(task); err != nil {
+			return pruned, err
+		}
+		if err := r.removeWorkDir(d.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not remove work directory for %s: %v\n", d.Task, err)
+			continue
+		}
+		pruned = append(pruned, d)
+	}
+	return pruned, nil
+}
+
+// taskBusy reports whether any hydra command holds one of the task's locks,
+// or hydra review dev is serving its work directory.
+func taskBusy(hydraDir, taskName string) bool {
+	for _, name := range taskLocks(taskName) {
+		if lock.New(hydraDir, name).IsHeld() {
+			return true
+		}
+	}
+	holders, err := lock.SharedHolders(hydraDir, "dev:"+taskName)
+	return err == nil && len(holders) > 0
+}
+
+// removeWorkDir runs the teardown command in a work directory and removes
+// it, detaching the worktree from the main repository where possible.
+func (r *Runner) removeWorkDir(wd string) error {
+	r.runTeardown(wd)
+	if r.Config != nil {
+		if err := repo.Open(r.Config.RepoDir).WorktreeRemove(wd); err == nil {
+			return nil
+		}
+	}
+	return os.RemoveAll(wd)
+}
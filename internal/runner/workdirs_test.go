@@ -0,0 +1,194 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/design"
+	"github.com/erikh/hydra/internal/taskrun"
+)
+
+// workDirsRunner returns a runner with a completed task, a review task, and
+// a pending task without a work directory. The completed task's work
+// directory was last used 40 days before now, the review task's today.
+func workDirsRunner(t *testing.T, now time.Time) *Runner {
+	t.Helper()
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+
+	for _, st := range []string{"completed", "review"} {
+		mkdirAll(t, filepath.Join(r.Design.Path, "state", st))
+	}
+	writeFile(t, filepath.Join(r.Design.Path, "state", "completed", "old-task.md"), "Old.")
+	writeFile(t, filepath.Join(r.Design.Path, "state", "review", "new-task.md"), "New.")
+	writeFile(t, filepath.Join(r.Design.Path, "tasks", "pending-task.md"), "Pending.")
+
+	old := filepath.Join(r.BaseDir, ".hydra", "work", "old-task")
+	mkdirAll(t, filepath.Join(old, "build"))
+	writeFile(t, filepath.Join(old, "build", "out.bin"), strings.Repeat("x", 4096))
+	stale := now.Add(-40 * 24 * time.Hour)
+	for _, p := range []string{filepath.Join(old, "build", "out.bin"), filepath.Join(old, "build"), old} {
+		if err := os.Chtimes(p, stale, stale); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recent := filepath.Join(r.BaseDir, ".hydra", "work", "new-task")
+	mkdirAll(t, recent)
+	writeFile(t, filepath.Join(recent, "main.go"), "package main\n")
+	return r
+}
+
+func TestWorkDirs(t *testing.T) {
+	now := time.Now()
+	r := workDirsRunner(t, now)
+
+	dirs, err := r.WorkDirs()
+	if err != nil {
+		t.Fatalf("WorkDirs: %v", err)
+	}
+	if len(dirs) != 2 {
+		t.Fatalf("got %d work dirs, want 2: %+v", len(dirs), dirs)
+	}
+	if dirs[0].Task != "old-task" || dirs[0].State != design.StateCompleted || dirs[0].Size != 4096 {
+		t.Errorf("largest work dir = %+v, want old-task (completed, 4096 bytes)", dirs[0])
+	}
+	if age := now.Sub(dirs[0].LastUsed); age < 39*24*time.Hour {
+		t.Errorf("old-task last used %v ago, want about 40 days", age)
+	}
+
+	out := FormatWorkDirs(dirs, now)
+	for _, want := range []string{"TASK", "old-task", "completed", "4.0 KiB", "40d ago", "2 work directories"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorkDirsOrphaned(t *testing.T) {
+	now := time.Now()
+	r := workDirsRunner(t, now)
+	work := filepath.Join(r.BaseDir, ".hydra", "work")
+	gitRun(t, "init", "-q", filepath.Join(work, "gone-task"))
+	gitRun(t, "init", "-q", filepath.Join(work, "_verify"))
+
+	dirs, err := r.WorkDirs()
+	if err != nil {
+		t.Fatalf("WorkDirs: %v", err)
+	}
+	byTask := make(map[string]WorkDir)
+	for _, d := range dirs {
+		byTask[d.Task] = d
+	}
+	if d, ok := byTask["gone-task"]; !ok || !d.Orphaned || d.State != "" {
+		t.Errorf("gone-task = %+v, want an orphaned work dir", d)
+	}
+	if d, ok := byTask["_verify"]; !ok || d.Orphaned {
+		t.Errorf("_verify = %+v, want hydra's own work dir", d)
+	}
+	if len(dirs) != 4 {
+		t.Errorf("got %d work dirs, want 4: %+v", len(dirs), dirs)
+	}
+	if out := FormatWorkDirs(dirs, now); !strings.Contains(out, "orphaned") {
+		t.Errorf("table should mark the orphaned work dir:\n%s", out)
+	}
+
+	pruned, err := r.PruneWorkDirs(PruneOpts{States: []design.TaskState{design.StateCompleted}, DryRun: true}, now)
+	if err != nil {
+		t.Fatalf("PruneWorkDirs: %v", err)
+	}
+	for _, d := range pruned {
+		if d.State == "" {
+			t.Errorf("prune should leave work dirs without a task to hydra fix, got %+v", d)
+		}
+	}
+}
+
+func TestWorkRoot(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		workDir string
+		want    string
+	}{
+		{"default", "", filepath.Join("proj", ".hydra", "work")},
+		{"relative", "scratch/work", filepath.Join("proj", "scratch", "work")},
+		{"absolute", "/dev/shm/hydra", "/dev/shm/hydra"},
+		{"home", "~/hydra-work", filepath.Join(home, "hydra-work")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorkRoot("proj", &taskrun.Commands{WorkDir: tt.workDir}); got != tt.want {
+				t.Errorf("WorkRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := WorkRoot("proj", nil); got != filepath.Join("proj", ".hydra", "work") {
+		t.Errorf("WorkRoot() without hydra.yml = %q", got)
+	}
+}
+
+func TestWorkDirOverride(t *testing.T) {
+	r := stubRunner(t)
+	r.BaseDir = t.TempDir()
+	task := &design.Task{Name: "add-auth", Group: "backend"}
+
+	r.TaskRunner = &taskrun.Commands{WorkDir: "fast"}
+	if got, want := r.workDir(task), filepath.Join(r.BaseDir, "fast", "backend", "add-auth"); got != want {
+		t.Errorf("workDir() with work_dir = %q, want %q", got, want)
+	}
+	r.WorkRoot = "/mnt/scratch"
+	if got, want := r.workDir(task), filepath.Join("/mnt/scratch", "backend", "add-auth"); got != want {
+		t.Errorf("workDir() with --work-dir = %q, want %q", got, want)
+	}
+}
+
+func TestPruneWorkDirs(t *testing.T) {
+	now := time.Now()
+	r := workDirsRunner(t, now)
+	opts := PruneOpts{OlderThan: 30 * 24 * time.Hour, States: []design.TaskState{design.StateCompleted, design.StateReview}}
+
+	opts.DryRun = true
+	pruned, err := r.PruneWorkDirs(opts, now)
+	if err != nil {
+		t.Fatalf("PruneWorkDirs: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Task != "old-task" {
+		t.Fatalf("dry run pruned %+v, want only old-task", pruned)
+	}
+	if _, err := os.Stat(pruned[0].Path); err != nil {
+		t.Fatal("dry run removed the work directory")
+	}
+
+	opts.DryRun = false
+	if _, err := r.PruneWorkDirs(opts, now); err != nil {
+		t.Fatalf("PruneWorkDirs: %v", err)
+	}
+	if _, err := os.Stat(pruned[0].Path); !os.IsNotExist(err) {
+		t.Error("old-task work directory not removed")
+	}
+	if _, err := os.Stat(filepath.Join(r.BaseDir, ".hydra", "work", "new-task")); err != nil {
+		t.Error("recently used work directory was removed")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1536:                   "1.5 KiB",
+		5 * 1024 * 1024:        "5.0 MiB",
+		3 * 1024 * 1024 * 1024: "3.0 GiB",
+	}
+	for n, want := range tests {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
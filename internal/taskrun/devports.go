@@ -0,0 +1,35 @@
+package taskrun
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DevPort is a port the dev command serves on. hydra review dev prints it
+// so the reviewer knows where to look, and publishes it from the container
+// with executor: docker.
+type DevPort struct {
+	Name string `yaml:"name"` // what the port serves, such as web or api
+	Port int    `yaml:"port"`
+	URL  string `yaml:"url"` // printed instead of http://localhost:<port>; may use ${VAR} from the dev environment
+}
+
+// Address returns where the port can be reached: its URL with variables
+// expanded by lookup, or http://localhost:<port>.
+func (p DevPort) Address(lookup func(string) string) string {
+	if p.URL != "" {
+		return os.Expand(p.URL, lookup)
+	}
+	return "http://localhost:" + strconv.Itoa(p.Port)
+}
+
+// validateDevPorts checks that every dev port is a valid TCP port.
+func (c *Commands) validateDevPorts() error {
+	for i, p := range c.DevPorts {
+		if p.Port < 1 || p.Port > 65535 {
+			return fmt.Errorf("dev_ports: entry %d: port %d is out of range", i+1, p.Port)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package taskrun
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Built-in diff policy checks.
+const (
+	CheckTodo           = "todo"
+	CheckNotImplemented = "not_implemented"
+	CheckSkippedTest    = "skipped_test"
+	CheckNolint         = "nolint"
+)
+
+// diffChecks are the patterns of the built-in checks, matched against each
+// line a session adds.
+var diffChecks = map[string]string{
+	CheckTodo:           `\b(TODO|FIXME|XXX)\b`,
+	CheckNotImplemented: `(?i)panic\(\s*"(not implemented|unimplemented|todo)|NotImplementedError|\b(unimplemented|todo)!\(`,
+	CheckSkippedTest:    `\bt\.Skip(Now|f)?\(|@(pytest\.mark\.skip|unittest\.skip)|\b(it|describe|test)\.skip\(|\bx(it|describe)\(|#\[ignore\]`,
+	CheckNolint:         `//\s*nolint\b|#\s*noqa\b|eslint-disable|@ts-(ignore|nocheck)|pylint:\s*disable|#\s*type:\s*ignore`,
+}
+
+// DiffChecks lists the names of the built-in diff policy checks.
+var DiffChecks = []string{CheckTodo, CheckNotImplemented, CheckSkippedTest, CheckNolint}
+
+// DiffPolicy lists patterns a run's commits must not add, such as TODOs
+// left behind or skipped tests. When a session's diff adds one, hydra runs
+// follow-up sessions with the findings before accepting the commits.
+type DiffPolicy struct {
+	Checks    []string           `yaml:"checks"`     // built-in checks to apply; all of them if empty
+	Forbid    []ForbiddenPattern `yaml:"forbid"`     // extra patterns, by name
+	FollowUps *int               `yaml:"follow_ups"` // follow-up sessions before the run fails; 1 if unset
+}
+
+// ForbiddenPattern is a named regular expression added lines must not match.
+type ForbiddenPattern struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// DiffRule is a compiled diff policy check.
+type DiffRule struct {
+	Name string
+	Re   *regexp.Regexp
+}
+
+// Rules returns the policy's checks, compiled: the built-in ones it
+// selects followed by its own patterns.
+func (p *DiffPolicy) Rules() ([]DiffRule, error) {
+	if p == nil {
+		return nil, nil
+	}
+	checks := p.Checks
+	if len(checks) == 0 {
+		checks = DiffChecks
+	}
+	rules := make([]DiffRule, 0, len(checks)+len(p.Forbid))
+	for _, name := range checks {
+		pattern, ok := diffChecks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown check %q (want one of %s)", name, strings.Join(DiffChecks, ", "))
+		}
+		rules = append(rules, DiffRule{Name: name, Re: regexp.MustCompile(pattern)})
+	}
+	for i, f := range p.Forbid {
+		if strings.TrimSpace(f.Name) == "" {
+			return nil, fmt.Errorf("forbid pattern %d has no name", i+1)
+		}
+		if slices.Contains(DiffChecks, f.Name) {
+			return nil, fmt.Errorf("forbid pattern %q uses the name of a built-in check", f.Name)
+		}
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("forbid pattern %q: %w", f.Name, err)
+		}
+		rules = append(rules, DiffRule{Name: f.Name, Re: re})
+	}
+	return rules, nil
+}
+
+// FollowUpLimit returns how many follow-up sessions may try to clear
+// violations before the run fails.
+func (p *DiffPolicy) FollowUpLimit() int {
+	if p == nil || p.FollowUps == nil {
+		return 1
+	}
+	return *p.FollowUps
+}
+
+// validate checks the policy's checks and patterns.
+func (p *DiffPolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.FollowUps != nil && *p.FollowUps < 0 {
+		return fmt.Errorf("diff_policy: follow_ups must not be negative")
+	}
+	if _, err := p.Rules(); err != nil {
+		return fmt.Errorf("diff_policy: %w", err)
+	}
+	return nil
+}
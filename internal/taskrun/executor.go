@@ -0,0 +1,164 @@
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/erikh/hydra/internal/platform"
+)
+
+// Executors that hydra.yml's executor setting accepts.
+const (
+	// ExecutorHost runs commands directly on the host. It is the default.
+	ExecutorHost = "host"
+	// ExecutorDocker runs each command in a disposable container.
+	ExecutorDocker = "docker"
+)
+
+// containerStopGrace is how long a container command gets to exit after
+// being asked to stop before the docker client is killed.
+const containerStopGrace = 10 * time.Second
+
+// Container configures the containers commands run in with executor: docker.
+type Container struct {
+	Image string   `yaml:"image"` // image to run commands in; it needs sh, and git for Claude to commit
+	Args  []string `yaml:"args"`  // extra docker run arguments, e.g. ["--network", "none"]
+}
+
+// validateExecutor checks the executor and container settings.
+func (c *Commands) validateExecutor() error {
+	switch c.Executor {
+	case "", ExecutorHost:
+		return nil
+	case ExecutorDocker:
+		if c.Container == nil || strings.TrimSpace(c.Container.Image) == "" {
+			return fmt.Errorf("executor %q requires container.image", ExecutorDocker)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown executor %q (want %q or %q)", c.Executor, ExecutorHost, ExecutorDocker)
+	}
+}
+
+// Containerized reports whether commands run in containers.
+func (c *Commands) Containerized() bool {
+	return c != nil && c.Executor == ExecutorDocker
+}
+
+// Command returns a command that runs cmdStr through a shell in workDir.
+// On the host that is $SHELL -c. With executor: docker it is sh -c in a
+// fresh container of the configured image, removed when the command exits.
+// The container sees only workDir, mounted at the same path, and the git
+// directory it belongs to, and runs as the current user so files it writes
+// stay owned by them.
+func (c *Commands) Command(ctx context.Context, workDir, cmdStr string) *exec.Cmd {
+	return c.command(ctx, workDir, cmdStr, nil)
+}
+
+// command is Command with dockerExtra added to the docker run arguments
+// when commands run in containers.
+func (c *Commands) command(ctx context.Context, workDir, cmdStr string, dockerExtra []string) *exec.Cmd {
+	if !c.Containerized() {
+		cmd := exec.CommandContext(ctx, userShell(), "-c", cmdStr) //nolint:gosec // commands from trusted config
+		cmd.Dir = workDir
+		return cmd
+	}
+
+	// Killing the docker client outright would leave the container
+	// running. Elsewhere the client forwards SIGTERM to the container, but
+	// Windows has no SIGTERM to send it, so there the container is named
+	// and stopped with docker stop.
+	var name string
+	if runtime.GOOS == "windows" {
+		name = fmt.Sprintf("hydra-%d-%d", os.Getpid(), containerSeq.Add(1))
+		dockerExtra = append([]string{"--name", name}, dockerExtra...)
+	}
+	cmd := exec.CommandContext(ctx, "docker", c.dockerArgs(workDir, cmdStr, dockerExtra...)...) //nolint:gosec // commands from trusted config
+	cmd.Cancel = func() error {
+		if name != "" {
+			return stopContainer(name)
+		}
+		return platform.Terminate(cmd.Process)
+	}
+	cmd.WaitDelay = containerStopGrace
+	return cmd
+}
+
+// containerSeq numbers the containers this process names.
+var containerSeq atomic.Int64
+
+// stopContainer stops the named container with docker stop, which kills
+// it if it hasn't exited after containerStopGrace.
+func stopContainer(name string) error {
+	grace := strconv.Itoa(int(containerStopGrace.Seconds()))
+	return exec.CommandContext(context.Background(), "docker", "stop", "-t", grace, name).Run() //nolint:gosec // name is generated by hydra
+}
+
+// dockerArgs returns the docker run arguments for running cmdStr in workDir,
+// with extra placed before the configured container arguments.
+func (c *Commands) dockerArgs(workDir, cmdStr string, extra ...string) []string {
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		dir = workDir
+	}
+
+	args := []string{"run", "--rm", "-i", "--init", "-v", dir + ":" + dir, "-w", dir}
+	if gitDir := worktreeGitDir(dir); gitDir != "" {
+		args = append(args, "-v", gitDir+":"+gitDir)
+	}
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		// An arbitrary user has no home directory in the image.
+		args = append(args, "--user", strconv.Itoa(uid)+":"+strconv.Itoa(gid), "-e", "HOME=/tmp")
+	}
+	args = append(args, gitIdentityEnv(dir)...)
+	args = append(args, extra...)
+	args = append(args, c.Container.Args...)
+	return append(args, c.Container.Image, "sh", "-c", cmdStr)
+}
+
+// worktreeGitDir returns the repository's git directory when dir is a git
+// worktree, whose .git file points into it; empty otherwise.
+func worktreeGitDir(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".git")) //nolint:gosec // dir is a trusted work directory
+	if err != nil {
+		return ""
+	}
+	gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(dir, gitdir)
+	}
+	common := gitdir
+	if data, err := os.ReadFile(filepath.Join(gitdir, "commondir")); err == nil { //nolint:gosec // path from git metadata
+		common = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitdir, common)
+		}
+	}
+	return filepath.Clean(common)
+}
+
+// gitIdentityEnv passes the repository's git author and committer identity
+// into the container, which has no git config of its own.
+func gitIdentityEnv(dir string) []string {
+	var env []string
+	for _, kv := range []struct{ key, who string }{{"user.name", "NAME"}, {"user.email", "EMAIL"}} {
+		out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "config", kv.key).Output() //nolint:gosec // fixed git config keys
+		value := strings.TrimSpace(string(out))
+		if err != nil || value == "" {
+			continue
+		}
+		env = append(env, "-e", "GIT_AUTHOR_"+kv.who+"="+value, "-e", "GIT_COMMITTER_"+kv.who+"="+value)
+	}
+	return env
+}
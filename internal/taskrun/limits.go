@@ -0,0 +1,192 @@
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// limitKillGrace is how long a command past its time limit gets to exit
+// after SIGTERM before it is killed.
+const limitKillGrace = 10 * time.Second
+
+// Limits caps the resources of a named command and everything it starts, so
+// a runaway test suite can't wedge the machine during unattended runs.
+type Limits struct {
+	CPU    float64   `yaml:"cpu"`    // CPUs' worth of time, e.g. 2 or 0.5; needs systemd-run
+	Memory string    `yaml:"memory"` // memory limit with a K, M, G, or T suffix, e.g. "4G"
+	Time   *Duration `yaml:"time"`   // wall time after which the command is stopped
+}
+
+// validateLimits checks every command's limits.
+func (c *Commands) validateLimits() error {
+	for name, l := range c.Limits {
+		if l.CPU < 0 {
+			return fmt.Errorf("limits.%s: cpu must be positive", name)
+		}
+		if _, err := parseMemory(l.Memory); err != nil {
+			return fmt.Errorf("limits.%s: %w", name, err)
+		}
+		if l.Time != nil && l.Time.Duration < 0 {
+			return fmt.Errorf("limits.%s: time must be positive", name)
+		}
+	}
+	return nil
+}
+
+// parseMemory parses a memory size like "512M" or "4G" into bytes. An empty
+// string is no limit.
+func parseMemory(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	shift := 0
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	case "T":
+		shift = 40
+	}
+	digits := s
+	if shift > 0 {
+		digits = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(digits), 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64>>shift {
+		return 0, fmt.Errorf("invalid memory %q: want a size like 512M or 4G", s)
+	}
+	return n << shift, nil
+}
+
+// limited returns cmdStr wrapped to run under the limits configured for the
+// named command, or cmdStr unchanged if it has none. On a Linux host with a
+// systemd user session, CPU and memory are enforced with a transient cgroup
+// through systemd-run. Elsewhere, and in containers, memory falls back to
+// ulimit -v and the CPU limit is not applied; container.args can limit the
+// container as a whole instead. The time limit uses timeout, or gtimeout
+// where coreutils is installed with a g prefix, as on macOS. Limits the
+// host can't enforce are dropped with a warning rather than failing the
+// command.
+func (c *Commands) limited(name, cmdStr string) string {
+	l, ok := c.Limits[name]
+	if !ok || strings.TrimSpace(cmdStr) == "" {
+		return cmdStr
+	}
+	shell := userShell()
+	cgroups := false
+	if runtime.GOOS == "windows" && !c.Containerized() && (l.CPU > 0 || l.Memory != "") {
+		warnNoWindowsLimits.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: cpu and memory limits are not supported on Windows; applying only the time limit for %q\n", name)
+		})
+		l.CPU, l.Memory = 0, ""
+	}
+	timeout := "timeout"
+	if c.Containerized() {
+		shell = "sh"
+	} else {
+		if l.CPU > 0 || l.Memory != "" {
+			cgroups = systemdRunAvailable()
+		}
+		timeout = timeoutCommand()
+	}
+	if l.Time != nil && l.Time.Duration > 0 && timeout == "" {
+		warnNoTimeLimit.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: time limits need timeout from coreutils; not applying the time limit for %q\n", name)
+		})
+		l.Time = nil
+	}
+	if runtime.GOOS == "darwin" && !c.Containerized() && l.Memory != "" {
+		// macOS's shells refuse ulimit -v, which would fail the command.
+		warnNoMemoryLimit.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: memory limits are not supported on macOS; not applying the memory limit for %q\n", name)
+		})
+		l.Memory = ""
+	}
+	if l.CPU > 0 && !cgroups {
+		warnNoCPULimit.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: cpu limits need systemd-run with a user session; not applying the cpu limit for %q\n", name)
+		})
+	}
+	return l.wrap(cmdStr, shell, timeout, cgroups)
+}
+
+// warnNoCPULimit warns, once, that cpu limits can't be applied.
+var warnNoCPULimit sync.Once
+
+// warnNoWindowsLimits warns, once, that cpu and memory limits can't be
+// applied on Windows.
+var warnNoWindowsLimits sync.Once
+
+// warnNoTimeLimit warns, once, that time limits can't be applied.
+var warnNoTimeLimit sync.Once
+
+// warnNoMemoryLimit warns, once, that memory limits can't be applied.
+var warnNoMemoryLimit sync.Once
+
+// timeoutCommand returns the coreutils timeout on PATH, as timeout or as
+// Homebrew's gtimeout, or "" if there is none.
+var timeoutCommand = sync.OnceValue(func() string {
+	for _, name := range []string{"timeout", "gtimeout"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+})
+
+// wrap returns a shell command that runs cmdStr with shell -c under the
+// limits, through systemd-run if cgroups is true and with the time limit
+// enforced by the timeout command.
+func (l Limits) wrap(cmdStr, shell, timeout string, cgroups bool) string {
+	wrapped := shell + " -c " + shellQuote(cmdStr)
+	if l.Time != nil && l.Time.Duration > 0 {
+		wrapped = fmt.Sprintf("%s -k %s %s %s", timeout, durationSeconds(limitKillGrace), durationSeconds(l.Time.Duration), wrapped)
+	}
+
+	memory, _ := parseMemory(l.Memory) // checked by Load
+	if cgroups && (memory > 0 || l.CPU > 0) {
+		args := []string{"systemd-run", "--user", "--scope", "--quiet", "--collect"}
+		if memory > 0 {
+			args = append(args, "-p", "MemoryMax="+strconv.FormatInt(memory, 10))
+		}
+		if l.CPU > 0 {
+			args = append(args, "-p", fmt.Sprintf("CPUQuota=%d%%", int(math.Round(l.CPU*100))))
+		}
+		return strings.Join(args, " ") + " -- " + wrapped
+	}
+	if memory > 0 {
+		return fmt.Sprintf("ulimit -v %d && exec %s", memory>>10, wrapped)
+	}
+	return wrapped
+}
+
+// durationSeconds formats d as whole seconds, rounded up, for timeout.
+func durationSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10) + "s"
+}
+
+// systemdRunAvailable reports whether systemd-run can start transient
+// scopes in the user's systemd session, which cgroup limits need.
+var systemdRunAvailable = sync.OnceValue(func() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "systemd-run", "--user", "--scope", "--quiet", "--collect", "true").Run() == nil
+})
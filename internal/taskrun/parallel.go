@@ -0,0 +1,83 @@
+package taskrun
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// Result is the outcome of one command run by OutputAll.
+type Result struct {
+	Name   string
+	Output string // combined stdout and stderr, without prefixes
+	Err    error
+}
+
+// OutputAll runs the named commands in workDir like Output and returns their
+// results in the order given. Unless serial_checks is set, the commands run
+// concurrently, and each line they print is prefixed with the command's name,
+// e.g. "[lint] ", so their interleaved output stays readable. A sink gets
+// each command's output unprefixed. Names that aren't
+// configured are skipped, like in Output, and yield an empty result.
+func (c *Commands) OutputAll(names []string, workDir string) []Result {
+	results := make([]Result, len(names))
+	if c.SerialChecks || len(names) < 2 {
+		for i, name := range names {
+			out, err := c.Output(name, workDir)
+			results[i] = Result{Name: name, Output: out, Err: err}
+		}
+		return results
+	}
+
+	var mu sync.Mutex // serializes writes to stdout across commands
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			pw := &prefixWriter{w: os.Stdout, mu: &mu, prefix: "[" + name + "] "}
+			out, err := c.output(name, workDir, pw)
+			pw.flush()
+			results[i] = Result{Name: name, Output: out, Err: err}
+		})
+	}
+	wg.Wait()
+	return results
+}
+
+// prefixWriter writes whole lines to w, each starting with prefix, holding
+// back a partial line until it is completed or flushed.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+// Write implements io.Writer.
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// flush writes any partial last line.
+func (p *prefixWriter) flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+// writeLine writes one prefixed line.
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.w, p.prefix)
+	_, _ = p.w.Write(line)
+}
@@ -0,0 +1,35 @@
+package taskrun
+
+import "io"
+
+// Sink takes the output of the commands hydra runs in place of stdout and
+// stderr, e.g. the TUI while a session has the terminal.
+type Sink interface {
+	// Begin announces that the named command, cmdStr, is starting. stdout
+	// and stderr are where its output would go without the sink. It returns
+	// the writers for the command's output and a function to call with the
+	// command's error, if any, once it exits.
+	Begin(name, cmdStr string, stdout, stderr io.Writer) (io.Writer, io.Writer, func(error))
+}
+
+// WithSink returns a copy of c whose commands send their output to s
+// instead of hydra's stdout and stderr. c itself is unchanged, so copies
+// with different sinks can be used at the same time.
+func (c *Commands) WithSink(s Sink) *Commands {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	cp.sink = s
+	return &cp
+}
+
+// streams returns where the named command's stdout and stderr go, and a
+// function to call with its error once it exits: whatever the sink makes of
+// stdout and stderr when one is set, otherwise stdout and stderr.
+func (c *Commands) streams(name, cmdStr string, stdout, stderr io.Writer) (io.Writer, io.Writer, func(error)) {
+	if c.sink == nil {
+		return stdout, stderr, func(error) {}
+	}
+	return c.sink.Begin(name, cmdStr, stdout, stderr)
+}
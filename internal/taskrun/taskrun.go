@@ -2,16 +2,26 @@
 package taskrun
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/erikh/hydra/internal/claude"
+	"github.com/erikh/hydra/internal/notify"
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.yaml.in/yaml/v4"
 )
 
@@ -20,29 +30,171 @@ type Duration struct {
 	time.Duration
 }
 
-// UnmarshalYAML parses a Go duration string like "30m" or "2h".
+// UnmarshalYAML parses a duration as ParseDuration does.
 func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
 	var s string
 	if err := node.Decode(&s); err != nil {
 		return err
 	}
-	parsed, err := time.ParseDuration(s)
+	parsed, err := ParseDuration(s)
 	if err != nil {
-		return fmt.Errorf("invalid duration %q: %w", s, err)
+		return err
 	}
 	d.Duration = parsed
 	return nil
 }
 
+// ParseDuration parses a Go duration string like "30m" or "2h", or a whole
+// number of days like "30d". Negative durations are rejected.
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q: want whole days like 30d", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", s)
+	}
+	return d, nil
+}
+
 // Commands holds the named commands loaded from hydra.yml.
 type Commands struct {
-	Model    string            `yaml:"model"`
-	APIType  string            `yaml:"api_type"`
-	GiteaURL string            `yaml:"gitea_url"`
-	Timeout  *Duration         `yaml:"timeout"`
-	Notify   string            `yaml:"notify"`
-	Teardown string            `yaml:"teardown"`
-	Commands map[string]string `yaml:"commands"`
+	Model          string            `yaml:"model"`
+	ModelFallbacks []string          `yaml:"model_fallbacks"` // models to switch to, in order, when the model stays overloaded
+	APIType        string            `yaml:"api_type"`
+	GiteaURL       string            `yaml:"gitea_url"`
+	PushRemote     string            `yaml:"push_remote"`    // fork URL that hydra/* branches are pushed to
+	DefaultBranch  string            `yaml:"default_branch"` // branch tasks start from and merge into; detected from origin if empty
+	Timeout        *Duration         `yaml:"timeout"`
+	WrapUp         *Duration         `yaml:"wrap_up"`     // how long before the timeout Claude is told to commit and stop
+	StaleAfter     *Duration         `yaml:"stale_after"` // how long a pending or review task can go unchanged before it is flagged stale
+	Notify         string            `yaml:"notify"`
+	Teardown       string            `yaml:"teardown"`
+	Commands       map[string]string `yaml:"commands"`
+	Limits         map[string]Limits `yaml:"limits"`    // resource limits by command name
+	DevPorts       []DevPort         `yaml:"dev_ports"` // ports the dev command serves on, printed by hydra review dev
+
+	CleanAfterMerge  bool `yaml:"clean_after_merge"`  // run the clean command once a task is merged
+	RemoveAfterMerge bool `yaml:"remove_after_merge"` // delete the work directory once a task is merged
+
+	WorkDir string `yaml:"work_dir"` // where work directories live; .hydra/work if empty
+
+	DesignAutoCommit bool   `yaml:"design_autocommit"` // commit every design directory change hydra makes
+	DesignAutoPush   bool   `yaml:"design_autopush"`   // push those commits to the design repo's upstream
+	DesignRemote     string `yaml:"design_remote"`     // remote hydra design push and pull sync with
+
+	ApproveBeforePush bool `yaml:"approve_before_push"` // wait for a human to approve a run's commits before pushing them
+
+	PlanHandoff bool `yaml:"plan_handoff"` // plan first and wait for hydra plan approve before executing the plan
+
+	CostPerMTok float64 `yaml:"cost_per_mtok"` // USD per million tokens, for the cost range of run estimates
+
+	Approval []claude.ApprovalRule `yaml:"approval"` // rules deciding tool approvals ahead of auto-accept, first match wins
+
+	DiffPolicy *DiffPolicy `yaml:"diff_policy"` // patterns a run's commits must not add, fixed by follow-up sessions
+
+	SerialChecks bool `yaml:"serial_checks"` // run test and lint one after the other, for suites that interfere
+
+	Language  string `yaml:"language"`  // language code of the boilerplate in workflow documents, e.g. de; English if empty
+	Templates string `yaml:"templates"` // directory of section templates, relative to the design dir; templates if empty
+
+	Verify *Verify `yaml:"verify"` // pre-merge verification; nil leaves verification to Claude
+
+	VCS string `yaml:"vcs"` // backend for work directories: git (the default) or jj
+
+	Executor  string     `yaml:"executor"`  // where commands run: host (the default) or docker
+	Container *Container `yaml:"container"` // container settings for executor: docker
+
+	Sync *Sync `yaml:"sync"` // how hydra sync maps issues to tasks
+
+	Notifications *Notifications `yaml:"notifications"` // channels hydra notify sends to besides the desktop
+
+	sink Sink // takes command output in place of stdout and stderr; set by WithSink
+
+	ctx context.Context //nolint:containedctx // carries the trace parent for commands; set by WithContext
+}
+
+// Notifications configures the Slack and webhook channels of hydra notify,
+// and which channels a notification goes to when none is given.
+type Notifications struct {
+	Slack    string   `yaml:"slack"`    // Slack incoming webhook URL
+	Webhook  string   `yaml:"webhook"`  // URL that receives a JSON POST of title and message
+	Channels []string `yaml:"channels"` // default channels; every configured one if empty
+	// Heartbeat is how often batch runs send a progress notification to the
+	// default channels. Batches shorter than it send none.
+	Heartbeat *Duration `yaml:"heartbeat"`
+}
+
+// Configured returns the channels that can be sent to: desktop always, and
+// slack and webhook when their URLs are set.
+func (n *Notifications) Configured() []string {
+	channels := []string{notify.ChannelDesktop}
+	if n == nil {
+		return channels
+	}
+	if n.Slack != "" {
+		channels = append(channels, notify.ChannelSlack)
+	}
+	if n.Webhook != "" {
+		channels = append(channels, notify.ChannelWebhook)
+	}
+	return channels
+}
+
+// Default returns the channels a notification goes to when none is given.
+func (n *Notifications) Default() []string {
+	if n != nil && len(n.Channels) > 0 {
+		return n.Channels
+	}
+	return n.Configured()
+}
+
+// validate checks that the default channels exist and are configured.
+func (n *Notifications) validate() error {
+	if n == nil {
+		return nil
+	}
+	if n.Heartbeat != nil && n.Heartbeat.Duration <= 0 {
+		return errors.New("notifications heartbeat must be positive")
+	}
+	configured := n.Configured()
+	for _, ch := range n.Channels {
+		if !slices.Contains(notify.Channels, ch) {
+			return fmt.Errorf("unknown notification channel %q", ch)
+		}
+		if !slices.Contains(configured, ch) {
+			return fmt.Errorf("notification channel %q has no URL configured", ch)
+		}
+	}
+	return nil
+}
+
+// Sync configures how hydra sync imports issues.
+type Sync struct {
+	Rules      []SyncRule `yaml:"rules"`      // label rules; the first matching one applies
+	Milestones bool       `yaml:"milestones"` // import forge milestones as hydra milestones
+}
+
+// SyncRule maps synced issues with a label to a task group or priority, or
+// skips them.
+type SyncRule struct {
+	Label    string `yaml:"label"`
+	Group    string `yaml:"group"`    // task group to import into instead of issues
+	Priority string `yaml:"priority"` // priority front matter for the task
+	Skip     bool   `yaml:"skip"`     // don't import matching issues
+}
+
+// Verify configures the test and lint run hydra does itself after rebasing
+// a task for merge, before deciding whether a Claude session is needed.
+type Verify struct {
+	FlakyRetries int `yaml:"flaky_retries"` // reruns of a failing test command before it counts as broken
 }
 
 // Load reads and parses a hydra.yml file.
@@ -60,10 +212,87 @@ func Load(path string) (*Commands, error) {
 	if cmds.Commands == nil {
 		cmds.Commands = make(map[string]string)
 	}
+	if err := cmds.validateExecutor(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if err := cmds.validateLimits(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if err := cmds.validateDevPorts(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if err := claude.ValidateApprovalRules(cmds.Approval); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if err := cmds.DiffPolicy.validate(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	switch cmds.VCS {
+	case "", "git", "jj":
+	default:
+		return nil, fmt.Errorf("parsing taskrun config: unknown vcs %q (want \"git\" or \"jj\")", cmds.VCS)
+	}
+	if err := cmds.Sync.validate(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if err := cmds.Notifications.validate(); err != nil {
+		return nil, fmt.Errorf("parsing taskrun config: %w", err)
+	}
+	if strings.ContainsAny(cmds.Language, `/\.`) {
+		return nil, fmt.Errorf("parsing taskrun config: language %q must be a language code such as de", cmds.Language)
+	}
+	if cmds.CostPerMTok < 0 {
+		return nil, errors.New("parsing taskrun config: cost_per_mtok must not be negative")
+	}
 
 	return &cmds, nil
 }
 
+// validate checks the sync rules.
+func (s *Sync) validate() error {
+	if s == nil {
+		return nil
+	}
+	for i, r := range s.Rules {
+		if strings.TrimSpace(r.Label) == "" {
+			return fmt.Errorf("sync rule %d has no label", i+1)
+		}
+		if strings.Contains(r.Group, "/") {
+			return fmt.Errorf("sync rule for label %q: group %q must not contain '/'", r.Label, r.Group)
+		}
+	}
+	return nil
+}
+
+// Overlay returns a copy of c with the model, model fallbacks, approval
+// rules, dev ports, and commands from a group's hydra.yml layered on top. A group can
+// also turn on plan_handoff for its tasks. Commands not set by the group,
+// and all other settings, come from c.
+func (c *Commands) Overlay(group *Commands) *Commands {
+	merged := *c
+	merged.Commands = maps.Clone(c.Commands)
+	if merged.Commands == nil {
+		merged.Commands = make(map[string]string)
+	}
+	maps.Copy(merged.Commands, group.Commands)
+	if group.Model != "" {
+		merged.Model = group.Model
+	}
+	if len(group.ModelFallbacks) > 0 {
+		merged.ModelFallbacks = group.ModelFallbacks
+	}
+	if len(group.Approval) > 0 {
+		merged.Approval = group.Approval
+	}
+	if len(group.DevPorts) > 0 {
+		merged.DevPorts = group.DevPorts
+	}
+	if group.PlanHandoff {
+		merged.PlanHandoff = true
+	}
+	return &merged
+}
+
 // hasMakeTarget checks if a Makefile exists in workDir and contains the given target.
 func hasMakeTarget(workDir, target string) bool {
 	makefile := filepath.Join(workDir, "Makefile")
@@ -80,32 +309,39 @@ func hasMakeTarget(workDir, target string) bool {
 	return false
 }
 
-// resolveCommand returns the command string for the given name.
-// It checks hydra.yml first, then falls back to "make <name>" if a Makefile
-// with that target exists in the work directory.
+// resolveCommand returns the command string for the given name, wrapped in
+// its limits if it has any. It checks hydra.yml first, then falls back to
+// "make <name>" if a Makefile with that target exists in the work directory.
 func (c *Commands) resolveCommand(name, workDir string) (string, bool) {
 	if cmdStr, ok := c.Commands[name]; ok {
-		return cmdStr, true
+		return c.limited(name, cmdStr), true
 	}
 	if hasMakeTarget(workDir, name) {
-		return "make " + name, true
+		return c.limited(name, "make "+name), true
 	}
 	return "", false
 }
 
-// userShell returns the user's shell from $SHELL, defaulting to /bin/sh.
+// userShell returns the user's shell from $SHELL, defaulting to /bin/sh, or
+// on Windows to the sh found on PATH, such as Git for Windows' sh.exe, since
+// hydra.yml commands are written for a POSIX shell.
 func userShell() string {
 	if sh := os.Getenv("SHELL"); sh != "" {
 		return sh
 	}
+	if runtime.GOOS == "windows" {
+		return "sh"
+	}
 	return "/bin/sh"
 }
 
-// RunDev executes the named "dev" command in the given working directory.
-// The command runs until it exits or the context is cancelled.
+// RunDev executes the named "dev" command in the given working directory,
+// with env added to its environment. The command runs until it exits or the
+// context is cancelled. With executor: docker, env is passed into the
+// container and the dev ports are published on the host.
 // Falls back to "make dev" if no dev command is configured but a Makefile
 // with a dev target exists. Returns an error if neither is available.
-func (c *Commands) RunDev(ctx context.Context, workDir string) error {
+func (c *Commands) RunDev(ctx context.Context, workDir string, env []string) error {
 	cmdStr, ok := c.resolveCommand("dev", workDir)
 	if !ok {
 		return errors.New("no dev command configured in hydra.yml and no dev target in Makefile")
@@ -115,13 +351,23 @@ func (c *Commands) RunDev(ctx context.Context, workDir string) error {
 		return errors.New("dev command is empty in hydra.yml")
 	}
 
-	cmd := exec.CommandContext(ctx, userShell(), "-c", cmdStr) //nolint:gosec // commands from trusted config
-	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var extra []string
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		extra = append(extra, "-e", name)
+	}
+	for _, p := range c.DevPorts {
+		extra = append(extra, "-p", strconv.Itoa(p.Port)+":"+strconv.Itoa(p.Port))
+	}
+	cmd := c.command(ctx, workDir, cmdStr, extra)
+	cmd.Env = append(os.Environ(), env...)
+	var end func(error)
+	cmd.Stdout, cmd.Stderr, end = c.streams("dev", cmdStr, os.Stdout, os.Stderr)
 	cmd.Stdin = os.Stdin
 
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	end(err)
+	if err != nil {
 		return fmt.Errorf("dev command failed: %w", err)
 	}
 
@@ -131,7 +377,8 @@ func (c *Commands) RunDev(ctx context.Context, workDir string) error {
 // EffectiveCommands returns the commands map including Makefile fallbacks.
 // For each standard command name (clean, dev, test, lint) not configured in
 // hydra.yml, if a matching Makefile target exists in workDir, it is included
-// as "make <name>".
+// as "make <name>". Commands with limits are wrapped in them, so Claude runs
+// them limited too.
 func (c *Commands) EffectiveCommands(workDir string) map[string]string {
 	result := make(map[string]string)
 	maps.Copy(result, c.Commands)
@@ -142,6 +389,9 @@ func (c *Commands) EffectiveCommands(workDir string) map[string]string {
 			}
 		}
 	}
+	for name, cmdStr := range result {
+		result[name] = c.limited(name, cmdStr)
+	}
 	return result
 }
 
@@ -160,15 +410,52 @@ func (c *Commands) RunNotify(title, message string) (bool, error) {
 	}
 
 	cmd := exec.CommandContext(context.Background(), userShell(), "-c", c.Notify+" "+shellQuote(title)+" "+shellQuote(message)) //nolint:gosec // commands from trusted config
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var end func(error)
+	cmd.Stdout, cmd.Stderr, end = c.streams("notify", c.Notify, os.Stdout, os.Stderr)
 
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	end(err)
+	if err != nil {
 		return true, fmt.Errorf("notify command failed: %w", err)
 	}
 	return true, nil
 }
 
+// Hook command names. Each is an optional key under commands, run by hydra
+// after the matching step.
+const (
+	HookAfterRun    = "after_run"    // a run moved its task to review
+	HookAfterReview = "after_review" // a review session finished
+	HookAfterMerge  = "after_merge"  // a task was merged
+	HookOnFailure   = "on_failure"   // a run, review, test, or merge failed
+)
+
+// RunHook executes the named hook command in workDir with env added to its
+// environment. Like notify, hooks always run on the host, even with
+// executor: docker, since they usually talk to outside services. workDir is
+// skipped if it doesn't exist. Returns false if the hook isn't configured.
+func (c *Commands) RunHook(name, workDir string, env []string) (bool, error) {
+	cmdStr := c.Commands[name]
+	if strings.TrimSpace(cmdStr) == "" {
+		return false, nil
+	}
+
+	cmd := exec.CommandContext(context.Background(), userShell(), "-c", cmdStr) //nolint:gosec // commands from trusted config
+	if info, err := os.Stat(workDir); err == nil && info.IsDir() {
+		cmd.Dir = workDir
+	}
+	cmd.Env = append(os.Environ(), env...)
+	var end func(error)
+	cmd.Stdout, cmd.Stderr, end = c.streams(name, cmdStr, os.Stdout, os.Stderr)
+
+	err := cmd.Run()
+	end(err)
+	if err != nil {
+		return true, fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return true, nil
+}
+
 // shellQuote wraps a string in single quotes for safe shell usage.
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
@@ -181,23 +468,81 @@ func (c *Commands) RunTeardown(workDir string) error {
 		return nil
 	}
 
-	cmd := exec.CommandContext(context.Background(), userShell(), "-c", c.Teardown) //nolint:gosec // commands from trusted config
-	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := c.Command(context.Background(), workDir, c.Teardown)
+	var end func(error)
+	cmd.Stdout, cmd.Stderr, end = c.streams("teardown", c.Teardown, os.Stdout, os.Stderr)
 
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	end(err)
+	if err != nil {
 		return fmt.Errorf("teardown command failed: %w", err)
 	}
 	return nil
 }
 
+// WithContext returns a copy of c whose named commands are traced as
+// children of the span in ctx, e.g. so the test and lint runs of a merge
+// show up in its trace.
+func (c *Commands) WithContext(ctx context.Context) *Commands {
+	if c == nil {
+		return nil
+	}
+	cp := *c
+	cp.ctx = ctx
+	return &cp
+}
+
+// traceCommand starts the span of the named command, if c carries one to
+// nest it under.
+func (c *Commands) traceCommand(name, cmdStr string) (context.Context, trace.Span) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracing.StartChild(ctx, "command "+name, attribute.String("hydra.command", cmdStr))
+}
+
+// Output executes the named command like Run, additionally returning its
+// combined stdout and stderr. Output is still shown as the command runs.
+func (c *Commands) Output(name, workDir string) (string, error) {
+	return c.output(name, workDir, nil)
+}
+
+// output implements Output, showing the command's output on w, or on
+// stdout if w is nil, unless the sink takes it.
+func (c *Commands) output(name, workDir string, w io.Writer) (_ string, err error) {
+	cmdStr, ok := c.resolveCommand(name, workDir)
+	if !ok || strings.TrimSpace(cmdStr) == "" {
+		return "", nil
+	}
+	ctx, span := c.traceCommand(name, cmdStr)
+	defer func() { tracing.End(span, err) }()
+
+	if w == nil {
+		w = os.Stdout
+	}
+	w, _, end := c.streams(name, cmdStr, w, w)
+	var buf bytes.Buffer
+	cmd := c.Command(ctx, workDir, cmdStr)
+	// A single writer for both streams keeps their output in order.
+	out := io.MultiWriter(w, &buf)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err = cmd.Run()
+	end(err)
+	if err != nil {
+		return buf.String(), fmt.Errorf("command %q failed: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
 // Run executes the named command in the given working directory.
-// The command is run via $SHELL -c, so shell features like pipes and
-// variable expansion work. Falls back to "make <name>" if the command
+// The command is run via $SHELL -c, or sh -c in a container with
+// executor: docker, so shell features like pipes and variable expansion work. Falls back to "make <name>" if the command
 // is not configured in hydra.yml but a Makefile with that target exists.
 // Returns nil if neither is available.
-func (c *Commands) Run(name, workDir string) error {
+func (c *Commands) Run(name, workDir string) (err error) {
 	cmdStr, ok := c.resolveCommand(name, workDir)
 	if !ok {
 		return nil
@@ -206,13 +551,16 @@ func (c *Commands) Run(name, workDir string) error {
 	if strings.TrimSpace(cmdStr) == "" {
 		return nil
 	}
+	ctx, span := c.traceCommand(name, cmdStr)
+	defer func() { tracing.End(span, err) }()
 
-	cmd := exec.CommandContext(context.Background(), userShell(), "-c", cmdStr) //nolint:gosec // commands from trusted config
-	cmd.Dir = workDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := c.Command(ctx, workDir, cmdStr)
+	var end func(error)
+	cmd.Stdout, cmd.Stderr, end = c.streams(name, cmdStr, os.Stdout, os.Stderr)
 
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	end(err)
+	if err != nil {
 		return fmt.Errorf("command %q failed: %w", name, err)
 	}
 
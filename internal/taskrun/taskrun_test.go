@@ -1,10 +1,21 @@
 package taskrun
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/erikh/hydra/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestLoadValid(t *testing.T) {
@@ -29,6 +40,201 @@ func TestLoadValid(t *testing.T) {
 	}
 }
 
+func TestLoadPushRemote(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "push_remote: git@github.com:me/fork.git\ncommands:\n  test: \"echo test\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cmds.PushRemote != "git@github.com:me/fork.git" {
+		t.Errorf("PushRemote = %q", cmds.PushRemote)
+	}
+}
+
+func TestLoadVCS(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	if err := os.WriteFile(path, []byte("vcs: jj\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cmds.VCS != "jj" {
+		t.Errorf("VCS = %q, want jj", cmds.VCS)
+	}
+
+	if err := os.WriteFile(path, []byte("vcs: hg\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for unknown vcs")
+	}
+}
+
+func TestLoadLanguage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	if err := os.WriteFile(path, []byte("language: de\ntemplates: prompts\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cmds.Language != "de" || cmds.Templates != "prompts" {
+		t.Errorf("Language, Templates = %q, %q, want de, prompts", cmds.Language, cmds.Templates)
+	}
+
+	if err := os.WriteFile(path, []byte("language: ../de\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for a language that is a path")
+	}
+}
+
+func TestLoadModelFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "model: claude-opus-4-5\nmodel_fallbacks: [claude-sonnet-4-5, claude-haiku-4-5]\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if len(cmds.ModelFallbacks) != 2 || cmds.ModelFallbacks[0] != "claude-sonnet-4-5" || cmds.ModelFallbacks[1] != "claude-haiku-4-5" {
+		t.Errorf("ModelFallbacks = %v", cmds.ModelFallbacks)
+	}
+}
+
+func TestLoadApproval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "approval:\n  - tools: [bash]\n    command: 'git\\s+push'\n    action: prompt\n  - path: src/\n    action: allow\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cmds.Approval) != 2 || cmds.Approval[0].Command != `git\s+push` || cmds.Approval[1].Path != "src/" {
+		t.Errorf("Approval = %+v", cmds.Approval)
+	}
+
+	if err := os.WriteFile(path, []byte("approval:\n  - tools: [read]\n    action: allow\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for a rule naming the read tool")
+	}
+}
+
+func TestLoadDiffPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "diff_policy:\n  checks: [todo, nolint]\n  forbid:\n    - name: debug print\n      pattern: 'fmt\\.Println\\(\"DEBUG'\n  follow_ups: 2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	rules, err := cmds.DiffPolicy.Rules()
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	if !slices.Equal(names, []string{CheckTodo, CheckNolint, "debug print"}) {
+		t.Errorf("rule names = %v", names)
+	}
+	if got := cmds.DiffPolicy.FollowUpLimit(); got != 2 {
+		t.Errorf("FollowUpLimit() = %d, want 2", got)
+	}
+
+	for _, bad := range []string{
+		"diff_policy:\n  checks: [typos]\n",
+		"diff_policy:\n  forbid:\n    - name: x\n      pattern: '('\n",
+		"diff_policy:\n  forbid:\n    - name: todo\n      pattern: 'x'\n",
+		"diff_policy:\n  follow_ups: -1\n",
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestDiffPolicyDefaults(t *testing.T) {
+	p := &DiffPolicy{}
+	rules, err := p.Rules()
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	if len(rules) != len(DiffChecks) {
+		t.Errorf("got %d rules, want every built-in check", len(rules))
+	}
+	if got := p.FollowUpLimit(); got != 1 {
+		t.Errorf("FollowUpLimit() = %d, want 1", got)
+	}
+
+	tests := []struct {
+		check string
+		line  string
+		want  bool
+	}{
+		{CheckTodo, "// TODO: handle errors", true},
+		{CheckTodo, "todoList := nil", false},
+		{CheckNotImplemented, `panic("not implemented")`, true},
+		{CheckNotImplemented, "raise NotImplementedError", true},
+		{CheckNotImplemented, `panic("unreachable")`, false},
+		{CheckSkippedTest, `t.Skip("flaky")`, true},
+		{CheckSkippedTest, "it.skip('works', () => {})", true},
+		{CheckSkippedTest, "@pytest.mark.skip", true},
+		{CheckSkippedTest, "if testing.Short() {", false},
+		{CheckNolint, "x := y //nolint:errcheck", true},
+		{CheckNolint, "import os  # noqa", true},
+		{CheckNolint, "// eslint-disable-next-line", true},
+		{CheckNolint, "lint := true", false},
+	}
+	byName := make(map[string]DiffRule)
+	for _, r := range rules {
+		byName[r.Name] = r
+	}
+	for _, tt := range tests {
+		if got := byName[tt.check].Re.MatchString(tt.line); got != tt.want {
+			t.Errorf("%s on %q = %v, want %v", tt.check, tt.line, got, tt.want)
+		}
+	}
+}
+
 func TestLoadMissing(t *testing.T) {
 	_, err := Load("/nonexistent/hydra.yml")
 	if err == nil {
@@ -110,6 +316,31 @@ func TestLoadTimeoutInvalid(t *testing.T) {
 	}
 }
 
+func TestLoadStaleAfterDays(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "stale_after: 30d\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cmds.StaleAfter == nil || cmds.StaleAfter.Duration != 30*24*time.Hour {
+		t.Errorf("stale_after = %v, want 720h", cmds.StaleAfter)
+	}
+
+	if err := os.WriteFile(path, []byte("stale_after: xd\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for invalid stale_after days")
+	}
+}
+
 func TestRunSuccess(t *testing.T) {
 	dir := t.TempDir()
 	cmds := &Commands{
@@ -137,6 +368,188 @@ func TestRunFailure(t *testing.T) {
 	}
 }
 
+func TestOutput(t *testing.T) {
+	dir := t.TempDir()
+	cmds := &Commands{
+		Commands: map[string]string{
+			"test": "echo ok; echo bad >&2; exit 1",
+		},
+	}
+
+	out, err := cmds.Output("test", dir)
+	if err == nil {
+		t.Fatal("expected error for failing command")
+	}
+	if out != "ok\nbad\n" {
+		t.Errorf("output = %q, want %q", out, "ok\nbad\n")
+	}
+
+	if out, err := cmds.Output("nonexistent", dir); err != nil || out != "" {
+		t.Errorf("Output undefined = %q, %v; want empty, nil", out, err)
+	}
+}
+
+func TestOutputAll(t *testing.T) {
+	dir := t.TempDir()
+	for _, serial := range []bool{false, true} {
+		cmds := &Commands{
+			SerialChecks: serial,
+			Commands: map[string]string{
+				"test": "echo ok; exit 1",
+				"lint": "printf clean",
+			},
+		}
+
+		results := cmds.OutputAll([]string{"lint", "test", "nonexistent"}, dir)
+		if len(results) != 3 {
+			t.Fatalf("serial %v: got %d results, want 3", serial, len(results))
+		}
+		if r := results[0]; r.Name != "lint" || r.Output != "clean" || r.Err != nil {
+			t.Errorf("serial %v: lint result = %+v", serial, r)
+		}
+		if r := results[1]; r.Name != "test" || r.Output != "ok\n" || r.Err == nil {
+			t.Errorf("serial %v: test result = %+v", serial, r)
+		}
+		if r := results[2]; r.Output != "" || r.Err != nil {
+			t.Errorf("serial %v: unconfigured result = %+v", serial, r)
+		}
+	}
+}
+
+func TestCommandSpans(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	dir := t.TempDir()
+	base := &Commands{
+		Commands: map[string]string{
+			"test": "exit 1",
+			"lint": "true",
+		},
+	}
+
+	// Without a traced context the commands make no stray root spans.
+	base.OutputAll([]string{"lint", "test"}, dir)
+	if n := len(rec.Ended()); n != 0 {
+		t.Fatalf("got %d spans without a parent, want 0", n)
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "merge")
+	cmds := base.WithContext(ctx)
+	if base.ctx != nil {
+		t.Fatal("WithContext changed the original commands")
+	}
+	cmds.OutputAll([]string{"lint", "test"}, dir)
+	tracing.End(parent, nil)
+
+	failed := map[string]bool{}
+	for _, span := range rec.Ended() {
+		if span.Name() == "merge" {
+			continue
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s should be a child of merge", span.Name())
+		}
+		failed[span.Name()] = span.Status().Code == codes.Error
+	}
+	if len(failed) != 2 || failed["command lint"] || !failed["command test"] {
+		t.Errorf("spans = %v, want command lint passing and command test failing", failed)
+	}
+}
+
+// recordSink is a Sink that keeps each command's output and error.
+type recordSink struct {
+	mu     sync.Mutex
+	output map[string]*strings.Builder
+	errs   map[string]error
+}
+
+func (s *recordSink) Begin(name, _ string, _, _ io.Writer) (io.Writer, io.Writer, func(error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := &strings.Builder{}
+	s.output[name] = b
+	return b, b, func(err error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.errs[name] = err
+	}
+}
+
+func TestSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := &recordSink{output: map[string]*strings.Builder{}, errs: map[string]error{}}
+	base := &Commands{
+		Commands: map[string]string{
+			"test":       "echo ok; exit 1",
+			"lint":       "printf clean >&2",
+			"after_run":  "echo hook",
+			"lint_fix":   "echo fixed",
+			"not_called": "echo never",
+		},
+	}
+	cmds := base.WithSink(sink)
+	if base.sink != nil {
+		t.Fatal("WithSink changed the original commands")
+	}
+
+	results := cmds.OutputAll([]string{"lint", "test"}, dir)
+	if results[0].Output != "clean" || results[1].Output != "ok\n" {
+		t.Errorf("results = %+v", results)
+	}
+	if err := cmds.Run("lint_fix", dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmds.RunHook("after_run", dir, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"lint": "clean", "test": "ok\n", "lint_fix": "fixed\n", "after_run": "hook\n"} {
+		if b, ok := sink.output[name]; !ok || b.String() != want {
+			t.Errorf("sink output for %s = %v, want %q", name, b, want)
+		}
+	}
+	if sink.errs["test"] == nil || sink.errs["lint"] != nil {
+		t.Errorf("sink errors = %v, want only test to fail", sink.errs)
+	}
+	if _, ok := sink.output["not_called"]; ok {
+		t.Error("sink got output for a command that never ran")
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var b strings.Builder
+	pw := &prefixWriter{w: &b, mu: &sync.Mutex{}, prefix: "[test] "}
+	_, _ = pw.Write([]byte("one\ntw"))
+	_, _ = pw.Write([]byte("o\nthree"))
+	pw.flush()
+
+	want := "[test] one\n[test] two\n[test] three\n"
+	if b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+}
+
+func TestLoadVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	content := "verify:\n  flaky_retries: 2\ncommands:\n  lint_fix: \"gofmt -w .\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cmds.Verify == nil || cmds.Verify.FlakyRetries != 2 {
+		t.Errorf("Verify = %+v, want flaky_retries 2", cmds.Verify)
+	}
+}
+
 func TestRunUndefined(t *testing.T) {
 	dir := t.TempDir()
 	cmds := &Commands{
@@ -167,3 +580,330 @@ func TestRunWithArgs(t *testing.T) {
 		t.Errorf("output file not created: %v", err)
 	}
 }
+
+func TestOverlay(t *testing.T) {
+	root := &Commands{
+		Model:          "root-model",
+		ModelFallbacks: []string{"root-fallback"},
+		PushRemote:     "git@example.com:fork.git",
+		Commands:       map[string]string{"test": "go test ./...", "lint": "golangci-lint run"},
+	}
+	group := &Commands{
+		Model:          "group-model",
+		ModelFallbacks: []string{"group-fallback"},
+		Commands:       map[string]string{"test": "npm test", "dev": "npm run dev"},
+	}
+
+	merged := root.Overlay(group)
+
+	if merged.Model != "group-model" {
+		t.Errorf("Model = %q, want group-model", merged.Model)
+	}
+	if len(merged.ModelFallbacks) != 1 || merged.ModelFallbacks[0] != "group-fallback" {
+		t.Errorf("ModelFallbacks = %v, want [group-fallback]", merged.ModelFallbacks)
+	}
+	if merged.PushRemote != root.PushRemote {
+		t.Errorf("PushRemote = %q, want %q", merged.PushRemote, root.PushRemote)
+	}
+	want := map[string]string{"test": "npm test", "lint": "golangci-lint run", "dev": "npm run dev"}
+	for name, cmd := range want {
+		if merged.Commands[name] != cmd {
+			t.Errorf("Commands[%q] = %q, want %q", name, merged.Commands[name], cmd)
+		}
+	}
+	if root.Commands["test"] != "go test ./..." {
+		t.Errorf("Overlay modified the root commands: test = %q", root.Commands["test"])
+	}
+
+	if m := root.Overlay(&Commands{}).Model; m != "root-model" {
+		t.Errorf("Model without group model = %q, want root-model", m)
+	}
+	if f := root.Overlay(&Commands{}).ModelFallbacks; len(f) != 1 || f[0] != "root-fallback" {
+		t.Errorf("ModelFallbacks without group fallbacks = %v, want [root-fallback]", f)
+	}
+	if root.Overlay(&Commands{}).PlanHandoff {
+		t.Error("PlanHandoff without group plan_handoff = true, want false")
+	}
+	if !root.Overlay(&Commands{PlanHandoff: true}).PlanHandoff {
+		t.Error("PlanHandoff with group plan_handoff = false, want true")
+	}
+}
+
+func TestLoadExecutor(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"default", "commands:\n  test: \"true\"\n", false},
+		{"host", "executor: host\n", false},
+		{"docker", "executor: docker\ncontainer:\n  image: golang:1.25\n  args: [\"--network\", \"none\"]\n", false},
+		{"docker without image", "executor: docker\n", true},
+		{"unknown", "executor: podman\n", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "hydra.yml")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmds, err := Load(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Load error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.name == "docker" && (!cmds.Containerized() || cmds.Container.Args[1] != "none") {
+			t.Errorf("%s: executor not loaded: %+v", tt.name, cmds)
+		}
+	}
+}
+
+func TestLoadDevPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"ports", "dev_ports:\n  - name: web\n    port: 3000\n  - port: 8080\n    url: http://${HYDRA_TASK}.localhost:8080\n", false},
+		{"out of range", "dev_ports:\n  - port: 70000\n", true},
+		{"no port", "dev_ports:\n  - name: web\n", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "hydra.yml")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmds, err := Load(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Load error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		lookup := func(string) string { return "login" }
+		if got := cmds.DevPorts[0].Address(lookup); got != "http://localhost:3000" {
+			t.Errorf("%s: first address = %q", tt.name, got)
+		}
+		if got := cmds.DevPorts[1].Address(lookup); got != "http://login.localhost:8080" {
+			t.Errorf("%s: second address = %q", tt.name, got)
+		}
+	}
+}
+
+func TestLoadSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"rules", "sync:\n  milestones: true\n  rules:\n    - label: bug\n      group: bugs\n      priority: high\n    - label: wontfix\n      skip: true\n", false},
+		{"no label", "sync:\n  rules:\n    - group: bugs\n", true},
+		{"nested group", "sync:\n  rules:\n    - label: bug\n      group: a/b\n", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "hydra.yml")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmds, err := Load(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Load error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.name == "rules" {
+			s := cmds.Sync
+			if s == nil || !s.Milestones || len(s.Rules) != 2 || s.Rules[0].Group != "bugs" || s.Rules[0].Priority != "high" || !s.Rules[1].Skip {
+				t.Errorf("sync not loaded: %+v", s)
+			}
+		}
+	}
+}
+
+func TestLoadNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // default channels
+		wantErr bool
+	}{
+		{"none", "model: x\n", []string{"desktop"}, false},
+		{"all configured", "notifications:\n  slack: https://hooks.slack.com/x\n  webhook: https://example.com/hook\n", []string{"desktop", "slack", "webhook"}, false},
+		{"routed", "notifications:\n  slack: https://hooks.slack.com/x\n  channels: [slack]\n", []string{"slack"}, false},
+		{"unknown", "notifications:\n  channels: [pager]\n", nil, true},
+		{"no url", "notifications:\n  channels: [webhook]\n", nil, true},
+		{"heartbeat", "notifications:\n  heartbeat: 30m\n", []string{"desktop"}, false},
+		{"negative heartbeat", "notifications:\n  heartbeat: -5m\n", nil, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "hydra.yml")
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmds, err := Load(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Load error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := cmds.Notifications.Default(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: Default() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.name == "heartbeat" && (cmds.Notifications.Heartbeat == nil || cmds.Notifications.Heartbeat.Duration != 30*time.Minute) {
+			t.Errorf("heartbeat = %+v, want 30m", cmds.Notifications.Heartbeat)
+		}
+	}
+}
+
+func TestCommandContainer(t *testing.T) {
+	// A worktree's .git file points into the main repository's git dir.
+	main := t.TempDir()
+	gitDir := filepath.Join(main, ".git")
+	wtGitDir := filepath.Join(gitDir, "worktrees", "task")
+	if err := os.MkdirAll(wtGitDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtGitDir, "commondir"), []byte("../..\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(wd, ".git"), []byte("gitdir: "+wtGitDir+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := &Commands{
+		Executor:  ExecutorDocker,
+		Container: &Container{Image: "golang:1.25", Args: []string{"--network", "none"}},
+	}
+	cmd := cmds.Command(t.Context(), wd, "go test ./...")
+	args := strings.Join(cmd.Args, " ")
+
+	for _, want := range []string{
+		"docker run --rm -i --init -v " + wd + ":" + wd + " -w " + wd,
+		"-v " + gitDir + ":" + gitDir,
+		"--network none golang:1.25 sh -c go test ./...",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("docker command missing %q:\n%s", want, args)
+		}
+	}
+
+	host := (&Commands{}).Command(t.Context(), wd, "echo hi")
+	if host.Dir != wd || host.Args[len(host.Args)-1] != "echo hi" {
+		t.Errorf("host command = %v in %q", host.Args, host.Dir)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	dir := t.TempDir()
+	cmds := &Commands{Commands: map[string]string{
+		HookAfterRun:  "echo \"$HYDRA_TASK\" > hook.out",
+		HookOnFailure: "false",
+	}}
+
+	ran, err := cmds.RunHook(HookAfterRun, dir, []string{"HYDRA_TASK=add-feature"})
+	if err != nil || !ran {
+		t.Fatalf("RunHook = %v, %v", ran, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hook.out")) //nolint:gosec // test
+	if err != nil || string(data) != "add-feature\n" {
+		t.Errorf("hook output = %q, %v", data, err)
+	}
+
+	if ran, err := cmds.RunHook(HookOnFailure, dir, nil); err == nil || !ran {
+		t.Errorf("failing hook = %v, %v; want an error", ran, err)
+	}
+	if ran, err := cmds.RunHook(HookAfterMerge, dir, nil); err != nil || ran {
+		t.Errorf("unconfigured hook = %v, %v; want not run", ran, err)
+	}
+}
+
+func TestLoadLimits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hydra.yml")
+
+	if err := os.WriteFile(path, []byte("limits:\n  test:\n    cpu: 1.5\n    memory: 4G\n    time: 20m\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	l := cmds.Limits["test"]
+	if l.CPU != 1.5 || l.Memory != "4G" || l.Time == nil || l.Time.Duration != 20*time.Minute {
+		t.Errorf("Limits[test] = %+v", l)
+	}
+
+	for _, bad := range []string{"memory: lots", "memory: 0M", "cpu: -1"} {
+		if err := os.WriteFile(path, []byte("limits:\n  test:\n    "+bad+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLimitsWrap(t *testing.T) {
+	l := Limits{CPU: 2, Memory: "512M", Time: &Duration{90 * time.Second}}
+
+	got := l.wrap("go test ./...", "/bin/bash", "timeout", true)
+	want := "systemd-run --user --scope --quiet --collect -p MemoryMax=536870912 -p CPUQuota=200% -- " +
+		"timeout -k 10s 90s /bin/bash -c 'go test ./...'"
+	if got != want {
+		t.Errorf("wrap with cgroups =\n%s\nwant\n%s", got, want)
+	}
+
+	got = l.wrap("go test ./...", "sh", "gtimeout", false)
+	want = "ulimit -v 524288 && exec gtimeout -k 10s 90s sh -c 'go test ./...'"
+	if got != want {
+		t.Errorf("wrap without cgroups =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunTimeLimit(t *testing.T) {
+	dir := t.TempDir()
+	cmds := &Commands{
+		Commands: map[string]string{"test": "sleep 30", "lint": "true"},
+		Limits:   map[string]Limits{"test": {Time: &Duration{time.Second}}},
+	}
+
+	start := time.Now()
+	if err := cmds.Run("test", dir); err == nil {
+		t.Error("expected the command to fail at its time limit")
+	}
+	if elapsed := time.Since(start); elapsed > 15*time.Second {
+		t.Errorf("command ran %s, want it stopped after about a second", elapsed)
+	}
+
+	// Commands without limits are left alone.
+	if got := cmds.EffectiveCommands(dir)["lint"]; got != "true" {
+		t.Errorf("lint = %q, want it unwrapped", got)
+	}
+	if got := cmds.EffectiveCommands(dir)["test"]; !strings.Contains(got, "timeout -k 10s 1s") {
+		t.Errorf("test = %q, want it wrapped in its time limit", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"-3d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
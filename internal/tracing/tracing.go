@@ -0,0 +1,83 @@
+// Package tracing provides OpenTelemetry spans for hydra runs. Spans are
+// exported via OTLP when an endpoint is configured through the standard
+// OTEL_EXPORTER_OTLP_* environment variables; otherwise tracing is a no-op.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/erikh/hydra"
+
+// Enabled returns true if an OTLP endpoint is configured in the environment.
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs a global tracer provider that exports spans via OTLP/HTTP.
+// If no endpoint is configured it does nothing. The returned function flushes
+// pending spans and shuts the provider down; it is always safe to call.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !Enabled() {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	// Explicit attributes come first so OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES can override them.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "hydra")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return noop, fmt.Errorf("creating trace resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span named name as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartChild starts a span only if ctx already carries one, so low-level
+// operations nest under a traced workflow without creating stray root traces.
+// Otherwise it returns ctx and a no-op span.
+func StartChild(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Start(ctx, name, attrs...)
+}
+
+// End records err on span, if non-nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestStartChildNests(t *testing.T) {
+	rec := recordSpans(t)
+
+	ctx, parent := Start(context.Background(), "run")
+	_, child := StartChild(ctx, "git fetch")
+	End(child, nil)
+	End(parent, nil)
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	if spans[0].Name() != "git fetch" || spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("git fetch should be a child of run")
+	}
+}
+
+func TestStartChildWithoutParent(t *testing.T) {
+	rec := recordSpans(t)
+
+	_, span := StartChild(context.Background(), "git fetch")
+	End(span, nil)
+
+	if n := len(rec.Ended()); n != 0 {
+		t.Errorf("got %d spans without a parent, want 0", n)
+	}
+}
+
+func TestEndRecordsError(t *testing.T) {
+	rec := recordSpans(t)
+
+	_, span := Start(context.Background(), "push")
+	End(span, errors.New("rejected"))
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "rejected" {
+		t.Errorf("status = %+v, want error 'rejected'", spans[0].Status())
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(context.Background())
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
@@ -10,10 +10,47 @@ import (
 
 // ApprovalDialog renders a tool approval prompt.
 type ApprovalDialog struct {
-	Request  claude.EventToolRequest
-	Selected int // 0 = Accept, 1 = Reject
-	Theme    Theme
-	Width    int
+	Request    claude.EventToolRequest
+	Selected   int // 0 = Accept, 1 = Reject
+	Theme      Theme
+	Width      int
+	DiffHeight int // max diff lines shown at once; 0 shows the whole diff
+	DiffOffset int // first diff line shown when the diff is scrolled
+}
+
+// diffLines returns the lines of the request's diff.
+func (a ApprovalDialog) diffLines() []string {
+	if a.Request.Meta.Diff == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(a.Request.Meta.Diff, "\n"), "\n")
+}
+
+// ScrollDiff moves the diff window by delta lines, clamped to the diff.
+func (a *ApprovalDialog) ScrollDiff(delta int) {
+	maxOffset := max(len(a.diffLines())-a.DiffHeight, 0)
+	if a.DiffHeight <= 0 {
+		maxOffset = 0
+	}
+	a.DiffOffset = min(max(a.DiffOffset+delta, 0), maxOffset)
+}
+
+// renderDiff renders the visible window of the diff, with a position
+// indicator when the diff is larger than the window.
+func (a ApprovalDialog) renderDiff() string {
+	lines := strings.Split(RenderDiff(a.Request.Meta.Diff, a.Theme), "\n")
+	if n := len(a.diffLines()); n < len(lines) {
+		lines = lines[:n]
+	}
+	if a.DiffHeight <= 0 || len(lines) <= a.DiffHeight {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	end := min(a.DiffOffset+a.DiffHeight, len(lines))
+	window := strings.Join(lines[a.DiffOffset:end], "\n")
+	indicator := a.Theme.MutedStyle().Render(fmt.Sprintf(
+		"lines %d-%d of %d (up/down, pgup/pgdown to scroll)", a.DiffOffset+1, end, len(lines)))
+	return window + "\n" + indicator + "\n"
 }
 
 // View renders the approval dialog.
@@ -28,7 +65,7 @@ func (a ApprovalDialog) View() string {
 		fmt.Fprintf(&b, "%s %s\n\n", headerStyle.Render("File:"), a.Request.Meta.Path)
 		if a.Request.Meta.Diff != "" {
 			fmt.Fprintf(&b, "%s\n", headerStyle.Render("Diff:"))
-			b.WriteString(RenderDiff(a.Request.Meta.Diff, a.Theme))
+			b.WriteString(a.renderDiff())
 			b.WriteString("\n")
 		}
 	case claude.ToolKindBash:
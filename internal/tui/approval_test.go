@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -139,3 +140,46 @@ func TestApprovalDialogNoDiffForWriteWithoutDiff(t *testing.T) {
 		t.Error("approval dialog should not show Diff header when diff is empty")
 	}
 }
+
+func TestApprovalDialogScrollsLargeDiff(t *testing.T) {
+	var lines []string
+	for i := range 50 {
+		lines = append(lines, fmt.Sprintf("+line-%02d", i))
+	}
+	dialog := ApprovalDialog{
+		Request: claude.EventToolRequest{
+			Name: "write_file",
+			Meta: claude.ToolMeta{
+				Kind: claude.ToolKindWrite,
+				Path: "big.txt",
+				Diff: strings.Join(lines, "\n") + "\n",
+			},
+		},
+		Theme:      DefaultTheme(),
+		Width:      80,
+		DiffHeight: 10,
+	}
+
+	view := dialog.View()
+	if !strings.Contains(view, "line-00") || strings.Contains(view, "line-10") {
+		t.Errorf("first page should show lines 0-9 only:\n%s", view)
+	}
+	if !strings.Contains(view, "lines 1-10 of 50") {
+		t.Errorf("missing scroll indicator:\n%s", view)
+	}
+
+	dialog.ScrollDiff(15)
+	view = dialog.View()
+	if strings.Contains(view, "line-14") || !strings.Contains(view, "line-15") || !strings.Contains(view, "line-24") {
+		t.Errorf("scrolled view should show lines 15-24:\n%s", view)
+	}
+
+	dialog.ScrollDiff(100)
+	if dialog.DiffOffset != 40 {
+		t.Errorf("offset = %d, want clamped to 40", dialog.DiffOffset)
+	}
+	dialog.ScrollDiff(-100)
+	if dialog.DiffOffset != 0 {
+		t.Errorf("offset = %d, want clamped to 0", dialog.DiffOffset)
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// copyToClipboard copies text to the system clipboard. It is a variable so
+// tests can capture what would be copied.
+var copyToClipboard = systemClipboard
+
+// clipboardCommands returns the clipboard tools to try, in order.
+func clipboardCommands() [][]string {
+	cmds := [][]string{{"pbcopy"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	return append(cmds,
+		[]string{"xclip", "-selection", "clipboard"},
+		[]string{"xsel", "--clipboard", "--input"},
+	)
+}
+
+// systemClipboard copies text with the first clipboard tool that works. If
+// none is available it falls back to an OSC 52 escape sequence, which most
+// modern terminals (and tmux, over SSH) honor.
+func systemClipboard(text string) {
+	for _, args := range clipboardCommands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(context.Background(), path, args[1:]...) //nolint:gosec // fixed clipboard tool names
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return
+		}
+	}
+	termenv.Copy(text)
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandStartMsg announces a command hydra started during the session.
+type commandStartMsg struct {
+	id        int64
+	name, cmd string
+}
+
+// commandOutputMsg carries output from a running command.
+type commandOutputMsg struct {
+	id   int64
+	text string
+}
+
+// commandEndMsg reports that a command exited, with its error, if any.
+type commandEndMsg struct {
+	id  int64
+	err error
+}
+
+// CommandSink shows the hydra.yml commands hydra runs while the TUI has the
+// terminal, such as hooks and test and lint checks, in the transcript
+// instead of letting them write over it. It implements taskrun.Sink.
+type CommandSink struct {
+	send func(tea.Msg)
+	next atomic.Int64
+}
+
+// NewCommandSink returns a sink showing commands in p. Output sent after p
+// exits is dropped.
+func NewCommandSink(p *tea.Program) *CommandSink {
+	return &CommandSink{send: p.Send}
+}
+
+// Begin implements taskrun.Sink. The command's output goes only to the
+// TUI, not to stdout and stderr.
+func (s *CommandSink) Begin(name, cmdStr string, _, _ io.Writer) (io.Writer, io.Writer, func(error)) {
+	id := s.next.Add(1)
+	s.send(commandStartMsg{id: id, name: name, cmd: cmdStr})
+	end := func(err error) { s.send(commandEndMsg{id: id, err: err}) }
+	w := commandWriter{send: s.send, id: id}
+	return w, w, end
+}
+
+// commandWriter sends a command's output to the TUI.
+type commandWriter struct {
+	send func(tea.Msg)
+	id   int64
+}
+
+// Write implements io.Writer.
+func (w commandWriter) Write(b []byte) (int, error) {
+	w.send(commandOutputMsg{id: w.id, text: string(b)})
+	return len(b), nil
+}
+
+// commandBlock is a command in the transcript: a status line, and its
+// output, which is shown only while command output is expanded.
+type commandBlock struct {
+	name, cmd string
+	output    strings.Builder
+	started   time.Time
+	elapsed   time.Duration
+	done      bool
+	err       error
+}
+
+// render returns the block as shown in the viewport. toggle is the key
+// that expands command output, for the hint on a collapsed block.
+func (b *commandBlock) render(theme Theme, expanded bool, toggle string) string {
+	var s strings.Builder
+	switch {
+	case !b.done:
+		s.WriteString(theme.MutedStyle().Render(fmt.Sprintf("\n[run] %s: %s", b.name, b.cmd)))
+	case b.err != nil:
+		s.WriteString(theme.ErrorStyle().Render(fmt.Sprintf("\n[fail] %s (%s): %v", b.name, b.elapsed.Round(100*time.Millisecond), b.err)))
+	default:
+		s.WriteString(theme.SuccessStyle().Render(fmt.Sprintf("\n[pass] %s (%s)", b.name, b.elapsed.Round(100*time.Millisecond))))
+	}
+	s.WriteString("\n")
+
+	out := strings.TrimRight(b.output.String(), "\n")
+	switch {
+	case out == "":
+	case expanded:
+		for line := range strings.SplitSeq(out, "\n") {
+			s.WriteString("  " + line + "\n")
+		}
+	default:
+		n := strings.Count(out, "\n") + 1
+		s.WriteString(theme.MutedStyle().Render(fmt.Sprintf("  %d line(s) of output; press %s to show", n, toggle)))
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// handleCommandMsg adds a command's start, output, or exit to its block in
+// the transcript.
+func (m *Model) handleCommandMsg(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case commandStartMsg:
+		b := &commandBlock{name: msg.name, cmd: msg.cmd, started: time.Now()}
+		if m.commands == nil {
+			m.commands = make(map[int64]*commandBlock)
+		}
+		m.commands[msg.id] = b
+		m.segments = append(m.segments, &outputSegment{command: b})
+		m.transcript.event("command %s started: %s", msg.name, msg.cmd)
+
+	case commandOutputMsg:
+		if b, ok := m.commands[msg.id]; ok {
+			b.output.WriteString(msg.text)
+			m.transcript.write(msg.text)
+		}
+
+	case commandEndMsg:
+		b, ok := m.commands[msg.id]
+		if !ok {
+			return
+		}
+		b.done, b.err, b.elapsed = true, msg.err, time.Since(b.started)
+		delete(m.commands, msg.id)
+		if b.err != nil {
+			m.transcript.event("command %s failed: %v", b.name, b.err)
+		} else {
+			m.transcript.event("command %s passed", b.name)
+		}
+	}
+	m.refreshViewport()
+}
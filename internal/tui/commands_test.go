@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCommandBlocks(t *testing.T) {
+	m, _ := newTestModel(false)
+	m.appendText("Working on it.")
+
+	m.handleCommandMsg(commandStartMsg{id: 1, name: "test", cmd: "go test ./..."})
+	m.handleCommandMsg(commandStartMsg{id: 2, name: "lint", cmd: "golangci-lint run"})
+	if got := ansi.Strip(m.content()); !strings.Contains(got, "[run] test: go test ./...") {
+		t.Errorf("running command missing from:\n%s", got)
+	}
+
+	m.handleCommandMsg(commandOutputMsg{id: 1, text: "ok  pkg\n"})
+	m.handleCommandMsg(commandOutputMsg{id: 2, text: "main.go:3: unused\n"})
+	m.handleCommandMsg(commandEndMsg{id: 1})
+	m.handleCommandMsg(commandEndMsg{id: 2, err: errors.New("exit status 1")})
+	m.appendOutput("\n[auto] after the checks\n")
+
+	got := ansi.Strip(m.content())
+	for _, want := range []string{"[pass] test (", "[fail] lint (", "): exit status 1", "1 line(s) of output; press o to show", "[auto] after the checks"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("collapsed content missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "main.go:3: unused") {
+		t.Errorf("collapsed content shows command output:\n%s", got)
+	}
+	if strings.Index(got, "[auto]") < strings.Index(got, "[fail] lint") {
+		t.Errorf("later output should follow the command blocks:\n%s", got)
+	}
+
+	m.expandCommands = true
+	got = ansi.Strip(m.content())
+	if !strings.Contains(got, "  ok  pkg\n") || !strings.Contains(got, "  main.go:3: unused\n") {
+		t.Errorf("expanded content missing command output:\n%s", got)
+	}
+	backlog, _, stop := m.Transcript().Follow()
+	stop()
+	if !strings.Contains(backlog, "main.go:3: unused") {
+		t.Error("command output missing from the crash transcript")
+	}
+}
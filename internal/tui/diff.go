@@ -1,45 +1,31 @@
 package tui
 
 import (
-	"fmt"
 	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/erikh/hydra/internal/claude"
+	"github.com/muesli/termenv"
 )
 
-// ComputeUnifiedDiff creates a simple unified diff between old and new content.
+// ComputeUnifiedDiff creates a unified diff between old and new content.
 func ComputeUnifiedDiff(path, oldContent, newContent string) string {
-	oldLines := strings.Split(oldContent, "\n")
-	newLines := strings.Split(newContent, "\n")
-
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "--- a/%s\n", path)
-	fmt.Fprintf(&buf, "+++ b/%s\n", path)
-
-	i, j := 0, 0
-	for i < len(oldLines) || j < len(newLines) {
-		switch {
-		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
-			fmt.Fprintf(&buf, " %s\n", oldLines[i])
-			i++
-			j++
-		case i < len(oldLines):
-			fmt.Fprintf(&buf, "-%s\n", oldLines[i])
-			i++
-		default:
-			fmt.Fprintf(&buf, "+%s\n", newLines[j])
-			j++
-		}
-	}
-
-	return buf.String()
+	return claude.UnifiedDiff(path, oldContent, newContent)
 }
 
-// RenderDiff colorizes a unified diff string using the theme.
+// RenderDiff colorizes a unified diff string using the theme. The content of
+// added, removed, and context lines is syntax-highlighted according to the
+// file name in the +++ header when the terminal supports color.
 func RenderDiff(diff string, theme Theme) string {
 	if diff == "" {
 		return ""
 	}
 
 	lines := strings.Split(diff, "\n")
+	hl := newLineHighlighter(diffPath(lines), theme)
 	var rendered []string
 
 	for _, line := range lines {
@@ -49,13 +35,66 @@ func RenderDiff(diff string, theme Theme) string {
 		case strings.HasPrefix(line, "@@"):
 			rendered = append(rendered, theme.AccentStyle().Render(line))
 		case strings.HasPrefix(line, "+"):
-			rendered = append(rendered, theme.DiffAddStyle().Render(line))
+			rendered = append(rendered, hl.render(theme.DiffAddStyle(), line))
 		case strings.HasPrefix(line, "-"):
-			rendered = append(rendered, theme.DiffRemoveStyle().Render(line))
+			rendered = append(rendered, hl.render(theme.DiffRemoveStyle(), line))
 		default:
-			rendered = append(rendered, theme.MutedStyle().Render(line))
+			rendered = append(rendered, hl.render(theme.MutedStyle(), line))
 		}
 	}
 
 	return strings.Join(rendered, "\n")
 }
+
+// diffPath returns the file path from a diff's +++ header, if any.
+func diffPath(lines []string) string {
+	for _, line := range lines {
+		if path, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			return path
+		}
+	}
+	return ""
+}
+
+// lineHighlighter syntax-highlights single diff lines for a file type.
+type lineHighlighter struct {
+	lexer     chroma.Lexer
+	formatter chroma.Formatter
+	style     *chroma.Style
+}
+
+// newLineHighlighter returns a highlighter for path. Its lexer is nil when the
+// file type is unknown or the terminal has no color, in which case lines are
+// rendered with the plain diff styles.
+func newLineHighlighter(path string, theme Theme) lineHighlighter {
+	if path == "" || lipgloss.ColorProfile() == termenv.Ascii {
+		return lineHighlighter{}
+	}
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		return lineHighlighter{}
+	}
+	return lineHighlighter{
+		lexer:     chroma.Coalesce(lexer),
+		formatter: formatters.Get("terminal256"),
+		style:     theme.ChromaStyle(),
+	}
+}
+
+// render styles the +/-/space marker with markerStyle and highlights the rest
+// of the line. Without a lexer the whole line gets markerStyle.
+func (h lineHighlighter) render(markerStyle lipgloss.Style, line string) string {
+	if h.lexer == nil || line == "" {
+		return markerStyle.Render(line)
+	}
+
+	iterator, err := h.lexer.Tokenise(nil, line[1:])
+	if err != nil {
+		return markerStyle.Render(line)
+	}
+	var b strings.Builder
+	if err := h.formatter.Format(&b, h.style, iterator); err != nil {
+		return markerStyle.Render(line)
+	}
+	return markerStyle.Render(line[:1]) + strings.TrimSuffix(b.String(), "\n")
+}
@@ -3,6 +3,9 @@ package tui
 import (
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 func TestComputeUnifiedDiffIdentical(t *testing.T) {
@@ -78,3 +81,25 @@ func TestRenderDiffColorizesLines(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderDiffHighlightsSyntax(t *testing.T) {
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+
+	diff := "--- a/f.go\n+++ b/f.go\n@@ -1 +1 @@\n-var x = 1\n+var x = 2\n"
+	rendered := RenderDiff(diff, DefaultTheme())
+	if !strings.Contains(rendered, "\x1b[") {
+		t.Fatalf("expected ANSI highlighting:\n%q", rendered)
+	}
+	// Highlighting splits tokens with escape codes, so "var x = 1" no longer
+	// appears verbatim, but each token does.
+	for _, tok := range []string{"var", "x", "1", "2"} {
+		if !strings.Contains(rendered, tok) {
+			t.Errorf("rendered diff missing %q:\n%q", tok, rendered)
+		}
+	}
+	if strings.Contains(rendered, "var x = 1") {
+		t.Errorf("go source should be syntax-highlighted:\n%q", rendered)
+	}
+}
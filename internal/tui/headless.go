@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/erikh/hydra/internal/claude"
+)
+
+// RunHeadless drives a session without a terminal, for runs in the
+// background. Tool calls are approved unless an approval rule denies them;
+// a rule that asks for a prompt denies them too, since no one is there to
+// answer. The session's output is written to w as plain text as it
+// streams, in the form the TUI shows it. Output and events are also
+// recorded in transcript. It returns when the session ends or ctx is done,
+// with the session's error if it failed.
+func RunHeadless(ctx context.Context, session *claude.Session, rules []claude.ApprovalRule, w io.Writer, transcript *Transcript) error {
+	out := func(format string, args ...any) {
+		s := fmt.Sprintf(format, args...)
+		_, _ = io.WriteString(w, s)
+		transcript.write(s)
+	}
+
+	for {
+		var evt claude.Event
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case evt = <-session.Events:
+		}
+
+		switch evt := evt.(type) {
+		case claude.EventRateLimited:
+			wait := evt.Wait.Round(time.Second)
+			transcript.event("rate limited, waiting %s", wait)
+			out("\n[rate limit] waiting %s before the next request\n", wait)
+
+		case claude.EventModelFallback:
+			transcript.event("%s overloaded, switching to %s", evt.From, evt.To)
+			out("\n[model] %s is overloaded; switching to %s\n", evt.From, evt.To)
+
+		case claude.EventInjected:
+			transcript.event("hydra: %s", evt.Text)
+			out("\n[hydra] %s\n", evt.Text)
+
+		case claude.EventText:
+			out("%s", evt.Text)
+
+		case claude.EventThinking:
+			out("%s", evt.Text)
+
+		case claude.EventToolRequest:
+			switch claude.DecideApproval(rules, evt) {
+			case claude.ApprovalDeny, claude.ApprovalPrompt:
+				session.ToolAnswer <- claude.ToolAnswer{ID: evt.ID, Approved: false, Reason: claude.DeniedByRule}
+				transcript.event("tool denied by rule %s: %s", evt.Name, toolSummary(evt))
+				out("\n[denied] %s: %s\n", evt.Name, toolSummary(evt))
+			default:
+				session.ToolAnswer <- claude.ToolAnswer{ID: evt.ID, Approved: true}
+				transcript.event("tool auto-approved %s: %s", evt.Name, toolSummary(evt))
+				out("\n[auto] %s: %s\n", evt.Name, toolSummary(evt))
+			}
+
+		case claude.EventToolResult:
+			prefix := "[ok]"
+			if evt.IsError {
+				prefix = "[err]"
+			}
+			transcript.event("tool result (error=%t): %s", evt.IsError, truncate(evt.Content, 200))
+			out("\n%s %s\n", prefix, truncate(evt.Content, 200))
+
+		case claude.EventDone:
+			transcript.event("session done: %s", evt.StopReason)
+			out("\n\nSession complete (%s).\n", evt.StopReason)
+			return nil
+
+		case claude.EventError:
+			transcript.event("session error: %v", evt.Err)
+			out("\n\nError: %v\n", evt.Err)
+			return evt.Err
+		}
+	}
+}
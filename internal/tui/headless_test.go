@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/erikh/hydra/internal/claude"
+)
+
+func TestRunHeadless(t *testing.T) {
+	events := make(chan claude.Event, 10)
+	answers := make(chan claude.ToolAnswer, 10)
+	session := &claude.Session{Events: events, ToolAnswer: answers}
+
+	events <- claude.EventText{Text: "working on it"}
+	events <- claude.EventToolRequest{
+		ID:   "t1",
+		Name: "bash",
+		Meta: claude.ToolMeta{Kind: claude.ToolKindBash, Command: "go test ./..."},
+	}
+	events <- claude.EventToolResult{ID: "t1", Content: "ok\nmore"}
+	events <- claude.EventDone{StopReason: "end_turn"}
+
+	var out strings.Builder
+	transcript := NewTranscript()
+	if err := RunHeadless(context.Background(), session, nil, &out, transcript); err != nil {
+		t.Fatalf("RunHeadless: %v", err)
+	}
+
+	if answer := <-answers; answer.ID != "t1" || !answer.Approved {
+		t.Errorf("answer = %+v, want t1 approved", answer)
+	}
+	for _, want := range []string{"working on it", "[auto] bash: go test ./...", "[ok] ok\n", "Session complete (end_turn)."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if !strings.Contains(transcript.output.String(), "working on it") || len(transcript.events) != 3 {
+		t.Errorf("transcript not recorded: %q, %v", transcript.output.String(), transcript.events)
+	}
+}
+
+func TestRunHeadlessError(t *testing.T) {
+	events := make(chan claude.Event, 1)
+	session := &claude.Session{Events: events, ToolAnswer: make(chan claude.ToolAnswer, 1)}
+	events <- claude.EventError{Err: errors.New("stream reset")}
+
+	var out strings.Builder
+	err := RunHeadless(context.Background(), session, nil, &out, NewTranscript())
+	if err == nil || err.Error() != "stream reset" {
+		t.Errorf("err = %v, want stream reset", err)
+	}
+}
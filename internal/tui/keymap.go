@@ -1,17 +1,38 @@
 package tui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"go.yaml.in/yaml/v4"
+)
 
 // KeyMap defines all keybindings for the TUI.
 type KeyMap struct {
-	Quit       key.Binding
-	AutoAccept key.Binding
-	Approve    key.Binding
-	Reject     key.Binding
-	ScrollUp   key.Binding
-	ScrollDown key.Binding
-	NavLeft    key.Binding
-	NavRight   key.Binding
+	Quit          key.Binding
+	AutoAccept    key.Binding
+	Approve       key.Binding
+	Reject        key.Binding
+	ScrollUp      key.Binding
+	ScrollDown    key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	NavLeft       key.Binding
+	NavRight      key.Binding
+	Search        key.Binding
+	NextMatch     key.Binding
+	PrevMatch     key.Binding
+	CopyMode      key.Binding
+	Markdown      key.Binding
+	CommandOutput key.Binding
+	Mark          key.Binding
+	Yank          key.Binding
+	Cancel        key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings.
@@ -41,6 +62,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("down"),
 			key.WithHelp("down", "scroll down"),
 		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdown", "page down"),
+		),
 		NavLeft: key.NewBinding(
 			key.WithKeys("left"),
 			key.WithHelp("left", "select accept"),
@@ -49,5 +78,156 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("right"),
 			key.WithHelp("right", "select reject"),
 		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search transcript"),
+		),
+		NextMatch: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		PrevMatch: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
+		CopyMode: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "copy mode"),
+		),
+		Markdown: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "toggle markdown rendering"),
+		),
+		CommandOutput: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "toggle command output"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark selection start"),
+		),
+		Yank: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy selection"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "exit search or copy mode"),
+		),
+	}
+}
+
+// actions maps the action names of the keys section in ~/.hydra.yml to
+// their bindings.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":           &k.Quit,
+		"auto_accept":    &k.AutoAccept,
+		"approve":        &k.Approve,
+		"reject":         &k.Reject,
+		"scroll_up":      &k.ScrollUp,
+		"scroll_down":    &k.ScrollDown,
+		"page_up":        &k.PageUp,
+		"page_down":      &k.PageDown,
+		"nav_left":       &k.NavLeft,
+		"nav_right":      &k.NavRight,
+		"search":         &k.Search,
+		"next_match":     &k.NextMatch,
+		"prev_match":     &k.PrevMatch,
+		"copy_mode":      &k.CopyMode,
+		"markdown":       &k.Markdown,
+		"command_output": &k.CommandOutput,
+		"mark":           &k.Mark,
+		"yank":           &k.Yank,
+		"cancel":         &k.Cancel,
+	}
+}
+
+// keyList is the keys bound to an action in ~/.hydra.yml: a single key or
+// a list of them.
+type keyList []string
+
+// UnmarshalYAML accepts a string or a list of strings.
+func (l *keyList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = keyList{node.Value}
+		return nil
+	}
+	var keys []string
+	if err := node.Decode(&keys); err != nil {
+		return err
+	}
+	*l = keys
+	return nil
+}
+
+// LoadKeyMap returns the default keybindings with the overrides from the
+// keys section of ~/.hydra.yml applied.
+func LoadKeyMap() KeyMap {
+	km := DefaultKeyMap()
+	if cfg, ok := loadGlobalConfig(); ok {
+		km.apply(cfg.Keys, os.Stderr)
+	}
+	return km
+}
+
+// apply rebinds each action named in keys to the given keys, replacing its
+// defaults. Unknown actions and empty key lists are reported to w and
+// skipped.
+func (k *KeyMap) apply(keys map[string]keyList, w io.Writer) {
+	actions := k.actions()
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b, ok := actions[name]
+		if !ok {
+			fmt.Fprintf(w, "Warning: unknown key action %q in ~/.hydra.yml\n", name)
+			continue
+		}
+		if len(keys[name]) == 0 {
+			fmt.Fprintf(w, "Warning: no keys given for %q in ~/.hydra.yml; keeping the default\n", name)
+			continue
+		}
+		b.SetKeys(keys[name]...)
+		b.SetHelp(strings.Join(keys[name], "/"), b.Help().Desc)
+	}
+}
+
+// viewportKeys adds the scroll keys to the viewport's own bindings, which
+// otherwise only know the default keys.
+func (k KeyMap) viewportKeys(vk viewport.KeyMap) viewport.KeyMap {
+	vk.Up.SetKeys(append(vk.Up.Keys(), k.ScrollUp.Keys()...)...)
+	vk.Down.SetKeys(append(vk.Down.Keys(), k.ScrollDown.Keys()...)...)
+	vk.PageUp.SetKeys(append(vk.PageUp.Keys(), k.PageUp.Keys()...)...)
+	vk.PageDown.SetKeys(append(vk.PageDown.Keys(), k.PageDown.Keys()...)...)
+	return vk
+}
+
+// statusHints lists the main keys for the status bar.
+func (k KeyMap) statusHints() string {
+	return fmt.Sprintf("| %s quit | %s: auto-accept | %s: search | %s: copy ",
+		keyLabel(k.Quit), keyLabel(k.AutoAccept), keyLabel(k.Search), keyLabel(k.CopyMode))
+}
+
+// keyLabel returns the first key of a binding for display, with modifier
+// names capitalized, e.g. Ctrl+C.
+func keyLabel(b key.Binding) string {
+	keys := b.Keys()
+	if len(keys) == 0 {
+		return ""
+	}
+	parts := strings.Split(keys[0], "+")
+	if len(parts) == 1 {
+		return keys[0]
+	}
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
 	}
+	return strings.Join(parts, "+")
 }
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.yaml.in/yaml/v4"
+)
+
+func TestKeyMapApply(t *testing.T) {
+	var cfg globalConfig
+	data := `
+keys:
+  auto_accept: ctrl+a
+  reject: [x, "n"]
+  scroll_up: []
+  bogus: q
+`
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	km := DefaultKeyMap()
+	var warnings bytes.Buffer
+	km.apply(cfg.Keys, &warnings)
+
+	press := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	if key.Matches(press("a"), km.AutoAccept) || !key.Matches(tea.KeyMsg{Type: tea.KeyCtrlA}, km.AutoAccept) {
+		t.Errorf("auto_accept keys = %v, want [ctrl+a]", km.AutoAccept.Keys())
+	}
+	if !key.Matches(press("x"), km.Reject) || key.Matches(tea.KeyMsg{Type: tea.KeyEsc}, km.Reject) {
+		t.Errorf("reject keys = %v, want [x n]", km.Reject.Keys())
+	}
+	if km.Reject.Help().Key != "x/n" || km.Reject.Help().Desc != "reject tool" {
+		t.Errorf("reject help = %+v", km.Reject.Help())
+	}
+	if got := km.ScrollUp.Keys(); len(got) != 1 || got[0] != "up" {
+		t.Errorf("an empty key list should keep the default, got %v", got)
+	}
+
+	for _, want := range []string{`unknown key action "bogus"`, `no keys given for "scroll_up"`} {
+		if !strings.Contains(warnings.String(), want) {
+			t.Errorf("warnings missing %q:\n%s", want, warnings.String())
+		}
+	}
+}
+
+func TestStatusHints(t *testing.T) {
+	if got := DefaultKeyMap().statusHints(); got != "| Ctrl+C quit | a: auto-accept | /: search | v: copy " {
+		t.Errorf("default hints = %q", got)
+	}
+
+	km := DefaultKeyMap()
+	km.apply(map[string]keyList{"auto_accept": {"ctrl+alt+a"}}, &bytes.Buffer{})
+	if got := km.statusHints(); !strings.Contains(got, "Ctrl+Alt+A: auto-accept") {
+		t.Errorf("hints should show the configured key, got %q", got)
+	}
+}
+
+func TestViewportKeysKeepDefaults(t *testing.T) {
+	km := DefaultKeyMap()
+	km.apply(map[string]keyList{"scroll_down": {"ctrl+j"}}, &bytes.Buffer{})
+
+	vk := km.viewportKeys(viewport.DefaultKeyMap())
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyCtrlJ}, vk.Down) {
+		t.Errorf("viewport down keys = %v, want ctrl+j added", vk.Down.Keys())
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyDown}, vk.Down) {
+		t.Errorf("viewport down keys = %v, want the defaults kept", vk.Down.Keys())
+	}
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	headingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	bulletRe    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedRe   = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	ruleRe      = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	inlineRe    = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*")
+	fenceMarker = "```"
+)
+
+// RenderMarkdown styles Claude's markdown output for the terminal with the
+// theme: headings, lists, block quotes, rules, inline code and emphasis, and
+// fenced code blocks, which are syntax-highlighted by their language (diff
+// blocks are rendered like tool diffs). A code block still being streamed is
+// rendered as code so far. Anything else is left as it is.
+func RenderMarkdown(text string, theme Theme) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if lang, ok := strings.CutPrefix(trimmed, fenceMarker); ok {
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != fenceMarker {
+				end++
+			}
+			out = append(out, theme.MutedStyle().Render(line))
+			out = append(out, renderCodeBlock(lines[i+1:end], strings.TrimSpace(lang), theme)...)
+			if end < len(lines) {
+				out = append(out, theme.MutedStyle().Render(lines[end]))
+			}
+			i = end
+			continue
+		}
+		out = append(out, renderMarkdownLine(line, theme))
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderMarkdownLine styles a line outside a code block.
+func renderMarkdownLine(line string, theme Theme) string {
+	if m := headingRe.FindStringSubmatch(line); m != nil {
+		style := theme.AccentStyle()
+		if len(m[1]) == 1 {
+			style = style.Underline(true)
+		}
+		return style.Render(m[2])
+	}
+	if ruleRe.MatchString(line) {
+		return theme.MutedStyle().Render(strings.Repeat("─", 40))
+	}
+	if quote, ok := strings.CutPrefix(line, ">"); ok {
+		return theme.MutedStyle().Render("│ " + strings.TrimPrefix(quote, " "))
+	}
+	if m := bulletRe.FindStringSubmatch(line); m != nil {
+		return m[1] + theme.AccentStyle().Render("•") + " " + renderInline(m[2], theme)
+	}
+	if m := orderedRe.FindStringSubmatch(line); m != nil {
+		return m[1] + theme.AccentStyle().Render(m[2]) + " " + renderInline(m[3], theme)
+	}
+	return renderInline(line, theme)
+}
+
+// renderInline styles `code`, **bold**, __bold__, and *italic* spans.
+func renderInline(text string, theme Theme) string {
+	return inlineRe.ReplaceAllStringFunc(text, func(span string) string {
+		switch {
+		case strings.HasPrefix(span, "`"):
+			return theme.HighlightStyle().Render(strings.Trim(span, "`"))
+		case strings.HasPrefix(span, "**"), strings.HasPrefix(span, "__"):
+			return lipgloss.NewStyle().Bold(true).Render(span[2 : len(span)-2])
+		default:
+			return lipgloss.NewStyle().Italic(true).Render(span[1 : len(span)-1])
+		}
+	})
+}
+
+// renderCodeBlock highlights the lines of a fenced code block in the given
+// language. Unknown languages, and terminals without color, get the muted
+// style.
+func renderCodeBlock(lines []string, lang string, theme Theme) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	code := strings.Join(lines, "\n")
+	if lang == "diff" || lang == "patch" {
+		return strings.Split(RenderDiff(code, theme), "\n")
+	}
+
+	plain := func() []string {
+		out := make([]string, len(lines))
+		for i, l := range lines {
+			out[i] = theme.MutedStyle().Render(l)
+		}
+		return out
+	}
+	if lang == "" || lipgloss.ColorProfile() == termenv.Ascii {
+		return plain()
+	}
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		return plain()
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return plain()
+	}
+	var b strings.Builder
+	if err := formatters.Get("terminal256").Format(&b, theme.ChromaStyle(), iterator); err != nil {
+		return plain()
+	}
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
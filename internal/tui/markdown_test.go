@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/erikh/hydra/internal/claude"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	theme := DefaultTheme()
+	text := "# Plan\n\nI'll change `main.go`:\n\n- add a **flag**\n2. wire it up\n> note\n---\n```go\nfunc main() {}\n```\nafter"
+
+	got := strings.Split(ansi.Strip(RenderMarkdown(text, theme)), "\n")
+	want := []string{
+		"Plan",
+		"",
+		"I'll change main.go:",
+		"",
+		"• add a flag",
+		"2. wire it up",
+		"│ note",
+		strings.Repeat("─", 40),
+		"```go",
+		"func main() {}",
+		"```",
+		"after",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("RenderMarkdown =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderMarkdownOpenFence(t *testing.T) {
+	// A code block still streaming in is treated as code, not markdown.
+	got := ansi.Strip(RenderMarkdown("```\n# not a heading\n- not a list", DefaultTheme()))
+	if got != "```\n# not a heading\n- not a list" {
+		t.Errorf("open fence rendered as %q", got)
+	}
+}
+
+func TestModelMarkdownToggle(t *testing.T) {
+	m, _ := newTestModel(false)
+	handleEvent(&m, eventMsg{event: claude.EventText{Text: "## Summary\n- done"}})
+
+	if got := ansi.Strip(m.content()); got != "Summary\n• done" {
+		t.Errorf("rendered content = %q", got)
+	}
+
+	m = press(t, m, runes("m"))
+	if m.markdown || m.content() != "## Summary\n- done" {
+		t.Errorf("raw content = %q, markdown = %v", m.content(), m.markdown)
+	}
+	m = press(t, m, runes("m"))
+	if !m.markdown {
+		t.Error("second press should turn markdown back on")
+	}
+}
@@ -3,8 +3,10 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -37,11 +39,38 @@ type Model struct {
 	approval   *ApprovalDialog
 	state      State
 	autoAccept bool
-	output     strings.Builder
+	rules      []claude.ApprovalRule // approval rules from hydra.yml, checked before autoAccept
+	output     strings.Builder       // raw transcript
 	err        error
 	width      int
 	height     int
 	ready      bool
+
+	// Transcript search and copy mode.
+	searchInput textinput.Model
+	searching   bool          // search prompt is open
+	query       string        // active search query
+	matches     []searchMatch // occurrences of query
+	matchIdx    int           // current match
+	copyMode    bool
+	copyCursor  int    // transcript line under the cursor
+	copyAnchor  int    // line where the selection starts; -1 if unmarked
+	notice      string // transient status bar message
+
+	// The transcript in segments, so Claude's text can be shown rendered as
+	// markdown or raw.
+	segments []*outputSegment
+	markdown bool
+
+	// Commands hydra runs during the session, by CommandSink ID, until
+	// they exit; their output is collapsed unless expandCommands is set.
+	commands       map[int64]*commandBlock
+	expandCommands bool
+
+	rateLimited bool      // the session is waiting on the rate limiter
+	started     time.Time // when the session started, for the elapsed time
+
+	transcript *Transcript // survives panics for crash logs
 }
 
 // eventMsg wraps a claude.Event for the Bubbletea message system.
@@ -49,27 +78,51 @@ type eventMsg struct {
 	event claude.Event
 }
 
+// tickMsg updates the elapsed time in the status bar.
+type tickMsg time.Time
+
+// tick schedules the next elapsed time update.
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
 // New creates a new TUI model.
 func New(session *claude.Session, model string, autoAccept bool) Model {
 	theme := LoadTheme()
+	keymap := LoadKeyMap()
+
+	input := textinput.New()
+	input.Prompt = "/"
 
 	return Model{
-		session:    session,
-		theme:      theme,
-		keymap:     DefaultKeyMap(),
-		autoAccept: autoAccept,
+		session:     session,
+		theme:       theme,
+		keymap:      keymap,
+		autoAccept:  autoAccept,
+		searchInput: input,
+		copyAnchor:  -1,
+		markdown:    true,
+		transcript:  NewTranscript(),
+		started:     time.Now(),
 		statusbar: StatusBar{
 			Model:      model,
 			State:      stateStreaming,
 			AutoAccept: autoAccept,
+			Hints:      keymap.statusHints(),
 			Theme:      theme,
 		},
 	}
 }
 
+// SetApprovalRules sets the rules that decide tool approvals ahead of the
+// auto-accept setting.
+func (m *Model) SetApprovalRules(rules []claude.ApprovalRule) {
+	m.rules = rules
+}
+
 // Init implements tea.Model.
 func (m Model) Init() tea.Cmd {
-	return m.waitForEvent()
+	return tea.Batch(m.waitForEvent(), tick())
 }
 
 // waitForEvent returns a command that waits for the next event from the session.
@@ -99,55 +152,111 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if !m.ready {
 			m.viewport = viewport.New(m.width, vpHeight)
+			m.viewport.KeyMap = m.keymap.viewportKeys(m.viewport.KeyMap)
 			m.viewport.YPosition = headerHeight
 			m.ready = true
 		} else {
 			m.viewport.Width = m.width
 			m.viewport.Height = vpHeight
 		}
+		if m.approval != nil {
+			m.approval.Width = m.width
+			m.approval.DiffHeight = diffHeight(m.height)
+			m.approval.ScrollDiff(0)
+		}
 
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, m.keymap.Quit):
+		if key.Matches(msg, m.keymap.Quit) {
 			m.session.Cancel()
 			return m, tea.Quit
+		}
+		if m.searching {
+			return m, m.handleSearchKey(msg)
+		}
+		if m.copyMode {
+			m.handleCopyKey(msg)
+			return m, nil
+		}
+		m.notice = ""
+		m.updateModeStatus()
+
+		// Search and copy mode are available whenever no tool is awaiting
+		// approval; during approval, n and y answer the dialog instead.
+		if m.approval == nil {
+			switch {
+			case key.Matches(msg, m.keymap.Search):
+				return m, m.startSearch()
+			case key.Matches(msg, m.keymap.CopyMode):
+				m.startCopyMode()
+				return m, nil
+			case m.query != "" && key.Matches(msg, m.keymap.NextMatch):
+				m.jumpToMatch(m.matchIdx + 1)
+				return m, nil
+			case m.query != "" && key.Matches(msg, m.keymap.PrevMatch):
+				m.jumpToMatch(m.matchIdx - 1)
+				return m, nil
+			case m.query != "" && key.Matches(msg, m.keymap.Cancel):
+				m.clearSearch()
+				return m, nil
+			}
+		}
+
+		// While a diff is shown for approval, scroll keys move the diff
+		// rather than the transcript.
+		if m.approval != nil && m.approval.Request.Meta.Diff != "" {
+			switch {
+			case key.Matches(msg, m.keymap.ScrollUp):
+				m.approval.ScrollDiff(-1)
+				return m, nil
+			case key.Matches(msg, m.keymap.ScrollDown):
+				m.approval.ScrollDiff(1)
+				return m, nil
+			case key.Matches(msg, m.keymap.PageUp):
+				m.approval.ScrollDiff(-m.approval.DiffHeight)
+				return m, nil
+			case key.Matches(msg, m.keymap.PageDown):
+				m.approval.ScrollDiff(m.approval.DiffHeight)
+				return m, nil
+			}
+		}
 
+		switch {
 		case key.Matches(msg, m.keymap.AutoAccept):
 			m.autoAccept = !m.autoAccept
 			m.statusbar.AutoAccept = m.autoAccept
-			// If we just enabled auto-accept and we're awaiting approval, approve it.
-			if m.autoAccept && m.state == StateAwaitingApproval && m.approval != nil {
-				m.session.ToolAnswer <- claude.ToolAnswer{
-					ID:       m.approval.Request.ID,
-					Approved: true,
-				}
-				m.state = StateStreaming
-				m.statusbar.State = stateStreaming
-				m.approval = nil
+			// If we just enabled auto-accept and we're awaiting approval, approve
+			// it, unless a rule says to always ask.
+			if m.autoAccept && m.state == StateAwaitingApproval && m.approval != nil &&
+				claude.DecideApproval(m.rules, m.approval.Request) != claude.ApprovalPrompt {
+				m.answerTool(true)
+			}
+
+		case key.Matches(msg, m.keymap.Markdown):
+			m.markdown = !m.markdown
+			m.notice = "Showing raw text"
+			if m.markdown {
+				m.notice = "Rendering markdown"
+			}
+			m.refreshViewport()
+
+		case key.Matches(msg, m.keymap.CommandOutput):
+			m.expandCommands = !m.expandCommands
+			m.notice = "Hiding command output"
+			if m.expandCommands {
+				m.notice = "Showing command output"
 			}
+			m.refreshViewport()
 
 		case key.Matches(msg, m.keymap.Approve):
 			if m.state == StateAwaitingApproval && m.approval != nil && m.approval.Selected == 0 {
-				m.session.ToolAnswer <- claude.ToolAnswer{
-					ID:       m.approval.Request.ID,
-					Approved: true,
-				}
-				m.state = StateStreaming
-				m.statusbar.State = stateStreaming
-				m.approval = nil
+				m.answerTool(true)
 			} else if m.state == StateCompleted || m.state == StateError {
 				return m, tea.Quit
 			}
 
 		case key.Matches(msg, m.keymap.Reject):
 			if m.state == StateAwaitingApproval && m.approval != nil {
-				m.session.ToolAnswer <- claude.ToolAnswer{
-					ID:       m.approval.Request.ID,
-					Approved: false,
-				}
-				m.state = StateStreaming
-				m.statusbar.State = stateStreaming
-				m.approval = nil
+				m.answerTool(false)
 			}
 
 		case key.Matches(msg, m.keymap.NavLeft):
@@ -161,8 +270,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tickMsg:
+		// The clock stops when the session ends.
+		if m.state == StateStreaming || m.state == StateAwaitingApproval {
+			m.statusbar.Elapsed = time.Time(msg).Sub(m.started)
+			cmds = append(cmds, tick())
+		}
+
 	case eventMsg:
 		cmds = append(cmds, handleEvent(&m, msg)...)
+
+	case commandStartMsg, commandOutputMsg, commandEndMsg:
+		m.handleCommandMsg(msg)
 	}
 
 	// Update viewport for scrolling.
@@ -173,43 +292,165 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// answerTool sends the user's decision on the pending tool request and
+// resumes streaming.
+func (m *Model) answerTool(approved bool) {
+	m.session.ToolAnswer <- claude.ToolAnswer{
+		ID:       m.approval.Request.ID,
+		Approved: approved,
+	}
+	decision := "rejected"
+	if approved {
+		decision = "approved"
+	}
+	m.transcript.event("tool %s %s: %s", decision, m.approval.Request.Name, toolSummary(m.approval.Request))
+	m.state = StateStreaming
+	m.statusbar.State = stateStreaming
+	m.approval = nil
+}
+
+// outputSegment is a run of transcript text: Claude's markdown, output
+// hydra styled itself, or a command hydra ran.
+type outputSegment struct {
+	text     strings.Builder
+	markdown bool
+	rendered string // text rendered as markdown, when not stale
+	stale    bool
+	command  *commandBlock // set for a command's block, which has no text
+}
+
+// appendOutput adds styled text to the transcript shown in the viewport and
+// to the crash transcript.
+func (m *Model) appendOutput(text string) {
+	m.appendSegment(text, false)
+}
+
+// appendText adds Claude's text, which is shown rendered as markdown unless
+// the user has switched to raw text.
+func (m *Model) appendText(text string) {
+	m.appendSegment(text, true)
+}
+
+func (m *Model) appendSegment(text string, markdown bool) {
+	m.output.WriteString(text)
+	m.transcript.write(text)
+	if n := len(m.segments); n == 0 || m.segments[n-1].markdown != markdown || m.segments[n-1].command != nil {
+		m.segments = append(m.segments, &outputSegment{markdown: markdown})
+	}
+	seg := m.segments[len(m.segments)-1]
+	seg.text.WriteString(text)
+	seg.stale = true
+}
+
+// content returns the transcript as shown in the viewport.
+func (m *Model) content() string {
+	var b strings.Builder
+	for _, seg := range m.segments {
+		if seg.command != nil {
+			b.WriteString(seg.command.render(m.theme, m.expandCommands, keyLabel(m.keymap.CommandOutput)))
+			continue
+		}
+		if !seg.markdown || !m.markdown {
+			b.WriteString(seg.text.String())
+			continue
+		}
+		if seg.stale {
+			seg.rendered = RenderMarkdown(seg.text.String(), m.theme)
+			seg.stale = false
+		}
+		b.WriteString(seg.rendered)
+	}
+	return b.String()
+}
+
 // handleEvent processes Claude session events and returns any resulting commands.
 func handleEvent(m *Model, msg eventMsg) []tea.Cmd {
 	var cmds []tea.Cmd
 
+	// Any other event means the session is moving again.
+	if _, ok := msg.event.(claude.EventRateLimited); !ok && m.rateLimited {
+		m.rateLimited = false
+		m.statusbar.State = stateStreaming
+	}
+
 	switch evt := msg.event.(type) {
+	case claude.EventRateLimited:
+		wait := evt.Wait.Round(time.Second)
+		m.rateLimited = true
+		m.statusbar.State = fmt.Sprintf("Rate limited, waiting %s", wait)
+		m.transcript.event("rate limited, waiting %s", wait)
+		m.appendOutput(m.theme.WarningStyle().Render(
+			fmt.Sprintf("\n[rate limit] waiting %s before the next request\n", wait)))
+		m.refreshViewport()
+		cmds = append(cmds, m.waitForEvent())
+
+	case claude.EventModelFallback:
+		m.statusbar.Model = evt.To
+		m.transcript.event("%s overloaded, switching to %s", evt.From, evt.To)
+		m.appendOutput(m.theme.WarningStyle().Render(
+			fmt.Sprintf("\n[model] %s is overloaded; switching to %s\n", evt.From, evt.To)))
+		m.refreshViewport()
+		cmds = append(cmds, m.waitForEvent())
+
+	case claude.EventInjected:
+		m.transcript.event("hydra: %s", evt.Text)
+		m.appendOutput(m.theme.WarningStyle().Render(
+			fmt.Sprintf("\n[hydra] %s\n", evt.Text)))
+		m.refreshViewport()
+		cmds = append(cmds, m.waitForEvent())
+
+	case claude.EventUsage:
+		m.statusbar.TokensIn = evt.InputTokens
+		m.statusbar.TokensOut = evt.OutputTokens
+		m.statusbar.Context = evt.ContextTokens
+		cmds = append(cmds, m.waitForEvent())
+
 	case claude.EventText:
-		m.output.WriteString(evt.Text)
-		m.viewport.SetContent(m.output.String())
-		m.viewport.GotoBottom()
+		m.appendText(evt.Text)
+		m.refreshViewport()
 		cmds = append(cmds, m.waitForEvent())
 
 	case claude.EventThinking:
-		m.output.WriteString(m.theme.MutedStyle().Render(evt.Text))
-		m.viewport.SetContent(m.output.String())
-		m.viewport.GotoBottom()
+		m.appendOutput(m.theme.MutedStyle().Render(evt.Text))
+		m.refreshViewport()
 		cmds = append(cmds, m.waitForEvent())
 
 	case claude.EventToolRequest:
-		if m.autoAccept || !claude.NeedsApproval(evt.Name) {
+		m.statusbar.ToolCalls++
+		action := claude.DecideApproval(m.rules, evt)
+		switch {
+		case action == claude.ApprovalDeny:
+			m.session.ToolAnswer <- claude.ToolAnswer{
+				ID:       evt.ID,
+				Approved: false,
+				Reason:   claude.DeniedByRule,
+			}
+			m.transcript.event("tool denied by rule %s: %s", evt.Name, toolSummary(evt))
+			m.appendOutput(m.theme.WarningStyle().Render(
+				fmt.Sprintf("\n[denied] %s: %s\n", evt.Name, toolSummary(evt))))
+			m.refreshViewport()
+			cmds = append(cmds, m.waitForEvent())
+		case action == claude.ApprovalAllow || (action == "" && (m.autoAccept || !claude.NeedsApproval(evt.Name))):
 			// Auto-approve.
 			m.session.ToolAnswer <- claude.ToolAnswer{
 				ID:       evt.ID,
 				Approved: true,
 			}
-			m.output.WriteString(m.theme.MutedStyle().Render(
+			m.transcript.event("tool auto-approved %s: %s", evt.Name, toolSummary(evt))
+			m.appendOutput(m.theme.MutedStyle().Render(
 				fmt.Sprintf("\n[auto] %s: %s\n", evt.Name, toolSummary(evt))))
-			m.viewport.SetContent(m.output.String())
-			m.viewport.GotoBottom()
+			m.refreshViewport()
 			cmds = append(cmds, m.waitForEvent())
-		} else {
+		default:
+			m.transcript.event("tool requested %s: %s", evt.Name, toolSummary(evt))
 			m.state = StateAwaitingApproval
 			m.statusbar.State = "Awaiting Approval"
 			m.approval = &ApprovalDialog{
-				Request:  evt,
-				Selected: 0,
-				Theme:    m.theme,
-				Width:    m.width,
+				Request:    evt,
+				Selected:   0,
+				Theme:      m.theme,
+				Width:      m.width,
+				DiffHeight: diffHeight(m.height),
 			}
 			cmds = append(cmds, m.waitForEvent())
 		}
@@ -219,27 +460,29 @@ func handleEvent(m *Model, msg eventMsg) []tea.Cmd {
 		if evt.IsError {
 			prefix = m.theme.ErrorStyle().Render("[err]")
 		}
-		fmt.Fprintf(&m.output, "\n%s %s\n", prefix, truncate(evt.Content, 200))
-		m.viewport.SetContent(m.output.String())
-		m.viewport.GotoBottom()
+		m.transcript.event("tool result (error=%t): %s", evt.IsError, truncate(evt.Content, 200))
+		m.appendOutput(fmt.Sprintf("\n%s %s\n", prefix, truncate(evt.Content, 200)))
+		m.refreshViewport()
 		cmds = append(cmds, m.waitForEvent())
 
 	case claude.EventDone:
+		m.statusbar.Elapsed = time.Since(m.started)
 		m.state = StateCompleted
 		m.statusbar.State = "Completed"
-		m.output.WriteString(m.theme.SuccessStyle().Render(
+		m.transcript.event("session done: %s", evt.StopReason)
+		m.appendOutput(m.theme.SuccessStyle().Render(
 			fmt.Sprintf("\n\nSession complete (%s). Press Enter to exit.\n", evt.StopReason)))
-		m.viewport.SetContent(m.output.String())
-		m.viewport.GotoBottom()
+		m.refreshViewport()
 
 	case claude.EventError:
+		m.statusbar.Elapsed = time.Since(m.started)
 		m.state = StateError
 		m.statusbar.State = "Error"
 		m.err = evt.Err
-		m.output.WriteString(m.theme.ErrorStyle().Render(
+		m.transcript.event("session error: %v", evt.Err)
+		m.appendOutput(m.theme.ErrorStyle().Render(
 			fmt.Sprintf("\n\nError: %v\nPress Enter to exit.\n", evt.Err)))
-		m.viewport.SetContent(m.output.String())
-		m.viewport.GotoBottom()
+		m.refreshViewport()
 	}
 
 	return cmds
@@ -261,17 +504,33 @@ func (m Model) View() string {
 		sections = append(sections, m.approval.View())
 	}
 
-	// Status bar.
-	sections = append(sections, m.statusbar.View())
+	// Status bar, replaced by the search prompt while typing a query.
+	if m.searching {
+		sections = append(sections, m.searchInput.View())
+	} else {
+		sections = append(sections, m.statusbar.View())
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
+// Transcript returns the session transcript, which is shared by all copies of
+// the model and remains readable after the TUI exits or panics.
+func (m Model) Transcript() *Transcript {
+	return m.transcript
+}
+
 // Err returns any error that occurred during the session.
 func (m Model) Err() error {
 	return m.err
 }
 
+// diffHeight returns how many diff lines the approval dialog shows at once
+// for a terminal of the given height: about half the screen.
+func diffHeight(termHeight int) int {
+	return max(termHeight/2, 5)
+}
+
 func toolSummary(evt claude.EventToolRequest) string {
 	switch evt.Meta.Kind {
 	case claude.ToolKindRead, claude.ToolKindList, claude.ToolKindSearch:
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/erikh/hydra/internal/claude"
@@ -78,6 +79,41 @@ func TestHandleEventThinking(t *testing.T) {
 	}
 }
 
+func TestHandleEventRateLimited(t *testing.T) {
+	m, _ := newTestModel(false)
+
+	cmds := handleEvent(&m, eventMsg{event: claude.EventRateLimited{Wait: 12400 * time.Millisecond}})
+	if len(cmds) == 0 {
+		t.Error("expected command to wait for next event")
+	}
+	if m.statusbar.State != "Rate limited, waiting 12s" {
+		t.Errorf("status = %q, want rate limit wait", m.statusbar.State)
+	}
+	if !strings.Contains(m.output.String(), "[rate limit] waiting 12s") {
+		t.Errorf("output should note the wait, got %q", m.output.String())
+	}
+
+	handleEvent(&m, eventMsg{event: claude.EventText{Text: "resumed"}})
+	if m.statusbar.State != stateStreaming {
+		t.Errorf("status after resuming = %q, want %q", m.statusbar.State, stateStreaming)
+	}
+}
+
+func TestHandleEventModelFallback(t *testing.T) {
+	m, _ := newTestModel(false)
+
+	cmds := handleEvent(&m, eventMsg{event: claude.EventModelFallback{From: "opus", To: "sonnet"}})
+	if len(cmds) == 0 {
+		t.Error("expected command to wait for next event")
+	}
+	if m.statusbar.Model != "sonnet" {
+		t.Errorf("status bar model = %q, want sonnet", m.statusbar.Model)
+	}
+	if !strings.Contains(m.output.String(), "opus is overloaded; switching to sonnet") {
+		t.Errorf("output should note the switch, got %q", m.output.String())
+	}
+}
+
 func TestHandleEventToolRequestAutoAccept(t *testing.T) {
 	m, answers := newTestModel(true)
 
@@ -151,6 +187,52 @@ func TestHandleEventToolRequestNeedsApproval(t *testing.T) {
 	}
 }
 
+func TestHandleEventToolRequestRules(t *testing.T) {
+	rules := []claude.ApprovalRule{
+		{Tools: []string{"bash"}, Command: `git\s+push`, Action: claude.ApprovalPrompt},
+		{Tools: []string{"bash"}, Command: `^rm\s`, Action: claude.ApprovalDeny},
+		{Tools: []string{"write", "edit"}, Path: "src/", Action: claude.ApprovalAllow},
+	}
+	tests := []struct {
+		name       string
+		autoAccept bool
+		tool       string
+		meta       claude.ToolMeta
+		want       string // "approve", "deny", or "prompt"
+	}{
+		{"allowed write", false, "write_file", claude.ToolMeta{Kind: claude.ToolKindWrite, RelPath: "src/a.go"}, "approve"},
+		{"unmatched write", false, "write_file", claude.ToolMeta{Kind: claude.ToolKindWrite, RelPath: "a.go"}, "prompt"},
+		{"denied bash", true, "bash", claude.ToolMeta{Kind: claude.ToolKindBash, Command: "rm -rf build"}, "deny"},
+		{"prompted bash with auto-accept", true, "bash", claude.ToolMeta{Kind: claude.ToolKindBash, Command: "git push origin x"}, "prompt"},
+		{"unmatched bash with auto-accept", true, "bash", claude.ToolMeta{Kind: claude.ToolKindBash, Command: "go test"}, "approve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, answers := newTestModel(tt.autoAccept)
+			m.SetApprovalRules(rules)
+			handleEvent(&m, eventMsg{event: claude.EventToolRequest{ID: "t", Name: tt.tool, Meta: tt.meta}})
+
+			got := "prompt"
+			select {
+			case answer := <-answers:
+				got = "deny"
+				if answer.Approved {
+					got = "approve"
+				} else if answer.Reason != claude.DeniedByRule {
+					t.Errorf("reason = %q, want DeniedByRule", answer.Reason)
+				}
+			default:
+				if m.state != StateAwaitingApproval {
+					t.Errorf("no answer, but state = %d", m.state)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHandleEventToolResult(t *testing.T) {
 	m, _ := newTestModel(false)
 
@@ -210,6 +292,44 @@ func TestHandleEventError(t *testing.T) {
 	}
 }
 
+func TestHandleEventUsageAndToolCalls(t *testing.T) {
+	m, answers := newTestModel(true)
+
+	cmds := handleEvent(&m, eventMsg{event: claude.EventUsage{InputTokens: 1500, OutputTokens: 200, ContextTokens: 1700}})
+	if len(cmds) != 1 {
+		t.Errorf("EventUsage should keep waiting for events, got %d commands", len(cmds))
+	}
+	if m.statusbar.TokensIn != 1500 || m.statusbar.TokensOut != 200 || m.statusbar.Context != 1700 {
+		t.Errorf("status bar usage = %+v", m.statusbar)
+	}
+
+	handleEvent(&m, eventMsg{event: claude.EventToolRequest{ID: "1", Name: "read_file"}})
+	handleEvent(&m, eventMsg{event: claude.EventToolRequest{ID: "2", Name: "read_file"}})
+	<-answers
+	<-answers
+	if m.statusbar.ToolCalls != 2 {
+		t.Errorf("ToolCalls = %d, want 2", m.statusbar.ToolCalls)
+	}
+}
+
+func TestTickStopsWhenSessionEnds(t *testing.T) {
+	m, _ := newTestModel(false)
+	m.started = time.Now().Add(-90 * time.Second)
+
+	updated, cmd := m.Update(tickMsg(time.Now()))
+	m = updated.(Model)
+	if m.statusbar.Elapsed < 90*time.Second || cmd == nil {
+		t.Errorf("tick should update elapsed (%s) and schedule the next tick", m.statusbar.Elapsed)
+	}
+
+	handleEvent(&m, eventMsg{event: claude.EventDone{StopReason: "end_turn"}})
+	elapsed := m.statusbar.Elapsed
+	updated, _ = m.Update(tickMsg(time.Now().Add(time.Hour)))
+	if updated.(Model).statusbar.Elapsed != elapsed {
+		t.Error("elapsed time should stop once the session is done")
+	}
+}
+
 func TestUpdateToggleAutoAccept(t *testing.T) {
 	m, _ := newTestModel(false)
 
@@ -271,6 +391,36 @@ func TestUpdateAutoAcceptApprovesCurrentDialog(t *testing.T) {
 	}
 }
 
+func TestUpdateAutoAcceptKeepsPromptRuleDialog(t *testing.T) {
+	m, answers := newTestModel(false)
+	m.SetApprovalRules([]claude.ApprovalRule{{Command: "git push", Action: claude.ApprovalPrompt}})
+	m.state = StateAwaitingApproval
+	m.approval = &ApprovalDialog{
+		Request: claude.EventToolRequest{
+			ID:   "tool-6",
+			Name: "bash",
+			Meta: claude.ToolMeta{Kind: claude.ToolKindBash, Command: "git push"},
+		},
+		Theme: m.theme,
+		Width: m.width,
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updated.(Model) //nolint:forcetypeassert // test
+
+	if !m.autoAccept {
+		t.Error("auto-accept should be on")
+	}
+	if m.state != StateAwaitingApproval || m.approval == nil {
+		t.Error("a prompt rule should keep the dialog open")
+	}
+	select {
+	case answer := <-answers:
+		t.Errorf("unexpected answer %+v", answer)
+	default:
+	}
+}
+
 func TestUpdateApproveToolEnter(t *testing.T) {
 	m, answers := newTestModel(false)
 
@@ -356,6 +506,44 @@ func TestUpdateNavigateButtons(t *testing.T) {
 	}
 }
 
+func TestUpdateScrollsApprovalDiff(t *testing.T) {
+	m, _ := newTestModel(false)
+
+	var lines []string
+	for i := range 40 {
+		lines = append(lines, fmt.Sprintf("+line-%d", i))
+	}
+	m.state = StateAwaitingApproval
+	m.approval = &ApprovalDialog{
+		Request: claude.EventToolRequest{
+			ID:   "tool-9",
+			Name: "write_file",
+			Meta: claude.ToolMeta{Kind: claude.ToolKindWrite, Path: "f.txt", Diff: strings.Join(lines, "\n")},
+		},
+		Theme:      m.theme,
+		Width:      m.width,
+		DiffHeight: diffHeight(m.height),
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model) //nolint:forcetypeassert // test
+	if m.approval.DiffOffset != 1 {
+		t.Errorf("down should scroll the diff by one line, offset = %d", m.approval.DiffOffset)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	m = updated.(Model) //nolint:forcetypeassert // test
+	if want := 1 + m.approval.DiffHeight; m.approval.DiffOffset != want {
+		t.Errorf("pgdown offset = %d, want %d", m.approval.DiffOffset, want)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	m = updated.(Model) //nolint:forcetypeassert // test
+	if m.approval.DiffOffset != 1 {
+		t.Errorf("pgup offset = %d, want 1", m.approval.DiffOffset)
+	}
+}
+
 func TestUpdateEnterWithRejectSelected(t *testing.T) {
 	m, answers := newTestModel(false)
 
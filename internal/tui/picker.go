@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ErrPickerCancelled is returned by Pick when the user quits without
+// choosing an item.
+var ErrPickerCancelled = errors.New("no item selected")
+
+// PickerItem is one entry in a Picker.
+type PickerItem struct {
+	Label   string // shown in the list and returned when chosen
+	Preview string // shown in the side pane while the item is highlighted
+}
+
+// Picker is a full-screen fuzzy finder over a list of items, with a preview
+// of the highlighted item in a side pane. Typing filters the list; up/down
+// move the selection, pgup/pgdown scroll the preview, enter chooses, and
+// esc or ctrl+c cancels.
+type Picker struct {
+	title   string
+	items   []PickerItem
+	theme   Theme
+	input   textinput.Model
+	preview viewport.Model
+	matches []int // indices into items, best match first
+	cursor  int
+	width   int
+	height  int
+	chosen  int // index into items, or -1
+}
+
+// NewPicker returns a picker over items with all of them listed.
+func NewPicker(title string, items []PickerItem, theme Theme) *Picker {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "type to filter"
+	input.Focus()
+
+	p := &Picker{
+		title:   title,
+		items:   items,
+		theme:   theme,
+		input:   input,
+		preview: viewport.New(0, 0),
+		chosen:  -1,
+	}
+	p.filter()
+	return p
+}
+
+// Chosen returns the label of the chosen item, or "" if none was chosen.
+func (p *Picker) Chosen() string {
+	if p.chosen < 0 {
+		return ""
+	}
+	return p.items[p.chosen].Label
+}
+
+// Init implements tea.Model.
+func (p *Picker) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+func (p *Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+		p.preview.Width = max(p.width-p.listWidth()-3, 0)
+		p.preview.Height = max(p.height-2, 0)
+		p.updatePreview()
+		return p, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return p, tea.Quit
+		case "enter":
+			if len(p.matches) > 0 {
+				p.chosen = p.matches[p.cursor]
+			}
+			return p, tea.Quit
+		case "up", "ctrl+p", "ctrl+k":
+			p.moveCursor(-1)
+			return p, nil
+		case "down", "ctrl+n", "ctrl+j":
+			p.moveCursor(1)
+			return p, nil
+		case "pgup":
+			p.preview.HalfPageUp()
+			return p, nil
+		case "pgdown":
+			p.preview.HalfPageDown()
+			return p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	query := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != query {
+		p.filter()
+	}
+	return p, cmd
+}
+
+// moveCursor moves the selection by delta, clamped to the matches.
+func (p *Picker) moveCursor(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = min(max(p.cursor+delta, 0), len(p.matches)-1)
+	p.updatePreview()
+}
+
+// filter recomputes the matches for the current query and resets the
+// selection to the best match.
+func (p *Picker) filter() {
+	labels := make([]string, len(p.items))
+	for i, item := range p.items {
+		labels[i] = item.Label
+	}
+	p.matches = fuzzyFilter(p.input.Value(), labels)
+	p.cursor = 0
+	p.updatePreview()
+}
+
+// updatePreview shows the highlighted item's preview in the side pane.
+func (p *Picker) updatePreview() {
+	content := ""
+	if len(p.matches) > 0 {
+		content = p.items[p.matches[p.cursor]].Preview
+	}
+	if p.preview.Width > 0 {
+		content = lipgloss.NewStyle().Width(p.preview.Width).Render(content)
+	}
+	p.preview.SetContent(content)
+	p.preview.GotoTop()
+}
+
+// listWidth is the width of the list column: wide enough for the longest
+// label, but never more than half the screen.
+func (p *Picker) listWidth() int {
+	w := 20
+	for _, item := range p.items {
+		w = max(w, lipgloss.Width(item.Label)+2)
+	}
+	return min(w, p.width/2)
+}
+
+// View implements tea.Model.
+func (p *Picker) View() string {
+	if p.width == 0 {
+		return ""
+	}
+	width := p.listWidth()
+
+	var b strings.Builder
+	b.WriteString(p.theme.AccentStyle().Bold(true).Render(p.title) + "\n")
+	b.WriteString(p.input.View() + "\n")
+
+	// Keep the cursor in the visible window of the list.
+	rows := max(p.height-3, 1)
+	start := max(p.cursor-rows+1, 0)
+	end := min(start+rows, len(p.matches))
+	for i := start; i < end; i++ {
+		label := ansi.Truncate(p.items[p.matches[i]].Label, max(width-2, 0), "…")
+		if i == p.cursor {
+			b.WriteString(p.theme.HighlightStyle().Bold(true).Render("> "+label) + "\n")
+		} else {
+			b.WriteString("  " + p.theme.TextStyle().Render(label) + "\n")
+		}
+	}
+	if len(p.matches) == 0 {
+		b.WriteString(p.theme.MutedStyle().Render("  no matches") + "\n")
+	}
+	b.WriteString(p.theme.MutedStyle().Render(ansi.Truncate("enter select · esc cancel · pgup/pgdown scroll preview", width, "…")))
+
+	list := lipgloss.NewStyle().Width(width).Height(p.height).Render(b.String())
+	sep := p.theme.MutedStyle().Render(strings.Repeat("│\n", max(p.height-1, 0)) + "│")
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, " ", sep, " ", p.preview.View())
+}
+
+// Pick shows a full-screen picker over items and returns the label of the
+// chosen item, or ErrPickerCancelled if the user quits without choosing.
+func Pick(title string, items []PickerItem) (string, error) {
+	p := NewPicker(title, items, LoadTheme())
+	if _, err := tea.NewProgram(p, tea.WithAltScreen()).Run(); err != nil {
+		return "", err
+	}
+	if p.Chosen() == "" {
+		return "", ErrPickerCancelled
+	}
+	return p.Chosen(), nil
+}
+
+// fuzzyFilter returns the indices of the candidates that contain the
+// characters of query in order, best match first. Ties keep the original
+// order, and an empty query matches everything. Matching is
+// case-insensitive unless the query contains an upper-case letter.
+func fuzzyFilter(query string, candidates []string) []int {
+	type scored struct {
+		index, score int
+	}
+	var hits []scored
+	for i, c := range candidates {
+		if score, ok := fuzzyScore(query, c); ok {
+			hits = append(hits, scored{i, score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+
+	matches := make([]int, len(hits))
+	for i, h := range hits {
+		matches[i] = h.index
+	}
+	return matches
+}
+
+// fuzzyScore reports whether the characters of query appear in s in order,
+// and scores the best such match: consecutive characters and characters at
+// the start of a word (after /, -, _, a space, or at the beginning) score
+// higher, and characters skipped between matches cost a little.
+func fuzzyScore(query, s string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		s = strings.ToLower(s)
+	}
+
+	q, runes := []rune(query), []rune(s)
+	best, found := 0, false
+	for start, r := range runes {
+		if r != q[0] {
+			continue
+		}
+		if score, ok := fuzzyScoreFrom(q, runes, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// fuzzyScoreFrom scores the match of q in runes that starts at start and
+// takes each later character at its first occurrence.
+func fuzzyScoreFrom(q, runes []rune, start int) (int, bool) {
+	score, qi, last := 0, 0, -1
+	for i := start; i < len(runes) && qi < len(q); i++ {
+		if runes[i] != q[qi] {
+			continue
+		}
+		score++
+		switch {
+		case last >= 0 && i == last+1:
+			score += 5
+		case i == 0 || strings.ContainsRune("/-_ ", runes[i-1]):
+			score += 3
+		}
+		if last >= 0 {
+			score -= min(i-last-1, 3)
+		}
+		last = i
+		qi++
+	}
+	return score, qi == len(q)
+}
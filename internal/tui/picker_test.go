@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyFilter(t *testing.T) {
+	candidates := []string{"backend/add-api", "add-feature", "frontend/fix-layout", "another-task"}
+
+	if got := fuzzyFilter("", candidates); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("empty query = %v, want every candidate in order", got)
+	}
+	if got := fuzzyFilter("adfe", candidates); !slices.Equal(got, []int{1}) {
+		t.Errorf("adfe = %v, want [1]", got)
+	}
+	if got := fuzzyFilter("zzz", candidates); len(got) != 0 {
+		t.Errorf("zzz = %v, want no matches", got)
+	}
+
+	// A consecutive match beats a scattered one.
+	got := fuzzyFilter("fix", []string{"f-i-x", "frontend/fix-layout"})
+	if !slices.Equal(got, []int{1, 0}) {
+		t.Errorf("fix = %v, want the consecutive match first", got)
+	}
+
+	if got := fuzzyFilter("API", candidates); len(got) != 0 {
+		t.Errorf("upper-case query should match case-sensitively, got %v", got)
+	}
+}
+
+func pickerPress(t *testing.T, p *Picker, msgs ...tea.KeyMsg) *Picker {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, _ := p.Update(msg)
+		p = updated.(*Picker) //nolint:forcetypeassert // test
+	}
+	return p
+}
+
+func TestPickerFilterAndChoose(t *testing.T) {
+	p := NewPicker("Pick a task", []PickerItem{
+		{Label: "add-feature", Preview: "Add the feature."},
+		{Label: "backend/add-api", Preview: "Build API."},
+		{Label: "backend/add-db", Preview: "Add database layer."},
+	}, DefaultTheme())
+	p.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+
+	p = pickerPress(t, p, runes("back"))
+	if len(p.matches) != 2 {
+		t.Fatalf("matches for back = %v, want 2", p.matches)
+	}
+	if !strings.Contains(p.View(), "Build API.") {
+		t.Error("preview should show the highlighted task")
+	}
+
+	p = pickerPress(t, p, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if !strings.Contains(p.View(), "Add database layer.") {
+		t.Error("preview should follow the selection")
+	}
+
+	p = pickerPress(t, p, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := p.Chosen(); got != "backend/add-db" {
+		t.Errorf("Chosen = %q, want backend/add-db", got)
+	}
+}
+
+func TestPickerCancel(t *testing.T) {
+	p := NewPicker("Pick a task", []PickerItem{{Label: "add-feature"}}, DefaultTheme())
+	p = pickerPress(t, p, tea.KeyMsg{Type: tea.KeyEsc})
+	if got := p.Chosen(); got != "" {
+		t.Errorf("Chosen after esc = %q, want empty", got)
+	}
+
+	p = NewPicker("Pick a task", []PickerItem{{Label: "add-feature"}}, DefaultTheme())
+	p = pickerPress(t, p, runes("zzz"), tea.KeyMsg{Type: tea.KeyEnter})
+	if got := p.Chosen(); got != "" {
+		t.Errorf("Chosen with no matches = %q, want empty", got)
+	}
+}
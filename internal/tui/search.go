@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatch is one occurrence of the search query in the transcript.
+type searchMatch struct {
+	line       int // transcript line index
+	start, end int // byte range within the line, ANSI codes stripped
+}
+
+// findMatches returns every occurrence of query in lines, which must be free
+// of ANSI codes. Matching is case-insensitive unless the query contains an
+// upper-case letter.
+func findMatches(lines []string, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	fold := !strings.ContainsFunc(query, unicode.IsUpper)
+	if fold {
+		query = strings.ToLower(query)
+	}
+
+	var matches []searchMatch
+	for i, line := range lines {
+		if fold {
+			// Keep byte offsets valid: only fold lines whose length is unchanged.
+			if lower := strings.ToLower(line); len(lower) == len(line) {
+				line = lower
+			}
+		}
+		for off := 0; ; {
+			idx := strings.Index(line[off:], query)
+			if idx < 0 {
+				break
+			}
+			start := off + idx
+			matches = append(matches, searchMatch{line: i, start: start, end: start + len(query)})
+			off = start + len(query)
+		}
+	}
+	return matches
+}
+
+// transcriptLines returns the transcript split into lines, with and without
+// ANSI styling.
+func (m *Model) transcriptLines() (styled, plain []string) {
+	styled = strings.Split(m.content(), "\n")
+	plain = make([]string, len(styled))
+	for i, line := range styled {
+		plain[i] = ansi.Strip(line)
+	}
+	return styled, plain
+}
+
+// browsing reports whether the user is searching or selecting text, in which
+// case new output must not yank the viewport to the bottom.
+func (m *Model) browsing() bool {
+	return m.searching || m.query != "" || m.copyMode
+}
+
+// refreshViewport re-renders the transcript into the viewport, applying
+// search highlights and the copy-mode selection, and follows new output
+// unless the user is browsing.
+func (m *Model) refreshViewport() {
+	if !m.browsing() {
+		m.viewport.SetContent(m.content())
+		m.viewport.GotoBottom()
+		m.updateModeStatus()
+		return
+	}
+
+	styled, plain := m.transcriptLines()
+	if m.query != "" {
+		m.matches = findMatches(plain, m.query)
+		m.matchIdx = min(m.matchIdx, max(len(m.matches)-1, 0))
+	}
+
+	byLine := make(map[int][]int)
+	for i, match := range m.matches {
+		byLine[match.line] = append(byLine[match.line], i)
+	}
+
+	matchStyle := lipgloss.NewStyle().Background(m.theme.Highlight).Foreground(m.theme.Bg)
+	currentStyle := lipgloss.NewStyle().Background(m.theme.Warning).Foreground(m.theme.Bg).Bold(true)
+	selectStyle := lipgloss.NewStyle().Reverse(true)
+	selStart, selEnd := m.selection()
+
+	lines := make([]string, len(styled))
+	for i := range styled {
+		switch {
+		case m.copyMode && i >= selStart && i <= selEnd:
+			lines[i] = selectStyle.Render(plain[i] + " ")
+		case len(byLine[i]) > 0:
+			var b strings.Builder
+			prev := 0
+			for _, idx := range byLine[i] {
+				match := m.matches[idx]
+				style := matchStyle
+				if idx == m.matchIdx {
+					style = currentStyle
+				}
+				b.WriteString(plain[i][prev:match.start])
+				b.WriteString(style.Render(plain[i][match.start:match.end]))
+				prev = match.end
+			}
+			b.WriteString(plain[i][prev:])
+			lines[i] = b.String()
+		default:
+			lines[i] = styled[i]
+		}
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	m.updateModeStatus()
+}
+
+// showLine scrolls the viewport so line is visible, centering it if needed.
+func (m *Model) showLine(line int) {
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/2)
+	}
+}
+
+// selection returns the first and last transcript lines of the copy-mode
+// selection. Without a mark, the selection is the cursor line.
+func (m *Model) selection() (int, int) {
+	if m.copyAnchor < 0 {
+		return m.copyCursor, m.copyCursor
+	}
+	return min(m.copyAnchor, m.copyCursor), max(m.copyAnchor, m.copyCursor)
+}
+
+// updateModeStatus shows the search or copy-mode state in the status bar.
+func (m *Model) updateModeStatus() {
+	switch {
+	case m.copyMode:
+		m.statusbar.Mode = "COPY: up/down move, space mark, y yank, esc exit"
+	case m.query != "" && len(m.matches) == 0:
+		m.statusbar.Mode = fmt.Sprintf("/%s: no matches", m.query)
+	case m.query != "":
+		m.statusbar.Mode = fmt.Sprintf("/%s: %d/%d (n/N, esc clears)", m.query, m.matchIdx+1, len(m.matches))
+	default:
+		m.statusbar.Mode = m.notice
+	}
+}
+
+// startSearch opens the search prompt.
+func (m *Model) startSearch() tea.Cmd {
+	m.searching = true
+	m.searchInput.SetValue("")
+	return m.searchInput.Focus()
+}
+
+// handleSearchKey handles a key while the search prompt is open.
+func (m *Model) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.Type == tea.KeyEnter:
+		m.searching = false
+		m.searchInput.Blur()
+		m.query = m.searchInput.Value()
+		m.matches = nil
+		m.matchIdx = 0
+		m.refreshViewport()
+		m.jumpToMatch(m.firstMatchFrom(m.viewport.YOffset))
+		return nil
+	case key.Matches(msg, m.keymap.Cancel):
+		m.searching = false
+		m.searchInput.Blur()
+		m.refreshViewport()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+// firstMatchFrom returns the index of the first match at or below line,
+// wrapping to the first match.
+func (m *Model) firstMatchFrom(line int) int {
+	for i, match := range m.matches {
+		if match.line >= line {
+			return i
+		}
+	}
+	return 0
+}
+
+// jumpToMatch makes match idx current and scrolls to it.
+func (m *Model) jumpToMatch(idx int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIdx = (idx + len(m.matches)) % len(m.matches)
+	m.refreshViewport()
+	m.showLine(m.matches[m.matchIdx].line)
+}
+
+// clearSearch removes the search highlights and resumes following output.
+func (m *Model) clearSearch() {
+	m.query = ""
+	m.matches = nil
+	m.matchIdx = 0
+	m.statusbar.Mode = ""
+	m.refreshViewport()
+}
+
+// startCopyMode enters copy mode with the cursor at the top visible line.
+func (m *Model) startCopyMode() {
+	m.copyMode = true
+	m.copyCursor = m.viewport.YOffset
+	m.copyAnchor = -1
+	m.notice = ""
+	m.refreshViewport()
+}
+
+// handleCopyKey handles a key in copy mode.
+func (m *Model) handleCopyKey(msg tea.KeyMsg) {
+	_, plain := m.transcriptLines()
+	last := len(plain) - 1
+
+	switch {
+	case key.Matches(msg, m.keymap.ScrollUp):
+		m.copyCursor = max(m.copyCursor-1, 0)
+	case key.Matches(msg, m.keymap.ScrollDown):
+		m.copyCursor = min(m.copyCursor+1, last)
+	case key.Matches(msg, m.keymap.PageUp):
+		m.copyCursor = max(m.copyCursor-m.viewport.Height, 0)
+	case key.Matches(msg, m.keymap.PageDown):
+		m.copyCursor = min(m.copyCursor+m.viewport.Height, last)
+	case key.Matches(msg, m.keymap.Mark):
+		if m.copyAnchor < 0 {
+			m.copyAnchor = m.copyCursor
+		} else {
+			m.copyAnchor = -1
+		}
+	case key.Matches(msg, m.keymap.Yank):
+		start, end := m.selection()
+		copyToClipboard(strings.Join(plain[start:end+1], "\n"))
+		m.copyMode = false
+		m.notice = fmt.Sprintf("Copied %d line(s) to clipboard", end-start+1)
+	case key.Matches(msg, m.keymap.Cancel):
+		m.copyMode = false
+		m.notice = ""
+	}
+
+	m.refreshViewport()
+	if m.copyMode {
+		m.showLine(m.copyCursor)
+	}
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFindMatches(t *testing.T) {
+	lines := []string{"Hello world", "no hits", "hello hello"}
+
+	matches := findMatches(lines, "hello")
+	if len(matches) != 3 {
+		t.Fatalf("smart-case search found %d matches, want 3: %+v", len(matches), matches)
+	}
+	if matches[2] != (searchMatch{line: 2, start: 6, end: 11}) {
+		t.Errorf("third match = %+v", matches[2])
+	}
+
+	if matches := findMatches(lines, "Hello"); len(matches) != 1 || matches[0].line != 0 {
+		t.Errorf("upper-case query should match case-sensitively, got %+v", matches)
+	}
+	if matches := findMatches(lines, ""); matches != nil {
+		t.Errorf("empty query should match nothing, got %+v", matches)
+	}
+}
+
+// newBrowseModel returns a test model showing a transcript of n numbered lines.
+func newBrowseModel(n int) Model {
+	m, _ := newTestModel(false)
+	m.viewport = viewport.New(80, 10)
+	for i := range n {
+		if i > 0 {
+			m.appendOutput("\n")
+		}
+		m.appendOutput("line ")
+		m.appendOutput(strings.Repeat("x", i%3))
+		if i == 5 || i == 40 {
+			m.appendOutput(" needle")
+		}
+	}
+	m.refreshViewport()
+	return m
+}
+
+func press(t *testing.T, m Model, msgs ...tea.KeyMsg) Model {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		m = updated.(Model) //nolint:forcetypeassert // test
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSearchNavigatesMatches(t *testing.T) {
+	m := newBrowseModel(50)
+	m.viewport.GotoTop()
+
+	m = press(t, m, runes("/"))
+	if !m.searching {
+		t.Fatal("/ should open the search prompt")
+	}
+	m = press(t, m, runes("needle"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.searching || m.query != "needle" || len(m.matches) != 2 {
+		t.Fatalf("searching=%v query=%q matches=%d", m.searching, m.query, len(m.matches))
+	}
+	if m.matchIdx != 0 {
+		t.Errorf("first match should be current, got %d", m.matchIdx)
+	}
+	if !strings.Contains(m.statusbar.View(), "1/2") {
+		t.Errorf("status bar should show match position: %s", m.statusbar.View())
+	}
+
+	m = press(t, m, runes("n"))
+	if m.matchIdx != 1 {
+		t.Errorf("n should advance to match 1, got %d", m.matchIdx)
+	}
+	if line := m.matches[1].line; line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		t.Errorf("match line %d not visible at offset %d", line, m.viewport.YOffset)
+	}
+
+	m = press(t, m, runes("N"), runes("N"))
+	if m.matchIdx != 1 {
+		t.Errorf("N should wrap around, got %d", m.matchIdx)
+	}
+
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEscape})
+	if m.query != "" || m.matches != nil {
+		t.Error("esc should clear the search")
+	}
+}
+
+func TestSearchPromptUsesCancelBinding(t *testing.T) {
+	m := newBrowseModel(5)
+	m.keymap.Cancel = key.NewBinding(key.WithKeys("ctrl+g"))
+
+	m = press(t, m, runes("/"), tea.KeyMsg{Type: tea.KeyEscape})
+	if !m.searching {
+		t.Error("esc should not close the prompt once cancel is rebound")
+	}
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.searching {
+		t.Error("the cancel binding should close the search prompt")
+	}
+}
+
+func TestSearchKeysDoNotRejectWithoutDialog(t *testing.T) {
+	m := newBrowseModel(5)
+	m = press(t, m, runes("/"), runes("an"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.autoAccept {
+		t.Error("typing 'a' in the search prompt should not toggle auto-accept")
+	}
+}
+
+func TestCopyModeYanksSelection(t *testing.T) {
+	var copied string
+	prev := copyToClipboard
+	copyToClipboard = func(text string) { copied = text }
+	t.Cleanup(func() { copyToClipboard = prev })
+
+	m := newBrowseModel(20)
+	m.viewport.GotoTop()
+
+	m = press(t, m,
+		runes("v"),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeySpace},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+		runes("y"),
+	)
+
+	if m.copyMode {
+		t.Error("y should leave copy mode")
+	}
+	want := "line x\nline xx\nline "
+	if copied != want {
+		t.Errorf("copied %q, want %q", copied, want)
+	}
+	if !strings.Contains(m.statusbar.View(), "Copied 3 line(s)") {
+		t.Errorf("status bar should confirm the copy: %s", m.statusbar.View())
+	}
+}
+
+func TestCopyModeEscCancels(t *testing.T) {
+	called := false
+	prev := copyToClipboard
+	copyToClipboard = func(string) { called = true }
+	t.Cleanup(func() { copyToClipboard = prev })
+
+	m := newBrowseModel(5)
+	m = press(t, m, runes("v"), tea.KeyMsg{Type: tea.KeyEscape})
+	if m.copyMode || called {
+		t.Errorf("esc should exit copy mode without copying (copyMode=%v, called=%v)", m.copyMode, called)
+	}
+}
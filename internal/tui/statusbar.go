@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -11,8 +12,17 @@ type StatusBar struct {
 	Model      string
 	State      string
 	AutoAccept bool
+	Mode       string // search or copy-mode status; shown when set
+	Hints      string // main keys; the default keys when empty
 	Theme      Theme
 	Width      int
+
+	// Session statistics, updated as events arrive.
+	TokensIn  int64
+	TokensOut int64
+	Context   int64 // tokens in the conversation as of the latest request
+	Elapsed   time.Duration
+	ToolCalls int
 }
 
 // View renders the status bar.
@@ -22,8 +32,19 @@ func (s StatusBar) View() string {
 		autoStr = "ON"
 	}
 
-	content := fmt.Sprintf(" %s | %s | Auto: %s | Ctrl+C quit | a: auto-accept ",
-		s.Model, s.State, autoStr)
+	hints := s.Hints
+	if hints == "" {
+		hints = DefaultKeyMap().statusHints()
+	}
+
+	content := fmt.Sprintf(" %s | %s | %s | Auto: %s ", s.Model, s.State, s.stats(), autoStr)
+	switch {
+	case s.Mode != "":
+		content += "| " + s.Mode + " "
+	case s.Width == 0 || lipgloss.Width(content+hints) <= s.Width:
+		// Key hints are dropped before they would wrap the bar.
+		content += hints
+	}
 
 	style := lipgloss.NewStyle().
 		Background(s.Theme.Accent).
@@ -33,3 +54,23 @@ func (s StatusBar) View() string {
 
 	return style.Render(content)
 }
+
+// stats renders the session statistics: tokens in and out, context size,
+// elapsed time, and tool calls.
+func (s StatusBar) stats() string {
+	return fmt.Sprintf("in %s out %s ctx %s | %s | %d tools",
+		formatTokens(s.TokensIn), formatTokens(s.TokensOut), formatTokens(s.Context),
+		s.Elapsed.Truncate(time.Second), s.ToolCalls)
+}
+
+// formatTokens abbreviates a token count, e.g. 950, 12.3k, or 1.2M.
+func formatTokens(n int64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1_000_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	}
+}
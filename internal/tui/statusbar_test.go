@@ -3,6 +3,7 @@ package tui
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestStatusBarViewContainsFields(t *testing.T) {
@@ -54,3 +55,36 @@ func TestStatusBarShowsState(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusBarShowsSessionStats(t *testing.T) {
+	sb := StatusBar{
+		Model:     "m",
+		State:     "Streaming",
+		TokensIn:  12345,
+		TokensOut: 987,
+		Context:   2_500_000,
+		Elapsed:   3*time.Minute + 12*time.Second + 400*time.Millisecond,
+		ToolCalls: 7,
+		Theme:     DefaultTheme(),
+		Width:     200,
+	}
+
+	view := sb.View()
+	for _, want := range []string{"in 12.3k out 987 ctx 2.5M", "3m12s", "7 tools", "Ctrl+C quit"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("status bar missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestStatusBarDropsKeyHintsWhenNarrow(t *testing.T) {
+	sb := StatusBar{Model: "m", State: "Streaming", Theme: DefaultTheme(), Width: 80}
+
+	view := sb.View()
+	if strings.Contains(view, "Ctrl+C quit") {
+		t.Errorf("key hints should be dropped rather than wrap:\n%s", view)
+	}
+	if strings.Contains(view, "\n") {
+		t.Errorf("status bar should fit on one line:\n%s", view)
+	}
+}
@@ -51,7 +51,28 @@ type globalColors struct {
 
 // globalConfig is the top-level structure of ~/.hydra.yml.
 type globalConfig struct {
-	Colors globalColors `yaml:"colors"`
+	Colors globalColors       `yaml:"colors"`
+	Keys   map[string]keyList `yaml:"keys"`
+}
+
+// loadGlobalConfig reads ~/.hydra.yml. It reports false if the file is
+// missing or can't be parsed.
+func loadGlobalConfig() (globalConfig, bool) {
+	var cfg globalConfig
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return cfg, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".hydra.yml")) //nolint:gosec // well-known user config path
+	if err != nil {
+		return cfg, false
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, false
+	}
+	return cfg, true
 }
 
 // pywalColors is the JSON structure of ~/.cache/wal/colors.json.
@@ -120,18 +141,8 @@ func loadPywalTheme() Theme {
 
 // applyGlobalConfig loads ~/.hydra.yml and overrides any color fields that are set.
 func applyGlobalConfig(theme *Theme) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return
-	}
-
-	data, err := os.ReadFile(filepath.Join(home, ".hydra.yml")) //nolint:gosec // well-known user config path
-	if err != nil {
-		return
-	}
-
-	var cfg globalConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	cfg, ok := loadGlobalConfig()
+	if !ok {
 		return
 	}
 
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Transcript records a session's output and events so they can be saved if
+// the session crashes. It is shared by every copy of a Model and is safe for
+// concurrent use.
+type Transcript struct {
+	mu        sync.Mutex
+	output    strings.Builder
+	events    []string
+	now       func() time.Time
+	followers map[chan string]struct{}
+}
+
+// followBuffer is how many writes a follower may fall behind by before it
+// is dropped.
+const followBuffer = 256
+
+// NewTranscript returns an empty transcript.
+func NewTranscript() *Transcript {
+	return &Transcript{now: time.Now}
+}
+
+// write appends session output, without ANSI styling.
+func (t *Transcript) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s = ansi.Strip(s)
+	t.output.WriteString(s)
+	for ch := range t.followers {
+		select {
+		case ch <- s:
+		default:
+			delete(t.followers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Follow returns the output written so far and a channel that receives
+// output as it is written, until stop is called. The channel is closed if
+// the follower falls too far behind, or when stop is called.
+func (t *Transcript) Follow() (backlog string, updates <-chan string, stop func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan string, followBuffer)
+	if t.followers == nil {
+		t.followers = make(map[chan string]struct{})
+	}
+	t.followers[ch] = struct{}{}
+	stop = func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.followers[ch]; ok {
+			delete(t.followers, ch)
+			close(ch)
+		}
+	}
+	return t.output.String(), ch, stop
+}
+
+// event appends a timestamped entry to the event list.
+func (t *Transcript) event(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, t.now().Format(time.RFC3339)+" "+fmt.Sprintf(format, args...))
+}
+
+// Save writes the transcript to path, creating its directory, with reason
+// explaining why it was saved.
+func (t *Transcript) Save(path, reason string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "hydra session transcript\nsaved: %s\nreason: %s\n\n", t.now().Format(time.RFC3339), reason)
+	b.WriteString("== Events ==\n\n")
+	for _, e := range t.events {
+		b.WriteString(e + "\n")
+	}
+	b.WriteString("\n== Output ==\n\n")
+	b.WriteString(t.output.String())
+	b.WriteString("\n")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating transcript directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikh/hydra/internal/claude"
+)
+
+func TestTranscriptRecordsSession(t *testing.T) {
+	m, _ := newTestModel(false)
+	m.transcript.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	handleEvent(&m, eventMsg{event: claude.EventText{Text: "working on it"}})
+	handleEvent(&m, eventMsg{event: claude.EventToolRequest{
+		ID:   "t1",
+		Name: "bash",
+		Meta: claude.ToolMeta{Kind: claude.ToolKindBash, Command: "go test ./..."},
+	}})
+	m.answerTool(false)
+	handleEvent(&m, eventMsg{event: claude.EventError{Err: errors.New("stream reset")}})
+
+	path := filepath.Join(t.TempDir(), "crash", "task-1.log")
+	if err := m.Transcript().Save(path, "received SIGTERM"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // test path
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+
+	for _, want := range []string{
+		"reason: received SIGTERM",
+		"2026-03-01T12:00:00Z tool requested bash: go test ./...",
+		"tool rejected bash: go test ./...",
+		"session error: stream reset",
+		"working on it",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("crash log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "\x1b[") {
+		t.Errorf("crash log should not contain ANSI codes:\n%q", log)
+	}
+}
+
+func TestTranscriptSharedAcrossModelCopies(t *testing.T) {
+	m, _ := newTestModel(false)
+	tr := m.Transcript()
+
+	updated, _ := m.Update(eventMsg{event: claude.EventText{Text: "from a copy"}})
+	if updated.(Model).Transcript() != tr { //nolint:forcetypeassert // test
+		t.Fatal("model copies should share the transcript")
+	}
+	if !strings.Contains(tr.output.String(), "from a copy") {
+		t.Error("output written through a model copy should reach the shared transcript")
+	}
+}
+
+func TestTranscriptFollow(t *testing.T) {
+	tr := NewTranscript()
+	tr.write("before\n")
+
+	backlog, updates, stop := tr.Follow()
+	if backlog != "before\n" {
+		t.Errorf("backlog = %q, want %q", backlog, "before\n")
+	}
+	tr.write("\x1b[1mafter\x1b[0m\n")
+	if got := <-updates; got != "after\n" {
+		t.Errorf("update = %q, want %q", got, "after\n")
+	}
+
+	stop()
+	if _, ok := <-updates; ok {
+		t.Error("updates still open after stop")
+	}
+	stop() // a second stop is harmless
+	tr.write("ignored\n")
+}
+
+func TestTranscriptFollowDropsSlowFollower(t *testing.T) {
+	tr := NewTranscript()
+	_, updates, stop := tr.Follow()
+	defer stop()
+	for range followBuffer + 1 {
+		tr.write("x")
+	}
+	n := 0
+	for range updates {
+		n++
+	}
+	if n != followBuffer {
+		t.Errorf("received %d updates before the channel closed, want %d", n, followBuffer)
+	}
+}